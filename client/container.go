@@ -1,16 +1,37 @@
 package rig
 
-import "context"
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+)
 
 // ContainerDef defines a service backed by a Docker container. Use the
 // Container() constructor for the common case.
 type ContainerDef struct {
-	image     string
-	cmd       []string
-	env       map[string]string
-	ingresses map[string]IngressDef
-	egresses  map[string]egressDef
-	hooks     hooksDef
+	image         string
+	tarball       string
+	cmd           []string
+	env           map[string]string
+	ingresses     map[string]IngressDef
+	egresses      map[string]egressDef
+	lastEgress    string
+	hooks         hooksDef
+	timeout       time.Duration
+	healthPath    string
+	healthStatus  int
+	successes     int
+	dockerHealth  bool
+	readyExec     []string
+	privileged    bool
+	capAdd        []string
+	sysctls       map[string]string
+	dependsOn     []string
+	taskQueues    []specTaskQueueSpec
+	scale         int
+	stopTimeout   *time.Duration
+	restartOnExit int
 }
 
 func (*ContainerDef) rigService() {}
@@ -42,7 +63,30 @@ func (d *ContainerDef) Port(containerPort int) *ContainerDef {
 	return d
 }
 
-// Cmd overrides the container's default command.
+// ImageTarball loads the image from a local tarball (the output of
+// `docker save`) instead of pulling it from a registry, for CI without
+// registry access. The tarball is skipped if the daemon already has the same
+// image under that tag; rebuilding the tarball loads the new image. Relative
+// paths are resolved against the test's working directory.
+//
+// If the image passed to Container is empty, the tarball must contain a
+// single tagged image, which is used. Otherwise the tarball must contain
+// that image.
+//
+//	rig.Container("").ImageTarball("testdata/api.tar").Port(8080)
+func (d *ContainerDef) ImageTarball(path string) *ContainerDef {
+	d.tarball = path
+	return d
+}
+
+// Cmd overrides the container's default command. Arguments support
+// ${VAR} expansion against the service env, including egress attributes
+// namespaced as RIG_EGRESS_<NAME>_<ATTR>:
+//
+//	rig.Container("migrate/migrate").
+//		Cmd("-path", "/migrations", "-database",
+//			"postgres://postgres:postgres@${RIG_EGRESS_DB_PGHOST}:${RIG_EGRESS_DB_PGPORT}/${RIG_EGRESS_DB_PGDATABASE}?sslmode=disable", "up").
+//		Egress("db")
 func (d *ContainerDef) Cmd(args ...string) *ContainerDef {
 	d.cmd = args
 	return d
@@ -57,6 +101,22 @@ func (d *ContainerDef) Env(key, value string) *ContainerDef {
 	return d
 }
 
+// Clone returns a deep copy of the definition. See GoDef.Clone.
+func (d *ContainerDef) Clone() *ContainerDef {
+	c := *d
+	c.cmd = slices.Clone(d.cmd)
+	c.env = maps.Clone(d.env)
+	c.ingresses = cloneIngresses(d.ingresses)
+	c.egresses = cloneEgresses(d.egresses)
+	c.hooks = d.hooks.clone()
+	c.readyExec = slices.Clone(d.readyExec)
+	c.capAdd = slices.Clone(d.capAdd)
+	c.sysctls = maps.Clone(d.sysctls)
+	c.dependsOn = slices.Clone(d.dependsOn)
+	c.taskQueues = slices.Clone(d.taskQueues)
+	return &c
+}
+
 // NoIngress removes all ingresses, for containers that are pure workers.
 func (d *ContainerDef) NoIngress() *ContainerDef {
 	d.ingresses = nil
@@ -87,6 +147,47 @@ func (d *ContainerDef) EgressAs(name, service string, ingress ...string) *Contai
 		eg.ingress = ingress[0]
 	}
 	d.egresses[name] = eg
+	d.lastEgress = name
+	return d
+}
+
+// EgressExternal adds a dependency on an address outside the environment.
+// See GoDef.EgressExternal.
+func (d *ContainerDef) EgressExternal(name, url string) *ContainerDef {
+	if d.egresses == nil {
+		d.egresses = make(map[string]egressDef)
+	}
+	d.egresses[name] = egressDef{external: url}
+	d.lastEgress = name
+	return d
+}
+
+// AllowMethods restricts the most recently added egress to the listed gRPC
+// methods. See GoDef.AllowMethods.
+func (d *ContainerDef) AllowMethods(methods ...string) *ContainerDef {
+	allowEgressMethods(d.egresses, d.lastEgress, methods)
+	return d
+}
+
+// Mock makes the proxy on the most recently added egress answer matching
+// requests with a canned response. See GoDef.Mock.
+func (d *ContainerDef) Mock(m MockResponse) *ContainerDef {
+	mockEgress(d.egresses, d.lastEgress, m)
+	return d
+}
+
+// Database points the most recently added egress at a logical database on
+// its Postgres target. See GoDef.Database.
+func (d *ContainerDef) Database(name string) *ContainerDef {
+	selectEgressDatabase(d.egresses, d.lastEgress, name)
+	return d
+}
+
+// WaitForGRPCService holds the service until the target of the most
+// recently added egress registers the named gRPC services. See
+// GoDef.WaitForGRPCService.
+func (d *ContainerDef) WaitForGRPCService(services ...string) *ContainerDef {
+	waitForEgressGRPCServices(d.egresses, d.lastEgress, services)
 	return d
 }
 
@@ -110,3 +211,128 @@ func (d *ContainerDef) PrestartHook(fn func(ctx context.Context, w Wiring) error
 	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
 	return d
 }
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *ContainerDef) DependsOn(services ...string) *ContainerDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// WaitForTaskQueue holds the service back from ready until a worker is
+// polling the named Temporal task queue. See GoDef.WaitForTaskQueue.
+func (d *ContainerDef) WaitForTaskQueue(queue string, egress ...string) *ContainerDef {
+	tq := specTaskQueueSpec{Name: queue}
+	if len(egress) > 0 {
+		tq.Egress = egress[0]
+	}
+	d.taskQueues = append(d.taskQueues, tq)
+	return d
+}
+
+// Scale runs n identical instances of the service. See GoDef.Scale.
+func (d *ContainerDef) Scale(n int) *ContainerDef {
+	d.scale = n
+	return d
+}
+
+// StopTimeout sets how long the container gets to exit after SIGTERM at
+// teardown before it is killed. Zero kills it immediately. The default is
+// 10s. Docker counts whole seconds, so the timeout is rounded up.
+func (d *ContainerDef) StopTimeout(timeout time.Duration) *ContainerDef {
+	d.stopTimeout = &timeout
+	return d
+}
+
+// RestartOnExit restarts the container up to max times if it exits. See
+// GoDef.RestartOnExit.
+func (d *ContainerDef) RestartOnExit(max int) *ContainerDef {
+	d.restartOnExit = max
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *ContainerDef) Timeout(timeout time.Duration) *ContainerDef {
+	d.timeout = timeout
+	return d
+}
+
+// HealthPath sets the HTTP ready check path. See GoDef.HealthPath.
+func (d *ContainerDef) HealthPath(path string) *ContainerDef {
+	d.healthPath = path
+	return d
+}
+
+// HealthStatus sets the status the HTTP ready check requires. See
+// GoDef.HealthStatus.
+func (d *ContainerDef) HealthStatus(code int) *ContainerDef {
+	d.healthStatus = code
+	return d
+}
+
+// ReadySuccesses sets how many ready checks in a row must pass. See
+// GoDef.ReadySuccesses.
+func (d *ContainerDef) ReadySuccesses(n int) *ContainerDef {
+	d.successes = n
+	return d
+}
+
+// UseDockerHealthcheck makes the service ready when the image's own
+// HEALTHCHECK reports healthy, instead of when its ports respond. Use it for
+// images whose ports open before the service can handle requests. The image
+// must declare a HEALTHCHECK; Up fails immediately if it doesn't.
+//
+//	rig.Container("myteam/search:latest").Port(9200).UseDockerHealthcheck()
+func (d *ContainerDef) UseDockerHealthcheck() *ContainerDef {
+	d.dockerHealth = true
+	return d
+}
+
+// Privileged runs the container in privileged mode: it gets every Linux
+// capability and access to the host's devices, as images that manage
+// iptables or mount filesystems need. This removes most of the isolation
+// between the container and the host, so prefer CapAdd when the image
+// needs only specific capabilities. Up logs a warning when a privileged
+// container also has Exec hooks.
+//
+//	rig.Container("myteam/vpn:latest").NoIngress().Privileged()
+func (d *ContainerDef) Privileged() *ContainerDef {
+	d.privileged = true
+	return d
+}
+
+// CapAdd grants the container additional Linux capabilities, named as
+// Docker names them (e.g. "NET_ADMIN", "SYS_PTRACE"). Each capability
+// widens what the container can do to the host; grant only what the image
+// needs. Repeated calls accumulate.
+//
+//	rig.Container("myteam/firewall:latest").Port(8080).CapAdd("NET_ADMIN")
+func (d *ContainerDef) CapAdd(caps ...string) *ContainerDef {
+	d.capAdd = append(d.capAdd, caps...)
+	return d
+}
+
+// Sysctl sets a namespaced kernel parameter in the container, e.g.
+// "net.ipv4.ip_forward". Docker accepts only parameters scoped to the
+// container's namespaces; others fail at container start.
+//
+//	rig.Container("myteam/router:latest").Port(8080).Sysctl("net.ipv4.ip_forward", "1")
+func (d *ContainerDef) Sysctl(key, value string) *ContainerDef {
+	if d.sysctls == nil {
+		d.sysctls = make(map[string]string)
+	}
+	d.sysctls[key] = value
+	return d
+}
+
+// ReadyExec makes the service ready when cmd, run inside the container,
+// exits 0, instead of when its ports respond. The command is retried until
+// it succeeds or the ready timeout expires; each failure is reported with
+// its output in a health.check_failed event. Unlike Exec, which runs once
+// after the service is ready, it gates readiness.
+//
+//	rig.Container("rabbitmq:3").Port(5672).ReadyExec("rabbitmqctl", "status")
+func (d *ContainerDef) ReadyExec(cmd ...string) *ContainerDef {
+	d.readyExec = cmd
+	return d
+}
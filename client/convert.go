@@ -1,11 +1,13 @@
 package rig
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/matgreaves/rig/connect"
 )
@@ -25,14 +27,50 @@ func envToSpec(testName string, services Services, handlers map[string]hookFunc,
 		}
 		specs[name] = svc
 	}
+
+	// Func services run in the test process, so they get the environment's
+	// clock and seed through their context rather than env vars. The
+	// service's own FakeClock is applied inside and takes priority.
+	var fakeNow string
+	if !o.fakeNow.IsZero() {
+		fakeNow = o.fakeNow.Format(time.RFC3339Nano)
+	}
+	if !o.fakeNow.IsZero() || o.seed != nil {
+		for name, fn := range startHandlers {
+			startHandlers[name] = func(ctx context.Context) error {
+				if !o.fakeNow.IsZero() {
+					ctx = connect.WithFakeNow(ctx, o.fakeNow)
+				}
+				if o.seed != nil {
+					ctx = connect.WithSeed(ctx, *o.seed)
+				}
+				return fn(ctx)
+			}
+		}
+	}
+
 	dir, _ := os.Getwd()
 	return specEnvironment{
-		Name:     testName,
-		Services: specs,
-		Observe:  o.observe,
-		HostEnv:  captureHostEnv(),
-		Dir:      dir,
-		TTL:      o.ttl,
+		Name:              testName,
+		Services:          specs,
+		Observe:           o.observe,
+		HostEnv:           captureHostEnv(),
+		Dir:               dir,
+		TTL:               o.ttl,
+		ContainerNetwork:  o.containerNetwork,
+		TCPIdleTimeout:    o.tcpIdleTimeout,
+		ObserveBodyLimit:  o.observeBodyLimit,
+		ObserveAutoDetect: o.autoDetect,
+		ObserveConnPool:   o.connPool,
+		ObserveTLS:        o.observeTLS,
+		ObserveRedact:     o.redact,
+		ProtoDescriptors:  o.protoDescriptors,
+		Metadata:          o.metadata,
+		Reuse:             o.reuse,
+		StartupOrder:      o.startupOrder,
+		FakeNow:           fakeNow,
+		Seed:              o.seed,
+		Faults:            o.faults,
 	}, nil
 }
 
@@ -48,6 +86,8 @@ func serviceToSpec(def ServiceDef, handlers map[string]hookFunc, startHandlers m
 		return containerToSpec(d, handlers)
 	case *PostgresDef:
 		return postgresToSpec(d, handlers)
+	case *MySQLDef:
+		return mysqlToSpec(d, handlers)
 	case *CustomDef:
 		return customToSpec(d, handlers)
 	case *TemporalDef:
@@ -60,50 +100,96 @@ func serviceToSpec(def ServiceDef, handlers map[string]hookFunc, startHandlers m
 		return sqsToSpec(d, handlers)
 	case *KafkaDef:
 		return kafkaToSpec(d, handlers)
+	case *NATSDef:
+		return natsToSpec(d, handlers)
+	case *MongoDef:
+		return mongoToSpec(d, handlers)
 	default:
 		return specService{}, fmt.Errorf("unknown service type: %T", def)
 	}
 }
 
 func goToSpec(d *GoDef, handlers map[string]hookFunc) (specService, error) {
-	cfg, _ := json.Marshal(map[string]string{"module": d.module})
+	cfgMap := map[string]any{"module": d.module}
+	if len(d.buildTags) > 0 {
+		cfgMap["build_tags"] = d.buildTags
+	}
+	if len(d.buildFlags) > 0 {
+		cfgMap["build_flags"] = d.buildFlags
+	}
+	if d.goVersion != "" {
+		cfgMap["go_version"] = d.goVersion
+	}
+	cfg, _ := json.Marshal(cfgMap)
 
 	hooks, err := hooksToSpec(d.hooks, handlers)
 	if err != nil {
 		return specService{}, err
 	}
 
+	dotEnv, err := envFileToSpec(d.envFile)
+	if err != nil {
+		return specService{}, err
+	}
+
 	return specService{
-		Type:      "go",
-		Config:    cfg,
-		Args:      d.args,
-		Ingresses: ingressesToSpec(d.ingresses),
-		Egresses:  egressesToSpec(d.egresses),
-		Hooks:     hooks,
+		Type:          "go",
+		Config:        cfg,
+		Args:          d.args,
+		Ingresses:     readySuccessesToSpec(readyHealthToSpec(readyTimeoutToSpec(ingressesToSpec(d.ingresses), d.timeout), d.healthPath, d.healthStatus), d.successes),
+		Egresses:      egressesToSpec(d.egresses),
+		DependsOn:     d.dependsOn,
+		TaskQueues:    d.taskQueues,
+		Scale:         d.scale,
+		Hooks:         hooks,
+		DotEnv:        dotEnv,
+		Env:           d.env,
+		StopTimeout:   stopTimeoutToSpec(d.stopTimeout),
+		RestartOnExit: d.restartOnExit,
 	}, nil
 }
 
 func processToSpec(d *ProcessDef, handlers map[string]hookFunc) (specService, error) {
-	cfg, _ := json.Marshal(map[string]string{"command": d.command, "dir": d.dir})
+	cfgMap := map[string]any{"command": d.command, "dir": d.dir}
+	cfg, _ := json.Marshal(cfgMap)
 
 	hooks, err := hooksToSpec(d.hooks, handlers)
 	if err != nil {
 		return specService{}, err
 	}
 
+	dotEnv, err := envFileToSpec(d.envFile)
+	if err != nil {
+		return specService{}, err
+	}
+
 	return specService{
-		Type:      "process",
-		Config:    cfg,
-		Args:      d.args,
-		Ingresses: ingressesToSpec(d.ingresses),
-		Egresses:  egressesToSpec(d.egresses),
-		Hooks:     hooks,
+		Type:          "process",
+		Config:        cfg,
+		Args:          d.args,
+		Ingresses:     readySuccessesToSpec(readyHealthToSpec(readyTimeoutToSpec(ingressesToSpec(d.ingresses), d.timeout), d.healthPath, d.healthStatus), d.successes),
+		Egresses:      egressesToSpec(d.egresses),
+		DependsOn:     d.dependsOn,
+		TaskQueues:    d.taskQueues,
+		Scale:         d.scale,
+		Hooks:         hooks,
+		DotEnv:        dotEnv,
+		Env:           d.env,
+		StopTimeout:   stopTimeoutToSpec(d.stopTimeout),
+		RestartOnExit: d.restartOnExit,
 	}, nil
 }
 
 func funcToSpec(d *FuncDef, handlers map[string]hookFunc, startHandlers map[string]startFunc) (specService, error) {
 	name := fmt.Sprintf("_start_%d", hookSeq.Add(1))
-	startHandlers[name] = startFunc(d.fn)
+	fn := d.fn
+	if !d.fakeNow.IsZero() {
+		fakeNow := d.fakeNow
+		fn = func(ctx context.Context) error {
+			return d.fn(connect.WithFakeNow(ctx, fakeNow))
+		}
+	}
+	startHandlers[name] = startFunc(fn)
 
 	cfg, _ := json.Marshal(map[string]string{"start_handler": name})
 
@@ -113,15 +199,54 @@ func funcToSpec(d *FuncDef, handlers map[string]hookFunc, startHandlers map[stri
 	}
 
 	return specService{
-		Type:      "client",
-		Config:    cfg,
-		Ingresses: ingressesToSpec(d.ingresses),
+		Type:       "client",
+		Config:     cfg,
+		Ingresses:  readySuccessesToSpec(readyHealthToSpec(readyTimeoutToSpec(ingressesToSpec(d.ingresses), d.timeout), d.healthPath, d.healthStatus), d.successes),
+		Egresses:   egressesToSpec(d.egresses),
+		DependsOn:  d.dependsOn,
+		TaskQueues: d.taskQueues,
+		Scale:      d.scale,
+		Hooks:      hooks,
+	}, nil
+}
+
+func postgresToSpec(d *PostgresDef, handlers map[string]hookFunc) (specService, error) {
+	var cfg json.RawMessage
+	if d.image != "" || len(d.databases) > 0 || d.logStatements || d.logMinDuration != nil {
+		cfgMap := map[string]any{}
+		if d.image != "" {
+			cfgMap["image"] = d.image
+		}
+		if len(d.databases) > 0 {
+			cfgMap["databases"] = d.databases
+		}
+		if d.logStatements {
+			cfgMap["log_statements"] = true
+		}
+		if d.logMinDuration != nil {
+			cfgMap["log_min_duration"] = specDuration{Duration: *d.logMinDuration}
+		}
+		cfg, _ = json.Marshal(cfgMap)
+	}
+
+	hooks, err := hooksToSpec(d.hooks, handlers)
+	if err != nil {
+		return specService{}, err
+	}
+
+	return specService{
+		Type:   "postgres",
+		Config: cfg,
+		Ingresses: readyTimeoutToSpec(map[string]specIngressSpec{
+			"default": {Protocol: TCP, ContainerPort: 5432},
+		}, d.timeout),
 		Egresses:  egressesToSpec(d.egresses),
+		DependsOn: d.dependsOn,
 		Hooks:     hooks,
 	}, nil
 }
 
-func postgresToSpec(d *PostgresDef, handlers map[string]hookFunc) (specService, error) {
+func mysqlToSpec(d *MySQLDef, handlers map[string]hookFunc) (specService, error) {
 	var cfg json.RawMessage
 	if d.image != "" {
 		cfg, _ = json.Marshal(map[string]string{"image": d.image})
@@ -133,24 +258,43 @@ func postgresToSpec(d *PostgresDef, handlers map[string]hookFunc) (specService,
 	}
 
 	return specService{
-		Type:   "postgres",
+		Type:   "mysql",
 		Config: cfg,
-		Ingresses: map[string]specIngressSpec{
-			"default": {Protocol: TCP, ContainerPort: 5432},
-		},
-		Egresses: egressesToSpec(d.egresses),
-		Hooks:    hooks,
+		Ingresses: readyTimeoutToSpec(map[string]specIngressSpec{
+			"default": {Protocol: TCP, ContainerPort: 3306},
+		}, d.timeout),
+		Egresses:  egressesToSpec(d.egresses),
+		DependsOn: d.dependsOn,
+		Hooks:     hooks,
 	}, nil
 }
 
 func containerToSpec(d *ContainerDef, handlers map[string]hookFunc) (specService, error) {
 	cfgMap := map[string]any{"image": d.image}
+	if d.tarball != "" {
+		cfgMap["image_tarball"] = d.tarball
+	}
 	if len(d.cmd) > 0 {
 		cfgMap["cmd"] = d.cmd
 	}
 	if len(d.env) > 0 {
 		cfgMap["env"] = d.env
 	}
+	if d.dockerHealth {
+		cfgMap["docker_healthcheck"] = true
+	}
+	if len(d.readyExec) > 0 {
+		cfgMap["ready_exec"] = d.readyExec
+	}
+	if d.privileged {
+		cfgMap["privileged"] = true
+	}
+	if len(d.capAdd) > 0 {
+		cfgMap["cap_add"] = d.capAdd
+	}
+	if len(d.sysctls) > 0 {
+		cfgMap["sysctls"] = d.sysctls
+	}
 	cfg, err := json.Marshal(cfgMap)
 	if err != nil {
 		return specService{}, fmt.Errorf("marshal container config: %w", err)
@@ -162,11 +306,16 @@ func containerToSpec(d *ContainerDef, handlers map[string]hookFunc) (specService
 	}
 
 	return specService{
-		Type:      "container",
-		Config:    cfg,
-		Ingresses: ingressesToSpec(d.ingresses),
-		Egresses:  egressesToSpec(d.egresses),
-		Hooks:     hooks,
+		Type:          "container",
+		Config:        cfg,
+		Ingresses:     readySuccessesToSpec(readyHealthToSpec(readyTimeoutToSpec(ingressesToSpec(d.ingresses), d.timeout), d.healthPath, d.healthStatus), d.successes),
+		Egresses:      egressesToSpec(d.egresses),
+		DependsOn:     d.dependsOn,
+		TaskQueues:    d.taskQueues,
+		Scale:         d.scale,
+		Hooks:         hooks,
+		StopTimeout:   stopTimeoutToSpec(d.stopTimeout),
+		RestartOnExit: d.restartOnExit,
 	}, nil
 }
 
@@ -186,12 +335,15 @@ func customToSpec(d *CustomDef, handlers map[string]hookFunc) (specService, erro
 	}
 
 	return specService{
-		Type:      d.svcType,
-		Config:    cfg,
-		Args:      d.args,
-		Ingresses: ingressesToSpec(d.ingresses),
-		Egresses:  egressesToSpec(d.egresses),
-		Hooks:     hooks,
+		Type:       d.svcType,
+		Config:     cfg,
+		Args:       d.args,
+		Ingresses:  readySuccessesToSpec(readyHealthToSpec(readyTimeoutToSpec(ingressesToSpec(d.ingresses), d.timeout), d.healthPath, d.healthStatus), d.successes),
+		Egresses:   egressesToSpec(d.egresses),
+		DependsOn:  d.dependsOn,
+		TaskQueues: d.taskQueues,
+		Scale:      d.scale,
+		Hooks:      hooks,
 	}, nil
 }
 
@@ -205,11 +357,14 @@ func ingressesToSpec(ingresses map[string]IngressDef) map[string]specIngressSpec
 			Protocol:      Protocol(ing.Protocol),
 			ContainerPort: ing.ContainerPort,
 			Attributes:    ing.Attributes,
+			MaxBodySize:   ing.maxBodySize,
 		}
 		if ing.Ready != nil {
 			s.Ready = &specReadySpec{
-				Type: ing.Ready.Type,
-				Path: ing.Ready.Path,
+				Type:      ing.Ready.Type,
+				Path:      ing.Ready.Path,
+				Status:    ing.Ready.Status,
+				Successes: ing.Ready.Successes,
 			}
 			if ing.Ready.Interval > 0 {
 				s.Ready.Interval = specDuration{Duration: ing.Ready.Interval}
@@ -223,16 +378,103 @@ func ingressesToSpec(ingresses map[string]IngressDef) map[string]specIngressSpec
 	return out
 }
 
+// stopTimeoutToSpec converts an optional stop timeout. Unset stays nil so
+// the server keeps the type's default; zero is sent as an explicit zero.
+func stopTimeoutToSpec(d *time.Duration) *specDuration {
+	if d == nil {
+		return nil
+	}
+	return &specDuration{Duration: *d}
+}
+
+// readyTimeoutToSpec applies a service-level ready timeout to every ingress
+// that doesn't set its own.
+func readyTimeoutToSpec(ingresses map[string]specIngressSpec, timeout time.Duration) map[string]specIngressSpec {
+	if timeout <= 0 {
+		return ingresses
+	}
+	for name, ing := range ingresses {
+		if ing.Ready == nil {
+			ing.Ready = &specReadySpec{}
+		}
+		if ing.Ready.Timeout.Duration == 0 {
+			ing.Ready.Timeout = specDuration{Duration: timeout}
+		}
+		ingresses[name] = ing
+	}
+	return ingresses
+}
+
+// readySuccessesToSpec applies a service-level consecutive-success count to
+// every ingress that doesn't set its own.
+func readySuccessesToSpec(ingresses map[string]specIngressSpec, n int) map[string]specIngressSpec {
+	if n == 0 {
+		return ingresses
+	}
+	for name, ing := range ingresses {
+		if ing.Ready == nil {
+			ing.Ready = &specReadySpec{}
+		}
+		if ing.Ready.Successes == 0 {
+			ing.Ready.Successes = n
+		}
+		ingresses[name] = ing
+	}
+	return ingresses
+}
+
+// readyHealthToSpec applies a service-level HTTP health path and expected
+// status to every HTTP-checked ingress that doesn't set its own.
+func readyHealthToSpec(ingresses map[string]specIngressSpec, path string, status int) map[string]specIngressSpec {
+	if path == "" && status == 0 {
+		return ingresses
+	}
+	for name, ing := range ingresses {
+		checkType := string(ing.Protocol)
+		if ing.Ready != nil && ing.Ready.Type != "" {
+			checkType = ing.Ready.Type
+		}
+		if checkType != string(HTTP) {
+			continue
+		}
+		if ing.Ready == nil {
+			ing.Ready = &specReadySpec{}
+		}
+		if ing.Ready.Path == "" {
+			ing.Ready.Path = path
+		}
+		if ing.Ready.Status == 0 {
+			ing.Ready.Status = status
+		}
+		ingresses[name] = ing
+	}
+	return ingresses
+}
+
 func egressesToSpec(egresses map[string]egressDef) map[string]specEgressSpec {
 	if len(egresses) == 0 {
 		return nil
 	}
 	out := make(map[string]specEgressSpec, len(egresses))
 	for name, eg := range egresses {
-		out[name] = specEgressSpec{
-			Service: eg.service,
-			Ingress: eg.ingress,
+		s := specEgressSpec{
+			Service:             eg.service,
+			Ingress:             eg.ingress,
+			AllowMethods:        eg.allowMethods,
+			Database:            eg.database,
+			External:            eg.external,
+			WaitForGRPCServices: eg.waitForGRPC,
 		}
+		for _, m := range eg.mocks {
+			s.Mocks = append(s.Mocks, specMockSpec{
+				Method:  m.Method,
+				Path:    m.Path,
+				Status:  m.Status,
+				Headers: m.Headers,
+				Body:    m.Body,
+			})
+		}
+		out[name] = s
 	}
 	return out
 }
@@ -273,7 +515,25 @@ func hookToSpec(h hook, handlers map[string]hookFunc) (*specHookSpec, error) {
 			ClientFunc: &specClientFuncSpec{Name: name},
 		}, nil
 	case sqlHook:
-		cfg, _ := json.Marshal(map[string]any{"statements": hk.statements})
+		cfgMap := map[string]any{"statements": hk.statements}
+		if hk.database != "" {
+			cfgMap["database"] = hk.database
+		}
+		cfg, _ := json.Marshal(cfgMap)
+		return &specHookSpec{
+			Type:   "sql",
+			Config: cfg,
+		}, nil
+	case sqlFileHook:
+		var stmts []string
+		for _, p := range hk.paths {
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return nil, fmt.Errorf("InitSQLFile: %w", err)
+			}
+			stmts = append(stmts, splitSQL(string(data))...)
+		}
+		cfg, _ := json.Marshal(map[string]any{"statements": stmts})
 		return &specHookSpec{
 			Type:   "sql",
 			Config: cfg,
@@ -284,6 +544,20 @@ func hookToSpec(h hook, handlers map[string]hookFunc) (*specHookSpec, error) {
 			Type:   "exec",
 			Config: cfg,
 		}, nil
+	case redisHook:
+		cfg, _ := json.Marshal(map[string]any{"commands": hk.commands})
+		return &specHookSpec{
+			Type:   "redis",
+			Config: cfg,
+		}, nil
+	case mongoHook:
+		cfg, _ := json.Marshal(map[string]any{"scripts": []string{hk.script}})
+		return &specHookSpec{
+			Type:   "mongo",
+			Config: cfg,
+		}, nil
+	case temporalHook:
+		return &specHookSpec{Type: "temporal"}, nil
 	case schemaHook:
 		cfg, _ := json.Marshal(map[string]any{
 			"subject":     hk.subject,
@@ -300,13 +574,28 @@ func hookToSpec(h hook, handlers map[string]hookFunc) (*specHookSpec, error) {
 }
 
 func temporalToSpec(d *TemporalDef, handlers map[string]hookFunc) (specService, error) {
-	var cfg json.RawMessage
+	cfgMap := map[string]any{}
 	if d.version != "" {
-		cfgMap := map[string]string{"version": d.version}
+		cfgMap["version"] = d.version
+	}
+	if d.namespace != "" {
+		cfgMap["namespace"] = d.namespace
+	}
+	if len(d.searchAttributes) > 0 {
+		cfgMap["search_attributes"] = d.searchAttributes
+	}
+	var cfg json.RawMessage
+	if len(cfgMap) > 0 {
 		cfg, _ = json.Marshal(cfgMap)
 	}
 
-	hooks, err := hooksToSpec(d.hooks, handlers)
+	// The namespace and search attributes are created first, so the
+	// service's own init hooks can rely on them.
+	h := d.hooks
+	if d.namespace != "" || len(d.searchAttributes) > 0 {
+		h.init = append([]hook{temporalHook{}}, h.init...)
+	}
+	hooks, err := hooksToSpec(h, handlers)
 	if err != nil {
 		return specService{}, err
 	}
@@ -314,12 +603,13 @@ func temporalToSpec(d *TemporalDef, handlers map[string]hookFunc) (specService,
 	return specService{
 		Type:   "temporal",
 		Config: cfg,
-		Ingresses: map[string]specIngressSpec{
+		Ingresses: readyTimeoutToSpec(map[string]specIngressSpec{
 			"default": {Protocol: GRPC},
 			"ui":      {Protocol: HTTP},
-		},
-		Egresses: egressesToSpec(d.egresses),
-		Hooks:    hooks,
+		}, d.timeout),
+		Egresses:  egressesToSpec(d.egresses),
+		DependsOn: d.dependsOn,
+		Hooks:     hooks,
 	}, nil
 }
 
@@ -337,11 +627,12 @@ func redisToSpec(d *RedisDef, handlers map[string]hookFunc) (specService, error)
 	return specService{
 		Type:   "redis",
 		Config: cfg,
-		Ingresses: map[string]specIngressSpec{
-			"default": {Protocol: TCP, ContainerPort: 6379},
-		},
-		Egresses: egressesToSpec(d.egresses),
-		Hooks:    hooks,
+		Ingresses: readyTimeoutToSpec(map[string]specIngressSpec{
+			"default": {Protocol: connect.Redis, ContainerPort: 6379},
+		}, d.timeout),
+		Egresses:  egressesToSpec(d.egresses),
+		DependsOn: d.dependsOn,
+		Hooks:     hooks,
 	}, nil
 }
 
@@ -353,11 +644,12 @@ func s3ToSpec(d *S3Def, handlers map[string]hookFunc) (specService, error) {
 
 	return specService{
 		Type: "s3",
-		Ingresses: map[string]specIngressSpec{
+		Ingresses: readyTimeoutToSpec(map[string]specIngressSpec{
 			"default": {Protocol: TCP, ContainerPort: 9000},
-		},
-		Egresses: egressesToSpec(d.egresses),
-		Hooks:    hooks,
+		}, d.timeout),
+		Egresses:  egressesToSpec(d.egresses),
+		DependsOn: d.dependsOn,
+		Hooks:     hooks,
 	}, nil
 }
 
@@ -369,11 +661,12 @@ func sqsToSpec(d *SQSDef, handlers map[string]hookFunc) (specService, error) {
 
 	return specService{
 		Type: "sqs",
-		Ingresses: map[string]specIngressSpec{
+		Ingresses: readyTimeoutToSpec(map[string]specIngressSpec{
 			"default": {Protocol: TCP, ContainerPort: 9324},
-		},
-		Egresses: egressesToSpec(d.egresses),
-		Hooks:    hooks,
+		}, d.timeout),
+		Egresses:  egressesToSpec(d.egresses),
+		DependsOn: d.dependsOn,
+		Hooks:     hooks,
 	}, nil
 }
 
@@ -391,15 +684,70 @@ func kafkaToSpec(d *KafkaDef, handlers map[string]hookFunc) (specService, error)
 	return specService{
 		Type:   "kafka",
 		Config: cfg,
-		Ingresses: map[string]specIngressSpec{
+		Ingresses: readyTimeoutToSpec(map[string]specIngressSpec{
 			"default":         {Protocol: connect.Kafka, ContainerPort: 9092},
 			"schema-registry": {Protocol: HTTP, ContainerPort: 8081},
-		},
-		Egresses: egressesToSpec(d.egresses),
-		Hooks:    hooks,
+		}, d.timeout),
+		Egresses:  egressesToSpec(d.egresses),
+		DependsOn: d.dependsOn,
+		Hooks:     hooks,
+	}, nil
+}
+
+func natsToSpec(d *NATSDef, handlers map[string]hookFunc) (specService, error) {
+	var cfg json.RawMessage
+	if d.image != "" {
+		cfg, _ = json.Marshal(map[string]string{"image": d.image})
+	}
+
+	hooks, err := hooksToSpec(d.hooks, handlers)
+	if err != nil {
+		return specService{}, err
+	}
+
+	return specService{
+		Type:   "nats",
+		Config: cfg,
+		Ingresses: readyTimeoutToSpec(map[string]specIngressSpec{
+			"default": {Protocol: connect.NATS, ContainerPort: 4222},
+		}, d.timeout),
+		Egresses:  egressesToSpec(d.egresses),
+		DependsOn: d.dependsOn,
+		Hooks:     hooks,
 	}, nil
 }
 
+func mongoToSpec(d *MongoDef, handlers map[string]hookFunc) (specService, error) {
+	var cfg json.RawMessage
+	if d.image != "" {
+		cfg, _ = json.Marshal(map[string]string{"image": d.image})
+	}
+
+	hooks, err := hooksToSpec(d.hooks, handlers)
+	if err != nil {
+		return specService{}, err
+	}
+
+	return specService{
+		Type:   "mongo",
+		Config: cfg,
+		Ingresses: readyTimeoutToSpec(map[string]specIngressSpec{
+			"default": {Protocol: connect.Mongo, ContainerPort: 27017},
+		}, d.timeout),
+		Egresses:  egressesToSpec(d.egresses),
+		DependsOn: d.dependsOn,
+		Hooks:     hooks,
+	}, nil
+}
+
+// envFileToSpec reads the dotenv file at path, if one was set.
+func envFileToSpec(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return readEnvFile(path)
+}
+
 // captureHostEnv returns the current process environment as a map.
 func captureHostEnv() map[string]string {
 	environ := os.Environ()
@@ -0,0 +1,659 @@
+package rig
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/connect"
+)
+
+func TestEnvToSpec_Timeout(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"echo": Go("./cmd/echo").Timeout(2 * time.Second),
+		"api": Process("/bin/api").
+			Ingress("admin", IngressDef{Protocol: HTTP, Ready: &ReadyDef{Path: "/healthz", Timeout: 5 * time.Second}}).
+			Timeout(time.Second),
+		"temporal": Temporal().Timeout(2 * time.Minute),
+		"db":       Postgres(),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		service, ingress string
+		want             time.Duration
+	}{
+		{"echo", "default", 2 * time.Second},
+		{"api", "default", time.Second},
+		{"api", "admin", 5 * time.Second}, // ingress override wins
+		{"temporal", "default", 2 * time.Minute},
+		{"temporal", "ui", 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		ready := spec.Services[tt.service].Ingresses[tt.ingress].Ready
+		if ready == nil {
+			t.Errorf("%s/%s: no ready spec", tt.service, tt.ingress)
+			continue
+		}
+		if ready.Timeout.Duration != tt.want {
+			t.Errorf("%s/%s: timeout = %s, want %s", tt.service, tt.ingress, ready.Timeout.Duration, tt.want)
+		}
+	}
+	if got := spec.Services["api"].Ingresses["admin"].Ready.Path; got != "/healthz" {
+		t.Errorf("api/admin: path = %q, want /healthz", got)
+	}
+	if ready := spec.Services["db"].Ingresses["default"].Ready; ready != nil {
+		t.Errorf("db: ready = %+v, want nil without Timeout", ready)
+	}
+}
+
+func TestEnvToSpec_ReadySuccesses(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"api": Process("/bin/api").
+			Ingress("admin", IngressDef{Protocol: HTTP, Ready: &ReadyDef{Successes: 5}}).
+			ReadySuccesses(3),
+		"db": Postgres(),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for ingress, want := range map[string]int{"default": 3, "admin": 5} {
+		ready := spec.Services["api"].Ingresses[ingress].Ready
+		if ready == nil || ready.Successes != want {
+			t.Errorf("api/%s: ready = %+v, want successes %d", ingress, ready, want)
+		}
+	}
+	if ready := spec.Services["db"].Ingresses["default"].Ready; ready != nil {
+		t.Errorf("db: ready = %+v, want nil without ReadySuccesses", ready)
+	}
+}
+
+func TestEnvToSpec_DependsOn(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"migrate": Process("/bin/migrate"),
+		"worker":  Go("./cmd/worker").DependsOn("migrate"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worker := spec.Services["worker"]
+	if len(worker.DependsOn) != 1 || worker.DependsOn[0] != "migrate" {
+		t.Errorf("worker depends_on = %v, want [migrate]", worker.DependsOn)
+	}
+	if len(worker.Egresses) != 0 {
+		t.Errorf("worker egresses = %v, want none", worker.Egresses)
+	}
+}
+
+func TestEnvToSpec_StartupOrder(t *testing.T) {
+	var o options
+	WithStartupOrder([]string{"a", "b"})(&o)
+	WithStartupOrder([]string{"c", "d"})(&o)
+	spec, err := envToSpec("T", Services{
+		"a": Process("/bin/a"),
+		"b": Process("/bin/b"),
+		"c": Process("/bin/c"),
+		"d": Process("/bin/d"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"a", "b"}, {"c", "d"}}
+	if !reflect.DeepEqual(spec.StartupOrder, want) {
+		t.Errorf("startup_order = %v, want %v", spec.StartupOrder, want)
+	}
+}
+
+func TestWithFault(t *testing.T) {
+	var o options
+	WithFault("api→db", FaultSpec{Latency: time.Second, DropRate: 0.5})(&o)
+	WithFault("~test -> api", FaultSpec{ErrorRate: 1})(&o)
+	want := []specFaultSpec{
+		{Source: "api", Target: "db", Latency: specDuration{Duration: time.Second}, DropRate: 0.5},
+		{Source: "~test", Target: "api", ErrorRate: 1},
+	}
+	if !reflect.DeepEqual(o.faults, want) {
+		t.Errorf("faults = %+v, want %+v", o.faults, want)
+	}
+
+	for _, edge := range []string{"api", "api→", "→db"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithFault(%q) did not panic", edge)
+				}
+			}()
+			WithFault(edge, FaultSpec{})
+		}()
+	}
+}
+
+func TestEnvToSpec_FrozenClockAndSeed(t *testing.T) {
+	base := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	var o options
+	WithFrozenClock(base)(&o)
+	WithSeed(42)(&o)
+
+	var gotNow time.Time
+	var gotSeed int64
+	startHandlers := map[string]startFunc{}
+	spec, err := envToSpec("T", Services{
+		"worker": Func(func(ctx context.Context) error {
+			gotNow = connect.Now(ctx)
+			gotSeed, _ = connect.Seed(ctx)
+			return nil
+		}),
+	}, map[string]hookFunc{}, startHandlers, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.FakeNow != "2030-01-02T03:04:05Z" {
+		t.Errorf("fake_now = %q, want 2030-01-02T03:04:05Z", spec.FakeNow)
+	}
+	if spec.Seed == nil || *spec.Seed != 42 {
+		t.Errorf("seed = %v, want 42", spec.Seed)
+	}
+
+	// Func services get the clock and seed through their context.
+	for _, fn := range startHandlers {
+		if err := fn(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !gotNow.Equal(base) || gotSeed != 42 {
+		t.Errorf("func saw now %v, seed %d; want %v, 42", gotNow, gotSeed, base)
+	}
+}
+
+func TestEnvToSpec_Redact(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		headers []string
+		want    string
+	}{
+		{"nil keeps defaults", nil, `{"headers":["Authorization","Cookie","Set-Cookie"],"json_paths":["user.email"]}`},
+		{"list replaces defaults", []string{"X-Api-Key"}, `{"headers":["X-Api-Key"],"json_paths":["user.email"]}`},
+		{"empty redacts no headers", []string{}, `{"json_paths":["user.email"]}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var o options
+			WithRedact(tt.headers, []string{"user.email"})(&o)
+			spec, err := envToSpec("T", Services{"api": Go("./cmd/api")}, map[string]hookFunc{}, map[string]startFunc{}, o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := json.Marshal(spec.ObserveRedact)
+			if string(got) != tt.want {
+				t.Errorf("observe_redact = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvToSpec_StopTimeout(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"api":    Go("./cmd/api").StopTimeout(2 * time.Second),
+		"worker": Process("/bin/worker").StopTimeout(0),
+		"cache":  Container("redis:7").Port(6379).StopTimeout(30 * time.Second),
+		"echo":   Go("./cmd/echo"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]time.Duration{
+		"api":    2 * time.Second,
+		"worker": 0,
+		"cache":  30 * time.Second,
+	} {
+		got := spec.Services[name].StopTimeout
+		if got == nil {
+			t.Errorf("%s: stop timeout unset, want %s", name, want)
+		} else if got.Duration != want {
+			t.Errorf("%s: stop timeout = %s, want %s", name, got.Duration, want)
+		}
+	}
+	if got := spec.Services["echo"].StopTimeout; got != nil {
+		t.Errorf("echo: stop timeout = %s, want unset", got.Duration)
+	}
+
+	// An explicit zero must survive the wire so the server kills
+	// immediately instead of applying its default.
+	b, err := json.Marshal(spec.Services["worker"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"stop_timeout":""`) {
+		t.Errorf("worker JSON = %s, want an explicit zero stop_timeout", b)
+	}
+}
+
+func TestEnvToSpec_RestartOnExit(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"api":    Go("./cmd/api").RestartOnExit(3),
+		"worker": Process("/bin/worker").RestartOnExit(1),
+		"cache":  Container("redis:7").Port(6379).RestartOnExit(2),
+		"echo":   Go("./cmd/echo"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]int{"api": 3, "worker": 1, "cache": 2, "echo": 0} {
+		if got := spec.Services[name].RestartOnExit; got != want {
+			t.Errorf("%s: restart on exit = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestEnvToSpec_KafkaTopics(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"kafka": Kafka().Topics("orders", "events"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks := spec.Services["kafka"].Hooks
+	if hooks == nil || len(hooks.Init) != 1 {
+		t.Fatalf("hooks = %+v, want one init hook", hooks)
+	}
+	hook := hooks.Init[0]
+	want := `{"command":["rpk","topic","create","orders","events"]}`
+	if hook.Type != "exec" || string(hook.Config) != want {
+		t.Errorf("hook = %s %s, want exec %s", hook.Type, hook.Config, want)
+	}
+	if spec.Services["kafka"].Ingresses["default"].Protocol != "kafka" {
+		t.Errorf("default ingress protocol = %q, want kafka", spec.Services["kafka"].Ingresses["default"].Protocol)
+	}
+}
+
+func TestEnvToSpec_NATS(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"bus": NATS().Image("nats:2.10-alpine"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := spec.Services["bus"]
+	if svc.Type != "nats" || string(svc.Config) != `{"image":"nats:2.10-alpine"}` {
+		t.Errorf("service = %s %s, want nats with image config", svc.Type, svc.Config)
+	}
+	ing := svc.Ingresses["default"]
+	if ing.Protocol != "nats" || ing.ContainerPort != 4222 {
+		t.Errorf("default ingress = %s:%d, want nats:4222", ing.Protocol, ing.ContainerPort)
+	}
+}
+
+func TestEnvToSpec_Mongo(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"db": Mongo().Image("mongo:6").InitScript(`db.users.insertOne({name: "Ada"})`),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := spec.Services["db"]
+	if svc.Type != "mongo" || string(svc.Config) != `{"image":"mongo:6"}` {
+		t.Errorf("service = %s %s, want mongo with image config", svc.Type, svc.Config)
+	}
+	ing := svc.Ingresses["default"]
+	if ing.Protocol != "mongo" || ing.ContainerPort != 27017 {
+		t.Errorf("default ingress = %s:%d, want mongo:27017", ing.Protocol, ing.ContainerPort)
+	}
+	if svc.Hooks == nil || len(svc.Hooks.Init) != 1 {
+		t.Fatalf("init hooks = %+v, want one mongo hook", svc.Hooks)
+	}
+	hook := svc.Hooks.Init[0]
+	if hook.Type != "mongo" || string(hook.Config) != `{"scripts":["db.users.insertOne({name: \"Ada\"})"]}` {
+		t.Errorf("init hook = %s %s", hook.Type, hook.Config)
+	}
+}
+
+func TestEnvToSpec_Health(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"api": Process("/bin/api").
+			Ingress("admin", IngressDef{Protocol: HTTP, Ready: &ReadyDef{Path: "/admin/ready"}}).
+			Ingress("rpc", IngressDef{Protocol: GRPC}).
+			HealthPath("/healthz").
+			HealthStatus(204),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ingresses := spec.Services["api"].Ingresses
+	tests := []struct {
+		ingress    string
+		wantPath   string
+		wantStatus int
+	}{
+		{"default", "/healthz", 204},
+		{"admin", "/admin/ready", 204}, // ingress path wins
+	}
+	for _, tt := range tests {
+		ready := ingresses[tt.ingress].Ready
+		if ready == nil {
+			t.Errorf("%s: no ready spec", tt.ingress)
+			continue
+		}
+		if ready.Path != tt.wantPath || ready.Status != tt.wantStatus {
+			t.Errorf("%s: ready = %s %d, want %s %d", tt.ingress, ready.Path, ready.Status, tt.wantPath, tt.wantStatus)
+		}
+	}
+	if ready := ingresses["rpc"].Ready; ready != nil {
+		t.Errorf("rpc: ready = %+v, want nil for a gRPC ingress", ready)
+	}
+}
+
+func TestEnvToSpec_DockerHealthcheck(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"search": Container("search:latest").Port(9200).UseDockerHealthcheck(),
+		"web":    Container("nginx:alpine").Port(80),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(spec.Services["search"].Config), `{"docker_healthcheck":true,"image":"search:latest"}`; got != want {
+		t.Errorf("search config = %s, want %s", got, want)
+	}
+	if got, want := string(spec.Services["web"].Config), `{"image":"nginx:alpine"}`; got != want {
+		t.Errorf("web config = %s, want %s", got, want)
+	}
+}
+
+func TestEnvToSpec_GoBuildOptions(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"api": Go("/src/cmd/api").BuildTags("integration").BuildFlags("-ldflags", "-X main.version=1.2.3").GoVersion("1.22.5"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"build_flags":["-ldflags","-X main.version=1.2.3"],"build_tags":["integration"],"go_version":"1.22.5","module":"/src/cmd/api"}`
+	if got := string(spec.Services["api"].Config); got != want {
+		t.Errorf("api config = %s, want %s", got, want)
+	}
+}
+
+func TestEnvToSpec_ReadyExec(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"queue": Container("rabbitmq:3").Port(5672).ReadyExec("rabbitmqctl", "status"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(spec.Services["queue"].Config), `{"image":"rabbitmq:3","ready_exec":["rabbitmqctl","status"]}`; got != want {
+		t.Errorf("queue config = %s, want %s", got, want)
+	}
+}
+
+func TestEnvToSpec_ContainerSecurity(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"vpn": Container("vpn").NoIngress().Privileged().
+			CapAdd("NET_ADMIN").CapAdd("SYS_MODULE").
+			Sysctl("net.ipv4.ip_forward", "1"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"cap_add":["NET_ADMIN","SYS_MODULE"],"image":"vpn","privileged":true,"sysctls":{"net.ipv4.ip_forward":"1"}}`
+	if got := string(spec.Services["vpn"].Config); got != want {
+		t.Errorf("vpn config = %s, want %s", got, want)
+	}
+}
+
+func TestEnvToSpec_InitSQLFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "schema.sql")
+	script := `CREATE TABLE users (id SERIAL PRIMARY KEY, name TEXT);
+-- a comment; not a statement
+CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+  NEW.name := 'x;y';
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+`
+	if err := os.WriteFile(file, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := envToSpec("T", Services{
+		"db": Postgres().InitSQL("CREATE EXTENSION pg_trgm").InitSQLFile(file).InitSQL("SELECT 1"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	init := spec.Services["db"].Hooks.Init
+	if len(init) != 3 {
+		t.Fatalf("init hooks = %d, want 3", len(init))
+	}
+	var cfg struct {
+		Statements []string `json:"statements"`
+	}
+	if err := json.Unmarshal(init[1].Config, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"CREATE TABLE users (id SERIAL PRIMARY KEY, name TEXT);",
+		"-- a comment; not a statement\nCREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n  NEW.name := 'x;y';\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;",
+	}
+	if !reflect.DeepEqual(cfg.Statements, want) {
+		t.Errorf("statements = %q, want %q", cfg.Statements, want)
+	}
+	if !strings.Contains(string(init[2].Config), "SELECT 1") {
+		t.Errorf("last init hook = %s, want inline SELECT 1", init[2].Config)
+	}
+}
+
+func TestEnvToSpec_InitSQLFileMissing(t *testing.T) {
+	_, err := envToSpec("T", Services{
+		"db": Postgres().InitSQLFile("testdata/does-not-exist.sql"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err == nil || !strings.Contains(err.Error(), "does-not-exist.sql") {
+		t.Fatalf("err = %v, want error naming the missing file", err)
+	}
+}
+
+func TestSplitSQL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"SELECT 1; SELECT 2", []string{"SELECT 1;", "SELECT 2"}},
+		{"SELECT ';'; ;;", []string{"SELECT ';';"}},
+		{`SELECT "a;b" FROM t; /* x; y */ SELECT $1`, []string{`SELECT "a;b" FROM t;`, "/* x; y */ SELECT $1"}},
+		{"DO $body$ BEGIN PERFORM 1; END $body$; SELECT 2;", []string{"DO $body$ BEGIN PERFORM 1; END $body$;", "SELECT 2;"}},
+	}
+	for _, tt := range tests {
+		if got := splitSQL(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitSQL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEnvToSpec_EgressExternal(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"api": Go("./cmd/api").
+			EgressExternal("payments", "https://sandbox.example.com").
+			Mock(MockResponse{Path: "/refunds", Status: 501}),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eg := spec.Services["api"].Egresses["payments"]
+	if eg.External != "https://sandbox.example.com" || eg.Service != "" {
+		t.Errorf("egress = %+v, want external https://sandbox.example.com", eg)
+	}
+	if len(eg.Mocks) != 1 || eg.Mocks[0].Path != "/refunds" {
+		t.Errorf("mocks = %+v, want the /refunds mock", eg.Mocks)
+	}
+}
+
+func TestEnvToSpec_WaitForGRPCService(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"orders": Go("./cmd/orders").Ingress("default", IngressGRPC()),
+		"api": Go("./cmd/api").
+			Egress("orders").
+			WaitForGRPCService("orders.v1.OrderService").
+			WaitForGRPCService("orders.v1.RefundService"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := spec.Services["api"].Egresses["orders"].WaitForGRPCServices
+	if want := []string{"orders.v1.OrderService", "orders.v1.RefundService"}; !slices.Equal(got, want) {
+		t.Errorf("wait for grpc services = %v, want %v", got, want)
+	}
+}
+
+func TestEnvToSpec_Reuse(t *testing.T) {
+	o := defaultOptions()
+	WithReuse()(&o)
+	spec, err := envToSpec("T", Services{"db": Postgres()}, map[string]hookFunc{}, map[string]startFunc{}, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !spec.Reuse {
+		t.Error("spec.Reuse = false, want true")
+	}
+}
+
+func TestEnvToSpec_Metadata(t *testing.T) {
+	o := defaultOptions()
+	WithMetadata(map[string]string{"pr": "1234", "shard": "1"})(&o)
+	WithMetadata(map[string]string{"shard": "2"})(&o)
+
+	spec, err := envToSpec("T", Services{"api": Process("/bin/api")}, map[string]hookFunc{}, map[string]startFunc{}, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := spec.Metadata; len(got) != 2 || got["pr"] != "1234" || got["shard"] != "2" {
+		t.Errorf("metadata = %v, want pr=1234 shard=2", got)
+	}
+}
+
+func TestFakeClockSetsEnv(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	spec, err := envToSpec("T", Services{
+		"api":    Go("./cmd/api").FakeClock(now).Env("LOG_LEVEL", "debug"),
+		"worker": Process("/bin/worker").FakeClock(now),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, svc := range spec.Services {
+		if got := svc.Env[connect.FakeNowEnv]; got != "2030-01-01T00:00:00Z" {
+			t.Errorf("%s %s = %q, want 2030-01-01T00:00:00Z", name, connect.FakeNowEnv, got)
+		}
+		if strings.Contains(string(svc.Config), `"env"`) {
+			t.Errorf("%s config = %s, want no env", name, svc.Config)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	base := Go("./cmd/api").
+		Env("LOG_LEVEL", "info").
+		EgressAs("db", "postgres").
+		InitHook(func(ctx context.Context, w Wiring) error { return nil })
+	clone := base.Clone().
+		Env("LOG_LEVEL", "debug").
+		Ingress("admin", IngressHTTP()).
+		Egress("cache").
+		InitHook(func(ctx context.Context, w Wiring) error { return nil })
+
+	spec, err := envToSpec("T", Services{
+		"base":  base,
+		"clone": clone,
+		"db":    Postgres(),
+		"cache": Redis(),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, c := spec.Services["base"], spec.Services["clone"]
+	if got := b.Env["LOG_LEVEL"]; got != "info" {
+		t.Errorf("base LOG_LEVEL = %q, want info", got)
+	}
+	if got := c.Env["LOG_LEVEL"]; got != "debug" {
+		t.Errorf("clone LOG_LEVEL = %q, want debug", got)
+	}
+	if len(b.Ingresses) != 1 || len(c.Ingresses) != 2 {
+		t.Errorf("ingresses: base %d, clone %d; want 1, 2", len(b.Ingresses), len(c.Ingresses))
+	}
+	if len(b.Egresses) != 1 || len(c.Egresses) != 2 {
+		t.Errorf("egresses: base %d, clone %d; want 1, 2", len(b.Egresses), len(c.Egresses))
+	}
+	if len(b.Hooks.Init) != 1 || len(c.Hooks.Init) != 2 {
+		t.Errorf("init hooks: base %d, clone %d; want 1, 2", len(b.Hooks.Init), len(c.Hooks.Init))
+	}
+}
+
+func TestEnvToSpec_Scale(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"api":    Go("./cmd/api").Scale(3),
+		"worker": Func(func(context.Context) error { return nil }).NoIngress().Scale(2),
+		"single": Process("/bin/true"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]int{"api": 3, "worker": 2, "single": 0} {
+		if got := spec.Services[name].Scale; got != want {
+			t.Errorf("%s scale = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestEnvToSpec_WaitForTaskQueue(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"temporal": Temporal(),
+		"worker": Go("./cmd/worker").
+			Egress("temporal").
+			WaitForTaskQueue("orders").
+			WaitForTaskQueue("billing", "temporal"),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []specTaskQueueSpec{{Name: "orders"}, {Name: "billing", Egress: "temporal"}}
+	if got := spec.Services["worker"].TaskQueues; !reflect.DeepEqual(got, want) {
+		t.Errorf("task queues = %+v, want %+v", got, want)
+	}
+}
+
+func TestEnvToSpec_TemporalNamespace(t *testing.T) {
+	spec, err := envToSpec("T", Services{
+		"temporal": Temporal().
+			Namespace("orders").
+			SearchAttribute("CustomerId", "Keyword").
+			InitHook(func(context.Context, Wiring) error { return nil }),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := spec.Services["temporal"]
+	if want := `{"namespace":"orders","search_attributes":{"CustomerId":"Keyword"}}`; string(svc.Config) != want {
+		t.Errorf("config = %s, want %s", svc.Config, want)
+	}
+	// The namespace hook runs before the service's own init hooks.
+	if len(svc.Hooks.Init) != 2 || svc.Hooks.Init[0].Type != "temporal" || svc.Hooks.Init[1].Type != "client_func" {
+		t.Errorf("init hooks = %+v, want temporal then client_func", svc.Hooks.Init)
+	}
+
+	spec, err = envToSpec("T", Services{"temporal": Temporal()}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc := spec.Services["temporal"]; svc.Config != nil || svc.Hooks != nil {
+		t.Errorf("plain Temporal() config = %s, hooks = %+v, want neither", svc.Config, svc.Hooks)
+	}
+}
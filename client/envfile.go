@@ -0,0 +1,70 @@
+package rig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readEnvFile loads KEY=VALUE pairs from a dotenv file. Blank lines and
+// lines starting with # are skipped, an optional "export " prefix is
+// allowed, and values may be wrapped in single or double quotes. Unquoted
+// values end at an inline " #" comment. Double-quoted values understand
+// \n, \t, \" and \\ escapes; single-quoted values are taken literally.
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("env file: %w", err)
+	}
+	defer f.Close()
+
+	env := make(map[string]string)
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("env file %s:%d: expected KEY=VALUE, got %q", path, n, sc.Text())
+		}
+		value, err := parseEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("env file %s:%d: %s: %w", path, n, key, err)
+		}
+		env[key] = value
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("env file %s: %w", path, err)
+	}
+	return env, nil
+}
+
+func parseEnvValue(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+	switch q := v[0]; q {
+	case '"', '\'':
+		end := strings.LastIndexByte(v, q)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated %c quote", q)
+		}
+		if rest := strings.TrimSpace(v[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after closing quote", rest)
+		}
+		inner := v[1:end]
+		if q == '\'' {
+			return inner, nil
+		}
+		return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(inner), nil
+	}
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+	return v, nil
+}
@@ -0,0 +1,79 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env.test")
+	content := `# comment
+LOG_LEVEL=debug
+
+export FEATURE_X=on
+GREETING="hello\nworld"
+LITERAL='a\nb # not a comment'
+TRAILING=value # comment
+EMPTY=
+URL=postgres://u:p@host/db?sslmode=disable
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"LOG_LEVEL": "debug",
+		"FEATURE_X": "on",
+		"GREETING":  "hello\nworld",
+		"LITERAL":   `a\nb # not a comment`,
+		"TRAILING":  "value",
+		"EMPTY":     "",
+		"URL":       "postgres://u:p@host/db?sslmode=disable",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readEnvFile = %v, want %v", got, want)
+	}
+}
+
+func TestReadEnvFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := readEnvFile(filepath.Join(dir, "missing.env")); err == nil || !strings.Contains(err.Error(), "missing.env") {
+		t.Errorf("missing file: err = %v, want error naming the file", err)
+	}
+
+	bad := filepath.Join(dir, "bad.env")
+	os.WriteFile(bad, []byte("OK=1\nnot a pair\n"), 0o644)
+	if _, err := readEnvFile(bad); err == nil || !strings.Contains(err.Error(), "bad.env:2") {
+		t.Errorf("malformed line: err = %v, want error with file:line", err)
+	}
+}
+
+func TestEnvToSpec_EnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("LOG_LEVEL=debug\n"), 0o644)
+
+	spec, err := envToSpec("T", Services{
+		"api": Go("./cmd/api").EnvFile(path),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := spec.Services["api"].DotEnv["LOG_LEVEL"]; got != "debug" {
+		t.Errorf("DotEnv[LOG_LEVEL] = %q, want debug", got)
+	}
+
+	_, err = envToSpec("T", Services{
+		"api": Process("/bin/api").EnvFile(filepath.Join(t.TempDir(), "nope")),
+	}, map[string]hookFunc{}, map[string]startFunc{}, options{})
+	if err == nil || !strings.Contains(err.Error(), `service "api"`) {
+		t.Errorf("missing env file: err = %v, want error naming the service", err)
+	}
+}
@@ -1,13 +1,19 @@
 package rig
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"sort"
 	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/connect"
 )
 
 // Environment is the resolved, running environment returned by Up.
-// It provides methods to look up service endpoints.
+// It provides methods to look up service endpoints. An environment from
+// Start has no Services or EnvDir until WaitReady returns nil.
 type Environment struct {
 	ID       string
 	Name     string
@@ -19,7 +25,62 @@ type Environment struct {
 	// the rig event log. Pass env.T to assertion libraries (testify,
 	// is, require, etc.) so failures appear in the event timeline
 	// alongside server-side events. File:line reporting is preserved.
+	// Use TB for the structured assertion helpers.
 	T testing.TB
+
+	serverURL string    // rigd base URL, used to query the event log
+	logFile   string    // persisted JSONL event log, set on teardown
+	teardown  *teardown // shared by Close and the test cleanup
+	startup   *startup  // closed once the environment is up or has failed
+}
+
+// TB returns env.T as a *TB, for its assertion helpers (Equal,
+// AssertStatus, AssertEventually). If T has been replaced, the replacement
+// is wrapped so failures still reach the event log.
+//
+//	env.TB().Equal(200, resp.StatusCode)
+func (e *Environment) TB() *TB {
+	if tb, ok := e.T.(*TB); ok {
+		return tb
+	}
+	return &TB{TB: e.T, serverURL: e.serverURL, envID: e.ID}
+}
+
+// WaitReady blocks until every service in an environment from Start is
+// ready, returning the startup error if one failed or the startup timeout
+// expired first. It returns ctx's error if ctx is done first; the startup
+// carries on and WaitReady may be called again. Once startup has finished,
+// WaitReady returns its result immediately.
+func (e *Environment) WaitReady(ctx context.Context) error {
+	if e.startup == nil {
+		return nil
+	}
+	select {
+	case <-e.startup.done:
+		return e.startup.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close tears the environment down and writes its event log, the same as
+// the cleanup Up registers. Use it when the environment's lifetime isn't a
+// single test, e.g. one environment shared from TestMain or held by a dev
+// tool. Set RIG_PRESERVE=true to keep the environment dir.
+//
+// Close is idempotent: the first call does the teardown, and calling it
+// again — or the test cleanup running afterwards — is a no-op that returns
+// nil. Environments with a TTL are destroyed by Close too.
+func (e *Environment) Close() error {
+	if e.teardown == nil {
+		return nil
+	}
+	result, err := e.teardown.close(os.Getenv("RIG_PRESERVE") == "true", false)
+	if err != nil {
+		return err
+	}
+	e.logFile = result.LogFile
+	return nil
 }
 
 // ResolvedService holds the resolved endpoints for a single service.
@@ -32,11 +93,28 @@ type ResolvedService struct {
 // ingress, it is returned regardless of its name.
 //
 // Panics with a descriptive message if the service or ingress is not found.
+// Use LookupEndpoint to handle a miss as an error instead.
 func (e *Environment) Endpoint(service string, ingress ...string) Endpoint {
+	ep, err := e.LookupEndpoint(service, ingress...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return ep
+}
+
+// LookupEndpoint is like Endpoint but returns an error instead of panicking
+// when the service or ingress is not found. The error lists what is
+// available and suggests a close name for likely typos:
+//
+//	ep, err := env.LookupEndpoint(tc.service)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+func (e *Environment) LookupEndpoint(service string, ingress ...string) (Endpoint, error) {
 	svc, ok := e.Services[service]
 	if !ok {
-		panic(fmt.Sprintf("rig: service %q not found in environment %q (available: %s)",
-			service, e.Name, sortedKeys(e.Services)))
+		return Endpoint{}, fmt.Errorf("rig: service %q not found in environment %q%s (available: %s)",
+			service, e.Name, didYouMean(service, e.Services), sortedKeys(e.Services))
 	}
 
 	ingressName := "default"
@@ -48,16 +126,24 @@ func (e *Environment) Endpoint(service string, ingress ...string) Endpoint {
 	// and no specific name was requested, return it.
 	if ingressName == "default" && len(svc.Ingresses) == 1 {
 		for _, ep := range svc.Ingresses {
-			return ep
+			return ep, nil
 		}
 	}
 
 	ep, ok := svc.Ingresses[ingressName]
 	if !ok {
-		panic(fmt.Sprintf("rig: ingress %q not found on service %q (available: %s)",
-			ingressName, service, sortedKeys(svc.Ingresses)))
+		return Endpoint{}, fmt.Errorf("rig: ingress %q not found on service %q%s (available: %s)",
+			ingressName, service, didYouMean(ingressName, svc.Ingresses), sortedKeys(svc.Ingresses))
 	}
-	return ep
+	return ep, nil
+}
+
+// AdvanceClock moves every fake clock in the environment forward by d.
+// Services configured with FakeClock observe the change on their next
+// connect.Now call.
+func (e *Environment) AdvanceClock(d time.Duration) error {
+	ctx := connect.WithWiring(context.Background(), &connect.Wiring{EnvDir: e.EnvDir})
+	return connect.Advance(ctx, d)
 }
 
 func sortedKeys[V any](m map[string]V) string {
@@ -68,3 +154,58 @@ func sortedKeys[V any](m map[string]V) string {
 	sort.Strings(keys)
 	return fmt.Sprintf("%v", keys)
 }
+
+// didYouMean returns a " (did you mean %q?)" hint naming the key of m
+// closest to name by edit distance, or "" if none is close enough. It
+// mirrors the suggestions the server gives for egress typos.
+func didYouMean[V any](name string, m map[string]V) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // deterministic choice between equally close names
+
+	best := ""
+	bestDist := len(name)/2 + 1 // threshold: must be within half the length
+	for _, k := range keys {
+		if d := editDistance(name, k); d < bestDist {
+			bestDist = d
+			best = k
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	if len(a) == 0 {
+		return len(b)
+	}
+	if len(b) == 0 {
+		return len(a)
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(curr[j-1]+1, min(prev[j]+1, prev[j-1]+cost))
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
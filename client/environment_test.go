@@ -0,0 +1,65 @@
+package rig
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEnvironment_Close(t *testing.T) {
+	var deletes int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/environments/env-1" {
+			http.NotFound(w, r)
+			return
+		}
+		deletes++
+		if r.URL.Query().Get("reason") != "" {
+			t.Errorf("Close sent reason=%q, want none", r.URL.Query().Get("reason"))
+		}
+		if deletes > 1 {
+			http.Error(w, "environment not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"log_file":"/tmp/env-1.jsonl"}`)
+	}))
+	defer ts.Close()
+
+	var cancelled bool
+	env := &Environment{ID: "env-1", teardown: &teardown{
+		serverURL:  ts.URL,
+		envID:      "env-1",
+		funcCancel: func() { cancelled = true },
+	}}
+
+	if err := env.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !cancelled {
+		t.Error("Close did not stop client-side functions")
+	}
+	if env.logFile != "/tmp/env-1.jsonl" {
+		t.Errorf("logFile = %q, want /tmp/env-1.jsonl", env.logFile)
+	}
+	if err := env.Close(); err != nil {
+		t.Errorf("second Close: %v, want nil", err)
+	}
+	if deletes != 1 {
+		t.Errorf("DELETE sent %d times, want 1", deletes)
+	}
+}
+
+func TestEnvironment_CloseError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	env := &Environment{ID: "env-1", teardown: &teardown{serverURL: ts.URL, envID: "env-1", funcCancel: func() {}}}
+	err := env.Close()
+	if err == nil || !strings.Contains(err.Error(), "HTTP 500: boom") {
+		t.Errorf("Close err = %v, want HTTP 500", err)
+	}
+}
@@ -0,0 +1,153 @@
+package rig
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// WithTrafficGolden snapshots the environment's observed traffic into a
+// golden file. At cleanup the distinct calls between services — edge,
+// method, path template and status — are compared against path, and the
+// test fails if they differ. Volatile details (latency, sizes, IDs in
+// paths, query strings) are stripped so the snapshot only changes when
+// the call pattern does.
+//
+// Regenerate the file by setting RIG_UPDATE_GOLDEN=true, or by running
+// the test binary with -update if the test package defines that flag.
+// Requires observe (the default).
+func WithTrafficGolden(path string) Option {
+	return func(o *options) { o.trafficGolden = path }
+}
+
+// checkTrafficGolden compares the environment's normalized traffic with the
+// golden file at path, or rewrites the file when updating.
+func checkTrafficGolden(t testing.TB, env *Environment, path string) {
+	t.Helper()
+	events, err := env.fetchEvents()
+	if err != nil {
+		t.Errorf("rig: traffic golden: %v", err)
+		return
+	}
+	got := normalizeTraffic(events)
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Errorf("rig: traffic golden: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Errorf("rig: traffic golden: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Errorf("rig: traffic golden %s does not exist; rerun with RIG_UPDATE_GOLDEN=true to create it", path)
+		return
+	}
+	if err != nil {
+		t.Errorf("rig: traffic golden: %v", err)
+		return
+	}
+	if diff := diffLines(string(want), got); diff != "" {
+		t.Errorf("rig: traffic differs from golden %s (rerun with RIG_UPDATE_GOLDEN=true to accept):\n%s", path, diff)
+	}
+}
+
+// updateGolden reports whether golden files should be rewritten.
+func updateGolden() bool {
+	if v, _ := strconv.ParseBool(os.Getenv("RIG_UPDATE_GOLDEN")); v {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		v, _ := strconv.ParseBool(f.Value.String())
+		return v
+	}
+	return false
+}
+
+// volatileSegment matches path segments that identify a specific resource
+// rather than a route: numbers, UUIDs, and long hex strings.
+var volatileSegment = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// normalizeTraffic renders the distinct HTTP and gRPC calls in events as
+// sorted lines, one per call pattern.
+func normalizeTraffic(events []wireEvent) string {
+	seen := map[string]bool{}
+	for _, ev := range events {
+		var line string
+		switch {
+		case ev.Type == "request.completed" && ev.Request != nil:
+			r := ev.Request
+			line = fmt.Sprintf("%s → %s  %s %s  %d", r.Source, r.Target, r.Method, pathTemplate(r.Path), r.StatusCode)
+		case ev.Type == "grpc.call.completed" && ev.GRPCCall != nil:
+			g := ev.GRPCCall
+			line = fmt.Sprintf("%s → %s  gRPC %s/%s  %s", g.Source, g.Target, g.Service, g.Method, g.GRPCStatus)
+		default:
+			continue
+		}
+		seen[line] = true
+	}
+	lines := make([]string, 0, len(seen))
+	for l := range seen {
+		lines = append(lines, l)
+	}
+	sort.Strings(lines)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// pathTemplate strips the query string and replaces ID-like segments
+// with {id}.
+func pathTemplate(p string) string {
+	if i := strings.IndexByte(p, '?'); i >= 0 {
+		p = p[:i]
+	}
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		if volatileSegment.MatchString(s) {
+			segs[i] = "{id}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// diffLines returns a "-"/"+" listing of lines missing from got and lines
+// not in want, or "" if the sets are identical.
+func diffLines(want, got string) string {
+	wantSet := lineSet(want)
+	gotSet := lineSet(got)
+	var out []string
+	for l := range wantSet {
+		if !gotSet[l] {
+			out = append(out, "- "+l)
+		}
+	}
+	for l := range gotSet {
+		if !wantSet[l] {
+			out = append(out, "+ "+l)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][2:] < out[j][2:] })
+	return strings.Join(out, "\n")
+}
+
+func lineSet(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			set[l] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,90 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPathTemplate(t *testing.T) {
+	tests := map[string]string{
+		"/orders":                   "/orders",
+		"/orders/123":               "/orders/{id}",
+		"/orders/123/items?limit=5": "/orders/{id}/items",
+		"/users/6f1c2a9e-8b1d-4c3e-9f2a-0d4e5b6c7a8b": "/users/{id}",
+		"/blobs/0123456789abcdef0123":                 "/blobs/{id}",
+		"/v1/health":                                  "/v1/health",
+	}
+	for in, want := range tests {
+		if got := pathTemplate(in); got != want {
+			t.Errorf("pathTemplate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCheckTrafficGolden(t *testing.T) {
+	ts := fakeLogServer(t, []map[string]any{
+		{"type": "request.completed", "request": map[string]any{
+			"source": "~test", "target": "api", "method": "POST", "path": "/orders", "status_code": 201, "latency_ms": 3.2,
+		}},
+		{"type": "request.completed", "request": map[string]any{
+			"source": "api", "target": "stock", "method": "GET", "path": "/items/42", "status_code": 200, "latency_ms": 1.1,
+		}},
+		{"type": "request.completed", "request": map[string]any{
+			"source": "api", "target": "stock", "method": "GET", "path": "/items/43", "status_code": 200, "latency_ms": 0.9,
+		}},
+		{"type": "grpc.call.completed", "grpc_call": map[string]any{
+			"source": "api", "target": "temporal", "service": "WorkflowService", "method": "Start", "grpc_status": "OK",
+		}},
+	})
+	env := &Environment{ID: "env-1", serverURL: ts.URL}
+	path := filepath.Join(t.TempDir(), "testdata", "flow.golden")
+
+	// Missing golden file fails with a hint.
+	rec := &recordTB{TB: t}
+	checkTrafficGolden(rec, env, path)
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "RIG_UPDATE_GOLDEN") {
+		t.Fatalf("errors = %v, want missing-file hint", rec.errors)
+	}
+
+	// Update writes the normalized snapshot.
+	t.Setenv("RIG_UPDATE_GOLDEN", "true")
+	rec = &recordTB{TB: t}
+	checkTrafficGolden(rec, env, path)
+	if len(rec.errors) > 0 {
+		t.Fatalf("update: %v", rec.errors)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"api → stock  GET /items/{id}  200\n" +
+		"api → temporal  gRPC WorkflowService/Start  OK\n" +
+		"~test → api  POST /orders  201\n"
+	if string(data) != want {
+		t.Fatalf("golden =\n%s\nwant\n%s", data, want)
+	}
+
+	// Matching snapshot passes.
+	t.Setenv("RIG_UPDATE_GOLDEN", "")
+	rec = &recordTB{TB: t}
+	checkTrafficGolden(rec, env, path)
+	if len(rec.errors) > 0 {
+		t.Fatalf("compare: %v", rec.errors)
+	}
+
+	// A changed call pattern is reported as a diff.
+	os.WriteFile(path, []byte("api → stock  GET /items/{id}  200\napi → billing  POST /charge  200\n"), 0o644)
+	rec = &recordTB{TB: t}
+	checkTrafficGolden(rec, env, path)
+	if len(rec.errors) != 1 {
+		t.Fatalf("errors = %v, want one diff", rec.errors)
+	}
+	for _, line := range []string{"- api → billing  POST /charge  200", "+ ~test → api  POST /orders  201"} {
+		if !strings.Contains(rec.errors[0], line) {
+			t.Errorf("diff missing %q:\n%s", line, rec.errors[0])
+		}
+	}
+}
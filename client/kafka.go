@@ -5,13 +5,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // KafkaDef defines a service backed by the builtin Kafka type (Redpanda).
 // Each test gets a fresh container — no pool, no topic collision.
 //
 // The service exposes two ingresses:
-//   - "default" (Kafka protocol on port 9092) — use ep.HostPort as bootstrap servers
+//   - "default" (Kafka protocol on port 9092) — KAFKA_BROKERS (= ep.HostPort)
+//     holds the bootstrap servers
 //   - "schema-registry" (HTTP on port 8081) — Confluent-compatible schema registry
 //
 // From tests, access them via the environment:
@@ -25,9 +27,11 @@ import (
 //	    Egress("kafka").                                       // → default ingress
 //	    EgressAs("schema-registry", "kafka", "schema-registry") // → schema-registry ingress
 type KafkaDef struct {
-	image    string
-	egresses map[string]egressDef
-	hooks    hooksDef
+	image     string
+	egresses  map[string]egressDef
+	hooks     hooksDef
+	timeout   time.Duration
+	dependsOn []string
 }
 
 func (*KafkaDef) rigService() {}
@@ -37,6 +41,7 @@ func (*KafkaDef) rigService() {}
 //	rig.Kafka()
 //	rig.Kafka().Image("redpandadata/redpanda:v24.1.1")
 //	rig.Kafka().AvroSchema("schemas/user-value.avsc")
+//	rig.Kafka().Topics("orders", "events")
 func Kafka() *KafkaDef {
 	return &KafkaDef{}
 }
@@ -77,6 +82,32 @@ func (d *KafkaDef) PrestartHook(fn func(ctx context.Context, w Wiring) error) *K
 	return d
 }
 
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *KafkaDef) DependsOn(services ...string) *KafkaDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *KafkaDef) Timeout(timeout time.Duration) *KafkaDef {
+	d.timeout = timeout
+	return d
+}
+
+// Topics pre-creates topics during init, before the service is reported
+// ready to its dependents. Each call adds one `rpk topic create` exec hook.
+//
+//	rig.Kafka().Topics("orders", "events")
+func (d *KafkaDef) Topics(topics ...string) *KafkaDef {
+	if len(topics) == 0 {
+		return d
+	}
+	cmd := append([]string{"rpk", "topic", "create"}, topics...)
+	d.hooks.init = append(d.hooks.init, execHook{command: cmd})
+	return d
+}
+
 // AvroSchema registers an Avro schema file to be posted to the schema registry
 // during init. The subject name is derived from the filename (sans extension):
 // "user-value.avsc" → subject "user-value".
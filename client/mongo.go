@@ -0,0 +1,92 @@
+package rig
+
+import (
+	"context"
+	"time"
+)
+
+// MongoDef defines a service backed by the builtin Mongo type. Each test
+// gets a fresh mongod container — no pool, no collection collisions.
+//
+// Publishes MONGO_URI (mongodb://host:port/test) and MONGO_DATABASE as
+// endpoint attributes. Traffic through the observe proxy is decoded: each
+// command is recorded as a mongo.command event with its name, collection,
+// and latency.
+type MongoDef struct {
+	image     string
+	egresses  map[string]egressDef
+	hooks     hooksDef
+	timeout   time.Duration
+	dependsOn []string
+}
+
+func (*MongoDef) rigService() {}
+
+// Mongo creates a MongoDB service definition. By default uses mongo:7.
+//
+//	rig.Mongo()
+//	rig.Mongo().Image("mongo:6")
+func Mongo() *MongoDef {
+	return &MongoDef{}
+}
+
+// Image overrides the default MongoDB Docker image (mongo:7). The image
+// must include mongosh, which the ready check and InitScript use.
+func (d *MongoDef) Image(image string) *MongoDef {
+	d.image = image
+	return d
+}
+
+// Egress adds a dependency on a service, named after the target.
+func (d *MongoDef) Egress(service string) *MongoDef {
+	return d.EgressAs(service, service)
+}
+
+// EgressAs adds a dependency with a custom local name.
+func (d *MongoDef) EgressAs(name, service string, ingress ...string) *MongoDef {
+	if d.egresses == nil {
+		d.egresses = make(map[string]egressDef)
+	}
+	eg := egressDef{service: service}
+	if len(ingress) > 0 {
+		eg.ingress = ingress[0]
+	}
+	d.egresses[name] = eg
+	return d
+}
+
+// InitScript registers JavaScript to run with mongosh against the test
+// database once MongoDB answers ping, before the service is marked ready.
+// Scripts run server-side — no MongoDB driver needed in the test process.
+// A script that throws fails Up. Can be called multiple times.
+//
+//	rig.Mongo().InitScript(`db.users.insertOne({name: "Ada"})`)
+func (d *MongoDef) InitScript(js string) *MongoDef {
+	d.hooks.init = append(d.hooks.init, mongoHook{script: js})
+	return d
+}
+
+// InitHook registers a client-side init hook function.
+func (d *MongoDef) InitHook(fn func(ctx context.Context, w Wiring) error) *MongoDef {
+	d.hooks.init = append(d.hooks.init, hookFunc(fn))
+	return d
+}
+
+// PrestartHook registers a client-side prestart hook function.
+func (d *MongoDef) PrestartHook(fn func(ctx context.Context, w Wiring) error) *MongoDef {
+	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
+	return d
+}
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *MongoDef) DependsOn(services ...string) *MongoDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *MongoDef) Timeout(timeout time.Duration) *MongoDef {
+	d.timeout = timeout
+	return d
+}
@@ -0,0 +1,98 @@
+package rig
+
+import (
+	"context"
+	"time"
+)
+
+// MySQLDef defines a service backed by the builtin MySQL type.
+// Rig manages the database name, user, and password — the API is minimal.
+//
+// Publishes MYSQL_HOST, MYSQL_PORT, MYSQL_USER, MYSQL_PASSWORD, and
+// MYSQL_DATABASE as endpoint attributes.
+type MySQLDef struct {
+	image     string
+	egresses  map[string]egressDef
+	hooks     hooksDef
+	timeout   time.Duration
+	dependsOn []string
+}
+
+func (*MySQLDef) rigService() {}
+
+// MySQL creates a MySQL service definition. By default uses mysql:8.
+// Each environment gets an isolated database in a shared container,
+// with user/password "root"/"mysql".
+//
+//	rig.MySQL()
+//	rig.MySQL().Image("mysql:8.4")
+func MySQL() *MySQLDef {
+	return &MySQLDef{}
+}
+
+// Image overrides the default MySQL Docker image (mysql:8).
+func (d *MySQLDef) Image(image string) *MySQLDef {
+	d.image = image
+	return d
+}
+
+// Egress adds a dependency on a service, named after the target.
+func (d *MySQLDef) Egress(service string) *MySQLDef {
+	return d.EgressAs(service, service)
+}
+
+// EgressAs adds a dependency with a custom local name.
+func (d *MySQLDef) EgressAs(name, service string, ingress ...string) *MySQLDef {
+	if d.egresses == nil {
+		d.egresses = make(map[string]egressDef)
+	}
+	eg := egressDef{service: service}
+	if len(ingress) > 0 {
+		eg.ingress = ingress[0]
+	}
+	d.egresses[name] = eg
+	return d
+}
+
+// InitSQL registers SQL statements to run via the mysql client against the
+// environment's database once MySQL accepts logins. Statements are executed
+// server-side via docker exec — no SQL driver needed in the test process.
+// Can be called multiple times.
+//
+//	rig.MySQL().InitSQL("CREATE TABLE users (id INT AUTO_INCREMENT PRIMARY KEY, name TEXT NOT NULL)")
+func (d *MySQLDef) InitSQL(statements ...string) *MySQLDef {
+	d.hooks.init = append(d.hooks.init, sqlHook{statements: statements})
+	return d
+}
+
+// Exec registers an exec init hook that runs a command inside the container
+// after it becomes healthy. The command is executed server-side via docker exec.
+func (d *MySQLDef) Exec(cmd ...string) *MySQLDef {
+	d.hooks.init = append(d.hooks.init, execHook{command: cmd})
+	return d
+}
+
+// InitHook registers a client-side init hook function.
+func (d *MySQLDef) InitHook(fn func(ctx context.Context, w Wiring) error) *MySQLDef {
+	d.hooks.init = append(d.hooks.init, hookFunc(fn))
+	return d
+}
+
+// PrestartHook registers a client-side prestart hook function.
+func (d *MySQLDef) PrestartHook(fn func(ctx context.Context, w Wiring) error) *MySQLDef {
+	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
+	return d
+}
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *MySQLDef) DependsOn(services ...string) *MySQLDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *MySQLDef) Timeout(timeout time.Duration) *MySQLDef {
+	d.timeout = timeout
+	return d
+}
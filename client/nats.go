@@ -0,0 +1,79 @@
+package rig
+
+import (
+	"context"
+	"time"
+)
+
+// NATSDef defines a service backed by the builtin NATS type. Each test gets
+// a fresh nats-server container — no pool, no subject collision.
+//
+// Publishes NATS_URL (nats://host:port) as an endpoint attribute. Traffic
+// through the observe proxy is decoded: each PUB, SUB, and delivered MSG is
+// recorded as a nats.message event with its subject and payload size.
+type NATSDef struct {
+	image     string
+	egresses  map[string]egressDef
+	hooks     hooksDef
+	timeout   time.Duration
+	dependsOn []string
+}
+
+func (*NATSDef) rigService() {}
+
+// NATS creates a NATS service definition. By default uses nats:latest.
+//
+//	rig.NATS()
+//	rig.NATS().Image("nats:2.10-alpine")
+func NATS() *NATSDef {
+	return &NATSDef{}
+}
+
+// Image overrides the default NATS Docker image (nats:latest).
+func (d *NATSDef) Image(image string) *NATSDef {
+	d.image = image
+	return d
+}
+
+// Egress adds a dependency on a service, named after the target.
+func (d *NATSDef) Egress(service string) *NATSDef {
+	return d.EgressAs(service, service)
+}
+
+// EgressAs adds a dependency with a custom local name.
+func (d *NATSDef) EgressAs(name, service string, ingress ...string) *NATSDef {
+	if d.egresses == nil {
+		d.egresses = make(map[string]egressDef)
+	}
+	eg := egressDef{service: service}
+	if len(ingress) > 0 {
+		eg.ingress = ingress[0]
+	}
+	d.egresses[name] = eg
+	return d
+}
+
+// InitHook registers a client-side init hook function.
+func (d *NATSDef) InitHook(fn func(ctx context.Context, w Wiring) error) *NATSDef {
+	d.hooks.init = append(d.hooks.init, hookFunc(fn))
+	return d
+}
+
+// PrestartHook registers a client-side prestart hook function.
+func (d *NATSDef) PrestartHook(fn func(ctx context.Context, w Wiring) error) *NATSDef {
+	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
+	return d
+}
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *NATSDef) DependsOn(services ...string) *NATSDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *NATSDef) Timeout(timeout time.Duration) *NATSDef {
+	d.timeout = timeout
+	return d
+}
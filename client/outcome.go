@@ -0,0 +1,128 @@
+package rig
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Outcome is the structured result of an environment, for harnesses that
+// embed rig outside `go test` and need more than the failure message.
+type Outcome struct {
+	// Status is "passed", "failed" (a test assertion failed), or "crashed"
+	// (a service failed). While the environment is still up it reflects
+	// the events so far.
+	Status string `json:"status"`
+
+	// Services maps each service to its last lifecycle status, e.g.
+	// "ready", "failed", or "stopped". Proxy and test nodes are omitted.
+	Services map[string]string `json:"services"`
+
+	// Failures lists the causes that brought the environment down, in order.
+	Failures []string `json:"failures,omitempty"`
+
+	// ExitCodes holds the first exit code of each service that crashed.
+	ExitCodes map[string]int `json:"exit_codes,omitempty"`
+
+	// Summary is the human-readable failure summary the server attaches to
+	// environment.down. Empty for passing or still-running environments.
+	Summary string `json:"summary,omitempty"`
+
+	// LogFile is the persisted JSONL event log. Set once the environment
+	// has been torn down.
+	LogFile string `json:"log_file,omitempty"`
+}
+
+// Outcome returns the environment's outcome. While the environment is up
+// it is read from rigd; after teardown it is read from the persisted event
+// log, so it can be called once the test's cleanup has run.
+func (e *Environment) Outcome() (Outcome, error) {
+	var events []wireEvent
+	var err error
+	if e.logFile != "" {
+		events, err = readEventLog(e.logFile)
+	} else {
+		events, err = e.fetchEvents()
+	}
+	if err != nil {
+		return Outcome{}, err
+	}
+	o := deriveOutcome(events)
+	o.LogFile = e.logFile
+	return o, nil
+}
+
+// deriveOutcome folds an event log into an Outcome. The status comes from
+// the server (environment.down or the log header) when present, and is
+// otherwise derived the way the server does: a failing environment has
+// crashed, a test note means the test failed.
+func deriveOutcome(events []wireEvent) Outcome {
+	o := Outcome{Services: map[string]string{}}
+	var failing, noted bool
+	for _, ev := range events {
+		switch {
+		case ev.Type == "environment.failing":
+			failing = true
+			o.Failures = append(o.Failures, ev.Error)
+		case ev.Type == "test.note":
+			noted = true
+		case ev.Type == "environment.down" || ev.Type == "log.header":
+			if ev.Outcome != "" {
+				o.Status = ev.Outcome
+			}
+			if ev.Type == "environment.down" {
+				o.Summary = ev.Message
+			}
+		case strings.HasPrefix(ev.Type, "service.") && ev.Type != "service.log":
+			if ev.Service == "" || strings.Contains(ev.Service, "~") {
+				continue
+			}
+			o.Services[ev.Service] = strings.TrimPrefix(ev.Type, "service.")
+			if ev.Type == "service.failed" && ev.ExitCode != nil {
+				if _, ok := o.ExitCodes[ev.Service]; !ok {
+					if o.ExitCodes == nil {
+						o.ExitCodes = map[string]int{}
+					}
+					o.ExitCodes[ev.Service] = *ev.ExitCode
+				}
+			}
+		}
+	}
+	if o.Status == "" {
+		switch {
+		case failing:
+			o.Status = "crashed"
+		case noted:
+			o.Status = "failed"
+		default:
+			o.Status = "passed"
+		}
+	}
+	return o
+}
+
+// readEventLog reads a persisted JSONL event log, header line included.
+func readEventLog(path string) ([]wireEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read event log: %w", err)
+	}
+	defer f.Close()
+
+	var events []wireEvent
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var ev wireEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("read event log %s: %w", path, err)
+		}
+		events = append(events, ev)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read event log %s: %w", path, err)
+	}
+	return events, nil
+}
@@ -0,0 +1,63 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOutcome_Running(t *testing.T) {
+	ts := fakeLogServer(t, []map[string]any{
+		{"type": "service.starting", "service": "db"},
+		{"type": "service.ready", "service": "db"},
+		{"type": "service.ready", "service": "db~proxy~api"},
+		{"type": "service.ready", "service": "api"},
+		{"type": "test.note", "error": "want 200, got 500"},
+	})
+	env := &Environment{ID: "env-1", serverURL: ts.URL}
+
+	o, err := env.Outcome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Outcome{
+		Status:   "failed",
+		Services: map[string]string{"db": "ready", "api": "ready"},
+	}
+	if !reflect.DeepEqual(o, want) {
+		t.Errorf("Outcome = %+v, want %+v", o, want)
+	}
+}
+
+func TestOutcome_AfterTeardown(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "TestOrders-abc.jsonl")
+	lines := `{"type":"log.header","environment":"TestOrders","outcome":"crashed"}
+{"type":"service.ready","service":"db"}
+{"type":"service.failed","service":"api","error":"exit status 2","exit_code":2}
+{"type":"environment.failing","service":"api","error":"service \"api\": exit status 2"}
+{"type":"service.stopped","service":"db"}
+{"type":"environment.down","message":"environment failed:\n  service \"api\": exit status 2","outcome":"crashed"}
+`
+	if err := os.WriteFile(logFile, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// No server: after teardown the outcome comes from the log file alone.
+	env := &Environment{ID: "env-1", logFile: logFile}
+
+	o, err := env.Outcome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Outcome{
+		Status:    "crashed",
+		Services:  map[string]string{"db": "stopped", "api": "failed"},
+		Failures:  []string{`service "api": exit status 2`},
+		ExitCodes: map[string]int{"api": 2},
+		Summary:   "environment failed:\n  service \"api\": exit status 2",
+		LogFile:   logFile,
+	}
+	if !reflect.DeepEqual(o, want) {
+		t.Errorf("Outcome = %+v, want %+v", o, want)
+	}
+}
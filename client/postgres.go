@@ -6,14 +6,20 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 )
 
 // PostgresDef defines a service backed by the builtin Postgres type.
 // Rig manages the database name, user, and password — the API is minimal.
 type PostgresDef struct {
-	image    string
-	egresses map[string]egressDef
-	hooks    hooksDef
+	image          string
+	databases      []string
+	egresses       map[string]egressDef
+	hooks          hooksDef
+	timeout        time.Duration
+	dependsOn      []string
+	logStatements  bool
+	logMinDuration *time.Duration
 }
 
 func (*PostgresDef) rigService() {}
@@ -34,6 +40,40 @@ func (d *PostgresDef) Image(image string) *PostgresDef {
 	return d
 }
 
+// Databases declares additional logical databases on the service, mirroring
+// a production cluster that hosts one database per service. Each is created
+// alongside the service's own database and dropped with it; its name is
+// published in the PGDATABASE_{NAME} attribute (e.g. PGDATABASE_ORDERS).
+// Consumers select one with Database on their egress, and InitSQLIn
+// creates schema inside it:
+//
+//	"postgres": rig.Postgres().Databases("orders", "billing").
+//	    InitSQLIn("orders", "CREATE TABLE orders (id SERIAL PRIMARY KEY)"),
+//	"orders": rig.Go("./cmd/orders").EgressAs("db", "postgres").Database("orders"),
+func (d *PostgresDef) Databases(names ...string) *PostgresDef {
+	d.databases = append(d.databases, names...)
+	return d
+}
+
+// LogStatements logs every SQL statement run against the test's databases
+// (log_statement=all) as service.log lines, so a failing test shows the
+// queries behind it and rig explain can pair an error response with the
+// SQL that failed. Off by default because it is noisy.
+//
+//	rig.Postgres().LogStatements()
+func (d *PostgresDef) LogStatements() *PostgresDef {
+	d.logStatements = true
+	return d
+}
+
+// LogSlowStatements logs statements that take at least threshold, with
+// their duration (log_min_duration_statement). Zero logs the duration of
+// every statement. It can be combined with LogStatements.
+func (d *PostgresDef) LogSlowStatements(threshold time.Duration) *PostgresDef {
+	d.logMinDuration = &threshold
+	return d
+}
+
 // Egress adds a dependency on a service, named after the target.
 func (d *PostgresDef) Egress(service string) *PostgresDef {
 	return d.EgressAs(service, service)
@@ -62,6 +102,13 @@ func (d *PostgresDef) InitSQL(statements ...string) *PostgresDef {
 	return d
 }
 
+// InitSQLIn is InitSQL for one of the logical databases declared with
+// Databases.
+func (d *PostgresDef) InitSQLIn(database string, statements ...string) *PostgresDef {
+	d.hooks.init = append(d.hooks.init, sqlHook{statements: statements, database: database})
+	return d
+}
+
 // InitSQLDir reads all .sql files from a directory, sorts them by filename,
 // and registers them as SQL init hooks. This is the directory-based equivalent
 // of InitSQL — use it with ordered migration files:
@@ -102,6 +149,30 @@ func (d *PostgresDef) InitSQLDir(dir string) *PostgresDef {
 	return d
 }
 
+// InitSQLFile registers SQL files to run as init statements, in the order
+// given and in order with any InitSQL calls around it:
+//
+//	rig.Postgres().InitSQLFile("testdata/schema.sql", "testdata/seed.sql")
+//
+// Paths are resolved relative to the working directory at call time, but
+// the files are read when the environment is brought up, so a missing file
+// fails Up before any container starts. Each file is split into statements
+// on top-level semicolons; semicolons inside quotes, comments and
+// dollar-quoted bodies ($$ ... $$) do not end a statement.
+func (d *PostgresDef) InitSQLFile(paths ...string) *PostgresDef {
+	abs := make([]string, len(paths))
+	for i, p := range paths {
+		abs[i] = p
+		if !filepath.IsAbs(p) {
+			if wd, err := os.Getwd(); err == nil {
+				abs[i] = filepath.Join(wd, p)
+			}
+		}
+	}
+	d.hooks.init = append(d.hooks.init, sqlFileHook{paths: abs})
+	return d
+}
+
 // Exec registers an exec init hook that runs a command inside the container
 // after it becomes healthy. The command is executed server-side via docker exec.
 //
@@ -122,3 +193,94 @@ func (d *PostgresDef) PrestartHook(fn func(ctx context.Context, w Wiring) error)
 	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
 	return d
 }
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *PostgresDef) DependsOn(services ...string) *PostgresDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *PostgresDef) Timeout(timeout time.Duration) *PostgresDef {
+	d.timeout = timeout
+	return d
+}
+
+// splitSQL splits a SQL script into statements on top-level semicolons.
+// Semicolons inside string literals, quoted identifiers, comments and
+// dollar-quoted bodies are part of the statement. Blank statements are
+// dropped and the terminating semicolon is kept.
+func splitSQL(script string) []string {
+	var stmts []string
+	start := 0
+	flush := func(end int) {
+		if s := strings.TrimSpace(script[start:end]); s != "" && s != ";" {
+			stmts = append(stmts, s)
+		}
+		start = end
+	}
+	for i := 0; i < len(script); {
+		switch c := script[i]; {
+		case c == '\'' || c == '"':
+			// Doubled quotes escape themselves, so scanning to the next
+			// quote and resuming handles them as two adjacent literals.
+			end := strings.IndexByte(script[i+1:], c)
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 2
+			}
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 1
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 4
+			}
+		case c == '$':
+			tag, ok := dollarTag(script[i:])
+			if !ok {
+				i++
+				continue
+			}
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += len(tag) + end + len(tag)
+			}
+		case c == ';':
+			i++
+			flush(i)
+		default:
+			i++
+		}
+	}
+	flush(len(script))
+	return stmts
+}
+
+// dollarTag returns the opening dollar-quote tag ($$ or $name$) at the
+// start of s. Positional parameters like $1 are not tags.
+func dollarTag(s string) (string, bool) {
+	for j := 1; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case c == '$':
+			return s[:j+1], true
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80:
+		case c >= '0' && c <= '9' && j > 1:
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
@@ -1,17 +1,22 @@
 package rig
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // RedisDef defines a service backed by the builtin Redis type.
 // Rig manages the container lifecycle and database isolation — the API
 // is minimal.
 //
-// Publishes REDIS_URL as an endpoint attribute.
+// Publishes REDIS_URL, REDIS_HOST, and REDIS_PORT as endpoint attributes.
 // Each environment gets an isolated database assigned by the server.
 type RedisDef struct {
-	image    string
-	egresses map[string]egressDef
-	hooks    hooksDef
+	image     string
+	egresses  map[string]egressDef
+	hooks     hooksDef
+	timeout   time.Duration
+	dependsOn []string
 }
 
 func (*RedisDef) rigService() {}
@@ -50,6 +55,18 @@ func (d *RedisDef) EgressAs(name, service string, ingress ...string) *RedisDef {
 	return d
 }
 
+// InitCommands registers Redis commands to run via redis-cli against the
+// environment's database once Redis answers PING, before the service is
+// marked ready. Arguments containing spaces can be quoted. Commands run
+// server-side — no Redis client needed in the test process. An error reply
+// fails Up. Can be called multiple times.
+//
+//	rig.Redis().InitCommands("SET feature:checkout on", `HSET user:1 name "Ada Lovelace"`)
+func (d *RedisDef) InitCommands(commands ...string) *RedisDef {
+	d.hooks.init = append(d.hooks.init, redisHook{commands: commands})
+	return d
+}
+
 // InitHook registers a client-side init hook function.
 func (d *RedisDef) InitHook(fn func(ctx context.Context, w Wiring) error) *RedisDef {
 	d.hooks.init = append(d.hooks.init, hookFunc(fn))
@@ -61,3 +78,16 @@ func (d *RedisDef) PrestartHook(fn func(ctx context.Context, w Wiring) error) *R
 	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
 	return d
 }
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *RedisDef) DependsOn(services ...string) *RedisDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *RedisDef) Timeout(timeout time.Duration) *RedisDef {
+	d.timeout = timeout
+	return d
+}
@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -55,6 +58,20 @@ type IngressDef struct {
 	ContainerPort int            // for container types only
 	Ready         *ReadyDef      // optional health check override
 	Attributes    map[string]any // static attributes published with this ingress
+
+	maxBodySize int64
+}
+
+// MaxBodySize makes the external proxy reject requests from the test whose
+// body exceeds n bytes with 413 Request Entity Too Large, without
+// forwarding them to the service. Use it to simulate an upstream gateway's
+// body limit. The rejection is recorded in the traffic log as
+// proxy-injected. HTTP ingresses only; requires observe (the default).
+//
+//	rig.IngressHTTP().MaxBodySize(1 << 20)
+func (d IngressDef) MaxBodySize(n int64) IngressDef {
+	d.maxBodySize = n
+	return d
 }
 
 // IngressHTTP returns an IngressDef for an HTTP endpoint.
@@ -69,19 +86,98 @@ func IngressGRPC() IngressDef { return IngressDef{Protocol: GRPC} }
 // IngressKafka returns an IngressDef for a Kafka endpoint.
 func IngressKafka() IngressDef { return IngressDef{Protocol: connect.Kafka} }
 
+// IngressRedis returns an IngressDef for a Redis (RESP) endpoint. Observed
+// traffic on it is recorded per command rather than per connection.
+func IngressRedis() IngressDef { return IngressDef{Protocol: connect.Redis} }
+
+// IngressNATS returns an IngressDef for a NATS endpoint. Observed traffic on
+// it is recorded per message (PUB, SUB, MSG) rather than per connection.
+func IngressNATS() IngressDef { return IngressDef{Protocol: connect.NATS} }
+
+// IngressMongo returns an IngressDef for a MongoDB endpoint. Observed traffic
+// on it is recorded per command rather than per connection.
+func IngressMongo() IngressDef { return IngressDef{Protocol: connect.Mongo} }
+
+// MockResponse is a canned HTTP response served by an egress proxy instead
+// of forwarding the request. Method and Path select which requests are
+// mocked; a zero value matches everything.
+type MockResponse struct {
+	Method  string            // request method to match; empty matches any
+	Path    string            // path.Match pattern, e.g. "/charges/*"; empty matches any
+	Status  int               // response status; defaults to 200
+	Headers map[string]string // response headers
+	Body    string            // response body
+}
+
 // ReadyDef overrides the health check for an ingress.
 type ReadyDef struct {
-	Type     string        // "tcp", "http", "grpc"
+	Type     string        // "tcp", "http", "grpc", "redis"
 	Path     string        // HTTP check path
+	Status   int           // HTTP status required; default any status < 500
 	Interval time.Duration // poll interval
 	Timeout  time.Duration // max wait
+
+	// Successes is how many probes in a row must pass; default 1.
+	Successes int
 }
 
 // Internal types — used by service builders but not exposed to users.
 
 type egressDef struct {
-	service string
-	ingress string
+	service      string
+	ingress      string
+	allowMethods []string
+	mocks        []MockResponse
+	database     string
+	external     string
+	waitForGRPC  []string
+}
+
+// allowEgressMethods appends methods to the allowlist of the named egress.
+// Panics if name is not a declared egress, i.e. AllowMethods was called
+// before Egress or EgressAs.
+func allowEgressMethods(egresses map[string]egressDef, name string, methods []string) {
+	eg, ok := egresses[name]
+	if !ok {
+		panic("rig: AllowMethods must follow Egress or EgressAs")
+	}
+	eg.allowMethods = append(eg.allowMethods, methods...)
+	egresses[name] = eg
+}
+
+// mockEgress appends a mock to the named egress. Panics if name is not a
+// declared egress, i.e. Mock was called before Egress or EgressAs.
+func mockEgress(egresses map[string]egressDef, name string, m MockResponse) {
+	eg, ok := egresses[name]
+	if !ok {
+		panic("rig: Mock must follow Egress or EgressAs")
+	}
+	eg.mocks = append(eg.mocks, m)
+	egresses[name] = eg
+}
+
+// selectEgressDatabase sets the logical database of the named egress.
+// Panics if name is not a declared egress, i.e. Database was called before
+// Egress or EgressAs.
+func selectEgressDatabase(egresses map[string]egressDef, name, database string) {
+	eg, ok := egresses[name]
+	if !ok {
+		panic("rig: Database must follow Egress or EgressAs")
+	}
+	eg.database = database
+	egresses[name] = eg
+}
+
+// waitForEgressGRPCServices appends to the gRPC services the named egress's
+// target must register. Panics if name is not a declared egress, i.e.
+// WaitForGRPCService was called before Egress or EgressAs.
+func waitForEgressGRPCServices(egresses map[string]egressDef, name string, services []string) {
+	eg, ok := egresses[name]
+	if !ok {
+		panic("rig: WaitForGRPCService must follow Egress or EgressAs")
+	}
+	eg.waitForGRPC = append(eg.waitForGRPC, services...)
+	egresses[name] = eg
 }
 
 type hooksDef struct {
@@ -89,6 +185,46 @@ type hooksDef struct {
 	init     []hook
 }
 
+func (h hooksDef) clone() hooksDef {
+	return hooksDef{prestart: slices.Clone(h.prestart), init: slices.Clone(h.init)}
+}
+
+// cloneIngresses deep-copies a builder's ingresses so a clone's changes,
+// including to an ingress's Attributes or Ready, don't reach the original.
+func cloneIngresses(in map[string]IngressDef) map[string]IngressDef {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]IngressDef, len(in))
+	for name, def := range in {
+		def.Attributes = maps.Clone(def.Attributes)
+		if def.Ready != nil {
+			ready := *def.Ready
+			def.Ready = &ready
+		}
+		out[name] = def
+	}
+	return out
+}
+
+// cloneEgresses deep-copies a builder's egresses.
+func cloneEgresses(in map[string]egressDef) map[string]egressDef {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]egressDef, len(in))
+	for name, eg := range in {
+		eg.allowMethods = slices.Clone(eg.allowMethods)
+		eg.waitForGRPC = slices.Clone(eg.waitForGRPC)
+		eg.mocks = slices.Clone(eg.mocks)
+		for i := range eg.mocks {
+			eg.mocks[i].Headers = maps.Clone(eg.mocks[i].Headers)
+		}
+		out[name] = eg
+	}
+	return out
+}
+
 type hook interface {
 	rigHook()
 }
@@ -97,18 +233,85 @@ type hookFunc func(ctx context.Context, w Wiring) error
 
 func (hookFunc) rigHook() {}
 
+// hookOutputs collects the values an init hook sets with SetOutput.
+type hookOutputs struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+type hookOutputsKey struct{}
+
+// SetOutput publishes a value from an init hook to the services that
+// depend on the hook's service. Each dependent sees it as an attribute of
+// its egress to the service, so it arrives in RIG_WIRING and as a flat
+// env var prefixed by the egress name (AUTH_JWT_KEY for an egress named
+// "auth"); the test reads it with env.Endpoint("auth").Attr("JWT_KEY").
+// Dependents start only after the service is ready, so they always see it.
+//
+//	rig.Go("./cmd/auth").InitHook(func(ctx context.Context, w rig.Wiring) error {
+//		key, err := createSigningKey(w.Ingress())
+//		if err != nil {
+//			return err
+//		}
+//		rig.SetOutput(ctx, "JWT_KEY", key)
+//		return nil
+//	})
+//
+// SetOutput panics if ctx is not an init or prestart hook's context.
+// Setting outputs from a prestart hook fails the service: they are only
+// published once the service is ready.
+func SetOutput(ctx context.Context, key, value string) {
+	out, ok := ctx.Value(hookOutputsKey{}).(*hookOutputs)
+	if !ok {
+		panic("rig: SetOutput called outside a hook")
+	}
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	if out.values == nil {
+		out.values = make(map[string]string)
+	}
+	out.values[key] = value
+}
+
 type sqlHook struct {
 	statements []string
+	database   string // logical database; empty means the service's own
 }
 
 func (sqlHook) rigHook() {}
 
+// sqlFileHook is an sqlHook whose statements are read from files when the
+// environment is converted to its spec.
+type sqlFileHook struct {
+	paths []string
+}
+
+func (sqlFileHook) rigHook() {}
+
 type execHook struct {
 	command []string
 }
 
 func (execHook) rigHook() {}
 
+type redisHook struct {
+	commands []string
+}
+
+func (redisHook) rigHook() {}
+
+type mongoHook struct {
+	script string
+}
+
+func (mongoHook) rigHook() {}
+
+// temporalHook creates a Temporal service's configured namespace and
+// search attributes.
+type temporalHook struct{}
+
+func (temporalHook) rigHook() {}
+
 type schemaHook struct {
 	subject    string
 	schemaType string // "AVRO", "PROTOBUF"
@@ -124,10 +327,28 @@ type startFunc func(ctx context.Context) error
 type Option func(*options)
 
 type options struct {
-	serverURL      string
-	startupTimeout time.Duration
-	observe        bool
-	ttl            string
+	serverURL        string
+	managedServer    bool
+	startupTimeout   time.Duration
+	startupBudget    time.Duration
+	observe          bool
+	ttl              string
+	containerNetwork bool
+	trafficGolden    string
+	tcpIdleTimeout   string
+	observeBodyLimit *int
+	autoDetect       bool
+	connPool         int
+	observeTLS       *specTLSSpec
+	redact           *specRedactSpec
+	protoDescriptors string
+	splitLogs        bool
+	metadata         map[string]string
+	reuse            bool
+	startupOrder     [][]string
+	fakeNow          time.Time
+	seed             *int64
+	faults           []specFaultSpec
 }
 
 func defaultOptions() options {
@@ -142,7 +363,25 @@ func defaultOptions() options {
 // WithServer sets the rigd server base URL (e.g. "http://127.0.0.1:8080").
 // Defaults to the RIG_SERVER_ADDR environment variable.
 func WithServer(url string) Option {
-	return func(o *options) { o.serverURL = url }
+	return func(o *options) {
+		o.serverURL = url
+		o.managedServer = false
+	}
+}
+
+// WithManagedServer runs the environment on a rigd owned by the test
+// process rather than a shared background daemon. The first environment
+// reuses the rigd in ~/.rig/rigd.addr if one is running and otherwise
+// starts one; later environments in the same process share it, and it is
+// stopped when the last of them is torn down. It takes precedence over
+// RIG_SERVER_ADDR, and replaces starting rigd from TestMain:
+//
+//	env := rig.Up(t, services, rig.WithManagedServer())
+func WithManagedServer() Option {
+	return func(o *options) {
+		o.serverURL = ""
+		o.managedServer = true
+	}
 }
 
 // WithTimeout sets the maximum time to wait for the environment to become
@@ -151,6 +390,15 @@ func WithTimeout(d time.Duration) Option {
 	return func(o *options) { o.startupTimeout = d }
 }
 
+// WithStartupBudget fails Up if the environment takes longer than d to
+// become ready, measured from creation to environment.up. Unlike
+// WithTimeout, which bounds the wait, the budget is checked after the
+// environment is up, so the error reports how long startup actually took.
+// Use it to catch startup slowly creeping towards the timeout.
+func WithStartupBudget(d time.Duration) Option {
+	return func(o *options) { o.startupBudget = d }
+}
+
 // WithoutObserve disables transparent traffic proxying. By default, rig
 // inserts a proxy on every egress edge and every external connection,
 // capturing request/connection events in the event log. Use this option
@@ -159,6 +407,123 @@ func WithoutObserve() Option {
 	return func(o *options) { o.observe = false }
 }
 
+// ObserveOption configures the traffic proxies enabled by WithObserve.
+type ObserveOption func(*options)
+
+// WithObserve enables transparent traffic proxying (the default) and applies
+// proxy options:
+//
+//	rig.Up(t, services, rig.WithObserve(rig.TCPIdleTimeout(5*time.Minute)))
+func WithObserve(opts ...ObserveOption) Option {
+	return func(o *options) {
+		o.observe = true
+		for _, opt := range opts {
+			opt(o)
+		}
+	}
+}
+
+// TCPIdleTimeout makes the proxy close TCP connections that have carried
+// no data in either direction for d, emitting connection.closed with
+// close_reason "idle_timeout". A connection with a write in flight is never
+// closed. This bounds proxy resources in environments with many pooled,
+// mostly idle connections.
+func TCPIdleTimeout(d time.Duration) ObserveOption {
+	return func(o *options) { o.tcpIdleTimeout = d.String() }
+}
+
+// HTTPConnPool makes each HTTP proxy keep up to n idle connections to its
+// target open for reuse. By default a proxy keeps two, so a caller with
+// more requests in flight makes the proxy dial the target again, which
+// shows up as proxy overhead (proxy_overhead_ms) on request.completed.
+// Latency (latency_ms) is timed from when the proxy has a connection, so it
+// is the target's either way.
+//
+//	rig.Up(t, services, rig.WithObserve(rig.HTTPConnPool(32)))
+func HTTPConnPool(n int) ObserveOption {
+	return func(o *options) { o.connPool = n }
+}
+
+// WithObserveBodyLimit sets how many body bytes the observe proxies capture
+// per HTTP request or response and gRPC call, overriding the 64KB default.
+// Pass 0 to skip body capture entirely (saving memory in high-throughput
+// tests) or -1 to capture bodies in full. Traffic is forwarded in full
+// either way; only what is recorded in the event log changes.
+//
+// Setting a limit other than 0 also turns on TCP previews: the first bytes
+// (up to the limit) sent each way over a plain TCP connection are recorded
+// on connection.closed and shown as a hex dump by `rig traffic --detail`.
+// Without this option TCP connections record byte counts only.
+func WithObserveBodyLimit(bytes int) Option {
+	return func(o *options) { o.observeBodyLimit = &bytes }
+}
+
+// WithObserveAutoDetect makes the observe proxies on TCP ingresses sniff
+// the first bytes of each connection and decode it as HTTP, gRPC or Kafka
+// when it matches, so a service declared as TCP that actually speaks one of
+// those still gets request-level events. Connections that match none of
+// them, or whose client sends nothing within a moment of connecting (as
+// with protocols where the server speaks first), are relayed as opaque TCP.
+func WithObserveAutoDetect() Option {
+	return func(o *options) { o.autoDetect = true }
+}
+
+// WithObserveTLS gives the observe proxies a certificate to terminate TLS
+// with. Edges to a SECURE http ingress are then decoded like plain HTTP:
+// the proxy presents certFile to the source, forwards over TLS to the
+// target, and advertises the certificate as the TLS_CERT_FILE attribute
+// (httpx.New trusts it). The certificate must be valid for 127.0.0.1.
+// Without it, edges to SECURE ingresses are relayed as opaque TCP.
+// Relative paths are resolved against the working directory.
+func WithObserveTLS(certFile, keyFile string) Option {
+	return func(o *options) {
+		o.observeTLS = &specTLSSpec{CertFile: absPath(certFile), KeyFile: absPath(keyFile)}
+	}
+}
+
+// defaultRedactHeaders matches the headers rigd redacts when the
+// environment doesn't name its own.
+var defaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// WithRedact masks secrets in captured traffic before it reaches the event
+// log: the values of the named headers and the JSON body fields at the
+// given dot-separated paths are replaced with "***". Header names match
+// case-insensitively. A path descends into arrays element by element, so
+// "items.card" masks the card of every item. Only what is recorded
+// changes; services see the real traffic.
+//
+// Authorization, Cookie and Set-Cookie are redacted by default. A nil
+// headerNames keeps that default; otherwise the list replaces it, and an
+// empty list redacts no headers.
+//
+//	rig.Up(t, services, rig.WithRedact(nil, []string{"user.email", "token"}))
+func WithRedact(headerNames []string, jsonPaths []string) Option {
+	return func(o *options) {
+		if headerNames == nil {
+			headerNames = defaultRedactHeaders
+		}
+		o.redact = &specRedactSpec{Headers: headerNames, JSONPaths: jsonPaths}
+	}
+}
+
+// WithProtoDescriptors loads a FileDescriptorSet (from `protoc
+// --include_imports --descriptor_set_out`) that the observe proxies use to
+// decode gRPC request and response bodies when the target doesn't serve
+// reflection. Methods missing from the set are captured as raw frames.
+// A relative path is resolved against the working directory.
+func WithProtoDescriptors(path string) Option {
+	return func(o *options) { o.protoDescriptors = absPath(path) }
+}
+
+// absPath returns p as an absolute path, or p unchanged if it can't be
+// resolved.
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
 // WithTTL sets a maximum lifetime for the environment. When set, the
 // environment auto-destroys after the specified duration and the client
 // skips sending DELETE on cleanup, allowing the environment to outlive
@@ -167,9 +532,167 @@ func WithTTL(d time.Duration) Option {
 	return func(o *options) { o.ttl = d.String() }
 }
 
+// WithContainerNetwork runs the environment's container-backed services on a
+// dedicated Docker network. Name addressing only applies together with
+// WithoutObserve: observe (the default) routes every edge through a proxy on
+// the host, so containers still reach each other through host ports, and
+// the server returns a warning. Without observe, containers address each
+// other by service name over the network rather than through host ports.
+// Ingresses are still published on 127.0.0.1 so the test can reach them.
+//
+//	rig.Up(t, services, rig.WithContainerNetwork(), rig.WithoutObserve())
+func WithContainerNetwork() Option {
+	return func(o *options) { o.containerNetwork = true }
+}
+
+// WithSplitLogs makes the server also write each service's stdout and
+// stderr to separate files on teardown ({name}-{id}-{service}.stderr.log
+// alongside the event log), so one service's output can be read without
+// filtering. The paths are logged with the event log path.
+func WithSplitLogs() Option {
+	return func(o *options) { o.splitLogs = true }
+}
+
+// WithReuse shares the environment with other tests that bring up an
+// identical one. If rigd is already running an environment created with
+// WithReuse from the same spec — same services, hooks, InitSQL, options and
+// working directory — Up attaches to it instead of starting a new one, and
+// the shared environment is torn down when the last test using it
+// finishes. Sharing lasts while at least one test holds it, so it pays off
+// for parallel tests or an environment held open from TestMain.
+//
+// Reuse means shared state: every test sees the others' rows, keys and
+// messages. It suits read-only fixtures such as a seeded schema. Specs with
+// in-process Func services or client-side hooks can't be shared.
+//
+//	env := rig.Up(t, services, rig.WithReuse())
+func WithReuse() Option {
+	return func(o *options) { o.reuse = true }
+}
+
+// WithMetadata tags the environment with key/value labels, such as the CI
+// shard or pull request it ran for. They are recorded in the event log
+// header, shown by `rig ls`, and matched by `rig ls --label key=value`.
+// Repeated calls merge, with later values winning. Keys must be non-empty
+// and must not contain '=' or ','.
+//
+//	rig.Up(t, services, rig.WithMetadata(map[string]string{"pr": "1234", "shard": "3"}))
+func WithMetadata(md map[string]string) Option {
+	return func(o *options) {
+		if o.metadata == nil {
+			o.metadata = make(map[string]string, len(md))
+		}
+		for k, v := range md {
+			o.metadata[k] = v
+		}
+	}
+}
+
+// WithStartupOrder starts the named services one after another, each
+// waiting for the previous one to be ready, even where no egress or
+// DependsOn edge links them. Use it to serialize boot when services
+// contend for a shared resource, such as migrations against one database.
+// Repeated calls add separate chains, so the order can be partial. Up
+// fails if a chain contradicts the dependency graph, e.g. naming a service
+// before one it has an egress to.
+//
+//	rig.Up(t, services, rig.WithStartupOrder([]string{"migrate-users", "migrate-orders"}))
+func WithStartupOrder(order []string) Option {
+	return func(o *options) {
+		o.startupOrder = append(o.startupOrder, slices.Clone(order))
+	}
+}
+
+// WithFrozenClock starts every service with a fixed clock at t, as if each
+// were configured with FakeClock. Services read it with connect.Now(ctx),
+// which stays at t until Environment.AdvanceClock moves it. A service's
+// own FakeClock takes priority. Services that call time.Now directly are
+// unaffected.
+//
+//	rig.Up(t, services, rig.WithFrozenClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+func WithFrozenClock(t time.Time) Option {
+	return func(o *options) { o.fakeNow = t }
+}
+
+// WithSeed passes seed to every service as RIG_SEED. Services that draw
+// randomness from connect.Rand(ctx) then produce the same sequence on
+// every run, so a failure seen once can be replayed.
+//
+//	rig.Up(t, services, rig.WithSeed(42))
+func WithSeed(seed int64) Option {
+	return func(o *options) { o.seed = &seed }
+}
+
+// FaultSpec describes faults the observe proxy injects on an edge. See
+// WithFault.
+type FaultSpec struct {
+	Latency   time.Duration // added to each HTTP or gRPC request, or each connection for other protocols
+	Jitter    time.Duration // random extra latency, up to this much
+	DropRate  float64       // fraction of connections, 0 to 1, closed on accept
+	ErrorRate float64       // fraction of HTTP requests, 0 to 1, answered with 503
+}
+
+// WithFault makes the observe proxy on an edge misbehave, so a test can
+// check how a service copes with a slow or flaky dependency. edge is
+// "source→target" (or "source->target"); use "~test" as the source for
+// requests made by the test itself. Each injected fault is recorded as a
+// fault.injected event, and injected 503s appear in the traffic log
+// marked as proxy-injected.
+//
+//	rig.Up(t, services, rig.WithFault("orders→payments", rig.FaultSpec{
+//		Latency:   200 * time.Millisecond,
+//		ErrorRate: 0.1,
+//	}))
+//
+// Faults require observe mode. A fault naming a service started with
+// Scale applies to every instance.
+func WithFault(edge string, fault FaultSpec) Option {
+	source, target, ok := strings.Cut(edge, "→")
+	if !ok {
+		source, target, ok = strings.Cut(edge, "->")
+	}
+	source, target = strings.TrimSpace(source), strings.TrimSpace(target)
+	if !ok || source == "" || target == "" {
+		panic(fmt.Sprintf("rig: WithFault edge %q must be \"source→target\"", edge))
+	}
+	return func(o *options) {
+		o.faults = append(o.faults, specFaultSpec{
+			Source:    source,
+			Target:    target,
+			Latency:   specDuration{Duration: fault.Latency},
+			Jitter:    specDuration{Duration: fault.Jitter},
+			DropRate:  fault.DropRate,
+			ErrorRate: fault.ErrorRate,
+		})
+	}
+}
+
+// resolveServer fills in o.serverURL, starting or connecting to rigd as
+// the options require. The returned release func is non-nil for a managed
+// server and must be called once the caller is done with it.
+func resolveServer(o *options) (release func(), err error) {
+	switch {
+	case o.managedServer:
+		addr, rel, err := acquireManagedServer(defaultRigDir())
+		if err != nil {
+			return nil, fmt.Errorf("rig: %w", err)
+		}
+		o.serverURL, release = addr, rel
+	case o.serverURL == "":
+		addr, err := EnsureServer("")
+		if err != nil {
+			return nil, fmt.Errorf("rig: %w", err)
+		}
+		o.serverURL = addr
+	}
+	// Trim trailing slash for consistent URL construction.
+	o.serverURL = strings.TrimRight(o.serverURL, "/")
+	return release, nil
+}
+
 // Up creates an environment, blocks until all services are ready, and
 // registers cleanup with t.Cleanup to tear down the environment when the
-// test finishes.
+// test finishes. It is Start followed by WaitReady.
 //
 // If any step fails (server connection, spec validation, service startup),
 // Up calls t.Fatal with a descriptive error message.
@@ -185,21 +708,61 @@ func Up(t testing.TB, services Services, opts ...Option) *Environment {
 // TryUp is like Up but returns an error instead of calling t.Fatal. Use this
 // to test expected-failure scenarios.
 func TryUp(t testing.TB, services Services, opts ...Option) (*Environment, error) {
+	env, err := start(t, services, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.WaitReady(context.Background()); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// Start creates an environment and returns without waiting for its
+// services to be ready. Hooks and Func services run in the background as
+// the server asks for them; call WaitReady before using the environment's
+// endpoints. Starting several environments before waiting on any lets
+// their startups overlap:
+//
+//	orders := rig.Start(t, orderServices)
+//	billing := rig.Start(t, billingServices)
+//	for _, env := range []*rig.Environment{orders, billing} {
+//		if err := env.WaitReady(ctx); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+//
+// Cleanup is registered as for Up. If the environment can't be created,
+// Start calls t.Fatal; startup failures are reported by WaitReady.
+func Start(t testing.TB, services Services, opts ...Option) *Environment {
+	t.Helper()
+	env, err := start(t, services, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+// start creates the environment and subscribes to its event stream. The
+// stream is processed in the background until the environment is up or
+// has failed; WaitReady reports which.
+func start(t testing.TB, services Services, opts ...Option) (*Environment, error) {
 	o := defaultOptions()
 	for _, opt := range opts {
 		opt(&o)
 	}
 
-	if o.serverURL == "" {
-		addr, err := EnsureServer("")
-		if err != nil {
-			return nil, fmt.Errorf("rig: %w", err)
-		}
-		o.serverURL = addr
+	// releaseServer is handed to the teardown once the environment exists;
+	// until then, failing to create it must release the server here.
+	releaseServer, err := resolveServer(&o)
+	if err != nil {
+		return nil, err
 	}
-
-	// Trim trailing slash for consistent URL construction.
-	o.serverURL = strings.TrimRight(o.serverURL, "/")
+	defer func() {
+		if releaseServer != nil {
+			releaseServer()
+		}
+	}()
 
 	// Validate TTL early so the user gets a clear error instead of a
 	// spec validation failure from the server.
@@ -216,8 +779,12 @@ func TryUp(t testing.TB, services Services, opts ...Option) (*Environment, error
 	if err != nil {
 		return nil, fmt.Errorf("rig: build spec: %v", err)
 	}
+	if o.reuse && (len(handlers) > 0 || len(startHandlers) > 0) {
+		return nil, fmt.Errorf("rig: WithReuse: environments with Func services or client-side hooks run code in this test process and can't be shared")
+	}
 
 	// POST /environments
+	createdAt := time.Now()
 	body, err := json.Marshal(specEnv)
 	if err != nil {
 		return nil, fmt.Errorf("rig: marshal spec: %v", err)
@@ -242,17 +809,33 @@ func TryUp(t testing.TB, services Services, opts ...Option) (*Environment, error
 			strings.Join(result.ValidationErrors, "\n  "))
 	}
 
-	if resp.StatusCode != http.StatusCreated {
+	// 503 is a pre-flight failure, such as an unreachable Docker daemon,
+	// whose message stands on its own.
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		var result struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Error != "" {
+			return nil, fmt.Errorf("rig: %s", result.Error)
+		}
+	}
+
+	// 200 means WithReuse attached to a running environment.
+	if resp.StatusCode != http.StatusCreated && !(o.reuse && resp.StatusCode == http.StatusOK) {
 		respBody, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("rig: create environment: HTTP %d: %s", resp.StatusCode, respBody)
 	}
 
 	var created struct {
-		ID string `json:"id"`
+		ID       string   `json:"id"`
+		Warnings []string `json:"warnings"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
 		return nil, fmt.Errorf("rig: decode create response: %v", err)
 	}
+	for _, w := range created.Warnings {
+		t.Logf("rig: warning: %s", w)
+	}
 
 	envID := created.ID
 
@@ -260,15 +843,49 @@ func TryUp(t testing.TB, services Services, opts ...Option) (*Environment, error
 	// before the environment is destroyed, giving functions time to stop.
 	funcCtx, funcCancel := context.WithCancel(context.Background())
 
+	// The stream context bounds startup. It is created here so the timeout
+	// runs from creation whether or not WaitReady is ever called.
+	streamCtx, streamCancel := context.WithTimeout(context.Background(), o.startupTimeout)
+
 	// Register cleanup: stop functions, destroy the environment.
 	// Always write the event log so it's available for inspection.
 	// When TTL is set, skip DELETE — the server will tear down on expiry.
-	// envDir is captured by reference and set after streaming succeeds.
-	var envDir string
+	// Teardown is shared with Environment.Close, so an environment closed
+	// early is not destroyed twice; its result is still logged here.
+	td := &teardown{serverURL: o.serverURL, envID: envID, splitLogs: o.splitLogs, funcCancel: funcCancel, release: releaseServer}
+	releaseServer = nil
+	st := &startup{done: make(chan struct{})}
+	env := &Environment{
+		ID:        envID,
+		Name:      t.Name(),
+		serverURL: o.serverURL,
+		teardown:  td,
+		startup:   st,
+		T: &TB{
+			TB:        t,
+			serverURL: o.serverURL,
+			envID:     envID,
+		},
+	}
 	t.Cleanup(func() {
-		funcCancel()
+		// A test may finish without waiting for startup; stop the stream
+		// so it isn't left dispatching callbacks during teardown.
+		streamCancel()
+		<-st.done
+		up := st.err == nil
 
-		if o.ttl != "" {
+		// Snapshot traffic before anything is torn down.
+		if o.trafficGolden != "" && up && !td.isDone() {
+			checkTrafficGolden(t, env, o.trafficGolden)
+		}
+
+		if o.ttl != "" && !td.isDone() {
+			funcCancel()
+			if td.release != nil {
+				// A managed server outlives the environment: it is
+				// only stopped if nothing is running on it.
+				td.release()
+			}
 			t.Logf("rig: environment has TTL %s — skipping teardown", o.ttl)
 			t.Logf("rig: use 'rig ps' to list active environments")
 			t.Logf("rig: use 'rig down %s' to tear down early", envID)
@@ -277,17 +894,18 @@ func TryUp(t testing.TB, services Services, opts ...Option) (*Environment, error
 
 		preserve := os.Getenv("RIG_PRESERVE") == "true" ||
 			(t.Failed() && os.Getenv("RIG_PRESERVE_ON_FAILURE") == "true")
-		result := destroyEnvironment(o.serverURL, envID, preserve, t.Failed())
+		result, _ := td.close(preserve, t.Failed())
+		env.logFile = result.LogFile
 		// Explain summary first — the diagnosis is what you want to see
 		// immediately. File paths and CLI commands are reference material.
 		if t.Failed() && result.Summary != "" {
 			t.Log(result.Summary)
 		}
-		if t.Failed() && envDir != "" {
+		if t.Failed() && env.EnvDir != "" {
 			if preserve {
-				t.Logf("rig: environment dir (preserved): %s", envDir)
+				t.Logf("rig: environment dir (preserved): %s", env.EnvDir)
 			} else {
-				t.Logf("rig: environment dir (cleaned): %s", envDir)
+				t.Logf("rig: environment dir (cleaned): %s", env.EnvDir)
 				t.Logf("rig: to preserve on failure, set RIG_PRESERVE_ON_FAILURE=true")
 			}
 		}
@@ -297,6 +915,9 @@ func TryUp(t testing.TB, services Services, opts ...Option) (*Environment, error
 		if result.LogFilePretty != "" {
 			t.Logf("rig: timeline:  %s", result.LogFilePretty)
 		}
+		for _, f := range result.ServiceLogFiles {
+			t.Logf("rig: service log: %s", f)
+		}
 		if result.LogFile != "" {
 			name := strings.TrimSuffix(filepath.Base(result.LogFile), ".jsonl")
 			var prefix string
@@ -308,65 +929,136 @@ func TryUp(t testing.TB, services Services, opts ...Option) (*Environment, error
 		}
 	})
 
-	// Open SSE stream and process events until environment.up or failure.
-	ctx, cancel := context.WithTimeout(context.Background(), o.startupTimeout)
-	defer cancel()
-
-	resolved, err := streamUntilReady(ctx, o.serverURL, envID, handlers, funcCtx, startHandlers)
+	// Subscribe before returning so callbacks are answered while the
+	// caller is busy elsewhere, then process events until environment.up
+	// or failure.
+	stream, err := openEventStream(streamCtx, o.serverURL, envID)
 	if err != nil {
-		return nil, fmt.Errorf("rig: %v", err)
+		streamCancel()
+		st.finish(fmt.Errorf("rig: %v", err))
+		return nil, st.err
 	}
+	go func() {
+		defer streamCancel()
+		defer stream.Close()
 
-	envDir = resolved.EnvDir
+		resolved, err := streamUntilReady(streamCtx, stream, o.serverURL, envID, handlers, funcCtx, startHandlers)
+		if err != nil {
+			st.finish(fmt.Errorf("rig: %v", err))
+			return
+		}
+		env.Services = resolved.Services
+		env.EnvDir = resolved.EnvDir
 
-	resolved.ID = envID
-	resolved.Name = t.Name()
-	resolved.T = &rigTB{
-		TB:        t,
-		serverURL: o.serverURL,
-		envID:     envID,
-	}
+		if elapsed := time.Since(createdAt); o.startupBudget > 0 && elapsed > o.startupBudget {
+			st.finish(fmt.Errorf("rig: environment took %s to come up, over the startup budget of %s",
+				elapsed.Round(time.Millisecond), o.startupBudget))
+			return
+		}
+		st.finish(nil)
+	}()
+
+	return env, nil
+}
+
+// startup records the outcome of an environment's startup. done is closed
+// once err is final.
+type startup struct {
+	done chan struct{}
+	err  error
+}
 
-	return resolved, nil
+func (s *startup) finish(err error) {
+	s.err = err
+	close(s.done)
 }
 
 // destroyResult holds the paths returned by the server after teardown.
 type destroyResult struct {
-	LogFile       string // structured JSONL event log
-	LogFilePretty string // human-readable timeline summary
-	Summary       string // condensed failure diagnosis from server
+	LogFile         string   // structured JSONL event log
+	LogFilePretty   string   // human-readable timeline summary
+	ServiceLogFiles []string // per-service stdout/stderr logs (WithSplitLogs)
+	Summary         string   // condensed failure diagnosis from server
+}
+
+// teardown destroys an environment at most once, for whichever of
+// Environment.Close and the test cleanup runs first.
+type teardown struct {
+	serverURL  string
+	envID      string
+	splitLogs  bool
+	funcCancel context.CancelFunc // stops client-side functions
+	release    func()             // releases a managed server; nil otherwise
+
+	mu     sync.Mutex
+	done   bool
+	result destroyResult
+}
+
+// close stops client-side functions and destroys the environment. Only the
+// first call does any work and can return an error; later calls return the
+// first call's result and a nil error.
+func (td *teardown) close(preserve, failed bool) (destroyResult, error) {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	if td.done {
+		return td.result, nil
+	}
+	td.done = true
+	td.funcCancel()
+	var err error
+	td.result, err = destroyEnvironment(td.serverURL, td.envID, preserve, failed, td.splitLogs)
+	if td.release != nil {
+		td.release()
+	}
+	return td.result, err
+}
+
+func (td *teardown) isDone() bool {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	return td.done
 }
 
 // destroyEnvironment sends DELETE /environments/{id}?log=true. Blocks until
 // teardown completes. The server writes the event log to disk and returns the
-// paths. Errors are swallowed — cleanup must not abort other tests.
-func destroyEnvironment(serverURL, envID string, preserve bool, failed bool) destroyResult {
+// paths. Test cleanup ignores the error — it must not abort other tests.
+func destroyEnvironment(serverURL, envID string, preserve bool, failed bool, splitLogs bool) (destroyResult, error) {
 	url := fmt.Sprintf("%s/environments/%s?log=true", serverURL, envID)
 	if preserve {
 		url += "&preserve=true"
 	}
+	if splitLogs {
+		url += "&split_logs=true"
+	}
 	if failed {
 		url += "&reason=test_failed"
 	}
 	req, err := http.NewRequest(http.MethodDelete, url, nil)
 	if err != nil {
-		return destroyResult{}
+		return destroyResult{}, fmt.Errorf("rig: destroy environment: %v", err)
 	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return destroyResult{}
+		return destroyResult{}, fmt.Errorf("rig: destroy environment: %v", err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return destroyResult{}, fmt.Errorf("rig: destroy environment: HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
 
 	var result struct {
-		LogFile       string `json:"log_file"`
-		LogFilePretty string `json:"log_file_pretty"`
-		Summary       string `json:"summary"`
+		LogFile         string   `json:"log_file"`
+		LogFilePretty   string   `json:"log_file_pretty"`
+		ServiceLogFiles []string `json:"service_log_files"`
+		Summary         string   `json:"summary"`
 	}
 	json.NewDecoder(resp.Body).Decode(&result)
 	return destroyResult{
-		LogFile:       result.LogFile,
-		LogFilePretty: result.LogFilePretty,
-		Summary:       result.Summary,
-	}
+		LogFile:         result.LogFile,
+		LogFilePretty:   result.LogFilePretty,
+		ServiceLogFiles: result.ServiceLogFiles,
+		Summary:         result.Summary,
+	}, nil
 }
@@ -1,6 +1,11 @@
 package rig_test
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	rig "github.com/matgreaves/rig/client"
@@ -62,6 +67,52 @@ func TestEndpoint_Lookup_PanicsOnMiss(t *testing.T) {
 	})
 }
 
+func TestLookupEndpoint(t *testing.T) {
+	t.Parallel()
+	env := &rig.Environment{
+		Name: "test",
+		Services: map[string]rig.ResolvedService{
+			"orders": {Ingresses: map[string]rig.Endpoint{
+				"default": {HostPort: "127.0.0.1:8080", Protocol: rig.HTTP},
+				"admin":   {HostPort: "127.0.0.1:8081", Protocol: rig.HTTP},
+			}},
+			"payments": {Ingresses: map[string]rig.Endpoint{
+				"default": {HostPort: "127.0.0.1:9090", Protocol: rig.GRPC},
+			}},
+		},
+	}
+
+	ep, err := env.LookupEndpoint("orders", "admin")
+	if err != nil || ep.HostPort != "127.0.0.1:8081" {
+		t.Errorf("LookupEndpoint(orders, admin) = %v, %v", ep, err)
+	}
+
+	tests := []struct {
+		service string
+		ingress []string
+		want    []string
+	}{
+		{"order", nil, []string{`service "order" not found`, `did you mean "orders"?`, "[orders payments]"}},
+		{"orders", []string{"admn"}, []string{`ingress "admn" not found on service "orders"`, `did you mean "admin"?`, "[admin default]"}},
+		{"inventory", nil, []string{`service "inventory" not found`, "[orders payments]"}},
+	}
+	for _, tt := range tests {
+		_, err := env.LookupEndpoint(tt.service, tt.ingress...)
+		if err == nil {
+			t.Errorf("LookupEndpoint(%s, %v): want error", tt.service, tt.ingress)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("LookupEndpoint(%s, %v) error %q missing %q", tt.service, tt.ingress, err, want)
+			}
+		}
+	}
+	if _, err := env.LookupEndpoint("inventory"); strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("unexpected suggestion for a distant name: %v", err)
+	}
+}
+
 func TestEndpoint_HostPort(t *testing.T) {
 	t.Parallel()
 	httpEP := rig.Endpoint{HostPort: "127.0.0.1:8080", Protocol: rig.HTTP}
@@ -114,3 +165,33 @@ func assertPanics(t *testing.T, name string, fn func()) {
 	}()
 	fn()
 }
+
+func TestTryUp_PreflightError(t *testing.T) {
+	const msg = "Docker is required for services [db] but the daemon is not reachable: connection refused"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": msg})
+	}))
+	defer ts.Close()
+
+	_, err := rig.TryUp(t, rig.Services{"db": rig.Postgres()}, rig.WithServer(ts.URL))
+	if err == nil || err.Error() != "rig: "+msg {
+		t.Fatalf("err = %v, want %q", err, "rig: "+msg)
+	}
+}
+
+func TestTryUp_ReuseRejectsFuncs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	_, err := rig.TryUp(t, rig.Services{
+		"worker": rig.Func(func(ctx context.Context) error { return nil }),
+	}, rig.WithServer(ts.URL), rig.WithReuse())
+	if err == nil || !strings.Contains(err.Error(), "WithReuse") {
+		t.Fatalf("err = %v, want a WithReuse error", err)
+	}
+}
@@ -1,6 +1,9 @@
 package rig
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // S3Def defines a service backed by the builtin S3 type.
 // Rig manages the container lifecycle and bucket isolation — the API
@@ -10,8 +13,10 @@ import "context"
 // AWS_SECRET_ACCESS_KEY as endpoint attributes.
 // Each environment gets an isolated bucket assigned by the server.
 type S3Def struct {
-	egresses map[string]egressDef
-	hooks    hooksDef
+	egresses  map[string]egressDef
+	hooks     hooksDef
+	timeout   time.Duration
+	dependsOn []string
 }
 
 func (*S3Def) rigService() {}
@@ -54,3 +59,16 @@ func (d *S3Def) PrestartHook(fn func(ctx context.Context, w Wiring) error) *S3De
 	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
 	return d
 }
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *S3Def) DependsOn(services ...string) *S3Def {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *S3Def) Timeout(timeout time.Duration) *S3Def {
+	d.timeout = timeout
+	return d
+}
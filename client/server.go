@@ -1,11 +1,13 @@
 package rig
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -57,12 +59,9 @@ func EnsureServer(rigDir string) (string, error) {
 		}
 	}
 
-	// If no binary found, download the version this SDK targets.
 	if binPath == "" {
-		binPath = filepath.Join(rigDir, "bin", "v"+RigdVersion, "rigd")
-		url := downloadURL(RigdVersion)
-		if err := downloadBinary(url, binPath); err != nil {
-			return "", fmt.Errorf("download rigd v%s: %w", RigdVersion, err)
+		if binPath, err = downloadRigd(rigDir); err != nil {
+			return "", err
 		}
 	}
 
@@ -71,6 +70,24 @@ func EnsureServer(rigDir string) (string, error) {
 	if !override {
 		args = append(args, "--addr-file", addrFile)
 	}
+	_, url, err := startRigd(binPath, rigDir, addrFile, args)
+	return url, err
+}
+
+// downloadRigd downloads the rigd version this SDK targets into rigDir and
+// returns its path.
+func downloadRigd(rigDir string) (string, error) {
+	binPath := filepath.Join(rigDir, "bin", "v"+RigdVersion, "rigd")
+	if err := downloadBinary(downloadURL(RigdVersion), binPath); err != nil {
+		return "", fmt.Errorf("download rigd v%s: %w", RigdVersion, err)
+	}
+	return binPath, nil
+}
+
+// startRigd starts rigd as a detached subprocess and waits for it to
+// publish a healthy address in addrFile. rigd writes the file atomically,
+// so a partial address is never read. Returns the process and base URL.
+func startRigd(binPath, rigDir, addrFile string, args []string) (*exec.Cmd, string, error) {
 	cmd := exec.Command(binPath, args...)
 	cmd.Dir = rigDir
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
@@ -84,7 +101,7 @@ func EnsureServer(rigDir string) (string, error) {
 	}
 
 	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("start rigd: %w", err)
+		return nil, "", fmt.Errorf("start rigd: %w", err)
 	}
 
 	// Poll for addr file.
@@ -97,13 +114,119 @@ func EnsureServer(rigDir string) (string, error) {
 		if addr, err := os.ReadFile(addrFile); err == nil && len(addr) > 0 {
 			addrStr := string(addr)
 			if probeHealth(addrStr) {
-				return "http://" + addrStr, nil
+				return cmd, "http://" + addrStr, nil
 			}
 		}
 		time.Sleep(pollInterval)
 	}
 
-	return "", fmt.Errorf("rigd did not become healthy within %s (log: %s)", pollTimeout, logPath)
+	return nil, "", fmt.Errorf("rigd did not become healthy within %s (log: %s)", pollTimeout, logPath)
+}
+
+// managed is the rigd shared by every environment in this process that
+// uses WithManagedServer.
+var managed struct {
+	mu   sync.Mutex
+	refs int
+	url  string
+	cmd  *exec.Cmd // nil when an already-running rigd was reused
+}
+
+// acquireManagedServer returns the base URL of the process's managed rigd
+// and a function that releases it. The first call reuses the rigd in
+// rigDir/rigd.addr if one answers, and otherwise starts one. The lock file
+// and rigd's atomic addr-file write mean that when parallel test binaries
+// race, only one starts rigd and the rest reuse it.
+//
+// When the last reference is released, a rigd this process started is
+// stopped, unless environments from other processes are still running on
+// it; those are left to its idle timeout.
+func acquireManagedServer(rigDir string) (url string, release func(), err error) {
+	managed.mu.Lock()
+	defer managed.mu.Unlock()
+
+	if managed.refs == 0 {
+		managed.url, managed.cmd, err = findOrStartManaged(rigDir)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	managed.refs++
+
+	var once sync.Once
+	return managed.url, func() { once.Do(releaseManagedServer) }, nil
+}
+
+func findOrStartManaged(rigDir string) (string, *exec.Cmd, error) {
+	addrFile := filepath.Join(rigDir, "rigd.addr")
+	if addr, err := os.ReadFile(addrFile); err == nil && probeHealth(string(addr)) {
+		return "http://" + string(addr), nil, nil
+	}
+
+	if err := os.MkdirAll(rigDir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("create rig dir: %w", err)
+	}
+	unlock, err := acquireLock(filepath.Join(rigDir, "rigd.lock"))
+	if err != nil {
+		return "", nil, fmt.Errorf("acquire lock: %w", err)
+	}
+	defer unlock()
+
+	// Another process may have started rigd while we waited for the lock.
+	if addr, err := os.ReadFile(addrFile); err == nil && probeHealth(string(addr)) {
+		return "http://" + string(addr), nil, nil
+	}
+
+	binPath, _, err := findBinary()
+	if err != nil {
+		return "", nil, err
+	}
+	if binPath == "" {
+		if binPath, err = downloadRigd(rigDir); err != nil {
+			return "", nil, err
+		}
+	}
+	// The idle timeout only matters if this process dies without
+	// releasing the server.
+	cmd, url, err := startRigd(binPath, rigDir, addrFile,
+		[]string{"--idle", "5m", "--rig-dir", rigDir, "--addr-file", addrFile})
+	if err != nil {
+		return "", nil, err
+	}
+	return url, cmd, nil
+}
+
+func releaseManagedServer() {
+	managed.mu.Lock()
+	defer managed.mu.Unlock()
+
+	managed.refs--
+	if managed.refs > 0 {
+		return
+	}
+	url, cmd := managed.url, managed.cmd
+	managed.url, managed.cmd = "", nil
+	if cmd == nil || activeEnvironments(url) > 0 {
+		return
+	}
+	cmd.Process.Signal(syscall.SIGTERM)
+	cmd.Wait()
+}
+
+// activeEnvironments returns the number of environments running on the
+// rigd at url, or 0 if it can't be asked.
+func activeEnvironments(url string) int {
+	c := http.Client{Timeout: time.Second}
+	resp, err := c.Get(url + "/environments")
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	var envs []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&envs); err != nil {
+		return 0
+	}
+	return len(envs)
 }
 
 // findBinary locates the rigd binary. Returns the path and whether this is an
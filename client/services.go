@@ -1,15 +1,37 @@
 package rig
 
-import "context"
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/matgreaves/rig/connect"
+)
 
 // GoDef defines a service built from a Go module. Use the Go() constructor
 // for the common case, or create a GoDef literal for full control.
 type GoDef struct {
-	module    string
-	args      []string
-	ingresses map[string]IngressDef
-	egresses  map[string]egressDef
-	hooks     hooksDef
+	module        string
+	buildTags     []string
+	buildFlags    []string
+	goVersion     string
+	args          []string
+	envFile       string
+	env           map[string]string
+	ingresses     map[string]IngressDef
+	egresses      map[string]egressDef
+	lastEgress    string
+	hooks         hooksDef
+	timeout       time.Duration
+	healthPath    string
+	healthStatus  int
+	successes     int
+	dependsOn     []string
+	taskQueues    []specTaskQueueSpec
+	scale         int
+	stopTimeout   *time.Duration
+	restartOnExit int
 }
 
 func (*GoDef) rigService() {}
@@ -29,6 +51,34 @@ func Go(module string) *GoDef {
 	}
 }
 
+// Clone returns a deep copy of the definition. Builder methods mutate
+// their receiver, so a base definition shared between subtests must be
+// cloned before it is customized:
+//
+//	base := rig.Go("./cmd/api").Egress("db")
+//	for _, level := range []string{"debug", "info"} {
+//		t.Run(level, func(t *testing.T) {
+//			t.Parallel()
+//			env := rig.Up(t, rig.Services{
+//				"api": base.Clone().Env("LOG_LEVEL", level),
+//				...
+//			})
+//		})
+//	}
+func (d *GoDef) Clone() *GoDef {
+	c := *d
+	c.buildTags = slices.Clone(d.buildTags)
+	c.buildFlags = slices.Clone(d.buildFlags)
+	c.args = slices.Clone(d.args)
+	c.env = maps.Clone(d.env)
+	c.ingresses = cloneIngresses(d.ingresses)
+	c.egresses = cloneEgresses(d.egresses)
+	c.hooks = d.hooks.clone()
+	c.dependsOn = slices.Clone(d.dependsOn)
+	c.taskQueues = slices.Clone(d.taskQueues)
+	return &c
+}
+
 // NoIngress removes all ingresses, for services that are pure workers
 // with only egress dependencies.
 func (d *GoDef) NoIngress() *GoDef {
@@ -65,6 +115,105 @@ func (d *GoDef) EgressAs(name, service string, ingress ...string) *GoDef {
 		eg.ingress = ingress[0]
 	}
 	d.egresses[name] = eg
+	d.lastEgress = name
+	return d
+}
+
+// EgressExternal adds a dependency on an address outside the environment,
+// such as a payment provider's sandbox, given as an http or https base URL.
+// The service is wired to an observe proxy that forwards to the URL, so the
+// calls show up in the traffic log (as request.completed events with the
+// egress name as target) and Mock works on them. The service speaks plain
+// HTTP to the proxy; the proxy speaks TLS to https URLs. Requires observe
+// (the default).
+//
+//	rig.Go("./cmd/api").EgressExternal("payments", "https://sandbox.example.com")
+func (d *GoDef) EgressExternal(name, url string) *GoDef {
+	if d.egresses == nil {
+		d.egresses = make(map[string]egressDef)
+	}
+	d.egresses[name] = egressDef{external: url}
+	d.lastEgress = name
+	return d
+}
+
+// AllowMethods restricts the most recently added egress to the listed gRPC
+// methods, given as "Method" or "pkg.Service/Method". The proxy on that
+// edge answers any other call with PERMISSION_DENIED without forwarding
+// it, simulating a service mesh policy. Requires observe (the default).
+//
+//	.EgressAs("temporal", "temporal").AllowMethods("StartWorkflowExecution")
+func (d *GoDef) AllowMethods(methods ...string) *GoDef {
+	allowEgressMethods(d.egresses, d.lastEgress, methods)
+	return d
+}
+
+// Mock makes the proxy on the most recently added egress answer matching
+// requests with a canned response instead of forwarding them, isolating the
+// service from a flaky or unavailable dependency. Mocks are checked in the
+// order added; unmatched requests are forwarded. Mocked requests appear in
+// the traffic log as request.mocked. HTTP egresses only; requires observe
+// (the default).
+//
+//	.EgressAs("payments", "payments").
+//	    Mock(rig.MockResponse{Method: "POST", Path: "/charges", Status: 200, Body: `{"id":"ch_1"}`})
+func (d *GoDef) Mock(m MockResponse) *GoDef {
+	mockEgress(d.egresses, d.lastEgress, m)
+	return d
+}
+
+// Database points the most recently added egress at one of the logical
+// databases declared on its Postgres target with PostgresDef.Databases.
+// The egress's PGDATABASE attribute then names that database instead of
+// the service's own.
+//
+//	.EgressAs("db", "postgres").Database("orders")
+func (d *GoDef) Database(name string) *GoDef {
+	selectEgressDatabase(d.egresses, d.lastEgress, name)
+	return d
+}
+
+// WaitForGRPCService holds the service until the target of the most
+// recently added egress lists each of the named gRPC services through
+// server reflection. A gRPC server can pass its health check before its
+// handlers are registered, so this avoids UNIMPLEMENTED errors at cold
+// start. Targets that don't serve reflection only need to pass the health
+// check. gRPC egresses only.
+//
+//	.Egress("orders").WaitForGRPCService("orders.v1.OrderService")
+func (d *GoDef) WaitForGRPCService(services ...string) *GoDef {
+	waitForEgressGRPCServices(d.egresses, d.lastEgress, services)
+	return d
+}
+
+// BuildTags sets the build tags the module is compiled with (go build
+// -tags). Builds with different tags are cached separately.
+func (d *GoDef) BuildTags(tags ...string) *GoDef {
+	d.buildTags = tags
+	return d
+}
+
+// BuildFlags sets extra go build arguments. Builds with different flags
+// are cached separately.
+//
+//	rig.Go("./cmd/api").BuildFlags("-ldflags", "-X main.version=1.2.3")
+func (d *GoDef) BuildFlags(flags ...string) *GoDef {
+	d.buildFlags = flags
+	return d
+}
+
+// GoVersion builds the module with a specific Go release instead of the go
+// command on PATH, so tests exercise the compiler production uses. The
+// build runs the golang.org/dl wrapper for that release, which must be
+// installed on the host:
+//
+//	go install golang.org/dl/go1.22.5@latest && go1.22.5 download
+//
+// Builds with different versions are cached separately.
+//
+//	rig.Go("./cmd/api").GoVersion("1.22.5")
+func (d *GoDef) GoVersion(version string) *GoDef {
+	d.goVersion = version
 	return d
 }
 
@@ -74,6 +223,43 @@ func (d *GoDef) Args(args ...string) *GoDef {
 	return d
 }
 
+// FakeClock starts the service with a fixed clock at t. The service reads
+// it with connect.Now(ctx), which stays at t until the environment's clock
+// is moved with Environment.AdvanceClock or connect.Advance. Services that
+// call time.Now directly are unaffected.
+func (d *GoDef) FakeClock(t time.Time) *GoDef {
+	if d.env == nil {
+		d.env = make(map[string]string)
+	}
+	d.env[connect.FakeNowEnv] = t.Format(time.RFC3339Nano)
+	return d
+}
+
+// Env sets an environment variable on the service process, e.g. a log
+// level or feature flag. Values are layered over the RIG_* wiring vars and
+// take priority over them, but RIG_WIRING itself cannot be overridden.
+// Func services share the test process's environment and have no Env.
+//
+//	rig.Go("./cmd/api").Env("LOG_LEVEL", "debug")
+func (d *GoDef) Env(key, value string) *GoDef {
+	if d.env == nil {
+		d.env = make(map[string]string)
+	}
+	d.env[key] = value
+	return d
+}
+
+// EnvFile loads KEY=VALUE pairs from a dotenv file into the service's
+// environment. The file is read when Up is called and a relative path is
+// resolved against the working directory. rig's wiring vars take priority
+// over values from the file.
+//
+//	rig.Go("./cmd/api").EnvFile(".env.test")
+func (d *GoDef) EnvFile(path string) *GoDef {
+	d.envFile = path
+	return d
+}
+
 // InitHook registers a client-side function that runs after health checks
 // pass, before the service is marked ready. Receives own ingresses only.
 func (d *GoDef) InitHook(fn func(ctx context.Context, w Wiring) error) *GoDef {
@@ -88,14 +274,127 @@ func (d *GoDef) PrestartHook(fn func(ctx context.Context, w Wiring) error) *GoDe
 	return d
 }
 
+// DependsOn makes this service start only after the named services are
+// ready. Unlike Egress, it is a pure ordering edge: no endpoint is wired
+// into the service and no proxy is inserted. Use it for dependencies that
+// don't involve a network connection, such as a migration job.
+//
+//	"worker": rig.Go("./cmd/worker").DependsOn("migrate"),
+func (d *GoDef) DependsOn(services ...string) *GoDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// WaitForTaskQueue holds the service back from ready until a worker is
+// polling the named Temporal task queue, so a workflow the test starts
+// right after Up doesn't sit waiting for a worker and time out on a cold
+// start. The queue is checked on the Temporal service behind the service's
+// sole egress to one; when it has several, name the egress.
+//
+//	rig.Go("./cmd/worker").Egress("temporal").WaitForTaskQueue("orders")
+func (d *GoDef) WaitForTaskQueue(queue string, egress ...string) *GoDef {
+	tq := specTaskQueueSpec{Name: queue}
+	if len(egress) > 0 {
+		tq.Egress = egress[0]
+	}
+	d.taskQueues = append(d.taskQueues, tq)
+	return d
+}
+
+// Scale runs n identical instances of the service, named "{name}-0",
+// "{name}-1", and so on, to test load-balanced behaviour. Egresses to the
+// service, including env.Endpoint, are spread across the instances one
+// connection at a time, and observed traffic names the instance that
+// served it. An instance can be addressed directly by its own name. n of 0
+// or 1 runs a single instance under the plain name.
+//
+//	"api":    rig.Go("./cmd/api").Scale(3),
+//	"client": rig.Go("./cmd/client").Egress("api"),
+func (d *GoDef) Scale(n int) *GoDef {
+	d.scale = n
+	return d
+}
+
+// StopTimeout bounds how long the service gets to exit after SIGINT at
+// teardown before its process group is killed. Zero kills it immediately.
+// By default rig waits for the process however long it takes.
+//
+//	rig.Go("./cmd/worker").StopTimeout(2 * time.Second)
+func (d *GoDef) StopTimeout(timeout time.Duration) *GoDef {
+	d.stopTimeout = &timeout
+	return d
+}
+
+// RestartOnExit restarts the service up to max times if it exits on its
+// own, for services that are expected to crash and come back. Each restart
+// emits a service.restarted event; the environment fails only when the
+// service exits again after the last one. A clean exit counts as an exit.
+//
+//	rig.Go("./cmd/consumer").RestartOnExit(3)
+func (d *GoDef) RestartOnExit(max int) *GoDef {
+	d.restartOnExit = max
+	return d
+}
+
+// Timeout overrides how long rig waits for this service's ready checks to
+// pass, in place of the server default. An ingress with its own
+// ReadyDef.Timeout keeps it. The whole Up is still bounded by WithTimeout.
+//
+//	rig.Go("./cmd/echo").Timeout(2 * time.Second)
+func (d *GoDef) Timeout(timeout time.Duration) *GoDef {
+	d.timeout = timeout
+	return d
+}
+
+// HealthPath sets the path the HTTP ready check probes on every HTTP
+// ingress that doesn't set its own ReadyDef.Path, for images whose "/"
+// isn't a usable health endpoint.
+//
+//	rig.Go("./cmd/api").HealthPath("/healthz")
+func (d *GoDef) HealthPath(path string) *GoDef {
+	d.healthPath = path
+	return d
+}
+
+// HealthStatus makes the HTTP ready check require this exact status on
+// every HTTP ingress that doesn't set its own ReadyDef.Status. Without it
+// any status below 500 counts as ready.
+func (d *GoDef) HealthStatus(code int) *GoDef {
+	d.healthStatus = code
+	return d
+}
+
+// ReadySuccesses makes every ingress that doesn't set its own
+// ReadyDef.Successes pass n ready checks in a row before the service is
+// ready, for services whose port opens and closes again while they warm
+// up. A failed check starts the count again. Checks are spaced by the
+// ReadyDef.Interval (default 10ms).
+//
+//	rig.Go("./cmd/api").ReadySuccesses(3)
+func (d *GoDef) ReadySuccesses(n int) *GoDef {
+	d.successes = n
+	return d
+}
+
 // FuncDef defines a service backed by a Go function running in the test
 // process. The function receives a context with wiring injected — use
 // connect.ParseWiring(ctx) to access it, just like a standalone binary.
+// There is no Env: the function shares the test process's environment, so
+// pass configuration through the closure.
 type FuncDef struct {
-	fn        func(ctx context.Context) error
-	ingresses map[string]IngressDef
-	egresses  map[string]egressDef
-	hooks     hooksDef
+	fn           func(ctx context.Context) error
+	fakeNow      time.Time
+	ingresses    map[string]IngressDef
+	egresses     map[string]egressDef
+	lastEgress   string
+	hooks        hooksDef
+	timeout      time.Duration
+	healthPath   string
+	healthStatus int
+	successes    int
+	dependsOn    []string
+	taskQueues   []specTaskQueueSpec
+	scale        int
 }
 
 func (*FuncDef) rigService() {}
@@ -109,6 +408,11 @@ func (*FuncDef) rigService() {}
 // context when available, falling back to environment variables.
 //
 //	rig.Func(echo.Run).Egress("db")
+//
+// A function serving gRPC declares a gRPC ingress; rig then readies it with
+// the standard gRPC health check instead of an HTTP GET:
+//
+//	rig.Func(greeter.Run).Ingress("default", rig.IngressGRPC())
 func Func(fn func(ctx context.Context) error) *FuncDef {
 	return &FuncDef{
 		fn:        fn,
@@ -116,6 +420,17 @@ func Func(fn func(ctx context.Context) error) *FuncDef {
 	}
 }
 
+// Clone returns a deep copy of the definition. See GoDef.Clone.
+func (d *FuncDef) Clone() *FuncDef {
+	c := *d
+	c.ingresses = cloneIngresses(d.ingresses)
+	c.egresses = cloneEgresses(d.egresses)
+	c.hooks = d.hooks.clone()
+	c.dependsOn = slices.Clone(d.dependsOn)
+	c.taskQueues = slices.Clone(d.taskQueues)
+	return &c
+}
+
 // NoIngress removes all ingresses.
 func (d *FuncDef) NoIngress() *FuncDef {
 	d.ingresses = nil
@@ -146,6 +461,54 @@ func (d *FuncDef) EgressAs(name, service string, ingress ...string) *FuncDef {
 		eg.ingress = ingress[0]
 	}
 	d.egresses[name] = eg
+	d.lastEgress = name
+	return d
+}
+
+// EgressExternal adds a dependency on an address outside the environment.
+// See GoDef.EgressExternal.
+func (d *FuncDef) EgressExternal(name, url string) *FuncDef {
+	if d.egresses == nil {
+		d.egresses = make(map[string]egressDef)
+	}
+	d.egresses[name] = egressDef{external: url}
+	d.lastEgress = name
+	return d
+}
+
+// AllowMethods restricts the most recently added egress to the listed gRPC
+// methods. See GoDef.AllowMethods.
+func (d *FuncDef) AllowMethods(methods ...string) *FuncDef {
+	allowEgressMethods(d.egresses, d.lastEgress, methods)
+	return d
+}
+
+// Mock makes the proxy on the most recently added egress answer matching
+// requests with a canned response. See GoDef.Mock.
+func (d *FuncDef) Mock(m MockResponse) *FuncDef {
+	mockEgress(d.egresses, d.lastEgress, m)
+	return d
+}
+
+// Database points the most recently added egress at a logical database on
+// its Postgres target. See GoDef.Database.
+func (d *FuncDef) Database(name string) *FuncDef {
+	selectEgressDatabase(d.egresses, d.lastEgress, name)
+	return d
+}
+
+// WaitForGRPCService holds the service until the target of the most
+// recently added egress registers the named gRPC services. See
+// GoDef.WaitForGRPCService.
+func (d *FuncDef) WaitForGRPCService(services ...string) *FuncDef {
+	waitForEgressGRPCServices(d.egresses, d.lastEgress, services)
+	return d
+}
+
+// FakeClock runs the function with a fixed clock at t, read via
+// connect.Now(ctx). See GoDef.FakeClock.
+func (d *FuncDef) FakeClock(t time.Time) *FuncDef {
+	d.fakeNow = t
 	return d
 }
 
@@ -161,15 +524,77 @@ func (d *FuncDef) PrestartHook(fn func(ctx context.Context, w Wiring) error) *Fu
 	return d
 }
 
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *FuncDef) DependsOn(services ...string) *FuncDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// WaitForTaskQueue holds the service back from ready until a worker is
+// polling the named Temporal task queue. See GoDef.WaitForTaskQueue.
+func (d *FuncDef) WaitForTaskQueue(queue string, egress ...string) *FuncDef {
+	tq := specTaskQueueSpec{Name: queue}
+	if len(egress) > 0 {
+		tq.Egress = egress[0]
+	}
+	d.taskQueues = append(d.taskQueues, tq)
+	return d
+}
+
+// Scale runs n identical instances of the service. See GoDef.Scale.
+func (d *FuncDef) Scale(n int) *FuncDef {
+	d.scale = n
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *FuncDef) Timeout(timeout time.Duration) *FuncDef {
+	d.timeout = timeout
+	return d
+}
+
+// HealthPath sets the HTTP ready check path. See GoDef.HealthPath.
+func (d *FuncDef) HealthPath(path string) *FuncDef {
+	d.healthPath = path
+	return d
+}
+
+// HealthStatus sets the status the HTTP ready check requires. See
+// GoDef.HealthStatus.
+func (d *FuncDef) HealthStatus(code int) *FuncDef {
+	d.healthStatus = code
+	return d
+}
+
+// ReadySuccesses sets how many ready checks in a row must pass. See
+// GoDef.ReadySuccesses.
+func (d *FuncDef) ReadySuccesses(n int) *FuncDef {
+	d.successes = n
+	return d
+}
+
 // ProcessDef defines a service that runs a pre-built binary. Use the
 // Process() constructor or create a ProcessDef literal for full control.
 type ProcessDef struct {
-	command   string
-	dir       string
-	args      []string
-	ingresses map[string]IngressDef
-	egresses  map[string]egressDef
-	hooks     hooksDef
+	command       string
+	dir           string
+	args          []string
+	envFile       string
+	env           map[string]string
+	ingresses     map[string]IngressDef
+	egresses      map[string]egressDef
+	lastEgress    string
+	hooks         hooksDef
+	timeout       time.Duration
+	healthPath    string
+	healthStatus  int
+	successes     int
+	dependsOn     []string
+	taskQueues    []specTaskQueueSpec
+	scale         int
+	stopTimeout   *time.Duration
+	restartOnExit int
 }
 
 func (*ProcessDef) rigService() {}
@@ -186,6 +611,19 @@ func Process(command string) *ProcessDef {
 	}
 }
 
+// Clone returns a deep copy of the definition. See GoDef.Clone.
+func (d *ProcessDef) Clone() *ProcessDef {
+	c := *d
+	c.args = slices.Clone(d.args)
+	c.env = maps.Clone(d.env)
+	c.ingresses = cloneIngresses(d.ingresses)
+	c.egresses = cloneEgresses(d.egresses)
+	c.hooks = d.hooks.clone()
+	c.dependsOn = slices.Clone(d.dependsOn)
+	c.taskQueues = slices.Clone(d.taskQueues)
+	return &c
+}
+
 // NoIngress removes all ingresses, for services that are pure workers
 // with only egress dependencies.
 func (d *ProcessDef) NoIngress() *ProcessDef {
@@ -223,6 +661,47 @@ func (d *ProcessDef) EgressAs(name, service string, ingress ...string) *ProcessD
 		eg.ingress = ingress[0]
 	}
 	d.egresses[name] = eg
+	d.lastEgress = name
+	return d
+}
+
+// EgressExternal adds a dependency on an address outside the environment.
+// See GoDef.EgressExternal.
+func (d *ProcessDef) EgressExternal(name, url string) *ProcessDef {
+	if d.egresses == nil {
+		d.egresses = make(map[string]egressDef)
+	}
+	d.egresses[name] = egressDef{external: url}
+	d.lastEgress = name
+	return d
+}
+
+// AllowMethods restricts the most recently added egress to the listed gRPC
+// methods. See GoDef.AllowMethods.
+func (d *ProcessDef) AllowMethods(methods ...string) *ProcessDef {
+	allowEgressMethods(d.egresses, d.lastEgress, methods)
+	return d
+}
+
+// Mock makes the proxy on the most recently added egress answer matching
+// requests with a canned response. See GoDef.Mock.
+func (d *ProcessDef) Mock(m MockResponse) *ProcessDef {
+	mockEgress(d.egresses, d.lastEgress, m)
+	return d
+}
+
+// Database points the most recently added egress at a logical database on
+// its Postgres target. See GoDef.Database.
+func (d *ProcessDef) Database(name string) *ProcessDef {
+	selectEgressDatabase(d.egresses, d.lastEgress, name)
+	return d
+}
+
+// WaitForGRPCService holds the service until the target of the most
+// recently added egress registers the named gRPC services. See
+// GoDef.WaitForGRPCService.
+func (d *ProcessDef) WaitForGRPCService(services ...string) *ProcessDef {
+	waitForEgressGRPCServices(d.egresses, d.lastEgress, services)
 	return d
 }
 
@@ -232,6 +711,32 @@ func (d *ProcessDef) Args(args ...string) *ProcessDef {
 	return d
 }
 
+// FakeClock starts the service with a fixed clock at t, read via
+// connect.Now(ctx). See GoDef.FakeClock.
+func (d *ProcessDef) FakeClock(t time.Time) *ProcessDef {
+	if d.env == nil {
+		d.env = make(map[string]string)
+	}
+	d.env[connect.FakeNowEnv] = t.Format(time.RFC3339Nano)
+	return d
+}
+
+// Env sets an environment variable on the service process. See GoDef.Env.
+func (d *ProcessDef) Env(key, value string) *ProcessDef {
+	if d.env == nil {
+		d.env = make(map[string]string)
+	}
+	d.env[key] = value
+	return d
+}
+
+// EnvFile loads KEY=VALUE pairs from a dotenv file into the service's
+// environment. See GoDef.EnvFile.
+func (d *ProcessDef) EnvFile(path string) *ProcessDef {
+	d.envFile = path
+	return d
+}
+
 // InitHook registers a client-side init hook function.
 func (d *ProcessDef) InitHook(fn func(ctx context.Context, w Wiring) error) *ProcessDef {
 	d.hooks.init = append(d.hooks.init, hookFunc(fn))
@@ -244,15 +749,87 @@ func (d *ProcessDef) PrestartHook(fn func(ctx context.Context, w Wiring) error)
 	return d
 }
 
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *ProcessDef) DependsOn(services ...string) *ProcessDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// WaitForTaskQueue holds the service back from ready until a worker is
+// polling the named Temporal task queue. See GoDef.WaitForTaskQueue.
+func (d *ProcessDef) WaitForTaskQueue(queue string, egress ...string) *ProcessDef {
+	tq := specTaskQueueSpec{Name: queue}
+	if len(egress) > 0 {
+		tq.Egress = egress[0]
+	}
+	d.taskQueues = append(d.taskQueues, tq)
+	return d
+}
+
+// Scale runs n identical instances of the service. See GoDef.Scale.
+func (d *ProcessDef) Scale(n int) *ProcessDef {
+	d.scale = n
+	return d
+}
+
+// StopTimeout bounds how long the process gets to exit at teardown. See
+// GoDef.StopTimeout.
+func (d *ProcessDef) StopTimeout(timeout time.Duration) *ProcessDef {
+	d.stopTimeout = &timeout
+	return d
+}
+
+// RestartOnExit restarts the process up to max times if it exits. See
+// GoDef.RestartOnExit.
+func (d *ProcessDef) RestartOnExit(max int) *ProcessDef {
+	d.restartOnExit = max
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *ProcessDef) Timeout(timeout time.Duration) *ProcessDef {
+	d.timeout = timeout
+	return d
+}
+
+// HealthPath sets the HTTP ready check path. See GoDef.HealthPath.
+func (d *ProcessDef) HealthPath(path string) *ProcessDef {
+	d.healthPath = path
+	return d
+}
+
+// HealthStatus sets the status the HTTP ready check requires. See
+// GoDef.HealthStatus.
+func (d *ProcessDef) HealthStatus(code int) *ProcessDef {
+	d.healthStatus = code
+	return d
+}
+
+// ReadySuccesses sets how many ready checks in a row must pass. See
+// GoDef.ReadySuccesses.
+func (d *ProcessDef) ReadySuccesses(n int) *ProcessDef {
+	d.successes = n
+	return d
+}
+
 // CustomDef defines a service using any server-registered type. This is the
 // escape hatch for types not yet modeled in the SDK.
 type CustomDef struct {
-	svcType   string
-	config    map[string]any
-	args      []string
-	ingresses map[string]IngressDef
-	egresses  map[string]egressDef
-	hooks     hooksDef
+	svcType      string
+	config       map[string]any
+	args         []string
+	ingresses    map[string]IngressDef
+	egresses     map[string]egressDef
+	lastEgress   string
+	hooks        hooksDef
+	timeout      time.Duration
+	healthPath   string
+	healthStatus int
+	successes    int
+	dependsOn    []string
+	taskQueues   []specTaskQueueSpec
+	scale        int
 }
 
 func (*CustomDef) rigService() {}
@@ -267,6 +844,21 @@ func Custom(svcType string, config map[string]any) *CustomDef {
 	}
 }
 
+// Clone returns a deep copy of the definition. See GoDef.Clone. Only the
+// top level of the config map is copied; nested maps and slices in it are
+// shared with the original.
+func (d *CustomDef) Clone() *CustomDef {
+	c := *d
+	c.config = maps.Clone(d.config)
+	c.args = slices.Clone(d.args)
+	c.ingresses = cloneIngresses(d.ingresses)
+	c.egresses = cloneEgresses(d.egresses)
+	c.hooks = d.hooks.clone()
+	c.dependsOn = slices.Clone(d.dependsOn)
+	c.taskQueues = slices.Clone(d.taskQueues)
+	return &c
+}
+
 // NoIngress removes all ingresses, for services that are pure workers
 // with only egress dependencies.
 func (d *CustomDef) NoIngress() *CustomDef {
@@ -298,6 +890,47 @@ func (d *CustomDef) EgressAs(name, service string, ingress ...string) *CustomDef
 		eg.ingress = ingress[0]
 	}
 	d.egresses[name] = eg
+	d.lastEgress = name
+	return d
+}
+
+// EgressExternal adds a dependency on an address outside the environment.
+// See GoDef.EgressExternal.
+func (d *CustomDef) EgressExternal(name, url string) *CustomDef {
+	if d.egresses == nil {
+		d.egresses = make(map[string]egressDef)
+	}
+	d.egresses[name] = egressDef{external: url}
+	d.lastEgress = name
+	return d
+}
+
+// AllowMethods restricts the most recently added egress to the listed gRPC
+// methods. See GoDef.AllowMethods.
+func (d *CustomDef) AllowMethods(methods ...string) *CustomDef {
+	allowEgressMethods(d.egresses, d.lastEgress, methods)
+	return d
+}
+
+// Mock makes the proxy on the most recently added egress answer matching
+// requests with a canned response. See GoDef.Mock.
+func (d *CustomDef) Mock(m MockResponse) *CustomDef {
+	mockEgress(d.egresses, d.lastEgress, m)
+	return d
+}
+
+// Database points the most recently added egress at a logical database on
+// its Postgres target. See GoDef.Database.
+func (d *CustomDef) Database(name string) *CustomDef {
+	selectEgressDatabase(d.egresses, d.lastEgress, name)
+	return d
+}
+
+// WaitForGRPCService holds the service until the target of the most
+// recently added egress registers the named gRPC services. See
+// GoDef.WaitForGRPCService.
+func (d *CustomDef) WaitForGRPCService(services ...string) *CustomDef {
+	waitForEgressGRPCServices(d.egresses, d.lastEgress, services)
 	return d
 }
 
@@ -318,3 +951,53 @@ func (d *CustomDef) PrestartHook(fn func(ctx context.Context, w Wiring) error) *
 	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
 	return d
 }
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *CustomDef) DependsOn(services ...string) *CustomDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// WaitForTaskQueue holds the service back from ready until a worker is
+// polling the named Temporal task queue. See GoDef.WaitForTaskQueue.
+func (d *CustomDef) WaitForTaskQueue(queue string, egress ...string) *CustomDef {
+	tq := specTaskQueueSpec{Name: queue}
+	if len(egress) > 0 {
+		tq.Egress = egress[0]
+	}
+	d.taskQueues = append(d.taskQueues, tq)
+	return d
+}
+
+// Scale runs n identical instances of the service. See GoDef.Scale.
+func (d *CustomDef) Scale(n int) *CustomDef {
+	d.scale = n
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *CustomDef) Timeout(timeout time.Duration) *CustomDef {
+	d.timeout = timeout
+	return d
+}
+
+// HealthPath sets the HTTP ready check path. See GoDef.HealthPath.
+func (d *CustomDef) HealthPath(path string) *CustomDef {
+	d.healthPath = path
+	return d
+}
+
+// HealthStatus sets the status the HTTP ready check requires. See
+// GoDef.HealthStatus.
+func (d *CustomDef) HealthStatus(code int) *CustomDef {
+	d.healthStatus = code
+	return d
+}
+
+// ReadySuccesses sets how many ready checks in a row must pass. See
+// GoDef.ReadySuccesses.
+func (d *CustomDef) ReadySuccesses(n int) *CustomDef {
+	d.successes = n
+	return d
+}
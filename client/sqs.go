@@ -1,6 +1,9 @@
 package rig
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // SQSDef defines a service backed by the builtin SQS type.
 // Rig manages the container lifecycle and queue isolation — the API
@@ -10,8 +13,10 @@ import "context"
 // AWS_SECRET_ACCESS_KEY as endpoint attributes.
 // Each environment gets an isolated queue assigned by the server.
 type SQSDef struct {
-	egresses map[string]egressDef
-	hooks    hooksDef
+	egresses  map[string]egressDef
+	hooks     hooksDef
+	timeout   time.Duration
+	dependsOn []string
 }
 
 func (*SQSDef) rigService() {}
@@ -54,3 +59,16 @@ func (d *SQSDef) PrestartHook(fn func(ctx context.Context, w Wiring) error) *SQS
 	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
 	return d
 }
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *SQSDef) DependsOn(services ...string) *SQSDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *SQSDef) Timeout(timeout time.Duration) *SQSDef {
+	d.timeout = timeout
+	return d
+}
@@ -6,8 +6,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/matgreaves/rig/connect"
 )
@@ -21,20 +23,27 @@ type streamState struct {
 // wireEvent mirrors the server's Event type for JSON decoding from the SSE
 // stream. Only the fields the SDK needs are included.
 type wireEvent struct {
+	Seq        uint64                             `json:"seq"`
 	Type       string                             `json:"type"`
 	Service    string                             `json:"service,omitempty"`
 	Ingress    string                             `json:"ingress,omitempty"`
 	Artifact   string                             `json:"artifact,omitempty"`
 	Error      string                             `json:"error,omitempty"`
 	Message    string                             `json:"message,omitempty"`
+	ExitCode   *int                               `json:"exit_code,omitempty"`
+	Outcome    string                             `json:"outcome,omitempty"`
 	Callback   *wireCallbackRequest               `json:"callback,omitempty"`
-	Request    *wireRequestInfo                   `json:"request,omitempty"`
-	Connection *wireConnectionInfo                `json:"connection,omitempty"`
+	Request    *RequestInfo                       `json:"request,omitempty"`
+	Connection *ConnectionInfo                    `json:"connection,omitempty"`
+	GRPCCall   *GRPCCallInfo                      `json:"grpc_call,omitempty"`
+	GRPCStream *GRPCStreamInfo                    `json:"grpc_stream,omitempty"`
 	EnvDir     string                             `json:"env_dir,omitempty"`
 	Ingresses  map[string]map[string]wireEndpoint `json:"ingresses,omitempty"`
+	Timestamp  time.Time                          `json:"timestamp"`
 }
 
-type wireRequestInfo struct {
+// RequestInfo describes an HTTP request observed by the traffic proxy.
+type RequestInfo struct {
 	Source       string  `json:"source"`
 	Target       string  `json:"target"`
 	Ingress      string  `json:"ingress"`
@@ -44,9 +53,20 @@ type wireRequestInfo struct {
 	LatencyMs    float64 `json:"latency_ms"`
 	RequestSize  int64   `json:"request_size"`
 	ResponseSize int64   `json:"response_size"`
+
+	RequestHeaders  map[string][]string `json:"request_headers,omitempty"`
+	RequestBody     []byte              `json:"request_body,omitempty"` // capped at the observe body limit
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+	ResponseBody    []byte              `json:"response_body,omitempty"`
+	ProxyInjected   bool                `json:"proxy_injected,omitempty"` // answered by the proxy (mock, fault, body limit)
+
+	TraceID      string `json:"trace_id,omitempty"` // see the X-Rig-Trace header
+	SpanID       string `json:"span_id,omitempty"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
 }
 
-type wireConnectionInfo struct {
+// ConnectionInfo describes a TCP connection observed by the traffic proxy.
+type ConnectionInfo struct {
 	Source     string  `json:"source"`
 	Target     string  `json:"target"`
 	Ingress    string  `json:"ingress"`
@@ -55,6 +75,48 @@ type wireConnectionInfo struct {
 	DurationMs float64 `json:"duration_ms"`
 }
 
+// GRPCCallInfo describes a gRPC call observed by the traffic proxy.
+type GRPCCallInfo struct {
+	Source              string          `json:"source"`
+	Target              string          `json:"target"`
+	Ingress             string          `json:"ingress"`
+	Service             string          `json:"service"`
+	Method              string          `json:"method"`
+	GRPCStatus          string          `json:"grpc_status"`
+	GRPCMessage         string          `json:"grpc_message"`
+	LatencyMs           float64         `json:"latency_ms"`
+	RequestBody         []byte          `json:"request_body,omitempty"`
+	ResponseBody        []byte          `json:"response_body,omitempty"`
+	RequestBodyDecoded  json.RawMessage `json:"request_body_decoded,omitempty"`
+	ResponseBodyDecoded json.RawMessage `json:"response_body_decoded,omitempty"`
+	TraceID             string          `json:"trace_id,omitempty"`
+	SpanID              string          `json:"span_id,omitempty"`
+	ParentSpanID        string          `json:"parent_span_id,omitempty"`
+}
+
+// GRPCStreamInfo describes a streaming gRPC call observed by the traffic
+// proxy. grpc.stream.message events set Direction ("in" from the client,
+// "out" from the target) and the message body; grpc.stream.closed sets the
+// final status and message counts.
+type GRPCStreamInfo struct {
+	Source       string          `json:"source"`
+	Target       string          `json:"target"`
+	Ingress      string          `json:"ingress"`
+	Service      string          `json:"service"`
+	Method       string          `json:"method"`
+	Direction    string          `json:"direction,omitempty"`
+	Body         []byte          `json:"body,omitempty"`
+	BodyDecoded  json.RawMessage `json:"body_decoded,omitempty"`
+	GRPCStatus   string          `json:"grpc_status,omitempty"`
+	GRPCMessage  string          `json:"grpc_message,omitempty"`
+	MessagesIn   int64           `json:"messages_in,omitempty"`
+	MessagesOut  int64           `json:"messages_out,omitempty"`
+	DurationMs   float64         `json:"duration_ms,omitempty"`
+	TraceID      string          `json:"trace_id,omitempty"`
+	SpanID       string          `json:"span_id,omitempty"`
+	ParentSpanID string          `json:"parent_span_id,omitempty"`
+}
+
 type wireCallbackRequest struct {
 	RequestID string             `json:"request_id"`
 	Name      string             `json:"name"`
@@ -75,18 +137,10 @@ type wireEndpoint struct {
 	Attributes map[string]any `json:"attributes,omitempty"`
 }
 
-// streamUntilReady connects to the SSE stream and processes events until
-// environment.up arrives (success) or environment.down arrives (failure).
-// funcCtx is the context for client-side functions (cancelled during cleanup).
-// startHandlers maps start callback names to functions launched asynchronously.
-func streamUntilReady(
-	ctx context.Context,
-	serverURL string,
-	envID string,
-	handlers map[string]hookFunc,
-	funcCtx context.Context,
-	startHandlers map[string]startFunc,
-) (*Environment, error) {
+// openEventStream connects to the environment's SSE stream. The server
+// replays events from the start, so nothing is missed between creating the
+// environment and subscribing. The stream lives as long as ctx.
+func openEventStream(ctx context.Context, serverURL, envID string) (io.ReadCloser, error) {
 	url := fmt.Sprintf("%s/environments/%s/events", serverURL, envID)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -98,13 +152,28 @@ func streamUntilReady(
 	if err != nil {
 		return nil, fmt.Errorf("connect to event stream: %w", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
 		return nil, fmt.Errorf("event stream: HTTP %d", resp.StatusCode)
 	}
+	return resp.Body, nil
+}
 
-	scanner := bufio.NewScanner(resp.Body)
+// streamUntilReady processes events from an open SSE stream until
+// environment.up arrives (success) or environment.down arrives (failure).
+// funcCtx is the context for client-side functions (cancelled during cleanup).
+// startHandlers maps start callback names to functions launched asynchronously.
+func streamUntilReady(
+	ctx context.Context,
+	stream io.Reader,
+	serverURL string,
+	envID string,
+	handlers map[string]hookFunc,
+	funcCtx context.Context,
+	startHandlers map[string]startFunc,
+) (*Environment, error) {
+	scanner := bufio.NewScanner(stream)
 	var eventType, data string
 	var state streamState
 
@@ -219,12 +288,14 @@ func dispatchHookCallback(
 ) error {
 	handler, ok := handlers[cb.Name]
 	if !ok {
-		postCallbackResult(serverURL, envID, serviceName, cb.RequestID,
+		postCallbackResult(serverURL, envID, serviceName, cb.RequestID, nil,
 			fmt.Errorf("no handler registered for callback %q", cb.Name))
 		return fmt.Errorf("no handler registered for callback %q", cb.Name)
 	}
 
 	wiring := convertWiring(cb.Wiring)
+	outputs := &hookOutputs{}
+	hookCtx := context.WithValue(ctx, hookOutputsKey{}, outputs)
 
 	var handlerErr error
 	func() {
@@ -233,10 +304,13 @@ func dispatchHookCallback(
 				handlerErr = fmt.Errorf("panic in hook handler: %v", r)
 			}
 		}()
-		handlerErr = handler(ctx, wiring)
+		handlerErr = handler(hookCtx, wiring)
 	}()
 
-	if err := postCallbackResult(serverURL, envID, serviceName, cb.RequestID, handlerErr); err != nil {
+	outputs.mu.Lock()
+	data := outputs.values
+	outputs.mu.Unlock()
+	if err := postCallbackResult(serverURL, envID, serviceName, cb.RequestID, data, handlerErr); err != nil {
 		return err
 	}
 	return handlerErr
@@ -256,7 +330,7 @@ func dispatchStartCallback(
 ) error {
 	handler, ok := startHandlers[cb.Name]
 	if !ok {
-		postCallbackResult(serverURL, envID, serviceName, cb.RequestID,
+		postCallbackResult(serverURL, envID, serviceName, cb.RequestID, nil,
 			fmt.Errorf("no start handler registered for callback %q", cb.Name))
 		return fmt.Errorf("no start handler registered for callback %q", cb.Name)
 	}
@@ -281,7 +355,7 @@ func dispatchStartCallback(
 	}()
 
 	// Respond immediately — the function is running.
-	return postCallbackResult(serverURL, envID, serviceName, cb.RequestID, nil)
+	return postCallbackResult(serverURL, envID, serviceName, cb.RequestID, nil, nil)
 }
 
 // postClientEvent POSTs a client event to the server's unified events endpoint.
@@ -297,17 +371,20 @@ func postClientEvent(serverURL, envID string, payload any) error {
 	return nil
 }
 
-// postCallbackResult posts a callback.response event to the server.
-func postCallbackResult(serverURL, envID, serviceName, requestID string, handlerErr error) error {
+// postCallbackResult posts a callback.response event to the server. data
+// carries the outputs a hook set with SetOutput.
+func postCallbackResult(serverURL, envID, serviceName, requestID string, data map[string]string, handlerErr error) error {
 	payload := struct {
-		Type      string `json:"type"`
-		Service   string `json:"service"`
-		RequestID string `json:"request_id"`
-		Error     string `json:"error,omitempty"`
+		Type      string            `json:"type"`
+		Service   string            `json:"service"`
+		RequestID string            `json:"request_id"`
+		Error     string            `json:"error,omitempty"`
+		Data      map[string]string `json:"data,omitempty"`
 	}{
 		Type:      "callback.response",
 		Service:   serviceName,
 		RequestID: requestID,
+		Data:      data,
 	}
 	if handlerErr != nil {
 		payload.Error = handlerErr.Error()
@@ -2,63 +2,188 @@ package rig
 
 import (
 	"fmt"
+	"net/http"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"testing"
+	"time"
 )
 
-// rigTB wraps a testing.TB to intercept assertion failures and post them
+// TB wraps a testing.TB to intercept assertion failures and post them
 // as test.note events to the rig server's event log. This creates a unified
 // timeline of server-side events and client-side test assertions.
 //
 // Helper() is NOT overridden — calls pass through to the embedded TB,
 // preserving correct file:line reporting even when assertion libraries
 // (testify, is, require, etc.) call t.Helper() internally.
-type rigTB struct {
+//
+// TB's own assertion helpers (Equal, AssertStatus, AssertEventually)
+// additionally attach the structured failure — file, line, the field checked,
+// want, and got — to the test.note event, so tooling can render and diff
+// failures without parsing the message.
+type TB struct {
 	testing.TB
 	serverURL string
 	envID     string
 }
 
-func (tb *rigTB) Error(args ...any) {
+func (tb *TB) Error(args ...any) {
 	tb.Helper()
 	msg := fmt.Sprint(args...)
 	tb.postNote(msg)
 	tb.TB.Error(args...)
 }
 
-func (tb *rigTB) Errorf(format string, args ...any) {
+func (tb *TB) Errorf(format string, args ...any) {
 	tb.Helper()
 	msg := fmt.Sprintf(format, args...)
 	tb.postNote(msg)
 	tb.TB.Errorf(format, args...)
 }
 
-func (tb *rigTB) Fatal(args ...any) {
+func (tb *TB) Fatal(args ...any) {
 	tb.Helper()
 	msg := fmt.Sprint(args...)
 	tb.postNote(msg)
 	tb.TB.Fatal(args...)
 }
 
-func (tb *rigTB) Fatalf(format string, args ...any) {
+func (tb *TB) Fatalf(format string, args ...any) {
 	tb.Helper()
 	msg := fmt.Sprintf(format, args...)
 	tb.postNote(msg)
 	tb.TB.Fatalf(format, args...)
 }
 
-func (tb *rigTB) postNote(msg string) {
-	// Capture the caller's file:line. Skip postNote (0) and the
-	// Error/Errorf/Fatal/Fatalf wrapper (1) to reach the call site.
-	if _, file, line, ok := runtime.Caller(2); ok {
+// Equal reports whether want and got are deeply equal. If not, it marks the
+// test as failed (like Errorf) and records want and got on the test.note
+// event.
+//
+//	env.TB().Equal(200, resp.StatusCode)
+func (tb *TB) Equal(want, got any) bool {
+	tb.Helper()
+	if reflect.DeepEqual(want, got) {
+		return true
+	}
+	a := &noteAssertion{Want: formatValue(want), Got: formatValue(got)}
+	msg := fmt.Sprintf("not equal: want %s, got %s", a.Want, a.Got)
+	tb.post(msg, a)
+	tb.TB.Errorf("%s", msg)
+	return false
+}
+
+// AssertStatus reports whether resp has the wanted HTTP status code. If not,
+// it marks the test as failed (like Errorf) and records the status field,
+// want, and got on the test.note event. A nil resp fails with got "no
+// response".
+//
+//	resp, err := client.Get("/orders")
+//	if err == nil {
+//		env.TB().AssertStatus(resp, http.StatusOK)
+//	}
+func (tb *TB) AssertStatus(resp *http.Response, want int) bool {
+	tb.Helper()
+	if resp != nil && resp.StatusCode == want {
+		return true
+	}
+	a := &noteAssertion{Field: "status", Want: fmt.Sprint(want), Got: "no response"}
+	msg := fmt.Sprintf("expected status %d, got no response", want)
+	if resp != nil {
+		a.Got = fmt.Sprint(resp.StatusCode)
+		msg = fmt.Sprintf("expected status %d, got %d", want, resp.StatusCode)
+		if req := resp.Request; req != nil && req.URL != nil {
+			msg += fmt.Sprintf(" (%s %s)", req.Method, req.URL.Path)
+		}
+	}
+	tb.post(msg, a)
+	tb.TB.Errorf("%s", msg)
+	return false
+}
+
+// AssertEventually polls cond until it returns nil or timeout elapses,
+// backing off from 10ms to 1s between attempts. If the condition never
+// holds, it marks the test as failed (like Errorf) and records cond's last
+// error as got on the test.note event.
+//
+//	env.TB().AssertEventually(func() error {
+//		n, err := countOrders(db)
+//		if err == nil && n != 1 {
+//			err = fmt.Errorf("%d orders", n)
+//		}
+//		return err
+//	}, 5*time.Second)
+func (tb *TB) AssertEventually(cond func() error, timeout time.Duration) bool {
+	tb.Helper()
+	deadline := time.Now().Add(timeout)
+	interval := 10 * time.Millisecond
+	var err error
+	for {
+		if err = cond(); err == nil {
+			return true
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(min(interval, max(time.Until(deadline), 0)))
+		interval = min(interval*2, time.Second)
+	}
+	a := &noteAssertion{
+		Field: "condition",
+		Want:  "holds within " + timeout.String(),
+		Got:   err.Error(),
+	}
+	msg := fmt.Sprintf("condition not met within %s: %v", timeout, err)
+	tb.post(msg, a)
+	tb.TB.Errorf("%s", msg)
+	return false
+}
+
+// noteAssertion is the structured part of a test.note event.
+type noteAssertion struct {
+	File  string `json:"file,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Field string `json:"field,omitempty"` // what was checked, e.g. "status"
+	Want  string `json:"want,omitempty"`
+	Got   string `json:"got,omitempty"`
+}
+
+func (tb *TB) postNote(msg string) {
+	tb.post(msg, nil)
+}
+
+// post sends a test.note event. It must be called directly from the
+// exported assertion method so the caller's file:line can be found.
+func (tb *TB) post(msg string, a *noteAssertion) {
+	// Skip post (0), postNote or the assertion helper (1) and, for
+	// postNote, the Error/Errorf/Fatal/Fatalf wrapper (2).
+	skip := 3
+	if a != nil {
+		skip = 2
+	}
+	if _, file, line, ok := runtime.Caller(skip); ok {
 		msg = fmt.Sprintf("%s:%d: %s", filepath.Base(file), line, msg)
+		if a != nil {
+			a.File = filepath.Base(file)
+			a.Line = line
+		}
 	}
 	postClientEvent(tb.serverURL, tb.envID, struct {
-		Type  string `json:"type"`
-		Error string `json:"error"`
+		Type      string         `json:"type"`
+		Error     string         `json:"error"`
+		Assertion *noteAssertion `json:"assertion,omitempty"`
 	}{
-		Type:  "test.note",
-		Error: msg,
+		Type:      "test.note",
+		Error:     msg,
+		Assertion: a,
 	})
 }
+
+// formatValue renders an assertion operand. Strings are quoted so that
+// "200" and 200 are distinguishable.
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
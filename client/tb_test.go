@@ -0,0 +1,155 @@
+package rig
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// noteCollector is a fake rigd events endpoint that records posted notes.
+type noteCollector struct {
+	mu    sync.Mutex
+	notes []map[string]any
+}
+
+func newNoteServer(t *testing.T) (*noteCollector, *httptest.Server) {
+	c := &noteCollector{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev map[string]any
+		json.NewDecoder(r.Body).Decode(&ev)
+		c.mu.Lock()
+		c.notes = append(c.notes, ev)
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(ts.Close)
+	return c, ts
+}
+
+func TestEnvironmentTB(t *testing.T) {
+	c, ts := newNoteServer(t)
+	tb := &TB{TB: t, serverURL: ts.URL, envID: "env-1"}
+	env := &Environment{ID: "env-1", serverURL: ts.URL, T: tb}
+	if env.TB() != tb {
+		t.Error("TB() did not return env.T")
+	}
+
+	// A replaced T is wrapped so assertions still reach the event log.
+	rec := &recordTB{TB: t}
+	env.T = rec
+	env.TB().Equal(1, 2)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.notes) != 1 || len(rec.errors) != 1 {
+		t.Errorf("got %d notes and %d failures, want 1 each", len(c.notes), len(rec.errors))
+	}
+}
+
+func TestTBEqual(t *testing.T) {
+	c, ts := newNoteServer(t)
+	rec := &recordTB{TB: t}
+	tb := &TB{TB: rec, serverURL: ts.URL, envID: "env-1"}
+
+	if !tb.Equal(200, 200) {
+		t.Error("Equal(200, 200) = false")
+	}
+	if tb.Equal("200", 500) {
+		t.Error(`Equal("200", 500) = true`)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.notes) != 1 {
+		t.Fatalf("got %d notes, want 1", len(c.notes))
+	}
+	note := c.notes[0]
+	if note["type"] != "test.note" {
+		t.Errorf("type = %v, want test.note", note["type"])
+	}
+	a, _ := note["assertion"].(map[string]any)
+	if a["file"] != "tb_test.go" || a["line"] == nil || a["want"] != `"200"` || a["got"] != "500" {
+		t.Errorf("assertion = %v", a)
+	}
+	if msg, _ := note["error"].(string); !strings.HasPrefix(msg, "tb_test.go:") {
+		t.Errorf("error = %q, want file:line prefix", msg)
+	}
+	if len(rec.errors) != 1 || rec.errors[0] != `not equal: want "200", got 500` {
+		t.Errorf("test failures = %q", rec.errors)
+	}
+}
+
+func TestTBAssertStatus(t *testing.T) {
+	c, ts := newNoteServer(t)
+	rec := &recordTB{TB: t}
+	tb := &TB{TB: rec, serverURL: ts.URL, envID: "env-1"}
+
+	req := httptest.NewRequest("GET", "/orders", nil)
+	if !tb.AssertStatus(&http.Response{StatusCode: 200, Request: req}, 200) {
+		t.Error("AssertStatus(200, 200) = false")
+	}
+	if tb.AssertStatus(&http.Response{StatusCode: 500, Request: req}, 200) {
+		t.Error("AssertStatus(500, 200) = true")
+	}
+	if tb.AssertStatus(nil, 200) {
+		t.Error("AssertStatus(nil, 200) = true")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.notes) != 2 {
+		t.Fatalf("got %d notes, want 2", len(c.notes))
+	}
+	a, _ := c.notes[0]["assertion"].(map[string]any)
+	if a["file"] != "tb_test.go" || a["field"] != "status" || a["want"] != "200" || a["got"] != "500" {
+		t.Errorf("assertion = %v", a)
+	}
+	if a, _ := c.notes[1]["assertion"].(map[string]any); a["got"] != "no response" {
+		t.Errorf("nil response assertion = %v", a)
+	}
+	want := []string{
+		"expected status 200, got 500 (GET /orders)",
+		"expected status 200, got no response",
+	}
+	if len(rec.errors) != 2 || rec.errors[0] != want[0] || rec.errors[1] != want[1] {
+		t.Errorf("test failures = %q, want %q", rec.errors, want)
+	}
+}
+
+func TestTBAssertEventually(t *testing.T) {
+	c, ts := newNoteServer(t)
+	rec := &recordTB{TB: t}
+	tb := &TB{TB: rec, serverURL: ts.URL, envID: "env-1"}
+
+	calls := 0
+	ok := tb.AssertEventually(func() error {
+		if calls++; calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	}, time.Second)
+	if !ok || calls != 3 {
+		t.Errorf("AssertEventually = %v after %d calls, want true after 3", ok, calls)
+	}
+
+	if tb.AssertEventually(func() error { return errors.New("0 orders") }, 50*time.Millisecond) {
+		t.Error("AssertEventually on a failing condition = true")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.notes) != 1 {
+		t.Fatalf("got %d notes, want 1", len(c.notes))
+	}
+	a, _ := c.notes[0]["assertion"].(map[string]any)
+	if a["field"] != "condition" || a["want"] != "holds within 50ms" || a["got"] != "0 orders" {
+		t.Errorf("assertion = %v", a)
+	}
+	if len(rec.errors) != 1 || rec.errors[0] != "condition not met within 50ms: 0 orders" {
+		t.Errorf("test failures = %q", rec.errors)
+	}
+}
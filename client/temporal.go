@@ -1,17 +1,25 @@
 package rig
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // TemporalDef defines a service backed by the builtin Temporal type.
 // Rig downloads the Temporal CLI binary on first use, caches it, and
 // runs `temporal server start-dev` with automatic port wiring.
 //
 // Publishes TEMPORAL_ADDRESS and TEMPORAL_NAMESPACE as endpoint attributes.
-// Each environment gets an isolated namespace assigned by the server.
+// Each environment gets an isolated namespace assigned by the server,
+// unless one is named with Namespace.
 type TemporalDef struct {
-	version  string
-	egresses map[string]egressDef
-	hooks    hooksDef
+	version          string
+	namespace        string
+	searchAttributes map[string]string
+	egresses         map[string]egressDef
+	hooks            hooksDef
+	timeout          time.Duration
+	dependsOn        []string
 }
 
 func (*TemporalDef) rigService() {}
@@ -32,6 +40,32 @@ func (d *TemporalDef) Version(v string) *TemporalDef {
 	return d
 }
 
+// Namespace names the namespace published as TEMPORAL_NAMESPACE in place
+// of the per-test one. It is created once the server is healthy, before
+// the service is ready, and creating one that already exists is not an
+// error. The dev server is shared between tests, so tests naming the same
+// namespace share it too: give workflows IDs that don't collide.
+//
+//	rig.Temporal().Namespace("orders")
+func (d *TemporalDef) Namespace(name string) *TemporalDef {
+	d.namespace = name
+	return d
+}
+
+// SearchAttribute registers a custom search attribute on the service's
+// namespace before it is ready. typ is a Temporal search attribute type:
+// "Keyword", "Text", "Int", "Double", "Bool", "Datetime" or "KeywordList".
+// Can be called multiple times.
+//
+//	rig.Temporal().Namespace("orders").SearchAttribute("CustomerId", "Keyword")
+func (d *TemporalDef) SearchAttribute(name, typ string) *TemporalDef {
+	if d.searchAttributes == nil {
+		d.searchAttributes = make(map[string]string)
+	}
+	d.searchAttributes[name] = typ
+	return d
+}
+
 // Egress adds a dependency on a service, named after the target.
 func (d *TemporalDef) Egress(service string) *TemporalDef {
 	return d.EgressAs(service, service)
@@ -61,3 +95,16 @@ func (d *TemporalDef) PrestartHook(fn func(ctx context.Context, w Wiring) error)
 	d.hooks.prestart = append(d.hooks.prestart, hookFunc(fn))
 	return d
 }
+
+// DependsOn makes this service start only after the named services are
+// ready, without creating an egress.
+func (d *TemporalDef) DependsOn(services ...string) *TemporalDef {
+	d.dependsOn = append(d.dependsOn, services...)
+	return d
+}
+
+// Timeout overrides the ready-check timeout for this service.
+func (d *TemporalDef) Timeout(timeout time.Duration) *TemporalDef {
+	d.timeout = timeout
+	return d
+}
@@ -0,0 +1,391 @@
+package rig
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+// BodyMatcher reports whether a captured request body matches an
+// expectation. For gRPC calls the body is the JSON-decoded message when
+// rig could decode it (via server reflection), otherwise the raw
+// protobuf bytes.
+type BodyMatcher func(body []byte) bool
+
+// BodyContains matches bodies that contain s as a substring.
+func BodyContains(s string) BodyMatcher {
+	return func(body []byte) bool {
+		return bytes.Contains(body, []byte(s))
+	}
+}
+
+// AssertGRPC asserts that the observed traffic contains at least one call
+// to the given method on the target service whose request body satisfies
+// every matcher. service is the rig service name of the call's target and
+// method is either the bare method name ("StartWorkflowExecution") or the
+// fully qualified "package.Service/Method" form.
+//
+// Traffic is only captured when observe is enabled (the default).
+func (e *Environment) AssertGRPC(t testing.TB, service, method string, matchers ...BodyMatcher) {
+	t.Helper()
+	events, err := e.fetchEvents()
+	if err != nil {
+		t.Fatalf("rig: AssertGRPC: %v", err)
+		return
+	}
+
+	var seen []string
+	for _, ev := range events {
+		g := ev.GRPCCall
+		if ev.Type != "grpc.call.completed" || g == nil || g.Target != service {
+			continue
+		}
+		seen = append(seen, g.Service+"/"+g.Method)
+		if method != g.Method && method != g.Service+"/"+g.Method {
+			continue
+		}
+		if matchAll(grpcRequestBody(g), matchers) {
+			return
+		}
+	}
+
+	if len(seen) == 0 {
+		t.Errorf("rig: no gRPC calls to %q observed (want %s)", service, method)
+		return
+	}
+	t.Errorf("rig: no gRPC call to %q matched %s with the given body matchers\nobserved calls:\n  %s",
+		service, method, strings.Join(seen, "\n  "))
+}
+
+// grpcRequestBody returns the decoded request body if available, falling
+// back to the raw captured bytes.
+func grpcRequestBody(g *GRPCCallInfo) []byte {
+	if len(g.RequestBodyDecoded) > 0 {
+		return g.RequestBodyDecoded
+	}
+	return g.RequestBody
+}
+
+func matchAll(body []byte, matchers []BodyMatcher) bool {
+	for _, m := range matchers {
+		if !m(body) {
+			return false
+		}
+	}
+	return true
+}
+
+// RetryWindow is the largest gap between one attempt completing and the
+// next identical request starting for RetryGroups to treat the second as a
+// retry of the first.
+const RetryWindow = 2 * time.Second
+
+// RetryGroup is a run of consecutive identical HTTP requests on one edge
+// that RetryGroups considers a single logical call.
+type RetryGroup struct {
+	Method   string
+	Path     string
+	Attempts int
+	Statuses []int // status code of each attempt, in order
+}
+
+// RetryGroups groups the observed HTTP requests from source to target into
+// logical calls so tests can assert on retry behaviour:
+//
+//	groups, err := env.RetryGroups("api", "payments")
+//	// groups[0].Attempts == 3, groups[0].Statuses == [503 503 503]
+//
+// A request joins the previous group when it has the same method and path,
+// the previous attempt failed (status 0 or >= 400), and it started within
+// RetryWindow of the previous attempt completing. Anything else starts a
+// new group. Use "~test" as source for requests made by the test itself.
+//
+// Traffic is only captured when observe is enabled (the default).
+func (e *Environment) RetryGroups(source, target string) ([]RetryGroup, error) {
+	events, err := e.fetchEvents()
+	if err != nil {
+		return nil, err
+	}
+	return groupRetries(events, source, target), nil
+}
+
+func groupRetries(events []wireEvent, source, target string) []RetryGroup {
+	var groups []RetryGroup
+	var lastStatus int
+	var lastEnd time.Time
+	for _, ev := range events {
+		r := ev.Request
+		if ev.Type != "request.completed" || r == nil || r.Source != source || r.Target != target {
+			continue
+		}
+		start := ev.Timestamp.Add(-time.Duration(r.LatencyMs * float64(time.Millisecond)))
+		n := len(groups)
+		retry := n > 0 &&
+			groups[n-1].Method == r.Method &&
+			groups[n-1].Path == r.Path &&
+			(lastStatus == 0 || lastStatus >= 400) &&
+			start.Sub(lastEnd) <= RetryWindow
+		if retry {
+			groups[n-1].Attempts++
+			groups[n-1].Statuses = append(groups[n-1].Statuses, r.StatusCode)
+		} else {
+			groups = append(groups, RetryGroup{
+				Method:   r.Method,
+				Path:     r.Path,
+				Attempts: 1,
+				Statuses: []int{r.StatusCode},
+			})
+		}
+		lastStatus = r.StatusCode
+		lastEnd = ev.Timestamp
+	}
+	return groups
+}
+
+// Event is a traffic or lifecycle event from the environment's event log,
+// as passed to WaitForTraffic. Which of Request, GRPCCall, GRPCStream and
+// Connection is set depends on Type.
+type Event struct {
+	Seq       uint64
+	Type      string // e.g. "request.completed", "grpc.call.completed", "service.ready"
+	Service   string
+	Ingress   string
+	Message   string
+	Error     string
+	Timestamp time.Time
+
+	Request    *RequestInfo    // request.completed, request.mocked
+	GRPCCall   *GRPCCallInfo   // grpc.call.completed
+	GRPCStream *GRPCStreamInfo // grpc.stream.opened, grpc.stream.message, grpc.stream.closed
+	Connection *ConnectionInfo // connection.opened, connection.closed
+}
+
+func eventFromWire(ev wireEvent) Event {
+	return Event{
+		Seq:        ev.Seq,
+		Type:       ev.Type,
+		Service:    ev.Service,
+		Ingress:    ev.Ingress,
+		Message:    ev.Message,
+		Error:      ev.Error,
+		Timestamp:  ev.Timestamp,
+		Request:    ev.Request,
+		GRPCCall:   ev.GRPCCall,
+		GRPCStream: ev.GRPCStream,
+		Connection: ev.Connection,
+	}
+}
+
+// WaitForTraffic blocks until an event satisfying match is observed, or ctx
+// is done. Events that happened before the call are considered too, so
+// there is no race between triggering a side effect and waiting for it:
+//
+//	ev, err := env.WaitForTraffic(ctx, func(ev rig.Event) bool {
+//		return ev.Type == "grpc.call.completed" &&
+//			ev.GRPCCall.Method == "StartWorkflowExecution"
+//	})
+//
+// Service log lines are not delivered. Traffic events are only captured when
+// observe is enabled (the default).
+func (e *Environment) WaitForTraffic(ctx context.Context, match func(Event) bool) (Event, error) {
+	if e.serverURL == "" {
+		return Event{}, fmt.Errorf("rig: WaitForTraffic: environment has no server (was it created by rig.Up?)")
+	}
+	url := fmt.Sprintf("%s/environments/%s/events", e.serverURL, e.ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Event{}, fmt.Errorf("rig: WaitForTraffic: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Event{}, ctx.Err()
+		}
+		return Event{}, fmt.Errorf("rig: WaitForTraffic: connect to event stream: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Event{}, fmt.Errorf("rig: WaitForTraffic: event stream: HTTP %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	// Traffic events carry captured bodies, which can outgrow the default
+	// 64KB line limit.
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var wev wireEvent
+		if err := json.Unmarshal([]byte(data), &wev); err != nil {
+			continue
+		}
+		if ev := eventFromWire(wev); match(ev) {
+			return ev, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return Event{}, ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return Event{}, fmt.Errorf("rig: WaitForTraffic: event stream read: %w", err)
+	}
+	return Event{}, fmt.Errorf("rig: WaitForTraffic: event stream closed before a matching event")
+}
+
+// RequestMatch selects observed HTTP requests for ExpectRequest. Zero
+// fields match anything.
+type RequestMatch struct {
+	Source string        // calling service, or "~test" for the test's own requests
+	Target string        // rig service name of the target
+	Method string        // e.g. "POST"
+	Path   string        // path.Match pattern for the path without its query, e.g. "/orders/*"
+	Status int           // response status code
+	Body   []BodyMatcher // request body matchers, all of which must match
+}
+
+func (m RequestMatch) matches(r *RequestInfo) bool {
+	if m.Source != "" && r.Source != m.Source ||
+		m.Target != "" && r.Target != m.Target ||
+		m.Method != "" && !strings.EqualFold(r.Method, m.Method) ||
+		m.Status != 0 && r.StatusCode != m.Status {
+		return false
+	}
+	if m.Path != "" {
+		p, _, _ := strings.Cut(r.Path, "?")
+		if ok, _ := path.Match(m.Path, p); !ok {
+			return false
+		}
+	}
+	return matchAll(r.RequestBody, m.Body)
+}
+
+// String describes m for failure messages, e.g. "api → db POST /orders".
+func (m RequestMatch) String() string {
+	orAny := func(s string) string {
+		if s == "" {
+			return "*"
+		}
+		return s
+	}
+	s := orAny(m.Source) + " → " + orAny(m.Target) + " " + orAny(m.Method) + " " + orAny(m.Path)
+	if m.Status != 0 {
+		s += fmt.Sprintf(" %d", m.Status)
+	}
+	if len(m.Body) > 0 {
+		s += " (with body matchers)"
+	}
+	return s
+}
+
+// RequestExpectation is an HTTP request the test expects the environment
+// to carry. See ExpectRequest.
+type RequestExpectation struct {
+	env   *Environment
+	match RequestMatch
+}
+
+// ExpectRequest expects a proxied HTTP request matching match. Call
+// WithinTimeout on the result to wait for it:
+//
+//	req := env.ExpectRequest(rig.RequestMatch{
+//		Source: "api", Target: "db", Method: "POST", Path: "/orders",
+//	}).WithinTimeout(5 * time.Second)
+//
+// Requests observed before the call count, so there is no race between
+// triggering the request and expecting it. Mocked requests count too.
+// Traffic is only captured when observe is enabled (the default).
+func (e *Environment) ExpectRequest(match RequestMatch) *RequestExpectation {
+	return &RequestExpectation{env: e, match: match}
+}
+
+// WithinTimeout blocks until a matching request is observed and returns
+// it. If none arrives within timeout, it fails the test with env.T.Fatalf,
+// listing the requests that were observed on the edge. The wait is cut
+// short by the test's deadline (go test -timeout) and ends when the test
+// does, so it is safe in parallel tests.
+func (x *RequestExpectation) WithinTimeout(timeout time.Duration) *RequestInfo {
+	t := x.env.TB()
+	t.Helper()
+
+	ctx := t.Context()
+	if d, ok := t.TB.(interface{ Deadline() (time.Time, bool) }); ok {
+		// Leave time to report the failure before the test binary panics.
+		if deadline, ok := d.Deadline(); ok && time.Until(deadline)-time.Second < timeout {
+			timeout = max(time.Until(deadline)-time.Second, 0)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ev, err := x.env.WaitForTraffic(ctx, func(ev Event) bool {
+		return (ev.Type == "request.completed" || ev.Type == "request.mocked") &&
+			ev.Request != nil && x.match.matches(ev.Request)
+	})
+	if err == nil {
+		return ev.Request
+	}
+	if ctx.Err() == nil {
+		t.Fatalf("rig: ExpectRequest: %v", err)
+		return nil
+	}
+
+	msg := fmt.Sprintf("rig: no request matching %s observed within %s", x.match, timeout)
+	if seen := x.observedRequests(); len(seen) > 0 {
+		msg += "\nobserved requests:\n  " + strings.Join(seen, "\n  ")
+	}
+	t.Fatalf("%s", msg)
+	return nil
+}
+
+// maxObservedRequests caps the requests listed when an expectation fails.
+const maxObservedRequests = 10
+
+// observedRequests describes the last requests on the expectation's edge,
+// for failure messages. Errors fetching the log leave it empty.
+func (x *RequestExpectation) observedRequests() []string {
+	events, _ := x.env.fetchEvents()
+	var seen []string
+	for _, ev := range events {
+		r := ev.Request
+		if ev.Type != "request.completed" && ev.Type != "request.mocked" || r == nil {
+			continue
+		}
+		if x.match.Source != "" && r.Source != x.match.Source || x.match.Target != "" && r.Target != x.match.Target {
+			continue
+		}
+		seen = append(seen, fmt.Sprintf("%s → %s %s %s %d", r.Source, r.Target, r.Method, r.Path, r.StatusCode))
+	}
+	if len(seen) > maxObservedRequests {
+		seen = seen[len(seen)-maxObservedRequests:]
+	}
+	return seen
+}
+
+// fetchEvents returns the environment's full event log from rigd.
+func (e *Environment) fetchEvents() ([]wireEvent, error) {
+	if e.serverURL == "" {
+		return nil, fmt.Errorf("environment has no server (was it created by rig.Up?)")
+	}
+	resp, err := http.Get(fmt.Sprintf("%s/environments/%s/log", e.serverURL, e.ID))
+	if err != nil {
+		return nil, fmt.Errorf("fetch event log: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch event log: HTTP %d", resp.StatusCode)
+	}
+	var events []wireEvent
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, fmt.Errorf("decode event log: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,256 @@
+package rig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeLogServer serves GET /environments/{id}/log with the given events.
+func fakeLogServer(t *testing.T, events []map[string]any) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/log") {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(events)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// recordTB captures assertion failures instead of failing the test.
+type recordTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordTB) Helper() {}
+
+func (r *recordTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordTB) Fatalf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGRPC(t *testing.T) {
+	ts := fakeLogServer(t, []map[string]any{
+		{"type": "service.ready", "service": "temporal"},
+		{"type": "grpc.call.completed", "grpc_call": map[string]any{
+			"source":               "worker",
+			"target":               "temporal",
+			"service":              "temporal.api.workflowservice.v1.WorkflowService",
+			"method":               "StartWorkflowExecution",
+			"request_body_decoded": map[string]any{"taskQueue": map[string]any{"name": "orders"}},
+		}},
+		{"type": "grpc.call.completed", "grpc_call": map[string]any{
+			"source":       "worker",
+			"target":       "billing",
+			"service":      "billing.v1.Billing",
+			"method":       "Charge",
+			"request_body": []byte("raw-charge-bytes"),
+		}},
+	})
+	env := &Environment{ID: "env-1", serverURL: ts.URL}
+
+	tests := []struct {
+		name     string
+		service  string
+		method   string
+		matchers []BodyMatcher
+		wantErr  string
+	}{
+		{name: "short method", service: "temporal", method: "StartWorkflowExecution",
+			matchers: []BodyMatcher{BodyContains("orders")}},
+		{name: "qualified method", service: "temporal",
+			method: "temporal.api.workflowservice.v1.WorkflowService/StartWorkflowExecution"},
+		{name: "raw body fallback", service: "billing", method: "Charge",
+			matchers: []BodyMatcher{BodyContains("charge")}},
+		{name: "body mismatch", service: "temporal", method: "StartWorkflowExecution",
+			matchers: []BodyMatcher{BodyContains("payments")}, wantErr: "observed calls"},
+		{name: "no calls to service", service: "api", method: "Get", wantErr: "no gRPC calls"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordTB{TB: t}
+			env.AssertGRPC(rec, tt.service, tt.method, tt.matchers...)
+			if tt.wantErr == "" {
+				if len(rec.errors) > 0 {
+					t.Fatalf("unexpected failure: %v", rec.errors)
+				}
+				return
+			}
+			if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], tt.wantErr) {
+				t.Fatalf("errors = %v, want one containing %q", rec.errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryGroups(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := func(at time.Duration, source, method, path string, status int) map[string]any {
+		return map[string]any{
+			"type":      "request.completed",
+			"timestamp": base.Add(at),
+			"request": map[string]any{
+				"source": source, "target": "payments",
+				"method": method, "path": path,
+				"status_code": status, "latency_ms": 10,
+			},
+		}
+	}
+	ts := fakeLogServer(t, []map[string]any{
+		// Three failing attempts then give up.
+		req(0, "api", "POST", "/charge", 503),
+		req(100*time.Millisecond, "api", "POST", "/charge", 503),
+		req(300*time.Millisecond, "api", "POST", "/charge", 503),
+		// Other edges are ignored.
+		req(350*time.Millisecond, "worker", "POST", "/charge", 503),
+		// A different path starts a new group.
+		req(400*time.Millisecond, "api", "GET", "/balance", 200),
+		// Identical request after a success is a new logical call.
+		req(500*time.Millisecond, "api", "GET", "/balance", 200),
+		// Identical failing request after a long gap is a new logical call.
+		req(time.Second, "api", "POST", "/charge", 500),
+		req(10*time.Second, "api", "POST", "/charge", 200),
+	})
+	env := &Environment{ID: "env-1", serverURL: ts.URL}
+
+	groups, err := env.RetryGroups("api", "payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RetryGroup{
+		{Method: "POST", Path: "/charge", Attempts: 3, Statuses: []int{503, 503, 503}},
+		{Method: "GET", Path: "/balance", Attempts: 1, Statuses: []int{200}},
+		{Method: "GET", Path: "/balance", Attempts: 1, Statuses: []int{200}},
+		{Method: "POST", Path: "/charge", Attempts: 1, Statuses: []int{500}},
+		{Method: "POST", Path: "/charge", Attempts: 1, Statuses: []int{200}},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d: %+v", len(groups), len(want), groups)
+	}
+	for i := range want {
+		g, w := groups[i], want[i]
+		if g.Method != w.Method || g.Path != w.Path || g.Attempts != w.Attempts || !slices.Equal(g.Statuses, w.Statuses) {
+			t.Errorf("group %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestWaitForTraffic(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/events") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		write := func(seq int, ev map[string]any) {
+			data, _ := json.Marshal(ev)
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", seq, ev["type"], data)
+			w.(http.Flusher).Flush()
+		}
+		write(1, map[string]any{"seq": 1, "type": "service.ready", "service": "temporal"})
+		write(2, map[string]any{"seq": 2, "type": "request.completed", "request": map[string]any{
+			"source": "~test", "target": "api", "method": "POST", "path": "/orders", "status_code": 202,
+		}})
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		write(3, map[string]any{"seq": 3, "type": "grpc.call.completed", "grpc_call": map[string]any{
+			"source": "worker", "target": "temporal",
+			"service": "temporal.api.workflowservice.v1.WorkflowService", "method": "StartWorkflowExecution",
+		}})
+		<-r.Context().Done()
+	}))
+	t.Cleanup(ts.Close)
+	env := &Environment{ID: "env-1", serverURL: ts.URL}
+	ctx := t.Context()
+
+	// Events from before the call match.
+	ev, err := env.WaitForTraffic(ctx, func(ev Event) bool {
+		return ev.Type == "request.completed" && ev.Request.Path == "/orders"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Seq != 2 || ev.Request.StatusCode != 202 {
+		t.Errorf("got seq %d status %d, want seq 2 status 202", ev.Seq, ev.Request.StatusCode)
+	}
+
+	// Blocks until a matching event arrives.
+	close(release)
+	ev, err = env.WaitForTraffic(ctx, func(ev Event) bool {
+		return ev.Type == "grpc.call.completed" && ev.GRPCCall.Method == "StartWorkflowExecution"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Seq != 3 || ev.GRPCCall.Target != "temporal" {
+		t.Errorf("got seq %d target %q, want seq 3 target temporal", ev.Seq, ev.GRPCCall.Target)
+	}
+
+	// Gives up when ctx expires.
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = env.WaitForTraffic(ctx, func(ev Event) bool { return ev.Type == "service.failed" })
+	if err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestExpectRequest(t *testing.T) {
+	order := map[string]any{"seq": 1, "type": "request.completed", "request": map[string]any{
+		"source": "api", "target": "db", "method": "POST", "path": "/orders?dry=1", "status_code": 201,
+		"request_body": []byte(`{"sku":"A1"}`),
+	}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/events"):
+			w.Header().Set("Content-Type", "text/event-stream")
+			data, _ := json.Marshal(order)
+			fmt.Fprintf(w, "id: 1\nevent: request.completed\ndata: %s\n\n", data)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/log"):
+			json.NewEncoder(w).Encode([]map[string]any{order})
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	rec := &recordTB{TB: t}
+	env := &Environment{ID: "env-1", serverURL: ts.URL, T: &TB{TB: rec, serverURL: ts.URL, envID: "env-1"}}
+
+	req := env.ExpectRequest(RequestMatch{
+		Source: "api", Target: "db", Method: "post", Path: "/orders",
+		Body: []BodyMatcher{BodyContains(`"sku":"A1"`)},
+	}).WithinTimeout(time.Second)
+	if req == nil || req.StatusCode != 201 {
+		t.Fatalf("request = %+v, want the 201 POST /orders", req)
+	}
+
+	if req := env.ExpectRequest(RequestMatch{Target: "db", Path: "/orders/*"}).WithinTimeout(50 * time.Millisecond); req != nil {
+		t.Errorf("unmatched expectation returned %+v", req)
+	}
+	if len(rec.errors) != 1 {
+		t.Fatalf("errors = %q, want one failure", rec.errors)
+	}
+	for _, want := range []string{"no request matching * → db * /orders/* observed within 50ms", "api → db POST /orders?dry=1 201"} {
+		if !strings.Contains(rec.errors[0], want) {
+			t.Errorf("failure %q does not contain %q", rec.errors[0], want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package rig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Validate checks services the way Up would — dependency cycles, unknown
+// egress targets, missing binaries and module directories, malformed image
+// references — without creating an environment. Nothing is built, pulled
+// or started, so it is cheap enough to run over a whole service graph in CI:
+//
+//	if errs := rig.Validate(services); len(errs) > 0 {
+//		t.Fatal(errors.Join(errs...))
+//	}
+//
+// It returns nil when the services are valid. Server options such as
+// WithServer apply; options that only affect a running environment are
+// ignored.
+func Validate(services Services, opts ...Option) []error {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	release, err := resolveServer(&o)
+	if err != nil {
+		return []error{err}
+	}
+	if release != nil {
+		defer release()
+	}
+
+	specEnv, err := envToSpec("Validate", services, map[string]hookFunc{}, map[string]startFunc{}, o)
+	if err != nil {
+		return []error{fmt.Errorf("rig: build spec: %v", err)}
+	}
+	body, err := json.Marshal(specEnv)
+	if err != nil {
+		return []error{fmt.Errorf("rig: marshal spec: %v", err)}
+	}
+
+	resp, err := http.Post(o.serverURL+"/environments?dry_run=true", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return []error{fmt.Errorf("rig: validate environment: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnprocessableEntity:
+		var result struct {
+			ValidationErrors []string `json:"validation_errors"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return []error{fmt.Errorf("rig: validate environment: %v", err)}
+		}
+		errs := make([]error, len(result.ValidationErrors))
+		for i, msg := range result.ValidationErrors {
+			errs[i] = errors.New(msg)
+		}
+		return errs
+	default:
+		respBody, _ := io.ReadAll(resp.Body)
+		return []error{fmt.Errorf("rig: validate environment: HTTP %d: %s", resp.StatusCode, respBody)}
+	}
+}
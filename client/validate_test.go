@@ -0,0 +1,42 @@
+package rig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var dryRun string
+	var validationErrors []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/environments" {
+			http.NotFound(w, r)
+			return
+		}
+		dryRun = r.URL.Query().Get("dry_run")
+		w.Header().Set("Content-Type", "application/json")
+		if len(validationErrors) > 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]any{"validation_errors": validationErrors})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"valid": true})
+	}))
+	defer srv.Close()
+
+	services := Services{"api": Process("/bin/api")}
+	if errs := Validate(services, WithServer(srv.URL)); errs != nil {
+		t.Fatalf("Validate = %v, want nil", errs)
+	}
+	if dryRun != "true" {
+		t.Errorf("dry_run = %q, want true", dryRun)
+	}
+
+	validationErrors = []string{`service "api": command: no such file`, `service "web": egress "db" targets unknown service "db"`}
+	errs := Validate(services, WithServer(srv.URL))
+	if len(errs) != 2 || errs[0].Error() != validationErrors[0] || errs[1].Error() != validationErrors[1] {
+		t.Errorf("Validate = %v, want %v", errs, validationErrors)
+	}
+}
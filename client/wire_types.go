@@ -10,21 +10,66 @@ import (
 // (now at internal/spec/) in terms of JSON tags and structure.
 
 type specEnvironment struct {
-	Name     string                 `json:"name"`
-	Services map[string]specService `json:"services"`
-	Observe  bool                   `json:"observe,omitempty"`
-	HostEnv  map[string]string      `json:"host_env,omitempty"`
-	Dir      string                 `json:"dir,omitempty"`
-	TTL      string                 `json:"ttl,omitempty"`
+	Name              string                 `json:"name"`
+	Services          map[string]specService `json:"services"`
+	Observe           bool                   `json:"observe,omitempty"`
+	HostEnv           map[string]string      `json:"host_env,omitempty"`
+	Dir               string                 `json:"dir,omitempty"`
+	TTL               string                 `json:"ttl,omitempty"`
+	ContainerNetwork  bool                   `json:"container_network,omitempty"`
+	TCPIdleTimeout    string                 `json:"tcp_idle_timeout,omitempty"`
+	ObserveBodyLimit  *int                   `json:"observe_body_limit,omitempty"`
+	ObserveAutoDetect bool                   `json:"observe_auto_detect,omitempty"`
+	ObserveConnPool   int                    `json:"observe_conn_pool,omitempty"`
+	ObserveTLS        *specTLSSpec           `json:"observe_tls,omitempty"`
+	ObserveRedact     *specRedactSpec        `json:"observe_redact,omitempty"`
+	ProtoDescriptors  string                 `json:"proto_descriptors,omitempty"`
+	Metadata          map[string]string      `json:"metadata,omitempty"`
+	Reuse             bool                   `json:"reuse,omitempty"`
+	StartupOrder      [][]string             `json:"startup_order,omitempty"`
+	FakeNow           string                 `json:"fake_now,omitempty"`
+	Seed              *int64                 `json:"seed,omitempty"`
+	Faults            []specFaultSpec        `json:"faults,omitempty"`
+}
+
+type specTLSSpec struct {
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+type specFaultSpec struct {
+	Source    string       `json:"source"`
+	Target    string       `json:"target"`
+	Latency   specDuration `json:"latency,omitempty"`
+	Jitter    specDuration `json:"jitter,omitempty"`
+	DropRate  float64      `json:"drop_rate,omitempty"`
+	ErrorRate float64      `json:"error_rate,omitempty"`
+}
+
+type specRedactSpec struct {
+	Headers   []string `json:"headers,omitempty"`
+	JSONPaths []string `json:"json_paths,omitempty"`
 }
 
 type specService struct {
-	Type      string                     `json:"type"`
-	Config    json.RawMessage            `json:"config,omitempty"`
-	Args      []string                   `json:"args,omitempty"`
-	Ingresses map[string]specIngressSpec `json:"ingresses,omitempty"`
-	Egresses  map[string]specEgressSpec  `json:"egresses,omitempty"`
-	Hooks     *specHooks                 `json:"hooks,omitempty"`
+	Type          string                     `json:"type"`
+	Config        json.RawMessage            `json:"config,omitempty"`
+	Args          []string                   `json:"args,omitempty"`
+	Ingresses     map[string]specIngressSpec `json:"ingresses,omitempty"`
+	Egresses      map[string]specEgressSpec  `json:"egresses,omitempty"`
+	DependsOn     []string                   `json:"depends_on,omitempty"`
+	Hooks         *specHooks                 `json:"hooks,omitempty"`
+	DotEnv        map[string]string          `json:"dotenv,omitempty"`
+	Env           map[string]string          `json:"env,omitempty"`
+	StopTimeout   *specDuration              `json:"stop_timeout,omitempty"`
+	RestartOnExit int                        `json:"restart_on_exit,omitempty"`
+	TaskQueues    []specTaskQueueSpec        `json:"task_queues,omitempty"`
+	Scale         int                        `json:"scale,omitempty"`
+}
+
+type specTaskQueueSpec struct {
+	Name   string `json:"name"`
+	Egress string `json:"egress,omitempty"`
 }
 
 type specHooks struct {
@@ -47,18 +92,35 @@ type specIngressSpec struct {
 	Protocol      Protocol       `json:"protocol"`
 	Ready         *specReadySpec `json:"ready,omitempty"`
 	Attributes    map[string]any `json:"attributes,omitempty"`
+	MaxBodySize   int64          `json:"max_body_size,omitempty"`
 }
 
 type specEgressSpec struct {
-	Service string `json:"service"`
-	Ingress string `json:"ingress,omitempty"`
+	Service             string         `json:"service"`
+	Ingress             string         `json:"ingress,omitempty"`
+	AllowMethods        []string       `json:"allow_methods,omitempty"`
+	Mocks               []specMockSpec `json:"mocks,omitempty"`
+	Database            string         `json:"database,omitempty"`
+	External            string         `json:"external,omitempty"`
+	WaitForGRPCServices []string       `json:"wait_for_grpc_services,omitempty"`
+}
+
+type specMockSpec struct {
+	Method  string            `json:"method,omitempty"`
+	Path    string            `json:"path,omitempty"`
+	Status  int               `json:"status,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
 }
 
 type specReadySpec struct {
 	Type     string       `json:"type,omitempty"`
 	Path     string       `json:"path,omitempty"`
+	Status   int          `json:"status,omitempty"`
 	Interval specDuration `json:"interval,omitempty"`
 	Timeout  specDuration `json:"timeout,omitempty"`
+
+	Successes int `json:"successes,omitempty"`
 }
 
 // specDuration wraps time.Duration with JSON marshalling as a string
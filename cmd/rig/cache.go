@@ -0,0 +1,445 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+// cacheStaleAfter mirrors artifact.DefaultStaleAfter: rigd re-pulls a
+// cached mutable image tag once its last check is older than this.
+const cacheStaleAfter = 24 * time.Hour
+
+// cacheEntry is one resolved artifact: a directory under
+// {rigDir}/cache/{kind}/ named by the artifact's cache key hash.
+type cacheEntry struct {
+	kind string // "docker", "go", "downloads", ...
+	hash string
+	dir  string
+
+	// name describes the artifact: the image reference of a Docker entry,
+	// otherwise the files the entry holds.
+	name string
+	size int64
+
+	// lastUsed is when rigd last resolved the entry; zero if it never
+	// marked it.
+	lastUsed time.Time
+
+	// mutable is true for Docker entries with a tag that can move, which
+	// rigd re-checks in the background. lastChecked is the last check, zero
+	// if there hasn't been one.
+	mutable     bool
+	lastChecked time.Time
+}
+
+// stale reports whether rigd's background refresh would re-pull the entry.
+func (e cacheEntry) stale(now time.Time) bool {
+	return e.mutable && (e.lastChecked.IsZero() || now.Sub(e.lastChecked) > cacheStaleAfter)
+}
+
+func runCache(args []string) error {
+	if len(args) == 0 {
+		printCacheUsage()
+		return fmt.Errorf("missing subcommand")
+	}
+	switch args[0] {
+	case "ls":
+		return runCacheLs(args[1:])
+	case "prune":
+		return runCachePrune(args[1:])
+	case "help", "-h", "--help":
+		printCacheUsage()
+		return nil
+	default:
+		printCacheUsage()
+		return fmt.Errorf("unknown subcommand %q", args[0])
+	}
+}
+
+func runCacheLs(args []string) error {
+	fs := flag.NewFlagSet("rig cache ls", flag.ContinueOnError)
+	fs.Usage = printCacheUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, err := scanCache(cacheDir())
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("cache is empty")
+		return nil
+	}
+
+	// Most recently used first.
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.After(entries[j].lastUsed)
+	})
+	renderCacheTable(os.Stdout, entries, time.Now())
+	return nil
+}
+
+func runCachePrune(args []string) error {
+	fs := flag.NewFlagSet("rig cache prune", flag.ContinueOnError)
+	var (
+		olderThanStr string
+		maxSizeStr   string
+		dryRun       bool
+	)
+	fs.StringVar(&olderThanStr, "older-than", "", "")
+	fs.StringVar(&maxSizeStr, "max-size", "", "")
+	fs.BoolVar(&dryRun, "n", false, "")
+	fs.BoolVar(&dryRun, "dry-run", false, "")
+	fs.Usage = printCacheUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if olderThanStr == "" && maxSizeStr == "" {
+		printCacheUsage()
+		return fmt.Errorf("set --older-than, --max-size, or both")
+	}
+
+	olderThan := time.Duration(-1)
+	if olderThanStr != "" {
+		d, err := parseAge(olderThanStr)
+		if err != nil {
+			return err
+		}
+		olderThan = d
+	}
+	maxSize := int64(-1)
+	if maxSizeStr != "" {
+		n, err := parseSize(maxSizeStr)
+		if err != nil {
+			return err
+		}
+		maxSize = n
+	}
+
+	removed, freed, err := pruneCache(cacheDir(), time.Now(), olderThan, maxSize, dryRun)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		fmt.Println("nothing to prune")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("would prune %d cache %s (would free ~%s)\n",
+			removed, plural(removed, "entry", "entries"), rigdata.FormatBytes(freed))
+	} else {
+		fmt.Printf("pruned %d cache %s (freed ~%s)\n",
+			removed, plural(removed, "entry", "entries"), rigdata.FormatBytes(freed))
+	}
+	return nil
+}
+
+// pruneCache removes the entries under dir that selectEvictions picks, or
+// with dryRun prints what it would remove, and returns how many and their
+// total size. Both rig cache prune and rig prune -c use it.
+//
+// rigd holds an entry's lock file while it resolves the entry, so an entry
+// whose lock can't be taken is skipped, and lock files are only unlinked
+// while pruneCache itself holds them. Lock files left without an entry are
+// cleared the same way.
+func pruneCache(dir string, now time.Time, olderThan time.Duration, maxSize int64, dryRun bool) (int, int64, error) {
+	entries, err := scanCache(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var freed int64
+	var removed int
+	for _, e := range selectEvictions(entries, now, olderThan, maxSize) {
+		if dryRun {
+			fmt.Printf("would remove cache/%s/%s (%s, last used %s, %s)\n",
+				e.kind, e.hash, e.name, formatCacheAge(e.lastUsed, now), rigdata.FormatBytes(e.size))
+			removed++
+			freed += e.size
+			continue
+		}
+		unlock, ok := tryLockCacheEntry(e.dir + ".lock")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "skipping cache/%s/%s: in use by rigd\n", e.kind, e.hash)
+			continue
+		}
+		err := os.RemoveAll(e.dir)
+		unlock()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to remove %s/%s: %v\n", e.kind, e.hash, err)
+			continue
+		}
+		removed++
+		freed += e.size
+	}
+
+	if !dryRun {
+		locks, _ := filepath.Glob(filepath.Join(dir, "*", "*.lock"))
+		for _, lock := range locks {
+			if _, err := os.Stat(strings.TrimSuffix(lock, ".lock")); !os.IsNotExist(err) {
+				continue
+			}
+			if unlock, ok := tryLockCacheEntry(lock); ok {
+				unlock()
+			}
+		}
+	}
+	return removed, freed, nil
+}
+
+// tryLockCacheEntry takes the exclusive flock rigd's artifact cache holds
+// on path while resolving an entry, without waiting for it. The returned
+// unlock removes the lock file before releasing it, so a lock file is never
+// unlinked while another process holds it. ok is false if the lock is held
+// or can't be opened.
+func tryLockCacheEntry(path string) (unlock func(), ok bool) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, false
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return nil, false
+	}
+	return func() {
+		os.Remove(path)
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN) //nolint:errcheck
+		f.Close()
+	}, true
+}
+
+// selectEvictions picks the entries to remove: every entry not used within
+// olderThan (if not negative), then, least recently used first, as many
+// more as it takes to bring the cache down to maxSize bytes (if not
+// negative). Entries never marked as used count as the oldest.
+func selectEvictions(entries []cacheEntry, now time.Time, olderThan time.Duration, maxSize int64) []cacheEntry {
+	byAge := slices.Clone(entries)
+	sort.SliceStable(byAge, func(i, j int) bool {
+		return byAge[i].lastUsed.Before(byAge[j].lastUsed)
+	})
+
+	var total int64
+	for _, e := range byAge {
+		total += e.size
+	}
+
+	var evict []cacheEntry
+	for _, e := range byAge {
+		tooOld := olderThan >= 0 && now.Sub(e.lastUsed) > olderThan
+		tooBig := maxSize >= 0 && total > maxSize
+		if !tooOld && !tooBig {
+			continue
+		}
+		evict = append(evict, e)
+		total -= e.size
+	}
+	return evict
+}
+
+// cacheDir returns the artifact cache root, {rigDir}/cache.
+func cacheDir() string {
+	return filepath.Join(rigdata.DefaultRigDir(), "cache")
+}
+
+// scanCache lists the entries under dir. A missing cache is empty.
+func scanCache(dir string) ([]cacheEntry, error) {
+	kinds, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading cache dir: %w", err)
+	}
+
+	var entries []cacheEntry
+	for _, kind := range kinds {
+		if !kind.IsDir() {
+			continue
+		}
+		kindDir := filepath.Join(dir, kind.Name())
+		hashes, err := os.ReadDir(kindDir)
+		if err != nil {
+			continue
+		}
+		for _, h := range hashes {
+			if !h.IsDir() {
+				continue // .lock files
+			}
+			entries = append(entries, readCacheEntry(kind.Name(), h.Name(), filepath.Join(kindDir, h.Name())))
+		}
+	}
+	return entries, nil
+}
+
+// readCacheEntry reads the breadcrumbs rigd leaves in an entry directory:
+// .last-used on every resolve, and .image-ref and .last-checked for pulled
+// Docker images.
+func readCacheEntry(kind, hash, dir string) cacheEntry {
+	e := cacheEntry{kind: kind, hash: hash, dir: dir, size: dirSize(dir)}
+	if info, err := os.Stat(filepath.Join(dir, ".last-used")); err == nil {
+		e.lastUsed = info.ModTime()
+	}
+	if ref, err := os.ReadFile(filepath.Join(dir, ".image-ref")); err == nil {
+		e.name = strings.TrimSpace(string(ref))
+		e.mutable = !strings.Contains(e.name, "@sha256:")
+		if info, err := os.Stat(filepath.Join(dir, ".last-checked")); err == nil {
+			e.lastChecked = info.ModTime()
+		}
+		return e
+	}
+
+	files, _ := os.ReadDir(dir)
+	var names []string
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name(), ".") {
+			names = append(names, f.Name())
+		}
+	}
+	e.name = strings.Join(names, ", ")
+	if e.name == "" {
+		e.name = "-"
+	}
+	return e
+}
+
+func renderCacheTable(w io.Writer, entries []cacheEntry, now time.Time) {
+	headers := []string{"KIND", "NAME", "SIZE", "LAST USED", "REFRESH"}
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+	}
+
+	rows := make([][5]string, len(entries))
+	var total int64
+	for i, e := range entries {
+		refresh := "-"
+		switch {
+		case e.stale(now):
+			refresh = "stale"
+		case e.mutable:
+			refresh = "checked " + formatCacheAge(e.lastChecked, now)
+		}
+		rows[i] = [5]string{
+			e.kind,
+			e.name,
+			rigdata.FormatBytes(e.size),
+			formatCacheAge(e.lastUsed, now),
+			refresh,
+		}
+		for j, c := range rows[i] {
+			if len(c) > widths[j] {
+				widths[j] = len(c)
+			}
+		}
+		total += e.size
+	}
+
+	for i, h := range headers {
+		if i > 0 {
+			fmt.Fprint(w, "  ")
+		}
+		fmt.Fprintf(w, "%-*s", widths[i], bold(h))
+	}
+	fmt.Fprintln(w)
+	for _, r := range rows {
+		for i, c := range r {
+			if i > 0 {
+				fmt.Fprint(w, "  ")
+			}
+			fmt.Fprintf(w, "%-*s", widths[i], c)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "\n%d %s, %s\n", len(entries), plural(len(entries), "entry", "entries"), rigdata.FormatBytes(total))
+}
+
+// formatCacheAge formats how long ago t was, or "never" for the zero time.
+func formatCacheAge(t time.Time, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// parseAge parses a Go duration, or a whole number of days such as "7d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// parseSize parses a byte count with an optional binary unit: "2GB",
+// "500MB", "1.5G", "4096".
+func parseSize(s string) (int64, error) {
+	num := strings.TrimSpace(strings.ToUpper(s))
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+		{"B", 1},
+	} {
+		if n, ok := strings.CutSuffix(num, u.suffix); ok {
+			num, mult = strings.TrimSpace(n), u.mult
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(f * float64(mult)), nil
+}
+
+func printCacheUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: rig cache <ls|prune> [flags]
+
+Inspect and evict artifacts in the rigd cache (built Go binaries, pulled
+and loaded Docker images, downloads).
+
+Subcommands:
+  ls                         List entries with size, last use, and refresh
+                             state (mutable image tags rigd re-checks daily)
+  prune                      Evict entries
+
+Prune flags:
+  --older-than <age>         Evict entries not used within age (e.g. 7d, 12h)
+  --max-size <size>          Then evict least recently used entries until
+                             the cache fits (e.g. 2GB, 500MB)
+  -n, --dry-run              Print what would be removed without deleting
+
+Entries rigd is resolving are skipped.
+`)
+}
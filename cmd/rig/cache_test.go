@@ -0,0 +1,223 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeCacheEntry creates {rigDir}/cache/{kind}/{hash} holding a file of
+// size bytes, last used age ago. files maps breadcrumb names to contents.
+func writeCacheEntry(t *testing.T, rigDir, kind, hash string, size int, age time.Duration, files map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(rigDir, "cache", kind, hash)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "artifact"), make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	used := filepath.Join(dir, ".last-used")
+	if err := os.WriteFile(used, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(used, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRunCacheLs(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("RIG_DIR", dir)
+
+	writeCacheEntry(t, dir, "go", "aaaa", 2048, time.Hour, nil)
+	img := writeCacheEntry(t, dir, "docker", "bbbb", 10, 2*time.Hour, map[string]string{
+		".image-ref": "postgres:16",
+		".image-id":  "sha256:abc",
+	})
+	checked := filepath.Join(img, ".last-checked")
+	os.WriteFile(checked, nil, 0o644)
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(checked, old, old)
+	writeCacheEntry(t, dir, "docker", "cccc", 10, 3*time.Hour, map[string]string{
+		".image-ref": "redis@sha256:def",
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCache([]string{"ls"}); err != nil {
+			t.Fatalf("runCache: %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("got %d lines, want header, 3 entries, blank and total:\n%s", len(lines), output)
+	}
+	// Most recently used first.
+	for i, want := range []string{"artifact", "postgres:16", "redis@sha256:def"} {
+		if !strings.Contains(lines[i+1], want) {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i+1], want)
+		}
+	}
+	if !strings.Contains(lines[2], "stale") {
+		t.Errorf("postgres:16 line = %q, want stale (checked 2d ago)", lines[2])
+	}
+	if strings.Contains(lines[3], "stale") || strings.Contains(lines[3], "checked") {
+		t.Errorf("digest-pinned line = %q, want no refresh state", lines[3])
+	}
+	if !strings.Contains(lines[5], "3 entries") {
+		t.Errorf("total line = %q, want 3 entries", lines[5])
+	}
+}
+
+func TestRunCacheLsEmpty(t *testing.T) {
+	t.Setenv("RIG_DIR", t.TempDir())
+	output := captureStdout(t, func() {
+		if err := runCache([]string{"ls"}); err != nil {
+			t.Fatalf("runCache: %v", err)
+		}
+	})
+	if !strings.Contains(output, "cache is empty") {
+		t.Errorf("output = %q, want cache is empty", output)
+	}
+}
+
+func TestRunCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("RIG_DIR", dir)
+
+	recent := writeCacheEntry(t, dir, "go", "recent", 1000, time.Hour, nil)
+	middle := writeCacheEntry(t, dir, "go", "middle", 1000, 2*24*time.Hour, nil)
+	old := writeCacheEntry(t, dir, "go", "old", 1000, 10*24*time.Hour, nil)
+	os.WriteFile(old+".lock", nil, 0o644)
+
+	// Dry run removes nothing.
+	captureStdout(t, func() {
+		if err := runCache([]string{"prune", "--older-than", "7d", "-n"}); err != nil {
+			t.Fatalf("runCache: %v", err)
+		}
+	})
+	if _, err := os.Stat(old); err != nil {
+		t.Fatalf("dry run removed %s", old)
+	}
+
+	// Age evicts the old entry; size then evicts the least recently used.
+	output := captureStdout(t, func() {
+		if err := runCache([]string{"prune", "--older-than", "7d", "--max-size", "1.5KB"}); err != nil {
+			t.Fatalf("runCache: %v", err)
+		}
+	})
+	if !strings.Contains(output, "pruned 2 cache entries") {
+		t.Errorf("output = %q, want 2 entries pruned", output)
+	}
+	for _, gone := range []string{old, old + ".lock", middle} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", gone)
+		}
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("recent entry removed: %v", err)
+	}
+}
+
+func TestRunCachePruneSkipsLockedEntries(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("RIG_DIR", dir)
+
+	old := writeCacheEntry(t, dir, "go", "old", 1000, 10*24*time.Hour, nil)
+	orphan := filepath.Join(dir, "cache", "go", "gone.lock")
+	os.WriteFile(orphan, nil, 0o644)
+
+	// Hold the entry's lock as a resolving rigd would.
+	f, err := os.OpenFile(old+".lock", os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := runCache([]string{"prune", "--older-than", "7d"}); err != nil {
+			t.Fatalf("runCache: %v", err)
+		}
+	})
+	if !strings.Contains(output, "nothing to prune") {
+		t.Errorf("output = %q, want nothing to prune", output)
+	}
+	for _, kept := range []string{old, old + ".lock"} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("locked entry: %v", err)
+		}
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("orphaned lock %s still exists", orphan)
+	}
+
+	// rig prune -c shares the same path once the lock is released.
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	captureStdout(t, func() {
+		if err := runPrune([]string{"-c", "-m", "168h"}); err != nil {
+			t.Fatalf("runPrune: %v", err)
+		}
+	})
+	for _, gone := range []string{old, old + ".lock"} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", gone)
+		}
+	}
+}
+
+func TestRunCachePruneNeedsPolicy(t *testing.T) {
+	t.Setenv("RIG_DIR", t.TempDir())
+	if err := runCache([]string{"prune"}); err == nil {
+		t.Error("expected an error without --older-than or --max-size")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"4096", 4096},
+		{"2GB", 2 << 30},
+		{"500mb", 500 << 20},
+		{"1.5K", 1536},
+		{"10B", 10},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "GB", "-1MB", "2XB"} {
+		if _, err := parseSize(bad); err == nil {
+			t.Errorf("parseSize(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	if d, err := parseAge("7d"); err != nil || d != 7*24*time.Hour {
+		t.Errorf("parseAge(7d) = %s, %v", d, err)
+	}
+	if d, err := parseAge("12h"); err != nil || d != 12*time.Hour {
+		t.Errorf("parseAge(12h) = %s, %v", d, err)
+	}
+	if _, err := parseAge("xd"); err == nil {
+		t.Error("parseAge(xd): expected an error")
+	}
+}
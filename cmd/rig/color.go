@@ -129,3 +129,10 @@ func colorMethod(s string) string {
 	}
 	return ansiCyan + s + ansiReset
 }
+
+func red(s string) string {
+	if !colorEnabled {
+		return s
+	}
+	return ansiRed + s + ansiReset
+}
@@ -41,19 +41,49 @@ func renderDetail(w io.Writer, rows []rigdata.TrafficRow, index int) error {
 	fmt.Fprintf(w, "  %s  %s → %s  %s  %s  %s  %s\n", dim(r.Time), src, tgt, colorMethod(r.Protocol), r.Path, colorStatus(r.Status), dim(r.Latency))
 
 	switch r.Event.Type {
-	case rigdata.TypeRequestCompleted:
+	case rigdata.TypeRequestCompleted, rigdata.TypeRequestMocked:
 		renderHTTPDetail(w, r.Event.Request)
 	case rigdata.TypeGRPCCallCompleted:
 		renderGRPCDetail(w, r.Event.GRPCCall)
+	case rigdata.TypeGRPCStreamClosed:
+		renderGRPCStreamDetail(w, r.Event.GRPCStream)
 	case rigdata.TypeConnectionClosed:
 		renderTCPDetail(w, r.Event.Connection)
 	case rigdata.TypeKafkaRequestCompleted:
 		renderKafkaDetail(w, r.Event.KafkaRequest)
+	case rigdata.TypeRedisCommandCompleted:
+		renderRedisDetail(w, r.Event.RedisCommand)
+	case rigdata.TypeNATSMessage:
+		renderNATSDetail(w, r.Event.NATSMessage)
+	case rigdata.TypeMongoCommand:
+		renderMongoDetail(w, r.Event.MongoCommand)
+	case rigdata.TypeWebSocketClosed:
+		renderWebSocketDetail(w, r.Event.WebSocket)
 	}
 	return nil
 }
 
+// bodyLimitNote explains how a non-default observe body limit, recorded in
+// the log header, affected the captured bodies. Returns "" for the default.
+func bodyLimitNote(hdr rigdata.LsHeader) string {
+	switch n := hdr.BodyLimit; {
+	case n == nil || *n < 0:
+		return ""
+	case *n == 0:
+		return "Body capture was disabled for this run (observe body limit 0); bodies were not recorded."
+	default:
+		return fmt.Sprintf("Bodies were captured up to %s per message (observe body limit).", rigdata.FormatBytes(int64(*n)))
+	}
+}
+
 func renderHTTPDetail(w io.Writer, r *rigdata.RequestInfo) {
+	if r.ProxyInjected {
+		fmt.Fprintf(w, "\n  %s\n", dim("Response generated by the rig proxy; not forwarded to "+r.Target+"."))
+	}
+	if r.Label != "" {
+		fmt.Fprintf(w, "\n  %s %s\n", bold("Label:"), r.Label)
+	}
+	writeTrace(w, r.TraceID, r.SpanID, r.ParentSpanID)
 	if len(r.RequestHeaders) > 0 {
 		fmt.Fprintf(w, "\n  %s\n", bold("Request Headers:"))
 		writeHeaders(w, r.RequestHeaders)
@@ -81,9 +111,13 @@ func renderHTTPDetail(w io.Writer, r *rigdata.RequestInfo) {
 }
 
 func renderGRPCDetail(w io.Writer, g *rigdata.GRPCCallInfo) {
+	if g.ProxyInjected {
+		fmt.Fprintf(w, "\n  %s\n", dim("Response generated by the rig proxy; not forwarded to "+g.Target+"."))
+	}
 	if g.GRPCMessage != "" {
 		fmt.Fprintf(w, "\n  %s %s\n", bold("gRPC Message:"), g.GRPCMessage)
 	}
+	writeTrace(w, g.TraceID, g.SpanID, g.ParentSpanID)
 	if len(g.RequestMetadata) > 0 {
 		fmt.Fprintf(w, "\n  %s\n", bold("Request Metadata:"))
 		writeHeaders(w, g.RequestMetadata)
@@ -117,6 +151,31 @@ func renderGRPCDetail(w io.Writer, g *rigdata.GRPCCallInfo) {
 	}
 }
 
+func renderGRPCStreamDetail(w io.Writer, g *rigdata.GRPCStreamInfo) {
+	fmt.Fprintf(w, "\n  %s %d in, %d out (%s↑ %s↓)\n", bold("Messages:"), g.MessagesIn, g.MessagesOut,
+		rigdata.FormatBytes(g.RequestSize), rigdata.FormatBytes(g.ResponseSize))
+	if g.GRPCMessage != "" {
+		fmt.Fprintf(w, "\n  %s %s\n", bold("gRPC Message:"), g.GRPCMessage)
+	}
+	writeTrace(w, g.TraceID, g.SpanID, g.ParentSpanID)
+	if len(g.ResponseMetadata) > 0 {
+		fmt.Fprintf(w, "\n  %s\n", bold("Response Metadata:"))
+		writeHeaders(w, g.ResponseMetadata)
+	}
+}
+
+// writeTrace prints the call's place in its X-Rig-Trace chain, if recorded.
+func writeTrace(w io.Writer, traceID, spanID, parentID string) {
+	if traceID == "" {
+		return
+	}
+	fmt.Fprintf(w, "\n  %s %s  %s %s", bold("Trace:"), traceID, bold("Span:"), spanID)
+	if parentID != "" {
+		fmt.Fprintf(w, "  %s %s", bold("Parent:"), parentID)
+	}
+	fmt.Fprintln(w)
+}
+
 func renderKafkaDetail(w io.Writer, k *rigdata.KafkaRequestInfo) {
 	fmt.Fprintf(w, "\n  %s        %s (key %d)\n", bold("API Name:"), k.APIName, k.APIKey)
 	fmt.Fprintf(w, "  %s     %d\n", bold("API Version:"), k.APIVersion)
@@ -126,10 +185,110 @@ func renderKafkaDetail(w io.Writer, k *rigdata.KafkaRequestInfo) {
 	fmt.Fprintf(w, "  %s         %s\n", bold("Latency:"), rigdata.FormatLatency(k.LatencyMs))
 }
 
+func renderRedisDetail(w io.Writer, c *rigdata.RedisCommandInfo) {
+	fmt.Fprintf(w, "\n  %s        %s\n", bold("Command:"), c.Command)
+	if c.Key != "" {
+		fmt.Fprintf(w, "  %s            %s\n", bold("Key:"), c.Key)
+	}
+	fmt.Fprintf(w, "  %s     %s\n", bold("Reply Type:"), c.ReplyType)
+	if c.RedisError != "" {
+		fmt.Fprintf(w, "  %s          %s\n", bold("Error:"), c.RedisError)
+	}
+	fmt.Fprintf(w, "  %s   %s\n", bold("Request Size:"), rigdata.FormatBytes(c.RequestSize))
+	fmt.Fprintf(w, "  %s  %s\n", bold("Response Size:"), rigdata.FormatBytes(c.ResponseSize))
+	fmt.Fprintf(w, "  %s        %s\n", bold("Latency:"), rigdata.FormatLatency(c.LatencyMs))
+}
+
+func renderMongoDetail(w io.Writer, c *rigdata.MongoCommandInfo) {
+	fmt.Fprintf(w, "\n  %s        %s\n", bold("Command:"), c.Command)
+	if c.Collection != "" {
+		fmt.Fprintf(w, "  %s     %s\n", bold("Collection:"), c.Collection)
+	}
+	if c.Database != "" {
+		fmt.Fprintf(w, "  %s       %s\n", bold("Database:"), c.Database)
+	}
+	if c.MongoError != "" {
+		fmt.Fprintf(w, "  %s          %s\n", bold("Error:"), c.MongoError)
+	}
+	fmt.Fprintf(w, "  %s   %s\n", bold("Request Size:"), rigdata.FormatBytes(c.RequestSize))
+	fmt.Fprintf(w, "  %s  %s\n", bold("Response Size:"), rigdata.FormatBytes(c.ResponseSize))
+	fmt.Fprintf(w, "  %s        %s\n", bold("Latency:"), rigdata.FormatLatency(c.LatencyMs))
+}
+
+func renderNATSDetail(w io.Writer, m *rigdata.NATSMessageInfo) {
+	fmt.Fprintf(w, "\n  %s       %s\n", bold("Operation:"), m.Op)
+	fmt.Fprintf(w, "  %s         %s\n", bold("Subject:"), m.Subject)
+	if m.ReplyTo != "" {
+		fmt.Fprintf(w, "  %s        %s\n", bold("Reply To:"), m.ReplyTo)
+	}
+	if m.Queue != "" {
+		fmt.Fprintf(w, "  %s     %s\n", bold("Queue Group:"), m.Queue)
+	}
+	if m.Op != "SUB" {
+		fmt.Fprintf(w, "  %s    %s\n", bold("Payload Size:"), rigdata.FormatBytes(m.PayloadSize))
+	}
+	if m.HeaderSize > 0 {
+		fmt.Fprintf(w, "  %s     %s\n", bold("Header Size:"), rigdata.FormatBytes(m.HeaderSize))
+	}
+}
+
+func renderWebSocketDetail(w io.Writer, c *rigdata.WebSocketInfo) {
+	fmt.Fprintf(w, "\n  %s   %d (%s)\n", bold("Frames In:"), c.FramesIn, rigdata.FormatBytes(c.BytesIn))
+	fmt.Fprintf(w, "  %s  %d (%s)\n", bold("Frames Out:"), c.FramesOut, rigdata.FormatBytes(c.BytesOut))
+	fmt.Fprintf(w, "  %s    %s\n", bold("Duration:"), rigdata.FormatLatency(c.DurationMs))
+}
+
 func renderTCPDetail(w io.Writer, c *rigdata.ConnectionInfo) {
 	fmt.Fprintf(w, "\n  %s   %s\n", bold("Bytes In:"), rigdata.FormatBytes(c.BytesIn))
 	fmt.Fprintf(w, "  %s  %s\n", bold("Bytes Out:"), rigdata.FormatBytes(c.BytesOut))
 	fmt.Fprintf(w, "  %s   %s\n", bold("Duration:"), rigdata.FormatLatency(c.DurationMs))
+	if c.CloseReason == "idle_timeout" {
+		fmt.Fprintf(w, "\n  %s\n", dim("Closed by the rig proxy after the TCP idle timeout."))
+	}
+	writePreview(w, "Preview In", c.PreviewIn, c.PreviewInTruncated, c.BytesIn)
+	writePreview(w, "Preview Out", c.PreviewOut, c.PreviewOutTruncated, c.BytesOut)
+}
+
+// writePreview renders one direction's captured TCP preview as a hex dump,
+// noting how much of the direction's traffic it covers.
+func writePreview(w io.Writer, name string, data []byte, truncated bool, total int64) {
+	if len(data) == 0 {
+		return
+	}
+	label := fmt.Sprintf("%s (%s)", name, rigdata.FormatBytes(int64(len(data))))
+	if truncated {
+		label = fmt.Sprintf("%s (first %s of %s) [truncated]", name,
+			rigdata.FormatBytes(int64(len(data))), rigdata.FormatBytes(total))
+	}
+	fmt.Fprintf(w, "\n  %s\n", bold(label+":"))
+	writeHexDump(w, data)
+}
+
+// writeHexDump writes data as offset, hex and ASCII columns, 16 bytes per
+// line, with non-printable bytes shown as '.'.
+func writeHexDump(w io.Writer, data []byte) {
+	for i := 0; i < len(data); i += 16 {
+		line := data[i:min(i+16, len(data))]
+		var hex, ascii strings.Builder
+		for j := range 16 {
+			if j == 8 {
+				hex.WriteByte(' ')
+			}
+			if j < len(line) {
+				fmt.Fprintf(&hex, "%02x ", line[j])
+			} else {
+				hex.WriteString("   ")
+			}
+		}
+		for _, b := range line {
+			if b >= 0x20 && b < 0x7f {
+				ascii.WriteByte(b)
+			} else {
+				ascii.WriteByte('.')
+			}
+		}
+		fmt.Fprintf(w, "    %08x  %s |%s|\n", i, hex.String(), ascii.String())
+	}
 }
 
 func writeHeaders(w io.Writer, headers map[string][]string) {
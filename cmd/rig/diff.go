@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+func runDiff(args []string) error {
+	pattern, flagArgs := extractFile(args)
+
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var (
+		baseline string
+		current  string
+		jsonOut  bool
+	)
+	fs.StringVar(&baseline, "baseline", "", "baseline log file (requires --current)")
+	fs.StringVar(&current, "current", "", "current log file (requires --baseline)")
+	fs.BoolVar(&jsonOut, "json", false, "output structured JSON")
+	fs.Usage = printDiffUsage
+
+	if err := fs.Parse(flagArgs); err != nil {
+		return err
+	}
+	if pattern == "" && fs.NArg() > 0 {
+		pattern = fs.Arg(0)
+	}
+
+	switch {
+	case baseline != "" || current != "":
+		if baseline == "" || current == "" {
+			return fmt.Errorf("--baseline and --current must be used together")
+		}
+		if pattern != "" {
+			return fmt.Errorf("cannot combine a test name with --baseline/--current")
+		}
+	case pattern == "":
+		return fmt.Errorf("missing test argument\n\nUsage: rig diff <test> [flags]\n       rig diff --baseline <file> --current <file> [flags]")
+	default:
+		var err error
+		if baseline, current, err = latestPassFail(pattern); err != nil {
+			return err
+		}
+	}
+
+	base, err := loadDiffRun(baseline)
+	if err != nil {
+		return fmt.Errorf("baseline: %w", err)
+	}
+	cur, err := loadDiffRun(current)
+	if err != nil {
+		return fmt.Errorf("current: %w", err)
+	}
+	report := rigdata.ComputeDiff(base, cur)
+
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	renderDiff(os.Stdout, report)
+	return nil
+}
+
+// latestPassFail finds the most recent passing and most recent failing
+// (or crashed) logs of the test matching pattern.
+func latestPassFail(pattern string) (passed, failed string, err error) {
+	paths, err := rigdata.ScanLogDir(pattern)
+	if err != nil {
+		return "", "", fmt.Errorf("read log directory: %w", err)
+	}
+
+	var entries []rigdata.LsEntry
+	envs := map[string]bool{}
+	for _, path := range paths {
+		hdr, err := rigdata.ReadHeader(path)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, rigdata.LsEntry{Path: path, Header: hdr})
+		envs[hdr.Environment] = true
+	}
+	if len(envs) > 1 {
+		names := make([]string, 0, len(envs))
+		for n := range envs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", "", fmt.Errorf("%q matches logs from several tests (%s); be more specific or use --baseline/--current",
+			pattern, strings.Join(names, ", "))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Header.Timestamp.After(entries[j].Header.Timestamp)
+	})
+	for _, e := range entries {
+		switch e.Header.Outcome {
+		case "passed":
+			if passed == "" {
+				passed = e.Path
+			}
+		case "failed", "crashed":
+			if failed == "" {
+				failed = e.Path
+			}
+		}
+	}
+	if passed == "" || failed == "" {
+		return "", "", fmt.Errorf("need a passing and a failing run of %q in %s; use --baseline/--current to pick files",
+			pattern, rigdata.LogDir())
+	}
+	return passed, failed, nil
+}
+
+// loadDiffRun reads the header, traffic, and logs of one log file.
+func loadDiffRun(arg string) (rigdata.DiffRun, error) {
+	path, err := rigdata.ResolveLogFile(arg)
+	if err != nil {
+		return rigdata.DiffRun{}, err
+	}
+	hdr, err := rigdata.ReadHeader(path)
+	if err != nil {
+		return rigdata.DiffRun{}, fmt.Errorf("%s: %w", path, err)
+	}
+	run := rigdata.DiffRun{Path: path, Header: hdr}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return rigdata.DiffRun{}, err
+	}
+	defer f.Close()
+	if run.Traffic, err = rigdata.ParseTrafficEvents(f); err != nil {
+		return rigdata.DiffRun{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return rigdata.DiffRun{}, err
+	}
+	if run.Logs, err = rigdata.ParseLogEvents(f); err != nil {
+		return rigdata.DiffRun{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return run, nil
+}
+
+func renderDiff(w io.Writer, r rigdata.DiffReport) {
+	for _, s := range []struct {
+		label string
+		side  rigdata.DiffSide
+	}{{"baseline", r.Baseline}, {"current ", r.Current}} {
+		fmt.Fprintf(w, "%s  %s  %s  %s\n", bold(s.label), s.side.Path,
+			colorOutcome(s.side.Outcome), rigdata.FormatLsDuration(s.side.DurationMs))
+	}
+
+	if len(r.AddedServices) > 0 || len(r.RemovedServices) > 0 {
+		fmt.Fprintf(w, "\n%s\n", bold("Services"))
+		for _, s := range r.AddedServices {
+			fmt.Fprintf(w, "  + %s  %s\n", s, dim("(only in current)"))
+		}
+		for _, s := range r.RemovedServices {
+			fmt.Fprintf(w, "  - %s  %s\n", s, dim("(only in baseline)"))
+		}
+	}
+
+	if len(r.Edges) > 0 {
+		fmt.Fprintf(w, "\n%s\n", bold("Traffic"))
+		renderEdgeDiffs(w, r.Edges)
+	}
+
+	var changed []rigdata.LogDiff
+	for _, l := range r.Logs {
+		if l.NewLinesTotal > 0 {
+			changed = append(changed, l)
+		}
+	}
+	if len(changed) > 0 {
+		fmt.Fprintf(w, "\n%s\n", bold("New log lines"))
+		for _, l := range changed {
+			fmt.Fprintf(w, "  %s  %s\n", l.Service,
+				dim(fmt.Sprintf("(%d → %d lines)", l.BaselineLines, l.CurrentLines)))
+			for _, line := range l.NewLines {
+				fmt.Fprintf(w, "    + %s\n", line)
+			}
+			if more := l.NewLinesTotal - len(l.NewLines); more > 0 {
+				fmt.Fprintf(w, "    %s\n", dim(fmt.Sprintf("… %d more", more)))
+			}
+		}
+	}
+}
+
+func renderEdgeDiffs(w io.Writer, edges []rigdata.EdgeDiff) {
+	headers := [4]string{"EDGE", "COUNT", "ERRORS", "P50"}
+	rows := make([][4]string, len(edges))
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+	}
+	for i, e := range edges {
+		edge := e.Source + " → " + e.Target
+		switch {
+		case e.Baseline == nil:
+			edge = "+ " + edge
+		case e.Current == nil:
+			edge = "- " + edge
+		default:
+			edge = "  " + edge
+		}
+		b, c := e.Baseline, e.Current
+		rows[i] = [4]string{
+			edge,
+			diffCell(b, c, func(s *rigdata.EdgeStats) string { return fmt.Sprint(s.Count) }),
+			diffCell(b, c, func(s *rigdata.EdgeStats) string { return fmt.Sprint(s.Errors) }),
+			diffCell(b, c, func(s *rigdata.EdgeStats) string { return rigdata.FormatLatency(s.P50Ms) }),
+		}
+		for j, cell := range rows[i] {
+			if n := utf8.RuneCountInString(cell); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+
+	fmt.Fprint(w, "  ")
+	for i, h := range headers {
+		if i > 0 {
+			fmt.Fprint(w, "  ")
+		}
+		fmt.Fprintf(w, "%-*s", widths[i], bold(h))
+	}
+	fmt.Fprintln(w)
+	for ri, cells := range rows {
+		fmt.Fprint(w, "  ")
+		for i, c := range cells {
+			if i > 0 {
+				fmt.Fprint(w, "  ")
+			}
+			if i == len(cells)-1 {
+				fmt.Fprint(w, c)
+				continue
+			}
+			padded := fmt.Sprintf("%-*s", widths[i], c)
+			if i == 2 && edgeErrorsRose(edges[ri]) {
+				padded = red(padded)
+			}
+			fmt.Fprint(w, padded)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// diffCell renders a stat as "before → after", or a single value when it
+// is unchanged. A missing side renders as "-".
+func diffCell(b, c *rigdata.EdgeStats, f func(*rigdata.EdgeStats) string) string {
+	before, after := "-", "-"
+	if b != nil {
+		before = f(b)
+	}
+	if c != nil {
+		after = f(c)
+	}
+	if before == after {
+		return after
+	}
+	return before + " → " + after
+}
+
+func edgeErrorsRose(e rigdata.EdgeDiff) bool {
+	if e.Current == nil {
+		return false
+	}
+	return e.Baseline == nil && e.Current.Errors > 0 ||
+		e.Baseline != nil && e.Current.Errors > e.Baseline.Errors
+}
+
+func printDiffUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: rig diff <test> [flags]
+       rig diff --baseline <file> --current <file> [flags]
+
+Compare two runs of a test: services added or removed, per-edge request
+count, errors, and p50 latency, and log lines new in the current run
+(numbers are ignored when matching lines).
+
+With a test name, compares its most recent passing run (baseline) against
+its most recent failing or crashed run (current). With --baseline and
+--current, compares those two log files directly.
+
+Flags:
+  --baseline <file>   baseline log file or name pattern
+  --current <file>    current log file or name pattern
+  --json              output structured JSON (same schema in both modes)
+`)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+func TestRunDiffExplicitFiles(t *testing.T) {
+	output := captureStdout(t, func() {
+		err := runDiff([]string{"--baseline", "testdata/diff_baseline.jsonl", "--current", "testdata/diff_current.jsonl", "--json"})
+		if err != nil {
+			t.Fatalf("runDiff: %v", err)
+		}
+	})
+
+	var r rigdata.DiffReport
+	if err := json.Unmarshal([]byte(output), &r); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, output)
+	}
+	if r.Baseline.Outcome != "passed" || r.Current.Outcome != "failed" {
+		t.Errorf("outcomes = %q, %q", r.Baseline.Outcome, r.Current.Outcome)
+	}
+	if len(r.AddedServices) != 1 || r.AddedServices[0] != "cache" {
+		t.Errorf("added_services = %v, want [cache]", r.AddedServices)
+	}
+	if len(r.RemovedServices) != 1 || r.RemovedServices[0] != "worker" {
+		t.Errorf("removed_services = %v, want [worker]", r.RemovedServices)
+	}
+
+	edges := map[string]rigdata.EdgeDiff{}
+	for _, e := range r.Edges {
+		edges[e.Source+"→"+e.Target] = e
+	}
+	if e := edges["test→api"]; e.Baseline == nil || e.Current == nil || e.Baseline.Errors != 0 || e.Current.Errors != 1 {
+		t.Errorf("test→api = %+v", e)
+	}
+	if e := edges["api→cache"]; e.Baseline != nil || e.Current == nil {
+		t.Errorf("api→cache should only be in current: %+v", e)
+	}
+	if e := edges["api→worker"]; e.Baseline == nil || e.Current != nil {
+		t.Errorf("api→worker should only be in baseline: %+v", e)
+	}
+
+	// Logs cover services in both runs; lines differing only in numbers
+	// (ports, IDs, durations) don't count as new.
+	if len(r.Logs) != 2 {
+		t.Fatalf("logs = %+v, want api and db", r.Logs)
+	}
+	api := r.Logs[0]
+	if api.Service != "api" || api.BaselineLines != 2 || api.CurrentLines != 3 {
+		t.Errorf("api log diff = %+v", api)
+	}
+	if api.NewLinesTotal != 1 || api.NewLines[0] != "cache miss for cart 7: connection refused" {
+		t.Errorf("api new lines = %q (total %d)", api.NewLines, api.NewLinesTotal)
+	}
+}
+
+func TestRunDiffByTestName(t *testing.T) {
+	dir := t.TempDir()
+	logDir := filepath.Join(dir, "logs")
+	os.MkdirAll(logDir, 0o755)
+	copyFile(t, "testdata/diff_baseline.jsonl", filepath.Join(logDir, "TestCheckout-19480a00000-aabbccdd.jsonl"))
+	copyFile(t, "testdata/diff_current.jsonl", filepath.Join(logDir, "TestCheckout-19480a00001-11223344.jsonl"))
+	copyFile(t, "testdata/passed.jsonl", filepath.Join(logDir, "TestBasic-19480a00002-deadbeef.jsonl"))
+	t.Setenv("RIG_DIR", dir)
+
+	output := captureStdout(t, func() {
+		if err := runDiff([]string{"TestCheckout", "--json"}); err != nil {
+			t.Fatalf("runDiff: %v", err)
+		}
+	})
+	var r rigdata.DiffReport
+	if err := json.Unmarshal([]byte(output), &r); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, output)
+	}
+	if !strings.Contains(r.Baseline.Path, "aabbccdd") || !strings.Contains(r.Current.Path, "11223344") {
+		t.Errorf("baseline = %s, current = %s", r.Baseline.Path, r.Current.Path)
+	}
+
+	// Only a passing run: nothing to compare against.
+	if err := runDiff([]string{"TestBasic"}); err == nil || !strings.Contains(err.Error(), "need a passing and a failing run") {
+		t.Errorf("err = %v", err)
+	}
+	// A pattern spanning tests is ambiguous.
+	if err := runDiff([]string{"Test*"}); err == nil || !strings.Contains(err.Error(), "several tests") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRunDiffFlagErrors(t *testing.T) {
+	if err := runDiff([]string{"--baseline", "testdata/diff_baseline.jsonl"}); err == nil {
+		t.Error("expected error for --baseline without --current")
+	}
+	if err := runDiff([]string{"TestCheckout", "--baseline", "a.jsonl", "--current", "b.jsonl"}); err == nil {
+		t.Error("expected error for test name combined with --baseline/--current")
+	}
+}
+
+func TestRenderDiff(t *testing.T) {
+	base, err := loadDiffRun("testdata/diff_baseline.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur, err := loadDiffRun("testdata/diff_current.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	renderDiff(&buf, rigdata.ComputeDiff(base, cur))
+	out := buf.String()
+	for _, want := range []string{
+		"+ cache",
+		"- worker",
+		"+ api → cache",
+		"- api → worker",
+		"0 → 1",
+		"12.0ms → 40.0ms",
+		"+ cache miss for cart 7: connection refused",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
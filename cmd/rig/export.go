@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+func runExport(args []string) error {
+	filename, flagArgs := extractFile(args)
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var (
+		format   string
+		endpoint string
+	)
+	fs.StringVar(&format, "format", "otlp", `output format (only "otlp" is supported)`)
+	fs.StringVar(&endpoint, "endpoint", "", "OTLP collector: host:port for OTLP/gRPC (e.g. localhost:4317) or a URL for OTLP/HTTP (e.g. http://localhost:4318); prints JSON to stdout when empty")
+
+	if err := fs.Parse(flagArgs); err != nil {
+		return err
+	}
+	if filename == "" {
+		if fs.NArg() > 0 {
+			filename = fs.Arg(0)
+		} else {
+			return fmt.Errorf("missing JSONL file argument\n\nUsage: rig export <file.jsonl> [flags]")
+		}
+	}
+	if format != "otlp" {
+		return fmt.Errorf("unsupported --format %q (supported: otlp)", format)
+	}
+
+	resolved, err := rigdata.ResolveLogFile(filename)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	events, err := rigdata.ParseTrafficEvents(f)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		fmt.Fprintln(os.Stderr, "No traffic events found.")
+		return nil
+	}
+
+	salt := strings.TrimSuffix(filepath.Base(resolved), ".jsonl")
+	traces := rigdata.BuildOTLP(events, salt)
+
+	if endpoint == "" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(traces)
+	}
+
+	post := postOTLPGRPC
+	if strings.Contains(endpoint, "://") {
+		post = postOTLP
+	}
+	n, err := post(endpoint, traces)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d spans to %s\n", n, endpoint)
+	return nil
+}
+
+// postOTLP sends traces to an OTLP/HTTP collector using the JSON encoding.
+// Returns the number of spans sent.
+func postOTLP(endpoint string, traces rigdata.OTLPTraces) (int, error) {
+	body, err := json.Marshal(traces)
+	if err != nil {
+		return 0, err
+	}
+	url := strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("export to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("collector returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return spanCount(traces), nil
+}
+
+// otlpGRPCExportPath is the gRPC method collectors serve on :4317.
+const otlpGRPCExportPath = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+
+// postOTLPGRPC sends traces to an OTLP/gRPC collector at host:port as a
+// single unary Export call. gRPC is spoken directly over cleartext HTTP/2,
+// which is what local collectors such as Jaeger accept by default.
+// Returns the number of spans sent.
+func postOTLPGRPC(endpoint string, traces rigdata.OTLPTraces) (int, error) {
+	msg, err := traces.MarshalProto()
+	if err != nil {
+		return 0, err
+	}
+	// gRPC length-prefixed message: uncompressed flag, then big-endian size.
+	body := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(msg)))
+	body = append(body, msg...)
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+endpoint+otlpGRPCExportPath, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("export to %s: %w", endpoint, err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{Protocols: &protocols},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("export to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	// Trailers are only populated once the body has been read.
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("collector returned HTTP %d", resp.StatusCode)
+	}
+
+	// A failed call may be trailers-only, in which case the status arrives
+	// with the headers.
+	status, message := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		if m, err := url.PathUnescape(message); err == nil {
+			message = m
+		}
+		return 0, fmt.Errorf("collector returned gRPC status %s: %s", status, message)
+	}
+	return spanCount(traces), nil
+}
+
+func spanCount(traces rigdata.OTLPTraces) int {
+	var n int
+	for _, rs := range traces.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			n += len(ss.Spans)
+		}
+	}
+	return n
+}
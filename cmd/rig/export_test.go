@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+func TestBuildOTLP(t *testing.T) {
+	events := loadTestEvents(t, "testdata/mixed_traffic.jsonl")
+	traces := rigdata.BuildOTLP(events, "test")
+
+	spans := map[string]rigdata.OTLPSpan{}
+	services := map[string]bool{}
+	for _, rs := range traces.ResourceSpans {
+		services[*rs.Resource.Attributes[0].Value.StringValue] = true
+		for _, ss := range rs.ScopeSpans {
+			for _, s := range ss.Spans {
+				spans[s.Name] = s
+			}
+		}
+	}
+	if !services["order"] || !services["temporal"] {
+		t.Errorf("resource services = %v, want order and temporal", services)
+	}
+
+	post, ok := spans["POST /orders"]
+	if !ok {
+		t.Fatalf("missing span for POST /orders; have %v", spans)
+	}
+	if len(post.TraceID) != 32 || len(post.SpanID) != 16 {
+		t.Errorf("trace/span IDs = %q/%q, want 32/16 hex chars", post.TraceID, post.SpanID)
+	}
+	if post.ParentSpanID != "" {
+		t.Errorf("ParentSpanID = %q, want empty without traceparent", post.ParentSpanID)
+	}
+	// latency 2.1ms ending at 10:00:00.412
+	end := time.Date(2026, 2, 23, 10, 0, 0, 412_000_000, time.UTC)
+	wantStart := end.Add(-2100 * time.Microsecond)
+	if post.EndTimeUnixNano != jsonInt(end.UnixNano()) || post.StartTimeUnixNano != jsonInt(wantStart.UnixNano()) {
+		t.Errorf("span times = %s..%s, want %d..%d", post.StartTimeUnixNano, post.EndTimeUnixNano, wantStart.UnixNano(), end.UnixNano())
+	}
+
+	if _, ok := spans["WorkflowService/Start"]; !ok {
+		t.Error("missing span for gRPC WorkflowService/Start")
+	}
+
+	// Deterministic: exporting the same log twice yields the same IDs.
+	again := rigdata.BuildOTLP(events, "test")
+	if got := again.ResourceSpans[0].ScopeSpans[0].Spans[0].SpanID; got != traces.ResourceSpans[0].ScopeSpans[0].Spans[0].SpanID {
+		t.Error("span IDs differ between exports of the same log")
+	}
+}
+
+func TestBuildOTLP_Traceparent(t *testing.T) {
+	events := []rigdata.Event{{
+		Seq:       7,
+		Type:      rigdata.TypeRequestCompleted,
+		Timestamp: time.Now(),
+		Request: &rigdata.RequestInfo{
+			Source: "api", Target: "billing", Method: "GET", Path: "/", StatusCode: 503,
+			RequestHeaders: map[string][]string{
+				"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			},
+		},
+	}}
+	span := rigdata.BuildOTLP(events, "x").ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if span.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceID = %q, want propagated trace", span.TraceID)
+	}
+	if span.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("ParentSpanID = %q, want caller span", span.ParentSpanID)
+	}
+	if span.Status.Code != 2 {
+		t.Errorf("Status.Code = %d, want error for 503", span.Status.Code)
+	}
+}
+
+func TestBuildOTLP_RigTrace(t *testing.T) {
+	events := []rigdata.Event{{
+		Seq:       3,
+		Type:      rigdata.TypeGRPCCallCompleted,
+		Timestamp: time.Now(),
+		GRPCCall: &rigdata.GRPCCallInfo{
+			Source: "api", Target: "billing", Service: "billing.v1.Billing", Method: "Charge", GRPCStatus: "OK",
+			TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "b7ad6b7169203331", ParentSpanID: "00f067aa0ba902b7",
+		},
+	}}
+	span := rigdata.BuildOTLP(events, "x").ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if span.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || span.SpanID != "b7ad6b7169203331" || span.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("span IDs = %s/%s/%s, want the recorded X-Rig-Trace IDs", span.TraceID, span.SpanID, span.ParentSpanID)
+	}
+}
+
+func TestPostOTLP(t *testing.T) {
+	var gotPath string
+	var got rigdata.OTLPTraces
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+	}))
+	defer ts.Close()
+
+	events := loadTestEvents(t, "testdata/mixed_traffic.jsonl")
+	n, err := postOTLP(ts.URL, rigdata.BuildOTLP(events, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(events) {
+		t.Errorf("exported %d spans, want %d", n, len(events))
+	}
+	if gotPath != "/v1/traces" {
+		t.Errorf("path = %q, want /v1/traces", gotPath)
+	}
+	if len(got.ResourceSpans) == 0 {
+		t.Error("collector received no resource spans")
+	}
+}
+
+func TestPostOTLPGRPC(t *testing.T) {
+	var gotPath, gotContentType string
+	var gotFrame []byte
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotContentType = r.URL.Path, r.Header.Get("Content-Type")
+		gotFrame, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Write([]byte{0, 0, 0, 0, 0}) // empty ExportTraceServiceResponse
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+
+	events := loadTestEvents(t, "testdata/mixed_traffic.jsonl")
+	traces := rigdata.BuildOTLP(events, "test")
+	n, err := postOTLPGRPC(ts.Listener.Addr().String(), traces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(events) {
+		t.Errorf("exported %d spans, want %d", n, len(events))
+	}
+	if gotPath != "/opentelemetry.proto.collector.trace.v1.TraceService/Export" {
+		t.Errorf("path = %q, want the TraceService/Export method", gotPath)
+	}
+	if gotContentType != "application/grpc" {
+		t.Errorf("content-type = %q, want application/grpc", gotContentType)
+	}
+	if len(gotFrame) < 5 || gotFrame[0] != 0 || int(binary.BigEndian.Uint32(gotFrame[1:5])) != len(gotFrame)-5 {
+		t.Fatalf("request is not a single uncompressed gRPC message: % x", gotFrame[:min(len(gotFrame), 5)])
+	}
+
+	// Walk ExportTraceServiceRequest → ResourceSpans → ScopeSpans → Span.
+	var names []string
+	var services []string
+	for _, rs := range protoFields(t, gotFrame[5:])[1] {
+		rsf := protoFields(t, rs)
+		for _, kv := range protoFields(t, rsf[1][0])[1] {
+			kvf := protoFields(t, kv)
+			if string(kvf[1][0]) == "service.name" {
+				services = append(services, string(protoFields(t, kvf[2][0])[1][0]))
+			}
+		}
+		for _, ss := range rsf[2] {
+			for _, span := range protoFields(t, ss)[2] {
+				sf := protoFields(t, span)
+				if len(sf[1][0]) != 16 || len(sf[2][0]) != 8 {
+					t.Errorf("trace/span ID = %d/%d bytes, want 16/8", len(sf[1][0]), len(sf[2][0]))
+				}
+				names = append(names, string(sf[5][0]))
+			}
+		}
+	}
+	if len(services) != len(traces.ResourceSpans) || services[0] != "order" {
+		t.Errorf("service.name resources = %v, want one per source starting with order", services)
+	}
+	if !slices.Contains(names, "POST /orders") || len(names) != len(events) {
+		t.Errorf("span names = %v, want %d spans including POST /orders", names, len(events))
+	}
+}
+
+func TestPostOTLPGRPCStatusError(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "12")
+		w.Header().Set("Grpc-Message", "unknown%20service")
+	}))
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+
+	events := loadTestEvents(t, "testdata/mixed_traffic.jsonl")
+	_, err := postOTLPGRPC(ts.Listener.Addr().String(), rigdata.BuildOTLP(events, "test"))
+	if err == nil || !strings.Contains(err.Error(), "gRPC status 12: unknown service") {
+		t.Errorf("err = %v, want gRPC status 12 with the decoded message", err)
+	}
+}
+
+// protoFields splits a protobuf message into its length-delimited fields,
+// keyed by field number. Other wire types are skipped.
+func protoFields(t *testing.T, b []byte) map[int][][]byte {
+	t.Helper()
+	fields := map[int][][]byte{}
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("bad tag in % x", b)
+		}
+		b = b[n:]
+		switch tag & 7 {
+		case 0:
+			_, n = binary.Uvarint(b)
+			b = b[n:]
+		case 1:
+			b = b[8:]
+		case 2:
+			size, n := binary.Uvarint(b)
+			b = b[n:]
+			fields[int(tag>>3)] = append(fields[int(tag>>3)], b[:size])
+			b = b[size:]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+	}
+	return fields
+}
+
+func jsonInt(n int64) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
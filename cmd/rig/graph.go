@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+func runGraph(args []string) error {
+	target, flagArgs := extractFile(args)
+
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	var format string
+	fs.StringVar(&format, "format", "dot", `output format: "dot" or "json"`)
+	fs.Usage = printGraphUsage
+
+	if err := fs.Parse(flagArgs); err != nil {
+		return err
+	}
+	if target == "" {
+		if fs.NArg() > 0 {
+			target = fs.Arg(0)
+		} else {
+			return fmt.Errorf("missing spec file or environment argument\n\nUsage: rig graph <spec.json|env> [flags]")
+		}
+	}
+	if format != "dot" && format != "json" {
+		return fmt.Errorf("invalid --format %q: want dot or json", format)
+	}
+
+	g, err := loadGraph(target)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g)
+	}
+	writeDOT(os.Stdout, g)
+	return nil
+}
+
+// loadGraph builds the graph from a spec file if target names one, and
+// otherwise fetches it from the active environment target names.
+func loadGraph(target string) (*rigdata.Graph, error) {
+	if f, err := os.Open(target); err == nil {
+		defer f.Close()
+		return rigdata.GraphFromSpec(f)
+	}
+
+	addr, err := rigdata.ServerAddr(RigdVersion)
+	if err != nil {
+		return nil, err
+	}
+	id, err := rigdata.ResolveEnvID(addr, target)
+	if err != nil {
+		return nil, err
+	}
+	return rigdata.FetchGraph(addr, id)
+}
+
+// writeDOT renders g as a Graphviz digraph. Nodes are labelled with their
+// service type and edges with the egress name, target ingress (when not
+// "default"), and protocol. External egresses point at a node named by
+// their URL.
+func writeDOT(w io.Writer, g *rigdata.Graph) {
+	fmt.Fprintln(w, "digraph rig {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(w, "  %s [label=%s];\n", strconv.Quote(n.Name), strconv.Quote(n.Name+"\n("+n.Type+")"))
+	}
+	for _, e := range g.Edges {
+		label := e.Egress
+		if e.Ingress != "" && e.Ingress != "default" {
+			label += " → " + e.Ingress
+		}
+		if e.Protocol != "" {
+			label += "\n" + e.Protocol
+		}
+		to := e.To
+		if e.External != "" {
+			to = e.External
+		}
+		fmt.Fprintf(w, "  %s -> %s [label=%s];\n", strconv.Quote(e.From), strconv.Quote(to), strconv.Quote(label))
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func printGraphUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: rig graph <spec.json|env> [flags]
+
+Print the service topology of an environment spec file, or of an active
+environment (by name or ID), as Graphviz DOT. Render it with:
+
+  rig graph spec.json | dot -Tsvg > graph.svg
+
+Flags:
+  --format <fmt>   Output format: dot (default) or json
+`)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+func TestGraphFromSpec(t *testing.T) {
+	spec := `{
+		"name": "orders",
+		"services": {
+			"api": {
+				"type": "go",
+				"ingresses": {"default": {"protocol": "http"}},
+				"egresses": {"db": {"service": "db"}, "temporal": {"service": "temporal", "ingress": "frontend"}}
+			},
+			"db": {"type": "postgres", "ingresses": {"default": {"protocol": "tcp"}}},
+			"temporal": {"type": "temporal", "ingresses": {"frontend": {"protocol": "grpc"}, "ui": {"protocol": "http"}}}
+		}
+	}`
+	g, err := rigdata.GraphFromSpec(strings.NewReader(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 3 || g.Nodes[0].Name != "api" || g.Nodes[0].Type != "go" {
+		t.Errorf("nodes = %+v", g.Nodes)
+	}
+	want := []rigdata.GraphEdge{
+		{From: "api", To: "db", Egress: "db", Ingress: "default", Protocol: "tcp"},
+		{From: "api", To: "temporal", Egress: "temporal", Ingress: "frontend", Protocol: "grpc"},
+	}
+	if len(g.Edges) != len(want) {
+		t.Fatalf("edges = %+v, want %+v", g.Edges, want)
+	}
+	for i := range want {
+		if g.Edges[i] != want[i] {
+			t.Errorf("edge %d = %+v, want %+v", i, g.Edges[i], want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	writeDOT(&buf, g)
+	out := buf.String()
+	for _, line := range []string{
+		`"api" [label="api\n(go)"];`,
+		`"api" -> "db" [label="db\ntcp"];`,
+		`"api" -> "temporal" [label="temporal → frontend\ngrpc"];`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("DOT output missing %s\n%s", line, out)
+		}
+	}
+}
+
+// TestGraphFromSpec_Parity checks GraphFromSpec against the graph rigd
+// builds for the same spec; the server side of the fixture is checked by
+// TestBuildGraph_Parity in internal/server.
+func TestGraphFromSpec_Parity(t *testing.T) {
+	f, err := os.Open("../../internal/testdata/graph/spec.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := rigdata.GraphFromSpec(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile("../../internal/testdata/graph/graph.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want rigdata.Graph
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("graph = %+v\nwant %+v", *got, want)
+	}
+
+	var buf bytes.Buffer
+	writeDOT(&buf, got)
+	if line := `"api" -> "https://payments.example.com" [label="payments\nhttp"];`; !strings.Contains(buf.String(), line) {
+		t.Errorf("DOT output missing %s\n%s", line, buf.String())
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// scaffoldData is the input to the init templates.
+type scaffoldData struct {
+	Package  string // package of the directory the test file is written to
+	Service  string // service name; also the cmd/ directory name
+	TestName string // suffix of the generated Test function
+}
+
+// scaffoldFile maps a template to the path it is rendered to.
+type scaffoldFile struct {
+	template string
+	path     string
+}
+
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	var (
+		service string
+		force   bool
+	)
+	fs.StringVar(&service, "service", "api", "name of the sample service")
+	fs.BoolVar(&force, "force", false, "overwrite existing files")
+	fs.Usage = printInitUsage
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if !validServiceName(service) {
+		return fmt.Errorf("invalid --service %q: use letters, digits, '-' or '_'", service)
+	}
+
+	pkg, err := packageName(dir)
+	if err != nil {
+		return err
+	}
+	data := scaffoldData{
+		Package:  pkg,
+		Service:  service,
+		TestName: exportedName(service),
+	}
+	files := []scaffoldFile{
+		{template: "rig_test.go.tmpl", path: filepath.Join(dir, "rig_test.go")},
+		{template: "main.go.tmpl", path: filepath.Join(dir, "cmd", service, "main.go")},
+	}
+
+	if !force {
+		for _, f := range files {
+			if _, err := os.Stat(f.path); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", f.path)
+			}
+		}
+	}
+
+	for _, f := range files {
+		src, err := renderScaffold(f.template, data)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.path, src, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("  %s %s\n", dim("created"), f.path)
+	}
+
+	fmt.Printf(`
+Next steps:
+  go get github.com/matgreaves/rig github.com/matgreaves/rig/connect/pgx
+  go test -run Test%s %s
+`, data.TestName, testTarget(dir))
+	return nil
+}
+
+// renderScaffold executes the named template and gofmts the result.
+func renderScaffold(name string, data scaffoldData) ([]byte, error) {
+	tmpl, err := template.ParseFS(templateFS, "templates/"+name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render %s: %w", name, err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format %s: %w", name, err)
+	}
+	return src, nil
+}
+
+// packageName returns the package declared by the non-test Go files in dir,
+// or a name derived from the directory when it has none yet.
+func packageName(dir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", err
+	}
+	for _, m := range matches {
+		if strings.HasSuffix(m, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), m, nil, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		if name := f.Name.Name; name != "main" {
+			return name, nil
+		}
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(abs)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "app" + name
+	}
+	return name, nil
+}
+
+// exportedName converts a service name like "order-api" into "OrderApi".
+func exportedName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if r == '-' || r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func validServiceName(s string) bool {
+	if s == "" || !unicode.IsLetter(rune(s[0])) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// testTarget returns the go test package argument for dir.
+func testTarget(dir string) string {
+	if dir == "." {
+		return "."
+	}
+	if filepath.IsAbs(dir) || strings.HasPrefix(dir, ".") {
+		return dir
+	}
+	return "./" + dir
+}
+
+func printInitUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: rig init [dir] [flags]
+
+Scaffold a rig test for the package in dir (default: current directory):
+a rig_test.go that starts a Go service and Postgres and asserts on a
+response, plus the service itself in cmd/<service>/main.go.
+
+Flags:
+  --service <name>   Name of the sample service (default: api)
+  --force            Overwrite existing files
+`)
+}
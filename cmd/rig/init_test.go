@@ -0,0 +1,77 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunInit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "orders")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "orders.go"), []byte("package orderstore\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runInit([]string{"--service", "order-api", dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	testFile := filepath.Join(dir, "rig_test.go")
+	f, err := parser.ParseFile(token.NewFileSet(), testFile, nil, 0)
+	if err != nil {
+		t.Fatalf("generated test does not parse: %v", err)
+	}
+	if f.Name.Name != "orderstore_test" {
+		t.Errorf("package = %q, want orderstore_test", f.Name.Name)
+	}
+	src, _ := os.ReadFile(testFile)
+	for _, want := range []string{"func TestOrderApi(", `rig.Go("./cmd/order-api").Egress("db")`, "rig.Postgres()"} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("rig_test.go missing %q", want)
+		}
+	}
+
+	mainFile := filepath.Join(dir, "cmd", "order-api", "main.go")
+	m, err := parser.ParseFile(token.NewFileSet(), mainFile, nil, 0)
+	if err != nil {
+		t.Fatalf("generated main does not parse: %v", err)
+	}
+	if m.Name.Name != "main" {
+		t.Errorf("main.go package = %q, want main", m.Name.Name)
+	}
+
+	// A second run refuses to overwrite without --force.
+	err = runInit([]string{"--service", "order-api", dir})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second init: err = %v, want already exists", err)
+	}
+	if err := runInit([]string{"--service", "order-api", "--force", dir}); err != nil {
+		t.Errorf("init --force: %v", err)
+	}
+}
+
+func TestRunInit_InvalidService(t *testing.T) {
+	if err := runInit([]string{"--service", "my api", t.TempDir()}); err == nil {
+		t.Error("expected error for invalid service name")
+	}
+}
+
+func TestPackageName_FromDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my-app")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := packageName(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "myapp" {
+		t.Errorf("packageName = %q, want myapp", got)
+	}
+}
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/matgreaves/rig/cmd/rig/rigdata"
 )
@@ -16,15 +23,17 @@ func runLogs(args []string) error {
 
 	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
 	var (
-		service string
-		stderr  bool
-		stdout  bool
-		grep    string
+		filter logFilter
+		grep   string
+		follow bool
+		server string
 	)
-	fs.StringVar(&service, "service", "", "filter to a specific service")
-	fs.BoolVar(&stderr, "stderr", false, "only show stderr output")
-	fs.BoolVar(&stdout, "stdout", false, "only show stdout output")
+	fs.StringVar(&filter.service, "service", "", "filter to a specific service")
+	fs.BoolVar(&filter.stderr, "stderr", false, "only show stderr output")
+	fs.BoolVar(&filter.stdout, "stdout", false, "only show stdout output")
 	fs.StringVar(&grep, "grep", "", "filter lines matching regex pattern")
+	fs.BoolVar(&follow, "follow", false, "stream logs from an active environment until it goes down")
+	fs.StringVar(&server, "server", "", "rigd address for --follow (default: read from the rigd addr file)")
 
 	if err := fs.Parse(flagArgs); err != nil {
 		return err
@@ -32,20 +41,42 @@ func runLogs(args []string) error {
 	if filename == "" {
 		if fs.NArg() > 0 {
 			filename = fs.Arg(0)
+		} else if follow {
+			return fmt.Errorf("missing environment argument\n\nUsage: rig logs <env> --follow [flags]")
 		} else {
 			return fmt.Errorf("missing JSONL file argument\n\nUsage: rig logs <file.jsonl> [flags]")
 		}
 	}
 
-	var grepRe *regexp.Regexp
 	if grep != "" {
 		var err error
-		grepRe, err = regexp.Compile(grep)
+		filter.grep, err = regexp.Compile(grep)
 		if err != nil {
 			return fmt.Errorf("invalid --grep pattern %q: %v", grep, err)
 		}
 	}
 
+	if follow {
+		addr, err := resolveServer(server)
+		if err != nil {
+			return err
+		}
+		id, err := rigdata.ResolveEnvID(addr, filename)
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		var services []string
+		if env, err := rigdata.FetchResolved(addr, id); err == nil {
+			for name := range env.Services {
+				services = append(services, name)
+			}
+		}
+		return followLogs(ctx, os.Stdout, addr, id, filter, services)
+	}
+
 	// Resolve glob pattern if the argument isn't a direct file path.
 	resolved, err := rigdata.ResolveLogFile(filename)
 	if err != nil {
@@ -80,43 +111,13 @@ func runLogs(args []string) error {
 		}
 	}
 
-	// Find the longest service name for alignment (minimum "TEST" width for notes).
-	maxName := 4 // len("TEST")
-	for name := range serviceIndex {
-		if len(name) > maxName {
-			maxName = len(name)
-		}
-	}
-
 	t0 := events[0].Timestamp
 	rows := make([]rigdata.LogRow, 0, len(events))
 	for _, ev := range events {
-		var row rigdata.LogRow
-		row.Time = rigdata.FormatDuration(ev.Timestamp.Sub(t0))
-
-		if ev.Type == rigdata.TypeTestNote {
-			row.Service = "TEST"
-			row.Stream = "note"
-			row.Data = ev.Error
-		} else {
-			row.Service = ev.Service
-			row.Stream = ev.Log.Stream
-			row.Data = ev.Log.Data
-		}
-
-		if service != "" && !strings.EqualFold(row.Service, service) {
-			continue
+		row := logRow(ev, t0)
+		if filter.match(row) {
+			rows = append(rows, row)
 		}
-		if stderr && row.Stream != "stderr" && row.Stream != "note" {
-			continue
-		}
-		if stdout && row.Stream != "stdout" {
-			continue
-		}
-		if grepRe != nil && !grepRe.MatchString(row.Data) {
-			continue
-		}
-		rows = append(rows, row)
 	}
 
 	if len(rows) == 0 {
@@ -125,19 +126,155 @@ func runLogs(args []string) error {
 	}
 
 	serviceColorTotal = len(serviceIndex)
-	renderLogs(os.Stdout, rows, serviceIndex, maxName)
+	renderLogs(os.Stdout, rows, serviceIndex, logNameWidth(serviceIndex))
 	return nil
 }
 
+// logFilter holds the rig logs row filters.
+type logFilter struct {
+	service string
+	stderr  bool
+	stdout  bool
+	grep    *regexp.Regexp
+}
+
+func (f logFilter) match(r rigdata.LogRow) bool {
+	if f.service != "" && !strings.EqualFold(r.Service, f.service) {
+		return false
+	}
+	if f.stderr && r.Stream != "stderr" && r.Stream != "note" {
+		return false
+	}
+	if f.stdout && r.Stream != "stdout" {
+		return false
+	}
+	if f.grep != nil && !f.grep.MatchString(r.Data) {
+		return false
+	}
+	return true
+}
+
+// logRow converts a service.log or test.note event into a display row,
+// timed relative to t0.
+func logRow(ev rigdata.LogEvent, t0 time.Time) rigdata.LogRow {
+	row := rigdata.LogRow{Time: rigdata.FormatDuration(ev.Timestamp.Sub(t0))}
+	if ev.Type == rigdata.TypeTestNote {
+		row.Service = "TEST"
+		row.Stream = "note"
+		row.Data = ev.Error
+	} else {
+		row.Service = ev.Service
+		row.Stream = ev.Log.Stream
+		row.Data = ev.Log.Data
+	}
+	return row
+}
+
+// logNameWidth returns the service column width: the longest service
+// name, and at least "TEST" wide for notes.
+func logNameWidth(serviceIndex map[string]int) int {
+	maxName := 4 // len("TEST")
+	for name := range serviceIndex {
+		if len(name) > maxName {
+			maxName = len(name)
+		}
+	}
+	return maxName
+}
+
+// followLogs streams an active environment's service logs and test notes
+// as they arrive, until environment.down, the stream closes, or ctx is
+// cancelled. Events arrive in order, so by the time environment.down is
+// read every log line published before it has been printed. services, if
+// known, fixes colors and the name column up front.
+func followLogs(ctx context.Context, w io.Writer, addr, id string, filter logFilter, services []string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/environments/"+id+"/events", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("connect to rigd: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("environment %s not found (may have already been torn down)", id)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("rigd returned %d: %s", resp.StatusCode, body)
+	}
+
+	serviceIndex := map[string]int{}
+	sort.Strings(services)
+	for _, name := range services {
+		if !strings.Contains(name, "~") {
+			serviceIndex[name] = len(serviceIndex)
+		}
+	}
+	serviceColorTotal = max(len(serviceIndex), 1)
+	maxName := logNameWidth(serviceIndex)
+
+	var t0 time.Time
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 256*1024), 4*1024*1024)
+	var data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && data != "":
+			var ev rigdata.LogEvent
+			err := json.Unmarshal([]byte(data), &ev)
+			data = ""
+			if err != nil {
+				continue
+			}
+			if t0.IsZero() {
+				t0 = ev.Timestamp
+			}
+			switch {
+			case ev.Type == "environment.down":
+				return nil
+			case ev.Type == rigdata.TypeServiceLog && ev.Log != nil,
+				ev.Type == rigdata.TypeTestNote && ev.Error != "":
+			default:
+				continue
+			}
+			row := logRow(ev, t0)
+			if !filter.match(row) {
+				continue
+			}
+			if _, ok := serviceIndex[row.Service]; !ok && row.Stream != "note" {
+				serviceIndex[row.Service] = len(serviceIndex)
+			}
+			renderLogs(w, []rigdata.LogRow{row}, serviceIndex, maxName)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil // interrupted
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("event stream read: %w", err)
+	}
+	return fmt.Errorf("event stream closed before environment.down")
+}
+
 func renderLogs(w io.Writer, rows []rigdata.LogRow, serviceIndex map[string]int, maxName int) {
 	for _, r := range rows {
 		name := fmt.Sprintf("%-*s", maxName, r.Service)
 		ts := dim(r.Time)
 
-		if r.Stream == "note" {
+		switch r.Stream {
+		case "note":
 			data := bold(colorNote("✗ " + r.Data))
 			fmt.Fprintf(w, "%s  %s  %s\n", ts, bold(colorNote(name)), data)
-		} else {
+		case "stderr":
+			idx := serviceIndex[r.Service]
+			fmt.Fprintf(w, "%s  %s  %s\n", ts, colorService(name, idx), colorStderr(r.Data))
+		default:
 			idx := serviceIndex[r.Service]
 			fmt.Fprintf(w, "%s  %s  %s\n", ts, colorService(name, idx), r.Data)
 		}
@@ -150,3 +287,11 @@ func colorNote(s string) string {
 	}
 	return ansiRed + s + ansiReset
 }
+
+// colorStderr sets stderr output apart from stdout.
+func colorStderr(s string) string {
+	if !colorEnabled {
+		return s
+	}
+	return ansiYellow + s + ansiReset
+}
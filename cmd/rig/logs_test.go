@@ -2,7 +2,9 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"os"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -207,3 +209,47 @@ func TestEmptyLogInput(t *testing.T) {
 		t.Errorf("got %d events, want 0", len(events))
 	}
 }
+
+func TestFollowLogs(t *testing.T) {
+	ts := sseServer(t,
+		`{"type":"service.starting","service":"api","timestamp":"2026-01-01T00:00:00Z"}`,
+		`{"type":"service.log","service":"api","log":{"stream":"stdout","data":"listening on :8080"},"timestamp":"2026-01-01T00:00:01Z"}`,
+		`{"type":"service.log","service":"worker","log":{"stream":"stderr","data":"connection refused"},"timestamp":"2026-01-01T00:00:02Z"}`,
+		`{"type":"service.log","service":"api","log":{"stream":"stderr","data":"connection refused"},"timestamp":"2026-01-01T00:00:03Z"}`,
+		`{"type":"environment.down","timestamp":"2026-01-01T00:00:04Z"}`,
+		`{"type":"service.log","service":"api","log":{"stream":"stdout","data":"after down"},"timestamp":"2026-01-01T00:00:05Z"}`,
+	)
+
+	var buf bytes.Buffer
+	if err := followLogs(context.Background(), &buf, ts.URL, "env-1", logFilter{}, []string{"api", "worker"}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"1.000s  api     listening on :8080", "2.000s  worker  connection refused"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "after down") {
+		t.Errorf("printed events after environment.down:\n%s", out)
+	}
+
+	buf.Reset()
+	filter := logFilter{service: "api", grep: regexp.MustCompile("refused")}
+	if err := followLogs(context.Background(), &buf, ts.URL, "env-1", filter, nil); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], "api") {
+		t.Errorf("filtered output = %q, want the api stderr line only", buf.String())
+	}
+}
+
+func TestFollowLogsStreamClosedEarly(t *testing.T) {
+	ts := sseServer(t, `{"type":"service.log","service":"api","log":{"stream":"stdout","data":"hi"},"timestamp":"2026-01-01T00:00:00Z"}`)
+
+	var buf bytes.Buffer
+	err := followLogs(context.Background(), &buf, ts.URL, "env-1", logFilter{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "before environment.down") {
+		t.Errorf("err = %v, want stream closed error", err)
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/matgreaves/rig/cmd/rig/rigdata"
 )
@@ -25,14 +28,26 @@ func runLs(args []string) error {
 		passed bool
 		quiet  bool
 		limit  int
+		labels labelFlags
+		format string
+		sortBy string
 	)
 	fs.BoolVar(&failed, "failed", false, "only show failed/crashed logs")
 	fs.BoolVar(&passed, "passed", false, "only show passed logs")
 	fs.BoolVar(&quiet, "q", false, "output file paths only, one per line")
 	fs.IntVar(&limit, "n", 0, "limit to the N most recent results")
+	fs.Var(&labels, "label", "only show logs with metadata key=value (repeatable)")
+	fs.StringVar(&format, "format", "table", `output format: "table", "tsv" or "json"`)
+	fs.StringVar(&sortBy, "sort", "time", `sort order: "time" (newest first), "duration" (slowest first) or "outcome" (crashed and failed first)`)
 	if err := fs.Parse(flagArgs); err != nil {
 		return err
 	}
+	if format != "table" && format != "tsv" && format != "json" {
+		return fmt.Errorf("invalid --format %q: want table, tsv or json", format)
+	}
+	if sortBy != "time" && sortBy != "duration" && sortBy != "outcome" {
+		return fmt.Errorf("invalid --sort %q: want time, duration or outcome", sortBy)
+	}
 	if pattern == "" && fs.NArg() > 0 {
 		pattern = fs.Arg(0)
 	}
@@ -60,6 +75,9 @@ func runLs(args []string) error {
 		if passed && hdr.Outcome != "passed" {
 			continue
 		}
+		if !labels.match(hdr.Metadata) {
+			continue
+		}
 
 		entries = append(entries, rigdata.LsEntry{Path: path, Header: hdr})
 	}
@@ -69,10 +87,7 @@ func runLs(args []string) error {
 		return errNoResults
 	}
 
-	// Sort by timestamp descending (newest first).
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Header.Timestamp.After(entries[j].Header.Timestamp)
-	})
+	sortLsEntries(entries, sortBy)
 
 	if limit > 0 && limit < len(entries) {
 		entries = entries[:limit]
@@ -85,20 +100,186 @@ func runLs(args []string) error {
 		return nil
 	}
 
+	switch format {
+	case "tsv":
+		renderLsTSV(os.Stdout, entries)
+		return nil
+	case "json":
+		return renderLsJSON(os.Stdout, entries)
+	}
 	renderLsTable(os.Stdout, entries)
 	return nil
 }
 
+// sortLsEntries orders entries newest first, then stably by duration
+// (slowest first) or outcome (crashed, failed, other, passed) if asked.
+func sortLsEntries(entries []rigdata.LsEntry, sortBy string) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Header.Timestamp.After(entries[j].Header.Timestamp)
+	})
+	switch sortBy {
+	case "duration":
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Header.DurationMs > entries[j].Header.DurationMs
+		})
+	case "outcome":
+		sort.SliceStable(entries, func(i, j int) bool {
+			return outcomeRank(entries[i].Header.Outcome) < outcomeRank(entries[j].Header.Outcome)
+		})
+	}
+}
+
+// outcomeRank orders outcomes for --sort outcome, worst first.
+func outcomeRank(outcome string) int {
+	switch outcome {
+	case "crashed":
+		return 0
+	case "failed":
+		return 1
+	case "passed":
+		return 3
+	}
+	return 2
+}
+
+// renderLsTSV writes one tab-separated line per log, without a header:
+// timestamp (RFC 3339, UTC), outcome, duration in milliseconds, name,
+// comma-separated services and path. Tabs and newlines in names are
+// replaced with spaces so each log stays on one line.
+func renderLsTSV(w io.Writer, entries []rigdata.LsEntry) {
+	clean := strings.NewReplacer("\t", " ", "\n", " ")
+	for _, e := range entries {
+		outcome := e.Header.Outcome
+		if outcome == "" {
+			outcome = "unknown"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Header.Timestamp.UTC().Format(time.RFC3339),
+			outcome,
+			strconv.FormatFloat(e.Header.DurationMs, 'f', -1, 64),
+			clean.Replace(e.Header.Environment),
+			clean.Replace(strings.Join(e.Header.Services, ",")),
+			e.Path,
+		)
+	}
+}
+
+// lsRecord is one line of `rig ls --format json`.
+type lsRecord struct {
+	Timestamp       time.Time          `json:"timestamp"`
+	Outcome         string             `json:"outcome"`
+	DurationMs      float64            `json:"duration_ms"`
+	Name            string             `json:"name"`
+	Services        []string           `json:"services"`
+	Path            string             `json:"path"`
+	ArtifactRetries int                `json:"artifact_retries,omitempty"`
+	ExitCodes       map[string]int     `json:"exit_codes,omitempty"`
+	ReadyDurations  map[string]float64 `json:"ready_durations_ms,omitempty"`
+	Metadata        map[string]string  `json:"metadata,omitempty"`
+}
+
+// renderLsJSON writes one JSON object per log, one per line.
+func renderLsJSON(w io.Writer, entries []rigdata.LsEntry) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		services := e.Header.Services
+		if services == nil {
+			services = []string{}
+		}
+		err := enc.Encode(lsRecord{
+			Timestamp:       e.Header.Timestamp,
+			Outcome:         e.Header.Outcome,
+			DurationMs:      e.Header.DurationMs,
+			Name:            e.Header.Environment,
+			Services:        services,
+			Path:            e.Path,
+			ArtifactRetries: e.Header.ArtifactRetries,
+			ExitCodes:       e.Header.ExitCodes,
+			ReadyDurations:  e.Header.ReadyDurations,
+			Metadata:        e.Header.Metadata,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelFlags collects repeated --label key=value filters.
+type labelFlags map[string]string
+
+func (l *labelFlags) String() string { return formatLabels(*l) }
+
+func (l *labelFlags) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("want key=value, got %q", s)
+	}
+	if *l == nil {
+		*l = labelFlags{}
+	}
+	(*l)[k] = v
+	return nil
+}
+
+// match reports whether md carries every label.
+func (l labelFlags) match(md map[string]string) bool {
+	for k, v := range l {
+		if got, ok := md[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// formatLabels renders metadata as sorted "k=v" pairs.
+func formatLabels(md map[string]string) string {
+	pairs := make([]string, 0, len(md))
+	for k, v := range md {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// slowestReady returns the service that took longest to become ready, or
+// "" when fewer than two services have a ready time, since there is then
+// nothing to compare.
+func slowestReady(durations map[string]float64) string {
+	if len(durations) < 2 {
+		return ""
+	}
+	var slowest string
+	for name, ms := range durations {
+		if slowest == "" || ms > durations[slowest] || (ms == durations[slowest] && name < slowest) {
+			slowest = name
+		}
+	}
+	return slowest
+}
+
+// maxLabelsWidth caps the LABELS column so long metadata doesn't push the
+// services list off screen.
+const maxLabelsWidth = 40
+
 func renderLsTable(w io.Writer, entries []rigdata.LsEntry) {
-	// Column headers and widths.
-	headers := []string{"TIME", "OUTCOME", "NAME", "DURATION", "SERVICES"}
+	// Column headers and widths. LABELS only appears when some log has
+	// metadata.
+	headers := []string{"TIME", "OUTCOME", "NAME", "DURATION", "LABELS", "SERVICES"}
+	showLabels := false
+	for _, e := range entries {
+		if len(e.Header.Metadata) > 0 {
+			showLabels = true
+			break
+		}
+	}
 	widths := make([]int, len(headers))
 	for i, h := range headers {
 		widths[i] = len(h)
 	}
 
 	type row struct {
-		cols [5]string
+		cols [6]string
 	}
 	rows := make([]row, len(entries))
 	for i, e := range entries {
@@ -107,15 +288,36 @@ func renderLsTable(w io.Writer, entries []rigdata.LsEntry) {
 		if outcome == "" {
 			outcome = "unknown"
 		}
+		switch n := e.Header.ArtifactRetries; {
+		case n == 1:
+			outcome += " (1 retry)"
+		case n > 1:
+			outcome += fmt.Sprintf(" (%d retries)", n)
+		}
 		durStr := rigdata.FormatLsDuration(e.Header.DurationMs)
-		svcs := strings.Join(e.Header.Services, ", ")
+		slowest := slowestReady(e.Header.ReadyDurations)
+		svcs := make([]string, len(e.Header.Services))
+		for j, name := range e.Header.Services {
+			svcs[j] = name
+			if code, ok := e.Header.ExitCodes[name]; ok {
+				svcs[j] += fmt.Sprintf(" (exit %d)", code)
+			} else if name == slowest {
+				svcs[j] += " (ready " + rigdata.FormatLsDuration(e.Header.ReadyDurations[name]) + ")"
+			}
+		}
+
+		labels := formatLabels(e.Header.Metadata)
+		if len(labels) > maxLabelsWidth {
+			labels = labels[:maxLabelsWidth-3] + "..."
+		}
 
-		rows[i] = row{cols: [5]string{
+		rows[i] = row{cols: [6]string{
 			timeStr,
 			outcome,
 			e.Header.Environment,
 			durStr,
-			svcs,
+			labels,
+			strings.Join(svcs, ", "),
 		}}
 		for j, c := range rows[i].cols {
 			if len(c) > widths[j] {
@@ -124,8 +326,13 @@ func renderLsTable(w io.Writer, entries []rigdata.LsEntry) {
 		}
 	}
 
+	const labelsCol = 4
+
 	// Print header.
 	for i, h := range headers {
+		if i == labelsCol && !showLabels {
+			continue
+		}
 		if i > 0 {
 			fmt.Fprint(w, "  ")
 		}
@@ -136,6 +343,9 @@ func renderLsTable(w io.Writer, entries []rigdata.LsEntry) {
 	// Print rows.
 	for _, r := range rows {
 		for i, c := range r.cols {
+			if i == labelsCol && !showLabels {
+				continue
+			}
 			if i > 0 {
 				fmt.Fprint(w, "  ")
 			}
@@ -154,8 +364,9 @@ func colorOutcome(s string) string {
 	if !colorEnabled {
 		return s
 	}
-	trimmed := strings.TrimSpace(s)
-	switch trimmed {
+	// Match on the first word so annotations like "(1 retry)" keep the color.
+	word, _, _ := strings.Cut(strings.TrimSpace(s), " ")
+	switch word {
 	case "passed":
 		return ansiGreen + s + ansiReset
 	case "failed", "crashed":
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -236,3 +238,158 @@ func captureStdout(t *testing.T, fn func()) string {
 	n, _ := r.Read(data)
 	return string(data[:n])
 }
+
+func TestRenderLsTableArtifactRetries(t *testing.T) {
+	var b strings.Builder
+	renderLsTable(&b, []rigdata.LsEntry{
+		{Header: rigdata.LsHeader{Environment: "TestPull", Outcome: "passed", ArtifactRetries: 2}},
+		{Header: rigdata.LsHeader{Environment: "TestQuiet", Outcome: "passed"}},
+	})
+	out := b.String()
+	if !strings.Contains(out, "passed (2 retries)") {
+		t.Errorf("output missing retry count:\n%s", out)
+	}
+	if strings.Count(out, "retr") != 1 {
+		t.Errorf("only TestPull should show retries:\n%s", out)
+	}
+}
+
+func TestRenderLsTableExitCodes(t *testing.T) {
+	var b strings.Builder
+	renderLsTable(&b, []rigdata.LsEntry{
+		{Header: rigdata.LsHeader{
+			Environment: "TestOOM",
+			Outcome:     "crashed",
+			Services:    []string{"api", "db"},
+			ExitCodes:   map[string]int{"api": 137},
+		}},
+	})
+	if out := b.String(); !strings.Contains(out, "api (exit 137), db") {
+		t.Errorf("output missing exit code:\n%s", out)
+	}
+}
+
+func TestRenderLsTableSlowestReady(t *testing.T) {
+	var b strings.Builder
+	renderLsTable(&b, []rigdata.LsEntry{
+		{Header: rigdata.LsHeader{
+			Environment:    "TestOrders",
+			Outcome:        "passed",
+			Services:       []string{"api", "db", "temporal"},
+			ReadyDurations: map[string]float64{"api": 310, "db": 2100, "temporal": 9120},
+		}},
+		{Header: rigdata.LsHeader{
+			Environment:    "TestSolo",
+			Outcome:        "passed",
+			Services:       []string{"api"},
+			ReadyDurations: map[string]float64{"api": 900},
+		}},
+	})
+	out := b.String()
+	if !strings.Contains(out, "api, db, temporal (ready 9.12s)") {
+		t.Errorf("output missing slowest service:\n%s", out)
+	}
+	if strings.Count(out, "(ready") != 1 {
+		t.Errorf("only environments with several services should mark the slowest:\n%s", out)
+	}
+}
+
+func TestRunLsLabel(t *testing.T) {
+	setupLsDir(t)
+	logDir := filepath.Join(os.Getenv("RIG_DIR"), "logs")
+	for _, f := range []struct{ name, metadata string }{
+		{"TestShard1-19480a00003-aaaa0001.jsonl", `{"pr":"1234","shard":"1"}`},
+		{"TestShard2-19480a00004-aaaa0002.jsonl", `{"pr":"1234","shard":"2"}`},
+	} {
+		hdr := `{"type":"log.header","environment":"` + strings.Split(f.name, "-")[0] + `","outcome":"passed","metadata":` + f.metadata + `,"timestamp":"2026-02-24T21:00:00Z"}` + "\n"
+		if err := os.WriteFile(filepath.Join(logDir, f.name), []byte(hdr), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := captureStdout(t, func() {
+		if err := runLs([]string{"--label", "pr=1234", "--label", "shard=2"}); err != nil {
+			t.Fatalf("runLs --label: %v", err)
+		}
+	})
+	if !strings.Contains(output, "TestShard2") || strings.Contains(output, "TestShard1") {
+		t.Errorf("--label should match only TestShard2:\n%s", output)
+	}
+	if !strings.Contains(output, "LABELS") || !strings.Contains(output, "pr=1234,shard=2") {
+		t.Errorf("output missing labels column:\n%s", output)
+	}
+
+	if err := runLs([]string{"--label", "pr=9999"}); err != errNoResults {
+		t.Errorf("expected errNoResults for unmatched label, got: %v", err)
+	}
+	if err := runLs([]string{"--label", "pr"}); err == nil {
+		t.Error("expected error for --label without '='")
+	}
+}
+
+func TestRunLsFormatTSV(t *testing.T) {
+	setupLsDir(t)
+
+	output := captureStdout(t, func() {
+		if err := runLs([]string{"--format", "tsv", "--sort", "duration"}); err != nil {
+			t.Fatalf("runLs --format tsv: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d:\n%s", len(lines), output)
+	}
+	// Slowest first.
+	cols := strings.Split(lines[0], "\t")
+	want := []string{"2026-02-24T20:32:01Z", "failed", "3312", "TestOrderFlow", "db,temporal,api"}
+	if len(cols) != 6 || !slices.Equal(cols[:5], want) || !strings.HasSuffix(cols[5], ".jsonl") {
+		t.Errorf("first line = %q, want %q followed by the path", cols, want)
+	}
+	if !strings.HasPrefix(lines[2], "2026-02-24T20:33:00Z\tcrashed\t500\t") {
+		t.Errorf("last line = %q, want the 500ms crash", lines[2])
+	}
+}
+
+func TestRunLsFormatJSON(t *testing.T) {
+	setupLsDir(t)
+
+	output := captureStdout(t, func() {
+		if err := runLs([]string{"--format", "json", "--sort", "outcome"}); err != nil {
+			t.Fatalf("runLs --format json: %v", err)
+		}
+	})
+	var outcomes []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var rec lsRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		if rec.Name == "" || rec.Path == "" || rec.Timestamp.IsZero() {
+			t.Errorf("record missing fields: %+v", rec)
+		}
+		outcomes = append(outcomes, rec.Outcome)
+	}
+	if want := []string{"crashed", "failed", "passed"}; !slices.Equal(outcomes, want) {
+		t.Errorf("outcomes = %v, want %v", outcomes, want)
+	}
+}
+
+func TestRunLsInvalidFormat(t *testing.T) {
+	setupLsDir(t)
+	if err := runLs([]string{"--format", "csv"}); err == nil || !strings.Contains(err.Error(), "invalid --format") {
+		t.Errorf("err = %v, want invalid --format", err)
+	}
+	if err := runLs([]string{"--sort", "name"}); err == nil || !strings.Contains(err.Error(), "invalid --sort") {
+		t.Errorf("err = %v, want invalid --sort", err)
+	}
+}
+
+func TestRenderLsTableNoLabels(t *testing.T) {
+	var b strings.Builder
+	renderLsTable(&b, []rigdata.LsEntry{
+		{Header: rigdata.LsHeader{Environment: "TestPlain", Outcome: "passed"}},
+	})
+	if strings.Contains(b.String(), "LABELS") {
+		t.Errorf("LABELS column shown without metadata:\n%s", b.String())
+	}
+}
@@ -17,6 +17,11 @@ func main() {
 			fmt.Fprintf(os.Stderr, "rig traffic: %v\n", err)
 			os.Exit(1)
 		}
+	case "stats":
+		if err := runStats(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "rig stats: %v\n", err)
+			os.Exit(1)
+		}
 	case "logs":
 		if err := runLogs(os.Args[2:]); err != nil {
 			fmt.Fprintf(os.Stderr, "rig logs: %v\n", err)
@@ -49,16 +54,46 @@ func main() {
 			fmt.Fprintf(os.Stderr, "rig ps: %v\n", err)
 			os.Exit(1)
 		}
+	case "watch":
+		if err := runWatch(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "rig watch: %v\n", err)
+			os.Exit(1)
+		}
 	case "down":
 		if err := runDown(os.Args[2:]); err != nil {
 			fmt.Fprintf(os.Stderr, "rig down: %v\n", err)
 			os.Exit(1)
 		}
+	case "diff":
+		if err := runDiff(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "rig diff: %v\n", err)
+			os.Exit(1)
+		}
+	case "export":
+		if err := runExport(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "rig export: %v\n", err)
+			os.Exit(1)
+		}
+	case "graph":
+		if err := runGraph(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "rig graph: %v\n", err)
+			os.Exit(1)
+		}
+	case "init":
+		if err := runInit(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "rig init: %v\n", err)
+			os.Exit(1)
+		}
 	case "prune":
 		if err := runPrune(os.Args[2:]); err != nil {
 			fmt.Fprintf(os.Stderr, "rig prune: %v\n", err)
 			os.Exit(1)
 		}
+	case "cache":
+		if err := runCache(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "rig cache: %v\n", err)
+			os.Exit(1)
+		}
 	case "help", "-h", "--help":
 		printUsage()
 	default:
@@ -73,14 +108,21 @@ func printUsage() {
 
 Commands:
   ps                     List active environments on rigd
+  watch   <env>          Stream an active environment's events live
   down    <env>          Tear down an active environment
   traffic <file>         Inspect traffic captured by rigd
-  logs    <file>         View service logs
+  stats   <file>         Latency percentiles per edge
+  logs    <file|env>     View service logs (--follow for an active environment)
   ls      [pattern]      List recent log files
   explain <file>         Analyze failure from event log
   summary [pattern]      Summarize local test results
+  diff    <test>         Compare a test's last passing and failing runs
   ci      [target]       Analyze CI run artifacts (requires gh CLI)
+  export  <file>         Export captured traffic as OTLP spans
+  graph   <spec|env>     Print the service topology as Graphviz DOT
+  init    [dir]          Scaffold a rig test and sample service
   prune                  Prune stale cache entries and logs
+  cache   <ls|prune>     Inspect the artifact cache or evict by age and size
 
 Run 'rig <command> --help' for command-specific flags.
 `)
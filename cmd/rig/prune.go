@@ -33,9 +33,9 @@ func runPrune(args []string) error {
 		return err
 	}
 
-	maxAge, err := time.ParseDuration(maxAgeStr)
+	maxAge, err := parseAge(maxAgeStr)
 	if err != nil {
-		return fmt.Errorf("invalid duration %q: %w", maxAgeStr, err)
+		return err
 	}
 
 	cutoff := time.Now().Add(-maxAge)
@@ -49,7 +49,7 @@ func runPrune(args []string) error {
 	var totalBytes int64
 
 	if doCache {
-		n, b, err := pruneCache(filepath.Join(rigdata.DefaultRigDir(), "cache"), cutoff, dryRun)
+		n, b, err := pruneCache(cacheDir(), time.Now(), maxAge, -1, dryRun)
 		if err != nil {
 			return err
 		}
@@ -81,90 +81,6 @@ func runPrune(args []string) error {
 	return nil
 }
 
-func pruneCache(cacheDir string, cutoff time.Time, dryRun bool) (int, int64, error) {
-	typeDirs, err := os.ReadDir(cacheDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return 0, 0, nil
-		}
-		return 0, 0, fmt.Errorf("reading cache dir: %w", err)
-	}
-
-	var totalPruned int
-	var totalBytes int64
-
-	for _, td := range typeDirs {
-		if !td.IsDir() {
-			continue
-		}
-		typeDir := filepath.Join(cacheDir, td.Name())
-
-		entries, err := os.ReadDir(typeDir)
-		if err != nil {
-			continue
-		}
-
-		// Track which hash dirs exist (for orphaned lock cleanup).
-		hashDirs := make(map[string]bool)
-		// Track which hash dirs were removed.
-		removedDirs := make(map[string]bool)
-
-		// First pass: identify and remove stale hash dirs.
-		for _, e := range entries {
-			if !e.IsDir() {
-				continue
-			}
-			hashDirs[e.Name()] = true
-
-			lastUsed := filepath.Join(typeDir, e.Name(), ".last-used")
-			info, err := os.Stat(lastUsed)
-
-			stale := err != nil || info.ModTime().Before(cutoff)
-			if !stale {
-				continue
-			}
-
-			entryDir := filepath.Join(typeDir, e.Name())
-			size := dirSize(entryDir)
-
-			if dryRun {
-				age := formatAge(info, err)
-				fmt.Printf("would remove cache/%s/%s (last used %s, %s)\n",
-					td.Name(), e.Name(), age, rigdata.FormatBytes(size))
-			} else {
-				if err := os.RemoveAll(entryDir); err != nil {
-					fmt.Fprintf(os.Stderr, "warning: failed to remove %s/%s: %v\n", td.Name(), e.Name(), err)
-					continue
-				}
-			}
-			removedDirs[e.Name()] = true
-			totalPruned++
-			totalBytes += size
-		}
-
-		// Second pass: clean orphaned .lock files.
-		for _, e := range entries {
-			if e.IsDir() {
-				continue
-			}
-			name := e.Name()
-			if !strings.HasSuffix(name, ".lock") {
-				continue
-			}
-			hashName := strings.TrimSuffix(name, ".lock")
-			if hashDirs[hashName] && !removedDirs[hashName] {
-				continue // hash dir still exists and wasn't removed
-			}
-			lockPath := filepath.Join(typeDir, name)
-			if !dryRun {
-				os.Remove(lockPath)
-			}
-		}
-	}
-
-	return totalPruned, totalBytes, nil
-}
-
 func pruneLogs(dir string, cutoff time.Time, dryRun bool) (int, int64, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -220,6 +136,22 @@ func pruneLogs(dir string, cutoff time.Time, dryRun bool) (int, int64, error) {
 			}
 			totalBytes += ci.Size()
 		}
+
+		// Remove per-service logs written with split_logs.
+		base := strings.TrimSuffix(e.Name(), ".jsonl")
+		split, _ := filepath.Glob(filepath.Join(dir, base+"-*.std*.log"))
+		for _, p := range split {
+			si, err := os.Stat(p)
+			if err != nil {
+				continue
+			}
+			if dryRun {
+				fmt.Printf("would remove logs/%s (%s)\n", filepath.Base(p), rigdata.FormatBytes(si.Size()))
+			} else {
+				os.Remove(p)
+			}
+			totalBytes += si.Size()
+		}
 	}
 
 	return totalPruned, totalBytes, nil
@@ -233,10 +165,12 @@ Remove stale cache entries and log files.
 Flags:
   -l                       Logs only
   -c                       Cache only
-  -m, --max-age <duration> Max age for entries (default: 24h)
+  -m, --max-age <age>      Max age for entries, e.g. 7d, 12h (default: 24h)
   -n, --dry-run            Print what would be removed without deleting
 
 By default both cache and logs are pruned. Use -l or -c to limit scope.
+Cache entries rigd is resolving are skipped; rig cache prune --older-than
+evicts the same way and can also cap the cache size.
 `)
 }
 
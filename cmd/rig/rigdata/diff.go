@@ -0,0 +1,170 @@
+package rigdata
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// maxNewLines caps the new log lines reported per service.
+const maxNewLines = 20
+
+// DiffRun is one side of a diff: a log file's header plus its parsed
+// traffic and log events.
+type DiffRun struct {
+	Path    string
+	Header  LsHeader
+	Traffic []Event
+	Logs    []LogEvent
+}
+
+// DiffReport compares two runs. Slices are always non-nil so the JSON
+// schema is the same whether or not anything differs.
+type DiffReport struct {
+	Baseline        DiffSide   `json:"baseline"`
+	Current         DiffSide   `json:"current"`
+	AddedServices   []string   `json:"added_services"`   // only in current
+	RemovedServices []string   `json:"removed_services"` // only in baseline
+	Edges           []EdgeDiff `json:"edges"`
+	Logs            []LogDiff  `json:"logs"`
+}
+
+// DiffSide identifies one of the compared runs.
+type DiffSide struct {
+	Path        string  `json:"path"`
+	Environment string  `json:"environment"`
+	Outcome     string  `json:"outcome"`
+	DurationMs  float64 `json:"duration_ms"`
+}
+
+// EdgeDiff pairs the stats of one source → target edge across both runs.
+// Baseline or Current is nil when the edge only carried traffic in the
+// other run.
+type EdgeDiff struct {
+	Source   string     `json:"source"`
+	Target   string     `json:"target"`
+	Baseline *EdgeStats `json:"baseline"`
+	Current  *EdgeStats `json:"current"`
+}
+
+// LogDiff summarizes how one service's output changed. NewLines holds up
+// to 20 distinct lines from the current run that have no counterpart in
+// the baseline; numbers are ignored when matching, so ports, IDs, and
+// durations don't register as changes. NewLinesTotal counts all of them.
+type LogDiff struct {
+	Service       string   `json:"service"`
+	BaselineLines int      `json:"baseline_lines"`
+	CurrentLines  int      `json:"current_lines"`
+	NewLines      []string `json:"new_lines"`
+	NewLinesTotal int      `json:"new_lines_total"`
+}
+
+// ComputeDiff compares baseline and current. Edges are sorted by source,
+// then target; logs cover services present in both runs, sorted by name.
+func ComputeDiff(baseline, current DiffRun) DiffReport {
+	report := DiffReport{
+		Baseline:        diffSide(baseline),
+		Current:         diffSide(current),
+		AddedServices:   []string{},
+		RemovedServices: []string{},
+		Edges:           []EdgeDiff{},
+		Logs:            []LogDiff{},
+	}
+
+	var shared []string
+	for _, s := range current.Header.Services {
+		if slices.Contains(baseline.Header.Services, s) {
+			shared = append(shared, s)
+		} else {
+			report.AddedServices = append(report.AddedServices, s)
+		}
+	}
+	for _, s := range baseline.Header.Services {
+		if !slices.Contains(current.Header.Services, s) {
+			report.RemovedServices = append(report.RemovedServices, s)
+		}
+	}
+	sort.Strings(report.AddedServices)
+	sort.Strings(report.RemovedServices)
+	sort.Strings(shared)
+
+	type edge struct{ source, target string }
+	edges := map[edge]*EdgeDiff{}
+	get := func(s EdgeStats) *EdgeDiff {
+		k := edge{s.Source, s.Target}
+		if edges[k] == nil {
+			edges[k] = &EdgeDiff{Source: s.Source, Target: s.Target}
+		}
+		return edges[k]
+	}
+	for _, s := range ComputeStats(baseline.Traffic) {
+		get(s).Baseline = &s
+	}
+	for _, s := range ComputeStats(current.Traffic) {
+		get(s).Current = &s
+	}
+	for _, e := range edges {
+		report.Edges = append(report.Edges, *e)
+	}
+	sort.Slice(report.Edges, func(i, j int) bool {
+		a, b := report.Edges[i], report.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+
+	baseLines := serviceLines(baseline.Logs)
+	curLines := serviceLines(current.Logs)
+	for _, svc := range shared {
+		report.Logs = append(report.Logs, diffLines(svc, baseLines[svc], curLines[svc]))
+	}
+	return report
+}
+
+func diffSide(r DiffRun) DiffSide {
+	return DiffSide{
+		Path:        r.Path,
+		Environment: r.Header.Environment,
+		Outcome:     r.Header.Outcome,
+		DurationMs:  r.Header.DurationMs,
+	}
+}
+
+// serviceLines groups service.log lines by service.
+func serviceLines(events []LogEvent) map[string][]string {
+	lines := map[string][]string{}
+	for _, ev := range events {
+		if ev.Type == TypeServiceLog && ev.Log != nil {
+			lines[ev.Service] = append(lines[ev.Service], ev.Log.Data)
+		}
+	}
+	return lines
+}
+
+var digitsRe = regexp.MustCompile(`[0-9]+`)
+
+func diffLines(service string, baseline, current []string) LogDiff {
+	d := LogDiff{
+		Service:       service,
+		BaselineLines: len(baseline),
+		CurrentLines:  len(current),
+		NewLines:      []string{},
+	}
+	seen := make(map[string]bool, len(baseline))
+	for _, l := range baseline {
+		seen[digitsRe.ReplaceAllString(l, "#")] = true
+	}
+	for _, l := range current {
+		key := digitsRe.ReplaceAllString(l, "#")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		d.NewLinesTotal++
+		if len(d.NewLines) < maxNewLines {
+			d.NewLines = append(d.NewLines, l)
+		}
+	}
+	return d
+}
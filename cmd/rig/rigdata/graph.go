@@ -0,0 +1,126 @@
+package rigdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// Graph is the service topology of an environment, as served by
+// GET /environments/{id}/graph.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a service in the graph.
+type GraphNode struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// GraphEdge is an egress from one service to another's ingress.
+type GraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Egress   string `json:"egress"`
+	Ingress  string `json:"ingress"`
+	Protocol string `json:"protocol,omitempty"`
+
+	// External is the URL of an egress to an address outside the
+	// environment. To and Ingress are empty for such edges.
+	External string `json:"external,omitempty"`
+}
+
+// FetchGraph fetches the topology of an active environment.
+func FetchGraph(addr, id string) (*Graph, error) {
+	resp, err := http.Get(addr + "/environments/" + id + "/graph")
+	if err != nil {
+		return nil, fmt.Errorf("connect to rigd: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("rigd returned %d: %s", resp.StatusCode, body)
+	}
+	var g Graph
+	if err := json.NewDecoder(resp.Body).Decode(&g); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &g, nil
+}
+
+// specEnvironment is the subset of the environment spec needed to build a
+// graph.
+type specEnvironment struct {
+	Services map[string]struct {
+		Type      string `json:"type"`
+		Ingresses map[string]struct {
+			Protocol string `json:"protocol"`
+		} `json:"ingresses"`
+		Egresses map[string]struct {
+			Service  string `json:"service"`
+			Ingress  string `json:"ingress"`
+			External string `json:"external"`
+		} `json:"egresses"`
+	} `json:"services"`
+}
+
+// GraphFromSpec builds the topology of an environment spec (the JSON an SDK
+// posts to rigd). An egress without an ingress targets the service's only
+// ingress, or "default", as the server resolves it.
+//
+// It mirrors server.BuildGraph applied after server.ResolveDefaults, which
+// this module can't call until its internal pin includes them. The two are
+// kept in step by parity tests over internal/testdata/graph.
+func GraphFromSpec(r io.Reader) (*Graph, error) {
+	var env specEnvironment
+	if err := json.NewDecoder(r).Decode(&env); err != nil {
+		return nil, fmt.Errorf("decode spec: %w", err)
+	}
+
+	names := make([]string, 0, len(env.Services))
+	for name := range env.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	g := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	for _, name := range names {
+		svc := env.Services[name]
+		g.Nodes = append(g.Nodes, GraphNode{Name: name, Type: svc.Type})
+
+		egressNames := make([]string, 0, len(svc.Egresses))
+		for egName := range svc.Egresses {
+			egressNames = append(egressNames, egName)
+		}
+		sort.Strings(egressNames)
+		for _, egName := range egressNames {
+			eg := svc.Egresses[egName]
+			edge := GraphEdge{From: name, To: eg.Service, Egress: egName, Ingress: eg.Ingress}
+			if eg.External != "" {
+				edge.External = eg.External
+				edge.Protocol = "http"
+				g.Edges = append(g.Edges, edge)
+				continue
+			}
+			target, ok := env.Services[eg.Service]
+			if ok && edge.Ingress == "" {
+				if len(target.Ingresses) == 1 {
+					for ingName := range target.Ingresses {
+						edge.Ingress = ingName
+					}
+				} else if _, hasDefault := target.Ingresses["default"]; hasDefault {
+					edge.Ingress = "default"
+				}
+			}
+			if ok {
+				edge.Protocol = target.Ingresses[edge.Ingress].Protocol
+			}
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+	return g, nil
+}
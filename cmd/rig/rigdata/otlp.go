@@ -0,0 +1,249 @@
+package rigdata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTLP/JSON trace payload types. Only the subset of the OpenTelemetry
+// protocol that rig populates is modelled; field names follow the proto3
+// JSON mapping expected by collectors on /v1/traces.
+
+// OTLPTraces is the body of an OTLP/HTTP ExportTraceServiceRequest.
+type OTLPTraces struct {
+	ResourceSpans []OTLPResourceSpans `json:"resourceSpans"`
+}
+
+// OTLPResourceSpans groups spans emitted by a single service.
+type OTLPResourceSpans struct {
+	Resource   OTLPResource     `json:"resource"`
+	ScopeSpans []OTLPScopeSpans `json:"scopeSpans"`
+}
+
+// OTLPResource identifies the service that produced a set of spans.
+type OTLPResource struct {
+	Attributes []OTLPKeyValue `json:"attributes"`
+}
+
+// OTLPScopeSpans groups spans by instrumentation scope.
+type OTLPScopeSpans struct {
+	Scope OTLPScope  `json:"scope"`
+	Spans []OTLPSpan `json:"spans"`
+}
+
+// OTLPScope names the instrumentation that produced the spans.
+type OTLPScope struct {
+	Name string `json:"name"`
+}
+
+// OTLPSpan is a single client span derived from a captured traffic event.
+type OTLPSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []OTLPKeyValue `json:"attributes,omitempty"`
+	Status            OTLPStatus     `json:"status"`
+}
+
+// OTLPStatus is a span's completion status.
+type OTLPStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// OTLPKeyValue is a span or resource attribute.
+type OTLPKeyValue struct {
+	Key   string    `json:"key"`
+	Value OTLPValue `json:"value"`
+}
+
+// OTLPValue holds a single attribute value. Exactly one field is set.
+type OTLPValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"` // int64 is a JSON string in proto3
+}
+
+// OTLP span kinds and status codes used by rig.
+const (
+	otlpSpanKindClient = 3
+	otlpStatusError    = 2
+	otlpScopeName      = "github.com/matgreaves/rig"
+	traceparentHeader  = "traceparent"
+)
+
+// BuildOTLP converts traffic events into OTLP spans, one client span per
+// event, grouped by source service. When a request carried a W3C
+// traceparent header its trace ID is reused and the caller's span becomes
+// the parent, so calls made while handling an inbound request nest under
+// it. Otherwise the IDs rig's proxies threaded through X-Rig-Trace are
+// used, and events without either get their own trace. Synthesized IDs are
+// derived from salt and the event sequence number, so exporting the same
+// log twice yields the same spans.
+func BuildOTLP(events []Event, salt string) OTLPTraces {
+	bySource := map[string][]OTLPSpan{}
+	for _, ev := range events {
+		span, source, ok := eventSpan(ev, salt)
+		if !ok {
+			continue
+		}
+		bySource[source] = append(bySource[source], span)
+	}
+
+	sources := make([]string, 0, len(bySource))
+	for s := range bySource {
+		sources = append(sources, s)
+	}
+	sort.Strings(sources)
+
+	out := OTLPTraces{ResourceSpans: []OTLPResourceSpans{}}
+	for _, s := range sources {
+		out.ResourceSpans = append(out.ResourceSpans, OTLPResourceSpans{
+			Resource: OTLPResource{Attributes: []OTLPKeyValue{strAttr("service.name", s)}},
+			ScopeSpans: []OTLPScopeSpans{{
+				Scope: OTLPScope{Name: otlpScopeName},
+				Spans: bySource[s],
+			}},
+		})
+	}
+	return out
+}
+
+func eventSpan(ev Event, salt string) (OTLPSpan, string, bool) {
+	var (
+		source, target string
+		latencyMs      float64
+		headers        map[string][]string
+		span           OTLPSpan
+	)
+	switch ev.Type {
+	case TypeRequestCompleted:
+		r := ev.Request
+		source, target, latencyMs, headers = r.Source, r.Target, r.LatencyMs, r.RequestHeaders
+		span.Name = r.Method + " " + r.Path
+		span.Attributes = []OTLPKeyValue{
+			strAttr("http.request.method", r.Method),
+			strAttr("url.path", r.Path),
+			intAttr("http.response.status_code", int64(r.StatusCode)),
+		}
+		if r.StatusCode >= 500 {
+			span.Status = OTLPStatus{Code: otlpStatusError}
+		}
+	case TypeGRPCCallCompleted:
+		g := ev.GRPCCall
+		source, target, latencyMs, headers = g.Source, g.Target, g.LatencyMs, g.RequestMetadata
+		span.Name = g.Service + "/" + g.Method
+		span.Attributes = []OTLPKeyValue{
+			strAttr("rpc.system", "grpc"),
+			strAttr("rpc.service", g.Service),
+			strAttr("rpc.method", g.Method),
+			strAttr("rpc.grpc.status", g.GRPCStatus),
+		}
+		if g.GRPCStatus != "" && g.GRPCStatus != "OK" && g.GRPCStatus != "0" {
+			span.Status = OTLPStatus{Code: otlpStatusError, Message: g.GRPCMessage}
+		}
+	case TypeConnectionClosed:
+		c := ev.Connection
+		source, target, latencyMs = c.Source, c.Target, c.DurationMs
+		span.Name = "TCP " + c.Target
+		span.Attributes = []OTLPKeyValue{
+			intAttr("rig.bytes_in", c.BytesIn),
+			intAttr("rig.bytes_out", c.BytesOut),
+		}
+	case TypeKafkaRequestCompleted:
+		k := ev.KafkaRequest
+		source, target, latencyMs = k.Source, k.Target, k.LatencyMs
+		span.Name = "kafka " + k.APIName
+		span.Attributes = []OTLPKeyValue{
+			strAttr("messaging.system", "kafka"),
+			intAttr("rig.kafka.api_version", int64(k.APIVersion)),
+		}
+	case TypeRedisCommandCompleted:
+		c := ev.RedisCommand
+		source, target, latencyMs = c.Source, c.Target, c.LatencyMs
+		span.Name = c.Command
+		span.Attributes = []OTLPKeyValue{
+			strAttr("db.system", "redis"),
+			strAttr("db.operation", c.Command),
+		}
+		if c.RedisError != "" {
+			span.Status = OTLPStatus{Code: otlpStatusError, Message: c.RedisError}
+		}
+	default:
+		return OTLPSpan{}, "", false
+	}
+
+	span.Kind = otlpSpanKindClient
+	span.Attributes = append(span.Attributes,
+		strAttr("rig.source", source),
+		strAttr("rig.target", target),
+	)
+
+	end := ev.Timestamp
+	start := end.Add(-time.Duration(latencyMs * float64(time.Millisecond)))
+	span.StartTimeUnixNano = strconv.FormatInt(start.UnixNano(), 10)
+	span.EndTimeUnixNano = strconv.FormatInt(end.UnixNano(), 10)
+
+	key := fmt.Sprintf("%s/%d", salt, ev.Seq)
+	span.SpanID = hashID(key+"/span", 8)
+	if traceID, parentID, ok := parseTraceparent(headers); ok {
+		span.TraceID = traceID
+		span.ParentSpanID = parentID
+	} else if traceID, spanID, parentID := rigTrace(ev); traceID != "" {
+		span.TraceID = traceID
+		span.SpanID = spanID
+		span.ParentSpanID = parentID
+	} else {
+		span.TraceID = hashID(key+"/trace", 16)
+	}
+	return span, source, true
+}
+
+// parseTraceparent extracts the trace and parent span IDs from a W3C
+// traceparent header ("00-<trace-id>-<parent-id>-<flags>").
+func parseTraceparent(headers map[string][]string) (traceID, parentID string, ok bool) {
+	for k, v := range headers {
+		if !strings.EqualFold(k, traceparentHeader) || len(v) == 0 {
+			continue
+		}
+		parts := strings.Split(strings.TrimSpace(v[0]), "-")
+		if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+			return "", "", false
+		}
+		return parts[1], parts[2], true
+	}
+	return "", "", false
+}
+
+// rigTrace returns the X-Rig-Trace IDs recorded on an HTTP or gRPC event.
+func rigTrace(ev Event) (traceID, spanID, parentID string) {
+	switch {
+	case ev.Request != nil:
+		return ev.Request.TraceID, ev.Request.SpanID, ev.Request.ParentSpanID
+	case ev.GRPCCall != nil:
+		return ev.GRPCCall.TraceID, ev.GRPCCall.SpanID, ev.GRPCCall.ParentSpanID
+	}
+	return "", "", ""
+}
+
+func hashID(key string, n int) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:n])
+}
+
+func strAttr(key, v string) OTLPKeyValue {
+	return OTLPKeyValue{Key: key, Value: OTLPValue{StringValue: &v}}
+}
+
+func intAttr(key string, v int64) OTLPKeyValue {
+	s := strconv.FormatInt(v, 10)
+	return OTLPKeyValue{Key: key, Value: OTLPValue{IntValue: &s}}
+}
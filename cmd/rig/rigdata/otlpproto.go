@@ -0,0 +1,149 @@
+package rigdata
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// MarshalProto encodes t as a binary ExportTraceServiceRequest, the body
+// of an OTLP/gRPC Export call. Only the fields BuildOTLP populates are
+// written; field numbers follow opentelemetry/proto/trace/v1/trace.proto.
+func (t OTLPTraces) MarshalProto() ([]byte, error) {
+	var b []byte
+	for _, rs := range t.ResourceSpans {
+		m, err := rs.marshalProto()
+		if err != nil {
+			return nil, err
+		}
+		b = appendProtoMessage(b, 1, m) // resource_spans
+	}
+	return b, nil
+}
+
+func (rs OTLPResourceSpans) marshalProto() ([]byte, error) {
+	var res []byte
+	for _, kv := range rs.Resource.Attributes {
+		m, err := kv.marshalProto()
+		if err != nil {
+			return nil, err
+		}
+		res = appendProtoMessage(res, 1, m) // attributes
+	}
+	b := appendProtoMessage(nil, 1, res) // resource
+
+	for _, ss := range rs.ScopeSpans {
+		scope := appendProtoString(nil, 1, ss.Scope.Name) // name
+		m := appendProtoMessage(nil, 1, scope)            // scope
+		for _, span := range ss.Spans {
+			sm, err := span.marshalProto()
+			if err != nil {
+				return nil, err
+			}
+			m = appendProtoMessage(m, 2, sm) // spans
+		}
+		b = appendProtoMessage(b, 2, m) // scope_spans
+	}
+	return b, nil
+}
+
+func (s OTLPSpan) marshalProto() ([]byte, error) {
+	traceID, err := hex.DecodeString(s.TraceID)
+	if err != nil {
+		return nil, fmt.Errorf("span %q: trace ID: %w", s.Name, err)
+	}
+	spanID, err := hex.DecodeString(s.SpanID)
+	if err != nil {
+		return nil, fmt.Errorf("span %q: span ID: %w", s.Name, err)
+	}
+	parentID, err := hex.DecodeString(s.ParentSpanID)
+	if err != nil {
+		return nil, fmt.Errorf("span %q: parent span ID: %w", s.Name, err)
+	}
+	start, err := strconv.ParseUint(s.StartTimeUnixNano, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("span %q: start time: %w", s.Name, err)
+	}
+	end, err := strconv.ParseUint(s.EndTimeUnixNano, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("span %q: end time: %w", s.Name, err)
+	}
+
+	b := appendProtoBytes(nil, 1, traceID)      // trace_id
+	b = appendProtoBytes(b, 2, spanID)          // span_id
+	b = appendProtoBytes(b, 4, parentID)        // parent_span_id
+	b = appendProtoString(b, 5, s.Name)         // name
+	b = appendProtoVarint(b, 6, uint64(s.Kind)) // kind
+	b = appendProtoFixed64(b, 7, start)         // start_time_unix_nano
+	b = appendProtoFixed64(b, 8, end)           // end_time_unix_nano
+	for _, kv := range s.Attributes {
+		m, err := kv.marshalProto()
+		if err != nil {
+			return nil, fmt.Errorf("span %q: %w", s.Name, err)
+		}
+		b = appendProtoMessage(b, 9, m) // attributes
+	}
+
+	status := appendProtoString(nil, 2, s.Status.Message)        // message
+	status = appendProtoVarint(status, 3, uint64(s.Status.Code)) // code
+	return appendProtoMessage(b, 15, status), nil                // status
+}
+
+func (kv OTLPKeyValue) marshalProto() ([]byte, error) {
+	var v []byte
+	switch {
+	case kv.Value.StringValue != nil:
+		// Written even when empty so the oneof is still set.
+		v = appendProtoMessage(nil, 1, []byte(*kv.Value.StringValue)) // string_value
+	case kv.Value.IntValue != nil:
+		n, err := strconv.ParseInt(*kv.Value.IntValue, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", kv.Key, err)
+		}
+		v = appendProtoTag(nil, 3, 0) // int_value
+		v = binary.AppendUvarint(v, uint64(n))
+	}
+	b := appendProtoString(nil, 1, kv.Key)  // key
+	return appendProtoMessage(b, 2, v), nil // value
+}
+
+// Protobuf wire encoding. Scalars equal to their zero value are omitted,
+// as proto3 does; messages are always written so presence is kept.
+
+func appendProtoTag(b []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, 0)
+	return binary.AppendUvarint(b, v)
+}
+
+func appendProtoFixed64(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, 1)
+	return binary.LittleEndian.AppendUint64(b, v)
+}
+
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendProtoMessage(b, field, v)
+}
+
+func appendProtoString(b []byte, field int, v string) []byte {
+	return appendProtoBytes(b, field, []byte(v))
+}
+
+func appendProtoMessage(b []byte, field int, m []byte) []byte {
+	b = appendProtoTag(b, field, 2)
+	b = binary.AppendUvarint(b, uint64(len(m)))
+	return append(b, m...)
+}
@@ -26,7 +26,7 @@ func ParseTrafficEvents(r io.Reader) ([]Event, error) {
 			return nil, fmt.Errorf("line %d: %w", lineNo, err)
 		}
 		switch ev.Type {
-		case TypeRequestCompleted, TypeConnectionClosed, TypeGRPCCallCompleted, TypeKafkaRequestCompleted:
+		case TypeRequestCompleted, TypeRequestMocked, TypeConnectionClosed, TypeGRPCCallCompleted, TypeGRPCStreamClosed, TypeKafkaRequestCompleted, TypeRedisCommandCompleted, TypeNATSMessage, TypeMongoCommand, TypeWebSocketClosed:
 			events = append(events, ev)
 		}
 	}
@@ -43,12 +43,13 @@ func BuildRows(events []Event) []TrafficRow {
 	for i, ev := range events {
 		rel := ev.Timestamp.Sub(t0)
 		row := TrafficRow{
-			Index: i + 1,
-			Time:  FormatDuration(rel),
-			Event: ev,
+			Index:  i + 1,
+			Time:   FormatDuration(rel),
+			Offset: rel,
+			Event:  ev,
 		}
 		switch ev.Type {
-		case TypeRequestCompleted:
+		case TypeRequestCompleted, TypeRequestMocked:
 			r := ev.Request
 			row.Source = r.Source
 			row.Target = r.Target
@@ -57,6 +58,9 @@ func BuildRows(events []Event) []TrafficRow {
 			row.Path = r.Path
 			row.Status = strconv.Itoa(r.StatusCode)
 			row.Latency = FormatLatency(r.LatencyMs)
+			if ev.Type == TypeRequestMocked {
+				row.Extra = "mocked"
+			}
 		case TypeGRPCCallCompleted:
 			g := ev.GRPCCall
 			row.Source = g.Source
@@ -66,6 +70,16 @@ func BuildRows(events []Event) []TrafficRow {
 			row.Path = g.Service + "/" + g.Method
 			row.Status = g.GRPCStatus
 			row.Latency = FormatLatency(g.LatencyMs)
+		case TypeGRPCStreamClosed:
+			g := ev.GRPCStream
+			row.Source = g.Source
+			row.Target = g.Target
+			row.Protocol = "gRPC"
+			row.Method = "gRPC"
+			row.Path = g.Service + "/" + g.Method
+			row.Status = g.GRPCStatus
+			row.Latency = FormatLatency(g.DurationMs)
+			row.Extra = fmt.Sprintf("%d↑ %d↓ msgs", g.MessagesIn, g.MessagesOut)
 		case TypeConnectionClosed:
 			c := ev.Connection
 			row.Source = c.Source
@@ -86,6 +100,68 @@ func BuildRows(events []Event) []TrafficRow {
 			row.Status = "—"
 			row.Latency = FormatLatency(k.LatencyMs)
 			row.Extra = fmt.Sprintf("%s↑ %s↓", FormatBytes(k.RequestSize), FormatBytes(k.ResponseSize))
+		case TypeRedisCommandCompleted:
+			c := ev.RedisCommand
+			row.Source = c.Source
+			row.Target = c.Target
+			row.Protocol = "Redis"
+			row.Method = c.Command
+			row.Path = c.Key
+			if row.Path == "" {
+				row.Path = "—"
+			}
+			row.Status = "OK"
+			if c.RedisError != "" {
+				// Error replies start with a code such as ERR or WRONGTYPE.
+				row.Status, _, _ = strings.Cut(c.RedisError, " ")
+			}
+			row.Latency = FormatLatency(c.LatencyMs)
+			row.Extra = c.ReplyType
+		case TypeMongoCommand:
+			c := ev.MongoCommand
+			row.Source = c.Source
+			row.Target = c.Target
+			row.Protocol = "Mongo"
+			row.Method = c.Command
+			row.Path = c.Collection
+			if row.Path == "" {
+				row.Path = "—"
+			}
+			row.Status = "OK"
+			if c.MongoError != "" {
+				// Errors start with a code name such as DuplicateKey.
+				row.Status, _, _ = strings.Cut(c.MongoError, ":")
+			}
+			row.Latency = FormatLatency(c.LatencyMs)
+			row.Extra = fmt.Sprintf("%s↑ %s↓", FormatBytes(c.RequestSize), FormatBytes(c.ResponseSize))
+		case TypeNATSMessage:
+			m := ev.NATSMessage
+			row.Source = m.Source
+			row.Target = m.Target
+			row.Protocol = "NATS"
+			row.Method = m.Op
+			row.Path = m.Subject
+			row.Status = "—"
+			row.Latency = "—"
+			switch {
+			case m.Op == "SUB" && m.Queue != "":
+				row.Extra = "queue " + m.Queue
+			case m.Op != "SUB":
+				row.Extra = FormatBytes(m.PayloadSize)
+				if m.ReplyTo != "" {
+					row.Extra += " reply " + m.ReplyTo
+				}
+			}
+		case TypeWebSocketClosed:
+			ws := ev.WebSocket
+			row.Source = ws.Source
+			row.Target = ws.Target
+			row.Protocol = "WS"
+			row.Method = "WS"
+			row.Path = ws.Path
+			row.Status = "—"
+			row.Latency = FormatLatency(ws.DurationMs)
+			row.Extra = fmt.Sprintf("%d↑ %d↓ frames", ws.FramesIn, ws.FramesOut)
 		}
 		rows[i] = row
 	}
@@ -94,7 +170,7 @@ func BuildRows(events []Event) []TrafficRow {
 
 // ApplyFilter returns only rows matching all filter criteria.
 func ApplyFilter(rows []TrafficRow, f TrafficFilter) []TrafficRow {
-	if f.Edge == "" && f.SlowMs == 0 && f.Status == "" && f.Protocol == "" {
+	if f.Edge == "" && f.SlowMs == 0 && f.Status == "" && f.Protocol == "" && f.Label == "" && f.Trace == "" && !f.windowed() {
 		return rows
 	}
 	var out []TrafficRow
@@ -111,6 +187,15 @@ func ApplyFilter(rows []TrafficRow, f TrafficFilter) []TrafficRow {
 		if !matchProtocol(r, f.Protocol) {
 			continue
 		}
+		if !matchLabel(r, f.Label) {
+			continue
+		}
+		if !matchTrace(r, f.Trace) {
+			continue
+		}
+		if !matchWindow(r, f) {
+			continue
+		}
 		out = append(out, r)
 	}
 	return out
@@ -142,7 +227,7 @@ func matchSlow(r TrafficRow, thresholdMs float64) bool {
 	}
 	var latencyMs float64
 	switch r.Event.Type {
-	case TypeRequestCompleted:
+	case TypeRequestCompleted, TypeRequestMocked:
 		latencyMs = r.Event.Request.LatencyMs
 	case TypeGRPCCallCompleted:
 		latencyMs = r.Event.GRPCCall.LatencyMs
@@ -150,6 +235,10 @@ func matchSlow(r TrafficRow, thresholdMs float64) bool {
 		latencyMs = r.Event.Connection.DurationMs
 	case TypeKafkaRequestCompleted:
 		latencyMs = r.Event.KafkaRequest.LatencyMs
+	case TypeRedisCommandCompleted:
+		latencyMs = r.Event.RedisCommand.LatencyMs
+	case TypeWebSocketClosed:
+		latencyMs = r.Event.WebSocket.DurationMs
 	}
 	return latencyMs >= thresholdMs
 }
@@ -160,7 +249,7 @@ func matchStatus(r TrafficRow, status string) bool {
 	}
 	if len(status) == 3 && status[1] == 'x' && status[2] == 'x' {
 		classDigit := status[0]
-		if r.Event.Type == TypeRequestCompleted {
+		if r.Event.Request != nil {
 			actual := strconv.Itoa(r.Event.Request.StatusCode)
 			return len(actual) == 3 && actual[0] == classDigit
 		}
@@ -176,6 +265,42 @@ func matchProtocol(r TrafficRow, protocol string) bool {
 	return strings.EqualFold(r.Protocol, protocol)
 }
 
+func matchLabel(r TrafficRow, label string) bool {
+	if label == "" {
+		return true
+	}
+	return r.Event.Request != nil && r.Event.Request.Label == label
+}
+
+func matchTrace(r TrafficRow, trace string) bool {
+	if trace == "" {
+		return true
+	}
+	id, _, _ := rigTrace(r.Event)
+	return id != "" && strings.HasPrefix(id, strings.ToLower(trace))
+}
+
+func (f TrafficFilter) windowed() bool {
+	return f.Since != 0 || f.Until != 0 || !f.SinceTime.IsZero() || !f.UntilTime.IsZero()
+}
+
+func matchWindow(r TrafficRow, f TrafficFilter) bool {
+	if f.Since != 0 && r.Offset < f.Since {
+		return false
+	}
+	if f.Until != 0 && r.Offset > f.Until {
+		return false
+	}
+	ts := r.Event.Timestamp
+	if !f.SinceTime.IsZero() && ts.Before(f.SinceTime) {
+		return false
+	}
+	if !f.UntilTime.IsZero() && ts.After(f.UntilTime) {
+		return false
+	}
+	return true
+}
+
 // ParseLogEvents reads JSONL and returns only log-related events.
 func ParseLogEvents(r io.Reader) ([]LogEvent, error) {
 	var events []LogEvent
@@ -0,0 +1,97 @@
+package rigdata
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// EdgeStats aggregates the latency of HTTP requests and gRPC calls on one
+// source → target edge. Errors are HTTP 4xx/5xx responses and gRPC calls
+// with a non-OK status.
+type EdgeStats struct {
+	Source    string  `json:"source"`
+	Target    string  `json:"target"`
+	Count     int     `json:"count"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"` // Errors / Count, 0–1
+	P50Ms     float64 `json:"p50_ms"`
+	P90Ms     float64 `json:"p90_ms"`
+	P99Ms     float64 `json:"p99_ms"`
+	MaxMs     float64 `json:"max_ms"`
+}
+
+// ComputeStats groups request.completed and grpc.call.completed events by
+// edge and computes exact percentiles from each edge's full latency sample.
+// Edges are sorted by source, then target.
+func ComputeStats(events []Event) []EdgeStats {
+	type edge struct{ source, target string }
+	type sample struct {
+		latencies []float64
+		errors    int
+	}
+	samples := map[edge]*sample{}
+	add := func(source, target string, latencyMs float64, failed bool) {
+		k := edge{source, target}
+		s := samples[k]
+		if s == nil {
+			s = &sample{}
+			samples[k] = s
+		}
+		s.latencies = append(s.latencies, latencyMs)
+		if failed {
+			s.errors++
+		}
+	}
+
+	for _, ev := range events {
+		switch ev.Type {
+		case TypeRequestCompleted:
+			r := ev.Request
+			add(r.Source, r.Target, r.LatencyMs, r.StatusCode >= 400)
+		case TypeGRPCCallCompleted:
+			g := ev.GRPCCall
+			add(g.Source, g.Target, g.LatencyMs, !grpcOK(g.GRPCStatus))
+		}
+	}
+
+	stats := make([]EdgeStats, 0, len(samples))
+	for k, s := range samples {
+		sort.Float64s(s.latencies)
+		n := len(s.latencies)
+		stats = append(stats, EdgeStats{
+			Source:    k.source,
+			Target:    k.target,
+			Count:     n,
+			Errors:    s.errors,
+			ErrorRate: float64(s.errors) / float64(n),
+			P50Ms:     percentile(s.latencies, 50),
+			P90Ms:     percentile(s.latencies, 90),
+			P99Ms:     percentile(s.latencies, 99),
+			MaxMs:     s.latencies[n-1],
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Source != stats[j].Source {
+			return stats[i].Source < stats[j].Source
+		}
+		return stats[i].Target < stats[j].Target
+	})
+	return stats
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted, which must
+// be non-empty.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// grpcOK reports whether a gRPC status, by name or numeric code, is OK.
+func grpcOK(status string) bool {
+	if status == "OK" {
+		return true
+	}
+	code, err := strconv.Atoi(status)
+	return err == nil && code == 0
+}
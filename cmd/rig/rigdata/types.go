@@ -8,9 +8,15 @@ import (
 // Event type constants for traffic display.
 const (
 	TypeRequestCompleted      = "request.completed"
+	TypeRequestMocked         = "request.mocked"
 	TypeConnectionClosed      = "connection.closed"
 	TypeGRPCCallCompleted     = "grpc.call.completed"
+	TypeGRPCStreamClosed      = "grpc.stream.closed"
 	TypeKafkaRequestCompleted = "kafka.request.completed"
+	TypeRedisCommandCompleted = "redis.command.completed"
+	TypeNATSMessage           = "nats.message"
+	TypeMongoCommand          = "mongo.command"
+	TypeWebSocketClosed       = "websocket.closed"
 )
 
 // Event type constants for log display.
@@ -28,7 +34,12 @@ type Event struct {
 	Request      *RequestInfo      `json:"request,omitempty"`
 	Connection   *ConnectionInfo   `json:"connection,omitempty"`
 	GRPCCall     *GRPCCallInfo     `json:"grpc_call,omitempty"`
+	GRPCStream   *GRPCStreamInfo   `json:"grpc_stream,omitempty"`
 	KafkaRequest *KafkaRequestInfo `json:"kafka_request,omitempty"`
+	RedisCommand *RedisCommandInfo `json:"redis_command,omitempty"`
+	NATSMessage  *NATSMessageInfo  `json:"nats_message,omitempty"`
+	MongoCommand *MongoCommandInfo `json:"mongo_command,omitempty"`
+	WebSocket    *WebSocketInfo    `json:"websocket,omitempty"`
 }
 
 // RequestInfo holds HTTP request/response metadata.
@@ -48,16 +59,28 @@ type RequestInfo struct {
 	ResponseHeaders       map[string][]string `json:"response_headers,omitempty"`
 	ResponseBody          []byte              `json:"response_body,omitempty"`
 	ResponseBodyTruncated bool                `json:"response_body_truncated,omitempty"`
+	ProxyInjected         bool                `json:"proxy_injected,omitempty"`
+	ProxyOverheadMs       float64             `json:"proxy_overhead_ms,omitempty"`
+	Label                 string              `json:"label,omitempty"`
+	TraceID               string              `json:"trace_id,omitempty"`
+	SpanID                string              `json:"span_id,omitempty"`
+	ParentSpanID          string              `json:"parent_span_id,omitempty"`
 }
 
 // ConnectionInfo holds TCP connection metadata.
 type ConnectionInfo struct {
-	Source     string  `json:"source"`
-	Target     string  `json:"target"`
-	Ingress    string  `json:"ingress"`
-	BytesIn    int64   `json:"bytes_in"`
-	BytesOut   int64   `json:"bytes_out"`
-	DurationMs float64 `json:"duration_ms"`
+	Source      string  `json:"source"`
+	Target      string  `json:"target"`
+	Ingress     string  `json:"ingress"`
+	BytesIn     int64   `json:"bytes_in"`
+	BytesOut    int64   `json:"bytes_out"`
+	DurationMs  float64 `json:"duration_ms"`
+	CloseReason string  `json:"close_reason,omitempty"`
+
+	PreviewIn           []byte `json:"preview_in,omitempty"`
+	PreviewInTruncated  bool   `json:"preview_in_truncated,omitempty"`
+	PreviewOut          []byte `json:"preview_out,omitempty"`
+	PreviewOutTruncated bool   `json:"preview_out_truncated,omitempty"`
 }
 
 // GRPCCallInfo holds gRPC call metadata.
@@ -80,6 +103,31 @@ type GRPCCallInfo struct {
 	ResponseBodyTruncated bool                `json:"response_body_truncated,omitempty"`
 	RequestBodyDecoded    json.RawMessage     `json:"request_body_decoded,omitempty"`
 	ResponseBodyDecoded   json.RawMessage     `json:"response_body_decoded,omitempty"`
+	ProxyInjected         bool                `json:"proxy_injected,omitempty"`
+	TraceID               string              `json:"trace_id,omitempty"`
+	SpanID                string              `json:"span_id,omitempty"`
+	ParentSpanID          string              `json:"parent_span_id,omitempty"`
+}
+
+// GRPCStreamInfo holds a streaming gRPC call's summary, as recorded on
+// grpc.stream.closed.
+type GRPCStreamInfo struct {
+	Source           string              `json:"source"`
+	Target           string              `json:"target"`
+	Ingress          string              `json:"ingress"`
+	Service          string              `json:"service"`
+	Method           string              `json:"method"`
+	GRPCStatus       string              `json:"grpc_status,omitempty"`
+	GRPCMessage      string              `json:"grpc_message,omitempty"`
+	MessagesIn       int64               `json:"messages_in,omitempty"`
+	MessagesOut      int64               `json:"messages_out,omitempty"`
+	RequestSize      int64               `json:"request_size,omitempty"`
+	ResponseSize     int64               `json:"response_size,omitempty"`
+	DurationMs       float64             `json:"duration_ms,omitempty"`
+	ResponseMetadata map[string][]string `json:"response_metadata,omitempty"`
+	TraceID          string              `json:"trace_id,omitempty"`
+	SpanID           string              `json:"span_id,omitempty"`
+	ParentSpanID     string              `json:"parent_span_id,omitempty"`
 }
 
 // KafkaRequestInfo holds Kafka request metadata.
@@ -96,15 +144,70 @@ type KafkaRequestInfo struct {
 	ResponseSize  int64   `json:"response_size"`
 }
 
+// RedisCommandInfo holds Redis command metadata.
+type RedisCommandInfo struct {
+	Source       string  `json:"source"`
+	Target       string  `json:"target"`
+	Ingress      string  `json:"ingress"`
+	Command      string  `json:"command"`
+	Key          string  `json:"key,omitempty"`
+	ReplyType    string  `json:"reply_type"`
+	RedisError   string  `json:"redis_error,omitempty"`
+	LatencyMs    float64 `json:"latency_ms"`
+	RequestSize  int64   `json:"request_size"`
+	ResponseSize int64   `json:"response_size"`
+}
+
+// MongoCommandInfo holds MongoDB command metadata.
+type MongoCommandInfo struct {
+	Source       string  `json:"source"`
+	Target       string  `json:"target"`
+	Ingress      string  `json:"ingress"`
+	Command      string  `json:"command"`
+	Collection   string  `json:"collection,omitempty"`
+	Database     string  `json:"database,omitempty"`
+	MongoError   string  `json:"mongo_error,omitempty"`
+	LatencyMs    float64 `json:"latency_ms"`
+	RequestSize  int64   `json:"request_size"`
+	ResponseSize int64   `json:"response_size"`
+}
+
+// NATSMessageInfo holds NATS message metadata: a PUB, SUB, or delivered MSG.
+type NATSMessageInfo struct {
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	Ingress     string `json:"ingress"`
+	Op          string `json:"op"`
+	Subject     string `json:"subject"`
+	ReplyTo     string `json:"reply_to,omitempty"`
+	Queue       string `json:"queue,omitempty"`
+	PayloadSize int64  `json:"payload_size"`
+	HeaderSize  int64  `json:"header_size,omitempty"`
+}
+
+// WebSocketInfo holds websocket connection metadata.
+type WebSocketInfo struct {
+	Source     string  `json:"source"`
+	Target     string  `json:"target"`
+	Ingress    string  `json:"ingress"`
+	Path       string  `json:"path"`
+	FramesIn   int64   `json:"frames_in"`
+	FramesOut  int64   `json:"frames_out"`
+	BytesIn    int64   `json:"bytes_in"`
+	BytesOut   int64   `json:"bytes_out"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
 // TrafficRow is a normalized row ready for display.
 type TrafficRow struct {
 	Index    int
-	Time     string // relative to first event
+	Time     string        // relative to first event
+	Offset   time.Duration // Time, unformatted
 	Source   string
 	Target   string
-	Protocol string // "HTTP", "gRPC", "TCP", "Kafka"
+	Protocol string // "HTTP", "gRPC", "TCP", "Kafka", "Redis", "NATS", "Mongo", "WS"
 	Method   string
-	Path     string // path for HTTP, service/method for gRPC, "—" for TCP
+	Path     string // path for HTTP, service/method for gRPC, key for Redis, subject for NATS, collection for Mongo, "—" for TCP
 	Status   string
 	Latency  string
 	Extra    string // e.g. byte counts for TCP
@@ -118,7 +221,16 @@ type TrafficFilter struct {
 	Edge     string
 	SlowMs   float64
 	Status   string
-	Protocol string // "http", "grpc", "tcp", "kafka", or ""
+	Protocol string // "http", "grpc", "tcp", "kafka", "redis", "nats", "mongo", or ""
+	Label    string // X-Rig-Label value of HTTP requests
+	Trace    string // trace ID, or a prefix of one, of HTTP and gRPC calls
+
+	// Time window. Since and Until are offsets from the first event;
+	// SinceTime and UntilTime are absolute. Zero values are unbounded.
+	Since     time.Duration
+	Until     time.Duration
+	SinceTime time.Time
+	UntilTime time.Time
 }
 
 // LogEntry holds a single log line with stream info.
@@ -147,12 +259,17 @@ type LogRow struct {
 
 // LsHeader mirrors the log.header struct written by the server.
 type LsHeader struct {
-	Type        string    `json:"type"`
-	Environment string    `json:"environment"`
-	Outcome     string    `json:"outcome"`
-	Services    []string  `json:"services"`
-	DurationMs  float64   `json:"duration_ms"`
-	Timestamp   time.Time `json:"timestamp"`
+	Type            string             `json:"type"`
+	Environment     string             `json:"environment"`
+	Outcome         string             `json:"outcome"`
+	Services        []string           `json:"services"`
+	DurationMs      float64            `json:"duration_ms"`
+	ArtifactRetries int                `json:"artifact_retries"`
+	ExitCodes       map[string]int     `json:"exit_codes"`
+	ReadyDurations  map[string]float64 `json:"ready_durations_ms"`
+	BodyLimit       *int               `json:"observe_body_limit"`
+	Metadata        map[string]string  `json:"metadata"`
+	Timestamp       time.Time          `json:"timestamp"`
 }
 
 // LsEntry is a parsed log file summary ready for display.
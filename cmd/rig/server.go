@@ -1,6 +1,10 @@
 package main
 
-import "github.com/matgreaves/rig/cmd/rig/rigdata"
+import (
+	"strings"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
 
 // serverAddr returns the rigd server address, using the version from this CLI.
 // Kept as a convenience wrapper used by commands that haven't been migrated
@@ -8,3 +12,19 @@ import "github.com/matgreaves/rig/cmd/rig/rigdata"
 func serverAddr() (string, error) {
 	return rigdata.ServerAddr(RigdVersion)
 }
+
+// resolveServer returns the rigd base URL for a --server flag value,
+// falling back to the rigd addr file when it is empty.
+func resolveServer(flagAddr string) (string, error) {
+	addr := flagAddr
+	if addr == "" {
+		var err error
+		addr, err = rigdata.ServerAddr(RigdVersion)
+		if err != nil {
+			return "", err
+		}
+	} else if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return strings.TrimRight(addr, "/"), nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+func runStats(args []string) error {
+	filename, flagArgs := extractFile(args)
+
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	var jsonOut bool
+	fs.BoolVar(&jsonOut, "json", false, "output structured JSON")
+	fs.Usage = printStatsUsage
+
+	if err := fs.Parse(flagArgs); err != nil {
+		return err
+	}
+	if filename == "" {
+		if fs.NArg() > 0 {
+			filename = fs.Arg(0)
+		} else {
+			return fmt.Errorf("missing JSONL file argument\n\nUsage: rig stats <file.jsonl> [flags]")
+		}
+	}
+
+	resolved, err := rigdata.ResolveLogFile(filename)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	events, err := rigdata.ParseTrafficEvents(f)
+	if err != nil {
+		return err
+	}
+	stats := rigdata.ComputeStats(events)
+
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	if len(stats) == 0 {
+		fmt.Fprintln(os.Stderr, "No HTTP or gRPC traffic found.")
+		return nil
+	}
+	renderStatsTable(os.Stdout, stats)
+	return nil
+}
+
+func renderStatsTable(w io.Writer, stats []rigdata.EdgeStats) {
+	headers := []string{"EDGE", "COUNT", "ERRORS", "P50", "P90", "P99", "MAX"}
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+	}
+
+	rows := make([][7]string, len(stats))
+	for i, s := range stats {
+		rows[i] = [7]string{
+			s.Source + " → " + s.Target,
+			strconv.Itoa(s.Count),
+			fmt.Sprintf("%d (%.1f%%)", s.Errors, s.ErrorRate*100),
+			rigdata.FormatLatency(s.P50Ms),
+			rigdata.FormatLatency(s.P90Ms),
+			rigdata.FormatLatency(s.P99Ms),
+			rigdata.FormatLatency(s.MaxMs),
+		}
+		for j, c := range rows[i] {
+			if n := utf8.RuneCountInString(c); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+
+	for i, h := range headers {
+		if i > 0 {
+			fmt.Fprint(w, "  ")
+		}
+		fmt.Fprintf(w, "%-*s", widths[i], bold(h))
+	}
+	fmt.Fprintln(w)
+
+	for ri, cells := range rows {
+		for i, c := range cells {
+			if i > 0 {
+				fmt.Fprint(w, "  ")
+			}
+			if i == len(cells)-1 {
+				fmt.Fprint(w, c)
+				continue
+			}
+			padded := fmt.Sprintf("%-*s", widths[i], c)
+			if i == 2 && stats[ri].Errors > 0 {
+				padded = red(padded)
+			}
+			fmt.Fprint(w, padded)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func printStatsUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: rig stats <file.jsonl> [flags]
+
+Aggregate HTTP and gRPC latency per source → target edge: request count,
+error rate, and p50/p90/p99/max latency. Percentiles are exact, computed
+from every request on the edge. Errors are HTTP 4xx/5xx responses and
+non-OK gRPC statuses.
+
+Flags:
+  --json        output structured JSON (for diffing runs)
+`)
+}
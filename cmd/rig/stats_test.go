@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+func TestComputeStatsPercentiles(t *testing.T) {
+	var events []rigdata.Event
+	// api → db: latencies 1..100ms, every 10th request a 500.
+	for i := 1; i <= 100; i++ {
+		status := 200
+		if i%10 == 0 {
+			status = 500
+		}
+		events = append(events, rigdata.Event{
+			Type:    rigdata.TypeRequestCompleted,
+			Request: &rigdata.RequestInfo{Source: "api", Target: "db", StatusCode: status, LatencyMs: float64(i)},
+		})
+	}
+	// ~test → api: two gRPC calls, one failed.
+	events = append(events,
+		rigdata.Event{Type: rigdata.TypeGRPCCallCompleted, GRPCCall: &rigdata.GRPCCallInfo{Source: "~test", Target: "api", GRPCStatus: "OK", LatencyMs: 3}},
+		rigdata.Event{Type: rigdata.TypeGRPCCallCompleted, GRPCCall: &rigdata.GRPCCallInfo{Source: "~test", Target: "api", GRPCStatus: "NotFound", LatencyMs: 7}},
+		// TCP connections have no request latency and are ignored.
+		rigdata.Event{Type: rigdata.TypeConnectionClosed, Connection: &rigdata.ConnectionInfo{Source: "api", Target: "db"}},
+	)
+
+	stats := rigdata.ComputeStats(events)
+	if len(stats) != 2 {
+		t.Fatalf("got %d edges, want 2: %+v", len(stats), stats)
+	}
+
+	grpc := stats[1]
+	if grpc.Source != "~test" || grpc.Count != 2 || grpc.Errors != 1 || grpc.P50Ms != 3 || grpc.MaxMs != 7 {
+		t.Errorf("~test → api = %+v, want 2 calls, 1 error, p50 3ms, max 7ms", grpc)
+	}
+
+	http := stats[0]
+	if http.Source != "api" || http.Target != "db" {
+		t.Fatalf("stats[0] edge = %s → %s, want api → db", http.Source, http.Target)
+	}
+	if http.Count != 100 || http.Errors != 10 || http.ErrorRate != 0.1 {
+		t.Errorf("count/errors/rate = %d/%d/%v, want 100/10/0.1", http.Count, http.Errors, http.ErrorRate)
+	}
+	if http.P50Ms != 50 || http.P90Ms != 90 || http.P99Ms != 99 || http.MaxMs != 100 {
+		t.Errorf("p50/p90/p99/max = %v/%v/%v/%v, want 50/90/99/100", http.P50Ms, http.P90Ms, http.P99Ms, http.MaxMs)
+	}
+}
+
+func TestRenderStatsTable(t *testing.T) {
+	stats := rigdata.ComputeStats(loadTestEvents(t, "testdata/mixed_traffic.jsonl"))
+
+	var b strings.Builder
+	renderStatsTable(&b, stats)
+	out := b.String()
+	for _, want := range []string{"EDGE", "P99", "order → postgres", "1 (33.3%)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -21,22 +21,10 @@ func runTraffic(args []string) error {
 	fs := flag.NewFlagSet("traffic", flag.ContinueOnError)
 	var (
 		detail int
-		edge   string
-		slow   string
-		status string
-		grpc   bool
-		http   bool
-		tcp    bool
-		kafka  bool
+		tf     trafficFlags
 	)
 	fs.IntVar(&detail, "detail", 0, "show full detail for request #N")
-	fs.StringVar(&edge, "edge", "", `filter by edge: "source→target", "source", or "→target"`)
-	fs.StringVar(&slow, "slow", "", "only show requests slower than threshold (e.g. 5ms, 1s)")
-	fs.StringVar(&status, "status", "", "filter by status code (e.g. 500) or class (e.g. 4xx)")
-	fs.BoolVar(&grpc, "grpc", false, "only show gRPC calls")
-	fs.BoolVar(&http, "http", false, "only show HTTP requests")
-	fs.BoolVar(&tcp, "tcp", false, "only show TCP connections")
-	fs.BoolVar(&kafka, "kafka", false, "only show Kafka requests")
+	tf.register(fs)
 
 	if err := fs.Parse(flagArgs); err != nil {
 		return err
@@ -50,27 +38,9 @@ func runTraffic(args []string) error {
 		}
 	}
 
-	var filter rigdata.TrafficFilter
-	filter.Edge = edge
-	filter.Status = status
-
-	if slow != "" {
-		d, err := time.ParseDuration(slow)
-		if err != nil {
-			return fmt.Errorf("invalid --slow value %q: %v", slow, err)
-		}
-		filter.SlowMs = float64(d) / float64(time.Millisecond)
-	}
-
-	switch {
-	case grpc:
-		filter.Protocol = "grpc"
-	case http:
-		filter.Protocol = "http"
-	case tcp:
-		filter.Protocol = "tcp"
-	case kafka:
-		filter.Protocol = "kafka"
+	filter, err := tf.filter()
+	if err != nil {
+		return err
 	}
 
 	// Resolve glob pattern if the argument isn't a direct file path.
@@ -105,13 +75,124 @@ func runTraffic(args []string) error {
 	}
 
 	if detail > 0 {
-		return renderDetail(os.Stdout, rows, detail)
+		if err := renderDetail(os.Stdout, rows, detail); err != nil {
+			return err
+		}
+		if hdr, err := rigdata.ReadHeader(filename); err == nil {
+			if note := bodyLimitNote(hdr); note != "" {
+				fmt.Fprintf(os.Stdout, "\n  %s\n", dim(note))
+			}
+		}
+		return nil
 	}
 
 	renderTable(os.Stdout, rows)
 	return nil
 }
 
+// trafficFlags holds the row filter flags shared by rig traffic and rig watch.
+type trafficFlags struct {
+	edge     string
+	slow     string
+	status   string
+	label    string
+	trace    string
+	since    string
+	until    string
+	protocol string
+	grpc     bool
+	http     bool
+	tcp      bool
+	kafka    bool
+	redis    bool
+	nats     bool
+	mongo    bool
+}
+
+func (tf *trafficFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&tf.edge, "edge", "", `filter by edge: "source→target", "source", or "→target"`)
+	fs.StringVar(&tf.slow, "slow", "", "only show requests slower than threshold (e.g. 5ms, 1s)")
+	fs.StringVar(&tf.status, "status", "", "filter by status code (e.g. 500) or class (e.g. 4xx)")
+	fs.StringVar(&tf.label, "label", "", "only show HTTP requests sent with this X-Rig-Label")
+	fs.StringVar(&tf.trace, "trace", "", "only show HTTP and gRPC calls in this trace (ID or prefix)")
+	fs.StringVar(&tf.since, "since", "", "only show events at or after this offset from the first event (e.g. 2s) or RFC 3339 time")
+	fs.StringVar(&tf.until, "until", "", "only show events at or before this offset from the first event (e.g. 500ms) or RFC 3339 time")
+	fs.StringVar(&tf.protocol, "filter", "", `only show one protocol: "http", "grpc", "tcp", "kafka", "redis", "nats", "mongo", or "ws"`)
+	fs.BoolVar(&tf.grpc, "grpc", false, "only show gRPC calls")
+	fs.BoolVar(&tf.http, "http", false, "only show HTTP requests")
+	fs.BoolVar(&tf.tcp, "tcp", false, "only show TCP connections")
+	fs.BoolVar(&tf.kafka, "kafka", false, "only show Kafka requests")
+	fs.BoolVar(&tf.redis, "redis", false, "only show Redis commands")
+	fs.BoolVar(&tf.nats, "nats", false, "only show NATS messages")
+	fs.BoolVar(&tf.mongo, "mongo", false, "only show MongoDB commands")
+}
+
+// filter converts the parsed flags into a TrafficFilter.
+func (tf *trafficFlags) filter() (rigdata.TrafficFilter, error) {
+	filter := rigdata.TrafficFilter{
+		Edge:     tf.edge,
+		Status:   tf.status,
+		Label:    tf.label,
+		Trace:    tf.trace,
+		Protocol: strings.ToLower(tf.protocol),
+	}
+
+	if tf.slow != "" {
+		d, err := time.ParseDuration(tf.slow)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --slow value %q: %v", tf.slow, err)
+		}
+		filter.SlowMs = float64(d) / float64(time.Millisecond)
+	}
+
+	var err error
+	if filter.Since, filter.SinceTime, err = parseWindowBound("since", tf.since); err != nil {
+		return filter, err
+	}
+	if filter.Until, filter.UntilTime, err = parseWindowBound("until", tf.until); err != nil {
+		return filter, err
+	}
+
+	switch filter.Protocol {
+	case "", "http", "grpc", "tcp", "kafka", "redis", "nats", "mongo", "ws":
+	default:
+		return filter, fmt.Errorf("invalid --filter value %q: want http, grpc, tcp, kafka, redis, nats, mongo, or ws", tf.protocol)
+	}
+
+	switch {
+	case tf.grpc:
+		filter.Protocol = "grpc"
+	case tf.http:
+		filter.Protocol = "http"
+	case tf.tcp:
+		filter.Protocol = "tcp"
+	case tf.kafka:
+		filter.Protocol = "kafka"
+	case tf.redis:
+		filter.Protocol = "redis"
+	case tf.nats:
+		filter.Protocol = "nats"
+	case tf.mongo:
+		filter.Protocol = "mongo"
+	}
+	return filter, nil
+}
+
+// parseWindowBound parses a --since or --until value: a duration offset
+// from the first event, or an absolute RFC 3339 timestamp.
+func parseWindowBound(name, v string) (time.Duration, time.Time, error) {
+	if v == "" {
+		return 0, time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return d, time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return 0, t, nil
+	}
+	return 0, time.Time{}, fmt.Errorf("invalid --%s value %q: want a duration (e.g. 2s) or an RFC 3339 time", name, v)
+}
+
 func renderTable(w io.Writer, rows []rigdata.TrafficRow) {
 	// Build service → color index map in order of first appearance.
 	serviceIndex := map[string]int{}
@@ -131,23 +212,10 @@ func renderTable(w io.Writer, rows []rigdata.TrafficRow) {
 		widths[i] = len(h)
 	}
 
-	type formattedRow struct {
-		cols [8]string
-	}
-	formatted := make([]formattedRow, len(rows))
+	formatted := make([][8]string, len(rows))
 	for i, r := range rows {
-		edgeStr := r.Source + " → " + r.Target
-		formatted[i] = formattedRow{cols: [8]string{
-			strconv.Itoa(r.Index),
-			r.Time,
-			edgeStr,
-			r.Method,
-			r.Path,
-			r.Status,
-			r.Latency,
-			r.Extra,
-		}}
-		for j, c := range formatted[i].cols {
+		formatted[i] = rowCells(r)
+		for j, c := range formatted[i] {
 			if len(c) > widths[j] {
 				widths[j] = len(c)
 			}
@@ -163,35 +231,53 @@ func renderTable(w io.Writer, rows []rigdata.TrafficRow) {
 	}
 	fmt.Fprintln(w)
 
-	// Print rows with colored edge, method, and status.
-	for ri, fr := range formatted {
-		for i, c := range fr.cols {
-			if i > 0 {
-				fmt.Fprint(w, "  ")
-			}
-			padded := fmt.Sprintf("%-*s", widths[i], c)
-			switch i {
-			case 2: // EDGE — color source and target separately
-				r := rows[ri]
-				coloredEdge := colorService(r.Source, serviceIndex[r.Source]) +
-					" → " +
-					colorService(r.Target, serviceIndex[r.Target])
-				// Pad to column width (edge plain text length is len(c))
-				padding := widths[i] - len(c)
-				if padding > 0 {
-					coloredEdge += strings.Repeat(" ", padding)
-				}
-				fmt.Fprint(w, coloredEdge)
-			case 3: // METHOD
-				fmt.Fprint(w, colorMethod(padded))
-			case 5: // STATUS
-				fmt.Fprint(w, colorStatus(padded))
-			default:
-				fmt.Fprint(w, padded)
+	for ri, cells := range formatted {
+		writeRow(w, rows[ri], cells, widths, serviceIndex)
+	}
+}
+
+// rowCells returns the plain-text table cells for r, in header order.
+func rowCells(r rigdata.TrafficRow) [8]string {
+	return [8]string{
+		strconv.Itoa(r.Index),
+		r.Time,
+		r.Source + " → " + r.Target,
+		r.Method,
+		r.Path,
+		r.Status,
+		r.Latency,
+		r.Extra,
+	}
+}
+
+// writeRow prints one table row, padding each cell to its column width
+// and coloring the edge, method, and status.
+func writeRow(w io.Writer, r rigdata.TrafficRow, cells [8]string, widths []int, serviceIndex map[string]int) {
+	for i, c := range cells {
+		if i > 0 {
+			fmt.Fprint(w, "  ")
+		}
+		padded := fmt.Sprintf("%-*s", widths[i], c)
+		switch i {
+		case 2: // EDGE — color source and target separately
+			coloredEdge := colorService(r.Source, serviceIndex[r.Source]) +
+				" → " +
+				colorService(r.Target, serviceIndex[r.Target])
+			// Pad to column width (edge plain text length is len(c))
+			padding := widths[i] - len(c)
+			if padding > 0 {
+				coloredEdge += strings.Repeat(" ", padding)
 			}
+			fmt.Fprint(w, coloredEdge)
+		case 3: // METHOD
+			fmt.Fprint(w, colorMethod(padded))
+		case 5: // STATUS
+			fmt.Fprint(w, colorStatus(padded))
+		default:
+			fmt.Fprint(w, padded)
 		}
-		fmt.Fprintln(w)
 	}
+	fmt.Fprintln(w)
 }
 
 // extractFile scans args for the first positional (non-flag) argument,
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/matgreaves/rig/cmd/rig/rigdata"
 )
@@ -211,6 +212,209 @@ func TestFilterProtocol(t *testing.T) {
 	}
 }
 
+func TestFilterLabel(t *testing.T) {
+	events := []rigdata.Event{
+		{Type: rigdata.TypeRequestCompleted, Request: &rigdata.RequestInfo{Source: "~test", Target: "api", Method: "POST", Path: "/orders", StatusCode: 201, Label: "checkout"}},
+		{Type: rigdata.TypeRequestCompleted, Request: &rigdata.RequestInfo{Source: "~test", Target: "api", Method: "GET", Path: "/health", StatusCode: 200}},
+		{Type: rigdata.TypeRequestCompleted, Request: &rigdata.RequestInfo{Source: "api", Target: "db", Method: "GET", Path: "/stock", StatusCode: 200, Label: "restock"}},
+	}
+	rows := rigdata.BuildRows(events)
+
+	filtered := rigdata.ApplyFilter(rows, rigdata.TrafficFilter{Label: "checkout"})
+	if len(filtered) != 1 {
+		t.Fatalf("got %d rows for label=checkout, want 1", len(filtered))
+	}
+	if filtered[0].Path != "/orders" {
+		t.Errorf("got path %q, want /orders", filtered[0].Path)
+	}
+}
+
+func TestFilterTrace(t *testing.T) {
+	const trace = "4bf92f3577b34da6a3ce929d0e0e4736"
+	events := []rigdata.Event{
+		{Type: rigdata.TypeRequestCompleted, Request: &rigdata.RequestInfo{Source: "~test", Target: "api", Method: "POST", Path: "/orders", StatusCode: 201, TraceID: trace, SpanID: "00f067aa0ba902b7"}},
+		{Type: rigdata.TypeRequestCompleted, Request: &rigdata.RequestInfo{Source: "~test", Target: "api", Method: "GET", Path: "/health", StatusCode: 200, TraceID: "0af7651916cd43dd8448eb211c80319c"}},
+		{Type: rigdata.TypeGRPCCallCompleted, GRPCCall: &rigdata.GRPCCallInfo{Source: "api", Target: "billing", Service: "billing.v1.Billing", Method: "Charge", GRPCStatus: "OK", TraceID: trace, ParentSpanID: "00f067aa0ba902b7"}},
+		{Type: rigdata.TypeConnectionClosed, Connection: &rigdata.ConnectionInfo{Source: "api", Target: "db"}},
+	}
+	rows := rigdata.BuildRows(events)
+
+	for _, id := range []string{trace, "4BF92F35"} {
+		filtered := rigdata.ApplyFilter(rows, rigdata.TrafficFilter{Trace: id})
+		if len(filtered) != 2 {
+			t.Fatalf("got %d rows for trace=%s, want 2", len(filtered), id)
+		}
+		if filtered[0].Path != "/orders" || filtered[1].Target != "billing" {
+			t.Errorf("trace=%s rows = %s, %s→%s; want /orders then api→billing", id, filtered[0].Path, filtered[1].Source, filtered[1].Target)
+		}
+	}
+}
+
+func TestFilterWindow(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var events []rigdata.Event
+	for i, path := range []string{"/a", "/b", "/c", "/d"} {
+		events = append(events, rigdata.Event{
+			Type:      rigdata.TypeRequestCompleted,
+			Timestamp: t0.Add(time.Duration(i) * time.Second),
+			Request:   &rigdata.RequestInfo{Source: "~test", Target: "api", Method: "GET", Path: path, StatusCode: 200},
+		})
+	}
+	rows := rigdata.BuildRows(events)
+
+	paths := func(f rigdata.TrafficFilter) string {
+		var out []string
+		for _, r := range rigdata.ApplyFilter(rows, f) {
+			out = append(out, r.Path)
+		}
+		return strings.Join(out, ",")
+	}
+	tests := []struct {
+		name string
+		f    rigdata.TrafficFilter
+		want string
+	}{
+		{"since", rigdata.TrafficFilter{Since: 2 * time.Second}, "/c,/d"},
+		{"until", rigdata.TrafficFilter{Until: 1500 * time.Millisecond}, "/a,/b"},
+		{"window", rigdata.TrafficFilter{Since: time.Second, Until: 2 * time.Second}, "/b,/c"},
+		{"absolute", rigdata.TrafficFilter{SinceTime: t0.Add(3 * time.Second)}, "/d"},
+	}
+	for _, tt := range tests {
+		if got := paths(tt.f); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseWindowBound(t *testing.T) {
+	if d, ts, err := parseWindowBound("since", "500ms"); err != nil || d != 500*time.Millisecond || !ts.IsZero() {
+		t.Errorf("500ms = %v, %v, %v", d, ts, err)
+	}
+	want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if d, ts, err := parseWindowBound("since", "2026-01-02T03:04:05Z"); err != nil || d != 0 || !ts.Equal(want) {
+		t.Errorf("timestamp = %v, %v, %v", d, ts, err)
+	}
+	if _, _, err := parseWindowBound("until", "soon"); err == nil || !strings.Contains(err.Error(), "--until") {
+		t.Errorf("err = %v, want invalid --until", err)
+	}
+}
+
+func TestBuildRowsMocked(t *testing.T) {
+	events := []rigdata.Event{
+		{Type: rigdata.TypeRequestMocked, Request: &rigdata.RequestInfo{Source: "orders", Target: "payments", Method: "POST", Path: "/charges", StatusCode: 402, ProxyInjected: true}},
+	}
+	rows := rigdata.BuildRows(events)
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	r := rows[0]
+	if r.Protocol != "HTTP" || r.Status != "402" || r.Extra != "mocked" {
+		t.Errorf("row = %s %s %q, want HTTP 402 \"mocked\"", r.Protocol, r.Status, r.Extra)
+	}
+	if got := rigdata.ApplyFilter(rows, rigdata.TrafficFilter{Status: "4xx"}); len(got) != 1 {
+		t.Errorf("status 4xx filter kept %d rows, want 1", len(got))
+	}
+}
+
+func TestBuildRowsRedis(t *testing.T) {
+	events := []rigdata.Event{
+		{Type: rigdata.TypeRedisCommandCompleted, RedisCommand: &rigdata.RedisCommandInfo{Source: "api", Target: "cache", Command: "GET", Key: "user:1", ReplyType: "bulk"}},
+		{Type: rigdata.TypeRedisCommandCompleted, RedisCommand: &rigdata.RedisCommandInfo{Source: "api", Target: "cache", Command: "INCR", Key: "name", ReplyType: "error", RedisError: "WRONGTYPE Operation against a key holding the wrong kind of value"}},
+	}
+	rows := rigdata.BuildRows(events)
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if r := rows[0]; r.Protocol != "Redis" || r.Method != "GET" || r.Path != "user:1" || r.Status != "OK" {
+		t.Errorf("row 1 = %s %s %s %s, want Redis GET user:1 OK", r.Protocol, r.Method, r.Path, r.Status)
+	}
+	if r := rows[1]; r.Status != "WRONGTYPE" {
+		t.Errorf("row 2 status = %q, want WRONGTYPE", r.Status)
+	}
+	if got := rigdata.ApplyFilter(rows, rigdata.TrafficFilter{Protocol: "redis"}); len(got) != 2 {
+		t.Errorf("redis filter kept %d rows, want 2", len(got))
+	}
+
+	var buf bytes.Buffer
+	if err := renderDetail(&buf, rows, 2); err != nil {
+		t.Fatalf("renderDetail: %v", err)
+	}
+	if !strings.Contains(buf.String(), "WRONGTYPE Operation") {
+		t.Errorf("detail missing redis error:\n%s", buf.String())
+	}
+}
+
+func TestBuildRowsNATS(t *testing.T) {
+	events := []rigdata.Event{
+		{Type: rigdata.TypeNATSMessage, NATSMessage: &rigdata.NATSMessageInfo{Source: "worker", Target: "bus", Op: "SUB", Subject: "orders.*", Queue: "workers"}},
+		{Type: rigdata.TypeNATSMessage, NATSMessage: &rigdata.NATSMessageInfo{Source: "api", Target: "bus", Op: "PUB", Subject: "orders.created", ReplyTo: "_INBOX.1", PayloadSize: 42}},
+	}
+	rows := rigdata.BuildRows(events)
+	if r := rows[0]; r.Protocol != "NATS" || r.Method != "SUB" || r.Path != "orders.*" || r.Extra != "queue workers" {
+		t.Errorf("row 1 = %s %s %s %q, want NATS SUB orders.* \"queue workers\"", r.Protocol, r.Method, r.Path, r.Extra)
+	}
+	if r := rows[1]; r.Method != "PUB" || r.Extra != "42B reply _INBOX.1" {
+		t.Errorf("row 2 = %s %q, want PUB \"42B reply _INBOX.1\"", r.Method, r.Extra)
+	}
+	if got := rigdata.ApplyFilter(rows, rigdata.TrafficFilter{Protocol: "nats"}); len(got) != 2 {
+		t.Errorf("nats filter kept %d rows, want 2", len(got))
+	}
+
+	var buf bytes.Buffer
+	if err := renderDetail(&buf, rows, 2); err != nil {
+		t.Fatalf("renderDetail: %v", err)
+	}
+	if !strings.Contains(buf.String(), "orders.created") || !strings.Contains(buf.String(), "_INBOX.1") {
+		t.Errorf("detail missing subject or reply:\n%s", buf.String())
+	}
+}
+
+func TestBuildRowsMongo(t *testing.T) {
+	events := []rigdata.Event{
+		{Type: rigdata.TypeMongoCommand, MongoCommand: &rigdata.MongoCommandInfo{Source: "api", Target: "db", Command: "find", Collection: "orders", Database: "test", LatencyMs: 1.5, RequestSize: 120, ResponseSize: 300}},
+		{Type: rigdata.TypeMongoCommand, MongoCommand: &rigdata.MongoCommandInfo{Source: "api", Target: "db", Command: "insert", Collection: "users", MongoError: "DuplicateKey: E11000 duplicate key error"}},
+	}
+	rows := rigdata.BuildRows(events)
+	if r := rows[0]; r.Protocol != "Mongo" || r.Method != "find" || r.Path != "orders" || r.Status != "OK" {
+		t.Errorf("row 1 = %s %s %s %s, want Mongo find orders OK", r.Protocol, r.Method, r.Path, r.Status)
+	}
+	if r := rows[1]; r.Status != "DuplicateKey" {
+		t.Errorf("row 2 status = %q, want DuplicateKey", r.Status)
+	}
+	if got := rigdata.ApplyFilter(rows, rigdata.TrafficFilter{Protocol: "mongo", Status: "DuplicateKey"}); len(got) != 1 {
+		t.Errorf("mongo DuplicateKey filter kept %d rows, want 1", len(got))
+	}
+
+	var buf bytes.Buffer
+	if err := renderDetail(&buf, rows, 2); err != nil {
+		t.Fatalf("renderDetail: %v", err)
+	}
+	if !strings.Contains(buf.String(), "users") || !strings.Contains(buf.String(), "E11000") {
+		t.Errorf("detail missing collection or error:\n%s", buf.String())
+	}
+}
+
+func TestBuildRowsWebSocket(t *testing.T) {
+	events := []rigdata.Event{
+		{Type: rigdata.TypeWebSocketClosed, WebSocket: &rigdata.WebSocketInfo{Source: "~test", Target: "chat", Path: "/ws", FramesIn: 3, FramesOut: 2, BytesIn: 30, BytesOut: 14, DurationMs: 1200}},
+	}
+	rows := rigdata.BuildRows(events)
+	if r := rows[0]; r.Protocol != "WS" || r.Path != "/ws" || r.Extra != "3↑ 2↓ frames" {
+		t.Errorf("row = %s %s %s, want WS /ws 3↑ 2↓ frames", r.Protocol, r.Path, r.Extra)
+	}
+	if got := rigdata.ApplyFilter(rows, rigdata.TrafficFilter{Protocol: "ws"}); len(got) != 1 {
+		t.Errorf("ws filter kept %d rows, want 1", len(got))
+	}
+
+	var buf bytes.Buffer
+	if err := renderDetail(&buf, rows, 1); err != nil {
+		t.Fatalf("renderDetail: %v", err)
+	}
+	if !strings.Contains(buf.String(), "3 (30B)") {
+		t.Errorf("detail missing inbound frames:\n%s", buf.String())
+	}
+}
+
 func TestRenderDetailHTTP(t *testing.T) {
 	events := loadTestEvents(t, "testdata/mixed_traffic.jsonl")
 	rows := rigdata.BuildRows(events)
@@ -288,6 +492,34 @@ func TestRenderDetailTCP(t *testing.T) {
 	}
 }
 
+func TestRenderTCPDetailPreview(t *testing.T) {
+	var buf bytes.Buffer
+	renderTCPDetail(&buf, &rigdata.ConnectionInfo{
+		BytesIn:            2048,
+		BytesOut:           2,
+		PreviewIn:          []byte("\x00\x01hello world, binary protocol"),
+		PreviewInTruncated: true,
+		PreviewOut:         []byte("ok"),
+	})
+	out := buf.String()
+
+	for _, want := range []string{
+		"Preview In (first 30B of 2.0KB) [truncated]:",
+		"00000000  00 01 68 65 6c 6c 6f 20  77 6f 72 6c 64 2c 20 62  |..hello world, b|",
+		"00000010  69 6e 61 72 79 20 70 72  6f 74 6f 63 6f 6c ",
+		"|inary protocol|",
+		"Preview Out (2B):",
+		"|ok|",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "[truncated]") != 1 {
+		t.Errorf("only the inbound preview should be marked truncated:\n%s", out)
+	}
+}
+
 func TestRenderDetailNotFound(t *testing.T) {
 	events := loadTestEvents(t, "testdata/mixed_traffic.jsonl")
 	rows := rigdata.BuildRows(events)
@@ -302,6 +534,25 @@ func TestRenderDetailNotFound(t *testing.T) {
 	}
 }
 
+func TestBodyLimitNote(t *testing.T) {
+	limit := func(n int) *int { return &n }
+	tests := []struct {
+		limit *int
+		want  string
+	}{
+		{nil, ""},
+		{limit(-1), ""},
+		{limit(0), "disabled"},
+		{limit(1024), "up to 1.0KB"},
+	}
+	for _, tt := range tests {
+		got := bodyLimitNote(rigdata.LsHeader{BodyLimit: tt.limit})
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("bodyLimitNote(%v) = %q, want %q", tt.limit, got, tt.want)
+		}
+	}
+}
+
 func TestFormatLatency(t *testing.T) {
 	tests := []struct {
 		ms   float64
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+func runWatch(args []string) error {
+	target, flagArgs := extractFile(args)
+
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	var (
+		server string
+		tf     trafficFlags
+	)
+	fs.StringVar(&server, "server", "", "rigd address (default: read from the rigd addr file)")
+	tf.register(fs)
+
+	if err := fs.Parse(flagArgs); err != nil {
+		return err
+	}
+	if target == "" {
+		if fs.NArg() > 0 {
+			target = fs.Arg(0)
+		} else {
+			return fmt.Errorf("missing environment argument\n\nUsage: rig watch <env> [flags]")
+		}
+	}
+
+	filter, err := tf.filter()
+	if err != nil {
+		return err
+	}
+
+	addr, err := resolveServer(server)
+	if err != nil {
+		return err
+	}
+
+	id, err := rigdata.ResolveEnvID(addr, target)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := newWatcher(os.Stdout, filter)
+	if env, err := rigdata.FetchResolved(addr, id); err == nil {
+		w.addServices(env.Services)
+	}
+	return w.watch(ctx, addr, id)
+}
+
+// watchEvent is a streamed event. Traffic fields come from rigdata.Event;
+// the rest are the lifecycle fields rig watch renders.
+type watchEvent struct {
+	rigdata.Event
+	Service  string `json:"service"`
+	Ingress  string `json:"ingress"`
+	Artifact string `json:"artifact"`
+	Error    string `json:"error"`
+	ExitCode *int   `json:"exit_code"`
+	Message  string `json:"message"`
+	Outcome  string `json:"outcome"`
+	Fault    *struct {
+		Source     string  `json:"source"`
+		Target     string  `json:"target"`
+		Kind       string  `json:"kind"`
+		DelayMs    float64 `json:"delay_ms"`
+		StatusCode int     `json:"status_code"`
+		Method     string  `json:"method"`
+		Path       string  `json:"path"`
+	} `json:"fault"`
+}
+
+// watchWidths are the fixed traffic column widths. Unlike rig traffic,
+// rows are printed as they arrive, so widths can't be fitted to the data.
+var watchWidths = []int{4, 8, 24, 6, 28, 6, 8, 0}
+
+// watcher renders a live event stream.
+type watcher struct {
+	w            io.Writer
+	filter       rigdata.TrafficFilter
+	t0           time.Time
+	rows         int // traffic events seen, for rig traffic --detail numbering
+	serviceIndex map[string]int
+}
+
+func newWatcher(w io.Writer, filter rigdata.TrafficFilter) *watcher {
+	return &watcher{w: w, filter: filter, serviceIndex: map[string]int{}}
+}
+
+// addServices assigns colors to the environment's services up front so
+// they stay stable as traffic arrives.
+func (wt *watcher) addServices(services map[string]rigdata.ResolvedSvc) {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		if !strings.Contains(name, "~") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		wt.colorIndex(name)
+	}
+	// The test client shows up as a traffic source too.
+	serviceColorTotal = len(wt.serviceIndex) + 1
+}
+
+func (wt *watcher) colorIndex(name string) int {
+	i, ok := wt.serviceIndex[name]
+	if !ok {
+		i = len(wt.serviceIndex)
+		wt.serviceIndex[name] = i
+	}
+	return i
+}
+
+// watch streams the environment's events until environment.down, the
+// stream closes, or ctx is cancelled. It returns an error if the
+// environment's outcome was failed or crashed.
+func (wt *watcher) watch(ctx context.Context, addr, id string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/environments/"+id+"/events", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("connect to rigd: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("environment %s not found (may have already been torn down)", id)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("rigd returned %d: %s", resp.StatusCode, body)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 256*1024), 4*1024*1024)
+	var data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && data != "":
+			var ev watchEvent
+			err := json.Unmarshal([]byte(data), &ev)
+			data = ""
+			if err != nil {
+				continue
+			}
+			if ev.Type == "environment.down" {
+				return wt.down(ev)
+			}
+			wt.render(ev)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil // interrupted
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("event stream read: %w", err)
+	}
+	return fmt.Errorf("event stream closed before environment.down")
+}
+
+// render prints one event: traffic as a table row, lifecycle events as
+// a one-line summary. Noisy events and injected nodes are skipped.
+func (wt *watcher) render(ev watchEvent) {
+	if wt.t0.IsZero() {
+		wt.t0 = ev.Timestamp
+	}
+	offset := ev.Timestamp.Sub(wt.t0)
+	rel := rigdata.FormatDuration(offset)
+
+	switch ev.Type {
+	case rigdata.TypeRequestCompleted, rigdata.TypeRequestMocked, rigdata.TypeConnectionClosed,
+		rigdata.TypeGRPCCallCompleted, rigdata.TypeGRPCStreamClosed, rigdata.TypeKafkaRequestCompleted, rigdata.TypeRedisCommandCompleted,
+		rigdata.TypeNATSMessage, rigdata.TypeMongoCommand, rigdata.TypeWebSocketClosed:
+		wt.rows++
+		r := rigdata.BuildRows([]rigdata.Event{ev.Event})[0]
+		r.Index = wt.rows
+		r.Time = rel
+		r.Offset = offset
+		if len(rigdata.ApplyFilter([]rigdata.TrafficRow{r}, wt.filter)) == 0 {
+			return
+		}
+		wt.colorIndex(r.Source)
+		wt.colorIndex(r.Target)
+		writeRow(wt.w, r, rowCells(r), watchWidths, wt.serviceIndex)
+		return
+	}
+
+	if strings.Contains(ev.Service, "~") {
+		return // proxies and the test node
+	}
+	var detail string
+	switch ev.Type {
+	case "artifact.started", "artifact.completed", "artifact.cached":
+		detail = ev.Artifact
+	case "artifact.failed":
+		detail = ev.Artifact + ": " + red(ev.Error)
+	case "artifact.retry":
+		detail = ev.Artifact + ": " + ev.Error
+	case "ingress.published":
+		if ev.Ingress != "default" {
+			detail = ev.Service + "/" + ev.Ingress
+		} else {
+			detail = ev.Service
+		}
+	case "service.starting", "service.healthy", "service.ready", "service.stopped":
+		detail = ev.Service
+	case "service.failed", "environment.failing":
+		msg := ev.Error
+		if ev.ExitCode != nil {
+			msg += fmt.Sprintf(" (exit %d)", *ev.ExitCode)
+		}
+		detail = red(msg)
+		if ev.Service != "" {
+			detail = ev.Service + ": " + detail
+		}
+	case "service.restarted":
+		detail = ev.Service + ": " + ev.Message
+		if ev.ExitCode != nil {
+			detail += fmt.Sprintf(" (exit %d)", *ev.ExitCode)
+		}
+	case "fault.injected":
+		f := ev.Fault
+		if f == nil {
+			return
+		}
+		detail = f.Source + " → " + f.Target + ": "
+		switch f.Kind {
+		case "latency":
+			detail += "+" + rigdata.FormatLatency(f.DelayMs)
+		case "error":
+			detail += colorStatus(strconv.Itoa(f.StatusCode))
+		case "drop":
+			detail += red("connection dropped")
+		default:
+			detail += f.Kind
+		}
+		if f.Method != "" {
+			detail += " " + f.Method + " " + f.Path
+		}
+	case "test.note":
+		detail = red(ev.Error)
+	case "progress.stall":
+		detail, _, _ = strings.Cut(ev.Message, "\n")
+	case "environment.up", "environment.destroying":
+	default:
+		return
+	}
+	fmt.Fprintf(wt.w, "%*s  %-*s  %s %s\n", watchWidths[0], "", watchWidths[1], rel, dim(fmt.Sprintf("%-22s", ev.Type)), detail)
+}
+
+// down prints the terminal event and the failure summary, if any.
+func (wt *watcher) down(ev watchEvent) error {
+	outcome := ev.Outcome
+	if outcome == "" {
+		// Older rigd versions don't report an outcome; a summary is only
+		// produced when something failed.
+		outcome = "passed"
+		if ev.Message != "" {
+			outcome = "crashed"
+		}
+	}
+	fmt.Fprintf(wt.w, "\n%s\n", bold("environment "+outcome))
+	if ev.Message != "" {
+		fmt.Fprintln(wt.w, ev.Message)
+	}
+	switch outcome {
+	case "failed", "crashed":
+		return fmt.Errorf("environment %s", outcome)
+	}
+	return nil
+}
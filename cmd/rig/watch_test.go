@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matgreaves/rig/cmd/rig/rigdata"
+)
+
+// sseServer serves events as the SSE stream of environment "env-1".
+func sseServer(t *testing.T, events ...string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/environments/env-1/events" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i, data := range events {
+			fmt.Fprintf(w, "id: %d\nevent: x\ndata: %s\n\n", i+1, data)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+var watchStream = []string{
+	`{"type":"service.starting","service":"api","timestamp":"2026-01-01T00:00:00Z"}`,
+	`{"type":"service.starting","service":"api~proxy~~test","timestamp":"2026-01-01T00:00:00.1Z"}`,
+	`{"type":"environment.up","timestamp":"2026-01-01T00:00:01Z"}`,
+	`{"type":"request.completed","timestamp":"2026-01-01T00:00:01.5Z","request":{"source":"~test","target":"api","method":"GET","path":"/orders","status_code":200,"latency_ms":3.2}}`,
+	`{"type":"grpc.call.completed","timestamp":"2026-01-01T00:00:02Z","grpc_call":{"source":"api","target":"db","service":"pkg.DB","method":"Get","grpc_status":"0","latency_ms":1.1}}`,
+}
+
+func TestWatchRendersStream(t *testing.T) {
+	ts := sseServer(t, append(watchStream,
+		`{"type":"artifact.retry","artifact":"docker:redis:7","error":"attempt 1 of 3 failed, retrying in 1s: connection reset","timestamp":"2026-01-01T00:00:02.5Z"}`,
+		`{"type":"fault.injected","fault":{"source":"api","target":"db","kind":"latency","delay_ms":50,"method":"GET","path":"/rows"},"timestamp":"2026-01-01T00:00:02.6Z"}`,
+		`{"type":"service.restarted","service":"api","error":"exit status 2","exit_code":2,"message":"restart 1 of 1","timestamp":"2026-01-01T00:00:02.7Z"}`,
+		`{"type":"service.failed","service":"api","error":"exit status 1","timestamp":"2026-01-01T00:00:03Z"}`,
+		`{"type":"environment.down","message":"service api crashed","outcome":"crashed","timestamp":"2026-01-01T00:00:03.1Z"}`,
+	)...)
+
+	var buf bytes.Buffer
+	err := newWatcher(&buf, rigdata.TrafficFilter{}).watch(context.Background(), ts.URL, "env-1")
+	if err == nil || !strings.Contains(err.Error(), "crashed") {
+		t.Errorf("err = %v, want crashed", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"service.starting",
+		"environment.up",
+		"~test → api",
+		"/orders",
+		"pkg.DB/Get",
+		"api → db: +50.0ms GET /rows",
+		"api: restart 1 of 1 (exit 2)",
+		"api: exit status 1",
+		"docker:redis:7: attempt 1 of 3 failed",
+		"environment crashed",
+		"service api crashed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "api~proxy~~test") {
+		t.Errorf("output should skip injected nodes:\n%s", out)
+	}
+}
+
+func TestWatchFilter(t *testing.T) {
+	ts := sseServer(t, append(watchStream,
+		`{"type":"environment.down","outcome":"passed","timestamp":"2026-01-01T00:00:03Z"}`,
+	)...)
+
+	var buf bytes.Buffer
+	w := newWatcher(&buf, rigdata.TrafficFilter{Protocol: "grpc"})
+	if err := w.watch(context.Background(), ts.URL, "env-1"); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "/orders") {
+		t.Errorf("grpc filter kept HTTP row:\n%s", out)
+	}
+	// Rows keep their unfiltered index so they match rig traffic --detail.
+	if !strings.Contains(out, "2     ") || !strings.Contains(out, "pkg.DB/Get") {
+		t.Errorf("grpc row missing or misnumbered:\n%s", out)
+	}
+}
+
+func TestWatchStreamClosedEarly(t *testing.T) {
+	ts := sseServer(t, watchStream...)
+
+	var buf bytes.Buffer
+	err := newWatcher(&buf, rigdata.TrafficFilter{}).watch(context.Background(), ts.URL, "env-1")
+	if err == nil || !strings.Contains(err.Error(), "before environment.down") {
+		t.Errorf("err = %v, want stream closed error", err)
+	}
+}
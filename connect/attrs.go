@@ -1,6 +1,9 @@
 package connect
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Attr is a typed attribute key for use with Endpoint.Attributes.
 // The type parameter T indicates the expected value type.
@@ -47,6 +50,22 @@ var (
 	PGDatabase = Attr[string]("PGDATABASE")
 )
 
+// PGDatabaseNamed returns the attribute holding the actual name of a logical
+// database declared with rig.Postgres().Databases, e.g. "PGDATABASE_ORDERS"
+// for "orders".
+func PGDatabaseNamed(name string) Attr[string] {
+	return Attr[string](string(PGDatabase) + "_" + strings.ToUpper(name))
+}
+
+// Well-known MySQL attributes.
+var (
+	MySQLHost     = Attr[string]("MYSQL_HOST")
+	MySQLPort     = Attr[string]("MYSQL_PORT")
+	MySQLUser     = Attr[string]("MYSQL_USER")
+	MySQLPassword = Attr[string]("MYSQL_PASSWORD")
+	MySQLDatabase = Attr[string]("MYSQL_DATABASE")
+)
+
 // Well-known Temporal attributes.
 var (
 	TemporalAddress   = Attr[string]("TEMPORAL_ADDRESS")
@@ -55,7 +74,26 @@ var (
 
 // Well-known Redis attributes.
 var (
-	RedisURL = Attr[string]("REDIS_URL")
+	RedisURL  = Attr[string]("REDIS_URL")
+	RedisHost = Attr[string]("REDIS_HOST")
+	RedisPort = Attr[string]("REDIS_PORT")
+)
+
+// Well-known Kafka attributes.
+var (
+	// KafkaBrokers is the comma-separated bootstrap server list.
+	KafkaBrokers = Attr[string]("KAFKA_BROKERS")
+)
+
+// Well-known NATS attributes.
+var (
+	NATSURL = Attr[string]("NATS_URL")
+)
+
+// Well-known MongoDB attributes.
+var (
+	MongoURI      = Attr[string]("MONGO_URI")
+	MongoDatabase = Attr[string]("MONGO_DATABASE")
 )
 
 // Well-known S3 attributes.
@@ -77,6 +115,11 @@ var (
 var (
 	// Secure indicates the endpoint requires TLS or equivalent.
 	Secure = Attr[bool]("SECURE")
+
+	// TLSCertFile is the path of the PEM certificate a SECURE endpoint
+	// presents, set when rig's observe proxy terminates TLS for it. Trust
+	// it to verify the connection.
+	TLSCertFile = Attr[string]("TLS_CERT_FILE")
 )
 
 // PostgresDSN builds a Postgres connection string from endpoint attributes.
@@ -89,3 +132,61 @@ func PostgresDSN(ep Endpoint) string {
 	db, _ := PGDatabase.Get(ep)
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", user, pass, host, port, db)
 }
+
+// MySQLDSN builds a go-sql-driver/mysql data source name from endpoint
+// attributes. Uses MYSQL_HOST/MYSQL_PORT/MYSQL_USER/MYSQL_PASSWORD/MYSQL_DATABASE
+// and parses DATE/DATETIME columns into time.Time.
+func MySQLDSN(ep Endpoint) string {
+	host, _ := MySQLHost.Get(ep)
+	port, _ := MySQLPort.Get(ep)
+	user, _ := MySQLUser.Get(ep)
+	pass, _ := MySQLPassword.Get(ep)
+	db, _ := MySQLDatabase.Get(ep)
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", user, pass, host, port, db)
+}
+
+// LookupPostgresDSN is like PostgresDSN but returns an error naming the
+// missing attributes, e.g. when the endpoint isn't a Postgres egress,
+// instead of a DSN with empty fields.
+func LookupPostgresDSN(ep Endpoint) (string, error) {
+	if err := requireAttrs(ep, PGHost, PGPort, PGUser, PGPassword, PGDatabase); err != nil {
+		return "", fmt.Errorf("postgres DSN: %w", err)
+	}
+	return PostgresDSN(ep), nil
+}
+
+// LookupMySQLDSN is like MySQLDSN but returns an error naming the missing
+// attributes instead of a DSN with empty fields.
+func LookupMySQLDSN(ep Endpoint) (string, error) {
+	if err := requireAttrs(ep, MySQLHost, MySQLPort, MySQLUser, MySQLPassword, MySQLDatabase); err != nil {
+		return "", fmt.Errorf("mysql DSN: %w", err)
+	}
+	return MySQLDSN(ep), nil
+}
+
+// LookupRedisURL returns the endpoint's REDIS_URL (e.g.
+// "redis://127.0.0.1:6379/3"), or an error if it is missing.
+func LookupRedisURL(ep Endpoint) (string, error) {
+	if err := requireAttrs(ep, RedisURL); err != nil {
+		return "", fmt.Errorf("redis URL: %w", err)
+	}
+	return RedisURL.MustGet(ep), nil
+}
+
+// requireAttrs returns an error listing the attrs missing from ep.
+func requireAttrs(ep Endpoint, attrs ...Attr[string]) error {
+	var missing []string
+	for _, a := range attrs {
+		if _, ok := a.Get(ep); !ok {
+			missing = append(missing, string(a))
+		}
+	}
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("endpoint %s has no %s attribute", ep.HostPort, missing[0])
+	default:
+		return fmt.Errorf("endpoint %s has no %s attributes", ep.HostPort, strings.Join(missing, ", "))
+	}
+}
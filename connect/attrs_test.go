@@ -1,6 +1,9 @@
 package connect
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestAttr_Get(t *testing.T) {
 	ep := Endpoint{
@@ -103,6 +106,12 @@ func TestAttr_Bool(t *testing.T) {
 	}
 }
 
+func TestPGDatabaseNamed(t *testing.T) {
+	if got := string(PGDatabaseNamed("orders")); got != "PGDATABASE_ORDERS" {
+		t.Errorf("PGDatabaseNamed(orders) = %q, want PGDATABASE_ORDERS", got)
+	}
+}
+
 func TestPostgresDSN(t *testing.T) {
 	ep := Endpoint{
 		Attributes: map[string]any{
@@ -126,3 +135,65 @@ func TestPostgresDSN_Missing(t *testing.T) {
 		t.Errorf("PostgresDSN = %q, want %q", got, want)
 	}
 }
+
+func TestLookupPostgresDSN(t *testing.T) {
+	ep := Endpoint{
+		HostPort: "127.0.0.1:5432",
+		Attributes: map[string]any{
+			"PGHOST":     "127.0.0.1",
+			"PGPORT":     "5432",
+			"PGUSER":     "postgres",
+			"PGPASSWORD": "",
+			"PGDATABASE": "testdb",
+		},
+	}
+	got, err := LookupPostgresDSN(ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "postgres://postgres:@127.0.0.1:5432/testdb?sslmode=disable"; got != want {
+		t.Errorf("LookupPostgresDSN = %q, want %q", got, want)
+	}
+
+	delete(ep.Attributes, "PGUSER")
+	delete(ep.Attributes, "PGDATABASE")
+	_, err = LookupPostgresDSN(ep)
+	if want := "postgres DSN: endpoint 127.0.0.1:5432 has no PGUSER, PGDATABASE attributes"; err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+}
+
+func TestLookupMySQLDSN_Missing(t *testing.T) {
+	_, err := LookupMySQLDSN(Endpoint{HostPort: "127.0.0.1:3306", Attributes: map[string]any{
+		"MYSQL_HOST": "127.0.0.1", "MYSQL_PORT": "3306", "MYSQL_USER": "root", "MYSQL_DATABASE": "rig_1",
+	}})
+	if want := "mysql DSN: endpoint 127.0.0.1:3306 has no MYSQL_PASSWORD attribute"; err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+}
+
+func TestLookupRedisURL(t *testing.T) {
+	got, err := LookupRedisURL(Endpoint{Attributes: map[string]any{"REDIS_URL": "redis://127.0.0.1:6379/3"}})
+	if err != nil || got != "redis://127.0.0.1:6379/3" {
+		t.Errorf("LookupRedisURL = (%q, %v), want redis://127.0.0.1:6379/3", got, err)
+	}
+	if _, err := LookupRedisURL(Endpoint{HostPort: "127.0.0.1:6379"}); err == nil || !strings.Contains(err.Error(), "no REDIS_URL attribute") {
+		t.Errorf("missing: err = %v, want one naming REDIS_URL", err)
+	}
+}
+
+func TestMySQLDSN(t *testing.T) {
+	ep := Endpoint{
+		Attributes: map[string]any{
+			"MYSQL_HOST":     "127.0.0.1",
+			"MYSQL_PORT":     "3306",
+			"MYSQL_USER":     "root",
+			"MYSQL_PASSWORD": "mysql",
+			"MYSQL_DATABASE": "rig_1",
+		},
+	}
+	want := "root:mysql@tcp(127.0.0.1:3306)/rig_1?parseTime=true"
+	if got := MySQLDSN(ep); got != want {
+		t.Errorf("MySQLDSN = %q, want %q", got, want)
+	}
+}
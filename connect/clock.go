@@ -0,0 +1,106 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FakeNowEnv is the environment variable carrying a service's injected
+// start time, formatted as RFC 3339 with nanoseconds. Set by the rig SDK
+// when a service is configured with FakeClock.
+const FakeNowEnv = "RIG_FAKE_NOW"
+
+// clockFile is the name of the file in the environment dir that holds the
+// offset applied by Advance. It is shared by every service in the
+// environment, so advancing the clock moves all fake clocks together.
+const clockFile = "rig-clock"
+
+type fakeNowKey struct{}
+
+// WithFakeNow returns a new context carrying an injected start time. Now
+// checks for this before falling back to the RIG_FAKE_NOW environment
+// variable. The rig SDK sets it automatically for Func services configured
+// with FakeClock.
+func WithFakeNow(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, fakeNowKey{}, t)
+}
+
+// Now returns the service's current time. When the service was started
+// with a fake clock, Now returns the injected time plus any offset applied
+// by Advance — the clock does not move on its own. Otherwise it returns
+// time.Now(), so production code can call Now unconditionally.
+func Now(ctx context.Context) time.Time {
+	base, ok := fakeNow(ctx)
+	if !ok {
+		return time.Now()
+	}
+	offset, _ := readClockOffset(clockDir(ctx))
+	return base.Add(offset)
+}
+
+// Advance moves every fake clock in the environment forward by d. It is
+// safe to call from hooks, services, and tests, but concurrent calls may
+// lose updates — advance from a single place.
+func Advance(ctx context.Context, d time.Duration) error {
+	dir := clockDir(ctx)
+	if dir == "" {
+		return fmt.Errorf("rig: advance clock: environment dir unknown (RIG_ENV_DIR not set)")
+	}
+	offset, err := readClockOffset(dir)
+	if err != nil {
+		return fmt.Errorf("rig: advance clock: %w", err)
+	}
+	path := filepath.Join(dir, clockFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte((offset + d).String()), 0o644); err != nil {
+		return fmt.Errorf("rig: advance clock: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rig: advance clock: %w", err)
+	}
+	return nil
+}
+
+// fakeNow returns the injected start time from the context or environment.
+func fakeNow(ctx context.Context) (time.Time, bool) {
+	if t, ok := ctx.Value(fakeNowKey{}).(time.Time); ok {
+		return t, true
+	}
+	raw := os.Getenv(FakeNowEnv)
+	if raw == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// clockDir returns the environment dir holding the shared clock offset.
+func clockDir(ctx context.Context) string {
+	if w, ok := ctx.Value(wiringKey{}).(*Wiring); ok && w != nil && w.EnvDir != "" {
+		return w.EnvDir
+	}
+	return os.Getenv("RIG_ENV_DIR")
+}
+
+// readClockOffset returns the offset recorded in dir, or zero if none has
+// been written yet.
+func readClockOffset(dir string) (time.Duration, error) {
+	if dir == "" {
+		return 0, nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, clockFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(strings.TrimSpace(string(data)))
+}
@@ -0,0 +1,58 @@
+package connect
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNow_RealClockWhenUnset(t *testing.T) {
+	t.Setenv(FakeNowEnv, "")
+	before := time.Now()
+	got := Now(context.Background())
+	if got.Before(before) || got.After(time.Now()) {
+		t.Errorf("Now = %v, want real time", got)
+	}
+}
+
+func TestNow_FakeClockFromEnv(t *testing.T) {
+	base := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	t.Setenv(FakeNowEnv, base.Format(time.RFC3339Nano))
+	t.Setenv("RIG_ENV_DIR", t.TempDir())
+
+	ctx := context.Background()
+	if got := Now(ctx); !got.Equal(base) {
+		t.Fatalf("Now = %v, want %v", got, base)
+	}
+
+	if err := Advance(ctx, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := Advance(ctx, 30*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Now(ctx), base.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("Now after advance = %v, want %v", got, want)
+	}
+}
+
+func TestNow_FakeClockFromContext(t *testing.T) {
+	t.Setenv(FakeNowEnv, "")
+	dir := t.TempDir()
+	base := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	ctx := WithFakeNow(WithWiring(context.Background(), &Wiring{EnvDir: dir}), base)
+
+	if err := Advance(ctx, 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Now(ctx), base.Add(24*time.Hour); !got.Equal(want) {
+		t.Errorf("Now = %v, want %v", got, want)
+	}
+}
+
+func TestAdvance_NoEnvDir(t *testing.T) {
+	t.Setenv("RIG_ENV_DIR", "")
+	if err := Advance(context.Background(), time.Second); err == nil {
+		t.Error("Advance without env dir: expected error")
+	}
+}
@@ -19,6 +19,9 @@ const (
 	HTTP  Protocol = "http"
 	GRPC  Protocol = "grpc"
 	Kafka Protocol = "kafka"
+	Redis Protocol = "redis"
+	NATS  Protocol = "nats"
+	Mongo Protocol = "mongo"
 )
 
 // Endpoint is a resolved service endpoint with connection helpers.
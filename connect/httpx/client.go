@@ -12,13 +12,31 @@
 package httpx
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 
 	"github.com/matgreaves/rig/connect"
 )
 
+// LabelHeader is the request header rig's traffic proxy reads to label a
+// request. The proxy strips it before forwarding and records its value as
+// the label on the request.completed event, so `rig traffic --label`
+// can find the request in a noisy capture.
+const LabelHeader = "X-Rig-Label"
+
+// TraceHeader is the request header rig's traffic proxy uses to thread
+// requests into a trace. Serve records it on each request's context, and
+// Do copies it from the request's context onto outgoing requests, so a
+// call made while handling an inbound request is linked to it in the
+// traffic log (`rig traffic --trace`).
+const TraceHeader = "X-Rig-Trace"
+
+type traceKey struct{}
+
 // Client is an HTTP client that prepends a base URL to all request paths.
 type Client struct {
 	// BaseURL is prepended to all request paths (e.g. "http://127.0.0.1:8080").
@@ -27,11 +45,30 @@ type Client struct {
 
 	// HTTP is the underlying http.Client. If nil, http.DefaultClient is used.
 	HTTP *http.Client
+
+	// Label, if set, is sent as the LabelHeader on every request that does
+	// not already carry one.
+	Label string
 }
 
-// New creates an HTTP client from a resolved endpoint.
+// New creates an HTTP client from a resolved endpoint. A SECURE endpoint
+// is reached over https; if it also advertises a TLS_CERT_FILE (set when
+// rig's observe proxy terminates TLS), that certificate is trusted.
 func New(ep connect.Endpoint) *Client {
-	return &Client{BaseURL: "http://" + ep.HostPort}
+	if secure, _ := connect.Secure.Get(ep); !secure {
+		return &Client{BaseURL: "http://" + ep.HostPort}
+	}
+	c := &Client{BaseURL: "https://" + ep.HostPort}
+	if certFile, ok := connect.TLSCertFile.Get(ep); ok {
+		if pem, err := os.ReadFile(certFile); err == nil {
+			pool := x509.NewCertPool()
+			pool.AppendCertsFromPEM(pem)
+			c.HTTP = &http.Client{Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			}}
+		}
+	}
+	return c
 }
 
 // NewClient creates an HTTP client for the given base URL string.
@@ -46,24 +83,46 @@ func (c *Client) httpClient() *http.Client {
 	return http.DefaultClient
 }
 
+// WithLabel returns a copy of c that labels its requests for correlation in
+// rig's traffic log:
+//
+//	resp, err := api.WithLabel("checkout").Post("/orders", "application/json", body)
+func (c *Client) WithLabel(label string) *Client {
+	cp := *c
+	cp.Label = label
+	return &cp
+}
+
 // Get sends a GET request to BaseURL + path.
 func (c *Client) Get(path string) (*http.Response, error) {
-	return c.httpClient().Get(c.BaseURL + path)
+	return c.send(http.MethodGet, path, "", nil)
 }
 
 // Head sends a HEAD request to BaseURL + path.
 func (c *Client) Head(path string) (*http.Response, error) {
-	return c.httpClient().Head(c.BaseURL + path)
+	return c.send(http.MethodHead, path, "", nil)
 }
 
 // Post sends a POST request to BaseURL + path.
 func (c *Client) Post(path, contentType string, body io.Reader) (*http.Response, error) {
-	return c.httpClient().Post(c.BaseURL+path, contentType, body)
+	return c.send(http.MethodPost, path, contentType, body)
+}
+
+func (c *Client) send(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.Do(req)
 }
 
 // Do sends an HTTP request. If the request URL has no host (i.e. is a
 // relative path like "/orders/1"), it is resolved against BaseURL.
-// Absolute URLs are sent as-is.
+// Absolute URLs are sent as-is. A request made with the context of an
+// inbound request handled by Serve carries its TraceHeader along.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	if req.URL.Host == "" {
 		base, err := url.Parse(c.BaseURL)
@@ -72,5 +131,17 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		}
 		req.URL = base.ResolveReference(req.URL)
 	}
+	if c.Label != "" && req.Header.Get(LabelHeader) == "" {
+		if req.Header == nil {
+			req.Header = make(http.Header)
+		}
+		req.Header.Set(LabelHeader, c.Label)
+	}
+	if trace, ok := req.Context().Value(traceKey{}).(string); ok && req.Header.Get(TraceHeader) == "" {
+		if req.Header == nil {
+			req.Header = make(http.Header)
+		}
+		req.Header.Set(TraceHeader, trace)
+	}
 	return c.httpClient().Do(req)
 }
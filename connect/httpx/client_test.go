@@ -2,11 +2,19 @@ package httpx_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/pem"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/matgreaves/rig/connect"
 	"github.com/matgreaves/rig/connect/httpx"
 )
 
@@ -161,3 +169,106 @@ func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.Header.Set(t.Header, t.Value)
 	return http.DefaultTransport.RoundTrip(req)
 }
+
+func TestWithLabel(t *testing.T) {
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(httpx.LabelHeader))
+	}))
+	defer ts.Close()
+
+	client := httpx.NewClient(ts.URL)
+	for _, c := range []*httpx.Client{client.WithLabel("checkout"), client} {
+		resp, err := c.Get("/orders")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if len(got) != 2 || got[0] != "checkout" || got[1] != "" {
+		t.Errorf("labels = %q, want [checkout \"\"]", got)
+	}
+}
+
+func TestServe_ForwardsTrace(t *testing.T) {
+	var downstreamTrace string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstreamTrace = r.Header.Get(httpx.TraceHeader)
+	}))
+	defer downstream.Close()
+	client := httpx.NewClient(downstream.URL)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- httpx.Serve(ctx, connect.Endpoint{HostPort: addr}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, "/stock", nil)
+			resp, err := client.Do(req)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			resp.Body.Close()
+		}))
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	const trace = "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"
+	var resp *http.Response
+	for range 50 {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/orders", nil)
+		req.Header.Set(httpx.TraceHeader, trace)
+		if resp, err = http.DefaultClient.Do(req); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if downstreamTrace != trace {
+		t.Errorf("downstream saw %s = %q, want %q", httpx.TraceHeader, downstreamTrace, trace)
+	}
+}
+
+func TestNew_SecureEndpoint(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ep := connect.Endpoint{
+		HostPort:   strings.TrimPrefix(ts.URL, "https://"),
+		Attributes: map[string]any{},
+	}
+	connect.Secure.Set(ep.Attributes, true)
+	connect.TLSCertFile.Set(ep.Attributes, certFile)
+
+	client := httpx.New(ep)
+	if client.BaseURL != ts.URL {
+		t.Errorf("BaseURL = %q, want %q", client.BaseURL, ts.URL)
+	}
+	resp, err := client.Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
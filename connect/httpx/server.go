@@ -31,11 +31,12 @@ func ListenAndServe(ctx context.Context, handler http.Handler) error {
 
 // Serve starts an HTTP server on the given endpoint with the provided
 // handler. It blocks until ctx is cancelled, then shuts down gracefully
-// with a 5-second timeout.
+// with a 5-second timeout. Each request's TraceHeader is kept on its
+// context for Client.Do to forward.
 func Serve(ctx context.Context, ep connect.Endpoint, handler http.Handler) error {
 	srv := &http.Server{
 		Addr:    ep.HostPort,
-		Handler: handler,
+		Handler: traceContext(handler),
 	}
 
 	errCh := make(chan error, 1)
@@ -53,3 +54,13 @@ func Serve(ctx context.Context, ep connect.Endpoint, handler http.Handler) error
 	defer cancel()
 	return srv.Shutdown(shutdownCtx)
 }
+
+// traceContext stores the inbound TraceHeader on the request context.
+func traceContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if trace := r.Header.Get(TraceHeader); trace != "" {
+			r = r.WithContext(context.WithValue(r.Context(), traceKey{}, trace))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
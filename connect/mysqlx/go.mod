@@ -0,0 +1,12 @@
+module github.com/matgreaves/rig/connect/mysqlx
+
+go 1.25.5
+
+require (
+	github.com/go-sql-driver/mysql v1.9.3
+	github.com/matgreaves/rig v0.0.0
+)
+
+require filippo.io/edwards25519 v1.1.0 // indirect
+
+replace github.com/matgreaves/rig => ../../
@@ -0,0 +1,31 @@
+// Package mysqlx provides MySQL connectivity built on rig endpoints.
+//
+// In tests, construct from a resolved environment endpoint:
+//
+//	db, err := mysqlx.Connect(env.Endpoint("db"))
+//	defer db.Close()
+//
+// In service code, construct from parsed wiring:
+//
+//	w, _ := connect.ParseWiring(ctx)
+//	db, err := mysqlx.Connect(w.Egress("db"))
+package mysqlx
+
+import (
+	"database/sql"
+
+	_ "github.com/go-sql-driver/mysql" // register "mysql" database/sql driver
+	"github.com/matgreaves/rig/connect"
+)
+
+// DSN builds a go-sql-driver/mysql data source name from endpoint attributes.
+// Uses MYSQL_HOST/MYSQL_PORT/MYSQL_USER/MYSQL_PASSWORD/MYSQL_DATABASE.
+func DSN(ep connect.Endpoint) string {
+	return connect.MySQLDSN(ep)
+}
+
+// Connect returns a *sql.DB for a rig MySQL endpoint. Like sql.Open, it
+// doesn't dial; the first query establishes the connection.
+func Connect(ep connect.Endpoint) (*sql.DB, error) {
+	return sql.Open("mysql", DSN(ep))
+}
@@ -0,0 +1,50 @@
+package mysqlx_test
+
+import (
+	"testing"
+
+	rig "github.com/matgreaves/rig/client"
+	"github.com/matgreaves/rig/connect"
+	"github.com/matgreaves/rig/connect/mysqlx"
+)
+
+func TestDSN(t *testing.T) {
+	ep := connect.Endpoint{
+		HostPort: "127.0.0.1:3306",
+		Protocol: connect.TCP,
+		Attributes: map[string]any{
+			"MYSQL_HOST":     "127.0.0.1",
+			"MYSQL_PORT":     "3306",
+			"MYSQL_USER":     "root",
+			"MYSQL_PASSWORD": "mysql",
+			"MYSQL_DATABASE": "rig_1",
+		},
+	}
+	want := "root:mysql@tcp(127.0.0.1:3306)/rig_1?parseTime=true"
+	if got := mysqlx.DSN(ep); got != want {
+		t.Errorf("DSN = %q, want %q", got, want)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	t.Parallel()
+
+	env := rig.Up(t, rig.Services{
+		"db": rig.MySQL().InitSQL("CREATE TABLE greetings (id INT PRIMARY KEY, text VARCHAR(64))",
+			"INSERT INTO greetings VALUES (1, 'hello')"),
+	})
+
+	db, err := mysqlx.Connect(env.Endpoint("db"))
+	if err != nil {
+		t.Fatalf("mysqlx.Connect: %v", err)
+	}
+	defer db.Close()
+
+	var text string
+	if err := db.QueryRow("SELECT text FROM greetings WHERE id = 1").Scan(&text); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if text != "hello" {
+		t.Errorf("text = %q, want hello", text)
+	}
+}
@@ -0,0 +1,54 @@
+package connect
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"strconv"
+)
+
+// SeedEnv is the environment variable carrying the environment's random
+// seed, as a decimal integer. Set by the rig SDK for every service when the
+// environment is started with WithSeed.
+const SeedEnv = "RIG_SEED"
+
+type seedKey struct{}
+
+// WithSeed returns a new context carrying a random seed. Seed and Rand
+// check for this before falling back to the RIG_SEED environment variable.
+// The rig SDK sets it automatically for Func services when the environment
+// has a seed.
+func WithSeed(ctx context.Context, seed int64) context.Context {
+	return context.WithValue(ctx, seedKey{}, seed)
+}
+
+// Seed returns the seed the service was started with, and false if it was
+// started without one.
+func Seed(ctx context.Context) (int64, bool) {
+	if n, ok := ctx.Value(seedKey{}).(int64); ok {
+		return n, true
+	}
+	raw := os.Getenv(SeedEnv)
+	if raw == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Rand returns a random number generator seeded with the service's seed,
+// so a seeded environment produces the same sequence on every run. Without
+// a seed it is randomly seeded, so production code can call Rand
+// unconditionally. Each call starts a new sequence from the seed: create
+// one generator and reuse it. The generator is not safe for concurrent
+// use.
+func Rand(ctx context.Context) *rand.Rand {
+	seed, ok := Seed(ctx)
+	if !ok {
+		return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	return rand.New(rand.NewPCG(uint64(seed), 0))
+}
@@ -0,0 +1,41 @@
+package connect
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSeed_FromEnv(t *testing.T) {
+	t.Setenv(SeedEnv, "42")
+	ctx := context.Background()
+	if n, ok := Seed(ctx); !ok || n != 42 {
+		t.Fatalf("Seed = %d, %v; want 42, true", n, ok)
+	}
+	a, b := Rand(ctx), Rand(ctx)
+	for range 5 {
+		if x, y := a.Uint64(), b.Uint64(); x != y {
+			t.Fatalf("seeded generators diverged: %d != %d", x, y)
+		}
+	}
+}
+
+func TestSeed_ContextOverridesEnv(t *testing.T) {
+	t.Setenv(SeedEnv, "42")
+	ctx := WithSeed(context.Background(), 7)
+	if n, ok := Seed(ctx); !ok || n != 7 {
+		t.Fatalf("Seed = %d, %v; want 7, true", n, ok)
+	}
+	if Rand(ctx).Uint64() == Rand(context.Background()).Uint64() {
+		t.Error("seeds 7 and 42 produced the same first value")
+	}
+}
+
+func TestSeed_Unset(t *testing.T) {
+	t.Setenv(SeedEnv, "")
+	if _, ok := Seed(context.Background()); ok {
+		t.Error("Seed reported a seed with RIG_SEED unset")
+	}
+	if Rand(context.Background()) == nil {
+		t.Error("Rand returned nil without a seed")
+	}
+}
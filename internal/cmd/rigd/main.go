@@ -9,10 +9,13 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/matgreaves/rig/internal/server"
+	"github.com/matgreaves/rig/internal/server/artifact"
 	"github.com/matgreaves/rig/internal/server/service"
 )
 
@@ -21,6 +24,13 @@ func main() {
 	idle := flag.Duration("idle", 5*time.Minute, "idle shutdown timeout (0 to disable)")
 	rigDir := flag.String("rig-dir", "", "rig directory (default ~/.rig)")
 	addrFileFlag := flag.String("addr-file", "", "addr file path (default {rig-dir}/rigd.addr)")
+	eventSocket := flag.String("event-socket", "", "stream all events as NDJSON to consumers of this Unix socket")
+	artifactRetries := flag.Int("artifact-retries", 3, "attempts for transient artifact failures such as image pulls")
+	artifactBackoff := flag.Duration("artifact-retry-backoff", time.Second, "wait before the first artifact retry; doubles after each")
+	artifactConcurrency := flag.Int("artifact-concurrency", artifact.DefaultConcurrency, "max artifacts (image pulls, go builds) resolved at once per environment")
+	streamLogs := flag.Bool("stream-logs", false, "append events to each environment's JSONL log as they happen, so it survives rigd being killed")
+	maxEvents := flag.Int("max-events", 0, "cap on events each environment holds in memory; past it the oldest service output and traffic are dropped (0 = no cap)")
+	portRange := flag.String("port-range", "", "ports to allocate service ingresses from, as lo-hi (default 8192-32767)")
 	flag.Parse()
 
 	if *rigDir == "" {
@@ -30,6 +40,9 @@ func main() {
 	pgPool := service.NewPostgresPool(os.Getpid())
 	defer pgPool.Close()
 
+	mysqlPool := service.NewMySQLPool(os.Getpid())
+	defer mysqlPool.Close()
+
 	redisPool := service.NewRedisPool(os.Getpid())
 	defer redisPool.Close()
 
@@ -49,22 +62,54 @@ func main() {
 	reg.Register("container", service.Container{})
 	reg.Register("client", service.Client{})
 	reg.Register("postgres", service.NewPostgres(pgPool))
+	reg.Register("mysql", service.NewMySQL(mysqlPool))
 	reg.Register("redis", service.NewRedis(redisPool))
 	reg.Register("temporal", service.NewTemporal(temporalPool))
 	reg.Register("s3", service.NewS3(s3Pool))
 	reg.Register("sqs", service.NewSQS(sqsPool))
 	reg.Register("kafka", service.Kafka{})
+	reg.Register("nats", service.NATS{})
+	reg.Register("mongo", service.Mongo{})
 	reg.Register("proxy", service.NewProxy())
+	reg.Register("external", service.External{})
+	reg.Register("balancer", service.Balancer{})
 	reg.Register("test", service.Test{})
 
+	ports := server.NewPortAllocator()
+	if *portRange != "" {
+		lo, hi, err := parsePortRange(*portRange)
+		if err == nil {
+			ports, err = server.NewPortAllocatorRange(lo, hi)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rigd: --port-range: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	s := server.NewServer(
-		server.NewPortAllocator(),
+		ports,
 		reg,
 		filepath.Join(*rigDir, "tmp"),
 		*idle,
 		*rigDir,
 	)
 
+	s.SetArtifactRetry(*artifactRetries, *artifactBackoff)
+	s.SetArtifactConcurrency(*artifactConcurrency)
+	s.SetStreamLogs(*streamLogs)
+	s.SetMaxEvents(*maxEvents)
+
+	if *eventSocket != "" {
+		sock, err := server.ListenEventSocket(*eventSocket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rigd: event socket: %v\n", err)
+			os.Exit(1)
+		}
+		defer sock.Close()
+		s.SetEventSocket(sock)
+	}
+
 	ln, err := net.Listen("tcp", *addr)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "rigd: listen: %v\n", err)
@@ -136,3 +181,18 @@ func main() {
 	defer cancel()
 	httpSrv.Shutdown(ctx)
 }
+
+// parsePortRange parses a "lo-hi" port range.
+func parsePortRange(s string) (lo, hi int, err error) {
+	l, h, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("want lo-hi, got %q", s)
+	}
+	if lo, err = strconv.Atoi(l); err != nil {
+		return 0, 0, fmt.Errorf("want lo-hi, got %q", s)
+	}
+	if hi, err = strconv.Atoi(h); err != nil {
+		return 0, 0, fmt.Errorf("want lo-hi, got %q", s)
+	}
+	return lo, hi, nil
+}
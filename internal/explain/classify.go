@@ -0,0 +1,126 @@
+package explain
+
+import "regexp"
+
+// Failure categories, set on a Report and on each diagnosed failure so
+// callers can branch on the kind of failure without matching error text.
+//
+//	category            derived from
+//	oom                 a service exit code of 137 (SIGKILL, usually the OOM
+//	                    killer), or "out of memory" in its error or stderr
+//	panic               "panic: " or "fatal error: " in a service's error or
+//	                    stderr
+//	db_error            SQL errors (SQLSTATE, "pq: ", Postgres ERROR/FATAL
+//	                    lines, constraint violations, MySQL "Error NNNN"),
+//	                    and Redis error replies
+//	timeout             HTTP 408/504, gRPC DEADLINE_EXCEEDED, a stall, or
+//	                    "timeout", "timed out", "deadline exceeded" or a
+//	                    ready check that ran out of time
+//	connection_refused  "connection refused" or ECONNREFUSED
+//	assertion           a failed test assertion with nothing more specific
+//
+// Text is matched in the order above, so a panic whose message mentions a
+// timeout is still a panic.
+const (
+	CategoryOOM               = "oom"
+	CategoryPanic             = "panic"
+	CategoryDBError           = "db_error"
+	CategoryTimeout           = "timeout"
+	CategoryConnectionRefused = "connection_refused"
+	CategoryAssertion         = "assertion"
+)
+
+// categoryPatterns maps error and stderr text to a category, first match
+// wins.
+var categoryPatterns = []struct {
+	category string
+	re       *regexp.Regexp
+}{
+	{CategoryOOM, regexp.MustCompile(`(?i)out of memory|oomkilled|cannot allocate memory`)},
+	{CategoryPanic, regexp.MustCompile(`(?m)^(panic: |fatal error: )|goroutine \d+ \[running\]`)},
+	{CategoryDBError, regexp.MustCompile(`SQLSTATE|\bpq: |^(ERROR|FATAL):  |duplicate key value|violates \w+ ?\w* constraint|relation ".*" does not exist|syntax error at or near|deadlock detected|Error \d{4} \(\w{5}\)`)},
+	{CategoryTimeout, regexp.MustCompile(`(?i)deadline exceeded|deadlineexceeded|deadline_exceeded|timed out|timeout|check failed after`)},
+	{CategoryConnectionRefused, regexp.MustCompile(`(?i)connection refused|econnrefused`)},
+}
+
+// classifyText returns the category of the first pattern any of texts
+// matches, or "".
+func classifyText(texts ...string) string {
+	for _, p := range categoryPatterns {
+		for _, t := range texts {
+			if t != "" && p.re.MatchString(t) {
+				return p.category
+			}
+		}
+	}
+	return ""
+}
+
+// classify sets the category of each failure in r and of r itself. The
+// report's category comes from its root cause, in the order Condensed
+// lists them: service failures, then a stall, then traffic errors (most
+// recent first), then assertions.
+func classify(r *Report) {
+	if r.Outcome == "passed" {
+		return
+	}
+
+	// Correlated stderr by service, and database lines by the request
+	// they were logged during.
+	stderr := make(map[string][]string)
+	during := make(map[string][]string)
+	for _, se := range r.ServiceErrors {
+		if se.Request != "" {
+			during[se.Request] = append(during[se.Request], se.Data)
+		} else {
+			stderr[se.Service] = append(stderr[se.Service], se.Data)
+		}
+	}
+
+	for i := range r.ServiceFailures {
+		sf := &r.ServiceFailures[i]
+		if sf.ExitCode != nil && *sf.ExitCode == 137 {
+			sf.Category = CategoryOOM
+			continue
+		}
+		sf.Category = classifyText(append([]string{sf.Error}, stderr[sf.Service]...)...)
+	}
+
+	for i := range r.Errors {
+		e := &r.Errors[i]
+		switch {
+		case e.Type == "redis":
+			e.Category = CategoryDBError
+		case e.Status == 408 || e.Status == 504:
+			e.Category = CategoryTimeout
+		default:
+			texts := []string{e.GRPCStatus, e.GRPCMessage, e.ResponseBody}
+			texts = append(texts, stderr[e.Target]...)
+			e.Category = classifyText(append(texts, during[e.request()]...)...)
+		}
+	}
+
+	for i := range r.Assertions {
+		r.Assertions[i].Category = CategoryAssertion
+	}
+
+	for _, sf := range r.ServiceFailures {
+		if sf.Category != "" {
+			r.Category = sf.Category
+			return
+		}
+	}
+	if r.Stall != nil {
+		r.Category = CategoryTimeout
+		return
+	}
+	for _, e := range r.Errors {
+		if e.Category != "" {
+			r.Category = e.Category
+			return
+		}
+	}
+	if len(r.Assertions) > 0 {
+		r.Category = CategoryAssertion
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -18,35 +19,57 @@ import (
 type Report struct {
 	Test            string           `json:"test"`
 	Outcome         string           `json:"outcome"`
+	Category        string           `json:"category,omitempty"` // root cause; see CategoryOOM etc.
 	DurationMs      float64          `json:"duration_ms"`
 	Services        []string         `json:"services"`
 	Assertions      []Assertion      `json:"assertions,omitempty"`
 	Errors          []TrafficError   `json:"errors,omitempty"`
 	ServiceErrors   []ServiceError   `json:"service_errors,omitempty"`
 	ServiceFailures []ServiceFailure `json:"service_failures,omitempty"`
+	ArtifactRetries []ArtifactRetry  `json:"artifact_retries,omitempty"`
 	Stall           *StallInfo       `json:"stall,omitempty"`
 	Phases          *PhaseTimings    `json:"phases,omitempty"`
+
+	// ReadyMs is how long each service took from starting to ready, from
+	// the log header. Services that never became ready are absent.
+	ReadyMs map[string]float64 `json:"ready_ms,omitempty"`
+
+	// DroppedEvents is how many service output and traffic events rigd
+	// dropped from the log to stay under its event cap. Errors and stderr
+	// from before the drop may be missing from the report.
+	DroppedEvents int `json:"dropped_events,omitempty"`
 }
 
-// Assertion is a parsed test.note assertion.
+// Assertion is a parsed test.note assertion. Field, Want, and Got are only
+// set when the note came from a structured SDK helper (e.g. env.TB().Equal,
+// env.TB().AssertStatus).
 type Assertion struct {
 	Message string `json:"message"`
 	File    string `json:"file,omitempty"`
 	Line    int    `json:"line,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Want    string `json:"want,omitempty"`
+	Got     string `json:"got,omitempty"`
+
+	Category string `json:"category,omitempty"` // always CategoryAssertion
 }
 
-// TrafficError is an HTTP 4xx/5xx or gRPC error captured by the proxy.
+// TrafficError is an HTTP 4xx/5xx, gRPC, or Redis error captured by the proxy.
 type TrafficError struct {
-	Type         string  `json:"type"`                    // "http" or "grpc"
+	Type         string  `json:"type"`                    // "http", "grpc", or "redis"
 	Source       string  `json:"source"`                  // source service
 	Target       string  `json:"target"`                  // target service
-	Method       string  `json:"method,omitempty"`        // HTTP method or gRPC method
-	Path         string  `json:"path,omitempty"`          // URL path (HTTP) or service/method (gRPC)
+	Method       string  `json:"method,omitempty"`        // HTTP method, gRPC method, or Redis command
+	Path         string  `json:"path,omitempty"`          // URL path (HTTP), service/method (gRPC), or key (Redis)
 	Status       int     `json:"status,omitempty"`        // HTTP status code
 	GRPCStatus   string  `json:"grpc_status,omitempty"`   // gRPC status code
 	GRPCMessage  string  `json:"grpc_message,omitempty"`  // gRPC status message
+	RedisError   string  `json:"redis_error,omitempty"`   // Redis error reply
 	LatencyMs    float64 `json:"latency_ms"`              // request latency
 	ResponseBody string  `json:"response_body,omitempty"` // response body (decoded)
+	Category     string  `json:"category,omitempty"`      // failure category, if classified
+
+	end time.Time // when the response was observed
 }
 
 // ServiceError is a stderr line correlated with a traffic error or service failure.
@@ -54,12 +77,24 @@ type ServiceError struct {
 	Service string `json:"service"`
 	Stream  string `json:"stream"` // "stderr"
 	Data    string `json:"data"`
+	// Request is set for database log lines (e.g. Postgres LogStatements)
+	// logged while a failing request was in flight: "POST /orders → 500".
+	Request string `json:"request,omitempty"`
 }
 
 // ServiceFailure records a service that crashed or failed to start.
 type ServiceFailure struct {
-	Service string `json:"service"`
-	Error   string `json:"error"`
+	Service  string `json:"service"`
+	Error    string `json:"error"`
+	ExitCode *int   `json:"exit_code,omitempty"` // set when the process or container exited
+	Category string `json:"category,omitempty"`  // failure category, if classified
+}
+
+// ArtifactRetry records a transient artifact failure (e.g. an image pull
+// network error) that was retried.
+type ArtifactRetry struct {
+	Artifact string `json:"artifact"`
+	Error    string `json:"error"`
 }
 
 // StallInfo captures the last progress.stall diagnostic snapshot.
@@ -86,22 +121,36 @@ type PhaseTimings struct {
 // --- Internal event types for JSONL parsing ---
 
 type logHeader struct {
-	Type       string   `json:"type"`
-	Env        string   `json:"environment"`
-	Outcome    string   `json:"outcome"`
-	Services   []string `json:"services"`
-	DurationMs float64  `json:"duration_ms"`
+	Type           string             `json:"type"`
+	Env            string             `json:"environment"`
+	Outcome        string             `json:"outcome"`
+	Services       []string           `json:"services"`
+	DurationMs     float64            `json:"duration_ms"`
+	ReadyDurations map[string]float64 `json:"ready_durations_ms"`
+	DroppedEvents  int                `json:"dropped_events"`
 }
 
 type rawEvent struct {
 	Type       string          `json:"type"`
 	Timestamp  time.Time       `json:"timestamp"`
 	Service    string          `json:"service,omitempty"`
+	Artifact   string          `json:"artifact,omitempty"`
 	Error      string          `json:"error,omitempty"`
+	ExitCode   *int            `json:"exit_code,omitempty"`
 	Log        *logEntry       `json:"log,omitempty"`
 	Request    *requestInfo    `json:"request,omitempty"`
 	GRPCCall   *grpcCallInfo   `json:"grpc_call,omitempty"`
+	Redis      *redisInfo      `json:"redis_command,omitempty"`
 	Diagnostic *diagnosticSnap `json:"diagnostic,omitempty"`
+	Assertion  *assertionInfo  `json:"assertion,omitempty"`
+}
+
+type assertionInfo struct {
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	Field string `json:"field"`
+	Want  string `json:"want"`
+	Got   string `json:"got"`
 }
 
 type logEntry struct {
@@ -131,6 +180,15 @@ type grpcCallInfo struct {
 	ResponseBodyDecoded json.RawMessage `json:"response_body_decoded,omitempty"`
 }
 
+type redisInfo struct {
+	Source     string  `json:"source"`
+	Target     string  `json:"target"`
+	Command    string  `json:"command"`
+	Key        string  `json:"key"`
+	RedisError string  `json:"redis_error"`
+	LatencyMs  float64 `json:"latency_ms"`
+}
+
 type diagnosticSnap struct {
 	StalledFor string            `json:"stalled_for"`
 	Services   []diagnosticSvc   `json:"services"`
@@ -145,6 +203,28 @@ type diagnosticSvc struct {
 // Max stderr lines kept per service during analysis.
 const maxStderrLines = 20
 
+// Max database log lines kept per service during analysis, and reported
+// per failing request.
+const (
+	maxSQLLines      = 1000
+	maxSQLPerRequest = 5
+)
+
+// sqlLogSlack is how long after a response a database log line can still
+// belong to it: the log is relayed asynchronously, so a line can arrive
+// after the response it caused.
+const sqlLogSlack = 500 * time.Millisecond
+
+// sqlLogRe matches a Postgres log message as relayed by LogStatements,
+// e.g. "ERROR:  relation \"orders\" does not exist".
+var sqlLogRe = regexp.MustCompile(`^(LOG|ERROR|FATAL|PANIC|WARNING|STATEMENT|DETAIL|HINT):  `)
+
+// timedLine is a log line and when it was logged.
+type timedLine struct {
+	at   time.Time
+	data string
+}
+
 // assertionRe matches "file.go:42: message" patterns in test.note error fields.
 var assertionRe = regexp.MustCompile(`^(.+\.go):(\d+):\s*(.*)$`)
 
@@ -185,6 +265,9 @@ func Analyze(r io.Reader) (*Report, error) {
 		Outcome:    hdr.Outcome,
 		DurationMs: hdr.DurationMs,
 		Services:   hdr.Services,
+		ReadyMs:    hdr.ReadyDurations,
+
+		DroppedEvents: hdr.DroppedEvents,
 	}
 
 	// Accumulators for single-pass analysis.
@@ -192,9 +275,12 @@ func Analyze(r io.Reader) (*Report, error) {
 		assertions      []Assertion
 		trafficErrors   []TrafficError
 		serviceFailures []ServiceFailure
+		artifactRetries []ArtifactRetry
 		stall           *StallInfo
 		// stderr lines per service, capped at maxStderrLines.
 		stderr = make(map[string][]string)
+		// Database log lines per service, capped at maxSQLLines.
+		sqlLines = make(map[string][]timedLine)
 		// Set of services that appear in service.failed events.
 		failedServices = make(map[string]bool)
 		// Services that reached the healthy state.
@@ -228,7 +314,12 @@ func Analyze(r io.Reader) (*Report, error) {
 
 		switch ev.Type {
 		case "test.note":
-			assertions = append(assertions, parseAssertion(ev.Error))
+			a := parseAssertion(ev.Error)
+			if s := ev.Assertion; s != nil {
+				a.File, a.Line, a.Want, a.Got = s.File, s.Line, s.Want, s.Got
+				a.Field = s.Field
+			}
+			assertions = append(assertions, a)
 
 		case "environment.up":
 			envUp = true
@@ -249,6 +340,7 @@ func Analyze(r io.Reader) (*Report, error) {
 					Path:     ev.Request.Path,
 					Status:   ev.Request.StatusCode,
 					LatencyMs: ev.Request.LatencyMs,
+					end:      ev.Timestamp,
 				}
 				te.ResponseBody = string(ev.Request.ResponseBody)
 				trafficErrors = append(trafficErrors, te)
@@ -265,6 +357,7 @@ func Analyze(r io.Reader) (*Report, error) {
 					GRPCStatus:  ev.GRPCCall.GRPCStatus,
 					GRPCMessage: ev.GRPCCall.GRPCMessage,
 					LatencyMs:   ev.GRPCCall.LatencyMs,
+					end:         ev.Timestamp,
 				}
 				if ev.GRPCCall.ResponseBodyDecoded != nil {
 					te.ResponseBody = string(ev.GRPCCall.ResponseBodyDecoded)
@@ -274,6 +367,19 @@ func Analyze(r io.Reader) (*Report, error) {
 				trafficErrors = append(trafficErrors, te)
 			}
 
+		case "redis.command.completed":
+			if !envDown && ev.Redis != nil && ev.Redis.RedisError != "" {
+				trafficErrors = append(trafficErrors, TrafficError{
+					Type:       "redis",
+					Source:     ev.Redis.Source,
+					Target:     ev.Redis.Target,
+					Method:     ev.Redis.Command,
+					Path:       ev.Redis.Key,
+					RedisError: ev.Redis.RedisError,
+					LatencyMs:  ev.Redis.LatencyMs,
+				})
+			}
+
 		case "service.log":
 			if !envDown && ev.Log != nil && ev.Log.Stream == "stderr" {
 				svc := ev.Service
@@ -287,6 +393,13 @@ func Analyze(r io.Reader) (*Report, error) {
 						copy(lines, lines[1:])
 						lines[len(lines)-1] = data
 					}
+					if sqlLogRe.MatchString(data) {
+						sl := sqlLines[svc]
+						if len(sl) == maxSQLLines {
+							sl = sl[1:]
+						}
+						sqlLines[svc] = append(sl, timedLine{at: ev.Timestamp, data: data})
+					}
 				}
 			}
 
@@ -299,8 +412,9 @@ func Analyze(r io.Reader) (*Report, error) {
 			// are consequences, not causes.
 			if !failedServices[ev.Service] {
 				serviceFailures = append(serviceFailures, ServiceFailure{
-					Service: ev.Service,
-					Error:   ev.Error,
+					Service:  ev.Service,
+					Error:    ev.Error,
+					ExitCode: ev.ExitCode,
 				})
 			}
 			failedServices[ev.Service] = true
@@ -324,6 +438,12 @@ func Analyze(r io.Reader) (*Report, error) {
 				}
 			}
 
+		case "artifact.retry":
+			artifactRetries = append(artifactRetries, ArtifactRetry{
+				Artifact: ev.Artifact,
+				Error:    ev.Error,
+			})
+
 		case "artifact.started", "artifact.completed", "artifact.cached":
 			if !ev.Timestamp.IsZero() {
 				if firstArtifact.IsZero() || ev.Timestamp.Before(firstArtifact) {
@@ -397,11 +517,14 @@ func Analyze(r io.Reader) (*Report, error) {
 	report.Assertions = assertions
 	report.Errors = trafficErrors
 	report.ServiceFailures = serviceFailures
+	report.ArtifactRetries = artifactRetries
 	report.Stall = stall
 
 	// Correlate stderr with traffic errors and failed services.
 	report.ServiceErrors = correlateServiceErrors(trafficErrors, stderr, failedServices)
+	report.ServiceErrors = append(report.ServiceErrors, correlateSQL(trafficErrors, sqlLines)...)
 
+	classify(report)
 	return report, nil
 }
 
@@ -486,6 +609,65 @@ func correlateServiceErrors(
 	return result
 }
 
+// correlateSQL pairs server errors (HTTP 5xx, gRPC errors) with the
+// database log lines logged while each request was in flight. Errors and
+// the statements that caused them are preferred; without any, the last
+// statement logged is reported, since that is the query most likely
+// behind the failure.
+func correlateSQL(errors []TrafficError, sqlLines map[string][]timedLine) []ServiceError {
+	services := make([]string, 0, len(sqlLines))
+	for svc := range sqlLines {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+
+	var result []ServiceError
+	for _, te := range errors {
+		if te.end.IsZero() || te.Type == "http" && te.Status < 500 {
+			continue
+		}
+		request := te.request()
+		start := te.end.Add(-time.Duration(te.LatencyMs * float64(time.Millisecond)))
+		stop := te.end.Add(sqlLogSlack)
+
+		for _, svc := range services {
+			var errLines []string
+			var last string
+			for _, l := range sqlLines[svc] {
+				if l.at.Before(start) || l.at.After(stop) {
+					continue
+				}
+				if strings.HasPrefix(l.data, "LOG:") {
+					last = l.data
+				} else if len(errLines) < maxSQLPerRequest {
+					errLines = append(errLines, l.data)
+				}
+			}
+			if len(errLines) == 0 && last != "" {
+				errLines = []string{last}
+			}
+			for _, data := range errLines {
+				result = append(result, ServiceError{
+					Service: svc,
+					Stream:  "stderr",
+					Data:    data,
+					Request: request,
+				})
+			}
+		}
+	}
+	return result
+}
+
+// request describes te the way ServiceError.Request does: "POST /orders →
+// 500", or "pkg.Service/Method → Internal" for gRPC.
+func (te TrafficError) request() string {
+	if te.Type == "grpc" {
+		return te.Path + " → " + te.GRPCStatus
+	}
+	return te.Method + " " + te.Path + fmt.Sprintf(" → %d", te.Status)
+}
+
 // extractErrorFingerprint tries to pull out a meaningful error string from
 // a response body. If the body is JSON with an "error" field, use that.
 // Otherwise use the first non-empty line.
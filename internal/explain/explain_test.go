@@ -314,6 +314,258 @@ func TestParseAssertion(t *testing.T) {
 	}
 }
 
+func TestAnalyzeStructuredAssertion(t *testing.T) {
+	log := `{"type":"log.header","environment":"TestOrders","outcome":"failed","services":["api"]}
+{"seq":1,"type":"test.note","error":"orders_test.go:17: not equal: want 200, got 500","assertion":{"file":"orders_test.go","line":17,"want":"200","got":"500"}}
+`
+	r, err := Analyze(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Assertions) != 1 {
+		t.Fatalf("got %d assertions, want 1", len(r.Assertions))
+	}
+	a := r.Assertions[0]
+	if a.File != "orders_test.go" || a.Line != 17 || a.Want != "200" || a.Got != "500" {
+		t.Errorf("assertion = %+v", a)
+	}
+
+	var buf bytes.Buffer
+	Pretty(&buf, r)
+	if !strings.Contains(buf.String(), "want: 200") || !strings.Contains(buf.String(), "got:  500") {
+		t.Errorf("pretty output missing want/got:\n%s", buf.String())
+	}
+}
+
+func TestAnalyzeStructuredAssertionField(t *testing.T) {
+	log := `{"type":"log.header","environment":"TestOrders","outcome":"failed","services":["api"]}
+{"seq":1,"type":"test.note","error":"expected status 200, got 500 (GET /orders)","assertion":{"file":"orders_test.go","line":21,"field":"status","want":"200","got":"500"}}
+`
+	r, err := Analyze(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Assertions) != 1 {
+		t.Fatalf("got %d assertions, want 1", len(r.Assertions))
+	}
+	if a := r.Assertions[0]; a.Field != "status" || a.Want != "200" || a.Got != "500" {
+		t.Errorf("assertion = %+v", a)
+	}
+
+	var buf bytes.Buffer
+	Pretty(&buf, r)
+	if !strings.Contains(buf.String(), "expected status 200, got 500\n") {
+		t.Errorf("pretty output missing structured field line:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "want: 200") {
+		t.Errorf("pretty output should not repeat want/got:\n%s", buf.String())
+	}
+}
+
+func TestAnalyzeReadyDurations(t *testing.T) {
+	log := `{"type":"log.header","environment":"TestOrders","outcome":"passed","services":["api","db","temporal","web"],"ready_durations_ms":{"api":310,"db":2100,"temporal":9120,"web":120}}
+{"seq":1,"type":"environment.up"}
+`
+	r, err := Analyze(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ReadyMs["temporal"] != 9120 {
+		t.Errorf("ReadyMs = %v, want temporal 9120", r.ReadyMs)
+	}
+
+	var buf bytes.Buffer
+	Pretty(&buf, r)
+	want := "  Slowest to ready:\n    temporal: 9.12s\n    db: 2.10s\n    api: 310ms\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("pretty output missing slowest services:\n%s", buf.String())
+	}
+}
+
+func TestAnalyzeRedisError(t *testing.T) {
+	log := `{"type":"log.header","environment":"TestCache","outcome":"failed","services":["api","cache"]}
+{"seq":1,"type":"environment.up"}
+{"seq":2,"type":"redis.command.completed","redis_command":{"source":"api","target":"cache","command":"GET","key":"user:1","reply_type":"bulk","latency_ms":0.3}}
+{"seq":3,"type":"redis.command.completed","redis_command":{"source":"api","target":"cache","command":"INCR","key":"user:1","reply_type":"error","redis_error":"WRONGTYPE Operation against a key holding the wrong kind of value","latency_ms":0.2}}
+`
+	r, err := Analyze(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Errors) != 1 {
+		t.Fatalf("got %d traffic errors, want 1", len(r.Errors))
+	}
+	e := r.Errors[0]
+	if e.Type != "redis" || e.Method != "INCR" || e.Path != "user:1" || !strings.HasPrefix(e.RedisError, "WRONGTYPE") {
+		t.Errorf("error = %+v", e)
+	}
+
+	if out := Condensed(r); !strings.Contains(out, "rig: Redis → cache INCR user:1: WRONGTYPE") {
+		t.Errorf("condensed output missing redis error:\n%s", out)
+	}
+}
+
+func TestAnalyzeSQLCorrelation(t *testing.T) {
+	log := `{"type":"log.header","environment":"TestOrders","outcome":"failed","services":["api","db"]}
+{"seq":1,"type":"environment.up","timestamp":"2026-03-01T10:00:00Z"}
+{"seq":2,"type":"service.log","service":"db","log":{"stream":"stderr","data":"LOG:  statement: SELECT 1"},"timestamp":"2026-03-01T10:00:01Z"}
+{"seq":3,"type":"request.completed","request":{"source":"~test","target":"api","method":"GET","path":"/health","status_code":200,"latency_ms":1},"timestamp":"2026-03-01T10:00:01.001Z"}
+{"seq":4,"type":"service.log","service":"db","log":{"stream":"stderr","data":"LOG:  statement: INSERT INTO orders (item) VALUES ('book')"},"timestamp":"2026-03-01T10:00:02.010Z"}
+{"seq":5,"type":"service.log","service":"db","log":{"stream":"stderr","data":"ERROR:  relation \"orders\" does not exist at character 13"},"timestamp":"2026-03-01T10:00:02.011Z"}
+{"seq":6,"type":"service.log","service":"db","log":{"stream":"stderr","data":"STATEMENT:  INSERT INTO orders (item) VALUES ('book')"},"timestamp":"2026-03-01T10:00:02.011Z"}
+{"seq":7,"type":"request.completed","request":{"source":"~test","target":"api","method":"POST","path":"/orders","status_code":500,"latency_ms":20},"timestamp":"2026-03-01T10:00:02.020Z"}
+{"seq":8,"type":"service.log","service":"db","log":{"stream":"stderr","data":"LOG:  statement: SELECT id FROM users WHERE id = 7"},"timestamp":"2026-03-01T10:00:03.005Z"}
+{"seq":9,"type":"request.completed","request":{"source":"~test","target":"api","method":"GET","path":"/users/7","status_code":500,"latency_ms":10},"timestamp":"2026-03-01T10:00:03.010Z"}
+{"seq":10,"type":"request.completed","request":{"source":"~test","target":"api","method":"GET","path":"/users/8","status_code":404,"latency_ms":10},"timestamp":"2026-03-01T10:00:04Z"}
+`
+	r, err := Analyze(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, se := range r.ServiceErrors {
+		if se.Request != "" {
+			got = append(got, se.Service+" | "+se.Request+" | "+se.Data)
+		}
+	}
+	want := []string{
+		// The 500 with no database error gets the last statement it ran.
+		"db | GET /users/7 → 500 | LOG:  statement: SELECT id FROM users WHERE id = 7",
+		// The 500 with one gets the error and its statement.
+		`db | POST /orders → 500 | ERROR:  relation "orders" does not exist at character 13`,
+		"db | POST /orders → 500 | STATEMENT:  INSERT INTO orders (item) VALUES ('book')",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("correlated SQL:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if out := Condensed(r); !strings.Contains(out, `rig: db stderr during POST /orders → 500: ERROR:  relation "orders" does not exist`) {
+		t.Errorf("condensed output missing SQL error:\n%s", out)
+	}
+}
+
+func TestAnalyzeArtifactRetry(t *testing.T) {
+	log := `{"type":"log.header","environment":"TestPull","outcome":"crashed","services":["db"]}
+{"seq":1,"type":"artifact.started","artifact":"docker:postgres:16"}
+{"seq":2,"type":"artifact.retry","artifact":"docker:postgres:16","error":"attempt 1 of 3 failed, retrying in 1s: docker pull postgres:16: connection reset by peer"}
+{"seq":3,"type":"artifact.failed","artifact":"docker:postgres:16","error":"docker pull postgres:16: connection reset by peer"}
+{"seq":4,"type":"service.failed","service":"db","error":"artifacts failed"}
+`
+	r, err := Analyze(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.ArtifactRetries) != 1 || r.ArtifactRetries[0].Artifact != "docker:postgres:16" {
+		t.Fatalf("artifact retries = %+v, want one for docker:postgres:16", r.ArtifactRetries)
+	}
+
+	var b strings.Builder
+	Pretty(&b, r)
+	if !strings.Contains(b.String(), "Artifact retries:") {
+		t.Errorf("pretty output missing artifact retries:\n%s", b.String())
+	}
+	if out := Condensed(r); !strings.Contains(out, "rig: artifact docker:postgres:16 retried: attempt 1 of 3") {
+		t.Errorf("condensed output missing artifact retry:\n%s", out)
+	}
+}
+
+func TestAnalyzeExitCode(t *testing.T) {
+	log := `{"type":"log.header","environment":"TestOOM","outcome":"crashed","services":["api"]}
+{"seq":1,"type":"service.failed","service":"api","error":"signal: killed","exit_code":137}
+`
+	r, err := Analyze(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.ServiceFailures) != 1 || r.ServiceFailures[0].ExitCode == nil || *r.ServiceFailures[0].ExitCode != 137 {
+		t.Fatalf("service failures = %+v, want api with exit code 137", r.ServiceFailures)
+	}
+
+	var b strings.Builder
+	Pretty(&b, r)
+	if !strings.Contains(b.String(), "api: signal: killed (exit 137)") {
+		t.Errorf("pretty output missing exit code:\n%s", b.String())
+	}
+	if out := Condensed(r); !strings.Contains(out, "rig: api failed: signal: killed (exit 137)") {
+		t.Errorf("condensed output missing exit code:\n%s", out)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"testdata/assertion_failure.jsonl", CategoryAssertion},
+		{"testdata/db_error.jsonl", CategoryDBError},
+		{"testdata/timeout.jsonl", CategoryTimeout},
+		{"testdata/connection_refused.jsonl", CategoryConnectionRefused},
+		{"testdata/panic.jsonl", CategoryPanic},
+		{"testdata/oom.jsonl", CategoryOOM},
+		{"testdata/passed.jsonl", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			r, err := AnalyzeFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Category != tt.want {
+				t.Errorf("category = %q, want %q", r.Category, tt.want)
+			}
+
+			var buf bytes.Buffer
+			if err := JSON(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != "" && !strings.Contains(buf.String(), `"category": "`+tt.want+`"`) {
+				t.Errorf("JSON output missing category %q:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestClassifyFailures(t *testing.T) {
+	r, err := AnalyzeFile("testdata/timeout.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each diagnosed failure carries its own category.
+	if len(r.Errors) != 2 || r.Errors[0].Category != CategoryTimeout || r.Errors[1].Category != CategoryTimeout {
+		t.Errorf("errors = %+v, want two timeouts", r.Errors)
+	}
+	if len(r.Assertions) != 1 || r.Assertions[0].Category != CategoryAssertion {
+		t.Errorf("assertions = %+v, want one assertion", r.Assertions)
+	}
+
+	var b strings.Builder
+	Pretty(&b, r)
+	if !strings.Contains(b.String(), "FAILED (timeout)") {
+		t.Errorf("pretty header missing category:\n%s", b.String())
+	}
+}
+
+func TestClassifyText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"fatal error: concurrent map writes", CategoryPanic},
+		{"pq: relation \"users\" does not exist", CategoryDBError},
+		{"Error 1062 (23000): Duplicate entry '1' for key 'PRIMARY'", CategoryDBError},
+		{"ingress \"default\": readiness check failed after 30s (last error: connection refused)", CategoryTimeout},
+		{"dial tcp 127.0.0.1:5432: connect: connection refused", CategoryConnectionRefused},
+		{"runtime: out of memory", CategoryOOM},
+		{"invalid argument", ""},
+	}
+	for _, tt := range tests {
+		if got := classifyText(tt.text); got != tt.want {
+			t.Errorf("classifyText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
 func TestExtractErrorFingerprint(t *testing.T) {
 	tests := []struct {
 		input string
@@ -342,3 +594,21 @@ func contains(ss []string, s string) bool {
 	}
 	return false
 }
+
+func TestAnalyzeDroppedEvents(t *testing.T) {
+	log := `{"type":"log.header","environment":"TestOrders","outcome":"passed","services":["api"],"dropped_events":1200}
+{"seq":1,"type":"environment.up"}
+`
+	r, err := Analyze(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.DroppedEvents != 1200 {
+		t.Errorf("DroppedEvents = %d, want 1200", r.DroppedEvents)
+	}
+	var buf bytes.Buffer
+	Pretty(&buf, r)
+	if !strings.Contains(buf.String(), "1200 log and traffic events dropped") {
+		t.Errorf("pretty output missing dropped events:\n%s", buf.String())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 )
 
@@ -18,9 +19,15 @@ func JSON(w io.Writer, r *Report) error {
 func Pretty(w io.Writer, r *Report) {
 	// Header line.
 	outcome := strings.ToUpper(r.Outcome)
+	if r.Category != "" {
+		outcome += " (" + r.Category + ")"
+	}
 	durStr := formatDurationMs(r.DurationMs)
 	svcs := "[" + strings.Join(r.Services, ", ") + "]"
 	fmt.Fprintf(w, "%s  %s  %s  %s\n", r.Test, outcome, durStr, svcs)
+	if r.DroppedEvents > 0 {
+		fmt.Fprintf(w, "  (%d log and traffic events dropped by the event cap; earlier errors may be missing)\n", r.DroppedEvents)
+	}
 
 	if len(r.Assertions) > 0 {
 		fmt.Fprintln(w)
@@ -31,6 +38,12 @@ func Pretty(w io.Writer, r *Report) {
 			} else {
 				fmt.Fprintf(w, "    %s\n", a.Message)
 			}
+			switch {
+			case a.Field != "":
+				fmt.Fprintf(w, "      expected %s %s, got %s\n", a.Field, a.Want, a.Got)
+			case a.Want != "" || a.Got != "":
+				fmt.Fprintf(w, "      want: %s\n      got:  %s\n", a.Want, a.Got)
+			}
 		}
 	}
 
@@ -38,7 +51,15 @@ func Pretty(w io.Writer, r *Report) {
 		fmt.Fprintln(w)
 		fmt.Fprintln(w, "  Service failures:")
 		for _, sf := range r.ServiceFailures {
-			fmt.Fprintf(w, "    %s: %s\n", sf.Service, sf.Error)
+			fmt.Fprintf(w, "    %s: %s%s\n", sf.Service, sf.Error, exitSuffix(sf.ExitCode))
+		}
+	}
+
+	if len(r.ArtifactRetries) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "  Artifact retries:")
+		for _, ar := range r.ArtifactRetries {
+			fmt.Fprintf(w, "    %s: %s\n", ar.Artifact, ar.Error)
 		}
 	}
 
@@ -55,6 +76,14 @@ func Pretty(w io.Writer, r *Report) {
 		}
 	}
 
+	if slowest := slowestReady(r.ReadyMs, 3); len(slowest) > 1 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "  Slowest to ready:")
+		for _, name := range slowest {
+			fmt.Fprintf(w, "    %s: %s\n", name, formatDurationMs(r.ReadyMs[name]))
+		}
+	}
+
 	if len(r.Errors) > 0 {
 		fmt.Fprintln(w)
 		fmt.Fprintln(w, "  Errors:")
@@ -70,6 +99,10 @@ func Pretty(w io.Writer, r *Report) {
 				if e.GRPCMessage != "" {
 					fmt.Fprintf(w, "      %s\n", e.GRPCMessage)
 				}
+			case "redis":
+				fmt.Fprintf(w, "    Redis %s %s %s (%.1fms)\n",
+					target, e.Method, e.Path, e.LatencyMs)
+				fmt.Fprintf(w, "      %s\n", e.RedisError)
 			}
 			if e.ResponseBody != "" {
 				fmt.Fprintf(w, "      %s\n", e.ResponseBody)
@@ -81,7 +114,11 @@ func Pretty(w io.Writer, r *Report) {
 		fmt.Fprintln(w)
 		fmt.Fprintln(w, "  Service stderr:")
 		for _, se := range r.ServiceErrors {
-			fmt.Fprintf(w, "    %s: %s\n", se.Service, se.Data)
+			if se.Request != "" {
+				fmt.Fprintf(w, "    %s: %s  (during %s)\n", se.Service, se.Data, se.Request)
+			} else {
+				fmt.Fprintf(w, "    %s: %s\n", se.Service, se.Data)
+			}
 		}
 	}
 }
@@ -126,7 +163,16 @@ func Condensed(r *Report) string {
 		if n >= maxFailures {
 			break
 		}
-		fmt.Fprintf(&b, "rig: %s failed: %s\n", sf.Service, sf.Error)
+		fmt.Fprintf(&b, "rig: %s failed: %s%s\n", sf.Service, sf.Error, exitSuffix(sf.ExitCode))
+		n++
+	}
+	// Retries explain slow or flaky artifact phases, e.g. an image pull
+	// that eventually gave up.
+	for _, ar := range r.ArtifactRetries {
+		if n >= maxFailures {
+			break
+		}
+		fmt.Fprintf(&b, "rig: artifact %s retried: %s\n", ar.Artifact, ar.Error)
 		n++
 	}
 
@@ -157,7 +203,7 @@ func Condensed(r *Report) string {
 		if n >= maxTrafficErrors {
 			break
 		}
-		key := fmt.Sprintf("%s:%s:%d:%s", e.Target, e.Path, e.Status, e.GRPCStatus)
+		key := fmt.Sprintf("%s:%s:%d:%s:%s", e.Target, e.Path, e.Status, e.GRPCStatus, e.RedisError)
 		if seen[key] {
 			continue
 		}
@@ -189,6 +235,9 @@ func Condensed(r *Report) string {
 				fmt.Fprintf(&b, "rig: gRPC %s %s status=%s\n",
 					target, e.Path, e.GRPCStatus)
 			}
+		case "redis":
+			fmt.Fprintf(&b, "rig: Redis %s %s %s: %s\n",
+				target, e.Method, e.Path, e.RedisError)
 		}
 		n++
 	}
@@ -199,7 +248,11 @@ func Condensed(r *Report) string {
 		if n >= maxStderr {
 			break
 		}
-		fmt.Fprintf(&b, "rig: %s stderr: %s\n", se.Service, se.Data)
+		if se.Request != "" {
+			fmt.Fprintf(&b, "rig: %s stderr during %s: %s\n", se.Service, se.Request, se.Data)
+		} else {
+			fmt.Fprintf(&b, "rig: %s stderr: %s\n", se.Service, se.Data)
+		}
 		n++
 	}
 
@@ -222,3 +275,31 @@ func formatDurationMs(ms float64) string {
 	}
 	return fmt.Sprintf("%.2fs", ms/1000)
 }
+
+// slowestReady returns up to n service names from ready, slowest first.
+// Ties are broken by name so the order is stable.
+func slowestReady(ready map[string]float64, n int) []string {
+	names := make([]string, 0, len(ready))
+	for name := range ready {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ready[names[i]] != ready[names[j]] {
+			return ready[names[i]] > ready[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > n {
+		names = names[:n]
+	}
+	return names
+}
+
+// exitSuffix renders a failure's exit code as " (exit N)", or "" if the
+// failure carried none.
+func exitSuffix(code *int) string {
+	if code == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (exit %d)", *code)
+}
@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,10 +9,12 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -26,8 +29,10 @@ import (
 	"github.com/matgreaves/rig/internal/server/service"
 	"github.com/matgreaves/rig/internal/testdata/services/echo"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"golang.org/x/net/websocket"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
@@ -75,6 +80,7 @@ func TestMain(m *testing.M) {
 	}
 
 	pgPool := service.NewPostgresPool(os.Getpid())
+	mysqlPool := service.NewMySQLPool(os.Getpid())
 	redisPool := service.NewRedisPool(os.Getpid())
 	s3Pool := service.NewS3Pool(os.Getpid())
 	sqsPool := service.NewSQSPool(os.Getpid())
@@ -88,12 +94,15 @@ func TestMain(m *testing.M) {
 	reg.Register("client", service.Client{})
 	reg.Register("container", service.Container{})
 	reg.Register("postgres", service.NewPostgres(pgPool))
+	reg.Register("mysql", service.NewMySQL(mysqlPool))
 	reg.Register("redis", service.NewRedis(redisPool))
 	reg.Register("temporal", service.NewTemporal(temporalPool))
 	reg.Register("s3", service.NewS3(s3Pool))
 	reg.Register("sqs", service.NewSQS(sqsPool))
 	reg.Register("kafka", service.Kafka{})
 	reg.Register("proxy", service.NewProxy())
+	reg.Register("external", service.External{})
+	reg.Register("balancer", service.Balancer{})
 	reg.Register("test", service.Test{})
 
 	rigDir := filepath.Join(dir, "..", ".rig")
@@ -156,6 +165,34 @@ func TestUp(t *testing.T) {
 		}
 	})
 
+	t.Run("GoServiceEnv", func(t *testing.T) {
+		t.Parallel()
+
+		env := rig.Up(t, rig.Services{
+			"echo": rig.Go(filepath.Join(root, "internal", "testdata", "services", "echo", "cmd")).
+				Env("LOG_LEVEL", "debug").
+				Env("RIG_SERVICE", "renamed").
+				FakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+		}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+		client := httpx.New(env.Endpoint("echo"))
+		for name, want := range map[string]string{
+			"LOG_LEVEL":    "debug",
+			"RIG_SERVICE":  "renamed", // test-set vars win over wiring vars
+			"RIG_FAKE_NOW": "2030-01-01T00:00:00Z",
+		} {
+			resp, err := client.Get("/env/" + name)
+			if err != nil {
+				t.Fatalf("GET /env/%s: %v", name, err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(body) != want {
+				t.Errorf("%s = %q, want %q", name, body, want)
+			}
+		}
+	})
+
 	t.Run("GoServiceRelativePath", func(t *testing.T) {
 		t.Parallel()
 
@@ -329,6 +366,79 @@ func TestUp(t *testing.T) {
 		}
 	})
 
+	t.Run("Close", func(t *testing.T) {
+		t.Parallel()
+
+		env := rig.Up(t, rig.Services{
+			"echo": rig.Func(echo.Run),
+		}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+		if err := env.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		// Closing again, and the cleanup Up registered, are no-ops.
+		if err := env.Close(); err != nil {
+			t.Errorf("second Close: %v", err)
+		}
+
+		o, err := env.Outcome()
+		if err != nil {
+			t.Fatalf("Outcome: %v", err)
+		}
+		if o.LogFile == "" || o.Status != "passed" {
+			t.Errorf("outcome after Close = %+v, want passed with a log file", o)
+		}
+		resp, err := http.Get(serverURL + "/environments/" + env.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET environment after Close: %d, want 404", resp.StatusCode)
+		}
+	})
+
+	t.Run("StartWaitReady", func(t *testing.T) {
+		t.Parallel()
+
+		// Both environments start before either is waited on. The hook
+		// runs in the background, so it can hold the first environment
+		// back until the second has started too.
+		release := make(chan struct{})
+		first := rig.Start(t, rig.Services{
+			"echo": rig.Func(echo.Run).
+				InitHook(func(ctx context.Context, w rig.Wiring) error {
+					select {
+					case <-release:
+						return nil
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}),
+		}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+		second := rig.Start(t, rig.Services{
+			"echo": rig.Func(echo.Run),
+		}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+		short, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		if err := first.WaitReady(short); err != context.DeadlineExceeded {
+			t.Fatalf("WaitReady while hook blocks = %v, want DeadlineExceeded", err)
+		}
+		close(release)
+
+		for _, env := range []*rig.Environment{first, second} {
+			if err := env.WaitReady(context.Background()); err != nil {
+				t.Fatalf("WaitReady: %v", err)
+			}
+			resp, err := httpx.New(env.Endpoint("echo")).Get("/health")
+			if err != nil {
+				t.Fatalf("health check: %v", err)
+			}
+			resp.Body.Close()
+		}
+	})
+
 	t.Run("FuncServiceWithEgress", func(t *testing.T) {
 		t.Parallel()
 
@@ -386,6 +496,41 @@ func TestUp(t *testing.T) {
 		}
 	})
 
+	t.Run("InitHookOutputs", func(t *testing.T) {
+		t.Parallel()
+
+		// The auth service's init hook publishes a value; api, which
+		// depends on auth, sees it on its egress before it starts.
+		apiKey := make(chan string, 1)
+		env := rig.Up(t, rig.Services{
+			"auth": rig.Func(echo.Run).
+				InitHook(func(ctx context.Context, w rig.Wiring) error {
+					rig.SetOutput(ctx, "JWT_KEY", "k$y-123")
+					return nil
+				}),
+			"api": rig.Func(func(ctx context.Context) error {
+				w, err := connect.ParseWiring(ctx)
+				if err != nil {
+					return err
+				}
+				apiKey <- w.Egress("auth").Attr("JWT_KEY")
+				return echo.Run(ctx)
+			}).Egress("auth"),
+		}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+		select {
+		case got := <-apiKey:
+			if got != "k$y-123" {
+				t.Errorf("api egress JWT_KEY = %q, want %q", got, "k$y-123")
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("api did not start")
+		}
+		if got := env.Endpoint("auth").Attr("JWT_KEY"); got != "k$y-123" {
+			t.Errorf("test endpoint JWT_KEY = %q, want %q", got, "k$y-123")
+		}
+	})
+
 	t.Run("Container", func(t *testing.T) {
 		t.Parallel()
 
@@ -886,6 +1031,122 @@ func TestUp(t *testing.T) {
 		conn.Close()
 	})
 
+	t.Run("MySQL", func(t *testing.T) {
+		t.Parallel()
+
+		env := rig.Up(t, rig.Services{
+			"db": rig.MySQL(),
+		}, rig.WithServer(serverURL), rig.WithTimeout(180*time.Second))
+
+		ep := env.Endpoint("db")
+
+		conn, err := net.DialTimeout("tcp", ep.HostPort, 5*time.Second)
+		if err != nil {
+			t.Fatalf("mysql dial: %v", err)
+		}
+		conn.Close()
+
+		if got := ep.Attr("MYSQL_DATABASE"); !strings.HasPrefix(got, "rig_") {
+			t.Errorf("MYSQL_DATABASE = %q, want rig_*", got)
+		}
+		if got := ep.Attr("MYSQL_USER"); got != "root" {
+			t.Errorf("MYSQL_USER = %q, want root", got)
+		}
+		if got := ep.Attr("MYSQL_PASSWORD"); got != "mysql" {
+			t.Errorf("MYSQL_PASSWORD = %q, want mysql", got)
+		}
+		if got := ep.Attr("MYSQL_HOST"); got != "127.0.0.1" {
+			t.Errorf("MYSQL_HOST = %q, want 127.0.0.1", got)
+		}
+		if got := ep.Attr("MYSQL_PORT"); got == "" {
+			t.Error("MYSQL_PORT is empty")
+		}
+	})
+
+	t.Run("MySQLInitSQL_BadSQL", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := rig.TryUp(t, rig.Services{
+			"db": rig.MySQL().InitSQL("INSERT INTO nonexistent_table VALUES (1)"),
+		}, rig.WithServer(serverURL), rig.WithTimeout(180*time.Second))
+		if err == nil {
+			t.Fatal("expected Up to fail due to bad SQL")
+		}
+
+		t.Logf("captured failure: %s", err)
+	})
+
+	t.Run("MySQLInitSQL", func(t *testing.T) {
+		t.Parallel()
+
+		// The INSERT only succeeds if the CREATE ran first against the
+		// same per-test database.
+		env := rig.Up(t, rig.Services{
+			"db": rig.MySQL().InitSQL(
+				"CREATE TABLE test_init (id INT PRIMARY KEY, name TEXT NOT NULL)",
+				"INSERT INTO test_init VALUES (1, 'hello')",
+			),
+		}, rig.WithServer(serverURL), rig.WithTimeout(180*time.Second))
+
+		ep := env.Endpoint("db")
+		conn, err := net.DialTimeout("tcp", ep.HostPort, 5*time.Second)
+		if err != nil {
+			t.Fatalf("mysql dial: %v", err)
+		}
+		conn.Close()
+	})
+
+	t.Run("RedisInitCommands_BadCommand", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := rig.TryUp(t, rig.Services{
+			"cache": rig.Redis().InitCommands("NOTACOMMAND foo"),
+		}, rig.WithServer(serverURL), rig.WithTimeout(120*time.Second))
+		if err == nil {
+			t.Fatal("expected Up to fail due to bad command")
+		}
+
+		t.Logf("captured failure: %s", err)
+	})
+
+	t.Run("RedisInitCommands", func(t *testing.T) {
+		t.Parallel()
+
+		env := rig.Up(t, rig.Services{
+			"cache": rig.Redis().InitCommands("SET greeting 'hello world'"),
+		}, rig.WithServer(serverURL), rig.WithTimeout(120*time.Second))
+
+		ep := env.Endpoint("cache")
+		if ep.Attr("REDIS_HOST") == "" || ep.Attr("REDIS_PORT") == "" {
+			t.Errorf("REDIS_HOST/REDIS_PORT not set: %v", ep.Attributes)
+		}
+
+		// Read the key back from this environment's database using
+		// inline commands.
+		u, err := url.Parse(ep.Attr("REDIS_URL"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := net.DialTimeout("tcp", ep.HostPort, 5*time.Second)
+		if err != nil {
+			t.Fatalf("redis dial: %v", err)
+		}
+		defer conn.Close()
+		fmt.Fprintf(conn, "SELECT %s\r\nGET greeting\r\n", strings.TrimPrefix(u.Path, "/"))
+		r := bufio.NewReader(conn)
+		var lines []string
+		for range 3 { // +OK, $11, hello world
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read reply: %v", err)
+			}
+			lines = append(lines, strings.TrimRight(line, "\r\n"))
+		}
+		if lines[2] != "hello world" {
+			t.Errorf("GET greeting replies = %q, want hello world", lines)
+		}
+	})
+
 	t.Run("UserAPI", func(t *testing.T) {
 		t.Parallel()
 
@@ -956,6 +1217,52 @@ func TestUp(t *testing.T) {
 		}
 	})
 
+	t.Run("ContainerHealthStatus", func(t *testing.T) {
+		t.Parallel()
+
+		// nginx serves 404 for unknown paths: requiring that status on a
+		// missing path passes the ready check...
+		rig.Up(t, rig.Services{
+			"nginx": rig.Container("nginx:alpine").Port(80).
+				HealthPath("/missing").HealthStatus(http.StatusNotFound),
+		}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+		// ...while requiring it on "/", which answers 200, never does.
+		_, err := rig.TryUp(t, rig.Services{
+			"nginx": rig.Container("nginx:alpine").Port(80).
+				HealthStatus(http.StatusNotFound).
+				Timeout(3 * time.Second),
+		}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+		if err == nil {
+			t.Fatal("expected Up to fail: / answers 200, want 404")
+		}
+		if !strings.Contains(err.Error(), "HTTP 200, want 404") {
+			t.Errorf("error does not mention the status mismatch: %v", err)
+		}
+	})
+
+	t.Run("ContainerDockerHealthcheckMissing", func(t *testing.T) {
+		t.Parallel()
+
+		// nginx:alpine declares no HEALTHCHECK, so opting in to it can never
+		// become ready: Up should fail straight away rather than time out.
+		start := time.Now()
+		_, err := rig.TryUp(t, rig.Services{
+			"nginx": rig.Container("nginx:alpine").Port(80).
+				UseDockerHealthcheck().
+				Timeout(30 * time.Second),
+		}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+		if err == nil {
+			t.Fatal("expected Up to fail: image has no HEALTHCHECK")
+		}
+		if !strings.Contains(err.Error(), "UseDockerHealthcheck") {
+			t.Errorf("error does not tell the user to remove the option: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 25*time.Second {
+			t.Errorf("Up took %s, want it to fail fast", elapsed)
+		}
+	})
+
 	t.Run("ContainerExecHook", func(t *testing.T) {
 		t.Parallel()
 
@@ -1071,6 +1378,27 @@ func TestUp(t *testing.T) {
 		}
 	})
 
+	t.Run("StartupBudget", func(t *testing.T) {
+		t.Parallel()
+
+		// The init hook makes startup slower than the budget but well
+		// within the timeout, so Up succeeds as far as the server is
+		// concerned and the client reports the overrun.
+		_, err := rig.TryUp(t, rig.Services{
+			"echo": rig.Func(echo.Run).
+				InitHook(func(ctx context.Context, w rig.Wiring) error {
+					time.Sleep(500 * time.Millisecond)
+					return nil
+				}),
+		}, rig.WithServer(serverURL), rig.WithStartupBudget(100*time.Millisecond))
+		if err == nil {
+			t.Fatal("expected Up to fail due to startup budget")
+		}
+		if !strings.Contains(err.Error(), "startup budget of 100ms") {
+			t.Errorf("error does not mention budget: %v", err)
+		}
+	})
+
 	t.Run("ServiceCrash", func(t *testing.T) {
 		t.Parallel()
 
@@ -1149,6 +1477,56 @@ func TestWrappedTB(t *testing.T) {
 	}
 }
 
+// TestArtifactsResolveConcurrently verifies that distinct go builds run in
+// parallel: with a fresh artifact cache, both builds start before either
+// completes.
+func TestArtifactsResolveConcurrently(t *testing.T) {
+	t.Parallel()
+	root := repoRoot(t)
+
+	reg := service.NewRegistry()
+	reg.Register("go", service.Go{})
+	reg.Register("proxy", service.NewProxy())
+	reg.Register("test", service.Test{})
+	s := server.NewServer(server.NewPortAllocator(), reg, t.TempDir(), 0, t.TempDir())
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	env := rig.Up(t, rig.Services{
+		"api": rig.Go(filepath.Join(root, "internal", "testdata", "services", "echo", "cmd")),
+		"db": rig.Go(filepath.Join(root, "internal", "testdata", "services", "tcpecho")).
+			Ingress("default", rig.IngressTCP()),
+	}, rig.WithServer(ts.URL), rig.WithTimeout(120*time.Second))
+
+	logResp, err := http.Get(fmt.Sprintf("%s/environments/%s/log", ts.URL, env.ID))
+	if err != nil {
+		t.Fatalf("fetch log: %v", err)
+	}
+	defer logResp.Body.Close()
+
+	var events []struct {
+		Type     string `json:"type"`
+		Artifact string `json:"artifact"`
+	}
+	if err := json.NewDecoder(logResp.Body).Decode(&events); err != nil {
+		t.Fatalf("decode log: %v", err)
+	}
+
+	started := 0
+	for _, e := range events {
+		switch e.Type {
+		case "artifact.started":
+			started++
+		case "artifact.completed":
+			if started < 2 {
+				t.Fatalf("artifact %s completed after %d build(s) started, want both builds in flight", e.Artifact, started)
+			}
+			return
+		}
+	}
+	t.Fatalf("no artifact.completed event (%d started)", started)
+}
+
 // TestObserve verifies that observe mode (on by default) inserts transparent
 // traffic proxies and captures request events in the event log.
 func TestObserve(t *testing.T) {
@@ -1235,6 +1613,161 @@ func TestObserve(t *testing.T) {
 
 }
 
+// TestScale verifies that a scaled service runs one instance per copy and
+// that connections to it are spread across them, with observed traffic
+// naming the instance that served it.
+func TestScale(t *testing.T) {
+	t.Parallel()
+	serverURL := sharedServerURL
+
+	env := rig.Up(t, rig.Services{
+		"backend": rig.Func(echo.Run).Scale(2),
+	}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+	// A fresh connection per request, so each is balanced.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	for range 4 {
+		resp, err := client.Get("http://" + env.Endpoint("backend").HostPort + "/hello")
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status: %d, want 200", resp.StatusCode)
+		}
+	}
+
+	// Instances are addressable directly too.
+	resp, err := httpx.New(env.Endpoint("backend-1")).Get("/direct")
+	if err != nil {
+		t.Fatalf("direct request: %v", err)
+	}
+	resp.Body.Close()
+
+	logResp, err := http.Get(fmt.Sprintf("%s/environments/%s/traffic?edge=~test&protocol=http", serverURL, env.ID))
+	if err != nil {
+		t.Fatalf("query traffic: %v", err)
+	}
+	defer logResp.Body.Close()
+	var page struct {
+		Events []struct {
+			Request *struct {
+				Target string `json:"target"`
+				Path   string `json:"path"`
+			} `json:"request"`
+		} `json:"events"`
+	}
+	if err := json.NewDecoder(logResp.Body).Decode(&page); err != nil {
+		t.Fatalf("decode traffic: %v", err)
+	}
+	hello := map[string]int{}
+	direct := 0
+	for _, e := range page.Events {
+		switch e.Request.Path {
+		case "/hello":
+			hello[e.Request.Target]++
+		case "/direct":
+			if e.Request.Target != "backend-1" {
+				t.Errorf("/direct served by %q, want backend-1", e.Request.Target)
+			}
+			direct++
+		}
+	}
+	if hello["backend-0"] != 2 || hello["backend-1"] != 2 {
+		t.Errorf("/hello requests per instance = %v, want 2 each", hello)
+	}
+	if direct != 1 {
+		t.Errorf("/direct requests = %d, want 1", direct)
+	}
+}
+
+// TestTrafficQuery verifies GET /environments/{id}/traffic filters the
+// captured traffic and pages through it.
+func TestTrafficQuery(t *testing.T) {
+	t.Parallel()
+	serverURL := sharedServerURL
+
+	env := rig.Up(t, rig.Services{
+		"backend": rig.Func(echo.Run),
+		"api":     rig.Func(echo.Run).EgressAs("backend", "backend"),
+	}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+	client := httpx.New(env.Endpoint("api"))
+	for range 3 {
+		resp, err := client.Get("/hello")
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	type page struct {
+		Events []struct {
+			Seq     uint64 `json:"seq"`
+			Request *struct {
+				Source string `json:"source"`
+				Target string `json:"target"`
+				Path   string `json:"path"`
+			} `json:"request"`
+		} `json:"events"`
+		More bool `json:"more"`
+	}
+	query := func(q string) page {
+		t.Helper()
+		resp, err := http.Get(fmt.Sprintf("%s/environments/%s/traffic?%s", serverURL, env.ID, q))
+		if err != nil {
+			t.Fatalf("query traffic: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("query %q: status %d", q, resp.StatusCode)
+		}
+		var p page
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+			t.Fatalf("decode traffic: %v", err)
+		}
+		return p
+	}
+
+	// Ready polling also goes through the proxy, so select by path.
+	var hello []uint64
+	after := uint64(0)
+	for {
+		p := query(fmt.Sprintf("edge=~test->api&protocol=http&limit=2&after_seq=%d", after))
+		for _, e := range p.Events {
+			if e.Request == nil || e.Request.Source != "~test" || e.Request.Target != "api" {
+				t.Fatalf("event %d doesn't match edge: %+v", e.Seq, e.Request)
+			}
+			if e.Request.Path == "/hello" {
+				hello = append(hello, e.Seq)
+			}
+			after = e.Seq
+		}
+		if !p.More {
+			break
+		}
+		if len(p.Events) != 2 {
+			t.Fatalf("page with more has %d events, want 2", len(p.Events))
+		}
+	}
+	if len(hello) != 3 {
+		t.Errorf("~test→api /hello requests = %d, want 3", len(hello))
+	}
+
+	if p := query("status=5xx"); len(p.Events) != 0 {
+		t.Errorf("status=5xx matched %d events, want 0", len(p.Events))
+	}
+
+	resp, err := http.Get(fmt.Sprintf("%s/environments/%s/traffic?protocol=smtp", serverURL, env.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("protocol=smtp: status %d, want 400", resp.StatusCode)
+	}
+}
+
 // TestObserveAttributes verifies that the observe proxy rewrites
 // address-derived endpoint attributes (TEMPORAL_ADDRESS) so that tools
 // reading env vars go through the proxy, not the real service.
@@ -1337,6 +1870,79 @@ func TestObserveTCP(t *testing.T) {
 	}
 }
 
+// TestObserveWebSocket verifies that websocket upgrades are relayed by the
+// HTTP observe proxy instead of hanging, and that the connection is logged.
+func TestObserveWebSocket(t *testing.T) {
+	t.Parallel()
+	serverURL := sharedServerURL
+
+	env := rig.Up(t, rig.Services{
+		"chat": rig.Func(func(ctx context.Context) error {
+			mux := http.NewServeMux()
+			mux.Handle("/ws", websocket.Handler(func(ws *websocket.Conn) {
+				io.Copy(ws, ws)
+			}))
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+			return httpx.ListenAndServe(ctx, mux)
+		}),
+	}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+	ep := env.Endpoint("chat")
+	ws, err := websocket.Dial("ws://"+ep.HostPort+"/ws", "", "http://"+ep.HostPort)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if err := websocket.Message.Send(ws, "hello"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	var reply string
+	if err := websocket.Message.Receive(ws, &reply); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if reply != "hello" {
+		t.Errorf("reply = %q, want hello", reply)
+	}
+	ws.Close()
+
+	// Give the proxy a moment to emit the websocket.closed event.
+	time.Sleep(100 * time.Millisecond)
+
+	logResp, err := http.Get(fmt.Sprintf("%s/environments/%s/log", serverURL, env.ID))
+	if err != nil {
+		t.Fatalf("fetch log: %v", err)
+	}
+	defer logResp.Body.Close()
+
+	var events []struct {
+		Type      string `json:"type"`
+		WebSocket *struct {
+			Path     string `json:"path"`
+			FramesIn int64  `json:"frames_in"`
+		} `json:"websocket,omitempty"`
+	}
+	if err := json.NewDecoder(logResp.Body).Decode(&events); err != nil {
+		t.Fatalf("decode log: %v", err)
+	}
+
+	var opened, closed int
+	for _, e := range events {
+		switch e.Type {
+		case "websocket.opened":
+			opened++
+		case "websocket.closed":
+			if e.WebSocket != nil && e.WebSocket.Path == "/ws" && e.WebSocket.FramesIn > 0 {
+				closed++
+			}
+		}
+	}
+	if opened != 1 {
+		t.Errorf("websocket.opened events: got %d, want 1", opened)
+	}
+	if closed != 1 {
+		t.Errorf("websocket.closed events (with frames): got %d, want 1", closed)
+	}
+}
+
 // TestObserveGRPC verifies that gRPC proxy captures grpc.call.completed events
 // with correct service, method, and status fields.
 func TestObserveGRPC(t *testing.T) {
@@ -1429,6 +2035,149 @@ func TestObserveGRPC(t *testing.T) {
 	}
 }
 
+// TestObserveFuncGRPC verifies that a Func service can serve gRPC: the
+// server's ready check uses the gRPC health protocol and traffic through
+// the proxy is decoded into grpc.call.completed events.
+func TestObserveFuncGRPC(t *testing.T) {
+	t.Parallel()
+	serverURL := sharedServerURL
+
+	var serving atomic.Bool
+	env := rig.Up(t, rig.Services{
+		"greeter": rig.Func(func(ctx context.Context) error {
+			w, err := connect.ParseWiring(ctx)
+			if err != nil {
+				return err
+			}
+			ln, err := net.Listen("tcp", w.Ingress().HostPort)
+			if err != nil {
+				return err
+			}
+			// Report NOT_SERVING until shortly after startup so the test
+			// can tell a health probe from a bare TCP or HTTP check.
+			hs := health.NewServer()
+			hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+			time.AfterFunc(300*time.Millisecond, func() {
+				serving.Store(true)
+				hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+			})
+			srv := grpc.NewServer()
+			healthpb.RegisterHealthServer(srv, hs)
+			go func() {
+				<-ctx.Done()
+				srv.Stop()
+			}()
+			return srv.Serve(ln)
+		}).Ingress("default", rig.IngressGRPC()),
+	}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+	if !serving.Load() {
+		t.Error("environment came up before the gRPC health check reported SERVING")
+	}
+
+	conn, err := grpc.NewClient(env.Endpoint("greeter").HostPort,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("grpc health check: %v", err)
+	}
+
+	// Give the proxy a moment to emit the event.
+	time.Sleep(200 * time.Millisecond)
+
+	logResp, err := http.Get(fmt.Sprintf("%s/environments/%s/log", serverURL, env.ID))
+	if err != nil {
+		t.Fatalf("fetch log: %v", err)
+	}
+	defer logResp.Body.Close()
+
+	var events []struct {
+		Type     string `json:"type"`
+		GRPCCall *struct {
+			Source     string `json:"source"`
+			Target     string `json:"target"`
+			Service    string `json:"service"`
+			Method     string `json:"method"`
+			GRPCStatus string `json:"grpc_status"`
+		} `json:"grpc_call,omitempty"`
+	}
+	if err := json.NewDecoder(logResp.Body).Decode(&events); err != nil {
+		t.Fatalf("decode log: %v", err)
+	}
+
+	var found bool
+	for _, e := range events {
+		if e.Type != "grpc.call.completed" || e.GRPCCall == nil {
+			continue
+		}
+		g := e.GRPCCall
+		if g.Source == "~test" && g.Target == "greeter" && g.Service == "grpc.health.v1.Health" && g.Method == "Check" {
+			found = true
+			if g.GRPCStatus != "OK" {
+				t.Errorf("grpc_status = %q, want OK", g.GRPCStatus)
+			}
+		}
+	}
+	if !found {
+		t.Error("no grpc.call.completed event from ~test to greeter for grpc.health.v1.Health/Check")
+	}
+}
+
+// TestFuncNamedIngresses verifies that a Func service can serve several
+// ingresses: it reads each port from its wiring, each is ready-checked with
+// its own protocol's probe, and each is reachable from the test.
+func TestFuncNamedIngresses(t *testing.T) {
+	t.Parallel()
+	serverURL := sharedServerURL
+
+	env := rig.Up(t, rig.Services{
+		"api": rig.Func(func(ctx context.Context) error {
+			w, err := connect.ParseWiring(ctx)
+			if err != nil {
+				return err
+			}
+			grpcLn, err := net.Listen("tcp", w.Ingress("grpc").HostPort)
+			if err != nil {
+				return err
+			}
+			srv := grpc.NewServer()
+			healthpb.RegisterHealthServer(srv, health.NewServer())
+			go srv.Serve(grpcLn)
+			defer srv.Stop()
+
+			return httpx.Serve(ctx, w.Ingress("http"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "public")
+			}))
+		}).
+			NoIngress().
+			Ingress("http", rig.IngressHTTP()).
+			Ingress("grpc", rig.IngressGRPC()),
+	}, rig.WithServer(serverURL), rig.WithTimeout(60*time.Second))
+
+	resp, err := http.Get("http://" + env.Endpoint("api", "http").HostPort + "/")
+	if err != nil {
+		t.Fatalf("GET http ingress: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "public" {
+		t.Errorf("http ingress body = %q, want public", body)
+	}
+
+	conn, err := grpc.NewClient(env.Endpoint("api", "grpc").HostPort,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("grpc health check: %v", err)
+	}
+}
+
 // TestFuncLogWriter verifies that connect.LogWriter ships Func service logs
 // to rigd's event timeline.
 func TestFuncLogWriter(t *testing.T) {
@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"os/exec"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	rig "github.com/matgreaves/rig/client"
+	"github.com/matgreaves/rig/connect/httpx"
 )
 
 // buildRigd builds the rigd binary into dir and returns the path.
@@ -107,3 +109,47 @@ func TestEnsureServer(t *testing.T) {
 		t.Fatalf("health: got %d, want 200", resp.StatusCode)
 	}
 }
+
+func TestManagedServer(t *testing.T) {
+	binPath := buildRigd(t, t.TempDir())
+	rigDir := t.TempDir()
+	t.Setenv("RIG_BINARY", binPath)
+	t.Setenv("RIG_DIR", rigDir)
+
+	echo := rig.Services{
+		"echo": rig.Func(func(ctx context.Context) error {
+			return httpx.ListenAndServe(ctx, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		}),
+	}
+	env1 := rig.Up(t, echo, rig.WithManagedServer(), rig.WithTimeout(30*time.Second))
+	addr, err := os.ReadFile(filepath.Join(rigDir, "rigd.addr"))
+	if err != nil {
+		t.Fatalf("managed rigd wrote no addr file: %v", err)
+	}
+
+	// A second environment shares the running server.
+	env2 := rig.Up(t, echo, rig.WithManagedServer(), rig.WithTimeout(30*time.Second))
+	if again, _ := os.ReadFile(filepath.Join(rigDir, "rigd.addr")); string(again) != string(addr) {
+		t.Errorf("second environment started another rigd: %s, then %s", addr, again)
+	}
+
+	if err := env1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := http.Get("http://" + string(addr) + "/health"); err != nil {
+		t.Fatalf("rigd stopped while an environment was still running: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+
+	if err := env2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := http.Get("http://" + string(addr) + "/health"); err == nil {
+		resp.Body.Close()
+		t.Error("rigd still running after the last environment closed")
+	}
+	if _, err := os.Stat(filepath.Join(rigDir, "rigd.addr")); !os.IsNotExist(err) {
+		t.Errorf("addr file left behind after shutdown: %v", err)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -14,7 +17,9 @@ import (
 
 	rig "github.com/matgreaves/rig/client"
 	"github.com/matgreaves/rig/connect"
+	"github.com/matgreaves/rig/internal/server"
 	"github.com/matgreaves/rig/internal/spec"
+	riglog "github.com/matgreaves/rig/log"
 )
 
 // TestWireTypeRoundTrip verifies that the JSON produced by the client SDK's
@@ -77,11 +82,17 @@ func TestWireTypeRoundTrip(t *testing.T) {
 	}))
 	defer ts.Close()
 
+	envFile := filepath.Join(t.TempDir(), ".env.test")
+	os.WriteFile(envFile, []byte("LOG_LEVEL=debug\n"), 0o644)
+
 	// Build environment exercising every wire type field.
 	rig.Up(t, rig.Services{
 		"mygo": rig.Go("/tmp/fake-module").
 			Args("-flag1", "val1").
-			EgressAs("db", "mypostgres").
+			EnvFile(envFile).
+			Env("FEATURE_X", "on").
+			EgressAs("db", "mypostgres").Database("orders").
+			EgressAs("wf", "mytemporal").AllowMethods("StartWorkflowExecution").
 			Ingress("default", rig.IngressDef{
 				Protocol: rig.HTTP,
 				Ready: &rig.ReadyDef{
@@ -91,10 +102,12 @@ func TestWireTypeRoundTrip(t *testing.T) {
 					Timeout:  30 * time.Second,
 				},
 				Attributes: map[string]any{"CUSTOM_KEY": "custom_val"},
-			}).
+			}.MaxBodySize(1<<20)).
 			InitHook(func(ctx context.Context, w rig.Wiring) error { return nil }).
 			PrestartHook(func(ctx context.Context, w rig.Wiring) error { return nil }),
-		"myprocess": rig.Process("/tmp/fake-bin").Dir("/tmp/workdir"),
+		"myprocess": rig.Process("/tmp/fake-bin").Dir("/tmp/workdir").
+			EgressAs("api", "mygo").
+			Mock(rig.MockResponse{Method: "POST", Path: "/charges/*", Status: 402, Headers: map[string]string{"X-K": "v"}, Body: "declined"}),
 		"mycontainer": rig.Container("nginx:alpine").
 			Port(80).
 			Cmd("sh", "-c", "echo hi").
@@ -102,13 +115,19 @@ func TestWireTypeRoundTrip(t *testing.T) {
 			Exec("sh", "-c", "echo test"),
 		"mypostgres": rig.Postgres().
 			Image("postgres:15").
-			InitSQL("CREATE TABLE t (id INT)", "INSERT INTO t VALUES (1)"),
+			Databases("orders").
+			InitSQL("CREATE TABLE t (id INT)", "INSERT INTO t VALUES (1)").
+			InitSQLIn("orders", "CREATE TABLE orders (id INT)"),
 		"mytemporal": rig.Temporal().Version("1.5.1"),
 		"myredis":    rig.Redis().Image("redis:6-alpine"),
 		"mys3":       rig.S3(),
 		"mycustom":   rig.Custom("mytype", map[string]any{"key": "val"}).Args("-x"),
 		"myfunc":     rig.Func(func(ctx context.Context) error { return nil }),
-	}, rig.WithServer(ts.URL), rig.WithTimeout(5*time.Second))
+	}, rig.WithServer(ts.URL), rig.WithTimeout(5*time.Second), rig.WithContainerNetwork(),
+		rig.WithObserve(rig.TCPIdleTimeout(5*time.Minute), rig.HTTPConnPool(16)), rig.WithObserveBodyLimit(0),
+		rig.WithStartupOrder([]string{"mycontainer", "myprocess"}), rig.WithRedact(nil, []string{"user.email"}),
+		rig.WithFrozenClock(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)), rig.WithSeed(42),
+		rig.WithFault("myprocess→mygo", rig.FaultSpec{Latency: 50 * time.Millisecond, ErrorRate: 0.25}))
 
 	// --- Decode captured body with spec types ---
 
@@ -130,6 +149,34 @@ func TestWireTypeRoundTrip(t *testing.T) {
 	if !env.Observe {
 		t.Error("observe flag lost in round-trip")
 	}
+	if !env.ContainerNetwork {
+		t.Error("container_network flag lost in round-trip")
+	}
+	if env.TCPIdleTimeout != "5m0s" {
+		t.Errorf("tcp_idle_timeout = %q, want 5m0s", env.TCPIdleTimeout)
+	}
+	if env.ObserveConnPool != 16 {
+		t.Errorf("observe_conn_pool = %d, want 16", env.ObserveConnPool)
+	}
+	if r := env.ObserveRedact; r == nil || len(r.Headers) != 3 || len(r.JSONPaths) != 1 || r.JSONPaths[0] != "user.email" {
+		t.Errorf("observe_redact = %+v, want default headers and [user.email]", r)
+	}
+	if env.ObserveBodyLimit == nil || *env.ObserveBodyLimit != 0 {
+		t.Errorf("observe_body_limit = %v, want 0", env.ObserveBodyLimit)
+	}
+	if len(env.StartupOrder) != 1 || len(env.StartupOrder[0]) != 2 || env.StartupOrder[0][1] != "myprocess" {
+		t.Errorf("startup_order = %v, want [[mycontainer myprocess]]", env.StartupOrder)
+	}
+	if env.FakeNow != "2030-01-02T03:04:05Z" {
+		t.Errorf("fake_now = %q, want 2030-01-02T03:04:05Z", env.FakeNow)
+	}
+	if env.Seed == nil || *env.Seed != 42 {
+		t.Errorf("seed = %v, want 42", env.Seed)
+	}
+	wantFault := spec.FaultSpec{Source: "myprocess", Target: "mygo", Latency: spec.Duration{Duration: 50 * time.Millisecond}, ErrorRate: 0.25}
+	if len(env.Faults) != 1 || env.Faults[0] != wantFault {
+		t.Errorf("faults = %+v, want [%+v]", env.Faults, wantFault)
+	}
 
 	expectedServices := []string{"mygo", "myprocess", "mycontainer", "mypostgres", "mytemporal", "mycustom", "myfunc", "mys3"}
 	for _, name := range expectedServices {
@@ -155,6 +202,13 @@ func TestWireTypeRoundTrip(t *testing.T) {
 			t.Errorf("mygo args = %v, want [-flag1 val1]", svc.Args)
 		}
 
+		if svc.DotEnv["LOG_LEVEL"] != "debug" {
+			t.Errorf("mygo dotenv = %v, want LOG_LEVEL=debug", svc.DotEnv)
+		}
+		if svc.Env["FEATURE_X"] != "on" {
+			t.Errorf("mygo env = %v, want FEATURE_X=on", svc.Env)
+		}
+
 		ing, ok := svc.Ingresses["default"]
 		if !ok {
 			t.Fatal("mygo missing default ingress")
@@ -185,6 +239,15 @@ func TestWireTypeRoundTrip(t *testing.T) {
 		if eg.Service != "mypostgres" {
 			t.Errorf("mygo egress.service = %q, want mypostgres", eg.Service)
 		}
+		if eg.Database != "orders" {
+			t.Errorf("mygo egress.database = %q, want orders", eg.Database)
+		}
+		if wf := svc.Egresses["wf"]; len(wf.AllowMethods) != 1 || wf.AllowMethods[0] != "StartWorkflowExecution" {
+			t.Errorf("mygo egress 'wf' allow_methods = %v, want [StartWorkflowExecution]", wf.AllowMethods)
+		}
+		if ing.MaxBodySize != 1<<20 {
+			t.Errorf("mygo ingress max_body_size = %d, want %d", ing.MaxBodySize, 1<<20)
+		}
 
 		if svc.Hooks == nil {
 			t.Fatal("mygo hooks lost")
@@ -214,6 +277,11 @@ func TestWireTypeRoundTrip(t *testing.T) {
 		if cfg["dir"] != "/tmp/workdir" {
 			t.Errorf("myprocess config.dir = %q, want /tmp/workdir", cfg["dir"])
 		}
+		mocks := svc.Egresses["api"].Mocks
+		want := spec.MockSpec{Method: "POST", Path: "/charges/*", Status: 402, Headers: map[string]string{"X-K": "v"}, Body: "declined"}
+		if len(mocks) != 1 || !reflect.DeepEqual(mocks[0], want) {
+			t.Errorf("myprocess egress api mocks = %+v, want [%+v]", mocks, want)
+		}
 	}
 
 	// --- Container service ---
@@ -256,17 +324,23 @@ func TestWireTypeRoundTrip(t *testing.T) {
 		if svc.Type != "postgres" {
 			t.Errorf("mypostgres type = %q, want postgres", svc.Type)
 		}
-		var cfg map[string]string
+		var cfg struct {
+			Image     string   `json:"image"`
+			Databases []string `json:"databases"`
+		}
 		json.Unmarshal(svc.Config, &cfg)
-		if cfg["image"] != "postgres:15" {
-			t.Errorf("mypostgres config.image = %q, want postgres:15", cfg["image"])
+		if cfg.Image != "postgres:15" {
+			t.Errorf("mypostgres config.image = %q, want postgres:15", cfg.Image)
+		}
+		if len(cfg.Databases) != 1 || cfg.Databases[0] != "orders" {
+			t.Errorf("mypostgres config.databases = %v, want [orders]", cfg.Databases)
 		}
 		if ing := svc.Ingresses["default"]; ing.Protocol != spec.TCP || ing.ContainerPort != 5432 {
 			t.Errorf("mypostgres default ingress = {%s %d}, want {tcp 5432}", ing.Protocol, ing.ContainerPort)
 		}
 		// InitSQL produces a sql hook.
-		if svc.Hooks == nil || len(svc.Hooks.Init) != 1 {
-			t.Fatal("mypostgres sql hook lost")
+		if svc.Hooks == nil || len(svc.Hooks.Init) != 2 {
+			t.Fatal("mypostgres sql hooks lost")
 		}
 		if svc.Hooks.Init[0].Type != "sql" {
 			t.Errorf("mypostgres init hook type = %q, want sql", svc.Hooks.Init[0].Type)
@@ -277,6 +351,10 @@ func TestWireTypeRoundTrip(t *testing.T) {
 		if !ok || len(stmts) != 2 {
 			t.Errorf("mypostgres sql statements = %v, want 2 items", sqlCfg["statements"])
 		}
+		json.Unmarshal(svc.Hooks.Init[1].Config, &sqlCfg)
+		if sqlCfg["database"] != "orders" {
+			t.Errorf("mypostgres InitSQLIn database = %v, want orders", sqlCfg["database"])
+		}
 	}
 
 	// --- Temporal service ---
@@ -318,8 +396,8 @@ func TestWireTypeRoundTrip(t *testing.T) {
 		if _, ok := svc.Ingresses["default"]; !ok {
 			t.Error("myredis missing default ingress")
 		}
-		if svc.Ingresses["default"].Protocol != spec.TCP {
-			t.Errorf("myredis default protocol = %q, want tcp", svc.Ingresses["default"].Protocol)
+		if svc.Ingresses["default"].Protocol != spec.Redis {
+			t.Errorf("myredis default protocol = %q, want redis", svc.Ingresses["default"].Protocol)
 		}
 	}
 
@@ -383,6 +461,9 @@ func TestProtocolParity(t *testing.T) {
 		{"HTTP", connect.HTTP, spec.HTTP},
 		{"GRPC", connect.GRPC, spec.GRPC},
 		{"Kafka", connect.Kafka, spec.Kafka},
+		{"Redis", connect.Redis, spec.Redis},
+		{"NATS", connect.NATS, spec.NATS},
+		{"Mongo", connect.Mongo, spec.Mongo},
 	}
 	for _, tc := range cases {
 		if string(tc.connectVal) != string(tc.specVal) {
@@ -397,6 +478,9 @@ func TestProtocolParity(t *testing.T) {
 		string(connect.HTTP):  true,
 		string(connect.GRPC):  true,
 		string(connect.Kafka): true,
+		string(connect.Redis): true,
+		string(connect.NATS):  true,
+		string(connect.Mongo): true,
 	}
 	for _, p := range specProtos {
 		if !connectKnown[string(p)] {
@@ -404,3 +488,71 @@ func TestProtocolParity(t *testing.T) {
 		}
 	}
 }
+
+// TestLogEventParity verifies the public log package's Event mirrors the
+// server's Event field for field, so a log written by rigd decodes without
+// losing data and log.Event doesn't invent fields the server never writes.
+// Fields the log package keeps as json.RawMessage are not descended into.
+func TestLogEventParity(t *testing.T) {
+	compareJSONFields(t, "Event", reflect.TypeOf(server.Event{}), reflect.TypeOf(riglog.Event{}))
+}
+
+// compareJSONFields reports json field names present in one struct type
+// but not the other, recursing through pointers, slices and maps.
+func compareJSONFields(t *testing.T, path string, want, got reflect.Type) {
+	t.Helper()
+	want, got = elemType(want), elemType(got)
+	if got == reflect.TypeOf(json.RawMessage(nil)) {
+		return
+	}
+	if want.Kind() != reflect.Struct || got.Kind() != reflect.Struct {
+		if want.Kind() != got.Kind() {
+			t.Errorf("%s: server kind %s, log kind %s", path, want.Kind(), got.Kind())
+		}
+		return
+	}
+	if want == reflect.TypeOf(time.Time{}) {
+		return
+	}
+	wantFields, gotFields := jsonFields(want), jsonFields(got)
+	for name, wf := range wantFields {
+		gf, ok := gotFields[name]
+		if !ok {
+			t.Errorf("%s.%s: missing from log package", path, name)
+			continue
+		}
+		compareJSONFields(t, path+"."+name, wf.Type, gf.Type)
+	}
+	for name := range gotFields {
+		if _, ok := wantFields[name]; !ok {
+			t.Errorf("%s.%s: not written by the server", path, name)
+		}
+	}
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			if t == reflect.TypeOf(json.RawMessage(nil)) || t.Elem().Kind() == reflect.Uint8 {
+				return t
+			}
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	out := map[string]reflect.StructField{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+		out[name] = f
+	}
+	return out
+}
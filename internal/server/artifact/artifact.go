@@ -62,3 +62,11 @@ type Resolver interface {
 type Validator interface {
 	Valid(output Output) bool
 }
+
+// Checker is an optional interface for resolvers that can cheaply verify
+// their inputs — a source directory exists, an image reference parses —
+// without producing the artifact. Dry-run validation calls Check so a
+// misconfigured environment fails without building or pulling anything.
+type Checker interface {
+	Check() error
+}
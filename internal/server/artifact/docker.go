@@ -4,13 +4,17 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/distribution/reference"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/errdefs"
 	"github.com/matgreaves/rig/internal/server/dockerutil"
 )
 
@@ -30,6 +34,14 @@ func (d DockerPull) CacheKey() (string, error) {
 	return "docker/" + hex.EncodeToString(sum[:]), nil
 }
 
+// Check verifies that the image reference is well-formed.
+func (d DockerPull) Check() error {
+	if _, err := reference.ParseNormalizedNamed(d.Image); err != nil {
+		return fmt.Errorf("image %q: %w", d.Image, err)
+	}
+	return nil
+}
+
 // Cached checks for a breadcrumb file (.image-id) left by a previous Resolve.
 func (d DockerPull) Cached(outputDir string) (Output, bool) {
 	data, err := os.ReadFile(filepath.Join(outputDir, ".image-id"))
@@ -70,15 +82,16 @@ func (d DockerPull) Resolve(ctx context.Context, outputDir string) (Output, erro
 		// Image not present locally — pull from registry.
 		rc, err := cli.ImagePull(ctx, d.Image, image.PullOptions{})
 		if err != nil {
-			return Output{}, fmt.Errorf("docker pull %s: %w", d.Image, err)
+			return Output{}, fmt.Errorf("docker pull %s: %w", d.Image, classifyPullError(err))
 		}
 		// Drain the pull output to completion — the pull isn't done until
-		// the response body is fully read.
-		if _, err := io.Copy(io.Discard, rc); err != nil {
-			rc.Close()
-			return Output{}, fmt.Errorf("docker pull %s: read response: %w", d.Image, err)
-		}
+		// the response body is fully read. Registry errors (unknown tag,
+		// denied) arrive as messages in the stream, not as an HTTP status.
+		err = readJSONStream(rc)
 		rc.Close()
+		if err != nil {
+			return Output{}, fmt.Errorf("docker pull %s: %w", d.Image, classifyPullError(err))
+		}
 
 		inspect, _, err = cli.ImageInspectWithRaw(ctx, d.Image)
 		if err != nil {
@@ -105,6 +118,50 @@ func (d DockerPull) Resolve(ctx context.Context, outputDir string) (Output, erro
 	}, nil
 }
 
+// readJSONStream reads a daemon JSON message stream (pull progress, load
+// output) to the end, returning the first error message reported in it.
+func readJSONStream(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+	}
+}
+
+// permanentPullErrors are registry responses that retrying won't change.
+var permanentPullErrors = []string{
+	"manifest unknown",
+	"not found",
+	"unauthorized",
+	"denied",
+	"invalid reference format",
+}
+
+// classifyPullError marks errors the registry or daemon reports about the
+// image itself as Permanent, leaving transport failures retryable.
+func classifyPullError(err error) error {
+	if errdefs.IsNotFound(err) || errdefs.IsUnauthorized(err) ||
+		errdefs.IsForbidden(err) || errdefs.IsInvalidParameter(err) {
+		return Permanent(err)
+	}
+	msg := strings.ToLower(err.Error())
+	for _, p := range permanentPullErrors {
+		if strings.Contains(msg, p) {
+			return Permanent(err)
+		}
+	}
+	return err
+}
+
 // Retryable returns true — image pulls are network operations.
 func (d DockerPull) Retryable() bool { return true }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/matgreaves/rig/internal/server/artifact"
@@ -58,6 +59,19 @@ func TestDockerPull_Retryable(t *testing.T) {
 	}
 }
 
+func TestDockerPull_Check(t *testing.T) {
+	for _, image := range []string{"postgres:16", "ghcr.io/org/app@sha256:" + strings.Repeat("a", 64)} {
+		if err := (artifact.DockerPull{Image: image}).Check(); err != nil {
+			t.Errorf("Check(%q) = %v, want nil", image, err)
+		}
+	}
+	for _, image := range []string{"", "Postgres:16", "postgres:bad tag"} {
+		if err := (artifact.DockerPull{Image: image}).Check(); err == nil {
+			t.Errorf("Check(%q) = nil, want an error", image)
+		}
+	}
+}
+
 func TestDockerPull_CachedMiss(t *testing.T) {
 	d := artifact.DockerPull{Image: "alpine:3.20"}
 	_, ok := d.Cached(t.TempDir())
@@ -0,0 +1,246 @@
+package artifact
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/matgreaves/rig/internal/server/dockerutil"
+)
+
+// DockerLoad resolves a Docker image by loading it from a local tarball
+// (the output of `docker save`), for environments without registry access.
+// If the daemon already has the tarball's image under the same reference the
+// tarball is not loaded again; a rebuilt tarball with a new image is.
+//
+// Like DockerPull, the output has no Path. Meta contains the image reference
+// and resolved image ID.
+type DockerLoad struct {
+	Tarball string // absolute path to the image tarball
+	Image   string // image reference the tarball must contain (e.g. "myteam/api:ci")
+}
+
+// CacheKey hashes the tarball path, size, and modification time together with
+// the image reference, so rebuilding the tarball invalidates the cache.
+func (d DockerLoad) CacheKey() (string, error) {
+	info, err := os.Stat(d.Tarball)
+	if err != nil {
+		return "", fmt.Errorf("image tarball: %w", err)
+	}
+	raw := fmt.Sprintf("docker-load:%s:%d:%d:%s", d.Tarball, info.Size(), info.ModTime().UnixNano(), d.Image)
+	sum := sha256.Sum256([]byte(raw))
+	return "docker-load/" + hex.EncodeToString(sum[:]), nil
+}
+
+// Check verifies that the tarball exists.
+func (d DockerLoad) Check() error {
+	if _, err := os.Stat(d.Tarball); err != nil {
+		return fmt.Errorf("image tarball: %w", err)
+	}
+	return nil
+}
+
+// Cached checks for the .image-id breadcrumb left by a previous Resolve.
+func (d DockerLoad) Cached(outputDir string) (Output, bool) {
+	data, err := os.ReadFile(filepath.Join(outputDir, ".image-id"))
+	if err != nil {
+		return Output{}, false
+	}
+	imageID := strings.TrimSpace(string(data))
+	if imageID == "" {
+		return Output{}, false
+	}
+	return d.output(imageID), true
+}
+
+// Resolve loads the tarball into the Docker daemon unless the daemon already
+// has the same image under the reference, then verifies the reference exists.
+// Images are compared by ID, so a tarball rebuilt under an existing tag is
+// loaded. OCI layouts don't record the image ID and are always loaded.
+func (d DockerLoad) Resolve(ctx context.Context, outputDir string) (Output, error) {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return Output{}, fmt.Errorf("create output dir: %w", err)
+	}
+
+	// Check the tarball holds the expected image before handing it to the
+	// daemon, so a mismatch fails with a useful message.
+	images, err := tarballImages(d.Tarball)
+	if err != nil {
+		return Output{}, err
+	}
+	i := slices.IndexFunc(images, func(img tarballImage) bool { return img.ref == normalizeImageRef(d.Image) })
+	if i < 0 {
+		return Output{}, fmt.Errorf("image tarball %s does not contain %s (has %s)", d.Tarball, d.Image, strings.Join(imageRefs(images), ", "))
+	}
+	wantID := images[i].id
+
+	cli, err := dockerutil.Client()
+	if err != nil {
+		return Output{}, fmt.Errorf("docker client: %w", err)
+	}
+
+	inspect, _, err := cli.ImageInspectWithRaw(ctx, d.Image)
+	if err != nil || wantID == "" || inspect.ID != wantID {
+		f, err := os.Open(d.Tarball)
+		if err != nil {
+			return Output{}, fmt.Errorf("image tarball: %w", err)
+		}
+		resp, err := cli.ImageLoad(ctx, f, true)
+		if err != nil {
+			f.Close()
+			return Output{}, fmt.Errorf("docker load %s: %w", d.Tarball, err)
+		}
+		err = readJSONStream(resp.Body)
+		resp.Body.Close()
+		f.Close()
+		if err != nil {
+			return Output{}, fmt.Errorf("docker load %s: %w", d.Tarball, err)
+		}
+
+		inspect, _, err = cli.ImageInspectWithRaw(ctx, d.Image)
+		if err != nil {
+			return Output{}, fmt.Errorf("docker inspect %s after load: %w", d.Image, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, ".image-id"), []byte(inspect.ID), 0o644); err != nil {
+		return Output{}, fmt.Errorf("write breadcrumb: %w", err)
+	}
+	return d.output(inspect.ID), nil
+}
+
+// Retryable returns false — loading a local file is not a network operation.
+func (d DockerLoad) Retryable() bool { return false }
+
+// Valid checks whether the loaded image still exists in the local Docker
+// daemon. Implements artifact.Validator.
+func (d DockerLoad) Valid(output Output) bool {
+	imageID := output.Meta["image_id"]
+	if imageID == "" {
+		return false
+	}
+	cli, err := dockerutil.Client()
+	if err != nil {
+		return false
+	}
+	_, _, err = cli.ImageInspectWithRaw(context.Background(), imageID)
+	return err == nil
+}
+
+func (d DockerLoad) output(imageID string) Output {
+	return Output{
+		Meta: map[string]string{
+			"image":    d.Image,
+			"image_id": imageID,
+			"tarball":  d.Tarball,
+		},
+	}
+}
+
+// normalizeImageRef adds the implicit ":latest" tag to an untagged image
+// reference, matching how docker save records RepoTags.
+func normalizeImageRef(ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	if strings.ContainsAny(name, ":@") {
+		return ref
+	}
+	return ref + ":latest"
+}
+
+// TarballImages returns the image references recorded in an image tarball.
+// Both `docker save` archives (manifest.json RepoTags) and OCI layouts
+// (index.json name annotations) are understood.
+func TarballImages(path string) ([]string, error) {
+	images, err := tarballImages(path)
+	if err != nil {
+		return nil, err
+	}
+	return imageRefs(images), nil
+}
+
+// tarballImage is one tagged image in an image tarball.
+type tarballImage struct {
+	ref string
+	id  string // "sha256:<config digest>"; empty for OCI layouts
+}
+
+func imageRefs(images []tarballImage) []string {
+	refs := make([]string, len(images))
+	for i, img := range images {
+		refs[i] = img.ref
+	}
+	return refs
+}
+
+func tarballImages(path string) ([]tarballImage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("image tarball: %w", err)
+	}
+	defer f.Close()
+
+	var tags []tarballImage
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("image tarball %s: %w", path, err)
+		}
+		switch strings.TrimPrefix(hdr.Name, "./") {
+		case "manifest.json":
+			var manifest []struct {
+				Config   string
+				RepoTags []string
+			}
+			if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+				return nil, fmt.Errorf("image tarball %s: manifest.json: %w", path, err)
+			}
+			// manifest.json is authoritative for docker save archives.
+			tags = tags[:0]
+			for _, m := range manifest {
+				// The config blob's digest is the image ID. Older archives
+				// name it "<hex>.json", newer ones "blobs/sha256/<hex>".
+				var id string
+				if m.Config != "" {
+					id = "sha256:" + strings.TrimSuffix(filepath.Base(m.Config), ".json")
+				}
+				for _, ref := range m.RepoTags {
+					tags = append(tags, tarballImage{ref: ref, id: id})
+				}
+			}
+			if len(tags) > 0 {
+				return tags, nil
+			}
+		case "index.json":
+			var index struct {
+				Manifests []struct {
+					Annotations map[string]string `json:"annotations"`
+				} `json:"manifests"`
+			}
+			if err := json.NewDecoder(tr).Decode(&index); err != nil {
+				return nil, fmt.Errorf("image tarball %s: index.json: %w", path, err)
+			}
+			for _, m := range index.Manifests {
+				if name := m.Annotations["io.containerd.image.name"]; name != "" {
+					tags = append(tags, tarballImage{ref: name})
+				}
+			}
+		}
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("image tarball %s: no tagged images found (was it created with docker save <image:tag>?)", path)
+	}
+	return tags, nil
+}
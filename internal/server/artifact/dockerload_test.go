@@ -0,0 +1,121 @@
+package artifact
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeImageTar writes a tarball containing a single file name with body.
+func writeImageTar(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "image.tar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTarballImages_DockerSave(t *testing.T) {
+	path := writeImageTar(t, "manifest.json", `[{"Config":"c.json","RepoTags":["myteam/api:ci","myteam/api:latest"],"Layers":[]}]`)
+	tags, err := TarballImages(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(tags, []string{"myteam/api:ci", "myteam/api:latest"}) {
+		t.Errorf("tags = %v", tags)
+	}
+}
+
+func TestTarballImages_OCI(t *testing.T) {
+	path := writeImageTar(t, "index.json", `{"manifests":[{"annotations":{"io.containerd.image.name":"docker.io/myteam/api:ci"}}]}`)
+	tags, err := TarballImages(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(tags, []string{"docker.io/myteam/api:ci"}) {
+		t.Errorf("tags = %v", tags)
+	}
+}
+
+func TestTarballImages_IDs(t *testing.T) {
+	tests := []struct {
+		name, file, body string
+		want             []tarballImage
+	}{
+		{
+			name: "legacy config",
+			file: "manifest.json",
+			body: `[{"Config":"4f2a.json","RepoTags":["myteam/api:ci"]}]`,
+			want: []tarballImage{{ref: "myteam/api:ci", id: "sha256:4f2a"}},
+		},
+		{
+			name: "blob config",
+			file: "manifest.json",
+			body: `[{"Config":"blobs/sha256/4f2a","RepoTags":["myteam/api:ci"]}]`,
+			want: []tarballImage{{ref: "myteam/api:ci", id: "sha256:4f2a"}},
+		},
+		{
+			// OCI layouts don't record the image ID, so Resolve always loads.
+			name: "oci",
+			file: "index.json",
+			body: `{"manifests":[{"annotations":{"io.containerd.image.name":"myteam/api:ci"}}]}`,
+			want: []tarballImage{{ref: "myteam/api:ci"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images, err := tarballImages(writeImageTar(t, tt.file, tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(images, tt.want) {
+				t.Errorf("images = %+v, want %+v", images, tt.want)
+			}
+		})
+	}
+}
+
+func TestTarballImages_Untagged(t *testing.T) {
+	path := writeImageTar(t, "manifest.json", `[{"Config":"c.json","RepoTags":null,"Layers":[]}]`)
+	if _, err := TarballImages(path); err == nil {
+		t.Error("expected error for untagged tarball")
+	}
+}
+
+func TestDockerLoad_CacheKey(t *testing.T) {
+	path := writeImageTar(t, "manifest.json", `[]`)
+	a := DockerLoad{Tarball: path, Image: "myteam/api:ci"}
+	keyA, err := a.CacheKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyB, err := DockerLoad{Tarball: path, Image: "myteam/api:other"}.CacheKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyA == keyB {
+		t.Error("different images should produce different keys")
+	}
+	if keyA[:12] != "docker-load/" {
+		t.Errorf("key should start with docker-load/: %q", keyA)
+	}
+
+	if _, err := (DockerLoad{Tarball: filepath.Join(t.TempDir(), "missing.tar")}).CacheKey(); err == nil {
+		t.Error("expected error for missing tarball")
+	}
+}
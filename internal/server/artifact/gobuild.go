@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -23,6 +24,14 @@ type GoBuild struct {
 	GOOS    string            // defaults to runtime.GOOS
 	GOARCH  string            // defaults to runtime.GOARCH
 	HostEnv map[string]string // host process env from SDK (used as base for go build)
+	Tags    []string          // build tags, passed as -tags
+	Flags   []string          // extra go build flags, e.g. ["-ldflags", "-X main.version=1.2.3"]
+
+	// GoVersion, when set, builds with that Go release ("1.22.5") rather
+	// than the go command on PATH, using the golang.org/dl wrapper
+	// go1.22.5 found on PATH or in GOBIN. GOTOOLCHAIN=local keeps it from
+	// switching to a newer toolchain a go.mod asks for.
+	GoVersion string
 }
 
 func (g GoBuild) goos() string {
@@ -43,6 +52,74 @@ func (g GoBuild) isLocal() bool {
 	return strings.HasPrefix(g.Module, "/")
 }
 
+// goVersion returns the version of the toolchain the build uses, for cache
+// keys. Without GoVersion it is rigd's own, taken to match the go on PATH.
+func (g GoBuild) goVersion() string {
+	if g.GoVersion != "" {
+		return "go" + g.GoVersion
+	}
+	return runtime.Version()
+}
+
+// goCommand returns the go command to build with: "go", or the path of
+// the GoVersion toolchain wrapper. The wrapper is looked up on the host
+// PATH, then where go install puts it (GOBIN, GOPATH/bin, ~/go/bin).
+func (g GoBuild) goCommand() (string, error) {
+	if g.GoVersion == "" {
+		return "go", nil
+	}
+	name := "go" + g.GoVersion
+	dirs := filepath.SplitList(g.getenv("PATH"))
+	if gobin := g.getenv("GOBIN"); gobin != "" {
+		dirs = append(dirs, gobin)
+	}
+	for _, gopath := range filepath.SplitList(g.getenv("GOPATH")) {
+		dirs = append(dirs, filepath.Join(gopath, "bin"))
+	}
+	if home := g.getenv("HOME"); home != "" {
+		dirs = append(dirs, filepath.Join(home, "go", "bin"))
+	}
+	for _, dir := range dirs {
+		p := filepath.Join(dir, name)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() && info.Mode()&0o111 != 0 {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("go toolchain %s not found on PATH or in GOBIN; install it with:\n\tgo install golang.org/dl/%s@latest && %s download", name, name, name)
+}
+
+// getenv returns key from HostEnv, or from rigd's environment when the SDK
+// didn't send one.
+func (g GoBuild) getenv(key string) string {
+	if len(g.HostEnv) > 0 {
+		return g.HostEnv[key]
+	}
+	return os.Getenv(key)
+}
+
+// Check verifies that a local module directory exists, or that a remote
+// module reference carries a version, and that the GoVersion toolchain is
+// installed.
+func (g GoBuild) Check() error {
+	if _, err := g.goCommand(); err != nil {
+		return err
+	}
+	if !g.isLocal() {
+		if !strings.Contains(g.Module, "@") {
+			return fmt.Errorf("remote module %q must include a version suffix (e.g. module@v1.2.3)", g.Module)
+		}
+		return nil
+	}
+	info, err := os.Stat(g.Module)
+	if err != nil {
+		return fmt.Errorf("module: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("module %s is not a directory", g.Module)
+	}
+	return nil
+}
+
 // buildEnv returns the environment for go build. If HostEnv is set (from the
 // SDK's captured environment), it is used as the base so the build sees the
 // test process's GOPATH, GOPROXY, GOFLAGS, etc. Falls back to rigd's own
@@ -57,13 +134,41 @@ func (g GoBuild) buildEnv() []string {
 	} else {
 		base = os.Environ()
 	}
-	return append(base, "GOOS="+g.goos(), "GOARCH="+g.goarch())
+	base = append(base, "GOOS="+g.goos(), "GOARCH="+g.goarch())
+	if g.GoVersion != "" {
+		base = append(base, "GOTOOLCHAIN=local")
+	}
+	return base
+}
+
+// buildArgs returns the go build arguments producing outputPath from pkg.
+func (g GoBuild) buildArgs(outputPath, pkg string) []string {
+	args := []string{"build", "-trimpath"}
+	if len(g.Tags) > 0 {
+		args = append(args, "-tags="+strings.Join(g.Tags, ","))
+	}
+	args = append(args, g.Flags...)
+	return append(args, "-o", outputPath, pkg)
+}
+
+// hashBuildOptions writes the build tags and flags to h, so variants of
+// the same module built with different options get different cache keys.
+// Tag order doesn't change the build; flag order can. Nothing is written
+// without options, so plain builds keep their existing keys.
+func (g GoBuild) hashBuildOptions(h io.Writer) {
+	if len(g.Tags) == 0 && len(g.Flags) == 0 {
+		return
+	}
+	tags := slices.Clone(g.Tags)
+	sort.Strings(tags)
+	fmt.Fprintf(h, "\ntags:%q\nflags:%q\n", tags, g.Flags)
 }
 
 // CacheKey returns a content-based hash suitable for use as a cache directory
-// name. For local modules the hash covers GOOS, GOARCH, and all source file
-// paths and contents. For remote modules the hash covers GOOS, GOARCH, and
-// the module reference (which must include a @version suffix).
+// name. For local modules the hash covers GOOS, GOARCH, build tags and
+// flags, and all source file paths and contents. For remote modules the
+// hash covers GOOS, GOARCH, build tags and flags, and the module reference
+// (which must include a @version suffix).
 func (g GoBuild) CacheKey() (string, error) {
 	if g.isLocal() {
 		return g.localCacheKey()
@@ -81,7 +186,8 @@ func (g GoBuild) CacheKey() (string, error) {
 //     (templates, SQL migrations, etc.) are not hashed. Same workaround.
 func (g GoBuild) localCacheKey() (string, error) {
 	h := sha256.New()
-	fmt.Fprintf(h, "goos:%s\ngoarch:%s\ngoversion:%s\n", g.goos(), g.goarch(), runtime.Version())
+	fmt.Fprintf(h, "goos:%s\ngoarch:%s\ngoversion:%s\n", g.goos(), g.goarch(), g.goVersion())
+	g.hashBuildOptions(h)
 
 	// Try git ls-files first — fast and excludes build artifacts.
 	files, err := gitSourceFiles(g.Module)
@@ -107,9 +213,10 @@ func (g GoBuild) remoteCacheKey() (string, error) {
 		return "", fmt.Errorf("remote module %q must include a version suffix (e.g. module@v1.2.3)", g.Module)
 	}
 	// The module reference is the version pin; no file hashing needed.
-	raw := fmt.Sprintf("goos:%s\ngoarch:%s\ngoversion:%s\nmodule:%s", g.goos(), g.goarch(), runtime.Version(), g.Module)
-	sum := sha256.Sum256([]byte(raw))
-	return "go/" + hex.EncodeToString(sum[:]), nil
+	h := sha256.New()
+	fmt.Fprintf(h, "goos:%s\ngoarch:%s\ngoversion:%s\nmodule:%s", g.goos(), g.goarch(), g.goVersion(), g.Module)
+	g.hashBuildOptions(h)
+	return "go/" + hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // Cached checks whether a compiled binary exists in outputDir from a previous
@@ -134,15 +241,19 @@ func (g GoBuild) Resolve(ctx context.Context, outputDir string) (Output, error)
 	}
 
 	outputPath := filepath.Join(outputDir, "binary")
+	goCmd, err := g.goCommand()
+	if err != nil {
+		return Output{}, Permanent(err)
+	}
 
 	var cmd *exec.Cmd
 	if g.isLocal() {
 		// Local builds must run from the module directory so go build
 		// resolves against the correct go.mod.
-		cmd = exec.CommandContext(ctx, "go", "build", "-trimpath", "-o", outputPath, ".")
+		cmd = exec.CommandContext(ctx, goCmd, g.buildArgs(outputPath, ".")...)
 		cmd.Dir = g.Module
 	} else {
-		cmd = exec.CommandContext(ctx, "go", "build", "-trimpath", "-o", outputPath, g.Module)
+		cmd = exec.CommandContext(ctx, goCmd, g.buildArgs(outputPath, g.Module)...)
 	}
 	cmd.Env = g.buildEnv()
 	out, err := cmd.CombinedOutput()
@@ -2,8 +2,11 @@ package artifact_test
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/matgreaves/rig/internal/server/artifact"
@@ -116,6 +119,141 @@ func TestGoBuild_Resolve(t *testing.T) {
 	}
 }
 
+func TestGoBuild_TagsAndFlags(t *testing.T) {
+	// A module whose output depends on a build tag and an -X ldflag.
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/tagged\n\ngo 1.21\n",
+		"main.go":        "package main\n\nimport \"fmt\"\n\nvar version = \"dev\"\n\nfunc main() { fmt.Print(mode, \" \", version) }\n",
+		"plain.go":       "//go:build !integration\n\npackage main\n\nconst mode = \"plain\"\n",
+		"integration.go": "//go:build integration\n\npackage main\n\nconst mode = \"integration\"\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plain := artifact.GoBuild{Module: dir}
+	tagged := artifact.GoBuild{
+		Module: dir,
+		Tags:   []string{"integration"},
+		Flags:  []string{"-ldflags", "-X main.version=1.2.3"},
+	}
+	artifacts := []artifact.Artifact{
+		{Key: "plain", Resolver: plain},
+		{Key: "tagged", Resolver: tagged},
+	}
+	cache := artifact.NewCache(t.TempDir())
+	results, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["plain"].Path == results["tagged"].Path {
+		t.Fatalf("both variants cached at %s", results["plain"].Path)
+	}
+
+	for key, want := range map[string]string{"plain": "plain dev", "tagged": "integration 1.2.3"} {
+		out, err := exec.Command(results[key].Path).Output()
+		if err != nil {
+			t.Fatalf("%s: run: %v", key, err)
+		}
+		if string(out) != want {
+			t.Errorf("%s: output = %q, want %q", key, out, want)
+		}
+	}
+
+	// Tag order doesn't matter; flags do.
+	reordered := artifact.GoBuild{Module: dir, Tags: []string{"b", "a"}}
+	sorted := artifact.GoBuild{Module: dir, Tags: []string{"a", "b"}}
+	if k1, k2 := cacheKey(t, reordered), cacheKey(t, sorted); k1 != k2 {
+		t.Errorf("tag order changed the cache key: %s != %s", k1, k2)
+	}
+	if k1, k2 := cacheKey(t, tagged), cacheKey(t, artifact.GoBuild{Module: dir, Tags: tagged.Tags}); k1 == k2 {
+		t.Error("flags did not change the cache key")
+	}
+}
+
+func TestGoBuild_GoVersion(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/pinned\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without the wrapper installed, the error says how to install it.
+	bin := t.TempDir()
+	pinned := artifact.GoBuild{Module: dir, GoVersion: "1.22.5", HostEnv: map[string]string{"PATH": bin}}
+	err := pinned.Check()
+	if err == nil || !strings.Contains(err.Error(), "go install golang.org/dl/go1.22.5@latest && go1.22.5 download") {
+		t.Fatalf("Check without toolchain = %v, want install instructions", err)
+	}
+
+	// A stand-in wrapper that records its environment and defers to go.
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not on PATH")
+	}
+	marker := filepath.Join(bin, "called")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$GOTOOLCHAIN\" > %s\nexec %s \"$@\"\n", marker, goPath)
+	if err := os.WriteFile(filepath.Join(bin, "go1.22.5"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	pinned.HostEnv = map[string]string{}
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		pinned.HostEnv[k] = v
+	}
+	pinned.HostEnv["PATH"] = bin + string(filepath.ListSeparator) + os.Getenv("PATH")
+	if err := pinned.Check(); err != nil {
+		t.Fatalf("Check with toolchain: %v", err)
+	}
+	if _, err := pinned.Resolve(context.Background(), t.TempDir()); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	got, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("wrapper was not used: %v", err)
+	}
+	if strings.TrimSpace(string(got)) != "local" {
+		t.Errorf("GOTOOLCHAIN = %q, want local", got)
+	}
+
+	if k1, k2 := cacheKey(t, pinned), cacheKey(t, artifact.GoBuild{Module: dir}); k1 == k2 {
+		t.Error("GoVersion did not change the cache key")
+	}
+}
+
+func cacheKey(t *testing.T, g artifact.GoBuild) string {
+	t.Helper()
+	key, err := g.CacheKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestGoBuild_Check(t *testing.T) {
+	dir := t.TempDir()
+	if err := (artifact.GoBuild{Module: dir}).Check(); err != nil {
+		t.Errorf("existing module dir: %v", err)
+	}
+	if err := (artifact.GoBuild{Module: filepath.Join(dir, "missing")}).Check(); err == nil {
+		t.Error("missing module dir: want an error")
+	}
+	if err := (artifact.GoBuild{Module: "github.com/org/tool"}).Check(); err == nil {
+		t.Error("unversioned remote module: want an error")
+	}
+	if err := (artifact.GoBuild{Module: "github.com/org/tool@v1.2.3"}).Check(); err != nil {
+		t.Errorf("versioned remote module: %v", err)
+	}
+}
+
 func TestGoBuild_RemoteCacheKey_RequiresVersion(t *testing.T) {
 	g := artifact.GoBuild{Module: "github.com/example/tool"} // no @version
 	_, err := g.CacheKey()
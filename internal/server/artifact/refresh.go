@@ -21,6 +21,7 @@ const DefaultStaleAfter = 24 * time.Hour
 type Refresher struct {
 	cache      *Cache
 	staleAfter time.Duration
+	retry      RetryPolicy
 	resolve    resolveFunc // injectable for testing
 }
 
@@ -33,10 +34,17 @@ func NewRefresher(cache *Cache, staleAfter time.Duration) *Refresher {
 	return &Refresher{
 		cache:      cache,
 		staleAfter: staleAfter,
+		retry:      DefaultRetry,
 		resolve:    defaultResolve,
 	}
 }
 
+// SetRetry sets the policy for retrying transient pull failures. Call before
+// the first RefreshOnce.
+func (r *Refresher) SetRetry(policy RetryPolicy) {
+	r.retry = policy
+}
+
 // defaultResolve pulls a Docker image via the same path as normal artifact
 // resolution — DockerPull.Resolve writes .image-id and .image-ref breadcrumbs.
 func defaultResolve(ctx context.Context, imageRef string, outputDir string) error {
@@ -71,7 +79,10 @@ func (r *Refresher) refreshEntry(ctx context.Context, e dockerEntry) {
 	}
 	defer unlock()
 
-	if err := r.resolve(ctx, e.imageRef, e.dir); err != nil {
+	err = withRetry(ctx, r.retry, func() error {
+		return r.resolve(ctx, e.imageRef, e.dir)
+	}, nil)
+	if err != nil {
 		// Pull failed — don't touch .last-checked so the entry gets retried
 		// on the next idle period rather than being suppressed for the full
 		// stale window.
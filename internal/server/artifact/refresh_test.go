@@ -106,6 +106,7 @@ func newTestRefresher(t *testing.T, staleAfter time.Duration, resolve resolveFun
 	cacheDir := t.TempDir()
 	r := NewRefresher(NewCache(cacheDir), staleAfter)
 	r.resolve = resolve
+	r.SetRetry(RetryPolicy{Attempts: 2, Backoff: time.Millisecond})
 	return r, cacheDir
 }
 
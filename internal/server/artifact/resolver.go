@@ -18,20 +18,35 @@ const (
 	EventCompleted EventKind = "completed"
 	EventCached    EventKind = "cached"
 	EventFailed    EventKind = "failed"
+	EventRetry     EventKind = "retry"
 )
 
 // EmitFunc is called for each artifact lifecycle event.
-// err is non-nil only when kind is EventFailed.
+// err is non-nil only when kind is EventFailed or EventRetry; for
+// EventRetry it is a *RetryError describing the failed attempt.
 type EmitFunc func(kind EventKind, key string, err error)
 
+// DefaultConcurrency is the number of cache-miss artifacts resolved at once
+// when no limit is configured.
+const DefaultConcurrency = 4
+
 // Resolve resolves all artifacts, deduplicating by Artifact.Key (first wins).
 // Cache-hit artifacts are recorded immediately; cache-miss artifacts are
-// resolved in parallel. Returns a map of Artifact.Key → Output.
+// resolved in parallel, at most concurrency at a time (DefaultConcurrency if
+// < 1). Returns a map of Artifact.Key → Output.
 //
-// Retryable resolvers are attempted up to 3 times with exponential backoff
-// (1s, 2s). Non-retryable resolvers are attempted once. The first error from
-// any artifact cancels in-flight resolutions and is returned.
-func Resolve(ctx context.Context, artifacts []Artifact, cache *Cache, emit EmitFunc) (map[string]Output, error) {
+// Retryable resolvers are retried according to retry (DefaultRetry if zero),
+// unless the error is marked Permanent. Non-retryable resolvers are attempted
+// once. The first error from any artifact cancels in-flight resolutions and
+// is returned.
+func Resolve(ctx context.Context, artifacts []Artifact, cache *Cache, retry RetryPolicy, concurrency int, emit EmitFunc) (map[string]Output, error) {
+	if retry == (RetryPolicy{}) {
+		retry = DefaultRetry
+	}
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
 	// Deduplicate by key; first occurrence wins.
 	seen := make(map[string]struct{}, len(artifacts))
 	var unique []Artifact
@@ -51,6 +66,10 @@ func Resolve(ctx context.Context, artifacts []Artifact, cache *Cache, emit EmitF
 	// Capacity matches the maximum number of errors that could be sent.
 	errCh := make(chan error, len(unique))
 	var wg sync.WaitGroup
+	// sem bounds the number of resolutions in flight. A slot is held from
+	// before the cross-process lock until the resolution finishes, so
+	// waiting on another rigd's build also counts against the limit.
+	sem := make(chan struct{}, concurrency)
 
 	for _, a := range unique {
 		cacheKey, err := a.Resolver.CacheKey()
@@ -76,6 +95,14 @@ func Resolve(ctx context.Context, artifacts []Artifact, cache *Cache, emit EmitF
 		go func(a Artifact, cacheKey, outputDir string) {
 			defer wg.Done()
 
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errCh <- fmt.Errorf("artifact %q: %w", a.Key, ctx.Err())
+				return
+			}
+
 			// Acquire per-key file lock to prevent duplicate work across
 			// concurrent rigd instances.
 			unlock, err := cache.Lock(cacheKey)
@@ -102,7 +129,11 @@ func Resolve(ctx context.Context, artifacts []Artifact, cache *Cache, emit EmitF
 				emit(EventStarted, a.Key, nil)
 			}
 
-			out, resolveErr := resolveWithRetry(ctx, a.Resolver, outputDir)
+			var onRetry func(*RetryError)
+			if emit != nil {
+				onRetry = func(e *RetryError) { emit(EventRetry, a.Key, e) }
+			}
+			out, resolveErr := resolveWithRetry(ctx, a.Resolver, outputDir, retry, onRetry)
 			if resolveErr != nil {
 				if emit != nil {
 					emit(EventFailed, a.Key, resolveErr)
@@ -162,42 +193,23 @@ func checkCached(r Resolver, outputDir string) (Output, bool) {
 	return out, true
 }
 
-// resolveWithRetry calls r.Resolve, retrying on failure if r.Retryable().
-// Retryable resolvers are attempted up to 3 times with 1s, 2s backoff.
-func resolveWithRetry(ctx context.Context, r Resolver, outputDir string) (Output, error) {
-	maxAttempts := 1
-	if r.Retryable() {
-		maxAttempts = 3
-	}
-
-	backoff := time.Second
-	var lastErr error
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		if ctx.Err() != nil {
-			return Output{}, ctx.Err()
-		}
-		if attempt > 0 {
-			timer := time.NewTimer(backoff)
-			select {
-			case <-ctx.Done():
-				timer.Stop()
-				return Output{}, ctx.Err()
-			case <-timer.C:
-			}
-			backoff *= 2
-		}
-
-		out, err := r.Resolve(ctx, outputDir)
-		if err == nil {
-			return out, nil
-		}
-		lastErr = err
+// resolveWithRetry calls r.Resolve, retrying on failure per policy if
+// r.Retryable().
+func resolveWithRetry(ctx context.Context, r Resolver, outputDir string, policy RetryPolicy, onRetry func(*RetryError)) (Output, error) {
+	if !r.Retryable() {
+		policy.Attempts = 1
 	}
-	return Output{}, lastErr
+	var out Output
+	err := withRetry(ctx, policy, func() error {
+		var err error
+		out, err = r.Resolve(ctx, outputDir)
+		return err
+	}, onRetry)
+	return out, err
 }
 
 // touchLastUsed updates the mtime of a .last-used marker in outputDir.
-// A future cache eviction command can use this for LRU ordering.
+// `rig cache ls` and `rig cache prune` use it for LRU ordering.
 func touchLastUsed(outputDir string) {
 	p := filepath.Join(outputDir, ".last-used")
 	now := time.Now()
@@ -3,6 +3,7 @@ package artifact_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -102,7 +103,7 @@ func TestResolve_CacheHit(t *testing.T) {
 
 	artifacts := []artifact.Artifact{{Key: "my-artifact", Resolver: resolver}}
 
-	results, err := artifact.Resolve(context.Background(), artifacts, cache, nil)
+	results, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, nil)
 	if err != nil {
 		t.Fatalf("Resolve: %v", err)
 	}
@@ -135,7 +136,7 @@ func TestResolve_Dedup(t *testing.T) {
 		{Key: "artifact-a", Resolver: resolver}, // duplicate key
 	}
 
-	results, err := artifact.Resolve(context.Background(), artifacts, cache, nil)
+	results, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, nil)
 	if err != nil {
 		t.Fatalf("Resolve: %v", err)
 	}
@@ -167,7 +168,7 @@ func TestResolve_Parallel(t *testing.T) {
 		{Key: "artifact-3", Resolver: makeResolver("key-3")},
 	}
 
-	results, err := artifact.Resolve(context.Background(), artifacts, cache, nil)
+	results, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, nil)
 	if err != nil {
 		t.Fatalf("Resolve: %v", err)
 	}
@@ -183,6 +184,51 @@ func TestResolve_Parallel(t *testing.T) {
 	}
 }
 
+// gaugeResolver holds each resolution open briefly, recording the peak
+// number of resolutions in flight across all resolvers sharing the gauge.
+type gaugeResolver struct {
+	stubResolver
+	inFlight, peak *atomic.Int64
+}
+
+func (g *gaugeResolver) Resolve(ctx context.Context, outputDir string) (artifact.Output, error) {
+	n := g.inFlight.Add(1)
+	defer g.inFlight.Add(-1)
+	for {
+		p := g.peak.Load()
+		if n <= p || g.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	return g.stubResolver.Resolve(ctx, outputDir)
+}
+
+func TestResolve_ConcurrencyLimit(t *testing.T) {
+	cache := artifact.NewCache(t.TempDir())
+
+	var inFlight, peak atomic.Int64
+	var artifacts []artifact.Artifact
+	for i := range 6 {
+		key := fmt.Sprintf("key-%d", i)
+		artifacts = append(artifacts, artifact.Artifact{
+			Key:      "artifact-" + key,
+			Resolver: &gaugeResolver{stubResolver: stubResolver{cacheKey: key}, inFlight: &inFlight, peak: &peak},
+		})
+	}
+
+	results, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 2, nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(results) != 6 {
+		t.Errorf("got %d results, want 6", len(results))
+	}
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrent resolutions = %d, want 2", got)
+	}
+}
+
 func TestResolve_Error(t *testing.T) {
 	cache := artifact.NewCache(t.TempDir())
 
@@ -193,7 +239,7 @@ func TestResolve_Error(t *testing.T) {
 
 	artifacts := []artifact.Artifact{{Key: "bad-artifact", Resolver: resolver}}
 
-	_, err := artifact.Resolve(context.Background(), artifacts, cache, nil)
+	_, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, nil)
 	if err == nil {
 		t.Fatal("expected error from failed resolver")
 	}
@@ -210,7 +256,7 @@ func TestResolve_EmitEvents(t *testing.T) {
 		events = append(events, kind)
 	}
 
-	if _, err := artifact.Resolve(context.Background(), artifacts, cache, emit); err != nil {
+	if _, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, emit); err != nil {
 		t.Fatalf("Resolve: %v", err)
 	}
 
@@ -242,7 +288,7 @@ func TestResolve_ValidatorInvalidatesCachedResult(t *testing.T) {
 
 	artifacts := []artifact.Artifact{{Key: "val-artifact", Resolver: resolver}}
 
-	results, err := artifact.Resolve(context.Background(), artifacts, cache, nil)
+	results, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, nil)
 	if err != nil {
 		t.Fatalf("Resolve: %v", err)
 	}
@@ -273,7 +319,7 @@ func TestResolve_ValidatorAcceptsCachedResult(t *testing.T) {
 
 	artifacts := []artifact.Artifact{{Key: "val-ok", Resolver: resolver}}
 
-	results, err := artifact.Resolve(context.Background(), artifacts, cache, nil)
+	results, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, nil)
 	if err != nil {
 		t.Fatalf("Resolve: %v", err)
 	}
@@ -308,7 +354,7 @@ func TestResolve_CancelsOnFirstError(t *testing.T) {
 	}
 
 	start := time.Now()
-	_, err := artifact.Resolve(context.Background(), artifacts, cache, nil)
+	_, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, nil)
 	elapsed := time.Since(start)
 
 	if err == nil {
@@ -333,7 +379,7 @@ func TestResolve_TouchLastUsed(t *testing.T) {
 	resolver := &stubResolver{cacheKey: "touch-key"}
 	artifacts := []artifact.Artifact{{Key: "touch-artifact", Resolver: resolver}}
 
-	if _, err := artifact.Resolve(context.Background(), artifacts, cache, nil); err != nil {
+	if _, err := artifact.Resolve(context.Background(), artifacts, cache, artifact.RetryPolicy{}, 0, nil); err != nil {
 		t.Fatalf("Resolve: %v", err)
 	}
 
@@ -350,3 +396,81 @@ func TestResolve_TouchLastUsed(t *testing.T) {
 		t.Errorf(".last-used mtime is %v ago, expected recent", time.Since(info.ModTime()))
 	}
 }
+
+// flakyResolver fails its first failures Resolve calls with err.
+type flakyResolver struct {
+	stubResolver
+	attempts *atomic.Int64
+	failures int
+	err      error
+}
+
+func (f *flakyResolver) Resolve(ctx context.Context, outputDir string) (artifact.Output, error) {
+	if f.attempts.Add(1) <= int64(f.failures) {
+		return artifact.Output{}, f.err
+	}
+	return f.stubResolver.Resolve(ctx, outputDir)
+}
+
+func TestResolve_RetryEmitsEvents(t *testing.T) {
+	cache := artifact.NewCache(t.TempDir())
+
+	var called atomic.Int64
+	resolver := &flakyResolver{
+		stubResolver: stubResolver{cacheKey: "flaky-key", retryable: true},
+		attempts:     &called,
+		failures:     2,
+		err:          errors.New("connection reset by peer"),
+	}
+	artifacts := []artifact.Artifact{{Key: "flaky", Resolver: resolver}}
+
+	var retries []*artifact.RetryError
+	emit := func(kind artifact.EventKind, key string, err error) {
+		if kind == artifact.EventRetry {
+			var re *artifact.RetryError
+			if !errors.As(err, &re) {
+				t.Errorf("retry event err = %v, want *RetryError", err)
+			}
+			retries = append(retries, re)
+		}
+	}
+
+	policy := artifact.RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+	if _, err := artifact.Resolve(context.Background(), artifacts, cache, policy, 0, emit); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if called.Load() != 3 {
+		t.Errorf("Resolve called %d times, want 3", called.Load())
+	}
+	if len(retries) != 2 {
+		t.Fatalf("got %d retry events, want 2", len(retries))
+	}
+	if retries[1].Attempt != 2 || retries[1].Backoff != 2*time.Millisecond {
+		t.Errorf("second retry = attempt %d backoff %s, want attempt 2 backoff 2ms", retries[1].Attempt, retries[1].Backoff)
+	}
+	if !strings.Contains(retries[0].Error(), "connection reset by peer") {
+		t.Errorf("retry error = %q, want the attempt's error", retries[0].Error())
+	}
+}
+
+func TestResolve_PermanentErrorNotRetried(t *testing.T) {
+	cache := artifact.NewCache(t.TempDir())
+
+	var called atomic.Int64
+	resolver := &stubResolver{
+		cacheKey:   "perm-key",
+		retryable:  true,
+		resolveN:   &called,
+		resolveErr: artifact.Permanent(errors.New("manifest unknown")),
+	}
+	artifacts := []artifact.Artifact{{Key: "perm", Resolver: resolver}}
+
+	policy := artifact.RetryPolicy{Attempts: 5, Backoff: time.Millisecond}
+	_, err := artifact.Resolve(context.Background(), artifacts, cache, policy, 0, nil)
+	if err == nil || !artifact.IsPermanent(err) {
+		t.Fatalf("err = %v, want permanent error", err)
+	}
+	if called.Load() != 1 {
+		t.Errorf("Resolve called %d times, want 1", called.Load())
+	}
+}
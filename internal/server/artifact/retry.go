@@ -0,0 +1,86 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy controls how transient failures of retryable resolvers are
+// retried.
+type RetryPolicy struct {
+	Attempts int           // total attempts, including the first; < 1 means 1
+	Backoff  time.Duration // wait before the first retry, doubled after each
+}
+
+// DefaultRetry is used when no policy is configured: 3 attempts with 1s, 2s
+// backoff.
+var DefaultRetry = RetryPolicy{Attempts: 3, Backoff: time.Second}
+
+// RetryError describes a failed attempt that is about to be retried. It is
+// passed to EmitFunc with EventRetry.
+type RetryError struct {
+	Attempt  int           // the attempt that failed, starting at 1
+	Attempts int           // total attempts allowed
+	Backoff  time.Duration // wait before the next attempt
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("attempt %d of %d failed, retrying in %s: %v", e.Attempt, e.Attempts, e.Backoff, e.Err)
+}
+
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// permanentError marks an error that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, e.g. an image tag that doesn't
+// exist or a registry that rejected the credentials. Resolvers wrap such
+// errors so only transport-level failures are retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// withRetry calls fn until it succeeds, returns a permanent error, or
+// policy.Attempts is exhausted. onRetry, if non-nil, is called before each
+// backoff wait.
+func withRetry(ctx context.Context, policy RetryPolicy, fn func() error, onRetry func(*RetryError)) error {
+	attempts := max(policy.Attempts, 1)
+	backoff := policy.Backoff
+
+	for attempt := 1; ; attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := fn()
+		if err == nil || attempt == attempts || IsPermanent(err) {
+			return err
+		}
+
+		if onRetry != nil {
+			onRetry(&RetryError{Attempt: attempt, Attempts: attempts, Backoff: backoff, Err: err})
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,55 @@
+package artifact
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+)
+
+func TestReadJSONStream_Pull(t *testing.T) {
+	tests := []struct {
+		name      string
+		stream    string
+		wantErr   string
+		permanent bool
+	}{
+		{"success", `{"status":"Pulling"}` + "\n" + `{"status":"Downloaded newer image"}`, "", false},
+		{"unknown tag", `{"status":"Pulling"}{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}`, "manifest unknown", true},
+		{"transport", `{"error":"read tcp 10.0.0.1:443: connection reset by peer"}`, "connection reset", false},
+		{"truncated", `{"status":"Pul`, "read response", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := readJSONStream(strings.NewReader(tt.stream))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err != nil {
+				err = classifyPullError(err)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent = %v, want %v", IsPermanent(err), tt.permanent)
+			}
+		})
+	}
+}
+
+func TestClassifyPullError(t *testing.T) {
+	if !IsPermanent(classifyPullError(errdefs.NotFound(errors.New("no such image")))) {
+		t.Error("errdefs not found should be permanent")
+	}
+	if !IsPermanent(classifyPullError(errors.New("pull access denied for private/img"))) {
+		t.Error("access denied should be permanent")
+	}
+	if IsPermanent(classifyPullError(errors.New("dial tcp: i/o timeout"))) {
+		t.Error("i/o timeout should be retried")
+	}
+}
@@ -0,0 +1,39 @@
+package dockerutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/network"
+)
+
+// NetworkName returns the Docker network name for an environment instance.
+func NetworkName(instanceID string) string {
+	return "rig-" + instanceID
+}
+
+// CreateNetwork creates a bridge network with the given name. Containers
+// attached to it can resolve each other by alias.
+func CreateNetwork(ctx context.Context, name string) error {
+	cli, err := Client()
+	if err != nil {
+		return fmt.Errorf("docker client: %w", err)
+	}
+	if _, err := cli.NetworkCreate(ctx, name, network.CreateOptions{
+		Driver: "bridge",
+		Labels: map[string]string{"rig": "true"},
+	}); err != nil {
+		return fmt.Errorf("create network %q: %w", name, err)
+	}
+	return nil
+}
+
+// RemoveNetwork removes the named network. Errors are returned so callers
+// can decide whether to report them; teardown paths typically ignore them.
+func RemoveNetwork(ctx context.Context, name string) error {
+	cli, err := Client()
+	if err != nil {
+		return fmt.Errorf("docker client: %w", err)
+	}
+	return cli.NetworkRemove(ctx, name)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/matgreaves/rig/internal/server/artifact"
+	"github.com/matgreaves/rig/internal/server/service"
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+// DryRun checks what ValidateEnvironment cannot see from the spec alone:
+// that each service's artifacts have usable inputs (a Go module directory
+// exists, an image reference is well-formed) and that process commands
+// can be found. Nothing is built, pulled or started. env must already
+// have passed ValidateEnvironment.
+func DryRun(env *spec.Environment, registry *service.Registry) []string {
+	var errs []string
+	for _, name := range realSortedServiceNames(env.Services) {
+		svc := env.Services[name]
+		svcType, err := registry.Get(svc.Type)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("service %q: %v", name, err))
+			continue
+		}
+		if svc.Type == "process" {
+			if err := checkProcessCommand(svc, env.Dir); err != nil {
+				errs = append(errs, fmt.Sprintf("service %q: %v", name, err))
+			}
+		}
+		provider, ok := svcType.(service.ArtifactProvider)
+		if !ok {
+			continue
+		}
+		arts, err := provider.Artifacts(service.ArtifactParams{
+			ServiceName: name,
+			Spec:        svc,
+			Dir:         env.Dir,
+			HostEnv:     env.HostEnv,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("service %q: artifacts: %v", name, err))
+			continue
+		}
+		for _, a := range arts {
+			if c, ok := a.Resolver.(artifact.Checker); ok {
+				if err := c.Check(); err != nil {
+					errs = append(errs, fmt.Sprintf("service %q: %v", name, err))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// checkProcessCommand verifies that a process service's command exists:
+// a path is resolved against the service's working directory, a bare name
+// is looked up on PATH.
+func checkProcessCommand(svc spec.Service, envDir string) error {
+	var cfg service.ProcessConfig
+	if svc.Config != nil {
+		if err := json.Unmarshal(svc.Config, &cfg); err != nil {
+			return fmt.Errorf("invalid process config: %w", err)
+		}
+	}
+	if cfg.Command == "" {
+		return fmt.Errorf("process config missing required \"command\" field")
+	}
+	if !strings.ContainsRune(cfg.Command, filepath.Separator) {
+		if _, err := exec.LookPath(cfg.Command); err != nil {
+			return fmt.Errorf("command: %w", err)
+		}
+		return nil
+	}
+	path := cfg.Command
+	if !filepath.IsAbs(path) {
+		dir := cfg.Dir
+		if dir == "" || !filepath.IsAbs(dir) {
+			dir = filepath.Join(envDir, dir)
+		}
+		path = filepath.Join(dir, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("command: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -20,6 +22,7 @@ const (
 	EventArtifactCompleted EventType = "artifact.completed"
 	EventArtifactFailed    EventType = "artifact.failed"
 	EventArtifactCached    EventType = "artifact.cached"
+	EventArtifactRetry     EventType = "artifact.retry"
 
 	// Service lifecycle.
 	EventIngressPublished EventType = "ingress.published"
@@ -30,6 +33,7 @@ const (
 	EventServiceInit      EventType = "service.init"
 	EventServiceReady     EventType = "service.ready"
 	EventServiceFailed    EventType = "service.failed"
+	EventServiceRestarted EventType = "service.restarted"
 	EventServiceStopping  EventType = "service.stopping"
 	EventServiceStopped   EventType = "service.stopped"
 	EventServiceLog       EventType = "service.log"
@@ -55,10 +59,22 @@ const (
 
 	// Traffic observation.
 	EventRequestCompleted      EventType = "request.completed"
+	EventRequestMocked         EventType = "request.mocked"
 	EventConnectionOpened      EventType = "connection.opened"
 	EventConnectionClosed      EventType = "connection.closed"
+	EventHTTPConnectionOpened  EventType = "http.connection.opened"
+	EventHTTPConnectionClosed  EventType = "http.connection.closed"
 	EventGRPCCallCompleted     EventType = "grpc.call.completed"
+	EventGRPCStreamOpened      EventType = "grpc.stream.opened"
+	EventGRPCStreamMessage     EventType = "grpc.stream.message"
+	EventGRPCStreamClosed      EventType = "grpc.stream.closed"
 	EventKafkaRequestCompleted EventType = "kafka.request.completed"
+	EventRedisCommandCompleted EventType = "redis.command.completed"
+	EventNATSMessage           EventType = "nats.message"
+	EventMongoCommand          EventType = "mongo.command"
+	EventWebSocketOpened       EventType = "websocket.opened"
+	EventWebSocketClosed       EventType = "websocket.closed"
+	EventFaultInjected         EventType = "fault.injected"
 )
 
 // LogEntry holds a line of service output.
@@ -112,6 +128,27 @@ type RequestInfo struct {
 	ResponseHeaders       map[string][]string `json:"response_headers,omitempty"`
 	ResponseBody          []byte              `json:"response_body,omitempty"`
 	ResponseBodyTruncated bool                `json:"response_body_truncated,omitempty"`
+
+	// ProxyInjected marks responses generated by the proxy rather than the
+	// target service, such as a 413 from an ingress body size limit.
+	ProxyInjected bool `json:"proxy_injected,omitempty"`
+
+	// ProxyOverheadMs is the time the request spent in the observe proxy
+	// before it had a connection to the target, dialing included. It is
+	// not part of LatencyMs, which is the target's alone.
+	ProxyOverheadMs float64 `json:"proxy_overhead_ms,omitempty"`
+
+	// Label is the caller-supplied X-Rig-Label header value, used to
+	// correlate a specific test request in the traffic log.
+	Label string `json:"label,omitempty"`
+
+	// TraceID, SpanID and ParentSpanID thread the request into a causal
+	// chain via the X-Rig-Trace header. ParentSpanID is the span of the
+	// call that caused this one, empty for the first hop or when the
+	// caller didn't forward the header.
+	TraceID      string `json:"trace_id,omitempty"`
+	SpanID       string `json:"span_id,omitempty"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
 }
 
 // ConnectionInfo captures an observed TCP connection.
@@ -122,6 +159,54 @@ type ConnectionInfo struct {
 	BytesIn    int64   `json:"bytes_in"`
 	BytesOut   int64   `json:"bytes_out"`
 	DurationMs float64 `json:"duration_ms"`
+
+	// CloseReason is set when the proxy closed the connection itself,
+	// e.g. "idle_timeout" after the environment's TCP idle timeout, or
+	// "upgraded" for an HTTP connection handed off to a websocket.
+	CloseReason string `json:"close_reason,omitempty"`
+
+	// Requests and HTTPProtocol are set on http.connection.closed: how
+	// many requests the client sent over the connection, and the protocol
+	// they used ("HTTP/1.1", "HTTP/1.0", "h2c" or "h2"). A client that
+	// opens a fresh connection per request shows Requests of 1 on each.
+	Requests     int64  `json:"requests,omitempty"`
+	HTTPProtocol string `json:"http_protocol,omitempty"`
+
+	// PreviewIn and PreviewOut are the first bytes sent client → target
+	// and target → client, captured on connection.closed when the
+	// environment sets observe_body_limit. The truncated flags mark a
+	// direction that carried more than the limit.
+	PreviewIn           []byte `json:"preview_in,omitempty"`
+	PreviewInTruncated  bool   `json:"preview_in_truncated,omitempty"`
+	PreviewOut          []byte `json:"preview_out,omitempty"`
+	PreviewOutTruncated bool   `json:"preview_out_truncated,omitempty"`
+}
+
+// WebSocketInfo captures an observed websocket connection. Frame counts
+// include control frames (ping, pong, close); byte counts include frame
+// headers. Both are zero on websocket.opened.
+type WebSocketInfo struct {
+	Source     string  `json:"source"`
+	Target     string  `json:"target"`
+	Ingress    string  `json:"ingress"`
+	Path       string  `json:"path"`
+	FramesIn   int64   `json:"frames_in"`
+	FramesOut  int64   `json:"frames_out"`
+	BytesIn    int64   `json:"bytes_in"`
+	BytesOut   int64   `json:"bytes_out"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// AssertionInfo is the structured form of a failed test assertion, attached
+// to test.note events by the SDK's assertion helpers (e.g. env.TB().Equal).
+// Field names what was checked ("status", "condition") when the helper knows.
+// Free-form failures (Errorf, Fatal) carry only the event's Error.
+type AssertionInfo struct {
+	File  string `json:"file,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Field string `json:"field,omitempty"`
+	Want  string `json:"want,omitempty"`
+	Got   string `json:"got,omitempty"`
 }
 
 // DiagnosticSnapshot captures the state of all services when a progress stall
@@ -138,6 +223,39 @@ type ServiceSnapshot struct {
 	WaitingOn []string `json:"waiting_on,omitempty"`
 }
 
+// GRPCStreamInfo captures a streaming gRPC call. grpc.stream.opened carries
+// the call's identity and request metadata, each grpc.stream.message one
+// message (direction "in" from the client, "out" from the target), and
+// grpc.stream.closed the final status, message counts and sizes.
+type GRPCStreamInfo struct {
+	Source          string              `json:"source"`
+	Target          string              `json:"target"`
+	Ingress         string              `json:"ingress"`
+	Service         string              `json:"service"` // "pkg.ServiceName"
+	Method          string              `json:"method"`  // "MethodName"
+	RequestMetadata map[string][]string `json:"request_metadata,omitempty"`
+
+	Direction     string          `json:"direction,omitempty"` // "in" or "out"
+	Body          []byte          `json:"body,omitempty"`      // length-prefixed frame
+	BodyTruncated bool            `json:"body_truncated,omitempty"`
+	BodyDecoded   json.RawMessage `json:"body_decoded,omitempty"`
+	Size          int64           `json:"size,omitempty"` // full frame size
+
+	GRPCStatus       string              `json:"grpc_status,omitempty"`
+	GRPCMessage      string              `json:"grpc_message,omitempty"`
+	MessagesIn       int64               `json:"messages_in,omitempty"`
+	MessagesOut      int64               `json:"messages_out,omitempty"`
+	RequestSize      int64               `json:"request_size,omitempty"`
+	ResponseSize     int64               `json:"response_size,omitempty"`
+	DurationMs       float64             `json:"duration_ms,omitempty"`
+	ResponseMetadata map[string][]string `json:"response_metadata,omitempty"`
+
+	// TraceID, SpanID and ParentSpanID are as for RequestInfo.
+	TraceID      string `json:"trace_id,omitempty"`
+	SpanID       string `json:"span_id,omitempty"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
+}
+
 // KafkaRequestInfo captures an observed Kafka request/response pair.
 type KafkaRequestInfo struct {
 	Source        string  `json:"source"`
@@ -152,6 +270,62 @@ type KafkaRequestInfo struct {
 	ResponseSize  int64   `json:"response_size"`
 }
 
+// RedisCommandInfo captures an observed Redis command and its reply.
+type RedisCommandInfo struct {
+	Source       string  `json:"source"`
+	Target       string  `json:"target"`
+	Ingress      string  `json:"ingress"`
+	Command      string  `json:"command"`               // "GET", "SET", etc.
+	Key          string  `json:"key,omitempty"`         // first key argument
+	ReplyType    string  `json:"reply_type"`            // "string", "error", "integer", "bulk", "array", "null", ...
+	RedisError   string  `json:"redis_error,omitempty"` // error reply text
+	LatencyMs    float64 `json:"latency_ms"`
+	RequestSize  int64   `json:"request_size"`
+	ResponseSize int64   `json:"response_size"`
+}
+
+// MongoCommandInfo captures an observed MongoDB command and its reply.
+type MongoCommandInfo struct {
+	Source       string  `json:"source"`
+	Target       string  `json:"target"`
+	Ingress      string  `json:"ingress"`
+	Command      string  `json:"command"`               // "find", "insert", "aggregate", etc.
+	Collection   string  `json:"collection,omitempty"`  // empty for database commands
+	Database     string  `json:"database,omitempty"`    // the command's $db
+	MongoError   string  `json:"mongo_error,omitempty"` // "CodeName: errmsg" when the reply has ok: 0
+	LatencyMs    float64 `json:"latency_ms"`
+	RequestSize  int64   `json:"request_size"`
+	ResponseSize int64   `json:"response_size"`
+}
+
+// FaultInfo describes a fault an observe proxy injected on an edge. Method
+// and Path are set when the fault hit an HTTP or gRPC request rather than a
+// connection.
+type FaultInfo struct {
+	Source     string  `json:"source"`
+	Target     string  `json:"target"`
+	Ingress    string  `json:"ingress"`
+	Kind       string  `json:"kind"`                  // "latency", "drop" or "error"
+	DelayMs    float64 `json:"delay_ms,omitempty"`    // latency faults
+	StatusCode int     `json:"status_code,omitempty"` // error faults
+	Method     string  `json:"method,omitempty"`
+	Path       string  `json:"path,omitempty"`
+}
+
+// NATSMessageInfo captures an observed NATS message operation: a PUB from a
+// client, a SUB registering interest, or a MSG delivered by the server.
+type NATSMessageInfo struct {
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	Ingress     string `json:"ingress"`
+	Op          string `json:"op"` // "PUB", "SUB", or "MSG"
+	Subject     string `json:"subject"`
+	ReplyTo     string `json:"reply_to,omitempty"`
+	Queue       string `json:"queue,omitempty"` // SUB queue group
+	PayloadSize int64  `json:"payload_size"`
+	HeaderSize  int64  `json:"header_size,omitempty"`
+}
+
 // GRPCCallInfo captures an observed gRPC call.
 type GRPCCallInfo struct {
 	Source           string              `json:"source"`
@@ -173,6 +347,15 @@ type GRPCCallInfo struct {
 	ResponseBodyTruncated bool            `json:"response_body_truncated,omitempty"`
 	RequestBodyDecoded    json.RawMessage `json:"request_body_decoded,omitempty"`
 	ResponseBodyDecoded   json.RawMessage `json:"response_body_decoded,omitempty"`
+
+	// ProxyInjected marks calls answered by the proxy rather than the
+	// target service, such as a method denied by an egress allowlist.
+	ProxyInjected bool `json:"proxy_injected,omitempty"`
+
+	// TraceID, SpanID and ParentSpanID are as for RequestInfo.
+	TraceID      string `json:"trace_id,omitempty"`
+	SpanID       string `json:"span_id,omitempty"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
 }
 
 // Event is a single entry in the event log.
@@ -188,18 +371,33 @@ type Event struct {
 	Callback     *CallbackRequest    `json:"callback,omitempty"`
 	Result       *CallbackResponse   `json:"result,omitempty"`
 	Error        string              `json:"error,omitempty"`
+	ExitCode     *int                `json:"exit_code,omitempty"`    // service.failed, service.restarted: exit status of a crashed process or container
+	StopTimeout  string              `json:"stop_timeout,omitempty"` // service.stopping: the service's configured stop timeout, e.g. "5s" or "0s"
+	Outputs      map[string]string   `json:"outputs,omitempty"`      // service.ready: values set by the service's init hooks
 	Request      *RequestInfo        `json:"request,omitempty"`
 	Connection   *ConnectionInfo     `json:"connection,omitempty"`
 	GRPCCall     *GRPCCallInfo       `json:"grpc_call,omitempty"`
+	GRPCStream   *GRPCStreamInfo     `json:"grpc_stream,omitempty"`
 	KafkaRequest *KafkaRequestInfo   `json:"kafka_request,omitempty"`
+	RedisCommand *RedisCommandInfo   `json:"redis_command,omitempty"`
+	NATSMessage  *NATSMessageInfo    `json:"nats_message,omitempty"`
+	MongoCommand *MongoCommandInfo   `json:"mongo_command,omitempty"`
+	WebSocket    *WebSocketInfo      `json:"websocket,omitempty"`
+	Fault        *FaultInfo          `json:"fault,omitempty"`
 	Diagnostic   *DiagnosticSnapshot `json:"diagnostic,omitempty"`
 	EnvDir       string              `json:"env_dir,omitempty"`
 	Message      string              `json:"message,omitempty"`
+	Outcome      string              `json:"outcome,omitempty"` // environment.down: "passed", "failed", or "crashed"
+	Assertion    *AssertionInfo      `json:"assertion,omitempty"`
 	// Ingresses is populated on environment.up. It maps service name to a
 	// map of ingress name to resolved endpoint, giving clients everything
 	// they need to connect to any service without a follow-up GET request.
-	Ingresses    map[string]map[string]spec.ResolvedEndpoint `json:"ingresses,omitempty"`
-	Timestamp    time.Time                                   `json:"timestamp"`
+	Ingresses map[string]map[string]spec.ResolvedEndpoint `json:"ingresses,omitempty"`
+	// Resolved is also populated on environment.up: the same snapshot
+	// GET /environments/{id} returns, with every real service's resolved
+	// ingresses, egresses and attributes.
+	Resolved  *spec.ResolvedEnvironment `json:"resolved,omitempty"`
+	Timestamp time.Time                 `json:"timestamp"`
 }
 
 // EventLog is a persistent, ordered event log. Events are stored in two
@@ -208,12 +406,22 @@ type Event struct {
 // scans (WaitFor, buildResolvedEnvironment) fast by avoiding high-volume
 // log output. When the full timeline is needed (Events, Subscribe, log dump),
 // both slices are zip-merged by sequence number.
+//
+// A log may be capped with SetMaxEvents, in which case the oldest service
+// output and traffic events are dropped to make room. Lifecycle and failure
+// events are always kept, so outcomes derived from the log don't change.
 type EventLog struct {
 	mu        sync.RWMutex
 	lifecycle []Event // everything except service.log
 	logEvents []Event // service.log only
 	seq       uint64
+	max       int           // cap on held events; 0 = unlimited
+	droppable int           // held events that evictable allows dropping
+	dropped   int           // events dropped to stay under max
 	notify    chan struct{} // closed and replaced on each new event
+	tap       func(Event)   // optional; called with each published event
+	tee       *json.Encoder // optional; each published event is written as a JSON line
+	teeErr    error         // first write error on tee; the tee is dropped after it
 }
 
 // NewEventLog creates an empty event log.
@@ -223,6 +431,139 @@ func NewEventLog() *EventLog {
 	}
 }
 
+// SetMaxEvents caps the number of events held in memory at n; n <= 0 removes
+// the cap. When a publish takes the log over the cap, the oldest evictable
+// events (service output and traffic) are dropped, in a batch so the next
+// publishes don't each pay for it. Events streamed by Tee and Tap are not
+// affected. Lifecycle and failure events are never dropped, so a log with
+// too many of them can still exceed n.
+func (l *EventLog) SetMaxEvents(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max(n, 0)
+	if l.over() {
+		l.evict()
+	}
+}
+
+// Dropped returns the number of events dropped to stay under the cap set by
+// SetMaxEvents.
+func (l *EventLog) Dropped() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.dropped
+}
+
+// evictable reports whether events of type t may be dropped from a capped
+// log: service output and observed traffic. Outcomes, ready durations and
+// the failure timeline only depend on the remaining types.
+func evictable(t EventType) bool {
+	switch t {
+	case EventServiceLog,
+		EventRequestCompleted, EventRequestMocked,
+		EventConnectionOpened, EventConnectionClosed,
+		EventHTTPConnectionOpened, EventHTTPConnectionClosed,
+		EventGRPCCallCompleted, EventGRPCStreamOpened, EventGRPCStreamMessage, EventGRPCStreamClosed,
+		EventKafkaRequestCompleted, EventRedisCommandCompleted, EventNATSMessage, EventMongoCommand,
+		EventWebSocketOpened, EventWebSocketClosed, EventFaultInjected:
+		return true
+	}
+	return false
+}
+
+// over reports whether the log holds more events than its cap and some of
+// them can be dropped. Caller must hold l.mu.
+func (l *EventLog) over() bool {
+	return l.max > 0 && l.droppable > 0 && len(l.lifecycle)+len(l.logEvents) > l.max
+}
+
+// evict drops the oldest evictable events, across both slices, until the
+// log is a tenth under its cap or nothing evictable is left. The slices are
+// copied so the dropped events' memory is released. Caller must hold l.mu.
+func (l *EventLog) evict() {
+	excess := len(l.lifecycle) + len(l.logEvents) - (l.max - l.max/10)
+
+	// Walk the evictable events in sequence order to find the newest one
+	// to drop.
+	var cutoff uint64
+	i, j, n := 0, 0, 0
+walk:
+	for n < excess {
+		for i < len(l.lifecycle) && !evictable(l.lifecycle[i].Type) {
+			i++
+		}
+		switch {
+		case j < len(l.logEvents) && (i == len(l.lifecycle) || l.logEvents[j].Seq < l.lifecycle[i].Seq):
+			cutoff = l.logEvents[j].Seq
+			j++
+		case i < len(l.lifecycle):
+			cutoff = l.lifecycle[i].Seq
+			i++
+		default:
+			break walk // nothing evictable left
+		}
+		n++
+	}
+	if n == 0 {
+		return
+	}
+
+	if n > j {
+		kept := make([]Event, 0, len(l.lifecycle)-(n-j))
+		for _, e := range l.lifecycle {
+			if e.Seq > cutoff || !evictable(e.Type) {
+				kept = append(kept, e)
+			}
+		}
+		l.lifecycle = kept
+	}
+	if j > 0 {
+		l.logEvents = slices.Clone(l.logEvents[j:])
+	}
+	l.droppable -= n
+	l.dropped += n
+}
+
+// Tap registers fn to be called with every event published after this
+// point, in sequence order. fn runs under the log's lock and must not block
+// or publish to the same log.
+func (l *EventLog) Tap(fn func(Event)) {
+	l.mu.Lock()
+	l.tap = fn
+	l.mu.Unlock()
+}
+
+// Tee writes the events already in the log and every event published after
+// this point to w, one JSON object per line, so the log survives a process
+// that dies before it can be written out. Writes happen under the log's
+// lock. The first write error drops the tee; TeeErr reports it. Tee(nil)
+// stops teeing.
+func (l *EventLog) Tee(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tee = nil
+	l.teeErr = nil
+	if w == nil {
+		return
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, e := range mergeSorted(l.lifecycle, l.logEvents) {
+		if err := enc.Encode(e); err != nil {
+			l.teeErr = err
+			return
+		}
+	}
+	l.tee = enc
+}
+
+// TeeErr returns the write error that stopped the tee, if any.
+func (l *EventLog) TeeErr() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.teeErr
+}
+
 // Publish appends an event to the log with the next sequence number and
 // the current timestamp, then wakes all waiters.
 func (l *EventLog) Publish(event Event) {
@@ -237,6 +578,21 @@ func (l *EventLog) Publish(event Event) {
 	} else {
 		l.lifecycle = append(l.lifecycle, event)
 	}
+	if evictable(event.Type) {
+		l.droppable++
+	}
+	if l.over() {
+		l.evict()
+	}
+	if l.tap != nil {
+		l.tap(event)
+	}
+	if l.tee != nil {
+		if err := l.tee.Encode(event); err != nil {
+			l.tee = nil
+			l.teeErr = err
+		}
+	}
 	ch := l.notify
 	l.notify = make(chan struct{})
 	l.mu.Unlock()
@@ -263,6 +619,14 @@ func (l *EventLog) LifecycleEvents() []Event {
 	return out
 }
 
+// LifecycleSince returns a snapshot of lifecycle events with sequence
+// number > seq.
+func (l *EventLog) LifecycleSince(seq uint64) []Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return slices.Clone(l.lifecycleSince(seq))
+}
+
 // Since returns all events (lifecycle + log) with sequence number > seq,
 // merged by sequence number.
 func (l *EventLog) Since(seq uint64) []Event {
@@ -1,8 +1,11 @@
 package server_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -552,3 +555,80 @@ func TestEventLog_ConcurrentMixedPublish(t *testing.T) {
 		t.Errorf("LifecycleEvents: expected %d, got %d", n-logCount, len(lc))
 	}
 }
+
+func TestEventLog_Tee(t *testing.T) {
+	log := server.NewEventLog()
+	log.Publish(server.Event{Type: server.EventServiceStarting, Service: "a"})
+
+	var buf bytes.Buffer
+	log.Tee(&buf)
+	log.Publish(server.Event{Type: server.EventServiceReady, Service: "a"})
+	log.Tee(nil)
+	log.Publish(server.Event{Type: server.EventServiceStopping, Service: "a"})
+
+	var got []server.EventType
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var ev server.Event
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ev.Type)
+	}
+	want := []server.EventType{server.EventServiceStarting, server.EventServiceReady}
+	if !slices.Equal(got, want) {
+		t.Errorf("teed events = %v, want %v", got, want)
+	}
+	if err := log.TeeErr(); err != nil {
+		t.Errorf("TeeErr = %v", err)
+	}
+}
+
+func TestEventLog_MaxEvents(t *testing.T) {
+	log := server.NewEventLog()
+	log.SetMaxEvents(10)
+
+	log.Publish(server.Event{Type: server.EventServiceStarting, Service: "api"})
+	for i := range 20 {
+		log.Publish(server.Event{Type: server.EventServiceLog, Service: "api", Log: &server.LogEntry{Data: fmt.Sprint(i)}})
+		log.Publish(server.Event{Type: server.EventRequestCompleted, Request: &server.RequestInfo{Path: fmt.Sprint("/", i)}})
+		if i == 10 {
+			log.Publish(server.Event{Type: server.EventTestNote, Error: "boom"})
+		}
+	}
+	log.Publish(server.Event{Type: server.EventServiceReady, Service: "api"})
+
+	events := log.Events()
+	if len(events) > 10 {
+		t.Errorf("held %d events, want at most 10", len(events))
+	}
+	if got := log.Dropped(); got != 43-len(events) {
+		t.Errorf("Dropped() = %d, want %d", got, 43-len(events))
+	}
+	for _, typ := range []server.EventType{server.EventServiceStarting, server.EventTestNote, server.EventServiceReady} {
+		if !slices.ContainsFunc(events, func(e server.Event) bool { return e.Type == typ }) {
+			t.Errorf("%s was dropped", typ)
+		}
+	}
+	// The newest traffic survives, in sequence order.
+	last := events[len(events)-2]
+	if last.Type != server.EventRequestCompleted || last.Request.Path != "/19" {
+		t.Errorf("second to last event = %s %+v, want the last request", last.Type, last.Request)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Seq <= events[i-1].Seq {
+			t.Fatalf("events out of order at %d: %d after %d", i, events[i].Seq, events[i-1].Seq)
+		}
+	}
+}
+
+func TestEventLog_MaxEventsKeepsLifecycle(t *testing.T) {
+	log := server.NewEventLog()
+	log.SetMaxEvents(2)
+	for range 5 {
+		log.Publish(server.Event{Type: server.EventServiceStarting})
+	}
+	if got := len(log.Events()); got != 5 || log.Dropped() != 0 {
+		t.Errorf("held %d events with %d dropped, want all 5 lifecycle events kept", got, log.Dropped())
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+)
+
+// eventSocketBuffer is the number of encoded events queued per consumer.
+// A consumer that falls further behind has events dropped rather than
+// stalling the publish path.
+const eventSocketBuffer = 4096
+
+// EventSocket streams events from every environment to consumers attached
+// to a Unix domain socket, one JSON object per line. Consumers only see
+// events published while they are connected.
+type EventSocket struct {
+	ln   net.Listener
+	path string
+
+	mu      sync.Mutex
+	clients map[*eventSocketClient]struct{}
+	closed  bool
+}
+
+type eventSocketClient struct {
+	conn net.Conn
+	ch   chan []byte
+}
+
+// socketEvent is the wire form of an event on the socket. The environment
+// ID is added so consumers can tell concurrent environments apart.
+type socketEvent struct {
+	EnvironmentID string `json:"environment_id"`
+	Event
+}
+
+// ListenEventSocket listens on a Unix socket at path, replacing any stale
+// socket file left by a previous rigd.
+func ListenEventSocket(path string) (*EventSocket, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	s := &EventSocket{
+		ln:      ln,
+		path:    path,
+		clients: make(map[*eventSocketClient]struct{}),
+	}
+	go s.acceptLoop()
+	return s, nil
+}
+
+func (s *EventSocket) acceptLoop() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		c := &eventSocketClient{conn: conn, ch: make(chan []byte, eventSocketBuffer)}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.clients[c] = struct{}{}
+		s.mu.Unlock()
+		go s.serve(c)
+	}
+}
+
+// serve writes queued events to a consumer until it disconnects or the
+// socket is closed.
+func (s *EventSocket) serve(c *eventSocketClient) {
+	defer c.conn.Close()
+	for line := range c.ch {
+		if _, err := c.conn.Write(line); err != nil {
+			s.remove(c)
+			return
+		}
+	}
+}
+
+func (s *EventSocket) remove(c *eventSocketClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[c]; ok {
+		delete(s.clients, c)
+		close(c.ch)
+	}
+}
+
+// Write queues an event from environment envID for every connected
+// consumer. It never blocks.
+func (s *EventSocket) Write(envID string, ev Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.clients) == 0 {
+		return
+	}
+	line, err := json.Marshal(socketEvent{EnvironmentID: envID, Event: ev})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	for c := range s.clients {
+		select {
+		case c.ch <- line:
+		default:
+		}
+	}
+}
+
+// Close stops accepting consumers, disconnects existing ones after their
+// queued events are flushed, and removes the socket file.
+func (s *EventSocket) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.clients {
+		delete(s.clients, c)
+		close(c.ch)
+	}
+	s.mu.Unlock()
+	err := s.ln.Close()
+	os.Remove(s.path)
+	return err
+}
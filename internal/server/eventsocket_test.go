@@ -0,0 +1,91 @@
+package server_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/internal/server"
+)
+
+func TestEventSocket_StreamsTappedEvents(t *testing.T) {
+	// Unix socket paths are length-limited, so avoid t.TempDir's long names.
+	dir, err := os.MkdirTemp("", "rig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.sock")
+
+	sock, err := server.ListenEventSocket(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sock.Close()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	log := server.NewEventLog()
+	log.Tap(func(ev server.Event) { sock.Write("env-1", ev) })
+
+	// The accept loop registers the consumer asynchronously; publish until
+	// the first line arrives.
+	lines := make(chan string, 16)
+	go func() {
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		log.Publish(server.Event{Type: server.EventServiceReady, Service: "api"})
+		select {
+		case line := <-lines:
+			var got struct {
+				EnvironmentID string `json:"environment_id"`
+				Seq           uint64 `json:"seq"`
+				Type          string `json:"type"`
+				Service       string `json:"service"`
+			}
+			if err := json.Unmarshal([]byte(line), &got); err != nil {
+				t.Fatalf("decode %q: %v", line, err)
+			}
+			if got.EnvironmentID != "env-1" || got.Type != "service.ready" || got.Service != "api" || got.Seq == 0 {
+				t.Errorf("got %+v", got)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("timed out waiting for event on socket")
+		}
+	}
+}
+
+func TestEventSocket_ReplacesStaleSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "rig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.sock")
+
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sock, err := server.ListenEventSocket(path)
+	if err != nil {
+		t.Fatalf("listen over stale file: %v", err)
+	}
+	sock.Close()
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/matgreaves/rig/internal/server/service"
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+// Graph is the service topology of an environment: a node per service and
+// an edge per egress. Nodes injected by rig (the ~test node, observe
+// proxies and balancers) are omitted, and an edge routed through a proxy
+// points at the service the proxy forwards to. An egress to a scaled
+// service has an edge to each instance.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a service in the graph.
+type GraphNode struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// GraphEdge is an egress from one service to another's ingress.
+type GraphEdge struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Egress   string        `json:"egress"`
+	Ingress  string        `json:"ingress"`
+	Protocol spec.Protocol `json:"protocol,omitempty"`
+
+	// External is the URL of an egress to an address outside the
+	// environment. To and Ingress are empty for such edges.
+	External string `json:"external,omitempty"`
+}
+
+// BuildGraph returns the topology of env, sorted for stable output. It
+// accepts specs both before and after InsertExternalNodes, ExpandScale and
+// TransformObserve. cmd/rig's rigdata.GraphFromSpec mirrors it for spec
+// files; TestBuildGraph_Parity keeps the two in step.
+func BuildGraph(env *spec.Environment) Graph {
+	g := Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	for _, name := range sortedKeys(env.Services) {
+		svc := env.Services[name]
+		if svc.Injected {
+			continue
+		}
+		g.Nodes = append(g.Nodes, GraphNode{Name: name, Type: svc.Type})
+
+		egressNames := make([]string, 0, len(svc.Egresses))
+		for egName := range svc.Egresses {
+			egressNames = append(egressNames, egName)
+		}
+		sort.Strings(egressNames)
+		for _, egName := range egressNames {
+			targets := []spec.EgressSpec{svc.Egresses[egName]}
+			// A balancer fans out to the instances of a scaled service.
+			if lb, ok := env.Services[targets[0].Service]; ok && lb.Type == "balancer" {
+				targets = targets[:0]
+				for _, backend := range sortedEgressNames(lb.Egresses) {
+					targets = append(targets, lb.Egresses[backend])
+				}
+			}
+			for _, eg := range targets {
+				// Look through observe proxies to the real target.
+				if target, ok := env.Services[eg.Service]; ok && target.Injected && target.Type == "proxy" {
+					eg = target.Egresses["target"]
+				}
+				if target, ok := env.Services[eg.Service]; ok && target.Type == "external" {
+					var ext service.ExternalConfig
+					json.Unmarshal(target.Config, &ext)
+					eg = spec.EgressSpec{External: ext.URL}
+				}
+				edge := GraphEdge{
+					From:    name,
+					To:      eg.Service,
+					Egress:  egName,
+					Ingress: eg.Ingress,
+				}
+				if eg.External != "" {
+					edge.External = eg.External
+					edge.Protocol = spec.HTTP
+				} else if target, ok := env.Services[eg.Service]; ok {
+					edge.Protocol = target.Ingresses[eg.Ingress].Protocol
+				}
+				g.Edges = append(g.Edges, edge)
+			}
+		}
+	}
+	return g
+}
+
+// handleGetGraph handles GET /environments/{id}/graph.
+func (s *Server) handleGetGraph(w http.ResponseWriter, r *http.Request) {
+	inst, ok := s.getInstance(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, BuildGraph(inst.spec))
+}
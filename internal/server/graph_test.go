@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+func TestBuildGraph_LooksThroughProxies(t *testing.T) {
+	env := &spec.Environment{
+		Name:    "test",
+		Observe: true,
+		Services: map[string]spec.Service{
+			"api": {
+				Type:      "go",
+				Ingresses: map[string]spec.IngressSpec{"default": {Protocol: spec.HTTP}},
+				Egresses: map[string]spec.EgressSpec{
+					"db":    {Service: "db"},
+					"admin": {Service: "db", Ingress: "admin"},
+				},
+			},
+			"db": {
+				Type: "postgres",
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.TCP},
+					"admin":   {Protocol: spec.HTTP},
+				},
+			},
+		},
+	}
+	ResolveDefaults(env)
+	before := BuildGraph(env)
+
+	InsertTestNode(env)
+	TransformObserve(env)
+	after := BuildGraph(env)
+
+	want := Graph{
+		Nodes: []GraphNode{{Name: "api", Type: "go"}, {Name: "db", Type: "postgres"}},
+		Edges: []GraphEdge{
+			{From: "api", To: "db", Egress: "admin", Ingress: "admin", Protocol: spec.HTTP},
+			{From: "api", To: "db", Egress: "db", Ingress: "default", Protocol: spec.TCP},
+		},
+	}
+	if !reflect.DeepEqual(before, want) {
+		t.Errorf("graph before transform = %+v, want %+v", before, want)
+	}
+	if !reflect.DeepEqual(after, want) {
+		t.Errorf("graph after transform = %+v, want %+v", after, want)
+	}
+}
+
+// TestBuildGraph_Parity pins the graph for a shared fixture. cmd/rig
+// re-implements BuildGraph for spec files and checks the same fixture in
+// TestGraphFromSpec_Parity, so a change here must be mirrored there.
+func TestBuildGraph_Parity(t *testing.T) {
+	data, err := os.ReadFile("../testdata/graph/spec.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := spec.DecodeEnvironment(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResolveDefaults(&env)
+	got := BuildGraph(&env)
+
+	data, err = os.ReadFile("../testdata/graph/graph.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want Graph
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("graph = %+v\nwant %+v", got, want)
+	}
+}
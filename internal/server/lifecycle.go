@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/matgreaves/rig/connect"
 	"github.com/matgreaves/rig/internal/server/artifact"
 	"github.com/matgreaves/rig/internal/server/proxy"
 	"github.com/matgreaves/rig/internal/server/ready"
@@ -31,11 +33,17 @@ type serviceContext struct {
 	tempDir           string
 	envDir            string
 	hostEnv           map[string]string // host process env from SDK
-	dir               string           // test process working directory from SDK
+	dir               string            // test process working directory from SDK
 	log               *EventLog
 	envName           string
 	instanceID        string
-	noIngressServices []string // real services with no ingresses (~test waits for these)
+	noIngressServices []string                // real services with no ingresses (~test waits for these)
+	environment       *spec.Environment       // full spec (~test only; used for the environment.up snapshot)
+	network           string                  // Docker network name (container-network environments only)
+	peers             map[string]spec.Service // services attached to network, keyed by name
+	reservation       *service.Reservation    // ingress listeners held open from publish until start
+	outputs           map[string]string       // set by init hooks; published on service.ready
+	temporalTargets   map[string]string       // task queue egress → Temporal service behind it
 }
 
 // serviceLifecycle builds the full lifecycle sequence for a single service.
@@ -46,7 +54,7 @@ type serviceContext struct {
 //	    publish, waitForEgresses, prestart,
 //	    Group{
 //	        "runner":    the service process,
-//	        "lifecycle": Sequence{ readyCheck, init, markReady, Idle },
+//	        "lifecycle": Sequence{ readyCheck, taskQueues, init, markReady, Idle },
 //	    },
 //	}
 //
@@ -66,25 +74,35 @@ func serviceLifecycle(sc *serviceContext, ports *PortAllocator) run.Runner {
 	// so callers never need to strip again.
 	return run.Func(func(ctx context.Context) error {
 		err := inner.Run(ctx)
+		// Close any reserved ports left open if the service never started.
+		sc.reservation.Release()
 		var domainErr string
 		if err != nil {
 			domainErr = stripRunPrefixes(err.Error())
 		}
 		if ctx.Err() != nil {
 			// Context cancelled — service is stopping due to teardown.
-			sc.log.Publish(Event{
+			ev := Event{
 				Type:        EventServiceStopping,
 				Environment: sc.envName,
 				Service:     sc.name,
-			})
+			}
+			if st := sc.spec.StopTimeout; st != nil {
+				ev.StopTimeout = st.Duration.String()
+			}
+			sc.log.Publish(ev)
 		} else if err != nil {
 			// Service failed — mark as failed before stopped.
-			sc.log.Publish(Event{
+			ev := Event{
 				Type:        EventServiceFailed,
 				Environment: sc.envName,
 				Service:     sc.name,
 				Error:       domainErr,
-			})
+			}
+			if code, ok := service.ExitCode(err); ok {
+				ev.ExitCode = &code
+			}
+			sc.log.Publish(ev)
 		}
 		sc.log.Publish(Event{
 			Type:        EventServiceStopped,
@@ -111,14 +129,6 @@ func publishStep(sc *serviceContext, ports *PortAllocator) run.Runner {
 			return fmt.Errorf("allocate ports: %w", err)
 		}
 
-		// Close listeners — service ports are used by external processes that
-		// need to bind themselves. Extract the port numbers first.
-		svcPorts := make([]int, n)
-		for i, ln := range listeners {
-			svcPorts[i] = ln.Addr().(*net.TCPAddr).Port
-			ln.Close()
-		}
-
 		// Sort ingress names for deterministic port assignment.
 		ingressNames := make([]string, 0, n)
 		for name := range sc.spec.Ingresses {
@@ -126,10 +136,15 @@ func publishStep(sc *serviceContext, ports *PortAllocator) run.Runner {
 		}
 		sort.Strings(ingressNames)
 
+		// Keep the listeners open as a reservation until the service starts,
+		// so nothing else can bind the ports while prestart hooks run.
 		portMap := make(map[string]int, n)
+		reserved := make(map[string]net.Listener, n)
 		for i, name := range ingressNames {
-			portMap[name] = svcPorts[i]
+			portMap[name] = listeners[i].Addr().(*net.TCPAddr).Port
+			reserved[name] = listeners[i]
 		}
+		sc.reservation = service.NewReservation(reserved)
 
 		endpoints, err := sc.svcType.Publish(ctx, service.PublishParams{
 			ServiceName: sc.name,
@@ -160,9 +175,21 @@ func publishStep(sc *serviceContext, ports *PortAllocator) run.Runner {
 	})
 }
 
-// waitForEgressesStep blocks until every egress target is READY.
+// waitForEgressesStep blocks until every egress target and every DependsOn
+// service is READY.
 func waitForEgressesStep(sc *serviceContext) run.Runner {
 	return run.Func(func(ctx context.Context) error {
+		for _, dep := range sc.spec.DependsOn {
+			_, err := sc.log.WaitFor(ctx, func(e Event) bool {
+				return e.Type == EventServiceReady &&
+					e.Environment == sc.envName &&
+					e.Service == dep
+			})
+			if err != nil {
+				return fmt.Errorf("waiting for dependency %q: %w", dep, err)
+			}
+		}
+
 		if len(sc.spec.Egresses) == 0 {
 			return nil
 		}
@@ -174,7 +201,7 @@ func waitForEgressesStep(sc *serviceContext) run.Runner {
 			targetIngress := egressSpec.Ingress
 
 			// Wait for the target service to be READY.
-			_, err := sc.log.WaitFor(ctx, func(e Event) bool {
+			readyEv, err := sc.log.WaitFor(ctx, func(e Event) bool {
 				return e.Type == EventServiceReady &&
 					e.Environment == sc.envName &&
 					e.Service == targetService
@@ -196,7 +223,22 @@ func waitForEgressesStep(sc *serviceContext) run.Runner {
 					egressName, err)
 			}
 
-			sc.egresses[egressName] = *ev.Endpoint
+			if names := egressSpec.WaitForGRPCServices; len(names) > 0 {
+				checker := &ready.GRPCServices{Services: names}
+				if err := ready.Poll(ctx, ev.Endpoint.HostPort, checker, nil, nil); err != nil {
+					return fmt.Errorf("egress %q: waiting for grpc services %s: %w",
+						egressName, strings.Join(names, ", "), err)
+				}
+			}
+
+			ep := withOutputs(*ev.Endpoint, readyEv.Outputs)
+			if egressSpec.Database != "" {
+				ep, err = selectDatabase(ep, egressSpec.Database)
+				if err != nil {
+					return fmt.Errorf("egress %q: %w", egressName, err)
+				}
+			}
+			sc.egresses[egressName] = ep
 		}
 
 		sc.log.Publish(Event{
@@ -209,6 +251,24 @@ func waitForEgressesStep(sc *serviceContext) run.Runner {
 	})
 }
 
+// withOutputs returns ep with a target's init hook outputs added to its
+// attributes. Outputs are literal values, so any $ is escaped to keep it
+// from being read as a template reference.
+func withOutputs(ep spec.Endpoint, outputs map[string]string) spec.Endpoint {
+	if len(outputs) == 0 {
+		return ep
+	}
+	attrs := make(map[string]any, len(ep.Attributes)+len(outputs))
+	for k, v := range ep.Attributes {
+		attrs[k] = v
+	}
+	for k, v := range outputs {
+		attrs[k] = strings.ReplaceAll(v, "$", "$$")
+	}
+	ep.Attributes = attrs
+	return ep
+}
+
 // prestartStep runs the prestart hooks if configured.
 func prestartStep(sc *serviceContext) run.Runner {
 	return run.Func(func(ctx context.Context) error {
@@ -248,7 +308,10 @@ func runWithLifecycle(sc *serviceContext) run.Runner {
 			service: sc.name,
 		}
 
-		env, err := BuildServiceEnv(sc.name, sc.ingresses, sc.egresses, sc.tempDir, sc.envDir, sc.hostEnv)
+		// Services that start later are missing from RIG_ENDPOINTS; it
+		// holds whatever was published by the time this one starts.
+		endpoints := publishedEndpoints(sc.log.LifecycleEvents(), sc.envName)
+		env, err := serviceEnv(sc, sc.ingresses, sc.egresses, endpoints)
 		if err != nil {
 			return fmt.Errorf("build service env: %w", err)
 		}
@@ -268,20 +331,36 @@ func runWithLifecycle(sc *serviceContext) run.Runner {
 			Stdout:      &teeWriter{logWriter, "stdout"},
 			Stderr:      &teeWriter{logWriter, "stderr"},
 			BuildEnv: func(ingresses, egresses map[string]spec.Endpoint) (map[string]string, error) {
-				return BuildServiceEnv(sc.name, ingresses, egresses, sc.tempDir, sc.envDir, sc.hostEnv)
+				return serviceEnv(sc, ingresses, egresses, endpoints)
 			},
 			Callback: func(ctx context.Context, name, callbackType string) error {
-				return dispatchCallback(ctx, sc, name, callbackType)
+				_, err := dispatchCallback(ctx, sc, name, callbackType)
+				return err
 			},
-			ProxyEmit: proxyEmitter(sc),
+			ProxyEmit:       proxyEmitter(sc),
+			Network:         sc.network,
+			NetworkEgresses: networkEgresses(sc),
+			Reservation:     sc.reservation,
+		})
+
+		// Free the reserved ports the service did not take just before it
+		// starts, so it can bind them itself.
+		reserved := runner
+		runner = run.Func(func(ctx context.Context) error {
+			sc.reservation.Release()
+			return reserved.Run(ctx)
 		})
+		if n := sc.spec.RestartOnExit; n > 0 {
+			runner = restartOnExit(sc, runner, n)
+		}
 
 		// Build the lifecycle continuation that runs alongside the service.
 		lifecycle := run.Sequence{
 			readyCheckRunner(sc),
+			taskQueueRunner(sc),
 			emitEvent(sc, EventServiceHealthy),
 			initRunner(sc),
-			emitEvent(sc, EventServiceReady),
+			emitReady(sc),
 			emitEnvironmentUp(sc),
 			run.Idle,
 		}
@@ -296,6 +375,79 @@ func runWithLifecycle(sc *serviceContext) run.Runner {
 	})
 }
 
+// restartOnExit reruns runner each time it exits while ctx is live, up to
+// max times, publishing service.restarted before each rerun. The lifecycle
+// continuation is unaffected, so a restart before the service is ready
+// just extends the ready check. Once the restarts are used up, the exit
+// is returned and fails the environment as usual.
+func restartOnExit(sc *serviceContext, runner run.Runner, max int) run.Runner {
+	return run.Func(func(ctx context.Context) error {
+		for restarts := 0; ; restarts++ {
+			err := runner.Run(ctx)
+			if ctx.Err() != nil {
+				return err
+			}
+			if err == nil {
+				err = errors.New("exited cleanly")
+			}
+			if restarts == max {
+				return fmt.Errorf("%w (after %d restarts)", err, max)
+			}
+			ev := Event{
+				Type:        EventServiceRestarted,
+				Environment: sc.envName,
+				Service:     sc.name,
+				Error:       stripRunPrefixes(err.Error()),
+				Message:     fmt.Sprintf("restart %d of %d", restarts+1, max),
+			}
+			if code, ok := service.ExitCode(err); ok {
+				ev.ExitCode = &code
+			}
+			sc.log.Publish(ev)
+		}
+	})
+}
+
+// serviceEnv builds a service's env for the given endpoints: the host env
+// (with dotenv), the wiring vars, RIG_ENDPOINTS, then the test-set Env, in
+// the order described on layerEnv.
+func serviceEnv(sc *serviceContext, ingresses, egresses map[string]spec.Endpoint, endpoints map[string]map[string]string) (map[string]string, error) {
+	env, err := BuildServiceEnv(sc.name, ingresses, egresses, sc.tempDir, sc.envDir, sc.hostEnv)
+	if err != nil {
+		return nil, err
+	}
+	addEndpointsEnv(env, endpoints)
+	return layerEnv(env, sc.spec.Env), nil
+}
+
+// networkEgresses returns the egresses whose targets share the service's
+// Docker network, rewritten to address the target by its network alias
+// (the service name) and the port it listens on inside its container.
+// Returns nil when the environment has no container network.
+func networkEgresses(sc *serviceContext) map[string]spec.Endpoint {
+	if sc.network == "" || !joinsNetwork(sc.spec) {
+		return nil
+	}
+	out := make(map[string]spec.Endpoint)
+	for egressName, egressSpec := range sc.spec.Egresses {
+		target, ok := sc.peers[egressSpec.Service]
+		if !ok {
+			continue
+		}
+		ep, ok := sc.egresses[egressName]
+		if !ok {
+			continue
+		}
+		port := ep.Port()
+		if is, ok := target.Ingresses[egressSpec.Ingress]; ok && is.ContainerPort != 0 {
+			port = is.ContainerPort
+		}
+		ep.HostPort = fmt.Sprintf("%s:%d", egressSpec.Service, port)
+		out[egressName] = ep
+	}
+	return out
+}
+
 // readyCheckRunner polls all ingresses until they're ready.
 // If the service type implements ReadyChecker, its custom checker is used
 // instead of the default protocol-based one.
@@ -333,6 +485,13 @@ func readyCheckRunner(sc *serviceContext) run.Runner {
 				})
 			}
 			if err := ready.Poll(ctx, ep.HostPort, checker, readySpec, onFailure); err != nil {
+				// A service-specific timeout ran out (rather than the
+				// environment being torn down): name it so the failure
+				// isn't mistaken for the global startup deadline.
+				if readySpec != nil && readySpec.Timeout.Duration > 0 && ctx.Err() == nil {
+					return fmt.Errorf("ingress %q: %s not ready within its %s timeout: %w",
+						ingressName, sc.name, readySpec.Timeout.Duration, err)
+				}
 				return fmt.Errorf("ingress %q: %w", ingressName, err)
 			}
 		}
@@ -340,6 +499,42 @@ func readyCheckRunner(sc *serviceContext) run.Runner {
 	})
 }
 
+// taskQueueRunner waits for each Temporal task queue in the service's
+// TaskQueues to have a poller, so workflows started once the environment is
+// up find a worker. It asks the Temporal server directly rather than
+// through the egress's observe proxy, keeping the polls out of the traffic
+// log.
+func taskQueueRunner(sc *serviceContext) run.Runner {
+	return run.Func(func(ctx context.Context) error {
+		for _, tq := range sc.spec.TaskQueues {
+			target := sc.temporalTargets[tq.Egress]
+			ev, err := sc.log.WaitFor(ctx, func(e Event) bool {
+				return e.Type == EventIngressPublished &&
+					e.Environment == sc.envName &&
+					e.Service == target &&
+					e.Ingress == "default"
+			})
+			if err != nil {
+				return fmt.Errorf("task queue %q: finding temporal endpoint: %w", tq.Name, err)
+			}
+			namespace, _ := ev.Endpoint.Attributes[string(connect.TemporalNamespace)].(string)
+			checker := ready.TemporalTaskQueue{Namespace: namespace, Queue: tq.Name}
+			onFailure := func(err error) {
+				sc.log.Publish(Event{
+					Type:        EventHealthCheckFailed,
+					Environment: sc.envName,
+					Service:     sc.name,
+					Error:       err.Error(),
+				})
+			}
+			if err := ready.Poll(ctx, ev.Endpoint.HostPort, checker, nil, onFailure); err != nil {
+				return fmt.Errorf("task queue %q: %w", tq.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
 // initRunner runs the init hooks if configured.
 func initRunner(sc *serviceContext) run.Runner {
 	return run.Func(func(ctx context.Context) error {
@@ -374,6 +569,21 @@ func emitEvent(sc *serviceContext, eventType EventType) run.Runner {
 	})
 }
 
+// emitReady returns a Runner that publishes service.ready carrying the
+// outputs set by the service's init hooks. Dependents wait for this event
+// before resolving their egresses, so they always see the outputs.
+func emitReady(sc *serviceContext) run.Runner {
+	return run.Func(func(ctx context.Context) error {
+		sc.log.Publish(Event{
+			Type:        EventServiceReady,
+			Environment: sc.envName,
+			Service:     sc.name,
+			Outputs:     sc.outputs,
+		})
+		return nil
+	})
+}
+
 // emitEnvironmentUp returns a Runner that emits EventEnvironmentUp when the
 // ~test node reaches ready. For all other services, this is a no-op.
 //
@@ -437,10 +647,23 @@ func emitEnvironmentUp(sc *serviceContext) run.Runner {
 			}
 		}
 
+		// Attach the full resolved snapshot — every real service's ingresses,
+		// egresses and attributes — so SSE consumers don't need a follow-up
+		// GET /environments/{id}.
+		var resolved *spec.ResolvedEnvironment
+		if sc.environment != nil {
+			snap, err := buildResolvedEnvironment(sc.instanceID, sc.environment, sc.log)
+			if err != nil {
+				return fmt.Errorf("resolve environment: %w", err)
+			}
+			resolved = &snap
+		}
+
 		sc.log.Publish(Event{
 			Type:        EventEnvironmentUp,
 			Environment: sc.envName,
 			Ingresses:   ingresses,
+			Resolved:    resolved,
 			EnvDir:      sc.envDir,
 		})
 		return nil
@@ -449,15 +672,15 @@ func emitEnvironmentUp(sc *serviceContext) run.Runner {
 
 // dispatchCallback sends a callback request to the client SDK via the event
 // log and blocks until the response arrives. This is used both for hooks and
-// for client service type start callbacks.
-func dispatchCallback(ctx context.Context, sc *serviceContext, name, callbackType string) error {
+// for client service type start callbacks. Returns the response's data.
+func dispatchCallback(ctx context.Context, sc *serviceContext, name, callbackType string) (map[string]any, error) {
 	ri, err := resolveEndpointMap(sc.ingresses)
 	if err != nil {
-		return fmt.Errorf("resolve ingress attributes: %w", err)
+		return nil, fmt.Errorf("resolve ingress attributes: %w", err)
 	}
 	re, err := resolveEndpointMap(sc.egresses)
 	if err != nil {
-		return fmt.Errorf("resolve egress attributes: %w", err)
+		return nil, fmt.Errorf("resolve egress attributes: %w", err)
 	}
 	wiring := &WiringContext{
 		Ingresses: ri,
@@ -490,15 +713,38 @@ func dispatchCallback(ctx context.Context, sc *serviceContext, name, callbackTyp
 	})
 	if err != nil {
 		if callbackCtx.Err() != nil && ctx.Err() == nil {
-			return fmt.Errorf("callback %q response not received within 30s — client may have disconnected", name)
+			return nil, fmt.Errorf("callback %q response not received within 30s — client may have disconnected", name)
 		}
-		return fmt.Errorf("callback %q: waiting for response: %w", name, err)
+		return nil, fmt.Errorf("callback %q: waiting for response: %w", name, err)
 	}
 
 	if ev.Result.Error != "" {
-		return fmt.Errorf("callback %q: error: %s", name, ev.Result.Error)
+		return nil, fmt.Errorf("callback %q: error: %s", name, ev.Result.Error)
 	}
 
+	return ev.Result.Data, nil
+}
+
+// recordOutputs stores the outputs a client_func hook returned in its
+// callback.response data. Only init hooks may set outputs: dependents
+// read them from service.ready, which prestart hooks run too early for.
+func recordOutputs(sc *serviceContext, name string, data map[string]any, prestart bool) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if prestart {
+		return fmt.Errorf("hook %q: outputs can only be set by init hooks", name)
+	}
+	if sc.outputs == nil {
+		sc.outputs = make(map[string]string, len(data))
+	}
+	for k, v := range data {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("hook %q: output %q must be a string, got %T", name, k, v)
+		}
+		sc.outputs[k] = s
+	}
 	return nil
 }
 
@@ -511,7 +757,11 @@ func executeHook(ctx context.Context, sc *serviceContext, hook *spec.HookSpec, p
 		if hook.ClientFunc == nil {
 			return fmt.Errorf("client_func hook missing client_func spec")
 		}
-		return dispatchCallback(ctx, sc, hook.ClientFunc.Name, "hook")
+		data, err := dispatchCallback(ctx, sc, hook.ClientFunc.Name, "hook")
+		if err != nil {
+			return err
+		}
+		return recordOutputs(sc, hook.ClientFunc.Name, data, prestart)
 	}
 
 	// Server-side hooks only run during init — the service must be running
@@ -586,6 +836,7 @@ func proxyEmitter(sc *serviceContext) func(proxy.Event) {
 				Path:                  pe.Request.Path,
 				StatusCode:            pe.Request.StatusCode,
 				LatencyMs:             pe.Request.LatencyMs,
+				ProxyOverheadMs:       pe.Request.ProxyOverheadMs,
 				RequestSize:           pe.Request.RequestSize,
 				ResponseSize:          pe.Request.ResponseSize,
 				RequestHeaders:        pe.Request.RequestHeaders,
@@ -594,16 +845,30 @@ func proxyEmitter(sc *serviceContext) func(proxy.Event) {
 				ResponseHeaders:       pe.Request.ResponseHeaders,
 				ResponseBody:          pe.Request.ResponseBody,
 				ResponseBodyTruncated: pe.Request.ResponseBodyTruncated,
+				ProxyInjected:         pe.Request.ProxyInjected,
+				Label:                 pe.Request.Label,
+				TraceID:               pe.Request.TraceID,
+				SpanID:                pe.Request.SpanID,
+				ParentSpanID:          pe.Request.ParentSpanID,
 			}
 		}
 		if pe.Connection != nil {
 			ev.Connection = &ConnectionInfo{
-				Source:     pe.Connection.Source,
-				Target:     pe.Connection.Target,
-				Ingress:    pe.Connection.Ingress,
-				BytesIn:    pe.Connection.BytesIn,
-				BytesOut:   pe.Connection.BytesOut,
-				DurationMs: pe.Connection.DurationMs,
+				Source:      pe.Connection.Source,
+				Target:      pe.Connection.Target,
+				Ingress:     pe.Connection.Ingress,
+				BytesIn:     pe.Connection.BytesIn,
+				BytesOut:    pe.Connection.BytesOut,
+				DurationMs:  pe.Connection.DurationMs,
+				CloseReason: pe.Connection.CloseReason,
+
+				Requests:     pe.Connection.Requests,
+				HTTPProtocol: pe.Connection.HTTPProtocol,
+
+				PreviewIn:           pe.Connection.PreviewIn,
+				PreviewInTruncated:  pe.Connection.PreviewInTruncated,
+				PreviewOut:          pe.Connection.PreviewOut,
+				PreviewOutTruncated: pe.Connection.PreviewOutTruncated,
 			}
 		}
 		if pe.GRPCCall != nil {
@@ -624,6 +889,10 @@ func proxyEmitter(sc *serviceContext) func(proxy.Event) {
 				RequestBodyTruncated:  pe.GRPCCall.RequestBodyTruncated,
 				ResponseBody:          pe.GRPCCall.ResponseBody,
 				ResponseBodyTruncated: pe.GRPCCall.ResponseBodyTruncated,
+				ProxyInjected:         pe.GRPCCall.ProxyInjected,
+				TraceID:               pe.GRPCCall.TraceID,
+				SpanID:                pe.GRPCCall.SpanID,
+				ParentSpanID:          pe.GRPCCall.ParentSpanID,
 			}
 			if pe.GRPCCall.RequestBodyDecoded != "" {
 				info.RequestBodyDecoded = json.RawMessage(pe.GRPCCall.RequestBodyDecoded)
@@ -633,6 +902,35 @@ func proxyEmitter(sc *serviceContext) func(proxy.Event) {
 			}
 			ev.GRPCCall = info
 		}
+		if pe.GRPCStream != nil {
+			info := &GRPCStreamInfo{
+				Source:           pe.GRPCStream.Source,
+				Target:           pe.GRPCStream.Target,
+				Ingress:          pe.GRPCStream.Ingress,
+				Service:          pe.GRPCStream.Service,
+				Method:           pe.GRPCStream.Method,
+				RequestMetadata:  pe.GRPCStream.RequestMetadata,
+				Direction:        pe.GRPCStream.Direction,
+				Body:             pe.GRPCStream.Body,
+				BodyTruncated:    pe.GRPCStream.BodyTruncated,
+				Size:             pe.GRPCStream.Size,
+				GRPCStatus:       pe.GRPCStream.GRPCStatus,
+				GRPCMessage:      pe.GRPCStream.GRPCMessage,
+				MessagesIn:       pe.GRPCStream.MessagesIn,
+				MessagesOut:      pe.GRPCStream.MessagesOut,
+				RequestSize:      pe.GRPCStream.RequestSize,
+				ResponseSize:     pe.GRPCStream.ResponseSize,
+				DurationMs:       pe.GRPCStream.DurationMs,
+				ResponseMetadata: pe.GRPCStream.ResponseMetadata,
+				TraceID:          pe.GRPCStream.TraceID,
+				SpanID:           pe.GRPCStream.SpanID,
+				ParentSpanID:     pe.GRPCStream.ParentSpanID,
+			}
+			if pe.GRPCStream.BodyDecoded != "" {
+				info.BodyDecoded = json.RawMessage(pe.GRPCStream.BodyDecoded)
+			}
+			ev.GRPCStream = info
+		}
 		if pe.KafkaRequest != nil {
 			ev.KafkaRequest = &KafkaRequestInfo{
 				Source:        pe.KafkaRequest.Source,
@@ -647,6 +945,72 @@ func proxyEmitter(sc *serviceContext) func(proxy.Event) {
 				ResponseSize:  pe.KafkaRequest.ResponseSize,
 			}
 		}
+		if pe.RedisCommand != nil {
+			ev.RedisCommand = &RedisCommandInfo{
+				Source:       pe.RedisCommand.Source,
+				Target:       pe.RedisCommand.Target,
+				Ingress:      pe.RedisCommand.Ingress,
+				Command:      pe.RedisCommand.Command,
+				Key:          pe.RedisCommand.Key,
+				ReplyType:    pe.RedisCommand.ReplyType,
+				RedisError:   pe.RedisCommand.Error,
+				LatencyMs:    pe.RedisCommand.LatencyMs,
+				RequestSize:  pe.RedisCommand.RequestSize,
+				ResponseSize: pe.RedisCommand.ResponseSize,
+			}
+		}
+		if pe.NATSMessage != nil {
+			ev.NATSMessage = &NATSMessageInfo{
+				Source:      pe.NATSMessage.Source,
+				Target:      pe.NATSMessage.Target,
+				Ingress:     pe.NATSMessage.Ingress,
+				Op:          pe.NATSMessage.Op,
+				Subject:     pe.NATSMessage.Subject,
+				ReplyTo:     pe.NATSMessage.ReplyTo,
+				Queue:       pe.NATSMessage.Queue,
+				PayloadSize: pe.NATSMessage.PayloadSize,
+				HeaderSize:  pe.NATSMessage.HeaderSize,
+			}
+		}
+		if pe.MongoCommand != nil {
+			ev.MongoCommand = &MongoCommandInfo{
+				Source:       pe.MongoCommand.Source,
+				Target:       pe.MongoCommand.Target,
+				Ingress:      pe.MongoCommand.Ingress,
+				Command:      pe.MongoCommand.Command,
+				Collection:   pe.MongoCommand.Collection,
+				Database:     pe.MongoCommand.Database,
+				MongoError:   pe.MongoCommand.Error,
+				LatencyMs:    pe.MongoCommand.LatencyMs,
+				RequestSize:  pe.MongoCommand.RequestSize,
+				ResponseSize: pe.MongoCommand.ResponseSize,
+			}
+		}
+		if pe.WebSocket != nil {
+			ev.WebSocket = &WebSocketInfo{
+				Source:     pe.WebSocket.Source,
+				Target:     pe.WebSocket.Target,
+				Ingress:    pe.WebSocket.Ingress,
+				Path:       pe.WebSocket.Path,
+				FramesIn:   pe.WebSocket.FramesIn,
+				FramesOut:  pe.WebSocket.FramesOut,
+				BytesIn:    pe.WebSocket.BytesIn,
+				BytesOut:   pe.WebSocket.BytesOut,
+				DurationMs: pe.WebSocket.DurationMs,
+			}
+		}
+		if pe.Fault != nil {
+			ev.Fault = &FaultInfo{
+				Source:     pe.Fault.Source,
+				Target:     pe.Fault.Target,
+				Ingress:    pe.Fault.Ingress,
+				Kind:       pe.Fault.Kind,
+				DelayMs:    pe.Fault.DelayMs,
+				StatusCode: pe.Fault.StatusCode,
+				Method:     pe.Fault.Method,
+				Path:       pe.Fault.Path,
+			}
+		}
 		sc.log.Publish(ev)
 	}
 }
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/matgreaves/rig/internal/server/artifact"
+	"github.com/matgreaves/rig/internal/server/dockerutil"
 	"github.com/matgreaves/rig/internal/server/service"
 	"github.com/matgreaves/rig/internal/spec"
 	"github.com/matgreaves/run"
@@ -25,24 +26,39 @@ type Orchestrator struct {
 	TempBase string          // base directory for temp dirs (default os.TempDir()/rig)
 	Cache    *artifact.Cache // artifact cache (shared with background refresher)
 	Preserve *bool           // when non-nil and true, skip temp dir cleanup on exit
+
+	// ArtifactRetry controls retries of transient artifact failures such
+	// as image pulls. Zero means artifact.DefaultRetry.
+	ArtifactRetry artifact.RetryPolicy
+
+	// ArtifactConcurrency bounds how many distinct artifacts are resolved
+	// at once. Zero means artifact.DefaultConcurrency.
+	ArtifactConcurrency int
 }
 
 // Orchestrate builds a run.Runner that manages the full lifecycle of the
 // given environment. The runner executes two phases sequentially:
 //
 //  1. Artifact phase: resolves all required artifacts (compiled binaries, etc.)
-//     in parallel, using a content-addressable cache.
+//     in parallel with a bounded worker pool, using a content-addressable
+//     cache. Services sharing an artifact key share one resolution.
 //  2. Service phase: starts all services concurrently. Dependency ordering
 //     emerges from services blocking on the event log until their egress
 //     targets are ready. On first failure, the server cancels all remaining
 //     services and emits environment.failing with the root cause.
 //
+// WithContainerNetwork environments create a dedicated Docker network
+// between the two phases and remove it once every service has stopped.
+//
 // If either phase fails, the runner emits environment.failing with the root
 // cause before returning. The results map is safe to share because the
 // artifact phase completes before the service phase begins.
 func (o *Orchestrator) Orchestrate(env *spec.Environment) (run.Runner, string, string, error) {
 	// Insert virtual service nodes before orchestration.
+	InsertExternalNodes(env)
 	InsertTestNode(env)
+	ApplyStartupOrder(env)
+	ExpandScale(env)
 	TransformObserve(env)
 
 	// Generate instance ID.
@@ -104,12 +120,15 @@ func (o *Orchestrator) Orchestrate(env *spec.Environment) (run.Runner, string, s
 			if err != nil {
 				evt.Error = err.Error()
 			}
+		case artifact.EventRetry:
+			evt.Type = EventArtifactRetry
+			evt.Error = err.Error()
 		}
 		o.Log.Publish(evt)
 	}
 
 	artifactPhase := run.Func(func(ctx context.Context) error {
-		resolved, err := artifact.Resolve(ctx, allArtifacts, cache, emit)
+		resolved, err := artifact.Resolve(ctx, allArtifacts, cache, o.ArtifactRetry, o.ArtifactConcurrency, emit)
 		if err != nil {
 			return err
 		}
@@ -126,6 +145,27 @@ func (o *Orchestrator) Orchestrate(env *spec.Environment) (run.Runner, string, s
 
 	allServiceNames := sortedServiceNames(env.Services)
 
+	// WithContainerNetwork environments attach container services to a
+	// dedicated network so they can reach each other by service name.
+	var network string
+	var peers map[string]spec.Service
+	if env.ContainerNetwork {
+		network = dockerutil.NetworkName(instanceID)
+		peers = make(map[string]spec.Service)
+		for name, svc := range env.Services {
+			if joinsNetwork(svc) {
+				peers[name] = svc
+			}
+		}
+	}
+
+	networkPhase := run.Func(func(ctx context.Context) error {
+		if network == "" {
+			return nil
+		}
+		return dockerutil.CreateNetwork(ctx, network)
+	})
+
 	servicePhase := run.Func(func(ctx context.Context) error {
 		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
@@ -148,6 +188,7 @@ func (o *Orchestrator) Orchestrate(env *spec.Environment) (run.Runner, string, s
 
 		var wg sync.WaitGroup
 		errs := make(chan serviceErr, len(allServiceNames))
+		baseEnv := withClockAndSeed(env)
 
 		for _, name := range allServiceNames {
 			svc := env.Services[name]
@@ -167,18 +208,29 @@ func (o *Orchestrator) Orchestrate(env *spec.Environment) (run.Runner, string, s
 				svcType:    svcType,
 				tempDir:    tempDir,
 				envDir:     envDir,
-				hostEnv:    env.HostEnv,
+				hostEnv:    layerEnv(baseEnv, svc.DotEnv),
 				dir:        env.Dir,
 				log:        o.Log,
 				envName:    env.Name,
 				instanceID: instanceID,
 				artifacts:  results,
+				network:    network,
+				peers:      peers,
+			}
+
+			if len(svc.TaskQueues) > 0 {
+				sc.temporalTargets = make(map[string]string, len(svc.TaskQueues))
+				for _, tq := range svc.TaskQueues {
+					sc.temporalTargets[tq.Egress] = egressTarget(env, svc.Egresses[tq.Egress])
+				}
 			}
 
 			// The ~test node needs to know about no-ingress services
-			// so emitEnvironmentUp can wait for them.
+			// so emitEnvironmentUp can wait for them, and the full spec
+			// so it can attach the resolved snapshot.
 			if name == "~test" {
 				sc.noIngressServices = noIngressServices
+				sc.environment = env
 			}
 
 			wg.Add(1)
@@ -234,6 +286,27 @@ func (o *Orchestrator) Orchestrate(env *spec.Environment) (run.Runner, string, s
 			}
 			return err
 		}
+		if err := networkPhase.Run(ctx); err != nil {
+			if ctx.Err() == nil {
+				o.Log.Publish(Event{
+					Type:        EventEnvironmentFailing,
+					Environment: env.Name,
+					Error:       err.Error(),
+				})
+			}
+			return err
+		}
+		if network != "" {
+			cancelNetworkCleanup, _ := onexit.OnExitF("docker network rm %s", network)
+			defer func() {
+				// Containers are removed by the time servicePhase returns,
+				// so the network has no endpoints left.
+				dockerutil.RemoveNetwork(context.Background(), network)
+				if cancelNetworkCleanup != nil {
+					cancelNetworkCleanup()
+				}
+			}()
+		}
 		if err := servicePhase.Run(ctx); err != nil {
 			if ctx.Err() == nil {
 				o.Log.Publish(Event{
@@ -292,6 +365,15 @@ func realSortedServiceNames(services map[string]spec.Service) []string {
 	return names
 }
 
+// egressTarget returns the service an egress ultimately reaches, looking
+// through an observe proxy to the service it forwards to.
+func egressTarget(env *spec.Environment, egress spec.EgressSpec) string {
+	if target, ok := env.Services[egress.Service]; ok && target.Injected && target.Type == "proxy" {
+		return target.Egresses["target"].Service
+	}
+	return egress.Service
+}
+
 func generateID() string {
 	b := make([]byte, 4)
 	rand.Read(b)
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand/v2"
@@ -15,24 +16,49 @@ const (
 
 // PortAllocator allocates ports using a prime-stepping strategy that spreads
 // allocations across the port range, minimising collisions in parallel tests.
-// Ports are returned as open net.Listeners — callers that need zero TOCTOU
-// (proxies) can use the listener directly; callers that need a raw port number
-// close the listener and use the port.
+// Ports are returned as open net.Listeners. The service lifecycle holds them
+// as a service.Reservation until the service starts: proxies serve on the
+// listener directly (zero TOCTOU), and the rest are closed just before the
+// service binds the port itself.
 type PortAllocator struct {
 	mu         sync.Mutex
 	allocated  map[int]string   // port → instance ID
 	byInstance map[string][]int // instance ID → ports (reverse index for O(k) release)
+	base       int
+	count      int
 	offset     uint64
-	step       uint64 // random prime
+	step       uint64 // random prime coprime with count, so every port is visited
 }
 
-// NewPortAllocator creates an empty port allocator.
+// ErrPortsExhausted is returned (wrapped) by Allocate when every port in the
+// allocator's range is taken, either by rig environments or by other
+// processes on the host.
+var ErrPortsExhausted = errors.New("no free ports in range")
+
+// NewPortAllocator creates an empty port allocator over the default range,
+// 8192–32767.
 func NewPortAllocator() *PortAllocator {
+	return newPortAllocator(portBase, portCount)
+}
+
+// NewPortAllocatorRange creates an empty port allocator over the inclusive
+// range lo–hi, e.g. to keep rig clear of ports other tooling on a CI host
+// relies on.
+func NewPortAllocatorRange(lo, hi int) (*PortAllocator, error) {
+	if lo < 1 || hi > 65535 || lo > hi {
+		return nil, fmt.Errorf("invalid port range %d-%d", lo, hi)
+	}
+	return newPortAllocator(lo, hi-lo+1), nil
+}
+
+func newPortAllocator(base, count int) *PortAllocator {
 	return &PortAllocator{
 		allocated:  make(map[int]string),
 		byInstance: make(map[string][]int),
-		offset:     rand.Uint64N(portCount),
-		step:       randomPrime(portCount),
+		base:       base,
+		count:      count,
+		offset:     rand.Uint64N(uint64(count)),
+		step:       randomStep(uint64(count)),
 	}
 }
 
@@ -62,8 +88,8 @@ func (a *PortAllocator) Allocate(instanceID string, n int) ([]net.Listener, erro
 
 	for range n {
 		found := false
-		for range portCount {
-			port := portBase + int(a.offset%uint64(portCount))
+		for range a.count {
+			port := a.base + int(a.offset%uint64(a.count))
 			a.offset += a.step
 
 			if _, taken := a.allocated[port]; taken {
@@ -82,7 +108,8 @@ func (a *PortAllocator) Allocate(instanceID string, n int) ([]net.Listener, erro
 		}
 		if !found {
 			cleanup()
-			return nil, fmt.Errorf("allocate port: exhausted %d candidates", portCount)
+			return nil, fmt.Errorf("%w %d-%d after %d attempts (%d held by rig environments); reduce test parallelism or widen the range",
+				ErrPortsExhausted, a.base, a.base+a.count-1, a.count, len(a.allocated))
 		}
 	}
 
@@ -112,11 +139,16 @@ func (a *PortAllocator) Allocated() int {
 	return len(a.allocated)
 }
 
-// randomPrime returns a random prime in [2, max).
-func randomPrime(max uint64) uint64 {
+// randomStep returns a random prime in [2, count) that doesn't divide count,
+// so stepping by it from any offset visits every port before repeating.
+// Ranges too small to have one step by 1.
+func randomStep(count uint64) uint64 {
+	if count < 3 {
+		return 1
+	}
 	for {
-		n := 2 + rand.Uint64N(max-2)
-		if big.NewInt(int64(n)).ProbablyPrime(20) {
+		n := 2 + rand.Uint64N(count-2)
+		if count%n != 0 && big.NewInt(int64(n)).ProbablyPrime(20) {
 			return n
 		}
 	}
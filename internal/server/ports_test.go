@@ -1,7 +1,10 @@
 package server_test
 
 import (
+	"errors"
 	"net"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/matgreaves/rig/internal/server"
@@ -172,3 +175,81 @@ func TestPortAllocator_MultipleInstancesGetDifferentPorts(t *testing.T) {
 		seen[p] = true
 	}
 }
+
+// freePort returns a port that was free a moment ago.
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+func TestPortAllocator_RangeExhausted(t *testing.T) {
+	port := freePort(t)
+	alloc, err := server.NewPortAllocatorRange(port, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lns, err := alloc.Allocate("inst-1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := listenersToPortsAndClose(t, lns); got[0] != port {
+		t.Errorf("allocated port %d, want %d", got[0], port)
+	}
+
+	// The only port is tracked by inst-1, so the range is exhausted.
+	_, err = alloc.Allocate("inst-2", 1)
+	if !errors.Is(err, server.ErrPortsExhausted) {
+		t.Fatalf("err = %v, want ErrPortsExhausted", err)
+	}
+	if !strings.Contains(err.Error(), "after 1 attempts") {
+		t.Errorf("error %q should report the attempts made", err)
+	}
+	if alloc.Allocated() != 1 {
+		t.Errorf("failed Allocate tracked ports: got %d, want 1", alloc.Allocated())
+	}
+
+	// Releasing frees it again.
+	alloc.Release("inst-1")
+	lns, err = alloc.Allocate("inst-2", 1)
+	if err != nil {
+		t.Fatalf("after release: %v", err)
+	}
+	listenersToPortsAndClose(t, lns)
+}
+
+func TestPortAllocator_RangeExhaustedPartway(t *testing.T) {
+	port := freePort(t)
+	alloc, err := server.NewPortAllocatorRange(port, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two ports from a one-port range: the first is found, then released
+	// when the second can't be.
+	if _, err := alloc.Allocate("inst-1", 2); !errors.Is(err, server.ErrPortsExhausted) {
+		t.Fatalf("err = %v, want ErrPortsExhausted", err)
+	}
+	if alloc.Allocated() != 0 {
+		t.Errorf("expected 0 tracked ports, got %d", alloc.Allocated())
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		t.Fatalf("port %d left bound after failed Allocate: %v", port, err)
+	}
+	ln.Close()
+}
+
+func TestNewPortAllocatorRange_Invalid(t *testing.T) {
+	for _, r := range [][2]int{{0, 10}, {2000, 1000}, {60000, 70000}} {
+		if _, err := server.NewPortAllocatorRange(r[0], r[1]); err == nil {
+			t.Errorf("NewPortAllocatorRange(%d, %d): expected error", r[0], r[1])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matgreaves/rig/internal/server/artifact"
+	"github.com/matgreaves/rig/internal/server/dockerutil"
+	"github.com/matgreaves/rig/internal/server/service"
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+// dockerPingTimeout bounds the pre-flight ping so a wedged daemon fails
+// environment creation quickly instead of stalling it.
+const dockerPingTimeout = 5 * time.Second
+
+// DockerPreflight fails fast when env has services that need Docker —
+// those whose artifacts are pulled or loaded images — and ping reports
+// the daemon unreachable. Environments that need no Docker (go, process,
+// client services) pass without pinging, so they still run when Docker
+// is down.
+func DockerPreflight(ctx context.Context, env *spec.Environment, registry *service.Registry, ping func(context.Context) error) error {
+	names := dockerServices(env, registry)
+	if len(names) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, dockerPingTimeout)
+	defer cancel()
+	if err := ping(ctx); err != nil {
+		return fmt.Errorf("Docker is required for services [%s] but the daemon is not reachable: %w", strings.Join(names, ", "), err)
+	}
+	return nil
+}
+
+// pingDocker pings the shared Docker client.
+func pingDocker(ctx context.Context) error {
+	cli, err := dockerutil.Client()
+	if err != nil {
+		return err
+	}
+	_, err = cli.Ping(ctx)
+	return err
+}
+
+// dockerServices returns the sorted names of env's real services that
+// resolve a Docker image. Services whose artifacts can't be listed are
+// skipped; orchestration reports those errors.
+func dockerServices(env *spec.Environment, registry *service.Registry) []string {
+	var names []string
+	for _, name := range realSortedServiceNames(env.Services) {
+		svc := env.Services[name]
+		svcType, err := registry.Get(svc.Type)
+		if err != nil {
+			continue
+		}
+		provider, ok := svcType.(service.ArtifactProvider)
+		if !ok {
+			continue
+		}
+		arts, err := provider.Artifacts(service.ArtifactParams{
+			ServiceName: name,
+			Spec:        svc,
+			Dir:         env.Dir,
+			HostEnv:     env.HostEnv,
+		})
+		if err != nil {
+			continue
+		}
+		if slices.ContainsFunc(arts, isDockerArtifact) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func isDockerArtifact(a artifact.Artifact) bool {
+	switch a.Resolver.(type) {
+	case artifact.DockerPull, artifact.DockerLoad:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matgreaves/rig/internal/server"
+	"github.com/matgreaves/rig/internal/server/service"
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+func TestDockerPreflight(t *testing.T) {
+	reg := service.NewRegistry()
+	reg.Register("process", service.Process{})
+	reg.Register("container", service.Container{})
+
+	pings := 0
+	down := func(context.Context) error {
+		pings++
+		return errors.New("connect: no such file or directory")
+	}
+
+	env := &spec.Environment{
+		Name: "T",
+		Services: map[string]spec.Service{
+			"api":   {Type: "process", Config: mustJSON(t, map[string]any{"command": "/bin/api"})},
+			"web":   {Type: "container", Config: mustJSON(t, map[string]any{"image": "nginx:1"})},
+			"cache": {Type: "container", Config: mustJSON(t, map[string]any{"image": "redis:7"})},
+		},
+	}
+	err := server.DockerPreflight(context.Background(), env, reg, down)
+	want := "Docker is required for services [cache, web] but the daemon is not reachable: connect: no such file or directory"
+	if err == nil || err.Error() != want {
+		t.Fatalf("err = %v, want %q", err, want)
+	}
+
+	// Without Docker services the daemon isn't consulted.
+	pings = 0
+	delete(env.Services, "web")
+	delete(env.Services, "cache")
+	if err := server.DockerPreflight(context.Background(), env, reg, down); err != nil || pings != 0 {
+		t.Errorf("process-only env: err = %v, pings = %d; want nil and 0", err, pings)
+	}
+}
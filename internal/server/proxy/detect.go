@@ -0,0 +1,181 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// sniffTimeout bounds how long an auto-detecting forwarder waits for a
+// client's first bytes. Protocols where the server speaks first (MySQL,
+// SMTP) send nothing, so they fall back to TCP once it expires.
+const sniffTimeout = 200 * time.Millisecond
+
+// maxSniff is the most bytes detectProtocol needs: the HTTP/2 preface.
+const maxSniff = len(http2Preface)
+
+const http2Preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "CONNECT", "TRACE"}
+
+// runAutoDetect accepts connections and hands each to the HTTP, gRPC, Kafka
+// or TCP path according to its first bytes. HTTP and gRPC connections are
+// served by the same servers runHTTP and runGRPC use, fed through
+// in-memory listeners.
+func (f *Forwarder) runAutoDetect(ctx context.Context) error {
+	ln, err := f.getListener()
+	if err != nil {
+		return fmt.Errorf("proxy %s→%s: listen: %w", f.Source, f.TargetSvc, err)
+	}
+
+	httpLn := newConnListener(ln.Addr())
+	grpcLn := newConnListener(ln.Addr())
+	httpSrv := f.httpServer(ctx)
+	grpcSrv := f.grpcServer()
+	go httpSrv.Serve(httpConnListener{httpLn})
+	go grpcSrv.Serve(grpcLn)
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+		httpSrv.Close()
+		grpcSrv.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("proxy %s→%s: accept: %w", f.Source, f.TargetSvc, err)
+		}
+		go func() {
+			proto, conn := sniff(conn, sniffTimeout)
+			switch proto {
+			case "http":
+				httpLn.push(conn)
+			case "grpc":
+				grpcLn.push(conn)
+			case "kafka":
+				f.handleKafkaConn(ctx, conn)
+			default:
+				f.handleTCPConn(ctx, conn)
+			}
+		}()
+	}
+}
+
+// sniff peeks at conn's first bytes, waiting at most timeout for them, and
+// returns the detected protocol ("" when inconclusive) along with a conn
+// that replays the peeked bytes.
+func sniff(conn net.Conn, timeout time.Duration) (string, net.Conn) {
+	br := bufio.NewReaderSize(conn, 4096)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	var proto string
+	need := 1
+	for {
+		b, err := br.Peek(min(max(need, br.Buffered()), maxSniff))
+		var more bool
+		proto, more = detectProtocol(b)
+		if !more || err != nil || len(b) >= maxSniff {
+			break
+		}
+		need = len(b) + 1
+	}
+	return proto, &peekedConn{Conn: conn, r: br}
+}
+
+// detectProtocol classifies a connection by its first bytes: "http" for an
+// HTTP/1 request line, "grpc" for the HTTP/2 cleartext preface, "kafka"
+// for a plausible Kafka request header. more reports that b is a prefix of
+// something that could still match, so more bytes are worth waiting for.
+func detectProtocol(b []byte) (proto string, more bool) {
+	if len(b) == 0 {
+		return "", true
+	}
+	if bytes.HasPrefix(b, []byte(http2Preface)) {
+		return "grpc", false
+	}
+	more = bytes.HasPrefix([]byte(http2Preface), b)
+	for _, m := range httpMethods {
+		line := m + " "
+		if bytes.HasPrefix(b, []byte(line)) {
+			return "http", false
+		}
+		if bytes.HasPrefix([]byte(line), b) {
+			more = true
+		}
+	}
+
+	// Kafka request: int32 size, int16 api key, int16 api version. Requests
+	// of 16MB or more are not expected, so the first byte is always zero.
+	if b[0] != 0 {
+		return "", more
+	}
+	if len(b) < 8 {
+		return "", true
+	}
+	size := binary.BigEndian.Uint32(b[0:4])
+	key := int16(binary.BigEndian.Uint16(b[4:6]))
+	version := int16(binary.BigEndian.Uint16(b[6:8]))
+	if size >= 10 && key >= 0 && key <= 74 && version >= 0 && version <= 20 {
+		return "kafka", false
+	}
+	return "", false
+}
+
+// peekedConn is a net.Conn whose reads drain a bufio.Reader first, so
+// bytes consumed while sniffing are not lost.
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+// connListener is a net.Listener fed by push, letting an http.Server
+// serve connections accepted elsewhere.
+type connListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{addr: addr, conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+// push hands conn to the next Accept, or closes it if the listener is
+// closed.
+func (l *connListener) push(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *connListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr { return l.addr }
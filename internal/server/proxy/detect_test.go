@@ -0,0 +1,156 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+func TestDetectProtocol(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		proto string
+		more  bool
+	}{
+		{"empty", "", "", true},
+		{"http get", "GET / HTTP/1.1\r\n", "http", false},
+		{"http options", "OPTIONS * HTTP/1.1\r\n", "http", false},
+		{"partial method", "POS", "", true},
+		{"h2 preface", http2Preface, "grpc", false},
+		{"partial preface", "PRI * HTTP", "", true},
+		{"kafka api versions", "\x00\x00\x00\x14\x00\x12\x00\x03\x00\x00\x00\x01", "kafka", false},
+		{"partial kafka", "\x00\x00\x00", "", true},
+		{"kafka bad key", "\x00\x00\x00\x14\x7f\x00\x00\x03", "", false},
+		{"postgres startup", "\x00\x00\x00\x08\x04\xd2\x16\x2f", "", false},
+		{"redis", "*1\r\n$4\r\nPING\r\n", "", false},
+		{"lowercase get", "get / HTTP/1.1", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proto, more := detectProtocol([]byte(tt.in))
+			if proto != tt.proto || more != tt.more {
+				t.Errorf("detectProtocol(%q) = %q, %v; want %q, %v", tt.in, proto, more, tt.proto, tt.more)
+			}
+		})
+	}
+}
+
+func TestForwarderAutoDetect(t *testing.T) {
+	// Upstream answers HTTP requests and echoes anything else, so one
+	// TCP ingress can carry both.
+	httpSrv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})}
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	httpLn := newConnListener(upstream.Addr())
+	go httpSrv.Serve(httpLn)
+	defer httpSrv.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				proto, conn := sniff(conn, time.Second)
+				if proto == "http" {
+					httpLn.push(conn)
+					return
+				}
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan Event, 16)
+	f := &Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: upstream.Addr().String(), Protocol: spec.TCP},
+		Source:     "~test",
+		TargetSvc:  "api",
+		Ingress:    "default",
+		Protocol:   "tcp",
+		Listener:   ln,
+		AutoDetect: true,
+		Emit: func(ev Event) {
+			if !strings.HasPrefix(ev.Type, "http.connection.") {
+				events <- ev
+			}
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	next := func() Event {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return Event{}
+		}
+	}
+
+	// HTTP is decoded into a request event.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + ln.Addr().String() + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Fatalf("body = %q, want %q", body, "ok")
+	}
+	ev := next()
+	if ev.Type != "request.completed" || ev.Request == nil || ev.Request.Path != "/hello" {
+		t.Fatalf("event = %+v, want request.completed for /hello", ev)
+	}
+
+	// Anything else is relayed as opaque TCP, bytes intact.
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 14)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(buf), "*1\r\n") {
+		t.Fatalf("echo = %q", buf)
+	}
+	conn.Close()
+	if ev := next(); ev.Type != "connection.opened" {
+		t.Fatalf("event = %s, want connection.opened", ev.Type)
+	}
+	if ev := next(); ev.Type != "connection.closed" || ev.Connection.BytesIn != 14 {
+		t.Fatalf("event = %+v, want connection.closed with 14 bytes in", ev)
+	}
+}
@@ -7,7 +7,13 @@ type Event struct {
 	Request      *RequestInfo
 	Connection   *ConnectionInfo
 	GRPCCall     *GRPCCallInfo
+	GRPCStream   *GRPCStreamInfo
 	KafkaRequest *KafkaRequestInfo
+	RedisCommand *RedisCommandInfo
+	NATSMessage  *NATSMessageInfo
+	MongoCommand *MongoCommandInfo
+	WebSocket    *WebSocketInfo
+	Fault        *FaultInfo
 }
 
 // RequestInfo captures an observed HTTP request/response pair.
@@ -28,6 +34,25 @@ type RequestInfo struct {
 	ResponseHeaders       map[string][]string
 	ResponseBody          []byte
 	ResponseBodyTruncated bool
+
+	// ProxyInjected is set when the response was produced by the proxy
+	// itself (e.g. a body size rejection) rather than the target.
+	ProxyInjected bool
+
+	// ProxyOverheadMs is the time the request spent in the proxy before it
+	// had a connection to the target, dialing included. LatencyMs starts
+	// after it.
+	ProxyOverheadMs float64
+
+	// Label is the value of the LabelHeader sent by the caller, if any.
+	Label string
+
+	// TraceID, SpanID and ParentSpanID place the request in a trace
+	// threaded through TraceHeader. ParentSpanID is empty for the first
+	// hop.
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
 }
 
 // ConnectionInfo captures an observed TCP connection.
@@ -38,6 +63,52 @@ type ConnectionInfo struct {
 	BytesIn    int64
 	BytesOut   int64
 	DurationMs float64
+
+	// CloseReason says why the proxy closed the connection, if it did
+	// (e.g. CloseReasonIdle). Empty when either peer closed it.
+	CloseReason string
+
+	// Requests and HTTPProtocol are set on http.connection.closed: the
+	// number of requests the client sent over the connection and the
+	// protocol they used ("HTTP/1.1", "h2c", ...). HTTPProtocol is empty
+	// if no request arrived.
+	Requests     int64
+	HTTPProtocol string
+
+	// PreviewIn and PreviewOut hold the first bytes sent client → target
+	// and target → client, when Forwarder.CapturePreview is set. The
+	// Truncated flags are set when the direction carried more than that.
+	PreviewIn           []byte
+	PreviewInTruncated  bool
+	PreviewOut          []byte
+	PreviewOutTruncated bool
+}
+
+// WebSocketInfo captures an observed websocket connection, upgraded from an
+// HTTP request. Frame and byte counts are zero on websocket.opened.
+type WebSocketInfo struct {
+	Source     string
+	Target     string
+	Ingress    string
+	Path       string
+	FramesIn   int64 // client → target
+	FramesOut  int64 // target → client
+	BytesIn    int64
+	BytesOut   int64
+	DurationMs float64
+}
+
+// FaultInfo describes a fault a forwarder injected. Method and Path are set
+// when the fault hit an HTTP or gRPC request rather than a connection.
+type FaultInfo struct {
+	Source     string
+	Target     string
+	Ingress    string
+	Kind       string  // FaultLatency, FaultDrop or FaultError
+	DelayMs    float64 // latency faults
+	StatusCode int     // error faults
+	Method     string
+	Path       string
 }
 
 // KafkaRequestInfo captures an observed Kafka request/response pair.
@@ -54,6 +125,48 @@ type KafkaRequestInfo struct {
 	ResponseSize  int64
 }
 
+// RedisCommandInfo captures an observed Redis command and its reply.
+type RedisCommandInfo struct {
+	Source       string
+	Target       string
+	Ingress      string
+	Command      string // "GET", "SET", etc.
+	Key          string // first key argument, empty for keyless commands
+	ReplyType    string // "string", "error", "integer", "bulk", "array", "null", ...
+	Error        string // error reply text, e.g. "ERR unknown command"
+	LatencyMs    float64
+	RequestSize  int64
+	ResponseSize int64
+}
+
+// MongoCommandInfo captures an observed MongoDB command and its reply.
+type MongoCommandInfo struct {
+	Source       string
+	Target       string
+	Ingress      string
+	Command      string // "find", "insert", "aggregate", etc.
+	Collection   string // collection the command names, empty for database commands
+	Database     string // the command's $db
+	Error        string // "CodeName: errmsg" when the reply has ok: 0
+	LatencyMs    float64
+	RequestSize  int64
+	ResponseSize int64
+}
+
+// NATSMessageInfo captures an observed NATS message operation: a PUB from a
+// client, a SUB registering interest, or a MSG delivered by the server.
+type NATSMessageInfo struct {
+	Source      string
+	Target      string
+	Ingress     string
+	Op          string // "PUB", "SUB", or "MSG" (HPUB/HMSG report as PUB/MSG)
+	Subject     string
+	ReplyTo     string // reply subject, for request/reply
+	Queue       string // queue group, SUB only
+	PayloadSize int64  // excludes headers
+	HeaderSize  int64  // HPUB/HMSG header block size
+}
+
 // GRPCCallInfo captures an observed gRPC call.
 type GRPCCallInfo struct {
 	Source           string
@@ -75,4 +188,50 @@ type GRPCCallInfo struct {
 	ResponseBodyTruncated bool
 	RequestBodyDecoded    string // JSON from reflection, empty if unavailable
 	ResponseBodyDecoded   string
+
+	// ProxyInjected is set when the proxy answered the call itself (e.g.
+	// a method denied by the egress allowlist) rather than the target.
+	ProxyInjected bool
+
+	// TraceID, SpanID and ParentSpanID are as for RequestInfo.
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+}
+
+// GRPCStreamInfo captures a streaming gRPC call. One value describes each
+// of grpc.stream.opened, grpc.stream.message (Direction and the message
+// fields set) and grpc.stream.closed (status, counts and sizes set).
+type GRPCStreamInfo struct {
+	Source  string
+	Target  string
+	Ingress string
+	Service string // "pkg.ServiceName"
+	Method  string // "MethodName"
+
+	RequestMetadata map[string][]string // opened
+
+	// Message fields, on grpc.stream.message. Direction is "in" for a
+	// message sent by the client, "out" for one sent by the target. Body
+	// is the length-prefixed frame, capped like GRPCCallInfo bodies.
+	Direction     string
+	Body          []byte
+	BodyTruncated bool
+	BodyDecoded   string // JSON from reflection, empty if unavailable
+	Size          int64  // full frame size, including the 5-byte prefix
+
+	// Closed fields, on grpc.stream.closed.
+	GRPCStatus       string
+	GRPCMessage      string
+	MessagesIn       int64
+	MessagesOut      int64
+	RequestSize      int64
+	ResponseSize     int64
+	DurationMs       float64
+	ResponseMetadata map[string][]string
+
+	// TraceID, SpanID and ParentSpanID are as for RequestInfo.
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
 }
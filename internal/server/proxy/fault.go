@@ -0,0 +1,185 @@
+package proxy
+
+import (
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Fault kinds reported in FaultInfo.Kind.
+const (
+	FaultLatency = "latency"
+	FaultDrop    = "drop"
+	FaultError   = "error"
+)
+
+// Fault makes a forwarder misbehave on purpose, so a test can check how
+// the source copes with a slow or unreliable dependency. Every fault the
+// forwarder injects is emitted as a fault.injected event.
+type Fault struct {
+	// Latency delays each HTTP or gRPC request, or the first read of any
+	// other connection, before it is forwarded.
+	Latency time.Duration
+
+	// Jitter adds a random extra delay of up to this much on top of
+	// Latency.
+	Jitter time.Duration
+
+	// DropRate is the fraction of connections, 0 to 1, closed as soon as
+	// they are accepted.
+	DropRate float64
+
+	// ErrorRate is the fraction of HTTP requests, 0 to 1, answered with
+	// 503 instead of being forwarded.
+	ErrorRate float64
+
+	// Rand, when set, replaces the random source deciding which
+	// connections and requests are hit. It returns values in [0, 1).
+	Rand func() float64
+}
+
+func (fl *Fault) rand() float64 {
+	if fl.Rand != nil {
+		return fl.Rand()
+	}
+	return rand.Float64()
+}
+
+// hit reports whether an event with probability rate should be faulted.
+func (fl *Fault) hit(rate float64) bool {
+	return rate > 0 && fl.rand() < rate
+}
+
+// delay returns the latency to add to one request or connection, or zero.
+func (fl *Fault) delay() time.Duration {
+	d := fl.Latency
+	if fl.Jitter > 0 {
+		d += time.Duration(fl.rand() * float64(fl.Jitter))
+	}
+	return d
+}
+
+// emitFault publishes a fault.injected event for this forwarder's edge.
+func (f *Forwarder) emitFault(info FaultInfo) {
+	info.Source = f.Source
+	info.Target = f.TargetSvc
+	info.Ingress = f.Ingress
+	f.emit(Event{Type: "fault.injected", Fault: &info})
+}
+
+// requestFaults reports whether f.Fault is applied per request by the HTTP
+// and gRPC handlers. Other forwarders, auto-detecting ones included, delay
+// connections instead.
+func (f *Forwarder) requestFaults() bool {
+	return f.Fault != nil && !f.AutoDetect && (f.Protocol == "http" || f.Protocol == "grpc")
+}
+
+// faultListener applies f.Fault to accepted connections: a fraction are
+// closed straight away, and, unless requests are delayed individually,
+// the rest have their first read delayed.
+type faultListener struct {
+	net.Listener
+	f *Forwarder
+}
+
+func (l faultListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		fl := l.f.Fault
+		if fl.hit(fl.DropRate) {
+			conn.Close()
+			l.f.emitFault(FaultInfo{Kind: FaultDrop})
+			continue
+		}
+		if l.f.requestFaults() || (fl.Latency <= 0 && fl.Jitter <= 0) {
+			return conn, nil
+		}
+		return &delayedConn{Conn: conn, f: l.f}, nil
+	}
+}
+
+// delayedConn sleeps for a fault delay before its first read, so nothing
+// the client sends reaches the target until the delay has passed.
+type delayedConn struct {
+	net.Conn
+	f    *Forwarder
+	once sync.Once
+}
+
+func (c *delayedConn) Read(p []byte) (int, error) {
+	c.once.Do(func() {
+		d := c.f.Fault.delay()
+		time.Sleep(d)
+		c.f.emitFault(FaultInfo{Kind: FaultLatency, DelayMs: float64(d.Microseconds()) / 1000.0})
+	})
+	return c.Conn.Read(p)
+}
+
+// faultRequests wraps next to delay each request by the fault latency and
+// answer a fraction of them with 503 instead of forwarding them. Only
+// HTTP forwarders inject errors; gRPC calls are only delayed. Injected
+// responses are emitted as request.completed events marked ProxyInjected.
+func (f *Forwarder) faultRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fl := f.Fault
+		path := r.URL.Path
+		if r.URL.RawQuery != "" {
+			path += "?" + r.URL.RawQuery
+		}
+		if d := fl.delay(); d > 0 {
+			t := time.NewTimer(d)
+			select {
+			case <-t.C:
+			case <-r.Context().Done():
+				t.Stop()
+				return
+			}
+			f.emitFault(FaultInfo{Kind: FaultLatency, DelayMs: float64(d.Microseconds()) / 1000.0, Method: r.Method, Path: path})
+		}
+		if f.Protocol != "http" || !fl.hit(fl.ErrorRate) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		msg := "rig proxy: injected fault\n"
+		reqCapture := newCappedBuffer(f.BodyLimit)
+		io.Copy(reqCapture, r.Body)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, msg)
+
+		f.emitFault(FaultInfo{Kind: FaultError, StatusCode: http.StatusServiceUnavailable, Method: r.Method, Path: path})
+		trace := nextTrace(r.Header.Get(TraceHeader))
+		f.emit(Event{
+			Type: "request.completed",
+			Request: &RequestInfo{
+				Source:               f.Source,
+				Target:               f.TargetSvc,
+				Ingress:              f.Ingress,
+				Method:               r.Method,
+				Path:                 path,
+				StatusCode:           http.StatusServiceUnavailable,
+				LatencyMs:            float64(time.Since(start).Microseconds()) / 1000.0,
+				RequestSize:          reqCapture.total,
+				ResponseSize:         int64(len(msg)),
+				RequestHeaders:       withoutHeader(r.Header, LabelHeader),
+				RequestBody:          reqCapture.bytes(),
+				RequestBodyTruncated: reqCapture.truncated,
+				ResponseHeaders:      cloneHeaders(w.Header()),
+				ResponseBody:         []byte(msg),
+				ProxyInjected:        true,
+				Label:                r.Header.Get(LabelHeader),
+				TraceID:              trace.TraceID,
+				SpanID:               trace.SpanID,
+				ParentSpanID:         trace.ParentSpanID,
+			},
+		})
+	})
+}
@@ -2,7 +2,10 @@ package proxy
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
+	"net/url"
+	"time"
 
 	"github.com/matgreaves/rig/internal/spec"
 	"github.com/matgreaves/run"
@@ -21,6 +24,65 @@ type Forwarder struct {
 	Emit       func(Event)   // publish to event log
 	Decoder    *GRPCDecoder  // set once before traffic flows; nil if reflection unavailable
 	Listener   net.Listener // pre-opened listener; avoids TOCTOU race when set
+
+	// MaxBodySize, when positive, rejects HTTP requests with larger bodies
+	// with 413 instead of forwarding them.
+	MaxBodySize int64
+
+	// AllowMethods, when non-empty, restricts gRPC traffic to the listed
+	// methods ("Method" or "pkg.Service/Method"). Other calls are answered
+	// with PERMISSION_DENIED instead of being forwarded.
+	AllowMethods []string
+
+	// Mocks, when non-empty, are canned HTTP responses served instead of
+	// forwarding matching requests. See Mock.
+	Mocks []Mock
+
+	// IdleTimeout, when positive, closes TCP relay connections that have
+	// carried no data in either direction for this long.
+	IdleTimeout time.Duration
+
+	// BodyLimit caps the HTTP and gRPC body bytes captured per request or
+	// response for the event log. Zero uses the 64KB default; negative
+	// disables body capture. Bodies are always forwarded in full.
+	BodyLimit int
+
+	// ConnPool, when positive, gives an HTTP forwarder its own transport
+	// that keeps up to this many idle target connections open for reuse.
+	// Otherwise it shares http.DefaultTransport, which keeps two, so a
+	// caller with more requests in flight dials the target again.
+	ConnPool int
+
+	// Redact, when set, masks headers and JSON body fields in every event
+	// before it reaches Emit. See Redaction.
+	Redact *Redaction
+
+	// CapturePreview, when positive, records up to this many of the first
+	// bytes relayed in each direction of a TCP connection as a preview on
+	// connection.closed. Zero disables previews. Bytes are always relayed
+	// in full.
+	CapturePreview int
+
+	// TLS, when set on an HTTP forwarder, terminates TLS on the listen side
+	// with this config and dials the target over TLS, so HTTPS traffic can
+	// be decoded. The target's certificate is not verified: in tests it is
+	// typically self-signed.
+	TLS *tls.Config
+
+	// AutoDetect, when set on a TCP forwarder, sniffs the first bytes of
+	// each connection and decodes it as HTTP, gRPC or Kafka when they match
+	// that protocol's opening. Anything else is relayed as opaque TCP.
+	AutoDetect bool
+
+	// External, when set on an HTTP forwarder, is the base URL of a target
+	// outside the environment. Requests are sent to its scheme, host and
+	// path prefix with the Host header rewritten to match, so virtual-hosted
+	// APIs route them. https targets have their certificates verified.
+	External *url.URL
+
+	// Fault, when set, injects latency, dropped connections or HTTP
+	// errors into the edge's traffic. See Fault.
+	Fault *Fault
 }
 
 // Endpoint returns the proxy endpoint that callers should connect to.
@@ -43,7 +105,8 @@ func (f *Forwarder) Endpoint() spec.Endpoint {
 }
 
 // Runner returns a run.Runner that listens and forwards traffic.
-// Dispatches to HTTP reverse proxy or TCP relay based on Protocol.
+// Dispatches to HTTP reverse proxy or TCP relay based on Protocol, or
+// per connection when AutoDetect is set.
 func (f *Forwarder) Runner() run.Runner {
 	return run.Func(func(ctx context.Context) error {
 		switch f.Protocol {
@@ -53,17 +116,34 @@ func (f *Forwarder) Runner() run.Runner {
 			return f.runGRPC(ctx)
 		case "kafka":
 			return f.runKafka(ctx)
+		case "redis":
+			return f.runRedis(ctx)
+		case "nats":
+			return f.runNATS(ctx)
+		case "mongo":
+			return f.runMongo(ctx)
 		default:
+			if f.AutoDetect {
+				return f.runAutoDetect(ctx)
+			}
 			// TCP relay for tcp and anything else.
 			return f.runTCP(ctx)
 		}
 	})
 }
 
-// getListener returns the pre-opened listener if set, otherwise opens a new one.
+// getListener returns the pre-opened listener if set, otherwise opens a new
+// one. With a Fault set, the listener drops and delays connections.
 func (f *Forwarder) getListener() (net.Listener, error) {
-	if f.Listener != nil {
-		return f.Listener, nil
+	ln := f.Listener
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", f.ListenAddr); err != nil {
+			return nil, err
+		}
+	}
+	if f.Fault != nil {
+		ln = faultListener{Listener: ln, f: f}
 	}
-	return net.Listen("tcp", f.ListenAddr)
+	return ln, nil
 }
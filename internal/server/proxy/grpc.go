@@ -4,10 +4,16 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
 
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
@@ -16,6 +22,26 @@ import (
 // runGRPC starts an HTTP/2 cleartext reverse proxy that captures gRPC metadata.
 // Structurally identical to runHTTP but uses h2c for HTTP/2 without TLS.
 func (f *Forwarder) runGRPC(ctx context.Context) error {
+	ln, err := f.getListener()
+	if err != nil {
+		return fmt.Errorf("proxy %s→%s: listen: %w", f.Source, f.TargetSvc, err)
+	}
+
+	srv := f.grpcServer()
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	err = srv.Serve(ln)
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// grpcServer builds the h2c observing reverse proxy server runGRPC serves.
+func (f *Forwarder) grpcServer() *http.Server {
 	target := &url.URL{
 		Scheme: "http",
 		Host:   f.Target.HostPort,
@@ -30,30 +56,109 @@ func (f *Forwarder) runGRPC(ctx context.Context) error {
 				return (&net.Dialer{}).DialContext(ctx, network, addr)
 			},
 		},
-		emit:       f.Emit,
+		emit:       f.emit,
 		source:     f.Source,
 		target:     f.TargetSvc,
 		ingress:    f.Ingress,
+		bodyLimit:  f.BodyLimit,
 		getDecoder: func() *GRPCDecoder { return f.Decoder },
 	}
 
-	ln, err := f.getListener()
-	if err != nil {
-		return fmt.Errorf("proxy %s→%s: listen: %w", f.Source, f.TargetSvc, err)
+	var inner http.Handler = proxy
+	if len(f.AllowMethods) > 0 {
+		inner = f.allowMethods(proxy)
+	}
+	if f.requestFaults() {
+		inner = f.faultRequests(inner)
 	}
 
 	h2s := &http2.Server{}
-	handler := h2c.NewHandler(proxy, h2s)
-	srv := &http.Server{Handler: handler}
+	return &http.Server{Handler: h2c.NewHandler(inner, h2s)}
+}
 
-	go func() {
-		<-ctx.Done()
-		srv.Close()
-	}()
+// allowMethods wraps next so that gRPC calls to methods outside
+// f.AllowMethods are answered with PERMISSION_DENIED without being
+// forwarded, simulating a service mesh authorization policy. Denied calls
+// are emitted as grpc.call.completed events marked ProxyInjected.
+//
+// The request body is never waited on, since a streaming client keeps its
+// side open and would otherwise hang: the event records only the part of
+// the request that had already arrived.
+func (f *Forwarder) allowMethods(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		svc, method := parseGRPCPath(r.URL.Path)
+		if methodAllowed(f.AllowMethods, svc, method) {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-	err = srv.Serve(ln)
-	if err == http.ErrServerClosed {
-		return nil
+		start := time.Now()
+		msg := fmt.Sprintf("rig: method %s/%s is not allowed on egress %s→%s", svc, method, f.Source, f.TargetSvc)
+		reqHeaders := cloneHeaders(r.Header)
+		trace := nextTrace(r.Header.Get(TraceHeader))
+
+		// Trailers-only response: status travels in the headers frame.
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(codes.PermissionDenied)))
+		w.Header().Set("Grpc-Message", encodeGRPCMessage(msg))
+		w.WriteHeader(http.StatusOK)
+
+		// Capture what the client has sent so far; the expired deadline
+		// stops the read once the buffered data is consumed.
+		reqCapture := newCappedBuffer(f.BodyLimit)
+		if err := http.NewResponseController(w).SetReadDeadline(time.Now()); err == nil {
+			io.Copy(reqCapture, r.Body)
+		}
+
+		f.emit(Event{
+			Type: "grpc.call.completed",
+			GRPCCall: &GRPCCallInfo{
+				Source:               f.Source,
+				Target:               f.TargetSvc,
+				Ingress:              f.Ingress,
+				Service:              svc,
+				Method:               method,
+				GRPCStatus:           codes.PermissionDenied.String(),
+				GRPCMessage:          msg,
+				LatencyMs:            float64(time.Since(start).Microseconds()) / 1000.0,
+				RequestSize:          reqCapture.total,
+				RequestMetadata:      reqHeaders,
+				RequestBody:          reqCapture.bytes(),
+				RequestBodyTruncated: reqCapture.truncated,
+				ProxyInjected:        true,
+				TraceID:              trace.TraceID,
+				SpanID:               trace.SpanID,
+				ParentSpanID:         trace.ParentSpanID,
+			},
+		})
+	})
+}
+
+// encodeGRPCMessage percent-encodes msg for the grpc-message header, which
+// only carries printable ASCII: every byte outside 0x20-0x7E, and '%'
+// itself, is written as %XX.
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
 	}
-	return err
+	return b.String()
+}
+
+// methodAllowed reports whether the gRPC method svc/method matches an entry
+// in allow, given either as a bare method name or as "pkg.Service/Method".
+func methodAllowed(allow []string, svc, method string) bool {
+	full := svc + "/" + method
+	for _, a := range allow {
+		a = strings.TrimPrefix(a, "/")
+		if a == method || a == full {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,245 @@
+package proxy_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/matgreaves/rig/internal/server/proxy"
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+func TestForwarderGRPC_AllowMethods(t *testing.T) {
+	upstreamLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream := grpc.NewServer()
+	healthpb.RegisterHealthServer(upstream, health.NewServer())
+	go upstream.Serve(upstreamLn)
+	defer upstream.Stop()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var events []proxy.Event
+	f := &proxy.Forwarder{
+		ListenAddr:   ln.Addr().String(),
+		Target:       spec.Endpoint{HostPort: upstreamLn.Addr().String(), Protocol: spec.GRPC},
+		Source:       "worker",
+		TargetSvc:    "health",
+		Ingress:      "default",
+		Protocol:     "grpc",
+		Listener:     ln,
+		AllowMethods: []string{"grpc.health.v1.Health/Check"},
+		Emit: func(ev proxy.Event) {
+			mu.Lock()
+			events = append(events, ev)
+			mu.Unlock()
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	callCtx, callCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer callCancel()
+
+	if _, err := client.Check(callCtx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("allowed Check: %v", err)
+	}
+	_, err = client.List(callCtx, &healthpb.HealthListRequest{})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("denied List: got %v, want PERMISSION_DENIED", err)
+	}
+	if msg, want := status.Convert(err).Message(), "rig: method grpc.health.v1.Health/List is not allowed on egress worker→health"; msg != want {
+		t.Errorf("denied List message = %q, want %q", msg, want)
+	}
+
+	// A denied streaming call must be answered while the client still
+	// holds its send side open.
+	stream, err := conn.NewStream(callCtx, &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, "/grpc.health.v1.Health/Upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&healthpb.HealthCheckRequest{Service: "a"}); err != nil {
+		t.Fatalf("SendMsg: %v", err)
+	}
+	if err := stream.RecvMsg(&healthpb.HealthCheckResponse{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("denied stream: got %v, want PERMISSION_DENIED", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var denied []*proxy.GRPCCallInfo
+	for _, ev := range events {
+		if ev.GRPCCall != nil && ev.GRPCCall.ProxyInjected {
+			denied = append(denied, ev.GRPCCall)
+		}
+	}
+	if len(denied) != 2 {
+		t.Fatalf("got %d proxy-injected events, want 2", len(denied))
+	}
+	if d := denied[0]; d.Method != "List" || d.GRPCStatus != "PermissionDenied" {
+		t.Errorf("denied event = %+v", d)
+	}
+}
+
+func TestForwarderGRPC_Streaming(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		decoder bool
+	}{
+		{"Framing", false},
+		{"Reflection", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			upstreamLn, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			upstream := grpc.NewServer()
+			hs := health.NewServer()
+			healthpb.RegisterHealthServer(upstream, hs)
+			reflection.Register(upstream)
+			go upstream.Serve(upstreamLn)
+			defer upstream.Stop()
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var mu sync.Mutex
+			var events []proxy.Event
+			f := &proxy.Forwarder{
+				ListenAddr: ln.Addr().String(),
+				Target:     spec.Endpoint{HostPort: upstreamLn.Addr().String(), Protocol: spec.GRPC},
+				Source:     "worker",
+				TargetSvc:  "health",
+				Ingress:    "default",
+				Protocol:   "grpc",
+				Listener:   ln,
+				Emit: func(ev proxy.Event) {
+					mu.Lock()
+					events = append(events, ev)
+					mu.Unlock()
+				},
+			}
+			if tc.decoder {
+				f.Decoder = proxy.ProbeReflection(context.Background(), upstreamLn.Addr().String())
+				if f.Decoder == nil {
+					t.Fatal("reflection probe failed")
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- f.Runner().Run(ctx) }()
+			defer func() {
+				cancel()
+				<-done
+			}()
+
+			conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			client := healthpb.NewHealthClient(conn)
+
+			callCtx, callCancel := context.WithTimeout(ctx, 5*time.Second)
+			defer callCancel()
+
+			// A unary call is still reported as a single completed call.
+			if _, err := client.Check(callCtx, &healthpb.HealthCheckRequest{}); err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+
+			// Watch streams a status, then another when it changes.
+			watchCtx, watchCancel := context.WithCancel(callCtx)
+			stream, err := client.Watch(watchCtx, &healthpb.HealthCheckRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := stream.Recv(); err != nil {
+				t.Fatal(err)
+			}
+			hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+			if _, err := stream.Recv(); err != nil {
+				t.Fatal(err)
+			}
+			watchCancel()
+
+			var types []string
+			var closed *proxy.GRPCStreamInfo
+			deadline := time.Now().Add(5 * time.Second)
+			for closed == nil && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				types = types[:0]
+				for _, ev := range events {
+					types = append(types, ev.Type)
+					if ev.Type == "grpc.stream.closed" {
+						closed = ev.GRPCStream
+					}
+				}
+				mu.Unlock()
+			}
+			want := []string{
+				"grpc.call.completed",
+				"grpc.stream.opened",
+				"grpc.stream.message",
+				"grpc.stream.message",
+				"grpc.stream.message",
+				"grpc.stream.closed",
+			}
+			if strings.Join(types, ",") != strings.Join(want, ",") {
+				t.Fatalf("events = %v, want %v", types, want)
+			}
+			if closed.Method != "Watch" || closed.MessagesIn != 1 || closed.MessagesOut != 2 {
+				t.Errorf("closed = %+v, want Watch with 1 in, 2 out", closed)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			var dirs []string
+			for _, ev := range events {
+				if ev.Type == "grpc.stream.message" {
+					dirs = append(dirs, ev.GRPCStream.Direction)
+					if tc.decoder && ev.GRPCStream.BodyDecoded == "" && ev.GRPCStream.Size > 5 {
+						t.Errorf("message %+v not decoded", ev.GRPCStream)
+					}
+				}
+			}
+			if got := strings.Join(dirs, ","); got != "in,out,out" {
+				t.Errorf("message directions = %s, want in,out,out", got)
+			}
+		})
+	}
+}
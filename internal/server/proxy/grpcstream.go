@@ -0,0 +1,224 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// grpcStreamObserver follows the messages of one gRPC call in both
+// directions and decides whether it is a stream. A call is a stream when
+// the decoder's descriptor says the method streams, or, without one, as
+// soon as either direction carries a second message. Until then messages
+// are held back, so a unary call emits nothing here and is reported by
+// the usual grpc.call.completed.
+type grpcStreamObserver struct {
+	emit      func(Event)
+	info      GRPCStreamInfo // identity, metadata and trace fields
+	decoder   *GRPCDecoder
+	bodyLimit int
+	start     time.Time
+
+	mu        sync.Mutex
+	streaming bool
+	pending   []GRPCStreamInfo
+	in, out   int64
+}
+
+func newGRPCStreamObserver(t *observingTransport, svc, method string, reqHeaders map[string][]string, trace traceContext, start time.Time) *grpcStreamObserver {
+	o := &grpcStreamObserver{
+		emit: t.emit,
+		info: GRPCStreamInfo{
+			Source:          t.source,
+			Target:          t.target,
+			Ingress:         t.ingress,
+			Service:         svc,
+			Method:          method,
+			RequestMetadata: reqHeaders,
+			TraceID:         trace.TraceID,
+			SpanID:          trace.SpanID,
+			ParentSpanID:    trace.ParentSpanID,
+		},
+		bodyLimit: t.bodyLimit,
+		start:     start,
+	}
+	if t.getDecoder != nil {
+		o.decoder = t.getDecoder()
+	}
+	return o
+}
+
+// openIfKnown opens the stream straight away when the decoder's descriptor
+// says the method streams. Called once the target has answered, so a call
+// that never reaches it emits nothing.
+func (o *grpcStreamObserver) openIfKnown() {
+	if o.decoder == nil {
+		return
+	}
+	if streaming, ok := o.decoder.Streaming(o.info.Service, o.info.Method); ok && streaming {
+		o.open()
+	}
+}
+
+// framer returns a writer that splits one direction's bytes into messages.
+func (o *grpcStreamObserver) framer(direction string) *grpcFramer {
+	return &grpcFramer{
+		limit:     o.bodyLimit,
+		onMessage: func(frame *cappedBuffer) { o.message(direction, frame) },
+	}
+}
+
+// isStreaming reports whether the call has been identified as a stream.
+func (o *grpcStreamObserver) isStreaming() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.streaming
+}
+
+func (o *grpcStreamObserver) message(direction string, frame *cappedBuffer) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	msg := o.base()
+	msg.Direction = direction
+	msg.Body = frame.bytes()
+	msg.BodyTruncated = frame.truncated
+	msg.Size = frame.total
+	if direction == "in" {
+		o.in++
+	} else {
+		o.out++
+	}
+
+	if o.streaming {
+		o.emitMessage(msg)
+		return
+	}
+	o.pending = append(o.pending, msg)
+	if o.in > 1 || o.out > 1 {
+		o.openLocked()
+	}
+}
+
+// open marks the call as a stream and emits grpc.stream.opened.
+func (o *grpcStreamObserver) open() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.streaming {
+		o.openLocked()
+	}
+}
+
+func (o *grpcStreamObserver) openLocked() {
+	o.streaming = true
+	opened := o.base()
+	opened.RequestMetadata = o.info.RequestMetadata
+	o.emit(Event{Type: "grpc.stream.opened", GRPCStream: &opened})
+	for _, msg := range o.pending {
+		o.emitMessage(msg)
+	}
+	o.pending = nil
+}
+
+func (o *grpcStreamObserver) emitMessage(msg GRPCStreamInfo) {
+	if o.decoder != nil {
+		msg.BodyDecoded = o.decoder.Decode(msg.Service, msg.Method, msg.Body, msg.Direction == "in")
+	}
+	o.emit(Event{Type: "grpc.stream.message", GRPCStream: &msg})
+}
+
+// close emits grpc.stream.closed with the call's final status.
+func (o *grpcStreamObserver) close(grpcStatus, grpcMessage string, respMeta map[string][]string, reqSize, respSize int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	closed := o.base()
+	closed.GRPCStatus = grpcStatus
+	closed.GRPCMessage = grpcMessage
+	closed.MessagesIn = o.in
+	closed.MessagesOut = o.out
+	closed.RequestSize = reqSize
+	closed.ResponseSize = respSize
+	closed.DurationMs = float64(time.Since(o.start).Microseconds()) / 1000.0
+	closed.ResponseMetadata = respMeta
+	o.emit(Event{Type: "grpc.stream.closed", GRPCStream: &closed})
+}
+
+// base returns the identity and trace fields shared by every stream event.
+func (o *grpcStreamObserver) base() GRPCStreamInfo {
+	return GRPCStreamInfo{
+		Source:       o.info.Source,
+		Target:       o.info.Target,
+		Ingress:      o.info.Ingress,
+		Service:      o.info.Service,
+		Method:       o.info.Method,
+		TraceID:      o.info.TraceID,
+		SpanID:       o.info.SpanID,
+		ParentSpanID: o.info.ParentSpanID,
+	}
+}
+
+// grpcFramer splits a stream of gRPC length-prefixed messages written to
+// it, calling onMessage with each complete frame (prefix included, capped
+// at limit like other captured bodies). gRPC-Web trailer frames, flagged
+// with the high bit, are not messages: their block goes to onTrailer, if
+// set, instead.
+type grpcFramer struct {
+	limit     int
+	onMessage func(frame *cappedBuffer)
+	onTrailer func(block []byte)
+
+	hdr       [5]byte
+	hdrN      int
+	cur       *cappedBuffer
+	trailer   []byte
+	inTrailer bool
+	remaining uint32
+}
+
+// grpcWebTrailerFlag marks a gRPC-Web frame that carries trailers.
+const grpcWebTrailerFlag = 0x80
+
+// maxGRPCWebTrailer caps the trailer block kept for parsing.
+const maxGRPCWebTrailer = 64 << 10
+
+func (f *grpcFramer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if f.cur == nil {
+			c := copy(f.hdr[f.hdrN:], p)
+			f.hdrN += c
+			p = p[c:]
+			if f.hdrN < len(f.hdr) {
+				break
+			}
+			f.hdrN = 0
+			f.remaining = binary.BigEndian.Uint32(f.hdr[1:5])
+			f.cur = newCappedBuffer(f.limit)
+			f.cur.Write(f.hdr[:])
+			f.inTrailer = f.hdr[0]&grpcWebTrailerFlag != 0
+			f.trailer = f.trailer[:0]
+		}
+		k := len(p)
+		if uint64(k) > uint64(f.remaining) {
+			k = int(f.remaining)
+		}
+		if f.inTrailer {
+			if room := maxGRPCWebTrailer - len(f.trailer); room > 0 {
+				f.trailer = append(f.trailer, p[:min(k, room)]...)
+			}
+		} else {
+			f.cur.Write(p[:k])
+		}
+		f.remaining -= uint32(k)
+		p = p[k:]
+		if f.remaining == 0 {
+			if !f.inTrailer {
+				f.onMessage(f.cur)
+			} else if f.onTrailer != nil {
+				f.onTrailer(f.trailer)
+			}
+			f.cur = nil
+		}
+	}
+	return n, nil
+}
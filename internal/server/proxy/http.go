@@ -3,9 +3,12 @@ package proxy
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/http/httputil"
 	"net/url"
 	"strconv"
@@ -16,33 +19,29 @@ import (
 	"google.golang.org/grpc/codes"
 )
 
-// maxBodyCapture is the maximum number of body bytes captured per request or
-// response for the event log. The full body is always forwarded regardless.
+// maxBodyCapture is the default maximum number of body bytes captured per
+// request or response for the event log (see Forwarder.BodyLimit). The full
+// body is always forwarded regardless.
 const maxBodyCapture = 64 * 1024 // 64KB
 
+// LabelHeader is the request header callers set to label a request for
+// correlation in the traffic log (see connect/httpx.LabelHeader). The proxy
+// records it on the request.completed event and strips it before
+// forwarding.
+const LabelHeader = "X-Rig-Label"
+
 // runHTTP starts an HTTP reverse proxy that captures request metadata.
 func (f *Forwarder) runHTTP(ctx context.Context) error {
-	target := &url.URL{
-		Scheme: "http",
-		Host:   f.Target.HostPort,
-	}
-
-	proxy := httputil.NewSingleHostReverseProxy(target)
-	proxy.Transport = &observingTransport{
-		inner:   http.DefaultTransport,
-		emit:    f.Emit,
-		source:  f.Source,
-		target:  f.TargetSvc,
-		ingress: f.Ingress,
-	}
-
 	ln, err := f.getListener()
 	if err != nil {
 		return fmt.Errorf("proxy %s→%s: listen: %w", f.Source, f.TargetSvc, err)
 	}
+	ln = httpConnListener{ln}
+	if f.TLS != nil {
+		ln = tls.NewListener(ln, f.TLS)
+	}
 
-	srv := &http.Server{Handler: proxy}
-
+	srv := f.httpServer(ctx)
 	go func() {
 		<-ctx.Done()
 		srv.Close()
@@ -55,6 +54,171 @@ func (f *Forwarder) runHTTP(ctx context.Context) error {
 	return err
 }
 
+// httpServer builds the observing reverse proxy server runHTTP serves.
+func (f *Forwarder) httpServer(ctx context.Context) *http.Server {
+	target := &url.URL{
+		Scheme: "http",
+		Host:   f.Target.HostPort,
+	}
+	inner := http.DefaultTransport
+	if f.TLS != nil || f.ConnPool > 0 {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		if f.TLS != nil {
+			target.Scheme = "https"
+			t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		if f.ConnPool > 0 {
+			t.MaxIdleConns = f.ConnPool
+			t.MaxIdleConnsPerHost = f.ConnPool
+			go func() {
+				<-ctx.Done()
+				t.CloseIdleConnections()
+			}()
+		}
+		inner = t
+	}
+
+	if f.External != nil {
+		target = f.External
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	if f.External != nil {
+		director := proxy.Director
+		proxy.Director = func(req *http.Request) {
+			director(req)
+			req.Host = target.Host
+		}
+	}
+	proxy.Transport = &observingTransport{
+		inner:      inner,
+		emit:       f.emit,
+		source:     f.Source,
+		target:     f.TargetSvc,
+		ingress:    f.Ingress,
+		bodyLimit:  f.BodyLimit,
+		getDecoder: func() *GRPCDecoder { return f.Decoder }, // gRPC-Web
+	}
+
+	var handler http.Handler = proxy
+	if len(f.Mocks) > 0 {
+		handler = f.mock(handler)
+	}
+	if f.MaxBodySize > 0 {
+		handler = f.limitBody(handler)
+	}
+	if f.requestFaults() {
+		handler = f.faultRequests(handler)
+	}
+	handler = stampArrival(handler)
+
+	// Hijacked connections (websocket upgrades) outlive srv.Close, so
+	// derive request contexts from ctx: the reverse proxy closes the
+	// upgraded backend connection when its request context is done.
+	// Clients with prior knowledge may speak HTTP/2 in cleartext (h2c).
+	srv := &http.Server{
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+		Protocols:   new(http.Protocols),
+	}
+	srv.Protocols.SetHTTP1(true)
+	srv.Protocols.SetHTTP2(true)
+	srv.Protocols.SetUnencryptedHTTP2(true)
+	f.trackHTTPConns(srv)
+	return srv
+}
+
+// limitBody wraps next so that requests whose body exceeds f.MaxBodySize are
+// rejected with 413 without being forwarded. A declared Content-Length is
+// checked up front; chunked bodies are buffered up to the limit and
+// rejected as soon as one more byte arrives. The emitted event is marked
+// ProxyInjected so the 413 is not attributed to the target service.
+func (f *Forwarder) limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		if r.ContentLength > f.MaxBodySize {
+			f.rejectTooLarge(w, r, r.ContentLength, nil, start)
+			return
+		}
+		if r.ContentLength < 0 && r.Body != nil {
+			buf, err := io.ReadAll(io.LimitReader(r.Body, f.MaxBodySize+1))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if int64(len(buf)) > f.MaxBodySize {
+				f.rejectTooLarge(w, r, int64(len(buf)), buf, start)
+				return
+			}
+			r.Body = readCloser{Reader: bytes.NewReader(buf), Closer: r.Body}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rejectTooLarge writes a 413 response and emits a request.completed event
+// for it. size is the number of body bytes seen before rejecting.
+func (f *Forwarder) rejectTooLarge(w http.ResponseWriter, r *http.Request, size int64, body []byte, start time.Time) {
+	msg := fmt.Sprintf("request body exceeds %d bytes (rig proxy limit)\n", f.MaxBodySize)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	io.WriteString(w, msg)
+
+	reqCapture := newCappedBuffer(f.BodyLimit)
+	reqCapture.Write(body)
+	path := r.URL.Path
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	trace := nextTrace(r.Header.Get(TraceHeader))
+	f.emit(Event{
+		Type: "request.completed",
+		Request: &RequestInfo{
+			Source:               f.Source,
+			Target:               f.TargetSvc,
+			Ingress:              f.Ingress,
+			Method:               r.Method,
+			Path:                 path,
+			StatusCode:           http.StatusRequestEntityTooLarge,
+			LatencyMs:            float64(time.Since(start).Microseconds()) / 1000.0,
+			RequestSize:          size,
+			ResponseSize:         int64(len(msg)),
+			RequestHeaders:       withoutHeader(r.Header, LabelHeader),
+			RequestBody:          reqCapture.bytes(),
+			RequestBodyTruncated: reqCapture.truncated,
+			ResponseHeaders:      cloneHeaders(w.Header()),
+			ResponseBody:         []byte(msg),
+			ProxyInjected:        true,
+			Label:                r.Header.Get(LabelHeader),
+			TraceID:              trace.TraceID,
+			SpanID:               trace.SpanID,
+			ParentSpanID:         trace.ParentSpanID,
+		},
+	})
+}
+
+// withoutHeader returns a copy of h with key removed.
+func withoutHeader(h http.Header, key string) map[string][]string {
+	c := cloneHeaders(h)
+	delete(c, http.CanonicalHeaderKey(key))
+	return c
+}
+
+// arrivalKey is the context key under which an HTTP forwarder's request
+// contexts carry the time the request reached the proxy.
+type arrivalKey struct{}
+
+// stampArrival records when each request reached the proxy, so the time it
+// spends in the proxy before reaching the target can be reported apart from
+// the target's latency.
+func stampArrival(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), arrivalKey{}, time.Now())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // observingTransport wraps an http.RoundTripper to capture headers and bodies.
 type observingTransport struct {
 	inner      http.RoundTripper
@@ -62,45 +226,87 @@ type observingTransport struct {
 	source     string
 	target     string
 	ingress    string
+	bodyLimit  int                 // see Forwarder.BodyLimit
 	getDecoder func() *GRPCDecoder // returns decoder lazily; nil means no decoding
 }
 
 func (t *observingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The label header is for rig only; record it and keep it away from
+	// the target. req is the reverse proxy's outbound copy, so deleting
+	// here does not affect the inbound request.
+	label := req.Header.Get(LabelHeader)
+	req.Header.Del(LabelHeader)
+	trace := traceRequest(req.Header)
+
 	// Copy request headers before the transport modifies them.
 	reqHeaders := cloneHeaders(req.Header)
 
+	start := time.Now()
+	arrived, ok := req.Context().Value(arrivalKey{}).(time.Time)
+	if !ok {
+		arrived = start
+	}
+
+	// The target's latency is timed from when the request has a connection
+	// to it, so dialing (or waiting for a pooled connection) counts as
+	// proxy overhead rather than target latency.
+	var gotConn time.Time
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) { gotConn = time.Now() },
+	}))
+
+	// gRPC calls are also split into messages as they pass, so streams
+	// can be reported message by message.
+	var stream *grpcStreamObserver
+	isGRPC, web := grpcContentType(req.Header.Get("Content-Type"))
+	if isGRPC {
+		svc, method := parseGRPCPath(req.URL.Path)
+		stream = newGRPCStreamObserver(t, svc, method, reqHeaders, trace, start)
+	}
+
 	// Tee request body into a capped buffer as the transport reads it.
-	reqCapture := &cappedBuffer{max: maxBodyCapture}
+	reqCapture := newCappedBuffer(t.bodyLimit)
 	if req.Body != nil {
+		var w io.Writer = reqCapture
+		if stream != nil {
+			w = io.MultiWriter(reqCapture, stream.framer("in"))
+		}
 		req.Body = readCloser{
-			Reader: io.TeeReader(req.Body, reqCapture),
+			Reader: io.TeeReader(req.Body, w),
 			Closer: req.Body,
 		}
 	}
 
-	start := time.Now()
 	resp, err := t.inner.RoundTrip(req)
 	if err != nil {
 		return nil, err
 	}
-	latency := time.Since(start)
-
-	// Branch: gRPC uses trailers for status, needs different event shape.
-	ct := req.Header.Get("Content-Type")
-	if strings.HasPrefix(ct, "application/grpc") {
-		return t.observeGRPC(req, resp, reqCapture, reqHeaders, latency)
+	sent := start
+	if !gotConn.IsZero() {
+		sent = gotConn
 	}
-
-	respHeaders := cloneHeaders(resp.Header)
+	latency := time.Since(sent)
+	overhead := sent.Sub(arrived)
 
 	path := req.URL.Path
 	if req.URL.RawQuery != "" {
 		path += "?" + req.URL.RawQuery
 	}
 
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		return t.observeUpgrade(req, resp, path), nil
+	}
+
+	// Branch: gRPC uses trailers for status, needs different event shape.
+	if isGRPC {
+		return t.observeGRPC(req, resp, reqCapture, reqHeaders, latency, trace, stream, web)
+	}
+
+	respHeaders := cloneHeaders(resp.Header)
+
 	// Wrap response body to tee into a capped buffer. The event is emitted
 	// when the reverse proxy closes the body after streaming to the client.
-	respCapture := &cappedBuffer{max: maxBodyCapture}
+	respCapture := newCappedBuffer(t.bodyLimit)
 	resp.Body = &observedBody{
 		reader:  io.TeeReader(resp.Body, respCapture),
 		closer:  resp.Body,
@@ -116,6 +322,7 @@ func (t *observingTransport) RoundTrip(req *http.Request) (*http.Response, error
 					Path:                  path,
 					StatusCode:            resp.StatusCode,
 					LatencyMs:             float64(latency.Microseconds()) / 1000.0,
+					ProxyOverheadMs:       float64(overhead.Microseconds()) / 1000.0,
 					RequestSize:           reqCapture.total,
 					ResponseSize:          respCapture.total,
 					RequestHeaders:        reqHeaders,
@@ -124,6 +331,10 @@ func (t *observingTransport) RoundTrip(req *http.Request) (*http.Response, error
 					ResponseHeaders:       respHeaders,
 					ResponseBody:          respCapture.bytes(),
 					ResponseBodyTruncated: respCapture.truncated,
+					Label:                 label,
+					TraceID:               trace.TraceID,
+					SpanID:                trace.SpanID,
+					ParentSpanID:          trace.ParentSpanID,
 				},
 			})
 		},
@@ -134,24 +345,40 @@ func (t *observingTransport) RoundTrip(req *http.Request) (*http.Response, error
 
 // observeGRPC wraps the response body for a gRPC call, reading trailers on
 // close to extract grpc-status and grpc-message, then emitting a
-// grpc.call.completed event.
+// grpc.call.completed event, or grpc.stream.closed if stream found the
+// call to be streaming. For gRPC-Web (web) the trailers arrive in the
+// body as a final frame rather than as HTTP trailers.
 func (t *observingTransport) observeGRPC(
 	req *http.Request,
 	resp *http.Response,
 	reqCapture *cappedBuffer,
 	reqHeaders map[string][]string,
 	latency time.Duration,
+	trace traceContext,
+	stream *grpcStreamObserver,
+	web bool,
 ) (*http.Response, error) {
 	svc, method := parseGRPCPath(req.URL.Path)
-	respCapture := &cappedBuffer{max: maxBodyCapture}
+	respCapture := newCappedBuffer(t.bodyLimit)
+	stream.openIfKnown()
+
+	framer := stream.framer("out")
+	var webTrailer http.Header
+	if web {
+		framer.onTrailer = func(block []byte) { webTrailer = parseGRPCWebTrailer(block) }
+	}
 
 	getDecoder := t.getDecoder // capture for closure
-	resp.Body = &observedGRPCBody{
-		reader:  io.TeeReader(resp.Body, respCapture),
+	body := &observedGRPCBody{
+		reader:  io.TeeReader(resp.Body, io.MultiWriter(respCapture, framer)),
 		closer:  resp.Body,
 		resp:    resp,
 		capture: respCapture,
 		emit: func(grpcStatus, grpcMessage string, respMeta map[string][]string) {
+			if stream.isStreaming() {
+				stream.close(grpcStatus, grpcMessage, respMeta, reqCapture.total, respCapture.total)
+				return
+			}
 			info := &GRPCCallInfo{
 				Source:                t.source,
 				Target:                t.target,
@@ -169,6 +396,9 @@ func (t *observingTransport) observeGRPC(
 				RequestBodyTruncated:  reqCapture.truncated,
 				ResponseBody:          respCapture.bytes(),
 				ResponseBodyTruncated: respCapture.truncated,
+				TraceID:               trace.TraceID,
+				SpanID:                trace.SpanID,
+				ParentSpanID:          trace.ParentSpanID,
 			}
 			if getDecoder != nil {
 				if d := getDecoder(); d != nil {
@@ -182,6 +412,10 @@ func (t *observingTransport) observeGRPC(
 			})
 		},
 	}
+	if web {
+		body.webTrailer = func() http.Header { return webTrailer }
+	}
+	resp.Body = body
 
 	return resp, nil
 }
@@ -195,6 +429,10 @@ type observedGRPCBody struct {
 	capture *cappedBuffer
 	emit    func(grpcStatus, grpcMessage string, respMeta map[string][]string)
 	once    sync.Once
+
+	// webTrailer returns the trailer frame of a gRPC-Web response, or nil
+	// if none was seen. Nil for native gRPC.
+	webTrailer func() http.Header
 }
 
 func (b *observedGRPCBody) Read(p []byte) (int, error) {
@@ -206,20 +444,54 @@ func (b *observedGRPCBody) Close() error {
 	io.Copy(io.Discard, b.reader)
 	err := b.closer.Close()
 	b.once.Do(func() {
-		grpcStatus := b.resp.Trailer.Get("Grpc-Status")
-		grpcMessage := b.resp.Trailer.Get("Grpc-Message")
+		trailer := b.resp.Trailer
+		if b.webTrailer != nil {
+			trailer = b.webTrailer()
+		}
+		grpcStatus := trailer.Get("Grpc-Status")
+		grpcMessage := trailer.Get("Grpc-Message")
 		if grpcStatus == "" {
 			// Some servers send trailers in headers when there's no body.
 			grpcStatus = b.resp.Header.Get("Grpc-Status")
 			grpcMessage = b.resp.Header.Get("Grpc-Message")
 		}
 		grpcStatus = grpcStatusName(grpcStatus)
-		respMeta := cloneHeaders(b.resp.Trailer)
+		respMeta := cloneHeaders(trailer)
 		b.emit(grpcStatus, grpcMessage, respMeta)
 	})
 	return err
 }
 
+// grpcContentType reports whether ct is a gRPC call the proxy follows as
+// one: native gRPC, or binary gRPC-Web (web), which browsers send over
+// HTTP/1.1 with the same message framing. Base64 gRPC-Web
+// (application/grpc-web-text) is recorded as a plain HTTP request.
+func grpcContentType(ct string) (isGRPC, web bool) {
+	switch {
+	case strings.HasPrefix(ct, "application/grpc-web-text"):
+		return false, false
+	case strings.HasPrefix(ct, "application/grpc-web"):
+		return true, true
+	case strings.HasPrefix(ct, "application/grpc"):
+		return true, false
+	}
+	return false, false
+}
+
+// parseGRPCWebTrailer parses the block of a gRPC-Web trailer frame: HTTP/1
+// style "name: value" lines separated by CRLF.
+func parseGRPCWebTrailer(block []byte) http.Header {
+	h := http.Header{}
+	for _, line := range strings.Split(string(block), "\r\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		h.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return h
+}
+
 // parseGRPCPath splits a gRPC path like "/pkg.Service/Method" into
 // service ("pkg.Service") and method ("Method").
 func parseGRPCPath(path string) (service, method string) {
@@ -246,14 +518,27 @@ func grpcStatusName(s string) string {
 type cappedBuffer struct {
 	buf       bytes.Buffer
 	max       int
+	off       bool // capture disabled: count bytes only, never truncated
 	truncated bool
 	total     int64
 }
 
+// newCappedBuffer returns a buffer for the given Forwarder.BodyLimit.
+func newCappedBuffer(limit int) *cappedBuffer {
+	switch {
+	case limit == 0:
+		return &cappedBuffer{max: maxBodyCapture}
+	case limit < 0:
+		return &cappedBuffer{off: true}
+	default:
+		return &cappedBuffer{max: limit}
+	}
+}
+
 func (b *cappedBuffer) Write(p []byte) (int, error) {
 	n := len(p)
 	b.total += int64(n)
-	if b.truncated {
+	if b.off || b.truncated {
 		return n, nil
 	}
 	remaining := b.max - b.buf.Len()
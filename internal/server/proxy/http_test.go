@@ -0,0 +1,1103 @@
+package proxy_test
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/internal/server/proxy"
+	"github.com/matgreaves/rig/internal/spec"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestForwarderHTTP_MaxBodySize(t *testing.T) {
+	var forwarded atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var events []proxy.Event
+	f := &proxy.Forwarder{
+		ListenAddr:  ln.Addr().String(),
+		Target:      spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:      "~test",
+		TargetSvc:   "api",
+		Ingress:     "default",
+		Protocol:    "http",
+		Listener:    ln,
+		MaxBodySize: 8,
+		Emit: func(ev proxy.Event) {
+			mu.Lock()
+			events = append(events, ev)
+			mu.Unlock()
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	url := "http://" + ln.Addr().String() + "/upload"
+	tests := []struct {
+		name       string
+		body       io.Reader
+		wantStatus int
+	}{
+		{name: "within limit", body: strings.NewReader("small"), wantStatus: http.StatusOK},
+		{name: "content-length over limit", body: strings.NewReader("much too large"), wantStatus: http.StatusRequestEntityTooLarge},
+		// Wrapping hides the length so the request is sent chunked.
+		{name: "chunked over limit", body: io.MultiReader(strings.NewReader("much too large")), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(url, "text/plain", tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	if got := forwarded.Load(); got != 1 {
+		t.Errorf("upstream saw %d requests, want 1", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var injected int
+	for _, ev := range events {
+		if ev.Request == nil || !ev.Request.ProxyInjected {
+			continue
+		}
+		injected++
+		if ev.Request.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("injected event status = %d, want 413", ev.Request.StatusCode)
+		}
+		if ev.Request.Target != "api" || ev.Request.Path != "/upload" {
+			t.Errorf("injected event = %+v, want target api path /upload", ev.Request)
+		}
+	}
+	if injected != 2 {
+		t.Errorf("got %d proxy-injected events, want 2", injected)
+	}
+}
+
+// emitTraffic returns an Emit func that sends events to ch, leaving out
+// the http.connection.* events tests of individual requests don't expect.
+func emitTraffic(ch chan<- proxy.Event) func(proxy.Event) {
+	return func(ev proxy.Event) {
+		if !strings.HasPrefix(ev.Type, "http.connection.") {
+			ch <- ev
+		}
+	}
+}
+
+func TestForwarderHTTP_Label(t *testing.T) {
+	var upstreamLabel atomic.Value
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamLabel.Store(r.Header.Get(proxy.LabelHeader))
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan proxy.Event, 1)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:     "~test",
+		TargetSvc:  "api",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	req, _ := http.NewRequest("GET", "http://"+ln.Addr().String()+"/orders", nil)
+	req.Header.Set(proxy.LabelHeader, "checkout")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if got, _ := upstreamLabel.Load().(string); got != "" {
+		t.Errorf("upstream saw %s = %q, want header stripped", proxy.LabelHeader, got)
+	}
+	ev := <-events
+	if ev.Request == nil || ev.Request.Label != "checkout" {
+		t.Fatalf("event = %+v, want label checkout", ev.Request)
+	}
+	if _, ok := ev.Request.RequestHeaders[proxy.LabelHeader]; ok {
+		t.Error("recorded request headers still contain the label header")
+	}
+}
+
+// grpcWebFrame returns a gRPC-Web frame: flag byte, big-endian length,
+// payload.
+func grpcWebFrame(flag byte, payload string) []byte {
+	n := len(payload)
+	return append([]byte{flag, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}, payload...)
+}
+
+func TestForwarderHTTP_GRPCWeb(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web-text") {
+			w.Write([]byte("AAAAAAJoaQ=="))
+			return
+		}
+		w.Write(grpcWebFrame(0x00, "hi"))
+		w.Write(grpcWebFrame(0x80, "grpc-status: 5\r\ngrpc-message: no such greeting\r\n"))
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan proxy.Event, 10)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:     "web",
+		TargetSvc:  "api",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	call := func(contentType string, body []byte) {
+		t.Helper()
+		resp, err := http.Post("http://"+ln.Addr().String()+"/pkg.Greeter/SayHello", contentType, strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	call("application/grpc-web+proto", grpcWebFrame(0x00, "hello"))
+	ev := <-events
+	if ev.Type != "grpc.call.completed" || ev.GRPCCall == nil {
+		t.Fatalf("event = %s %+v, want grpc.call.completed", ev.Type, ev)
+	}
+	c := ev.GRPCCall
+	if c.Source != "web" || c.Service != "pkg.Greeter" || c.Method != "SayHello" {
+		t.Errorf("call = %s→%s %s/%s, want web→api pkg.Greeter/SayHello", c.Source, c.Target, c.Service, c.Method)
+	}
+	if c.GRPCStatus != "NotFound" || c.GRPCMessage != "no such greeting" {
+		t.Errorf("status = %q %q, want NotFound \"no such greeting\"", c.GRPCStatus, c.GRPCMessage)
+	}
+	if got := c.ResponseMetadata["Grpc-Status"]; len(got) != 1 || got[0] != "5" {
+		t.Errorf("response metadata = %v, want the trailer frame's fields", c.ResponseMetadata)
+	}
+	if c.RequestSize != 10 || c.ResponseSize != int64(7+5+len("grpc-status: 5\r\ngrpc-message: no such greeting\r\n")) {
+		t.Errorf("sizes = %d/%d, want the framed bodies", c.RequestSize, c.ResponseSize)
+	}
+
+	// Base64 gRPC-Web stays a plain HTTP request.
+	call("application/grpc-web-text", []byte("AAAAAAVoZWxsbw=="))
+	ev = <-events
+	if ev.Type != "request.completed" || ev.Request == nil {
+		t.Fatalf("event = %s, want request.completed for grpc-web-text", ev.Type)
+	}
+
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected event %s", ev.Type)
+	default:
+	}
+}
+
+func TestForwarderHTTP_GRPC(t *testing.T) {
+	upstreamLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		grpc.SetTrailer(ctx, metadata.Pairs("x-served-by", "upstream"))
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(upstream, health.NewServer())
+	go upstream.Serve(upstreamLn)
+	defer upstream.Stop()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan proxy.Event, 10)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: upstreamLn.Addr().String(), Protocol: spec.GRPC},
+		Source:     "worker",
+		TargetSvc:  "health",
+		Ingress:    "default",
+		Protocol:   "grpc",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	callCtx, callCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer callCancel()
+	if _, err := healthpb.NewHealthClient(conn).Check(callCtx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	ev := <-events
+	if ev.Type != "grpc.call.completed" || ev.GRPCCall == nil {
+		t.Fatalf("event = %s %+v, want grpc.call.completed", ev.Type, ev)
+	}
+	c := ev.GRPCCall
+	if c.Service != "grpc.health.v1.Health" || c.Method != "Check" {
+		t.Errorf("call = %s/%s, want grpc.health.v1.Health/Check", c.Service, c.Method)
+	}
+	if c.GRPCStatus != "OK" {
+		t.Errorf("grpc_status = %q, want OK", c.GRPCStatus)
+	}
+	if got := c.ResponseMetadata["X-Served-By"]; len(got) != 1 || got[0] != "upstream" {
+		t.Errorf("response metadata = %v, want the upstream's trailers", c.ResponseMetadata)
+	}
+}
+
+func TestForwarderHTTP_Trace(t *testing.T) {
+	var upstreamTrace atomic.Value
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamTrace.Store(r.Header.Get(proxy.TraceHeader))
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan proxy.Event, 1)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:     "~test",
+		TargetSvc:  "api",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	get := func(trace string) (proxy.Event, string) {
+		t.Helper()
+		req, _ := http.NewRequest("GET", "http://"+ln.Addr().String()+"/orders", nil)
+		if trace != "" {
+			req.Header.Set(proxy.TraceHeader, trace)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		got, _ := upstreamTrace.Load().(string)
+		return <-events, got
+	}
+
+	// No incoming header: a new trace, and the target sees its span.
+	first, forwarded := get("")
+	r := first.Request
+	if len(r.TraceID) != 32 || len(r.SpanID) != 16 || r.ParentSpanID != "" {
+		t.Fatalf("first hop trace = %q/%q/%q, want new trace without parent", r.TraceID, r.SpanID, r.ParentSpanID)
+	}
+	if want := r.TraceID + "-" + r.SpanID; forwarded != want {
+		t.Errorf("upstream saw %s = %q, want %q", proxy.TraceHeader, forwarded, want)
+	}
+
+	// A service forwarding the header continues the trace.
+	second, _ := get(forwarded)
+	if second.Request.TraceID != r.TraceID || second.Request.ParentSpanID != r.SpanID {
+		t.Errorf("second hop trace = %q parent %q, want %q parent %q",
+			second.Request.TraceID, second.Request.ParentSpanID, r.TraceID, r.SpanID)
+	}
+	if second.Request.SpanID == r.SpanID {
+		t.Error("second hop reused the first hop's span ID")
+	}
+
+	// A malformed header starts over.
+	third, _ := get("not-a-trace")
+	if third.Request.TraceID == r.TraceID || third.Request.ParentSpanID != "" {
+		t.Errorf("malformed header: trace %q parent %q, want a new trace", third.Request.TraceID, third.Request.ParentSpanID)
+	}
+}
+
+func TestForwarderHTTP_BodyLimit(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b)
+	}))
+	defer upstream.Close()
+
+	body := strings.Repeat("x", 100*1024)
+	tests := []struct {
+		name          string
+		limit         int
+		wantCaptured  int
+		wantTruncated bool
+	}{
+		{"default", 0, 64 * 1024, true},
+		{"raised", 200 * 1024, len(body), false},
+		{"lowered", 10, 10, true},
+		{"disabled", -1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events := make(chan proxy.Event, 1)
+			f := &proxy.Forwarder{
+				ListenAddr: ln.Addr().String(),
+				Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+				Source:     "~test",
+				TargetSvc:  "api",
+				Ingress:    "default",
+				Protocol:   "http",
+				Listener:   ln,
+				Emit:       emitTraffic(events),
+				BodyLimit:  tt.limit,
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- f.Runner().Run(ctx) }()
+			defer func() {
+				cancel()
+				<-done
+			}()
+
+			resp, err := http.Post("http://"+ln.Addr().String()+"/echo", "text/plain", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			req := (<-events).Request
+			if len(req.RequestBody) != tt.wantCaptured || len(req.ResponseBody) != tt.wantCaptured {
+				t.Errorf("captured %d/%d bytes, want %d", len(req.RequestBody), len(req.ResponseBody), tt.wantCaptured)
+			}
+			if req.RequestBodyTruncated != tt.wantTruncated || req.ResponseBodyTruncated != tt.wantTruncated {
+				t.Errorf("truncated = %v/%v, want %v", req.RequestBodyTruncated, req.ResponseBodyTruncated, tt.wantTruncated)
+			}
+			if req.RequestSize != int64(len(body)) || req.ResponseSize != int64(len(body)) {
+				t.Errorf("sizes = %d/%d, want %d", req.RequestSize, req.ResponseSize, len(body))
+			}
+		})
+	}
+}
+
+func TestForwarderHTTP_Redact(t *testing.T) {
+	var upstreamAuth atomic.Value
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamAuth.Store(r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Set-Cookie", "session=abc")
+		io.WriteString(w, `{"users":[{"name":"ann","ssn":"123"},{"name":"bob","ssn":"456"}],"count":2}`)
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan proxy.Event, 1)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:     "~test",
+		TargetSvc:  "api",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+		Redact: &proxy.Redaction{
+			Headers:   proxy.DefaultRedactHeaders,
+			JSONPaths: []string{"password", "users.ssn", "missing.field"},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	req, _ := http.NewRequest("POST", "http://"+ln.Addr().String()+"/login",
+		strings.NewReader(`{"user":"ann","password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// The services see the real traffic.
+	if got, _ := upstreamAuth.Load().(string); got != "Bearer secret" {
+		t.Errorf("upstream Authorization = %q, want it forwarded unredacted", got)
+	}
+	if !strings.Contains(string(body), `"ssn":"123"`) {
+		t.Errorf("client got %s, want the unredacted response", body)
+	}
+
+	ev := (<-events).Request
+	if got := ev.RequestHeaders["Authorization"]; len(got) != 1 || got[0] != proxy.Redacted {
+		t.Errorf("recorded Authorization = %q, want redacted", got)
+	}
+	if got := ev.ResponseHeaders["Set-Cookie"]; len(got) != 1 || got[0] != proxy.Redacted {
+		t.Errorf("recorded Set-Cookie = %q, want redacted", got)
+	}
+	if got, want := string(ev.RequestBody), `{"password":"***","user":"ann"}`; got != want {
+		t.Errorf("recorded request body = %s, want %s", got, want)
+	}
+	if got, want := string(ev.ResponseBody), `{"count":2,"users":[{"name":"ann","ssn":"***"},{"name":"bob","ssn":"***"}]}`; got != want {
+		t.Errorf("recorded response body = %s, want %s", got, want)
+	}
+}
+
+func TestForwarderHTTP_TLS(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "secure "+r.URL.Path)
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reuse the upstream's test certificate for the proxy's listen side;
+	// upstream.Client() trusts it.
+	events := make(chan proxy.Event, 1)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "https://"), Protocol: spec.HTTP},
+		Source:     "~test",
+		TargetSvc:  "api",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+		TLS:        &tls.Config{Certificates: upstream.TLS.Certificates},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	resp, err := upstream.Client().Get("https://" + ln.Addr().String() + "/orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "secure /orders" {
+		t.Errorf("body = %q, want %q", body, "secure /orders")
+	}
+
+	ev := <-events
+	if ev.Type != "request.completed" || ev.Request.Path != "/orders" || ev.Request.StatusCode != 200 {
+		t.Errorf("event = %s %+v, want request.completed GET /orders 200", ev.Type, ev.Request)
+	}
+	if string(ev.Request.ResponseBody) != "secure /orders" {
+		t.Errorf("captured response body = %q", ev.Request.ResponseBody)
+	}
+}
+
+func TestForwarderHTTP_External(t *testing.T) {
+	var gotHost, gotPath atomic.Value
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost.Store(r.Host)
+		gotPath.Store(r.URL.Path)
+		io.WriteString(w, "charged")
+	}))
+	defer upstream.Close()
+	external, _ := url.Parse(upstream.URL + "/v1")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan proxy.Event, 1)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: external.Host, Protocol: spec.HTTP},
+		Source:     "api",
+		TargetSvc:  "payments",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+		External:   external,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	resp, err := http.Post("http://"+ln.Addr().String()+"/charges", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "charged" {
+		t.Errorf("body = %q, want %q", body, "charged")
+	}
+
+	// The external service sees its own host and the URL's path prefix.
+	if got := gotHost.Load(); got != external.Host {
+		t.Errorf("upstream Host = %v, want %s", got, external.Host)
+	}
+	if got := gotPath.Load(); got != "/v1/charges" {
+		t.Errorf("upstream path = %v, want /v1/charges", got)
+	}
+
+	ev := <-events
+	if ev.Type != "request.completed" || ev.Request.Source != "api" || ev.Request.Target != "payments" || ev.Request.StatusCode != 200 {
+		t.Errorf("event = %s %+v, want request.completed api → payments 200", ev.Type, ev.Request)
+	}
+}
+
+func TestForwarderHTTP_Mock(t *testing.T) {
+	var forwarded atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded.Add(1)
+		w.Write([]byte("real"))
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan proxy.Event, 2)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:     "orders",
+		TargetSvc:  "payments",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+		// A body limit wraps the handler too; mocks must still be served.
+		MaxBodySize: 1 << 10,
+		Mocks: []proxy.Mock{{
+			Method:  "POST",
+			Path:    "/charges/*",
+			Status:  http.StatusPaymentRequired,
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    `{"error":"declined"}`,
+		}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	base := "http://" + ln.Addr().String()
+	resp, err := http.Post(base+"/charges/42", "application/json", strings.NewReader(`{"amount":5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPaymentRequired || string(body) != `{"error":"declined"}` {
+		t.Errorf("mocked response = %d %q", resp.StatusCode, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if n := forwarded.Load(); n != 0 {
+		t.Errorf("mocked request was forwarded %d times", n)
+	}
+
+	ev := <-events
+	if ev.Type != "request.mocked" || ev.Request == nil {
+		t.Fatalf("event = %+v, want request.mocked", ev)
+	}
+	if !ev.Request.ProxyInjected || ev.Request.Path != "/charges/42" || string(ev.Request.RequestBody) != `{"amount":5}` {
+		t.Errorf("mocked event request = %+v", ev.Request)
+	}
+
+	// A request the mock doesn't match is forwarded.
+	resp, err = http.Get(base + "/charges/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "real" || forwarded.Load() != 1 {
+		t.Errorf("unmatched request: body %q, forwarded %d", body, forwarded.Load())
+	}
+	if ev := <-events; ev.Type != "request.completed" {
+		t.Errorf("unmatched event type = %q, want request.completed", ev.Type)
+	}
+}
+
+func TestForwarderHTTP_WebSocket(t *testing.T) {
+	upstream := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		io.Copy(ws, ws)
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan proxy.Event, 2)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:     "~test",
+		TargetSvc:  "chat",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	ws, err := websocket.Dial("ws://"+ln.Addr().String()+"/ws?room=1", "", "http://localhost/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"hello", "again"} {
+		if err := websocket.Message.Send(ws, msg); err != nil {
+			t.Fatal(err)
+		}
+		var reply string
+		if err := websocket.Message.Receive(ws, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply != msg {
+			t.Errorf("reply = %q, want %q", reply, msg)
+		}
+	}
+
+	opened := <-events
+	if opened.Type != "websocket.opened" || opened.WebSocket == nil || opened.WebSocket.Path != "/ws?room=1" {
+		t.Fatalf("first event = %+v, want websocket.opened for /ws?room=1", opened)
+	}
+
+	ws.Close()
+	var closed proxy.Event
+	select {
+	case closed = <-events:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no websocket.closed event after close")
+	}
+	info := closed.WebSocket
+	if closed.Type != "websocket.closed" || info == nil {
+		t.Fatalf("second event = %+v, want websocket.closed", closed)
+	}
+	// Two text frames each way, plus close frames.
+	if info.FramesIn < 2 || info.FramesOut < 2 {
+		t.Errorf("frames in/out = %d/%d, want >= 2 each", info.FramesIn, info.FramesOut)
+	}
+	if info.BytesIn <= 10 || info.BytesOut <= 10 {
+		t.Errorf("bytes in/out = %d/%d, want more than the payloads", info.BytesIn, info.BytesOut)
+	}
+}
+
+func TestForwarderHTTP_ConnectionReuse(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan proxy.Event, 32)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:     "~test",
+		TargetSvc:  "api",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit: func(ev proxy.Event) {
+			if strings.HasPrefix(ev.Type, "http.connection.") {
+				events <- ev
+			}
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	nextClosed := func() *proxy.ConnectionInfo {
+		t.Helper()
+		for {
+			select {
+			case ev := <-events:
+				if ev.Type == "http.connection.closed" {
+					return ev.Connection
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("no http.connection.closed event")
+				return nil
+			}
+		}
+	}
+
+	h2c := new(http.Protocols)
+	h2c.SetUnencryptedHTTP2(true)
+	tests := []struct {
+		name      string
+		transport *http.Transport
+		requests  int
+		proto     string
+	}{
+		{"keep-alive", &http.Transport{}, 3, "HTTP/1.1"},
+		{"h2c", &http.Transport{Protocols: h2c}, 2, "h2c"},
+		{"no keep-alive", &http.Transport{DisableKeepAlives: true}, 1, "HTTP/1.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: tt.transport}
+			for range tt.requests {
+				resp, err := client.Get("http://" + ln.Addr().String() + "/orders")
+				if err != nil {
+					t.Fatal(err)
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			tt.transport.CloseIdleConnections()
+
+			info := nextClosed()
+			if info.Requests != int64(tt.requests) || info.HTTPProtocol != tt.proto {
+				t.Errorf("closed = %d requests over %q, want %d over %q",
+					info.Requests, info.HTTPProtocol, tt.requests, tt.proto)
+			}
+			if info.BytesIn == 0 || info.BytesOut == 0 || info.Target != "api" {
+				t.Errorf("closed = %+v, want bytes both ways to api", info)
+			}
+		})
+	}
+}
+
+func TestForwarderHTTP_ConnPool(t *testing.T) {
+	const concurrency = 8
+
+	// Hold each request until all of a round are in flight, so the proxy
+	// needs that many target connections at once.
+	var dials atomic.Int32
+	var mu sync.Mutex
+	arrived := 0
+	release := make(chan struct{})
+	upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrived++
+		last := arrived%concurrency == 0
+		wait := release
+		if last {
+			close(release)
+			release = make(chan struct{})
+		}
+		mu.Unlock()
+		if !last {
+			select {
+			case <-wait:
+			case <-time.After(5 * time.Second):
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, "ok")
+	}))
+	upstream.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			dials.Add(1)
+		}
+	}
+	upstream.Start()
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan proxy.Event, 4*concurrency)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:     "~test",
+		TargetSvc:  "api",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		ConnPool:   concurrency,
+		Emit:       emitTraffic(events),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: concurrency}}
+	round := func() {
+		t.Helper()
+		var wg sync.WaitGroup
+		for range concurrency {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := client.Get("http://" + ln.Addr().String() + "/orders")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}()
+		}
+		wg.Wait()
+	}
+	round()
+	// Let the proxy's transport return the connections to its pool.
+	time.Sleep(50 * time.Millisecond)
+	round()
+
+	if got := dials.Load(); got != concurrency {
+		t.Errorf("target saw %d connections over two rounds, want %d (second round reusing the first's)", got, concurrency)
+	}
+
+	for range 2 * concurrency {
+		ev := <-events
+		r := ev.Request
+		if r == nil {
+			t.Fatalf("event = %+v, want request.completed", ev)
+		}
+		if r.LatencyMs < 20 {
+			t.Errorf("latency = %.3fms, want at least the target's 20ms", r.LatencyMs)
+		}
+		if r.ProxyOverheadMs <= 0 || r.ProxyOverheadMs >= r.LatencyMs {
+			t.Errorf("proxy overhead = %.3fms, want positive and below latency %.3fms", r.ProxyOverheadMs, r.LatencyMs)
+		}
+	}
+}
+
+// seqRand returns a Fault.Rand that yields vals in turn, then repeats the
+// last one.
+func seqRand(vals ...float64) func() float64 {
+	var mu sync.Mutex
+	return func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		v := vals[0]
+		if len(vals) > 1 {
+			vals = vals[1:]
+		}
+		return v
+	}
+}
+
+func TestForwarderHTTP_Fault(t *testing.T) {
+	var forwarded atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded.Add(1)
+		w.Write([]byte("real"))
+	}))
+	defer upstream.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan proxy.Event, 8)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: strings.TrimPrefix(upstream.URL, "http://"), Protocol: spec.HTTP},
+		Source:     "orders",
+		TargetSvc:  "payments",
+		Ingress:    "default",
+		Protocol:   "http",
+		Listener:   ln,
+		Emit:       emitTraffic(events),
+		// First request forwarded, second answered with 503.
+		Fault: &proxy.Fault{Latency: 50 * time.Millisecond, ErrorRate: 0.5, Rand: seqRand(0.9, 0.1)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	get := func() (int, string, time.Duration) {
+		t.Helper()
+		start := time.Now()
+		resp, err := http.Get("http://" + ln.Addr().String() + "/charges")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp.StatusCode, string(body), time.Since(start)
+	}
+
+	if status, body, took := get(); status != http.StatusOK || body != "real" || took < 50*time.Millisecond {
+		t.Errorf("first request = %d %q in %s, want 200 \"real\" after at least 50ms", status, body, took)
+	}
+	ev := <-events
+	if ev.Type != "fault.injected" || ev.Fault.Kind != proxy.FaultLatency || ev.Fault.DelayMs != 50 {
+		t.Errorf("first event = %+v %+v, want 50ms latency fault", ev, ev.Fault)
+	}
+	if ev := <-events; ev.Type != "request.completed" || ev.Request.ProxyInjected {
+		t.Errorf("second event = %+v, want forwarded request.completed", ev)
+	}
+
+	if status, _, _ := get(); status != http.StatusServiceUnavailable {
+		t.Errorf("second request status = %d, want 503", status)
+	}
+	if n := forwarded.Load(); n != 1 {
+		t.Errorf("upstream saw %d requests, want 1", n)
+	}
+	var kinds []string
+	for range 3 {
+		ev := <-events
+		switch ev.Type {
+		case "fault.injected":
+			kinds = append(kinds, ev.Fault.Kind)
+			if ev.Fault.Source != "orders" || ev.Fault.Target != "payments" || ev.Fault.Path != "/charges" {
+				t.Errorf("fault = %+v, want orders→payments /charges", ev.Fault)
+			}
+		case "request.completed":
+			if !ev.Request.ProxyInjected || ev.Request.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("injected request = %+v, want ProxyInjected 503", ev.Request)
+			}
+		}
+	}
+	if strings.Join(kinds, ",") != "latency,error" {
+		t.Errorf("fault kinds = %v, want [latency error]", kinds)
+	}
+}
@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// CloseReasonUpgraded is the ConnectionInfo.CloseReason on
+// http.connection.closed for a connection handed off to a protocol upgrade
+// (a websocket), which is then observed as that protocol.
+const CloseReasonUpgraded = "upgraded"
+
+// httpConnKey is the context key under which an HTTP forwarder's request
+// contexts carry the *httpConn the request arrived on.
+type httpConnKey struct{}
+
+// httpConn counts the bytes and requests carried by one client connection
+// to an HTTP forwarder, for the http.connection.closed event.
+type httpConn struct {
+	net.Conn
+	start    time.Time
+	bytesIn  atomic.Int64 // client → target
+	bytesOut atomic.Int64 // target → client
+	requests atomic.Int64
+	proto    atomic.Pointer[string]
+	closed   atomic.Bool
+}
+
+func (c *httpConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.bytesIn.Add(int64(n))
+	return n, err
+}
+
+func (c *httpConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.bytesOut.Add(int64(n))
+	return n, err
+}
+
+// httpConnListener wraps each connection it accepts in an httpConn, so the
+// HTTP forwarder's server can report on it. Wrap it before any TLS
+// listener, so byte counts are what crossed the wire.
+type httpConnListener struct {
+	net.Listener
+}
+
+func (l httpConnListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &httpConn{Conn: conn, start: time.Now()}, nil
+}
+
+// asHTTPConn returns the httpConn under c, which the server may have
+// wrapped in TLS, or nil if c didn't come from an httpConnListener.
+func asHTTPConn(c net.Conn) *httpConn {
+	if tc, ok := c.(*tls.Conn); ok {
+		c = tc.NetConn()
+	}
+	hc, _ := c.(*httpConn)
+	return hc
+}
+
+// trackHTTPConns sets srv's hooks to emit http.connection.opened when a
+// client connects and http.connection.closed, with the number of requests
+// it carried and the protocol they used, when the connection closes or is
+// upgraded. It wraps srv's handler to do the counting.
+func (f *Forwarder) trackHTTPConns(srv *http.Server) {
+	next := srv.Handler
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hc, ok := r.Context().Value(httpConnKey{}).(*httpConn); ok {
+			hc.requests.Add(1)
+			proto := httpProtocol(r)
+			hc.proto.Store(&proto)
+		}
+		next.ServeHTTP(w, r)
+	})
+	srv.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+		if hc := asHTTPConn(c); hc != nil {
+			return context.WithValue(ctx, httpConnKey{}, hc)
+		}
+		return ctx
+	}
+	srv.ConnState = func(c net.Conn, state http.ConnState) {
+		hc := asHTTPConn(c)
+		if hc == nil {
+			return
+		}
+		switch state {
+		case http.StateNew:
+			f.emit(Event{
+				Type: "http.connection.opened",
+				Connection: &ConnectionInfo{
+					Source:  f.Source,
+					Target:  f.TargetSvc,
+					Ingress: f.Ingress,
+				},
+			})
+		case http.StateClosed, http.StateHijacked:
+			if !hc.closed.CompareAndSwap(false, true) {
+				return
+			}
+			info := &ConnectionInfo{
+				Source:     f.Source,
+				Target:     f.TargetSvc,
+				Ingress:    f.Ingress,
+				BytesIn:    hc.bytesIn.Load(),
+				BytesOut:   hc.bytesOut.Load(),
+				DurationMs: float64(time.Since(hc.start).Microseconds()) / 1000.0,
+				Requests:   hc.requests.Load(),
+			}
+			if p := hc.proto.Load(); p != nil {
+				info.HTTPProtocol = *p
+			}
+			if state == http.StateHijacked {
+				info.CloseReason = CloseReasonUpgraded
+			}
+			f.emit(Event{Type: "http.connection.closed", Connection: info})
+		}
+	}
+}
+
+// httpProtocol names the protocol r arrived over: "HTTP/1.1" or "HTTP/1.0",
+// "h2c" for HTTP/2 with prior knowledge, or "h2" for HTTP/2 over TLS.
+func httpProtocol(r *http.Request) string {
+	if r.ProtoMajor != 2 {
+		return r.Proto
+	}
+	if r.TLS != nil {
+		return "h2"
+	}
+	return "h2c"
+}
@@ -81,7 +81,7 @@ func (f *Forwarder) runKafka(ctx context.Context) error {
 func (f *Forwarder) handleKafkaConn(ctx context.Context, client net.Conn) {
 	start := time.Now()
 
-	f.Emit(Event{
+	f.emit(Event{
 		Type: "connection.opened",
 		Connection: &ConnectionInfo{
 			Source:  f.Source,
@@ -93,7 +93,7 @@ func (f *Forwarder) handleKafkaConn(ctx context.Context, client net.Conn) {
 	target, err := net.DialTimeout("tcp", f.Target.HostPort, 5*time.Second)
 	if err != nil {
 		client.Close()
-		f.Emit(Event{
+		f.emit(Event{
 			Type: "connection.closed",
 			Connection: &ConnectionInfo{
 				Source:     f.Source,
@@ -138,7 +138,7 @@ func (f *Forwarder) handleKafkaConn(ctx context.Context, client net.Conn) {
 		source:    f.Source,
 		target:    f.TargetSvc,
 		ingress:   f.Ingress,
-		emit:      f.Emit,
+		emit:      f.emit,
 	}
 	go func() {
 		defer wg.Done()
@@ -153,7 +153,7 @@ func (f *Forwarder) handleKafkaConn(ctx context.Context, client net.Conn) {
 	client.Close()
 	target.Close()
 
-	f.Emit(Event{
+	f.emit(Event{
 		Type: "connection.closed",
 		Connection: &ConnectionInfo{
 			Source:     f.Source,
@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+)
+
+// Mock is a canned HTTP response the proxy serves in place of the target.
+type Mock struct {
+	Method  string            // request method to match; empty matches any
+	Path    string            // path.Match pattern for the request path; empty matches any
+	Status  int               // response status; defaults to 200
+	Headers map[string]string // response headers
+	Body    string            // response body
+}
+
+// matches reports whether r is selected by m.
+func (m Mock) matches(r *http.Request) bool {
+	if m.Method != "" && m.Method != r.Method {
+		return false
+	}
+	if m.Path == "" {
+		return true
+	}
+	ok, _ := path.Match(m.Path, r.URL.Path)
+	return ok
+}
+
+// mock wraps next so that requests matching one of f.Mocks are answered
+// with the canned response instead of being forwarded. The first matching
+// mock wins; unmatched requests pass through. Mocked requests are recorded
+// as request.mocked events marked ProxyInjected.
+func (f *Forwarder) mock(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, m := range f.Mocks {
+			if m.matches(r) {
+				f.serveMock(w, r, m)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveMock writes m as the response to r and emits a request.mocked event.
+func (f *Forwarder) serveMock(w http.ResponseWriter, r *http.Request, m Mock) {
+	start := time.Now()
+	reqCapture := newCappedBuffer(f.BodyLimit)
+	if r.Body != nil {
+		io.Copy(reqCapture, r.Body)
+	}
+
+	status := m.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	for k, v := range m.Headers {
+		w.Header().Set(k, v)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(m.Body)))
+	w.WriteHeader(status)
+	io.WriteString(w, m.Body)
+
+	p := r.URL.Path
+	if r.URL.RawQuery != "" {
+		p += "?" + r.URL.RawQuery
+	}
+	respCapture := newCappedBuffer(f.BodyLimit)
+	io.WriteString(respCapture, m.Body)
+	trace := nextTrace(r.Header.Get(TraceHeader))
+	f.emit(Event{
+		Type: "request.mocked",
+		Request: &RequestInfo{
+			Source:                f.Source,
+			Target:                f.TargetSvc,
+			Ingress:               f.Ingress,
+			Method:                r.Method,
+			Path:                  p,
+			StatusCode:            status,
+			LatencyMs:             float64(time.Since(start).Microseconds()) / 1000.0,
+			RequestSize:           reqCapture.total,
+			ResponseSize:          respCapture.total,
+			RequestHeaders:        withoutHeader(r.Header, LabelHeader),
+			RequestBody:           reqCapture.bytes(),
+			RequestBodyTruncated:  reqCapture.truncated,
+			ResponseHeaders:       cloneHeaders(w.Header()),
+			ResponseBody:          respCapture.bytes(),
+			ResponseBodyTruncated: respCapture.truncated,
+			ProxyInjected:         true,
+			Label:                 r.Header.Get(LabelHeader),
+			TraceID:               trace.TraceID,
+			SpanID:                trace.SpanID,
+			ParentSpanID:          trace.ParentSpanID,
+		},
+	})
+}
@@ -0,0 +1,448 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MongoDB wire protocol constants.
+const (
+	mongoHeaderLen     = 16
+	mongoOpMsg         = 2013
+	mongoMaxMessageLen = 48 * 1000 * 1000 // maxMessageSizeBytes
+	mongoMoreToCome    = 1 << 1           // OP_MSG flag: no reply follows
+)
+
+// mongoHeader is the standard message header that starts every MongoDB
+// wire protocol message.
+type mongoHeader struct {
+	length     int32
+	requestID  int32
+	responseTo int32
+	opCode     int32
+}
+
+// mongoCommand is an in-flight command awaiting its reply.
+type mongoCommand struct {
+	name        string
+	collection  string
+	database    string
+	startTime   time.Time
+	requestSize int64
+}
+
+// mongoInFlight holds commands awaiting replies, keyed by request ID.
+// Unlike RESP, every reply names the request it answers in responseTo.
+type mongoInFlight struct {
+	mu      sync.Mutex
+	pending map[int32]mongoCommand
+}
+
+func (p *mongoInFlight) put(id int32, c mongoCommand) {
+	p.mu.Lock()
+	p.pending[id] = c
+	p.mu.Unlock()
+}
+
+func (p *mongoInFlight) take(id int32) (mongoCommand, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.pending[id]
+	delete(p.pending, id)
+	return c, ok
+}
+
+// runMongo starts a MongoDB-aware TCP proxy that emits an event per
+// command.
+func (f *Forwarder) runMongo(ctx context.Context) error {
+	ln, err := f.getListener()
+	if err != nil {
+		return fmt.Errorf("proxy %s→%s: listen: %w", f.Source, f.TargetSvc, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("proxy %s→%s: accept: %w", f.Source, f.TargetSvc, err)
+		}
+		go f.handleMongoConn(ctx, conn)
+	}
+}
+
+func (f *Forwarder) handleMongoConn(ctx context.Context, client net.Conn) {
+	start := time.Now()
+
+	f.emit(Event{
+		Type: "connection.opened",
+		Connection: &ConnectionInfo{
+			Source:  f.Source,
+			Target:  f.TargetSvc,
+			Ingress: f.Ingress,
+		},
+	})
+
+	target, err := net.DialTimeout("tcp", f.Target.HostPort, 5*time.Second)
+	if err != nil {
+		client.Close()
+		f.emit(Event{
+			Type: "connection.closed",
+			Connection: &ConnectionInfo{
+				Source:     f.Source,
+				Target:     f.TargetSvc,
+				Ingress:    f.Ingress,
+				DurationMs: float64(time.Since(start).Microseconds()) / 1000.0,
+			},
+		})
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		client.Close()
+		target.Close()
+	}()
+
+	inflight := &mongoInFlight{pending: make(map[int32]mongoCommand)}
+	emit := func(c mongoCommand, latency time.Duration, errMsg string, responseSize int64) {
+		f.emit(Event{Type: "mongo.command", MongoCommand: &MongoCommandInfo{
+			Source:       f.Source,
+			Target:       f.TargetSvc,
+			Ingress:      f.Ingress,
+			Command:      c.name,
+			Collection:   c.collection,
+			Database:     c.database,
+			Error:        errMsg,
+			LatencyMs:    float64(latency.Microseconds()) / 1000.0,
+			RequestSize:  c.requestSize,
+			ResponseSize: responseSize,
+		}})
+	}
+
+	var bytesIn, bytesOut atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// client → server: record each command until its reply arrives.
+	go func() {
+		defer wg.Done()
+		n := relayMongo(client, target, func(h mongoHeader, msg []byte) {
+			if h.opCode != mongoOpMsg {
+				return
+			}
+			flags, body, ok := parseOpMsg(msg)
+			if !ok {
+				return
+			}
+			c, ok := mongoCommandOf(body)
+			if !ok {
+				return
+			}
+			c.startTime = time.Now()
+			c.requestSize = int64(len(msg))
+			if flags&mongoMoreToCome != 0 {
+				// Unacknowledged: the server sends no reply.
+				emit(c, 0, "", 0)
+				return
+			}
+			inflight.put(h.requestID, c)
+		})
+		bytesIn.Store(n)
+		if tc, ok := target.(*net.TCPConn); ok {
+			tc.CloseWrite()
+		}
+	}()
+
+	// server → client: pair each reply with the command it answers.
+	go func() {
+		defer wg.Done()
+		n := relayMongo(target, client, func(h mongoHeader, msg []byte) {
+			c, ok := inflight.take(h.responseTo)
+			if !ok {
+				return
+			}
+			var errMsg string
+			if h.opCode == mongoOpMsg {
+				if _, body, ok := parseOpMsg(msg); ok {
+					errMsg = mongoReplyError(body)
+				}
+			}
+			emit(c, time.Since(c.startTime), errMsg, int64(len(msg)))
+		})
+		bytesOut.Store(n)
+		if tc, ok := client.(*net.TCPConn); ok {
+			tc.CloseWrite()
+		}
+	}()
+
+	wg.Wait()
+	client.Close()
+	target.Close()
+
+	f.emit(Event{
+		Type: "connection.closed",
+		Connection: &ConnectionInfo{
+			Source:     f.Source,
+			Target:     f.TargetSvc,
+			Ingress:    f.Ingress,
+			BytesIn:    bytesIn.Load(),
+			BytesOut:   bytesOut.Load(),
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000.0,
+		},
+	})
+}
+
+// relayMongo reads whole wire protocol messages from src, calls onMessage
+// with each message's header and bytes (header included), and forwards
+// them unchanged to dst. If a header is implausible, the rest of the
+// stream is copied verbatim. Returns total bytes forwarded.
+func relayMongo(src io.Reader, dst io.Writer, onMessage func(mongoHeader, []byte)) int64 {
+	br := bufio.NewReader(src)
+	var total int64
+	var msg []byte
+	for {
+		hdr, err := br.Peek(mongoHeaderLen)
+		if err != nil {
+			m, _ := io.Copy(dst, br)
+			return total + m
+		}
+		h := mongoHeader{
+			length:     int32(binary.LittleEndian.Uint32(hdr[0:])),
+			requestID:  int32(binary.LittleEndian.Uint32(hdr[4:])),
+			responseTo: int32(binary.LittleEndian.Uint32(hdr[8:])),
+			opCode:     int32(binary.LittleEndian.Uint32(hdr[12:])),
+		}
+		if h.length < mongoHeaderLen || h.length > mongoMaxMessageLen {
+			m, _ := io.Copy(dst, br)
+			return total + m
+		}
+
+		if cap(msg) < int(h.length) {
+			msg = make([]byte, h.length)
+		}
+		msg = msg[:h.length]
+		n, err := io.ReadFull(br, msg)
+		if err != nil {
+			w, _ := dst.Write(msg[:n])
+			return total + int64(w)
+		}
+
+		if onMessage != nil {
+			onMessage(h, msg)
+		}
+		w, err := dst.Write(msg)
+		total += int64(w)
+		if err != nil {
+			return total
+		}
+	}
+}
+
+// parseOpMsg returns the flag bits and the body document (the kind 0
+// section) of an OP_MSG message, header included. Document sequences
+// (kind 1 sections) are skipped.
+func parseOpMsg(msg []byte) (flags uint32, body []byte, ok bool) {
+	if len(msg) < mongoHeaderLen+4 {
+		return 0, nil, false
+	}
+	flags = binary.LittleEndian.Uint32(msg[mongoHeaderLen:])
+	sections := msg[mongoHeaderLen+4:]
+	if flags&1 != 0 && len(sections) >= 4 {
+		sections = sections[:len(sections)-4] // trailing CRC-32C checksum
+	}
+	for len(sections) > 5 {
+		kind := sections[0]
+		size := int(binary.LittleEndian.Uint32(sections[1:]))
+		if size < 5 || size > len(sections)-1 {
+			return 0, nil, false
+		}
+		if kind == 0 {
+			return flags, sections[1 : 1+size], true
+		}
+		sections = sections[1+size:]
+	}
+	return 0, nil, false
+}
+
+// mongoCommandOf reads the command a body document carries: the command
+// name is its first field, and for collection commands that field's value
+// names the collection. getMore names it in a separate "collection" field.
+// Driver handshakes and monitoring (hello, isMaster) are not reported.
+func mongoCommandOf(body []byte) (mongoCommand, bool) {
+	var c mongoCommand
+	first := true
+	ok := bsonElements(body, func(typ byte, name string, val []byte) {
+		switch {
+		case first:
+			first = false
+			c.name = name
+			if typ == bsonString {
+				c.collection = bsonStringValue(val)
+			}
+		case name == "collection" && typ == bsonString && c.collection == "":
+			c.collection = bsonStringValue(val)
+		case name == "$db" && typ == bsonString:
+			c.database = bsonStringValue(val)
+		}
+	})
+	switch c.name {
+	case "", "hello", "isMaster", "ismaster":
+		return c, false
+	}
+	return c, ok
+}
+
+// mongoReplyError returns "CodeName: errmsg" (or just the message) if the
+// reply body reports ok: 0, or "" for a successful reply.
+func mongoReplyError(body []byte) string {
+	failed := false
+	var errmsg, codeName string
+	bsonElements(body, func(typ byte, name string, val []byte) {
+		switch name {
+		case "ok":
+			switch typ {
+			case bsonDouble:
+				failed = math.Float64frombits(binary.LittleEndian.Uint64(val)) == 0
+			case bsonInt32:
+				failed = binary.LittleEndian.Uint32(val) == 0
+			case bsonInt64:
+				failed = binary.LittleEndian.Uint64(val) == 0
+			case bsonBool:
+				failed = val[0] == 0
+			}
+		case "errmsg":
+			if typ == bsonString {
+				errmsg = bsonStringValue(val)
+			}
+		case "codeName":
+			if typ == bsonString {
+				codeName = bsonStringValue(val)
+			}
+		}
+	})
+	switch {
+	case !failed:
+		return ""
+	case codeName != "" && errmsg != "":
+		return codeName + ": " + errmsg
+	case errmsg != "":
+		return errmsg
+	case codeName != "":
+		return codeName
+	}
+	return "command failed"
+}
+
+// BSON element types read by the Mongo proxy.
+const (
+	bsonDouble = 0x01
+	bsonString = 0x02
+	bsonBool   = 0x08
+	bsonInt32  = 0x10
+	bsonInt64  = 0x12
+)
+
+// bsonElements calls fn for each top-level element of doc with its type,
+// name, and raw value bytes. It reports false if doc is malformed or holds
+// an element type it doesn't know the size of, after calling fn for the
+// elements before it.
+func bsonElements(doc []byte, fn func(typ byte, name string, val []byte)) bool {
+	if len(doc) < 5 || int(binary.LittleEndian.Uint32(doc)) != len(doc) {
+		return false
+	}
+	rest := doc[4 : len(doc)-1]
+	for len(rest) > 0 {
+		typ := rest[0]
+		end := bytes.IndexByte(rest[1:], 0)
+		if end < 0 {
+			return false
+		}
+		name := string(rest[1 : 1+end])
+		rest = rest[2+end:]
+		size, ok := bsonValueSize(typ, rest)
+		if !ok || size > len(rest) {
+			return false
+		}
+		fn(typ, name, rest[:size])
+		rest = rest[size:]
+	}
+	return true
+}
+
+// bsonValueSize returns the size of a value of type typ at the start of b.
+func bsonValueSize(typ byte, b []byte) (int, bool) {
+	switch typ {
+	case 0x0A, 0x06, 0x7F, 0xFF: // null, undefined, max key, min key
+		return 0, true
+	case bsonBool:
+		return 1, true
+	case bsonInt32:
+		return 4, true
+	case bsonDouble, bsonInt64, 0x09, 0x11: // date, timestamp
+		return 8, true
+	case 0x07: // ObjectId
+		return 12, true
+	case 0x13: // decimal128
+		return 16, true
+	case 0x03, 0x04, 0x0F: // document, array, code with scope
+		if len(b) < 4 {
+			return 0, false
+		}
+		n := int(int32(binary.LittleEndian.Uint32(b)))
+		return n, n >= 5
+	case bsonString, 0x0D, 0x0E: // string, JavaScript code, symbol
+		if len(b) < 4 {
+			return 0, false
+		}
+		n := int(int32(binary.LittleEndian.Uint32(b)))
+		return 4 + n, n >= 1
+	case 0x05: // binary: length, subtype, bytes
+		if len(b) < 4 {
+			return 0, false
+		}
+		n := int(int32(binary.LittleEndian.Uint32(b)))
+		return 5 + n, n >= 0
+	case 0x0B: // regex: two C strings
+		i := bytes.IndexByte(b, 0)
+		if i < 0 {
+			return 0, false
+		}
+		j := bytes.IndexByte(b[i+1:], 0)
+		if j < 0 {
+			return 0, false
+		}
+		return i + j + 2, true
+	case 0x0C: // DBPointer: string, ObjectId
+		if len(b) < 4 {
+			return 0, false
+		}
+		n := int(int32(binary.LittleEndian.Uint32(b)))
+		return 4 + n + 12, n >= 1
+	}
+	return 0, false
+}
+
+// bsonStringValue decodes a BSON string value: a length, the bytes, and a
+// trailing NUL.
+func bsonStringValue(val []byte) string {
+	if len(val) < 5 {
+		return ""
+	}
+	return string(val[4 : len(val)-1])
+}
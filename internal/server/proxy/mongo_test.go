@@ -0,0 +1,213 @@
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+// bsonDoc encodes name/value pairs as a BSON document. Values may be
+// string, int32, int64, float64, bool, or an embedded document from bsonDoc.
+func bsonDoc(pairs ...any) []byte {
+	var body []byte
+	for i := 0; i < len(pairs); i += 2 {
+		name := pairs[i].(string)
+		var typ byte
+		var val []byte
+		switch v := pairs[i+1].(type) {
+		case string:
+			typ = bsonString
+			val = binary.LittleEndian.AppendUint32(nil, uint32(len(v)+1))
+			val = append(append(val, v...), 0)
+		case int32:
+			typ, val = bsonInt32, binary.LittleEndian.AppendUint32(nil, uint32(v))
+		case int64:
+			typ, val = bsonInt64, binary.LittleEndian.AppendUint64(nil, uint64(v))
+		case float64:
+			typ, val = bsonDouble, binary.LittleEndian.AppendUint64(nil, math.Float64bits(v))
+		case bool:
+			typ, val = bsonBool, []byte{0}
+			if v {
+				val[0] = 1
+			}
+		case []byte:
+			typ, val = 0x03, v
+		}
+		body = append(body, typ)
+		body = append(append(body, name...), 0)
+		body = append(body, val...)
+	}
+	doc := binary.LittleEndian.AppendUint32(nil, uint32(len(body)+5))
+	return append(append(doc, body...), 0)
+}
+
+// opMsg builds an OP_MSG message with a single body section.
+func opMsg(requestID, responseTo int32, flags uint32, body []byte) []byte {
+	msg := make([]byte, mongoHeaderLen, mongoHeaderLen+5+len(body))
+	binary.LittleEndian.PutUint32(msg[4:], uint32(requestID))
+	binary.LittleEndian.PutUint32(msg[8:], uint32(responseTo))
+	binary.LittleEndian.PutUint32(msg[12:], mongoOpMsg)
+	msg = binary.LittleEndian.AppendUint32(msg, flags)
+	msg = append(append(msg, 0), body...)
+	binary.LittleEndian.PutUint32(msg, uint32(len(msg)))
+	return msg
+}
+
+// readOpMsg reads one wire protocol message from r.
+func readOpMsg(r io.Reader) ([]byte, error) {
+	hdr := make([]byte, 4)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.LittleEndian.Uint32(hdr))
+	copy(msg, hdr)
+	_, err := io.ReadFull(r, msg[4:])
+	return msg, err
+}
+
+// serveFakeMongo answers each acknowledged command with { ok: 1 }, or a
+// DuplicateKey error for commands on the "dupes" collection.
+func serveFakeMongo(conn net.Conn) {
+	defer conn.Close()
+	for id := int32(1); ; id++ {
+		msg, err := readOpMsg(conn)
+		if err != nil {
+			return
+		}
+		flags, body, ok := parseOpMsg(msg)
+		if !ok || flags&mongoMoreToCome != 0 {
+			continue
+		}
+		reply := bsonDoc("ok", 1.0)
+		if c, _ := mongoCommandOf(body); c.collection == "dupes" {
+			reply = bsonDoc("ok", 0.0, "errmsg", "E11000 duplicate key error", "code", int32(11000), "codeName", "DuplicateKey")
+		}
+		requestID := int32(binary.LittleEndian.Uint32(msg[4:]))
+		if _, err := conn.Write(opMsg(id, requestID, 0, reply)); err != nil {
+			return
+		}
+	}
+}
+
+func TestForwarderMongo(t *testing.T) {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeMongo(conn)
+		}
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan Event, 32)
+	f := &Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: upstream.Addr().String(), Protocol: spec.Mongo},
+		Source:     "api",
+		TargetSvc:  "db",
+		Ingress:    "default",
+		Protocol:   "mongo",
+		Listener:   ln,
+		Emit:       func(ev Event) { events <- ev },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	roundTrip := func(id int32, body []byte) {
+		t.Helper()
+		if _, err := conn.Write(opMsg(id, 0, 0, body)); err != nil {
+			t.Fatal(err)
+		}
+		reply, err := readOpMsg(conn)
+		if err != nil {
+			t.Fatalf("reply to %d: %v", id, err)
+		}
+		if got := int32(binary.LittleEndian.Uint32(reply[8:])); got != id {
+			t.Fatalf("reply responseTo = %d, want %d", got, id)
+		}
+	}
+	roundTrip(1, bsonDoc("hello", int32(1), "$db", "admin"))
+	roundTrip(2, bsonDoc("find", "orders", "filter", bsonDoc(), "$db", "shop"))
+	roundTrip(3, bsonDoc("insert", "dupes", "$db", "shop"))
+	roundTrip(4, bsonDoc("getMore", int64(7), "collection", "orders", "$db", "shop"))
+	// Unacknowledged write: no reply, so it is reported when sent.
+	if _, err := conn.Write(opMsg(5, 0, mongoMoreToCome, bsonDoc("delete", "carts", "$db", "shop"))); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []MongoCommandInfo{
+		{Command: "find", Collection: "orders", Database: "shop"},
+		{Command: "insert", Collection: "dupes", Database: "shop", Error: "DuplicateKey: E11000 duplicate key error"},
+		{Command: "getMore", Collection: "orders", Database: "shop"},
+		{Command: "delete", Collection: "carts", Database: "shop"},
+	}
+	var got []MongoCommandInfo
+	timeout := time.After(5 * time.Second)
+	for len(got) < len(want) {
+		select {
+		case ev := <-events:
+			if ev.Type != "mongo.command" {
+				continue
+			}
+			c := ev.MongoCommand
+			if c.Source != "api" || c.Target != "db" || c.Ingress != "default" {
+				t.Errorf("event edge = %s→%s/%s, want api→db/default", c.Source, c.Target, c.Ingress)
+			}
+			if c.RequestSize == 0 {
+				t.Errorf("%s: request size is 0", c.Command)
+			}
+			got = append(got, MongoCommandInfo{Command: c.Command, Collection: c.Collection, Database: c.Database, Error: c.Error})
+		case <-timeout:
+			t.Fatalf("timed out with %d of %d mongo.command events: %+v", len(got), len(want), got)
+		}
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBSONElements_Malformed(t *testing.T) {
+	doc := bsonDoc("find", "orders")
+	for _, bad := range [][]byte{nil, doc[:len(doc)-1], append([]byte{}, doc[:4]...)} {
+		if bsonElements(bad, func(byte, string, []byte) {}) {
+			t.Errorf("bsonElements(%x) = true, want false", bad)
+		}
+	}
+	// An unknown element type stops the walk without panicking.
+	unknown := bsonDoc("find", "orders")
+	unknown[4] = 0x42
+	if bsonElements(unknown, func(byte, string, []byte) {}) {
+		t.Error("bsonElements accepted an unknown element type")
+	}
+}
@@ -0,0 +1,232 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// natsMaxControlLine matches the NATS server's default max_control_line.
+const natsMaxControlLine = 4096
+
+// runNATS starts a NATS-aware TCP proxy that emits an event per published,
+// subscribed, or delivered message.
+func (f *Forwarder) runNATS(ctx context.Context) error {
+	ln, err := f.getListener()
+	if err != nil {
+		return fmt.Errorf("proxy %s→%s: listen: %w", f.Source, f.TargetSvc, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("proxy %s→%s: accept: %w", f.Source, f.TargetSvc, err)
+		}
+		go f.handleNATSConn(ctx, conn)
+	}
+}
+
+func (f *Forwarder) handleNATSConn(ctx context.Context, client net.Conn) {
+	start := time.Now()
+
+	f.emit(Event{
+		Type: "connection.opened",
+		Connection: &ConnectionInfo{
+			Source:  f.Source,
+			Target:  f.TargetSvc,
+			Ingress: f.Ingress,
+		},
+	})
+
+	target, err := net.DialTimeout("tcp", f.Target.HostPort, 5*time.Second)
+	if err != nil {
+		client.Close()
+		f.emit(Event{
+			Type: "connection.closed",
+			Connection: &ConnectionInfo{
+				Source:     f.Source,
+				Target:     f.TargetSvc,
+				Ingress:    f.Ingress,
+				DurationMs: float64(time.Since(start).Microseconds()) / 1000.0,
+			},
+		})
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		client.Close()
+		target.Close()
+	}()
+
+	emit := func(m NATSMessageInfo) {
+		m.Source, m.Target, m.Ingress = f.Source, f.TargetSvc, f.Ingress
+		f.emit(Event{Type: "nats.message", NATSMessage: &m})
+	}
+
+	var bytesIn, bytesOut atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// client → server: PUB, HPUB, and SUB.
+	go func() {
+		defer wg.Done()
+		n := relayNATS(client, target, emit)
+		bytesIn.Store(n)
+		if tc, ok := target.(*net.TCPConn); ok {
+			tc.CloseWrite()
+		}
+	}()
+
+	// server → client: MSG and HMSG deliveries.
+	go func() {
+		defer wg.Done()
+		n := relayNATS(target, client, emit)
+		bytesOut.Store(n)
+		if tc, ok := client.(*net.TCPConn); ok {
+			tc.CloseWrite()
+		}
+	}()
+
+	wg.Wait()
+	client.Close()
+	target.Close()
+
+	f.emit(Event{
+		Type: "connection.closed",
+		Connection: &ConnectionInfo{
+			Source:     f.Source,
+			Target:     f.TargetSvc,
+			Ingress:    f.Ingress,
+			BytesIn:    bytesIn.Load(),
+			BytesOut:   bytesOut.Load(),
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000.0,
+		},
+	})
+}
+
+// relayNATS reads NATS protocol operations from src, calls emit for each
+// message operation (PUB, HPUB, SUB, MSG, HMSG), and forwards the raw
+// bytes unchanged to dst. Other operations (INFO, CONNECT, PING, +OK, ...)
+// are forwarded silently. If a control line can't be parsed, the rest of
+// the stream is copied verbatim. Returns total bytes forwarded.
+func relayNATS(src io.Reader, dst io.Writer, emit func(NATSMessageInfo)) int64 {
+	br := bufio.NewReaderSize(src, natsMaxControlLine)
+	var total int64
+	for {
+		line, err := br.ReadSlice('\n')
+		if err != nil {
+			n, _ := dst.Write(line)
+			m, _ := io.Copy(dst, br)
+			return total + int64(n) + m
+		}
+
+		msg, payload, ok := parseNATSControl(string(line))
+		if !ok {
+			n, _ := dst.Write(line)
+			m, _ := io.Copy(dst, br)
+			return total + int64(n) + m
+		}
+
+		n, err := dst.Write(line)
+		total += int64(n)
+		if err != nil {
+			return total
+		}
+		if payload >= 0 {
+			// Payload plus its trailing CRLF.
+			m, err := io.CopyN(dst, br, int64(payload)+2)
+			total += m
+			if err != nil {
+				return total
+			}
+		}
+		if msg != nil && emit != nil {
+			emit(*msg)
+		}
+	}
+}
+
+// parseNATSControl parses a NATS control line. For message operations it
+// returns the event info; payload is the number of payload bytes that
+// follow the line, or -1 if none do. ok is false if the line is malformed.
+func parseNATSControl(line string) (msg *NATSMessageInfo, payload int, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, -1, true // stray blank line
+	}
+	op := strings.ToUpper(fields[0])
+	args := fields[1:]
+	switch op {
+	case "PUB", "MSG":
+		// PUB <subject> [reply-to] <#bytes>
+		// MSG <subject> <sid> [reply-to] <#bytes>
+		nargs := 2
+		if op == "MSG" {
+			nargs = 3
+		}
+		if len(args) < nargs || len(args) > nargs+1 {
+			return nil, -1, false
+		}
+		size, err := strconv.Atoi(args[len(args)-1])
+		if err != nil || size < 0 {
+			return nil, -1, false
+		}
+		m := &NATSMessageInfo{Op: op, Subject: args[0], PayloadSize: int64(size)}
+		if len(args) == nargs+1 {
+			m.ReplyTo = args[len(args)-2]
+		}
+		return m, size, true
+	case "HPUB", "HMSG":
+		// HPUB <subject> [reply-to] <#header bytes> <#total bytes>
+		// HMSG <subject> <sid> [reply-to] <#header bytes> <#total bytes>
+		nargs := 3
+		if op == "HMSG" {
+			nargs = 4
+		}
+		if len(args) < nargs || len(args) > nargs+1 {
+			return nil, -1, false
+		}
+		hdr, err1 := strconv.Atoi(args[len(args)-2])
+		size, err2 := strconv.Atoi(args[len(args)-1])
+		if err1 != nil || err2 != nil || hdr < 0 || size < hdr {
+			return nil, -1, false
+		}
+		m := &NATSMessageInfo{
+			Op:          strings.TrimPrefix(op, "H"),
+			Subject:     args[0],
+			PayloadSize: int64(size - hdr),
+			HeaderSize:  int64(hdr),
+		}
+		if len(args) == nargs+1 {
+			m.ReplyTo = args[len(args)-3]
+		}
+		return m, size, true
+	case "SUB":
+		// SUB <subject> [queue group] <sid>
+		if len(args) < 2 || len(args) > 3 {
+			return nil, -1, false
+		}
+		m := &NATSMessageInfo{Op: op, Subject: args[0]}
+		if len(args) == 3 {
+			m.Queue = args[1]
+		}
+		return m, -1, true
+	}
+	return nil, -1, true
+}
@@ -0,0 +1,168 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+// serveFakeNATS sends INFO, then echoes every PUB on "orders.*" back as a
+// MSG to subscription 1 and answers PING with PONG.
+func serveFakeNATS(conn net.Conn) {
+	defer conn.Close()
+	io.WriteString(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1048576}\r\n")
+	br := bufio.NewReader(conn)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		switch f[0] {
+		case "PING":
+			io.WriteString(conn, "PONG\r\n")
+		case "PUB":
+			n, _ := strconv.Atoi(f[len(f)-1])
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(br, payload); err != nil {
+				return
+			}
+			io.WriteString(conn, "MSG "+f[1]+" 1 "+f[len(f)-1]+"\r\n"+string(payload))
+		}
+	}
+}
+
+func TestForwarderNATS(t *testing.T) {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeNATS(conn)
+		}
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan Event, 32)
+	f := &Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: upstream.Addr().String(), Protocol: spec.NATS},
+		Source:     "api",
+		TargetSvc:  "bus",
+		Ingress:    "default",
+		Protocol:   "nats",
+		Listener:   ln,
+		Emit:       func(ev Event) { events <- ev },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	br := bufio.NewReader(conn)
+	if info, err := br.ReadString('\n'); err != nil || !strings.HasPrefix(info, "INFO ") {
+		t.Fatalf("INFO = %q, %v", info, err)
+	}
+
+	// A payload containing CRLF and "PUB" must be relayed, not parsed.
+	req := "CONNECT {\"verbose\":false}\r\n" +
+		"SUB orders.* workers 1\r\n" +
+		"PUB orders.created _INBOX.1 12\r\nPUB x\r\nfake!\r\n" +
+		"PING\r\n"
+	if _, err := io.WriteString(conn, req); err != nil {
+		t.Fatal(err)
+	}
+	want := "MSG orders.created 1 12\r\nPUB x\r\nfake!\r\n" + "PONG\r\n"
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(br, got); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("relayed = %q, want %q", got, want)
+	}
+
+	wantEvents := []NATSMessageInfo{
+		{Op: "SUB", Subject: "orders.*", Queue: "workers"},
+		{Op: "PUB", Subject: "orders.created", ReplyTo: "_INBOX.1", PayloadSize: 12},
+		{Op: "MSG", Subject: "orders.created", PayloadSize: 12},
+	}
+	deadline := time.After(5 * time.Second)
+	for i := 0; i < len(wantEvents); {
+		select {
+		case ev := <-events:
+			if ev.Type != "nats.message" {
+				continue
+			}
+			m, w := *ev.NATSMessage, wantEvents[i]
+			if m.Source != "api" || m.Target != "bus" || m.Ingress != "default" {
+				t.Errorf("event %d edge = %s→%s/%s, want api→bus/default", i, m.Source, m.Target, m.Ingress)
+			}
+			m.Source, m.Target, m.Ingress = "", "", ""
+			if m != w {
+				t.Errorf("event %d = %+v, want %+v", i, m, w)
+			}
+			i++
+		case <-deadline:
+			t.Fatalf("timed out after %d of %d nats events", i, len(wantEvents))
+		}
+	}
+}
+
+func TestParseNATSControl(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    *NATSMessageInfo
+		payload int
+		ok      bool
+	}{
+		{"PUB a 5\r\n", &NATSMessageInfo{Op: "PUB", Subject: "a", PayloadSize: 5}, 5, true},
+		{"pub a reply 0\r\n", &NATSMessageInfo{Op: "PUB", Subject: "a", ReplyTo: "reply"}, 0, true},
+		{"HPUB a 22 30\r\n", &NATSMessageInfo{Op: "PUB", Subject: "a", PayloadSize: 8, HeaderSize: 22}, 30, true},
+		{"HMSG a 9 reply 10 12\r\n", &NATSMessageInfo{Op: "MSG", Subject: "a", ReplyTo: "reply", PayloadSize: 2, HeaderSize: 10}, 12, true},
+		{"SUB a 1\r\n", &NATSMessageInfo{Op: "SUB", Subject: "a"}, -1, true},
+		{"PING\r\n", nil, -1, true},
+		{"+OK\r\n", nil, -1, true},
+		{"PUB a x\r\n", nil, -1, false},
+		{"HPUB a 10 5\r\n", nil, -1, false},
+		{"SUB a\r\n", nil, -1, false},
+	}
+	for _, tt := range tests {
+		msg, payload, ok := parseNATSControl(tt.line)
+		if ok != tt.ok || payload != tt.payload {
+			t.Errorf("%q: payload, ok = %d, %v; want %d, %v", tt.line, payload, ok, tt.payload, tt.ok)
+			continue
+		}
+		if (msg == nil) != (tt.want == nil) || msg != nil && *msg != *tt.want {
+			t.Errorf("%q: msg = %+v, want %+v", tt.line, msg, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Redacted replaces the values of redacted headers and JSON fields.
+const Redacted = "***"
+
+// DefaultRedactHeaders are the headers redacted when the environment
+// doesn't name its own.
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Redaction masks secrets in observed traffic before it is emitted, so they
+// never reach the event log. Forwarded traffic is untouched.
+type Redaction struct {
+	// Headers are HTTP header (and gRPC metadata) names whose values are
+	// replaced. Matching is case-insensitive.
+	Headers []string
+
+	// JSONPaths are dot-separated field paths ("user.email") replaced in
+	// HTTP bodies with a JSON content type. Arrays are searched element by
+	// element, so "items.card" masks the card field of every item. A JSON
+	// body that can't be parsed, typically because capture truncated it,
+	// is dropped rather than emitted unredacted.
+	JSONPaths []string
+}
+
+// emit publishes ev to f.Emit, redacting it first when f.Redact is set.
+func (f *Forwarder) emit(ev Event) {
+	if f.Redact != nil {
+		f.Redact.apply(ev)
+	}
+	f.Emit(ev)
+}
+
+// apply redacts ev's headers and bodies in place.
+func (r *Redaction) apply(ev Event) {
+	if req := ev.Request; req != nil {
+		req.RequestHeaders = r.headers(req.RequestHeaders)
+		req.ResponseHeaders = r.headers(req.ResponseHeaders)
+		req.RequestBody = r.body(req.RequestHeaders, req.RequestBody)
+		req.ResponseBody = r.body(req.ResponseHeaders, req.ResponseBody)
+	}
+	if c := ev.GRPCCall; c != nil {
+		c.RequestMetadata = r.headers(c.RequestMetadata)
+		c.ResponseMetadata = r.headers(c.ResponseMetadata)
+	}
+	if s := ev.GRPCStream; s != nil {
+		s.RequestMetadata = r.headers(s.RequestMetadata)
+		s.ResponseMetadata = r.headers(s.ResponseMetadata)
+	}
+}
+
+// headers returns h with the values of redacted headers masked. h is
+// copied before the first change, since the same map may be shared by
+// several events.
+func (r *Redaction) headers(h map[string][]string) map[string][]string {
+	var out map[string][]string
+	for name, values := range h {
+		if !r.redactsHeader(name) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string][]string, len(h))
+			for k, v := range h {
+				out[k] = v
+			}
+		}
+		masked := make([]string, len(values))
+		for i := range masked {
+			masked[i] = Redacted
+		}
+		out[name] = masked
+	}
+	if out == nil {
+		return h
+	}
+	return out
+}
+
+func (r *Redaction) redactsHeader(name string) bool {
+	for _, h := range r.Headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// body returns a JSON body with the redacted paths masked. Bodies without
+// a JSON content type are returned unchanged.
+func (r *Redaction) body(headers map[string][]string, body []byte) []byte {
+	if len(r.JSONPaths) == 0 || len(body) == 0 || !isJSON(headers) {
+		return body
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil
+	}
+	var changed bool
+	for _, path := range r.JSONPaths {
+		if redactPath(v, strings.Split(path, ".")) {
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// redactPath masks the field at path within v, descending into arrays
+// element by element. Reports whether anything was masked.
+func redactPath(v any, path []string) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		child, ok := v[path[0]]
+		if !ok {
+			return false
+		}
+		if len(path) == 1 {
+			v[path[0]] = Redacted
+			return true
+		}
+		return redactPath(child, path[1:])
+	case []any:
+		var changed bool
+		for _, elem := range v {
+			if redactPath(elem, path) {
+				changed = true
+			}
+		}
+		return changed
+	}
+	return false
+}
+
+// isJSON reports whether headers declare a JSON content type, including
+// suffixed types such as application/problem+json.
+func isJSON(headers map[string][]string) bool {
+	ct := http.Header(headers).Get("Content-Type")
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
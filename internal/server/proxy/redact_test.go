@@ -0,0 +1,40 @@
+package proxy
+
+import "testing"
+
+func TestRedactionBody(t *testing.T) {
+	r := &Redaction{JSONPaths: []string{"token"}}
+	jsonHeaders := map[string][]string{"Content-Type": {"application/problem+json"}}
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		body    string
+		want    string
+	}{
+		{"redacted", jsonHeaders, `{"token":"abc","n":1.50}`, `{"n":1.50,"token":"***"}`},
+		{"no match kept verbatim", jsonHeaders, `{ "id": 1 }`, `{ "id": 1 }`},
+		{"truncated dropped", jsonHeaders, `{"token":"ab`, ``},
+		{"not json", map[string][]string{"Content-Type": {"text/plain"}}, `token=abc`, `token=abc`},
+		{"no content type", nil, `{"token":"abc"}`, `{"token":"abc"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(r.body(tt.headers, []byte(tt.body))); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactionHeaders_CopiesSharedMap(t *testing.T) {
+	r := &Redaction{Headers: []string{"authorization"}}
+	shared := map[string][]string{"Authorization": {"Bearer x"}, "Accept": {"*/*"}}
+
+	got := r.headers(shared)
+	if got["Authorization"][0] != Redacted || got["Accept"][0] != "*/*" {
+		t.Errorf("headers = %v, want Authorization redacted and Accept kept", got)
+	}
+	if shared["Authorization"][0] != "Bearer x" {
+		t.Error("headers modified the caller's map")
+	}
+}
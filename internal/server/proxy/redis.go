@@ -0,0 +1,463 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Redis wire protocol limits.
+const (
+	redisMaxBulkLen = 512 * 1024 * 1024 // 512 MB — matches Redis's default proto-max-bulk-len
+	redisMaxDepth   = 32                // nesting limit for aggregate replies
+	redisMaxArgLen  = 256               // decoded bytes kept per command argument
+)
+
+// redisCommand is an in-flight command awaiting its reply.
+type redisCommand struct {
+	name        string
+	key         string
+	startTime   time.Time
+	requestSize int64
+}
+
+// redisPipeline queues in-flight commands in send order. RESP has no
+// correlation IDs, so replies are matched to commands strictly FIFO.
+type redisPipeline struct {
+	mu      sync.Mutex
+	pending []redisCommand
+	stopped bool
+}
+
+func (p *redisPipeline) push(c redisCommand) {
+	p.mu.Lock()
+	if !p.stopped {
+		p.pending = append(p.pending, c)
+	}
+	p.mu.Unlock()
+}
+
+func (p *redisPipeline) pop() (redisCommand, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) == 0 {
+		return redisCommand{}, false
+	}
+	c := p.pending[0]
+	p.pending = p.pending[1:]
+	return c, true
+}
+
+// stop stops tracking new commands. Used once replies can no longer be
+// paired with commands one-to-one (pub/sub, MONITOR, unparseable traffic).
+// Commands already queued are still matched.
+func (p *redisPipeline) stop() {
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+}
+
+// runRedis starts a RESP-aware TCP proxy that emits an event per command.
+func (f *Forwarder) runRedis(ctx context.Context) error {
+	ln, err := f.getListener()
+	if err != nil {
+		return fmt.Errorf("proxy %s→%s: listen: %w", f.Source, f.TargetSvc, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("proxy %s→%s: accept: %w", f.Source, f.TargetSvc, err)
+		}
+		go f.handleRedisConn(ctx, conn)
+	}
+}
+
+func (f *Forwarder) handleRedisConn(ctx context.Context, client net.Conn) {
+	start := time.Now()
+
+	f.emit(Event{
+		Type: "connection.opened",
+		Connection: &ConnectionInfo{
+			Source:  f.Source,
+			Target:  f.TargetSvc,
+			Ingress: f.Ingress,
+		},
+	})
+
+	target, err := net.DialTimeout("tcp", f.Target.HostPort, 5*time.Second)
+	if err != nil {
+		client.Close()
+		f.emit(Event{
+			Type: "connection.closed",
+			Connection: &ConnectionInfo{
+				Source:     f.Source,
+				Target:     f.TargetSvc,
+				Ingress:    f.Ingress,
+				DurationMs: float64(time.Since(start).Microseconds()) / 1000.0,
+			},
+		})
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		client.Close()
+		target.Close()
+	}()
+
+	pipe := &redisPipeline{}
+
+	var bytesIn, bytesOut atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// client → server: parse commands and queue them for correlation.
+	go func() {
+		defer wg.Done()
+		n := relayRedisCommands(client, target, pipe)
+		bytesIn.Store(n)
+		if tc, ok := target.(*net.TCPConn); ok {
+			tc.CloseWrite()
+		}
+	}()
+
+	// server → client: pair each reply with the oldest queued command.
+	replyRelay := &redisReplyRelay{
+		pipe:    pipe,
+		source:  f.Source,
+		target:  f.TargetSvc,
+		ingress: f.Ingress,
+		emit:    f.emit,
+	}
+	go func() {
+		defer wg.Done()
+		n := replyRelay.relay(target, client)
+		bytesOut.Store(n)
+		if tc, ok := client.(*net.TCPConn); ok {
+			tc.CloseWrite()
+		}
+	}()
+
+	wg.Wait()
+	client.Close()
+	target.Close()
+
+	f.emit(Event{
+		Type: "connection.closed",
+		Connection: &ConnectionInfo{
+			Source:     f.Source,
+			Target:     f.TargetSvc,
+			Ingress:    f.Ingress,
+			BytesIn:    bytesIn.Load(),
+			BytesOut:   bytesOut.Load(),
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000.0,
+		},
+	})
+}
+
+// relayRedisCommands reads RESP commands from src, queues each on pipe,
+// and forwards the raw bytes unchanged to dst. If the stream can't be
+// parsed, tracking stops and the rest is copied verbatim. Returns total
+// bytes forwarded.
+func relayRedisCommands(src io.Reader, dst io.Writer, pipe *redisPipeline) int64 {
+	r := &respReader{br: bufio.NewReader(src)}
+	var total int64
+	for {
+		r.raw = r.raw[:0]
+		args, err := r.command()
+		if err != nil {
+			pipe.stop()
+			n, _ := dst.Write(r.raw)
+			m, _ := io.Copy(dst, r.br)
+			return total + int64(n) + m
+		}
+
+		// Redis ignores empty inline commands without replying.
+		if len(args) > 0 {
+			name, key := redisCommandKey(args)
+			pipe.push(redisCommand{
+				name:        name,
+				key:         key,
+				startTime:   time.Now(),
+				requestSize: int64(len(r.raw)),
+			})
+			switch name {
+			case "SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE", "MONITOR":
+				pipe.stop()
+			}
+		}
+
+		if _, err := dst.Write(r.raw); err != nil {
+			return total
+		}
+		total += int64(len(r.raw))
+	}
+}
+
+// redisReplyRelay holds the configuration for relaying RESP replies from
+// a Redis server back to a client.
+type redisReplyRelay struct {
+	pipe    *redisPipeline
+	source  string // for event emission
+	target  string
+	ingress string
+	emit    func(Event) // nil to skip event emission
+}
+
+// relay reads RESP replies from src, emits a redis.command.completed event
+// for each one that answers a queued command, and forwards everything to
+// dst unchanged. RESP3 push messages are forwarded without consuming a
+// command. Returns total bytes forwarded.
+func (k *redisReplyRelay) relay(src io.Reader, dst io.Writer) int64 {
+	r := &respReader{br: bufio.NewReader(src)}
+	var total int64
+	for {
+		r.raw = r.raw[:0]
+		v, err := r.value(0, false)
+		if err != nil {
+			n, _ := dst.Write(r.raw)
+			m, _ := io.Copy(dst, r.br)
+			return total + int64(n) + m
+		}
+
+		if v.typ != '>' {
+			if cmd, ok := k.pipe.pop(); ok && k.emit != nil {
+				info := &RedisCommandInfo{
+					Source:       k.source,
+					Target:       k.target,
+					Ingress:      k.ingress,
+					Command:      cmd.name,
+					Key:          cmd.key,
+					ReplyType:    v.replyType(),
+					LatencyMs:    float64(time.Since(cmd.startTime).Microseconds()) / 1000.0,
+					RequestSize:  cmd.requestSize,
+					ResponseSize: int64(len(r.raw)),
+				}
+				if v.typ == '-' || v.typ == '!' {
+					info.Error = v.str
+				}
+				k.emit(Event{Type: "redis.command.completed", RedisCommand: info})
+			}
+		}
+
+		if _, err := dst.Write(r.raw); err != nil {
+			return total
+		}
+		total += int64(len(r.raw))
+	}
+}
+
+// redisCommandKey returns the upper-cased command name and its first key
+// argument. Commands that take no key (or whose first argument is a
+// pattern, channel, or cursor) report an empty key.
+func redisCommandKey(args []string) (name, key string) {
+	name = strings.ToUpper(args[0])
+	switch name {
+	case "PING", "ECHO", "AUTH", "HELLO", "SELECT", "QUIT", "RESET", "INFO",
+		"CONFIG", "CLIENT", "COMMAND", "CLUSTER", "ACL", "DEBUG", "LATENCY", "SLOWLOG",
+		"DBSIZE", "FLUSHDB", "FLUSHALL", "SWAPDB", "RANDOMKEY", "KEYS", "SCAN", "TIME",
+		"SAVE", "BGSAVE", "LASTSAVE", "WAIT", "READONLY", "READWRITE",
+		"MULTI", "EXEC", "DISCARD", "UNWATCH", "SCRIPT", "FUNCTION", "MONITOR",
+		"SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE", "UNSUBSCRIBE", "PUNSUBSCRIBE",
+		"SUNSUBSCRIBE", "PUBLISH", "SPUBLISH", "PUBSUB":
+		return name, ""
+	case "EVAL", "EVALSHA", "EVAL_RO", "EVALSHA_RO", "FCALL", "FCALL_RO":
+		// EVAL script numkeys key [key ...] arg [arg ...]
+		if len(args) > 3 && args[2] != "0" {
+			key = args[3]
+		}
+		return name, key
+	case "XREAD", "XREADGROUP":
+		for i, a := range args {
+			if strings.EqualFold(a, "STREAMS") && i+1 < len(args) {
+				return name, args[i+1]
+			}
+		}
+		return name, ""
+	}
+	if len(args) > 1 {
+		key = args[1]
+	}
+	return name, key
+}
+
+// respValue is a parsed RESP value. Aggregate elements and bulk payloads
+// are only decoded when requested; raw bytes live in the respReader.
+type respValue struct {
+	typ   byte
+	null  bool
+	str   string // simple string, error text, or (possibly truncated) bulk payload
+	elems []respValue
+}
+
+// replyType names v's RESP type for events.
+func (v respValue) replyType() string {
+	if v.null {
+		return "null"
+	}
+	switch v.typ {
+	case '+':
+		return "string"
+	case '-', '!':
+		return "error"
+	case ':':
+		return "integer"
+	case '$':
+		return "bulk"
+	case '*':
+		return "array"
+	case '_':
+		return "null"
+	case ',':
+		return "double"
+	case '#':
+		return "boolean"
+	case '(':
+		return "bignum"
+	case '=':
+		return "verbatim"
+	case '%':
+		return "map"
+	case '~':
+		return "set"
+	case '>':
+		return "push"
+	}
+	return string(v.typ)
+}
+
+// respReader reads RESP2/RESP3 values, recording the raw bytes consumed
+// so they can be forwarded unchanged.
+type respReader struct {
+	br  *bufio.Reader
+	raw []byte
+}
+
+// line reads a CRLF-terminated line and returns it without the terminator.
+func (r *respReader) line() (string, error) {
+	b, err := r.br.ReadBytes('\n')
+	r.raw = append(r.raw, b...)
+	if err != nil {
+		if len(b) > 0 && err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r"), nil
+}
+
+// command reads one client command, either a RESP array of bulk strings
+// or an inline command, and returns its arguments.
+func (r *respReader) command() ([]string, error) {
+	b, err := r.br.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if b[0] != '*' {
+		line, err := r.line()
+		if err != nil {
+			return nil, err
+		}
+		return strings.Fields(line), nil
+	}
+	v, err := r.value(0, true)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(v.elems))
+	for _, e := range v.elems {
+		args = append(args, e.str)
+	}
+	return args, nil
+}
+
+// value reads one complete RESP value. When keep is set, aggregate
+// elements and bulk payloads (up to redisMaxArgLen bytes) are decoded;
+// otherwise only the type and any error text are.
+func (r *respReader) value(depth int, keep bool) (respValue, error) {
+	if depth > redisMaxDepth {
+		return respValue{}, errors.New("redis: reply nested too deeply")
+	}
+	line, err := r.line()
+	if err != nil {
+		return respValue{}, err
+	}
+	if line == "" {
+		return respValue{}, errors.New("redis: empty line")
+	}
+
+	v := respValue{typ: line[0]}
+	body := line[1:]
+	switch v.typ {
+	case '+', '-', ':', ',', '#', '(':
+		v.str = body
+	case '_':
+		v.null = true
+	case '$', '!', '=':
+		n, err := strconv.Atoi(body)
+		if err != nil || n > redisMaxBulkLen {
+			return respValue{}, fmt.Errorf("redis: invalid bulk length %q", body)
+		}
+		if n < 0 {
+			v.null = true
+			return v, nil
+		}
+		start := len(r.raw)
+		r.raw = append(r.raw, make([]byte, n+2)...)
+		if _, err := io.ReadFull(r.br, r.raw[start:]); err != nil {
+			return respValue{}, err
+		}
+		payload := r.raw[start : start+n]
+		switch {
+		case v.typ == '!':
+			v.str = string(payload)
+		case keep:
+			v.str = string(payload[:min(n, redisMaxArgLen)])
+		}
+	case '*', '%', '~', '>', '|':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return respValue{}, fmt.Errorf("redis: invalid aggregate length %q", body)
+		}
+		if n < 0 {
+			v.null = true
+			return v, nil
+		}
+		if v.typ == '%' || v.typ == '|' {
+			n *= 2
+		}
+		for range n {
+			e, err := r.value(depth+1, keep)
+			if err != nil {
+				return respValue{}, err
+			}
+			if keep {
+				v.elems = append(v.elems, e)
+			}
+		}
+		if v.typ == '|' {
+			// Attributes annotate the value that follows them.
+			return r.value(depth, keep)
+		}
+	default:
+		return respValue{}, fmt.Errorf("redis: unknown type byte %q", v.typ)
+	}
+	return v, nil
+}
@@ -0,0 +1,227 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+// serveFakeRedis answers a handful of commands with canned replies.
+func serveFakeRedis(conn net.Conn) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		var args []string
+		if strings.HasPrefix(line, "*") {
+			n, _ := strconv.Atoi(line[1:])
+			for range n {
+				hdr, err := br.ReadString('\n')
+				if err != nil {
+					return
+				}
+				size, _ := strconv.Atoi(strings.TrimRight(hdr, "\r\n")[1:])
+				buf := make([]byte, size+2)
+				if _, err := io.ReadFull(br, buf); err != nil {
+					return
+				}
+				args = append(args, string(buf[:size]))
+			}
+		} else {
+			args = strings.Fields(line)
+		}
+
+		var reply string
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			reply = "+PONG\r\n"
+		case "SET":
+			reply = "+OK\r\n"
+		case "GET":
+			if args[1] == "foo" {
+				reply = "$3\r\nbar\r\n"
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "INCR":
+			reply = ":1\r\n"
+		case "LRANGE":
+			reply = "*2\r\n$1\r\na\r\n$1\r\nb\r\n"
+		default:
+			reply = "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func TestForwarderRedis(t *testing.T) {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeRedis(conn)
+		}
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan Event, 32)
+	f := &Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: upstream.Addr().String(), Protocol: spec.Redis},
+		Source:     "api",
+		TargetSvc:  "cache",
+		Ingress:    "default",
+		Protocol:   "redis",
+		Listener:   ln,
+		Emit:       func(ev Event) { events <- ev },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Pipeline every command in a single write, including an inline one.
+	req := "*3\r\n$3\r\nSET\r\n$3\r\nfoo\r\n$3\r\nbar\r\n" +
+		"*2\r\n$3\r\nget\r\n$3\r\nfoo\r\n" +
+		"*2\r\n$3\r\nGET\r\n$4\r\nnope\r\n" +
+		"*2\r\n$4\r\nINCR\r\n$1\r\nn\r\n" +
+		"*4\r\n$6\r\nLRANGE\r\n$1\r\nl\r\n$1\r\n0\r\n$2\r\n-1\r\n" +
+		"*2\r\n$5\r\nBOGUS\r\n$1\r\nx\r\n" +
+		"PING\r\n"
+	if _, err := io.WriteString(conn, req); err != nil {
+		t.Fatal(err)
+	}
+	want := "+OK\r\n" + "$3\r\nbar\r\n" + "$-1\r\n" + ":1\r\n" +
+		"*2\r\n$1\r\na\r\n$1\r\nb\r\n" + "-ERR unknown command 'BOGUS'\r\n" + "+PONG\r\n"
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(conn, got); err != nil {
+		t.Fatalf("read replies: %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("replies = %q, want %q", got, want)
+	}
+
+	wantEvents := []RedisCommandInfo{
+		{Command: "SET", Key: "foo", ReplyType: "string"},
+		{Command: "GET", Key: "foo", ReplyType: "bulk"},
+		{Command: "GET", Key: "nope", ReplyType: "null"},
+		{Command: "INCR", Key: "n", ReplyType: "integer"},
+		{Command: "LRANGE", Key: "l", ReplyType: "array"},
+		{Command: "BOGUS", Key: "x", ReplyType: "error", Error: "ERR unknown command 'BOGUS'"},
+		{Command: "PING", ReplyType: "string"},
+	}
+	deadline := time.After(5 * time.Second)
+	for i := 0; i < len(wantEvents); {
+		select {
+		case ev := <-events:
+			if ev.Type != "redis.command.completed" {
+				continue
+			}
+			c, w := ev.RedisCommand, wantEvents[i]
+			if c.Command != w.Command || c.Key != w.Key || c.ReplyType != w.ReplyType || c.Error != w.Error {
+				t.Errorf("event %d = %s %q %s %q, want %s %q %s %q",
+					i, c.Command, c.Key, c.ReplyType, c.Error, w.Command, w.Key, w.ReplyType, w.Error)
+			}
+			if c.Source != "api" || c.Target != "cache" {
+				t.Errorf("event %d edge = %s→%s, want api→cache", i, c.Source, c.Target)
+			}
+			if c.RequestSize == 0 || c.ResponseSize == 0 {
+				t.Errorf("event %d sizes = %d/%d, want non-zero", i, c.RequestSize, c.ResponseSize)
+			}
+			i++
+		case <-deadline:
+			t.Fatalf("timed out after %d of %d redis events", i, len(wantEvents))
+		}
+	}
+}
+
+func TestRespReaderValue(t *testing.T) {
+	tests := []struct {
+		name, in, typ, err string
+	}{
+		{"attribute precedes reply", "|1\r\n+ttl\r\n:3\r\n:42\r\n", "integer", ""},
+		{"resp3 map", "%1\r\n+k\r\n*1\r\n:1\r\n", "map", ""},
+		{"bulk error", "!9\r\nERR boom!\r\n", "error", "ERR boom!"},
+		{"null array", "*-1\r\n", "null", ""},
+		{"resp3 null", "_\r\n", "null", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &respReader{br: bufio.NewReader(strings.NewReader(tt.in))}
+			v, err := r.value(0, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.replyType() != tt.typ {
+				t.Errorf("reply type = %q, want %q", v.replyType(), tt.typ)
+			}
+			if tt.err != "" && v.str != tt.err {
+				t.Errorf("error = %q, want %q", v.str, tt.err)
+			}
+			if string(r.raw) != tt.in {
+				t.Errorf("raw = %q, want %q", r.raw, tt.in)
+			}
+		})
+	}
+
+	r := &respReader{br: bufio.NewReader(strings.NewReader("$5\r\nab"))}
+	if _, err := r.value(0, false); err == nil {
+		t.Error("expected error for truncated bulk string")
+	}
+}
+
+func TestRedisCommandKey(t *testing.T) {
+	tests := []struct {
+		args      []string
+		name, key string
+	}{
+		{[]string{"get", "user:1"}, "GET", "user:1"},
+		{[]string{"HSET", "h", "f", "v"}, "HSET", "h"},
+		{[]string{"PING"}, "PING", ""},
+		{[]string{"SELECT", "3"}, "SELECT", ""},
+		{[]string{"KEYS", "user:*"}, "KEYS", ""},
+		{[]string{"EVAL", "return 1", "1", "k1", "a"}, "EVAL", "k1"},
+		{[]string{"EVAL", "return 1", "0"}, "EVAL", ""},
+		{[]string{"XREAD", "COUNT", "2", "STREAMS", "s1", "0"}, "XREAD", "s1"},
+	}
+	for _, tt := range tests {
+		name, key := redisCommandKey(tt.args)
+		if name != tt.name || key != tt.key {
+			t.Errorf("redisCommandKey(%q) = %q, %q; want %q, %q", tt.args, name, key, tt.name, tt.key)
+		}
+	}
+}
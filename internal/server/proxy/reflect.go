@@ -7,6 +7,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"os"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -20,14 +21,16 @@ import (
 )
 
 // GRPCDecoder decodes gRPC request/response bodies into JSON using
-// descriptors obtained via server reflection.
+// descriptors obtained via server reflection or loaded from a descriptor
+// set.
 type GRPCDecoder struct {
 	methods map[string]methodDesc // key: "pkg.Service/Method"
 }
 
 type methodDesc struct {
-	input  protoreflect.MessageDescriptor
-	output protoreflect.MessageDescriptor
+	input     protoreflect.MessageDescriptor
+	output    protoreflect.MessageDescriptor
+	streaming bool // client, server or bidi streaming
 }
 
 // ProbeReflection dials the target gRPC server and attempts to fetch service
@@ -78,14 +81,39 @@ func ProbeReflection(ctx context.Context, addr string) *GRPCDecoder {
 		return nil
 	}
 
-	// Build a FileDescriptorSet and resolve into a registry.
-	fds := &descriptorpb.FileDescriptorSet{File: allFiles}
-	resolved, err := protodesc.NewFiles(fds)
+	dec, err := newGRPCDecoder(&descriptorpb.FileDescriptorSet{File: allFiles})
 	if err != nil {
 		return nil
 	}
+	return dec
+}
+
+// LoadDescriptorSet builds a decoder from a binary FileDescriptorSet file,
+// as written by `protoc --include_imports --descriptor_set_out`. It lets
+// bodies be decoded for targets that don't serve reflection.
+func LoadDescriptorSet(path string) (*GRPCDecoder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &fds); err != nil {
+		return nil, fmt.Errorf("parse descriptor set %s: %w", path, err)
+	}
+	dec, err := newGRPCDecoder(&fds)
+	if err != nil {
+		return nil, fmt.Errorf("descriptor set %s: %w", path, err)
+	}
+	return dec, nil
+}
+
+// newGRPCDecoder resolves fds and indexes every method it declares.
+func newGRPCDecoder(fds *descriptorpb.FileDescriptorSet) (*GRPCDecoder, error) {
+	resolved, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, err
+	}
 
-	// Build method map.
 	methods := make(map[string]methodDesc)
 	resolved.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
 		for i := 0; i < fd.Services().Len(); i++ {
@@ -94,8 +122,9 @@ func ProbeReflection(ctx context.Context, addr string) *GRPCDecoder {
 				md := sd.Methods().Get(j)
 				key := fmt.Sprintf("%s/%s", sd.FullName(), md.Name())
 				methods[key] = methodDesc{
-					input:  md.Input(),
-					output: md.Output(),
+					input:     md.Input(),
+					output:    md.Output(),
+					streaming: md.IsStreamingClient() || md.IsStreamingServer(),
 				}
 			}
 		}
@@ -103,10 +132,10 @@ func ProbeReflection(ctx context.Context, addr string) *GRPCDecoder {
 	})
 
 	if len(methods) == 0 {
-		return nil
+		return nil, fmt.Errorf("no services")
 	}
 
-	return &GRPCDecoder{methods: methods}
+	return &GRPCDecoder{methods: methods}, nil
 }
 
 // fetchFileDescriptors fetches the file descriptor for a service (by symbol)
@@ -175,6 +204,13 @@ func fetchDescriptors(
 	return result, nil
 }
 
+// Streaming reports whether the method streams in either direction. ok is
+// false when the decoder has no descriptor for it.
+func (d *GRPCDecoder) Streaming(svc, method string) (streaming, ok bool) {
+	md, ok := d.methods[svc+"/"+method]
+	return md.streaming, ok
+}
+
 // Decode decodes a gRPC framed body (length-prefixed protobuf) into JSON.
 // svc is "pkg.Service", method is "Method". isRequest selects which descriptor
 // (input or output) to use. Returns "" on any failure.
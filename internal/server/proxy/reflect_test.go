@@ -2,9 +2,12 @@ package proxy
 
 import (
 	"encoding/binary"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -208,3 +211,43 @@ func makeFrame(payload []byte) []byte {
 	copy(frame[5:], payload)
 	return frame
 }
+
+func TestLoadDescriptorSet(t *testing.T) {
+	fds := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+	}}
+	data, err := proto.Marshal(fds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "health.pb")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	decoder, err := LoadDescriptorSet(path)
+	if err != nil {
+		t.Fatalf("LoadDescriptorSet: %v", err)
+	}
+
+	req, err := proto.Marshal(&healthpb.HealthCheckRequest{Service: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decoder.Decode("grpc.health.v1.Health", "Check", makeFrame(req), true)
+	if got != `{"service":"orders"}` {
+		t.Errorf("Decode = %q, want {\"service\":\"orders\"}", got)
+	}
+	if got := decoder.Decode("grpc.health.v1.Health", "Unknown", makeFrame(req), true); got != "" {
+		t.Errorf("expected empty string for unknown method, got %q", got)
+	}
+
+	garbage := filepath.Join(t.TempDir(), "garbage.pb")
+	os.WriteFile(garbage, []byte("not a descriptor set"), 0o644)
+	if _, err := LoadDescriptorSet(garbage); err == nil {
+		t.Error("expected error for invalid descriptor set")
+	}
+	if _, err := LoadDescriptorSet(filepath.Join(t.TempDir(), "missing.pb")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -37,7 +37,7 @@ func (f *Forwarder) runTCP(ctx context.Context) error {
 func (f *Forwarder) handleTCPConn(ctx context.Context, client net.Conn) {
 	start := time.Now()
 
-	f.Emit(Event{
+	f.emit(Event{
 		Type: "connection.opened",
 		Connection: &ConnectionInfo{
 			Source:  f.Source,
@@ -49,7 +49,7 @@ func (f *Forwarder) handleTCPConn(ctx context.Context, client net.Conn) {
 	target, err := net.DialTimeout("tcp", f.Target.HostPort, 5*time.Second)
 	if err != nil {
 		client.Close()
-		f.Emit(Event{
+		f.emit(Event{
 			Type: "connection.closed",
 			Connection: &ConnectionInfo{
 				Source:     f.Source,
@@ -68,6 +68,22 @@ func (f *Forwarder) handleTCPConn(ctx context.Context, client net.Conn) {
 		target.Close()
 	}()
 
+	var idle *idleTracker
+	done := make(chan struct{})
+	if f.IdleTimeout > 0 {
+		idle = newIdleTracker()
+		go idle.watch(f.IdleTimeout, done, func() {
+			client.Close()
+			target.Close()
+		})
+	}
+
+	var previewIn, previewOut *cappedBuffer
+	if f.CapturePreview > 0 {
+		previewIn = &cappedBuffer{max: f.CapturePreview}
+		previewOut = &cappedBuffer{max: f.CapturePreview}
+	}
+
 	var bytesIn, bytesOut atomic.Int64
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -75,7 +91,7 @@ func (f *Forwarder) handleTCPConn(ctx context.Context, client net.Conn) {
 	// client → target
 	go func() {
 		defer wg.Done()
-		n, _ := io.Copy(target, client)
+		n := relay(target, client, idle, previewIn)
 		bytesIn.Store(n)
 		if tc, ok := target.(*net.TCPConn); ok {
 			tc.CloseWrite()
@@ -85,7 +101,7 @@ func (f *Forwarder) handleTCPConn(ctx context.Context, client net.Conn) {
 	// target → client
 	go func() {
 		defer wg.Done()
-		n, _ := io.Copy(client, target)
+		n := relay(client, target, idle, previewOut)
 		bytesOut.Store(n)
 		if tc, ok := client.(*net.TCPConn); ok {
 			tc.CloseWrite()
@@ -93,18 +109,105 @@ func (f *Forwarder) handleTCPConn(ctx context.Context, client net.Conn) {
 	}()
 
 	wg.Wait()
+	close(done)
 	client.Close()
 	target.Close()
 
-	f.Emit(Event{
-		Type: "connection.closed",
-		Connection: &ConnectionInfo{
-			Source:     f.Source,
-			Target:     f.TargetSvc,
-			Ingress:    f.Ingress,
-			BytesIn:    bytesIn.Load(),
-			BytesOut:   bytesOut.Load(),
-			DurationMs: float64(time.Since(start).Microseconds()) / 1000.0,
-		},
-	})
+	var reason string
+	if idle != nil && idle.expired.Load() {
+		reason = CloseReasonIdle
+	}
+	info := &ConnectionInfo{
+		Source:      f.Source,
+		Target:      f.TargetSvc,
+		Ingress:     f.Ingress,
+		BytesIn:     bytesIn.Load(),
+		BytesOut:    bytesOut.Load(),
+		DurationMs:  float64(time.Since(start).Microseconds()) / 1000.0,
+		CloseReason: reason,
+	}
+	if previewIn != nil {
+		info.PreviewIn, info.PreviewInTruncated = previewIn.bytes(), previewIn.truncated
+		info.PreviewOut, info.PreviewOutTruncated = previewOut.bytes(), previewOut.truncated
+	}
+	f.emit(Event{Type: "connection.closed", Connection: info})
+}
+
+// CloseReasonIdle is the ConnectionInfo.CloseReason for connections closed
+// by the proxy after Forwarder.IdleTimeout without traffic.
+const CloseReasonIdle = "idle_timeout"
+
+// idleTracker records the last time data moved in either direction of a
+// relayed connection, and how many writes are in flight.
+type idleTracker struct {
+	last     atomic.Int64 // unix nanos
+	inflight atomic.Int32
+	expired  atomic.Bool
+}
+
+func newIdleTracker() *idleTracker {
+	t := &idleTracker{}
+	t.touch()
+	return t
+}
+
+func (t *idleTracker) touch() { t.last.Store(time.Now().UnixNano()) }
+
+// watch calls closeFn once the connection has been idle for timeout with no
+// write in flight, or returns when done is closed.
+func (t *idleTracker) watch(timeout time.Duration, done <-chan struct{}, closeFn func()) {
+	tick := time.NewTicker(max(timeout/4, 10*time.Millisecond))
+	defer tick.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-tick.C:
+			if t.inflight.Load() > 0 {
+				continue
+			}
+			if time.Since(time.Unix(0, t.last.Load())) >= timeout {
+				t.expired.Store(true)
+				closeFn()
+				return
+			}
+		}
+	}
+}
+
+// relay copies src to dst until either side fails, returning the number of
+// bytes written. When idle is set, every chunk marks the connection active
+// and is counted as in flight until its write completes. When preview is
+// set, the bytes read are also written to it.
+func relay(dst, src net.Conn, idle *idleTracker, preview *cappedBuffer) int64 {
+	if idle == nil {
+		if preview != nil {
+			n, _ := io.Copy(dst, io.TeeReader(src, preview))
+			return n
+		}
+		n, _ := io.Copy(dst, src)
+		return n
+	}
+	buf := make([]byte, 32*1024)
+	var n int64
+	for {
+		nr, rerr := src.Read(buf)
+		if nr > 0 {
+			if preview != nil {
+				preview.Write(buf[:nr])
+			}
+			idle.inflight.Add(1)
+			idle.touch()
+			nw, werr := dst.Write(buf[:nr])
+			idle.touch()
+			idle.inflight.Add(-1)
+			n += int64(nw)
+			if werr != nil {
+				return n
+			}
+		}
+		if rerr != nil {
+			return n
+		}
+	}
 }
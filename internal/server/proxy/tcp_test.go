@@ -0,0 +1,288 @@
+package proxy_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/internal/server/proxy"
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+func TestForwarderTCP_IdleTimeout(t *testing.T) {
+	// Echo server.
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan proxy.Event, 16)
+	f := &proxy.Forwarder{
+		ListenAddr:  ln.Addr().String(),
+		Target:      spec.Endpoint{HostPort: upstream.Addr().String(), Protocol: spec.TCP},
+		Source:      "api",
+		TargetSvc:   "db",
+		Ingress:     "default",
+		Protocol:    "tcp",
+		Listener:    ln,
+		IdleTimeout: 200 * time.Millisecond,
+		Emit:        func(ev proxy.Event) { events <- ev },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Traffic more often than the timeout keeps the connection open.
+	buf := make([]byte, 4)
+	for range 5 {
+		if _, err := conn.Write([]byte("ping")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			t.Fatalf("connection closed while active: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Once idle, the proxy closes it.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(buf); err == nil {
+		t.Fatal("expected connection to be closed after idle timeout")
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type != "connection.closed" {
+				continue
+			}
+			if ev.Connection.CloseReason != proxy.CloseReasonIdle {
+				t.Errorf("close reason = %q, want %q", ev.Connection.CloseReason, proxy.CloseReasonIdle)
+			}
+			if ev.Connection.BytesIn != 20 || ev.Connection.BytesOut != 20 {
+				t.Errorf("bytes in/out = %d/%d, want 20/20", ev.Connection.BytesIn, ev.Connection.BytesOut)
+			}
+			return
+		case <-deadline:
+			t.Fatal("timed out waiting for connection.closed")
+		}
+	}
+}
+
+func TestForwarderTCP_CapturePreview(t *testing.T) {
+	// Server that replies "ok" to whatever it's sent.
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(io.Discard, conn)
+				conn.Write([]byte("ok"))
+			}()
+		}
+	}()
+
+	for _, tt := range []struct {
+		name    string
+		preview int
+	}{
+		{"Disabled", 0},
+		{"Enabled", 8},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			events := make(chan proxy.Event, 16)
+			f := &proxy.Forwarder{
+				ListenAddr:     ln.Addr().String(),
+				Target:         spec.Endpoint{HostPort: upstream.Addr().String(), Protocol: spec.TCP},
+				Source:         "api",
+				TargetSvc:      "db",
+				Ingress:        "default",
+				Protocol:       "tcp",
+				Listener:       ln,
+				CapturePreview: tt.preview,
+				Emit:           func(ev proxy.Event) { events <- ev },
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- f.Runner().Run(ctx) }()
+			defer func() {
+				cancel()
+				<-done
+			}()
+
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Write([]byte("\x00\x01hello world"))
+			conn.(*net.TCPConn).CloseWrite()
+			if reply, _ := io.ReadAll(conn); string(reply) != "ok" {
+				t.Fatalf("reply = %q, want ok", reply)
+			}
+			conn.Close()
+
+			var c *proxy.ConnectionInfo
+			for c == nil {
+				select {
+				case ev := <-events:
+					if ev.Type == "connection.closed" {
+						c = ev.Connection
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for connection.closed")
+				}
+			}
+
+			if tt.preview == 0 {
+				if c.PreviewIn != nil || c.PreviewOut != nil {
+					t.Errorf("preview captured with CapturePreview 0: %q / %q", c.PreviewIn, c.PreviewOut)
+				}
+				return
+			}
+			if string(c.PreviewIn) != "\x00\x01hello " || !c.PreviewInTruncated {
+				t.Errorf("preview in = %q truncated=%v, want first 8 bytes, truncated", c.PreviewIn, c.PreviewInTruncated)
+			}
+			if string(c.PreviewOut) != "ok" || c.PreviewOutTruncated {
+				t.Errorf("preview out = %q truncated=%v, want \"ok\", not truncated", c.PreviewOut, c.PreviewOutTruncated)
+			}
+			if c.BytesIn != 13 {
+				t.Errorf("bytes in = %d, want 13", c.BytesIn)
+			}
+		})
+	}
+}
+
+func TestForwarderTCP_Fault(t *testing.T) {
+	// Echo server.
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan proxy.Event, 16)
+	f := &proxy.Forwarder{
+		ListenAddr: ln.Addr().String(),
+		Target:     spec.Endpoint{HostPort: upstream.Addr().String(), Protocol: spec.TCP},
+		Source:     "api",
+		TargetSvc:  "db",
+		Ingress:    "default",
+		Protocol:   "tcp",
+		Listener:   ln,
+		Emit:       func(ev proxy.Event) { events <- ev },
+		// First connection dropped, second delayed.
+		Fault: &proxy.Fault{Latency: 50 * time.Millisecond, DropRate: 0.5, Rand: seqRand(0.1, 0.9)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Runner().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	buf := make([]byte, 4)
+	dropped, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dropped.Close()
+	dropped.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := dropped.Read(buf); err == nil {
+		t.Error("expected the first connection to be dropped")
+	}
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	start := time.Now()
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatalf("delayed connection: %v", err)
+	}
+	if took := time.Since(start); took < 50*time.Millisecond {
+		t.Errorf("echo took %s, want at least 50ms", took)
+	}
+
+	var kinds []string
+	deadline := time.After(5 * time.Second)
+	for len(kinds) < 2 {
+		select {
+		case ev := <-events:
+			if ev.Type == "fault.injected" {
+				kinds = append(kinds, ev.Fault.Kind)
+			}
+		case <-deadline:
+			t.Fatalf("timed out with fault kinds %v", kinds)
+		}
+	}
+	if kinds[0] != proxy.FaultDrop || kinds[1] != proxy.FaultLatency {
+		t.Errorf("fault kinds = %v, want [drop latency]", kinds)
+	}
+}
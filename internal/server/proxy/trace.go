@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// TraceHeader carries rig's synthesized trace context between hops, as
+// "<trace-id>-<span-id>" (32 and 16 hex digits). A proxy that receives a
+// request with the header continues that trace, recording the caller's
+// span as the parent; otherwise it starts a new trace. Either way the
+// target sees the header with the proxy's own span, so a service that
+// copies it onto the requests it makes links them to the inbound one.
+// Services that drop it produce one trace per hop.
+const TraceHeader = "X-Rig-Trace"
+
+// traceContext identifies one proxied call within a trace.
+type traceContext struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+}
+
+// nextTrace returns the trace context for a call that arrived with the
+// given TraceHeader value (possibly empty or malformed).
+func nextTrace(incoming string) traceContext {
+	tc := traceContext{SpanID: randomHex(8)}
+	if traceID, spanID, ok := strings.Cut(strings.TrimSpace(incoming), "-"); ok && isHex(traceID, 32) && isHex(spanID, 16) {
+		tc.TraceID = strings.ToLower(traceID)
+		tc.ParentSpanID = strings.ToLower(spanID)
+	} else {
+		tc.TraceID = randomHex(16)
+	}
+	return tc
+}
+
+// traceRequest starts the call's span from h's TraceHeader and replaces
+// the header with it for the next hop.
+func traceRequest(h http.Header) traceContext {
+	tc := nextTrace(h.Get(TraceHeader))
+	h.Set(TraceHeader, tc.TraceID+"-"+tc.SpanID)
+	return tc
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
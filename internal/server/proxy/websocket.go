@@ -0,0 +1,149 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// observeUpgrade handles a 101 Switching Protocols response. The reverse
+// proxy hijacks the client connection and relays bytes between it and
+// resp.Body, which must stay an io.ReadWriteCloser — wrapping it in a
+// read-only observedBody makes the upgrade fail. For websocket upgrades the
+// body is wrapped in a wsConn that counts frames and bytes in each
+// direction, with websocket.opened emitted now and websocket.closed when
+// the relay finishes. Other upgrades are passed through unobserved.
+func (t *observingTransport) observeUpgrade(req *http.Request, resp *http.Response, path string) *http.Response {
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return resp
+	}
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		return resp
+	}
+
+	info := func() *WebSocketInfo {
+		return &WebSocketInfo{
+			Source:  t.source,
+			Target:  t.target,
+			Ingress: t.ingress,
+			Path:    path,
+		}
+	}
+	t.emit(Event{Type: "websocket.opened", WebSocket: info()})
+
+	start := time.Now()
+	c := &wsConn{rwc: rwc}
+	c.emit = func() {
+		ws := info()
+		ws.FramesIn = c.in.frames.Load()
+		ws.FramesOut = c.out.frames.Load()
+		ws.BytesIn = c.in.bytes.Load()
+		ws.BytesOut = c.out.bytes.Load()
+		ws.DurationMs = float64(time.Since(start).Microseconds()) / 1000.0
+		t.emit(Event{Type: "websocket.closed", WebSocket: ws})
+	}
+	resp.Body = c
+	return resp
+}
+
+// wsConn wraps the upgraded backend connection. Writes carry client →
+// target traffic and reads carry target → client traffic; each direction
+// is fed through its own frame counter.
+type wsConn struct {
+	rwc  io.ReadWriteCloser
+	in   wsFrameCounter // client → target
+	out  wsFrameCounter // target → client
+	emit func()
+	once sync.Once
+}
+
+func (c *wsConn) Read(p []byte) (int, error) {
+	n, err := c.rwc.Read(p)
+	c.out.Write(p[:n])
+	return n, err
+}
+
+func (c *wsConn) Write(p []byte) (int, error) {
+	n, err := c.rwc.Write(p)
+	c.in.Write(p[:n])
+	return n, err
+}
+
+func (c *wsConn) Close() error {
+	err := c.rwc.Close()
+	c.once.Do(c.emit)
+	return err
+}
+
+// wsFrameCounter counts websocket frames (RFC 6455) in one direction of a
+// byte stream. It only decodes frame headers to find frame boundaries;
+// payloads are skipped. Control frames (ping, pong, close) are counted.
+// Write is called from a single goroutine; the counts are atomic so they
+// can be read once the connection closes.
+type wsFrameCounter struct {
+	frames atomic.Int64
+	bytes  atomic.Int64
+
+	hdr  []byte // partial frame header
+	skip uint64 // payload bytes left in the current frame
+}
+
+func (c *wsFrameCounter) Write(p []byte) (int, error) {
+	n := len(p)
+	c.bytes.Add(int64(n))
+	for len(p) > 0 {
+		if c.skip > 0 {
+			k := min(c.skip, uint64(len(p)))
+			c.skip -= k
+			p = p[k:]
+			continue
+		}
+		need := wsHeaderLen(c.hdr)
+		take := min(need-len(c.hdr), len(p))
+		c.hdr = append(c.hdr, p[:take]...)
+		p = p[take:]
+		if len(c.hdr) < wsHeaderLen(c.hdr) {
+			continue
+		}
+		c.frames.Add(1)
+		c.skip = wsPayloadLen(c.hdr)
+		c.hdr = c.hdr[:0]
+	}
+	return n, nil
+}
+
+// wsHeaderLen returns the full header length of the frame whose header
+// starts with h, or 2 if not enough of it has been seen to tell.
+func wsHeaderLen(h []byte) int {
+	if len(h) < 2 {
+		return 2
+	}
+	n := 2
+	switch h[1] & 0x7f {
+	case 126:
+		n += 2
+	case 127:
+		n += 8
+	}
+	if h[1]&0x80 != 0 { // masked: 4-byte masking key
+		n += 4
+	}
+	return n
+}
+
+// wsPayloadLen decodes the payload length from a complete frame header.
+func wsPayloadLen(h []byte) uint64 {
+	switch l := h[1] & 0x7f; l {
+	case 126:
+		return uint64(binary.BigEndian.Uint16(h[2:4]))
+	case 127:
+		return binary.BigEndian.Uint64(h[2:10])
+	default:
+		return uint64(l)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
 	"google.golang.org/grpc/status"
 )
 
@@ -37,3 +38,56 @@ func (GRPC) Check(ctx context.Context, addr string) error {
 	}
 	return nil
 }
+
+// GRPCServices checks that the server has registered each of Services,
+// listed through server reflection. A server can pass the health check
+// before its handlers are registered, so dependents waiting on this avoid
+// UNIMPLEMENTED errors at cold start. If the server doesn't serve
+// reflection, it falls back to the GRPC check.
+type GRPCServices struct {
+	Services []string // fully qualified, e.g. "orders.v1.OrderService"
+}
+
+func (c *GRPCServices) Check(ctx context.Context, addr string) error {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Cancelling ends the reflection stream.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return err
+	}
+	err = stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := stream.Recv()
+	if status.Code(err) == codes.Unimplemented {
+		return GRPC{}.Check(ctx, addr)
+	}
+	if err != nil {
+		return err
+	}
+	list := resp.GetListServicesResponse()
+	if list == nil {
+		return fmt.Errorf("grpc reflection: no service list in response")
+	}
+	registered := make(map[string]bool, len(list.Service))
+	for _, s := range list.Service {
+		registered[s.Name] = true
+	}
+	for _, name := range c.Services {
+		if !registered[name] {
+			return fmt.Errorf("grpc service %s not registered", name)
+		}
+	}
+	return nil
+}
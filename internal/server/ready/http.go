@@ -2,15 +2,18 @@ package ready
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"time"
 )
 
 // HTTP checks readiness by making an HTTP GET request.
-// Any response with status < 500 is considered ready.
+// Any response with status < 500 is considered ready, unless Status is set.
 type HTTP struct {
-	Path string // default "/"
+	Path   string // default "/"
+	Status int    // exact status required; 0 accepts any status < 500
+	TLS    bool   // use https; the certificate is not verified
 }
 
 func (h *HTTP) Check(ctx context.Context, addr string) error {
@@ -19,19 +22,34 @@ func (h *HTTP) Check(ctx context.Context, addr string) error {
 		path = "/"
 	}
 
-	url := fmt.Sprintf("http://%s%s", addr, path)
+	scheme := "http"
+	if h.TLS {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, addr, path)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return err
 	}
 
 	client := &http.Client{Timeout: 200 * time.Millisecond}
+	if h.TLS {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
 	resp.Body.Close()
 
+	if h.Status != 0 {
+		if resp.StatusCode != h.Status {
+			return fmt.Errorf("HTTP %d, want %d", resp.StatusCode, h.Status)
+		}
+		return nil
+	}
 	if resp.StatusCode >= 500 {
 		return fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
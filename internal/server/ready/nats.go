@@ -0,0 +1,40 @@
+package ready
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// NATS checks readiness by connecting and waiting for the server's INFO
+// line, which a NATS server sends as soon as it accepts a client. A bare
+// TCP dial can succeed before the server is serving (e.g. through Docker's
+// port forwarder) and then be closed without a greeting.
+type NATS struct{}
+
+func (NATS) Check(ctx context.Context, addr string) error {
+	d := net.Dialer{Timeout: 200 * time.Millisecond}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(time.Second)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("nats info: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("nats info: got %q, want INFO", strings.TrimRight(line, "\r\n"))
+	}
+	return nil
+}
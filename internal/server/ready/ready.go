@@ -2,9 +2,11 @@ package ready
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/matgreaves/rig/connect"
 	"github.com/matgreaves/rig/internal/spec"
 )
 
@@ -35,32 +37,49 @@ func ForEndpoint(ep spec.Endpoint, readySpec *spec.ReadySpec) Checker {
 	switch checkType {
 	case "http":
 		path := "/"
-		if readySpec != nil && readySpec.Path != "" {
-			path = readySpec.Path
+		var status int
+		if readySpec != nil {
+			if readySpec.Path != "" {
+				path = readySpec.Path
+			}
+			status = readySpec.Status
 		}
-		return &HTTP{Path: path}
+		secure, _ := ep.Attributes[string(connect.Secure)].(bool)
+		return &HTTP{Path: path, Status: status, TLS: secure}
 	case "grpc":
 		return &GRPC{}
+	case "redis":
+		return Redis{}
+	case "nats":
+		return NATS{}
 	default:
 		return &TCP{}
 	}
 }
 
 // Poll repeatedly calls checker.Check with exponential backoff until
-// the check succeeds or the context is cancelled/timed out.
+// the check succeeds or the context is cancelled/timed out. When
+// readySpec.Successes is above one, that many checks must pass in a row;
+// passing checks are spaced by the initial interval and a failure starts
+// the count again.
 //
 // If onFailure is non-nil it is called after each failed probe with the
 // check error, giving the caller an opportunity to log or emit events.
+// A check error marked with Permanent stops polling immediately.
 func Poll(ctx context.Context, addr string, checker Checker, readySpec *spec.ReadySpec, onFailure func(err error)) error {
 	timeout := DefaultTimeout
-	interval := DefaultInitialInterval
+	initial := DefaultInitialInterval
+	successes := 1
 
 	if readySpec != nil {
 		if readySpec.Timeout.Duration > 0 {
 			timeout = readySpec.Timeout.Duration
 		}
 		if readySpec.Interval.Duration > 0 {
-			interval = readySpec.Interval.Duration
+			initial = readySpec.Interval.Duration
+		}
+		if readySpec.Successes > 1 {
+			successes = readySpec.Successes
 		}
 	}
 
@@ -68,24 +87,43 @@ func Poll(ctx context.Context, addr string, checker Checker, readySpec *spec.Rea
 	defer cancel()
 
 	var lastErr error
+	interval := initial
+	streak := 0
 
 	for {
 		if err := checker.Check(ctx, addr); err == nil {
-			return nil
+			streak++
+			if streak >= successes {
+				return nil
+			}
 		} else {
+			streak = 0
 			lastErr = err
 			if onFailure != nil {
 				onFailure(err)
 			}
+			if IsPermanent(err) {
+				return fmt.Errorf("readiness check failed: %w", err)
+			}
 		}
 
+		wait := interval
+		if streak > 0 {
+			wait = initial
+		}
 		select {
 		case <-ctx.Done():
+			if streak > 0 {
+				return fmt.Errorf("readiness check failed after %s (%d of %d checks in a row passed)", timeout, streak, successes)
+			}
 			if lastErr != nil {
 				return fmt.Errorf("readiness check failed after %s (last error: %v)", timeout, lastErr)
 			}
 			return fmt.Errorf("readiness check failed: %w", ctx.Err())
-		case <-time.After(interval):
+		case <-time.After(wait):
+		}
+		if streak > 0 {
+			continue
 		}
 
 		// Exponential backoff, capped at max (but never below the configured interval).
@@ -99,3 +137,25 @@ func Poll(ctx context.Context, addr string, checker Checker, readySpec *spec.Rea
 		}
 	}
 }
+
+// permanentError marks a check error that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, e.g. a service configured in a
+// way that can never become ready. Poll returns it without waiting out the
+// timeout.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
@@ -5,12 +5,21 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/matgreaves/rig/internal/server/ready"
 	"github.com/matgreaves/rig/internal/spec"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 func TestTCPCheck_Success(t *testing.T) {
@@ -97,6 +106,59 @@ func TestHTTPCheck_ServerError(t *testing.T) {
 	}
 }
 
+func TestHTTPCheck_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	ep := spec.Endpoint{Protocol: spec.HTTP, Attributes: map[string]any{"SECURE": true}}
+	checker := ready.ForEndpoint(ep, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// A plain-http probe would get a 400 and pass; only an https probe
+	// reaches the handler's 500.
+	err := checker.Check(ctx, strings.TrimPrefix(ts.URL, "https://"))
+	if err == nil || !strings.Contains(err.Error(), "HTTP 500") {
+		t.Errorf("expected HTTP 500 over https, got: %v", err)
+	}
+}
+
+func TestHTTPCheck_Status(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	addr := strings.TrimPrefix(ts.URL, "http://")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tests := []struct {
+		ready   spec.ReadySpec
+		wantErr string
+	}{
+		{ready: spec.ReadySpec{Path: "/missing", Status: http.StatusNotFound}},
+		{ready: spec.ReadySpec{Path: "/", Status: http.StatusNotFound}, wantErr: "HTTP 200, want 404"},
+		{ready: spec.ReadySpec{Path: "/"}},
+		{ready: spec.ReadySpec{Path: "/missing"}}, // 404 < 500
+	}
+	for _, tt := range tests {
+		checker := ready.ForEndpoint(spec.Endpoint{Protocol: spec.HTTP}, &tt.ready)
+		err := checker.Check(ctx, addr)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%+v: unexpected error: %v", tt.ready, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%+v: err = %v, want %q", tt.ready, err, tt.wantErr)
+		}
+	}
+}
+
 func TestPoll_Success(t *testing.T) {
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -161,6 +223,74 @@ func TestPoll_OnFailureCallback(t *testing.T) {
 	}
 }
 
+// checkFunc adapts a function to ready.Checker.
+type checkFunc func(ctx context.Context, addr string) error
+
+func (f checkFunc) Check(ctx context.Context, addr string) error { return f(ctx, addr) }
+
+func TestPoll_PermanentError(t *testing.T) {
+	calls := 0
+	checker := checkFunc(func(context.Context, string) error {
+		calls++
+		return ready.Permanent(fmt.Errorf("no healthcheck"))
+	})
+
+	start := time.Now()
+	err := ready.Poll(context.Background(), "", checker, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "no healthcheck") {
+		t.Fatalf("err = %v, want the permanent check error", err)
+	}
+	if calls != 1 {
+		t.Errorf("checker called %d times, want 1", calls)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Poll took %s, want an immediate return", time.Since(start))
+	}
+}
+
+func TestPoll_Successes(t *testing.T) {
+	// Passes, fails, then passes for good: the failure restarts the count.
+	results := []bool{true, false, true, true, true}
+	calls := 0
+	checker := checkFunc(func(context.Context, string) error {
+		ok := calls >= len(results) || results[calls]
+		calls++
+		if !ok {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+
+	rs := &spec.ReadySpec{Interval: spec.Duration{Duration: time.Millisecond}, Successes: 3}
+	if err := ready.Poll(context.Background(), "", checker, rs, nil); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if calls != 5 {
+		t.Errorf("checker called %d times, want 5", calls)
+	}
+}
+
+func TestPoll_SuccessesTimeout(t *testing.T) {
+	// Alternating probes never make a streak of two.
+	calls := 0
+	checker := checkFunc(func(context.Context, string) error {
+		calls++
+		if calls%2 == 0 {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+
+	rs := &spec.ReadySpec{
+		Interval:  spec.Duration{Duration: time.Millisecond},
+		Timeout:   spec.Duration{Duration: 50 * time.Millisecond},
+		Successes: 2,
+	}
+	if err := ready.Poll(context.Background(), "", checker, rs, nil); err == nil {
+		t.Fatal("expected a timeout when checks never pass twice in a row")
+	}
+}
+
 func TestPoll_DelayedReady(t *testing.T) {
 	// Start a listener after a delay to simulate slow startup.
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
@@ -227,3 +357,180 @@ func TestForEndpoint_ReadySpecOverride(t *testing.T) {
 		t.Errorf("expected TCP checker from override, got %s", got)
 	}
 }
+
+// fakeRedis accepts one connection at a time and answers every read with
+// reply.
+func fakeRedis(t *testing.T, reply string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 64)
+			conn.Read(buf)
+			conn.Write([]byte(reply))
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestRedisCheck(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := (ready.Redis{}).Check(ctx, fakeRedis(t, "+PONG\r\n")); err != nil {
+		t.Errorf("expected success, got: %v", err)
+	}
+
+	err := (ready.Redis{}).Check(ctx, fakeRedis(t, "-LOADING Redis is loading the dataset in memory\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "LOADING") {
+		t.Errorf("expected LOADING error, got: %v", err)
+	}
+}
+
+// fakeGreeter accepts connections and writes greeting to each without
+// waiting for the client, as a NATS server does.
+func fakeGreeter(t *testing.T, greeting string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte(greeting))
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestNATSCheck(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := (ready.NATS{}).Check(ctx, fakeGreeter(t, "INFO {\"server_id\":\"x\"}\r\n")); err != nil {
+		t.Errorf("expected success, got: %v", err)
+	}
+	if err := (ready.NATS{}).Check(ctx, fakeGreeter(t, "")); err == nil {
+		t.Error("expected error when the connection closes without INFO")
+	}
+	if got := fmt.Sprintf("%T", ready.ForEndpoint(spec.Endpoint{Protocol: spec.NATS}, nil)); got != "ready.NATS" {
+		t.Errorf("ForEndpoint(nats) = %s, want ready.NATS", got)
+	}
+}
+
+func TestTemporalTaskQueueCheck(t *testing.T) {
+	// A fake frontend with one activity worker polling "orders" in
+	// namespace "ns".
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		if method != "/temporal.api.workflowservice.v1.WorkflowService/DescribeTaskQueue" {
+			return status.Errorf(codes.Unimplemented, "unexpected method %s", method)
+		}
+		req := &emptypb.Empty{}
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		var namespace, queue string
+		var typ uint64
+		b := req.ProtoReflect().GetUnknown()
+		for len(b) > 0 {
+			num, wt, n := protowire.ConsumeTag(b)
+			b = b[n:]
+			switch {
+			case num == 1:
+				v, n := protowire.ConsumeString(b)
+				namespace, b = v, b[n:]
+			case num == 2:
+				v, n := protowire.ConsumeBytes(b)
+				_, _, tn := protowire.ConsumeTag(v)
+				queue, _ = protowire.ConsumeString(v[tn:])
+				b = b[n:]
+			case num == 3:
+				v, n := protowire.ConsumeVarint(b)
+				typ, b = v, b[n:]
+			default:
+				b = b[protowire.ConsumeFieldValue(num, wt, b):]
+			}
+		}
+
+		resp := &emptypb.Empty{}
+		if namespace == "ns" && queue == "orders" && typ == 2 {
+			var poller []byte
+			poller = protowire.AppendTag(poller, 2, protowire.BytesType)
+			poller = protowire.AppendString(poller, "worker@host")
+			var out []byte
+			out = protowire.AppendTag(out, 1, protowire.BytesType)
+			out = protowire.AppendBytes(out, poller)
+			resp.ProtoReflect().SetUnknown(out)
+		}
+		return stream.SendMsg(resp)
+	}))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	addr := ln.Addr().String()
+	if err := (ready.TemporalTaskQueue{Namespace: "ns", Queue: "orders"}).Check(ctx, addr); err != nil {
+		t.Errorf("expected success, got: %v", err)
+	}
+	err = (ready.TemporalTaskQueue{Namespace: "ns", Queue: "billing"}).Check(ctx, addr)
+	if err == nil || !strings.Contains(err.Error(), `task queue "billing" has no pollers`) {
+		t.Errorf("err = %v, want no pollers", err)
+	}
+}
+
+func TestGRPCServicesCheck(t *testing.T) {
+	serve := func(withReflection bool) string {
+		srv := grpc.NewServer()
+		healthpb.RegisterHealthServer(srv, health.NewServer())
+		if withReflection {
+			reflection.Register(srv)
+		}
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		go srv.Serve(ln)
+		t.Cleanup(srv.Stop)
+		return ln.Addr().String()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	addr := serve(true)
+	if err := (&ready.GRPCServices{Services: []string{"grpc.health.v1.Health"}}).Check(ctx, addr); err != nil {
+		t.Errorf("expected success, got: %v", err)
+	}
+	err := (&ready.GRPCServices{Services: []string{"grpc.health.v1.Health", "orders.v1.OrderService"}}).Check(ctx, addr)
+	if err == nil || !strings.Contains(err.Error(), "grpc service orders.v1.OrderService not registered") {
+		t.Errorf("err = %v, want not registered", err)
+	}
+
+	// Without reflection the service list is unknown; a serving health
+	// check is enough.
+	if err := (&ready.GRPCServices{Services: []string{"orders.v1.OrderService"}}).Check(ctx, serve(false)); err != nil {
+		t.Errorf("fallback: expected success, got: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package ready
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Redis checks readiness by sending PING and waiting for PONG. A bare TCP
+// dial can succeed while Redis is still loading its dataset; during that
+// window PING is answered with a -LOADING error instead.
+type Redis struct{}
+
+func (Redis) Check(ctx context.Context, addr string) error {
+	d := net.Dialer{Timeout: 200 * time.Millisecond}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(time.Second)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+		return fmt.Errorf("redis ping: %w", err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("redis ping: %w", err)
+	}
+	if reply := strings.TrimRight(line, "\r\n"); reply != "+PONG" {
+		return fmt.Errorf("redis ping: got %q, want +PONG", reply)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package ready
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const describeTaskQueueMethod = "/temporal.api.workflowservice.v1.WorkflowService/DescribeTaskQueue"
+
+// Temporal TaskQueueType values.
+const (
+	taskQueueTypeWorkflow = 1
+	taskQueueTypeActivity = 2
+)
+
+// TemporalTaskQueue checks that a worker has registered on a Temporal task
+// queue, i.e. that DescribeTaskQueue lists at least one poller for it.
+// Workflow and activity pollers both count. The check runs against the
+// Temporal frontend's gRPC address.
+//
+// rig doesn't depend on the Temporal API module, so the request and
+// response are encoded by hand: the messages ride in the unknown fields of
+// an empty proto message.
+type TemporalTaskQueue struct {
+	Namespace string
+	Queue     string
+}
+
+func (c TemporalTaskQueue) Check(ctx context.Context, addr string) error {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, typ := range []uint64{taskQueueTypeWorkflow, taskQueueTypeActivity} {
+		req := &emptypb.Empty{}
+		req.ProtoReflect().SetUnknown(describeTaskQueueRequest(c.Namespace, c.Queue, typ))
+		resp := &emptypb.Empty{}
+		if err := conn.Invoke(ctx, describeTaskQueueMethod, req, resp); err != nil {
+			return fmt.Errorf("describe task queue %q: %w", c.Queue, err)
+		}
+		if countPollers(resp.ProtoReflect().GetUnknown()) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("task queue %q has no pollers", c.Queue)
+}
+
+// describeTaskQueueRequest encodes a DescribeTaskQueueRequest:
+//
+//	namespace = 1; task_queue = 2 { name = 1; kind = 2 (NORMAL) }; task_queue_type = 3
+func describeTaskQueueRequest(namespace, queue string, typ uint64) []byte {
+	var tq []byte
+	tq = protowire.AppendTag(tq, 1, protowire.BytesType)
+	tq = protowire.AppendString(tq, queue)
+	tq = protowire.AppendTag(tq, 2, protowire.VarintType)
+	tq = protowire.AppendVarint(tq, 1)
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, namespace)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, tq)
+	b = protowire.AppendTag(b, 3, protowire.VarintType)
+	b = protowire.AppendVarint(b, typ)
+	return b
+}
+
+// countPollers counts the pollers (repeated field 1) in an encoded
+// DescribeTaskQueueResponse.
+func countPollers(b []byte) int {
+	n := 0
+	for len(b) > 0 {
+		num, typ, tagLen := protowire.ConsumeTag(b)
+		if tagLen < 0 {
+			return n
+		}
+		b = b[tagLen:]
+		valLen := protowire.ConsumeFieldValue(num, typ, b)
+		if valLen < 0 {
+			return n
+		}
+		if num == 1 && typ == protowire.BytesType {
+			n++
+		}
+		b = b[valLen:]
+	}
+	return n
+}
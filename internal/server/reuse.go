@@ -0,0 +1,70 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+// SpecHash identifies an environment spec for reuse. It covers everything
+// that shapes the running environment — services, hooks, options, the
+// client's working directory and host env — but not the name, metadata or
+// the reuse flag itself, which differ between the tests sharing it.
+func SpecHash(env *spec.Environment) string {
+	e := *env
+	e.Name = ""
+	e.Metadata = nil
+	e.Reuse = false
+	// encoding/json sorts map keys, so equal specs marshal identically.
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// acquireShared finds a live environment created with Reuse from a spec
+// with the given hash and takes a reference to it. Environments that have
+// failed or are shutting down are not shared.
+func (s *Server) acquireShared(hash string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, inst := range s.envs {
+		if !inst.spec.Reuse || inst.specHash != hash || inst.refs == 0 || envStopping(inst.log) {
+			continue
+		}
+		inst.refs++
+		return id, true
+	}
+	return "", false
+}
+
+// releaseShared drops one reference to environment id. It reports false,
+// leaving the environment to be torn down, when id is unknown or this was
+// the last reference. A failed client's reason sticks, so the log of the
+// shared environment records that a test using it failed.
+func (s *Server) releaseShared(id, reason string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inst, ok := s.envs[id]
+	if !ok || inst.refs <= 1 {
+		return 0, false
+	}
+	inst.refs--
+	if reason != "" {
+		inst.reason = reason
+	}
+	return inst.refs, true
+}
+
+// envStopping reports whether an environment has started failing or
+// tearing down.
+func envStopping(log *EventLog) bool {
+	for _, e := range log.LifecycleEvents() {
+		switch e.Type {
+		case EventEnvironmentFailing, EventEnvironmentDestroying, EventEnvironmentDown:
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -34,6 +35,11 @@ type Server struct {
 	idle      *IdleTimer
 	cache     *artifact.Cache
 	refresher *artifact.Refresher
+	socket    *EventSocket // optional; receives every environment's events
+	retry     artifact.RetryPolicy
+	artifacts int  // max concurrent artifact resolutions; 0 = default
+	stream    bool // stream each environment's JSONL log to disk as events are published
+	maxEvents int  // cap on each environment's in-memory event log; 0 = unlimited
 }
 
 // envInstance holds the runtime state of a single active environment.
@@ -42,13 +48,20 @@ type envInstance struct {
 	spec     *spec.Environment
 	log      *EventLog
 	envDir   string
-	preserve *bool  // shared with Orchestrator; set to true to skip cleanup
-	reason   string // client-signalled teardown reason (e.g. "test_failed")
+	preserve *bool    // shared with Orchestrator; set to true to skip cleanup
+	reason   string   // client-signalled teardown reason (e.g. "test_failed")
+	stream   *os.File // streamed JSONL log; nil unless the server streams logs
+	archived bool     // the streamed log holds events dropped from memory; keep it
 
 	cancel      context.CancelFunc
 	done        <-chan error // receives runner's terminal error (buffered 1)
 	ttlTimer    *time.Timer // stopped on teardown to prevent double-fire
 	ttlDeadline time.Time   // when the TTL expires; used by GET /environments
+
+	// specHash identifies the spec for reuse; refs counts the clients
+	// sharing the environment. Both are guarded by Server.mu.
+	specHash string
+	refs     int
 }
 
 // NewServer creates a Server and registers all HTTP routes.
@@ -86,10 +99,55 @@ func NewServer(
 	s.mux.HandleFunc("GET /environments", s.handleListEnvironments)
 	s.mux.HandleFunc("GET /environments/{id}", s.handleGetEnvironment)
 	s.mux.HandleFunc("GET /environments/{id}/log", s.handleGetLog)
+	s.mux.HandleFunc("GET /environments/{id}/graph", s.handleGetGraph)
+	s.mux.HandleFunc("GET /environments/{id}/traffic", s.handleGetTraffic)
 
 	return s
 }
 
+// SetEventSocket streams the events of every environment created after
+// this call to sock. Call before serving requests.
+func (s *Server) SetEventSocket(sock *EventSocket) {
+	s.socket = sock
+}
+
+// SetArtifactRetry retries transient artifact failures, such as image pulls
+// that hit a network error, up to attempts times in total, waiting backoff
+// before the first retry and doubling it after each. Each retry is recorded
+// as an artifact.retry event. Permanent failures (an unknown image tag, a
+// denied pull) are never retried. Call before serving requests.
+func (s *Server) SetArtifactRetry(attempts int, backoff time.Duration) {
+	s.retry = artifact.RetryPolicy{Attempts: attempts, Backoff: backoff}
+	s.refresher.SetRetry(s.retry)
+}
+
+// SetArtifactConcurrency limits how many artifacts (image pulls, go
+// builds) an environment resolves at once. n < 1 means
+// artifact.DefaultConcurrency. Call before serving requests.
+func (s *Server) SetArtifactConcurrency(n int) {
+	s.artifacts = n
+}
+
+// SetStreamLogs makes the server append each environment's events to its
+// JSONL log in {rigDir}/logs/ as they are published, rather than writing
+// the whole file on teardown. A log then exists even if rigd is killed
+// before the environment is torn down; its header has outcome "running"
+// until teardown rewrites the file and the .log timeline. Call before
+// serving requests.
+func (s *Server) SetStreamLogs(on bool) {
+	s.stream = on
+}
+
+// SetMaxEvents caps each environment's in-memory event log at n events, so
+// long-running or high-traffic environments can't exhaust rigd's memory.
+// Past the cap the oldest service output and traffic events are dropped;
+// lifecycle and failure events are kept. The log header records how many
+// were dropped. With SetStreamLogs the streamed JSONL log keeps every
+// event. n <= 0 means no cap. Call before serving requests.
+func (s *Server) SetMaxEvents(n int) {
+	s.maxEvents = n
+}
+
 // ServeHTTP implements http.Handler.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.mux.ServeHTTP(w, r)
@@ -154,23 +212,52 @@ func (s *Server) handleCreateEnvironment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if errs := ValidateEnvironment(&env); len(errs) > 0 {
+	errs := ValidateEnvironment(&env)
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	if dryRun && len(errs) == 0 {
+		errs = DryRun(&env, s.registry)
+	}
+	if len(errs) > 0 {
 		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
 			"error":             "spec validation failed",
 			"validation_errors": errs,
 		})
 		return
 	}
+	// A dry run stops here: the spec is valid and nothing was created.
+	if dryRun {
+		writeJSON(w, http.StatusOK, map[string]any{"valid": true})
+		return
+	}
+
+	// Hash and collect warnings before orchestration, which inserts virtual
+	// services into env.
+	hash := SpecHash(&env)
+	warnings := ValidationWarnings(&env)
+	if env.Reuse {
+		if id, ok := s.acquireShared(hash); ok {
+			writeJSON(w, http.StatusOK, map[string]any{"id": id, "reused": true})
+			return
+		}
+	}
+
+	if err := DockerPreflight(r.Context(), &env, s.registry, pingDocker); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
 
 	envLog := NewEventLog()
+	envLog.SetMaxEvents(s.maxEvents)
 	preserve := false
 	orch := &Orchestrator{
-		Ports:    s.ports,
-		Registry: s.registry,
-		Log:      envLog,
-		TempBase: s.tempBase,
-		Cache:    s.cache,
-		Preserve: &preserve,
+		Ports:               s.ports,
+		Registry:            s.registry,
+		Log:                 envLog,
+		TempBase:            s.tempBase,
+		Cache:               s.cache,
+		Preserve:            &preserve,
+		ArtifactRetry:       s.retry,
+		ArtifactConcurrency: s.artifacts,
 	}
 
 	runner, id, envDir, err := orch.Orchestrate(&env)
@@ -179,6 +266,10 @@ func (s *Server) handleCreateEnvironment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if s.socket != nil {
+		envLog.Tap(func(ev Event) { s.socket.Write(id, ev) })
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	done := make(chan error, 1)
 
@@ -190,6 +281,16 @@ func (s *Server) handleCreateEnvironment(w http.ResponseWriter, r *http.Request)
 		preserve: &preserve,
 		cancel:   cancel,
 		done:     done,
+		specHash: hash,
+		refs:     1,
+	}
+	if s.stream {
+		// Best effort: without the streamed file the log is still written
+		// on teardown.
+		if f, err := s.openStreamLog(inst); err == nil {
+			inst.stream = f
+			envLog.Tee(f)
+		}
 	}
 
 	s.mu.Lock()
@@ -222,16 +323,27 @@ func (s *Server) handleCreateEnvironment(w http.ResponseWriter, r *http.Request)
 		// Emit environment.down before signalling done so that SSE clients
 		// see the terminal event before DELETE returns. Include a pre-formatted
 		// summary so client SDKs can use it directly as an error message.
+		// Teardown sets inst.reason before cancelling ctx, so it is only
+		// read once ctx is done; a runner that exits on its own has crashed.
+		var reason string
+		if ctx.Err() != nil {
+			reason = inst.reason
+		}
 		envLog.Publish(Event{
 			Type:        EventEnvironmentDown,
 			Environment: env.Name,
 			Message:     buildDownSummary(envLog),
+			Outcome:     deriveOutcome(reason, envLog.Events()),
 		})
 
 		done <- err
 	}()
 
-	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+	created := map[string]any{"id": id}
+	if len(warnings) > 0 {
+		created["warnings"] = warnings
+	}
+	writeJSON(w, http.StatusCreated, created)
 }
 
 // handleGetEnvironment handles GET /environments/{id}.
@@ -240,7 +352,7 @@ func (s *Server) handleGetEnvironment(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
-	resolved, err := buildResolvedEnvironment(inst)
+	resolved, err := buildResolvedEnvironment(inst.id, inst.spec, inst.log)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "resolve attributes: "+err.Error())
 		return
@@ -264,6 +376,9 @@ type clientEvent struct {
 	// service.log fields
 	Stream  string `json:"stream,omitempty"`   // "stdout" or "stderr"
 	LogData string `json:"log_data,omitempty"` // log line content
+
+	// test.note fields
+	Assertion *AssertionInfo `json:"assertion,omitempty"`
 }
 
 // handleClientEvent handles POST /environments/{id}/events.
@@ -327,6 +442,7 @@ func (s *Server) handleClientEvent(w http.ResponseWriter, r *http.Request) {
 			Type:        EventTestNote,
 			Environment: inst.spec.Name,
 			Error:       ev.Error,
+			Assertion:   ev.Assertion,
 		})
 
 	default:
@@ -339,18 +455,20 @@ func (s *Server) handleClientEvent(w http.ResponseWriter, r *http.Request) {
 
 // teardownOpts controls how teardownEnvironment behaves.
 type teardownOpts struct {
-	preserve bool   // skip temp dir cleanup
-	reason   string // e.g. "test_failed", "ttl_expired", "orphaned"
-	writeLog bool   // write event log to disk
+	preserve  bool   // skip temp dir cleanup
+	reason    string // e.g. "test_failed", "ttl_expired", "orphaned"
+	writeLog  bool   // write event log to disk
+	splitLogs bool   // also write per-service stdout/stderr files (requires writeLog)
 }
 
 // teardownResult holds the outcome of an environment teardown.
 type teardownResult struct {
-	OK            bool   // false if the environment was not found (already torn down)
-	EnvDir        string // the environment's temp directory
-	LogFile       string // structured JSONL event log path
-	LogFilePretty string // human-readable timeline path
-	Summary       string // condensed failure diagnosis
+	OK            bool     // false if the environment was not found (already torn down)
+	EnvDir        string   // the environment's temp directory
+	LogFile       string   // structured JSONL event log path
+	LogFilePretty string   // human-readable timeline path
+	ServiceLogs   []string // per-service stdout/stderr log paths
+	Summary       string   // condensed failure diagnosis
 }
 
 // teardownEnvironment performs the full teardown sequence for an environment:
@@ -408,6 +526,19 @@ func (s *Server) teardownEnvironment(id string, opts teardownOpts) teardownResul
 		inst.ttlTimer.Stop()
 	}
 
+	// Stop streaming before the log is rewritten in full (or, if the
+	// client asked for no log, removed).
+	if inst.stream != nil {
+		// A tee that never failed has every event on disk, including any
+		// dropped from memory, so the streamed events are kept.
+		inst.archived = inst.log.TeeErr() == nil && inst.log.Dropped() > 0
+		inst.log.Tee(nil)
+		inst.stream.Close()
+		if !opts.writeLog {
+			os.Remove(inst.stream.Name())
+		}
+	}
+
 	result := teardownResult{OK: true, EnvDir: inst.envDir}
 	if opts.writeLog {
 		if jp, lp, err := s.writeEventLog(inst); err == nil {
@@ -417,6 +548,11 @@ func (s *Server) teardownEnvironment(id string, opts teardownOpts) teardownResul
 				result.Summary = sm
 			}
 		}
+		if opts.splitLogs {
+			if paths, err := s.writeServiceLogs(inst); err == nil {
+				result.ServiceLogs = paths
+			}
+		}
 	}
 
 	return result
@@ -435,10 +571,21 @@ const defaultTTL = 10 * time.Minute
 func (s *Server) handleDeleteEnvironment(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
+	// A shared environment outlives all but its last client.
+	if refs, ok := s.releaseShared(id, r.URL.Query().Get("reason")); ok {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id":     id,
+			"status": "released",
+			"refs":   refs,
+		})
+		return
+	}
+
 	opts := teardownOpts{
-		preserve: r.URL.Query().Get("preserve") == "true",
-		reason:   r.URL.Query().Get("reason"),
-		writeLog: r.URL.Query().Get("log") == "true",
+		preserve:  r.URL.Query().Get("preserve") == "true",
+		reason:    r.URL.Query().Get("reason"),
+		writeLog:  r.URL.Query().Get("log") == "true",
+		splitLogs: r.URL.Query().Get("split_logs") == "true",
 	}
 
 	tr := s.teardownEnvironment(id, opts)
@@ -458,6 +605,9 @@ func (s *Server) handleDeleteEnvironment(w http.ResponseWriter, r *http.Request)
 	if tr.LogFilePretty != "" {
 		result["log_file_pretty"] = tr.LogFilePretty
 	}
+	if len(tr.ServiceLogs) > 0 {
+		result["service_log_files"] = tr.ServiceLogs
+	}
 	if tr.Summary != "" {
 		result["summary"] = tr.Summary
 	}
@@ -480,9 +630,9 @@ func (s *Server) getInstance(w http.ResponseWriter, r *http.Request) (*envInstan
 
 // buildResolvedEnvironment scans the event log to construct a point-in-time
 // snapshot of the environment: resolved ingress/egress endpoints and service
-// statuses.
-func buildResolvedEnvironment(inst *envInstance) (spec.ResolvedEnvironment, error) {
-	events := inst.log.LifecycleEvents()
+// statuses. It backs GET /environments/{id} and the environment.up payload.
+func buildResolvedEnvironment(id string, env *spec.Environment, log *EventLog) (spec.ResolvedEnvironment, error) {
+	events := log.LifecycleEvents()
 
 	// Intermediate state uses spec.Endpoint (may contain templates).
 	type svcState struct {
@@ -491,8 +641,8 @@ func buildResolvedEnvironment(inst *envInstance) (spec.ResolvedEnvironment, erro
 		status    spec.ServiceStatus
 	}
 
-	states := make(map[string]*svcState, len(inst.spec.Services))
-	for name, svc := range inst.spec.Services {
+	states := make(map[string]*svcState, len(env.Services))
+	for name, svc := range env.Services {
 		if svc.Injected {
 			continue // filter injected services from resolved output
 		}
@@ -535,7 +685,7 @@ func buildResolvedEnvironment(inst *envInstance) (spec.ResolvedEnvironment, erro
 	// follow through to find the real target's endpoint from events).
 	for name := range states {
 		st := states[name]
-		svcSpec := inst.spec.Services[name]
+		svcSpec := env.Services[name]
 		for egressName, egressSpec := range svcSpec.Egresses {
 			if target, ok := states[egressSpec.Service]; ok {
 				if ep, ok := target.ingresses[egressSpec.Ingress]; ok {
@@ -564,8 +714,8 @@ func buildResolvedEnvironment(inst *envInstance) (spec.ResolvedEnvironment, erro
 	}
 
 	return spec.ResolvedEnvironment{
-		ID:       inst.id,
-		Name:     inst.spec.Name,
+		ID:       id,
+		Name:     env.Name,
 		Services: services,
 	}, nil
 }
@@ -653,8 +803,11 @@ func buildDownSummary(log *EventLog) string {
 		switch e.Type {
 		case EventServiceLog, EventHealthCheckFailed,
 			EventCallbackRequest, EventCallbackResponse,
-			EventRequestCompleted, EventConnectionOpened, EventConnectionClosed,
-			EventGRPCCallCompleted,
+			EventRequestCompleted, EventRequestMocked, EventConnectionOpened, EventConnectionClosed,
+			EventHTTPConnectionOpened, EventHTTPConnectionClosed,
+			EventGRPCCallCompleted, EventRedisCommandCompleted, EventNATSMessage, EventMongoCommand,
+			EventGRPCStreamOpened, EventGRPCStreamMessage, EventGRPCStreamClosed,
+			EventWebSocketOpened, EventWebSocketClosed, EventFaultInjected,
 			EventServiceStopping, EventServiceStopped:
 			continue
 		}
@@ -738,17 +891,24 @@ type envListEntry struct {
 	TTL          string   `json:"ttl,omitempty"`
 	RemainingTTL string   `json:"remaining_ttl"`
 	Services     []string `json:"services"`
+	SpecHash     string   `json:"spec_hash"`
+	Refs         int      `json:"refs"`
 }
 
 // handleListEnvironments handles GET /environments.
 //
 // Returns a JSON array of all active environments with their IDs, names,
 // TTL, and service names. Used by `rig ps` and `rig down` for name resolution.
+// ?spec_hash= restricts the list to environments created from that spec.
 func (s *Server) handleListEnvironments(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
+	specHash := r.URL.Query().Get("spec_hash")
 	s.mu.Lock()
 	entries := make([]envListEntry, 0, len(s.envs))
 	for _, inst := range s.envs {
+		if specHash != "" && inst.specHash != specHash {
+			continue
+		}
 		serviceNames := make([]string, 0, len(inst.spec.Services))
 		for name, svc := range inst.spec.Services {
 			if svc.Injected {
@@ -769,6 +929,8 @@ func (s *Server) handleListEnvironments(w http.ResponseWriter, r *http.Request)
 			TTL:          inst.spec.TTL,
 			RemainingTTL: remaining.Truncate(time.Second).String(),
 			Services:     serviceNames,
+			SpecHash:     inst.specHash,
+			Refs:         inst.refs,
 		})
 	}
 	s.mu.Unlock()
@@ -784,12 +946,18 @@ func (s *Server) handleListEnvironments(w http.ResponseWriter, r *http.Request)
 // logHeader is the synthetic first line of a JSONL event log. It contains
 // everything rig ls needs to display a summary without reading further.
 type logHeader struct {
-	Type        string   `json:"type"`
-	Environment string   `json:"environment"`
-	Outcome     string   `json:"outcome,omitempty"`
-	Services    []string `json:"services,omitempty"`
-	DurationMs  float64  `json:"duration_ms"`
-	Timestamp   time.Time `json:"timestamp"`
+	Type            string             `json:"type"`
+	Environment     string             `json:"environment"`
+	Outcome         string             `json:"outcome,omitempty"`
+	Services        []string           `json:"services,omitempty"`
+	DurationMs      float64            `json:"duration_ms"`
+	ArtifactRetries int                `json:"artifact_retries,omitempty"`
+	DroppedEvents   int                `json:"dropped_events,omitempty"`     // events missing from the log, dropped to stay under the event cap
+	ExitCodes       map[string]int     `json:"exit_codes,omitempty"`         // first exit code per crashed service
+	ReadyDurations  map[string]float64 `json:"ready_durations_ms,omitempty"` // service.starting → service.ready per service
+	BodyLimit       *int               `json:"observe_body_limit,omitempty"` // observe body capture limit, if not the default
+	Metadata        map[string]string  `json:"metadata,omitempty"`           // labels from rig.WithMetadata
+	Timestamp       time.Time          `json:"timestamp"`
 }
 
 // deriveOutcome computes the test outcome from the client reason and event log.
@@ -814,9 +982,103 @@ func deriveOutcome(reason string, events []Event) string {
 	return "passed"
 }
 
+// serviceReadyDurations returns how long each of services took from its
+// first service.starting to its first service.ready, in milliseconds.
+// Services that never became ready are left out.
+func serviceReadyDurations(events []Event, services map[string]struct{}) map[string]float64 {
+	starting := map[string]time.Time{}
+	var durations map[string]float64
+	for _, e := range events {
+		if _, ok := services[e.Service]; !ok {
+			continue
+		}
+		switch e.Type {
+		case EventServiceStarting:
+			if _, ok := starting[e.Service]; !ok {
+				starting[e.Service] = e.Timestamp
+			}
+		case EventServiceReady:
+			start, ok := starting[e.Service]
+			if _, done := durations[e.Service]; !ok || done {
+				continue
+			}
+			if durations == nil {
+				durations = map[string]float64{}
+			}
+			durations[e.Service] = float64(e.Timestamp.Sub(start).Microseconds()) / 1000.0
+		}
+	}
+	return durations
+}
+
 // logMaxAge is how long event log files are kept before pruning.
 const logMaxAge = 72 * time.Hour
 
+// openStreamLog creates the JSONL log that inst's events are streamed to and
+// writes a provisional log.header with outcome "running", so `rig ls` lists
+// the log even if the server dies before teardown replaces it.
+func (s *Server) openStreamLog(inst *envInstance) (*os.File, error) {
+	logDir := filepath.Join(s.rigDir, "logs")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(logBase(logDir, inst) + ".jsonl")
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	header := logHeader{
+		Type:        "log.header",
+		Environment: inst.spec.Name,
+		Outcome:     "running",
+		Metadata:    inst.spec.Metadata,
+		Timestamp:   time.Now(),
+	}
+	if err := enc.Encode(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// replaceLogHeader rewrites the JSONL log at path with header in place of
+// its first line, copying the events that follow it.
+func replaceLogHeader(path string, header logHeader) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	r := bufio.NewReader(src)
+	if _, err := r.ReadBytes('\n'); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	enc := json.NewEncoder(tmp)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(header); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
 // writeEventLog writes both a structured JSONL event log and a human-readable
 // timeline summary to {rigDir}/logs/. The JSONL file (one event per line) is
 // the source of truth for tooling; the .log file is a convenience rendering
@@ -834,15 +1096,27 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 		return "", "", fmt.Errorf("no events")
 	}
 
-	safe := strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(inst.spec.Name)
-	base := filepath.Join(logDir, safe+"-"+inst.id)
+	base := logBase(logDir, inst)
 
 	// Derive outcome from events + client reason.
 	outcome := deriveOutcome(inst.reason, events)
 
 	// Collect service names from lifecycle events, filtering injected nodes.
 	serviceSet := map[string]struct{}{}
+	var artifactRetries int
+	var exitCodes map[string]int
 	for _, e := range events {
+		if e.Type == EventArtifactRetry {
+			artifactRetries++
+		}
+		if e.Type == EventServiceFailed && e.ExitCode != nil {
+			if _, ok := exitCodes[e.Service]; !ok {
+				if exitCodes == nil {
+					exitCodes = map[string]int{}
+				}
+				exitCodes[e.Service] = *e.ExitCode
+			}
+		}
 		if e.Service != "" {
 			// Filter injected services (proxy nodes, ~test node).
 			if svc, ok := inst.spec.Services[e.Service]; ok && svc.Injected {
@@ -855,6 +1129,7 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 	for name := range serviceSet {
 		serviceNames = append(serviceNames, name)
 	}
+	readyDurations := serviceReadyDurations(events, serviceSet)
 	sort.Strings(serviceNames)
 
 	// Compute duration from first to last event.
@@ -866,29 +1141,42 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 	// Write structured JSONL — one event per line for streaming parsers.
 	// The first line is a synthetic log.header for fast scanning by rig ls.
 	jsonlPath := base + ".jsonl"
-	var jb strings.Builder
-	enc := json.NewEncoder(&jb)
-	enc.SetEscapeHTML(false)
+	dropped := inst.log.Dropped()
 
 	header := logHeader{
-		Type:        "log.header",
-		Environment: inst.spec.Name,
-		Outcome:     outcome,
-		Services:    serviceNames,
-		DurationMs:  durationMs,
-		Timestamp:   time.Now(),
-	}
-	if err := enc.Encode(header); err != nil {
-		return "", "", err
-	}
-
-	for _, e := range events {
-		if err := enc.Encode(e); err != nil {
+		Type:            "log.header",
+		Environment:     inst.spec.Name,
+		Outcome:         outcome,
+		Services:        serviceNames,
+		DurationMs:      durationMs,
+		ArtifactRetries: artifactRetries,
+		ExitCodes:       exitCodes,
+		ReadyDurations:  readyDurations,
+		BodyLimit:       inst.spec.ObserveBodyLimit,
+		Metadata:        inst.spec.Metadata,
+		Timestamp:       time.Now(),
+	}
+	if inst.archived {
+		// The streamed log has every event; only its header is replaced.
+		if err := replaceLogHeader(jsonlPath, header); err != nil {
+			return "", "", err
+		}
+	} else {
+		header.DroppedEvents = dropped
+		var jb strings.Builder
+		enc := json.NewEncoder(&jb)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(header); err != nil {
+			return "", "", err
+		}
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				return "", "", err
+			}
+		}
+		if err := os.WriteFile(jsonlPath, []byte(jb.String()), 0o644); err != nil {
 			return "", "", err
 		}
-	}
-	if err := os.WriteFile(jsonlPath, []byte(jb.String()), 0o644); err != nil {
-		return "", "", err
 	}
 
 	// Collect the last few log lines per service so we can include them
@@ -912,6 +1200,7 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 	type edgeKey struct{ source, target string }
 	type edgeStats struct {
 		requests    int
+		httpConns   int // HTTP client connections the requests arrived on
 		connections int
 		grpcCalls   int
 		totalLatMs  float64
@@ -936,6 +1225,9 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 	durSec := durationMs / 1000.0
 	fmt.Fprintf(&b, "rig: %s  %s  %.2fs  [%s]",
 		inst.spec.Name, strings.ToUpper(outcome), durSec, strings.Join(serviceNames, ", "))
+	if dropped > 0 {
+		fmt.Fprintf(&b, "\n  (%d log and traffic events dropped to stay under the event cap)", dropped)
+	}
 	for _, e := range events {
 		// Skip noisy per-line events — the timeline is a structural overview.
 		// Health check probes and service log lines are in the JSONL for detail.
@@ -945,7 +1237,7 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 		elapsed := e.Timestamp.Sub(start).Seconds()
 
 		// Render observed traffic events with source→target detail.
-		if e.Type == EventRequestCompleted && e.Request != nil {
+		if (e.Type == EventRequestCompleted || e.Type == EventRequestMocked) && e.Request != nil {
 			r := e.Request
 			fmt.Fprintf(&b, "\n  %5.2fs  %-22s %-10s → %-10s %-6s %-14s %3d  %.1fms",
 				elapsed, e.Type, r.Source, r.Target, r.Method, r.Path, r.StatusCode, r.LatencyMs)
@@ -964,6 +1256,12 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 			s.bytesOut += c.BytesOut
 			continue
 		}
+		if e.Type == EventHTTPConnectionClosed && e.Connection != nil {
+			// Not a timeline line: it only feeds requests per connection
+			// in the traffic summary.
+			getEdge(e.Connection.Source, e.Connection.Target).httpConns++
+			continue
+		}
 		if e.Type == EventGRPCCallCompleted && e.GRPCCall != nil {
 			g := e.GRPCCall
 			fmt.Fprintf(&b, "\n  %5.2fs  %-22s %-10s → %-10s %s/%s  %s  %.1fms",
@@ -973,8 +1271,29 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 			s.grpcLatMs += g.LatencyMs
 			continue
 		}
-		if e.Type == EventConnectionOpened {
-			// Skip noisy per-open events.
+		if e.Type == EventWebSocketClosed && e.WebSocket != nil {
+			ws := e.WebSocket
+			fmt.Fprintf(&b, "\n  %5.2fs  %-22s %-10s → %-10s %-14s %.1fms  %d↑ %d↓ frames",
+				elapsed, e.Type, ws.Source, ws.Target, ws.Path, ws.DurationMs, ws.FramesIn, ws.FramesOut)
+			s := getEdge(ws.Source, ws.Target)
+			s.connections++
+			s.bytesIn += ws.BytesIn
+			s.bytesOut += ws.BytesOut
+			continue
+		}
+		if e.Type == EventGRPCStreamClosed && e.GRPCStream != nil {
+			g := e.GRPCStream
+			fmt.Fprintf(&b, "\n  %5.2fs  %-22s %-10s → %-10s %s/%s  %s  %.1fms  %d↑ %d↓ msgs",
+				elapsed, e.Type, g.Source, g.Target, g.Service, g.Method, g.GRPCStatus, g.DurationMs, g.MessagesIn, g.MessagesOut)
+			s := getEdge(g.Source, g.Target)
+			s.connections++
+			s.bytesIn += g.RequestSize
+			s.bytesOut += g.ResponseSize
+			continue
+		}
+		if e.Type == EventConnectionOpened || e.Type == EventHTTPConnectionOpened || e.Type == EventWebSocketOpened ||
+			e.Type == EventGRPCStreamOpened || e.Type == EventGRPCStreamMessage {
+			// Skip noisy per-open and per-message events.
 			continue
 		}
 		if e.Type == EventProgressStall && e.Diagnostic != nil {
@@ -993,6 +1312,9 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 			subject = e.Artifact
 		}
 		detail := e.Error
+		if e.ExitCode != nil {
+			detail = fmt.Sprintf("%s (exit %d)", detail, *e.ExitCode)
+		}
 		if subject != "" && detail != "" {
 			fmt.Fprintf(&b, "\n  %5.2fs  %-22s %-12s %s", elapsed, e.Type, subject, detail)
 		} else if subject != "" {
@@ -1036,6 +1358,10 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 				avg := e.stats.totalLatMs / float64(e.stats.requests)
 				fmt.Fprintf(&b, "\n    %-10s → %-10s %d requests   avg %.1fms",
 					e.key.source, e.key.target, e.stats.requests, avg)
+				if e.stats.httpConns > 0 {
+					fmt.Fprintf(&b, "   %d connections (%.1f requests/connection)",
+						e.stats.httpConns, float64(e.stats.requests)/float64(e.stats.httpConns))
+				}
 			}
 			if e.stats.grpcCalls > 0 {
 				avg := e.stats.grpcLatMs / float64(e.stats.grpcCalls)
@@ -1057,6 +1383,57 @@ func (s *Server) writeEventLog(inst *envInstance) (jsonlFile, logFile string, er
 	return jsonlPath, logPath, nil
 }
 
+// logBase returns the path prefix shared by an environment's log files.
+func logBase(logDir string, inst *envInstance) string {
+	safe := strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(inst.spec.Name)
+	return filepath.Join(logDir, safe+"-"+inst.id)
+}
+
+// writeServiceLogs writes each service's output to its own files in
+// {rigDir}/logs/, one per stream: {base}-{service}.stdout.log and
+// {base}-{service}.stderr.log. Streams with no output are skipped, as are
+// injected nodes. Returns the paths written, sorted.
+func (s *Server) writeServiceLogs(inst *envInstance) ([]string, error) {
+	logDir := filepath.Join(s.rigDir, "logs")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		return nil, err
+	}
+	base := logBase(logDir, inst)
+
+	type streamKey struct{ service, stream string }
+	output := make(map[streamKey]*strings.Builder)
+	for _, e := range inst.log.Events() {
+		if e.Type != EventServiceLog || e.Log == nil {
+			continue
+		}
+		if svc, ok := inst.spec.Services[e.Service]; ok && svc.Injected {
+			continue
+		}
+		k := streamKey{e.Service, e.Log.Stream}
+		b, ok := output[k]
+		if !ok {
+			b = &strings.Builder{}
+			output[k] = b
+		}
+		b.WriteString(e.Log.Data)
+		if !strings.HasSuffix(e.Log.Data, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+
+	paths := make([]string, 0, len(output))
+	for k, b := range output {
+		safe := strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(k.service)
+		path := base + "-" + safe + "." + k.stream + ".log"
+		if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
 // pruneOldLogs removes .jsonl and .log files older than maxAge from dir.
 // Best-effort — errors are silently ignored.
 func pruneOldLogs(dir string, maxAge time.Duration) {
@@ -6,8 +6,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -203,6 +207,54 @@ func TestServer_ValidationErrors(t *testing.T) {
 	}
 }
 
+func TestServer_DryRun(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(t)
+
+	post := func(services map[string]any) (int, []string) {
+		t.Helper()
+		body := mustJSON(t, map[string]any{"name": "dry", "services": services})
+		resp, err := http.Post(ts.URL+"/environments?dry_run=true", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var result struct {
+			ValidationErrors []string `json:"validation_errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result.ValidationErrors
+	}
+
+	status, errs := post(map[string]any{
+		"api": map[string]any{"type": "process", "config": map[string]any{"command": "/bin/sh"}},
+	})
+	if status != http.StatusOK || len(errs) != 0 {
+		t.Fatalf("valid spec: status = %d, errors = %v; want 200 and none", status, errs)
+	}
+
+	status, errs = post(map[string]any{
+		"api": map[string]any{"type": "process", "config": map[string]any{"command": "/no/such/binary"}},
+		"web": map[string]any{"type": "container", "config": map[string]any{"image": "Not A/Valid Image"}},
+	})
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", status)
+	}
+	assertContainsError(t, errs, `service "api": command:`)
+	assertContainsError(t, errs, `service "web": image "Not A/Valid Image"`)
+
+	resp, err := http.Get(ts.URL + "/environments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var list []any
+	json.NewDecoder(resp.Body).Decode(&list)
+	if len(list) != 0 {
+		t.Errorf("dry run created environments: %v", list)
+	}
+}
+
 func TestServer_IdleTimer(t *testing.T) {
 	t.Parallel()
 	reg := service.NewRegistry()
@@ -222,6 +274,252 @@ func TestServer_IdleTimer(t *testing.T) {
 	}
 }
 
+func TestServer_PortExhaustion(t *testing.T) {
+	t.Parallel()
+	reg := service.NewRegistry()
+	reg.Register("process", service.Process{})
+	reg.Register("test", service.Test{})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	ports, err := server.NewPortAllocatorRange(port, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server.NewServer(ports, reg, t.TempDir(), 0, t.TempDir()))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Two ingresses can't fit in a one-port range.
+	body := mustJSON(t, map[string]any{
+		"name": "test-port-exhaustion",
+		"services": map[string]any{
+			"api": map[string]any{
+				"type":   "process",
+				"config": mustJSON(t, service.ProcessConfig{Command: "true"}),
+				"ingresses": map[string]any{
+					"http": map[string]any{"protocol": "http"},
+					"grpc": map[string]any{"protocol": "grpc"},
+				},
+			},
+		},
+	})
+	resp, err := http.Post(ts.URL+"/environments", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created map[string]string
+	json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+
+	events := sseEvents(t, ctx, ts.URL+"/environments/"+created["id"]+"/events")
+	failing := waitForEvent(t, ctx, events, func(e server.Event) bool {
+		return e.Type == server.EventEnvironmentFailing
+	})
+	if failing.Service != "api" {
+		t.Errorf("environment.failing service = %q, want api", failing.Service)
+	}
+	if !strings.Contains(failing.Error, "no free ports in range") {
+		t.Errorf("environment.failing error = %q, want port exhaustion", failing.Error)
+	}
+}
+
+func TestServer_StreamLogs(t *testing.T) {
+	t.Parallel()
+	reg := service.NewRegistry()
+	reg.Register("process", service.Process{})
+	reg.Register("test", service.Test{})
+
+	rigDir := t.TempDir()
+	s := server.NewServer(server.NewPortAllocator(), reg, t.TempDir(), 0, rigDir)
+	s.SetStreamLogs(true)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	body := mustJSON(t, map[string]any{
+		"name": "test-stream-logs",
+		"services": map[string]any{
+			"worker": map[string]any{
+				"type":   "process",
+				"config": mustJSON(t, service.ProcessConfig{Command: "sleep"}),
+				"args":   []string{"60"},
+			},
+		},
+	})
+	resp, err := http.Post(ts.URL+"/environments", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created map[string]string
+	json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	id := created["id"]
+
+	events := sseEvents(t, ctx, ts.URL+"/environments/"+id+"/events")
+	waitForEvent(t, ctx, events, func(e server.Event) bool {
+		return e.Type == server.EventEnvironmentUp
+	})
+
+	// readLog returns the header and event types of the streamed log.
+	path := rigDir + "/logs/test-stream-logs-" + id + ".jsonl"
+	var readyDurations map[string]float64
+	readLog := func() (outcome string, types []string) {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var v struct {
+				Type           string             `json:"type"`
+				Outcome        string             `json:"outcome"`
+				ReadyDurations map[string]float64 `json:"ready_durations_ms"`
+			}
+			if err := json.Unmarshal([]byte(line), &v); err != nil {
+				t.Fatalf("line %d: %v", i+1, err)
+			}
+			if i == 0 {
+				if v.Type != "log.header" {
+					t.Fatalf("first line type = %q, want log.header", v.Type)
+				}
+				outcome = v.Outcome
+				readyDurations = v.ReadyDurations
+				continue
+			}
+			types = append(types, v.Type)
+		}
+		return outcome, types
+	}
+
+	// The log is on disk while the environment is still up.
+	outcome, types := readLog()
+	if outcome != "running" {
+		t.Errorf("outcome before teardown = %q, want running", outcome)
+	}
+	if !slices.Contains(types, string(server.EventEnvironmentUp)) {
+		t.Errorf("streamed log has no environment.up: %v", types)
+	}
+
+	delReq, _ := http.NewRequest(http.MethodDelete, ts.URL+"/environments/"+id+"?log=true", nil)
+	delResp, err := http.DefaultClient.Do(delReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delResp.Body.Close()
+
+	// Teardown rewrites the file with the final header.
+	outcome, types = readLog()
+	if outcome != "passed" {
+		t.Errorf("outcome after teardown = %q, want passed", outcome)
+	}
+	if d, ok := readyDurations["worker"]; !ok || d < 0 || len(readyDurations) != 1 {
+		t.Errorf("ready_durations_ms = %v, want just worker", readyDurations)
+	}
+	if !slices.Contains(types, string(server.EventEnvironmentDown)) {
+		t.Errorf("log has no environment.down: %v", types)
+	}
+}
+
+func TestServer_MaxEvents(t *testing.T) {
+	t.Parallel()
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			t.Parallel()
+			reg := service.NewRegistry()
+			reg.Register("process", service.Process{})
+			reg.Register("test", service.Test{})
+
+			rigDir := t.TempDir()
+			s := server.NewServer(server.NewPortAllocator(), reg, t.TempDir(), 0, rigDir)
+			s.SetStreamLogs(stream)
+			// Below the lifecycle events alone, so every log line is dropped.
+			s.SetMaxEvents(5)
+			ts := httptest.NewServer(s)
+			defer ts.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			body := mustJSON(t, map[string]any{
+				"name": "test-max-events",
+				"services": map[string]any{
+					"worker": map[string]any{
+						"type":   "process",
+						"config": mustJSON(t, service.ProcessConfig{Command: "sleep"}),
+						"args":   []string{"60"},
+					},
+				},
+			})
+			resp, err := http.Post(ts.URL+"/environments", "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var created map[string]string
+			json.NewDecoder(resp.Body).Decode(&created)
+			resp.Body.Close()
+			id := created["id"]
+
+			events := sseEvents(t, ctx, ts.URL+"/environments/"+id+"/events")
+			waitForEvent(t, ctx, events, func(e server.Event) bool {
+				return e.Type == server.EventEnvironmentUp
+			})
+			for i := range 20 {
+				ev := mustJSON(t, map[string]any{"type": "service.log", "service": "worker", "log_data": fmt.Sprint("line ", i)})
+				resp, err := http.Post(ts.URL+"/environments/"+id+"/events", "application/json", bytes.NewReader(ev))
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+			}
+
+			delReq, _ := http.NewRequest(http.MethodDelete, ts.URL+"/environments/"+id+"?log=true", nil)
+			delResp, err := http.DefaultClient.Do(delReq)
+			if err != nil {
+				t.Fatal(err)
+			}
+			delResp.Body.Close()
+
+			data, err := os.ReadFile(rigDir + "/logs/test-max-events-" + id + ".jsonl")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var header struct {
+				Outcome       string `json:"outcome"`
+				DroppedEvents int    `json:"dropped_events"`
+			}
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+				t.Fatal(err)
+			}
+			logLines := strings.Count(string(data), `"type":"service.log"`)
+
+			if header.Outcome != "passed" {
+				t.Errorf("outcome = %q, want passed", header.Outcome)
+			}
+			if stream {
+				// The streamed log is the archive: nothing is missing from it.
+				if header.DroppedEvents != 0 || logLines < 20 {
+					t.Errorf("streamed log has dropped_events = %d and %d service.log lines, want 0 and all 20", header.DroppedEvents, logLines)
+				}
+			} else if header.DroppedEvents < 20 || logLines != 0 {
+				t.Errorf("log has dropped_events = %d and %d service.log lines, want >= 20 and none", header.DroppedEvents, logLines)
+			}
+			if !strings.Contains(lines[len(lines)-1], string(server.EventEnvironmentDown)) {
+				t.Errorf("last event = %s, want environment.down", lines[len(lines)-1])
+			}
+		})
+	}
+}
+
 // --- integration tests (share binaries via parent test) ---
 
 // TestServer runs integration tests that exercise the HTTP API with real
@@ -302,6 +600,208 @@ func TestServer(t *testing.T) {
 		delResp.Body.Close()
 	})
 
+	t.Run("EnvironmentUpSnapshot", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		body := mustJSON(t, map[string]any{
+			"name": "test-up-snapshot",
+			"services": map[string]any{
+				"backend": map[string]any{
+					"type":      "process",
+					"config":    mustJSON(t, service.ProcessConfig{Command: echoBin}),
+					"ingresses": map[string]any{"default": map[string]any{"protocol": "http"}},
+				},
+				"api": map[string]any{
+					"type":      "process",
+					"config":    mustJSON(t, service.ProcessConfig{Command: echoBin}),
+					"ingresses": map[string]any{"default": map[string]any{"protocol": "http"}},
+					"egresses":  map[string]any{"backend": map[string]any{"service": "backend"}},
+				},
+			},
+		})
+		resp, err := http.Post(ts.URL+"/environments", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var created map[string]string
+		json.NewDecoder(resp.Body).Decode(&created)
+		resp.Body.Close()
+		id := created["id"]
+		defer func() {
+			delReq, _ := http.NewRequest(http.MethodDelete, ts.URL+"/environments/"+id, nil)
+			if delResp, err := http.DefaultClient.Do(delReq); err == nil {
+				delResp.Body.Close()
+			}
+		}()
+
+		up := waitForEvent(t, ctx, sseEvents(t, ctx, ts.URL+"/environments/"+id+"/events"), func(e server.Event) bool {
+			return e.Type == server.EventEnvironmentUp
+		})
+		if up.Resolved == nil {
+			t.Fatal("environment.up has no resolved snapshot")
+		}
+		if up.Resolved.ID != id {
+			t.Errorf("resolved.ID = %q, want %q", up.Resolved.ID, id)
+		}
+		if _, ok := up.Resolved.Services["~test"]; ok {
+			t.Error("resolved snapshot includes injected ~test service")
+		}
+		backend := up.Resolved.Services["backend"].Ingresses["default"]
+		if backend.HostPort == "" {
+			t.Fatal("backend default ingress not in resolved snapshot")
+		}
+		api := up.Resolved.Services["api"]
+		if api.Status != spec.StatusReady {
+			t.Errorf("api status = %q, want %q", api.Status, spec.StatusReady)
+		}
+		if got := api.Egresses["backend"].HostPort; got != backend.HostPort {
+			t.Errorf("api egress backend = %q, want %q", got, backend.HostPort)
+		}
+	})
+
+	t.Run("Reuse", func(t *testing.T) {
+		t.Parallel()
+
+		create := func(name string) (int, string) {
+			t.Helper()
+			body := mustJSON(t, map[string]any{
+				"name":  name,
+				"reuse": true,
+				"services": map[string]any{
+					"echo": map[string]any{
+						"type":      "process",
+						"config":    mustJSON(t, service.ProcessConfig{Command: echoBin}),
+						"ingresses": map[string]any{"default": map[string]any{"protocol": "http"}},
+					},
+				},
+			})
+			resp, err := http.Post(ts.URL+"/environments", "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			var created map[string]any
+			json.NewDecoder(resp.Body).Decode(&created)
+			id, _ := created["id"].(string)
+			return resp.StatusCode, id
+		}
+		del := func(id string) map[string]any {
+			t.Helper()
+			req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/environments/"+id, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			var result map[string]any
+			json.NewDecoder(resp.Body).Decode(&result)
+			return result
+		}
+
+		status, first := create("test-reuse-a")
+		if status != http.StatusCreated {
+			t.Fatalf("first create: status %d, want 201", status)
+		}
+		status, second := create("test-reuse-b")
+		if status != http.StatusOK || second != first {
+			t.Fatalf("second create: status %d id %q, want 200 and %q", status, second, first)
+		}
+
+		// The shared environment is listed under its spec hash with both refs.
+		var list []map[string]any
+		resp, err := http.Get(ts.URL + "/environments")
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		var hash string
+		for _, e := range list {
+			if e["id"] == first {
+				hash, _ = e["spec_hash"].(string)
+				if e["refs"] != float64(2) {
+					t.Errorf("refs = %v, want 2", e["refs"])
+				}
+			}
+		}
+		resp, err = http.Get(ts.URL + "/environments?spec_hash=" + hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list = nil
+		json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if len(list) != 1 || list[0]["id"] != first {
+			t.Errorf("spec_hash lookup = %v, want only %s", list, first)
+		}
+
+		if got := del(first)["status"]; got != "released" {
+			t.Errorf("first delete status = %v, want released", got)
+		}
+		if got := del(first)["status"]; got != "destroyed" {
+			t.Errorf("last delete status = %v, want destroyed", got)
+		}
+	})
+
+	t.Run("DependsOn", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		echo := func(dependsOn ...string) map[string]any {
+			return map[string]any{
+				"type":       "process",
+				"config":     mustJSON(t, service.ProcessConfig{Command: echoBin}),
+				"ingresses":  map[string]any{"default": map[string]any{"protocol": "http"}},
+				"depends_on": dependsOn,
+			}
+		}
+		body := mustJSON(t, map[string]any{
+			"name": "test-depends-on",
+			"services": map[string]any{
+				"migrate": echo(),
+				"worker":  echo("migrate"),
+			},
+		})
+		resp, err := http.Post(ts.URL+"/environments", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var created map[string]string
+		json.NewDecoder(resp.Body).Decode(&created)
+		resp.Body.Close()
+		id := created["id"]
+		defer func() {
+			delReq, _ := http.NewRequest(http.MethodDelete, ts.URL+"/environments/"+id, nil)
+			if delResp, err := http.DefaultClient.Do(delReq); err == nil {
+				delResp.Body.Close()
+			}
+		}()
+
+		events := collectUntil(t, ctx, sseEvents(t, ctx, ts.URL+"/environments/"+id+"/events"), func(e server.Event) bool {
+			return e.Type == server.EventEnvironmentUp || e.Type == server.EventEnvironmentFailing
+		})
+		ready, ok := findEvent(events, func(e server.Event) bool {
+			return e.Type == server.EventServiceReady && e.Service == "migrate"
+		})
+		if !ok {
+			t.Fatal("no service.ready for migrate")
+		}
+		published, ok := findEvent(events, func(e server.Event) bool {
+			return e.Type == server.EventIngressPublished && e.Service == "worker"
+		})
+		if !ok {
+			t.Fatal("no ingress.published for worker")
+		}
+		if published.Seq < ready.Seq {
+			t.Errorf("worker published at seq %d, before migrate was ready at seq %d", published.Seq, ready.Seq)
+		}
+	})
+
 	t.Run("FailurePropagation", func(t *testing.T) {
 		t.Parallel()
 
@@ -404,6 +904,9 @@ func TestServer(t *testing.T) {
 		if !strings.Contains(failed.Error, "exit status") {
 			t.Errorf("service.failed error = %q, want it to contain 'exit status'", failed.Error)
 		}
+		if failed.ExitCode == nil || *failed.ExitCode != 1 {
+			t.Errorf("service.failed exit_code = %v, want 1", failed.ExitCode)
+		}
 
 		// environment.failing must appear with the root cause.
 		failing, ok := findEvent(all, func(e server.Event) bool {
@@ -434,6 +937,151 @@ func TestServer(t *testing.T) {
 		}
 	})
 
+	t.Run("EndpointsEnv", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		// reader waits on backend through its egress, so backend's address
+		// is published by the time reader starts.
+		body := mustJSON(t, map[string]any{
+			"name": "test-endpoints-env",
+			"services": map[string]any{
+				"backend": map[string]any{
+					"type":      "process",
+					"config":    mustJSON(t, service.ProcessConfig{Command: echoBin}),
+					"ingresses": map[string]any{"default": map[string]any{"protocol": "http"}},
+				},
+				"reader": map[string]any{
+					"type":     "process",
+					"config":   mustJSON(t, service.ProcessConfig{Command: "/bin/sh"}),
+					"args":     []string{"-c", "printenv RIG_ENDPOINTS > ${RIG_TEMP_DIR}/endpoints.json; exec sleep 60"},
+					"egresses": map[string]any{"backend": map[string]any{"service": "backend"}},
+				},
+			},
+			"observe": true,
+		})
+		resp, err := http.Post(ts.URL+"/environments", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var created map[string]string
+		json.NewDecoder(resp.Body).Decode(&created)
+		resp.Body.Close()
+		id := created["id"]
+		defer func() {
+			delReq, _ := http.NewRequest(http.MethodDelete, ts.URL+"/environments/"+id, nil)
+			if delResp, err := http.DefaultClient.Do(delReq); err == nil {
+				delResp.Body.Close()
+			}
+		}()
+
+		up := waitForEvent(t, ctx, sseEvents(t, ctx, ts.URL+"/environments/"+id+"/events"), func(e server.Event) bool {
+			return e.Type == server.EventEnvironmentUp
+		})
+
+		path := filepath.Join(up.EnvDir, "reader", "endpoints.json")
+		var data []byte
+		for {
+			data, err = os.ReadFile(path)
+			if err == nil && len(data) > 0 {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				t.Fatalf("reader never wrote %s: %v", path, err)
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+		var endpoints map[string]map[string]string
+		if err := json.Unmarshal(data, &endpoints); err != nil {
+			t.Fatalf("RIG_ENDPOINTS = %s: %v", data, err)
+		}
+		addr := endpoints["backend"]["default"]
+		if addr == "" {
+			t.Fatalf("RIG_ENDPOINTS = %s, want backend's default ingress", data)
+		}
+		getResp, err := http.Get("http://" + addr + "/")
+		if err != nil {
+			t.Fatalf("GET backend at RIG_ENDPOINTS address: %v", err)
+		}
+		getResp.Body.Close()
+		for name := range endpoints {
+			if strings.Contains(name, "~") {
+				t.Errorf("RIG_ENDPOINTS includes injected node %q", name)
+			}
+		}
+	})
+
+	t.Run("ServiceRestartEvents", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		envSpec := map[string]any{
+			"name": "test-restart-events",
+			"services": map[string]any{
+				"flaky": map[string]any{
+					"type":            "process",
+					"config":          mustJSON(t, service.ProcessConfig{Command: failBin}),
+					"restart_on_exit": 2,
+					"ingresses": map[string]any{
+						"default": map[string]any{"protocol": "http"},
+					},
+				},
+			},
+		}
+		body := mustJSON(t, envSpec)
+		resp, err := http.Post(ts.URL+"/environments", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var created map[string]string
+		json.NewDecoder(resp.Body).Decode(&created)
+		id := created["id"]
+		defer func() {
+			req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/environments/"+id, nil)
+			http.DefaultClient.Do(req)
+		}()
+
+		events := sseEvents(t, ctx, ts.URL+"/environments/"+id+"/events")
+		all := collectUntil(t, ctx, events, func(e server.Event) bool {
+			return e.Type == server.EventEnvironmentDown
+		})
+
+		var restarts []string
+		for _, e := range all {
+			if e.Type != server.EventServiceRestarted {
+				continue
+			}
+			if e.ExitCode == nil || *e.ExitCode != 1 {
+				t.Errorf("service.restarted exit_code = %v, want 1", e.ExitCode)
+			}
+			restarts = append(restarts, e.Message)
+		}
+		if want := []string{"restart 1 of 2", "restart 2 of 2"}; !slices.Equal(restarts, want) {
+			t.Errorf("service.restarted messages = %q, want %q", restarts, want)
+		}
+
+		// The exit after the last restart fails the environment.
+		failed, ok := findEvent(all, func(e server.Event) bool {
+			return e.Type == server.EventServiceFailed && e.Service == "flaky"
+		})
+		if !ok {
+			t.Fatal("no service.failed event for 'flaky'")
+		}
+		if !strings.Contains(failed.Error, "after 2 restarts") {
+			t.Errorf("service.failed error = %q, want it to mention the restarts", failed.Error)
+		}
+		if failed.ExitCode == nil || *failed.ExitCode != 1 {
+			t.Errorf("service.failed exit_code = %v, want 1", failed.ExitCode)
+		}
+	})
+
 	t.Run("CrashSummary", func(t *testing.T) {
 		t.Parallel()
 
@@ -479,6 +1127,9 @@ func TestServer(t *testing.T) {
 		})
 
 		summary := down.Message
+		if down.Outcome != "crashed" {
+			t.Errorf("environment.down outcome = %q, want crashed", down.Outcome)
+		}
 
 		// Summary should include the service name and log output.
 		if !strings.Contains(summary, "broken output:") {
@@ -499,9 +1150,10 @@ func TestServer(t *testing.T) {
 		// DELETE with ?log=true to get the condensed explain output.
 		// Both paths (buildDownSummary and explain.Condensed) should
 		// surface the failed service name and its stderr — this catches
-		// drift between the two implementations.
+		// drift between the two implementations. split_logs also writes
+		// each service's output to its own files.
 		delReq, _ := http.NewRequest(http.MethodDelete,
-			ts.URL+"/environments/"+id+"?log=true", nil)
+			ts.URL+"/environments/"+id+"?log=true&split_logs=true", nil)
 		delResp, err := http.DefaultClient.Do(delReq)
 		if err != nil {
 			t.Fatal(err)
@@ -522,6 +1174,24 @@ func TestServer(t *testing.T) {
 		if !strings.Contains(condensed, "intentional failure") {
 			t.Errorf("condensed summary missing stderr output, got:\n%s", condensed)
 		}
+
+		var stderrLog string
+		files, _ := result["service_log_files"].([]any)
+		for _, f := range files {
+			if p, _ := f.(string); strings.HasSuffix(p, "-broken.stderr.log") {
+				stderrLog = p
+			}
+		}
+		if stderrLog == "" {
+			t.Fatalf("service_log_files = %v, want a broken.stderr.log", files)
+		}
+		data, err := os.ReadFile(stderrLog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "intentional failure") {
+			t.Errorf("%s missing stderr output, got:\n%s", stderrLog, data)
+		}
 	})
 
 	t.Run("HealthCheckTimeoutDiagnostics", func(t *testing.T) {
@@ -591,6 +1261,9 @@ func TestServer(t *testing.T) {
 		if !strings.Contains(failed.Error, "200ms") {
 			t.Errorf("timeout error should include the timeout duration, got: %q", failed.Error)
 		}
+		if !strings.Contains(failed.Error, "sleeper not ready within its 200ms timeout") {
+			t.Errorf("timeout error should name the service and its own timeout, got: %q", failed.Error)
+		}
 	})
 
 	t.Run("MultiServiceCrashCleanup", func(t *testing.T) {
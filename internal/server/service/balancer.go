@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/matgreaves/rig/internal/spec"
+	"github.com/matgreaves/run"
+)
+
+// BalancerConfig is the type-specific config for a balancer node.
+type BalancerConfig struct {
+	Source string `json:"source"` // consuming service name or "~test"
+	Target string `json:"target"` // scaled service name, before expansion
+}
+
+// BalancerBackendPrefix prefixes the egress names of a balancer's
+// backends: "backend-0", "backend-1", and so on, one per instance.
+const BalancerBackendPrefix = "backend-"
+
+// Balancer implements service.Type for the nodes that spread an egress
+// across the instances of a scaled service. They are injected by the spec
+// transformation, one per edge, and relay each accepted TCP connection to
+// the next instance in turn.
+type Balancer struct{}
+
+// Publish copies the protocol and attributes of the first backend, like a
+// proxy node, so the balancer is a drop-in stand-in for any instance.
+func (Balancer) Publish(_ context.Context, params PublishParams) (map[string]spec.Endpoint, error) {
+	first, ok := params.Egresses[BalancerBackendPrefix+"0"]
+	if !ok {
+		return nil, fmt.Errorf("balancer: no resolved egress %q", BalancerBackendPrefix+"0")
+	}
+	port, ok := params.Ports["default"]
+	if !ok {
+		return nil, fmt.Errorf("balancer: no port allocated for ingress \"default\"")
+	}
+
+	var attrs map[string]any
+	if first.Attributes != nil {
+		attrs = make(map[string]any, len(first.Attributes))
+		for k, v := range first.Attributes {
+			attrs[k] = v
+		}
+	}
+	return map[string]spec.Endpoint{
+		"default": {
+			HostPort:   fmt.Sprintf("127.0.0.1:%d", port),
+			Protocol:   first.Protocol,
+			Attributes: attrs,
+		},
+	}, nil
+}
+
+// Runner accepts connections on the balancer's ingress and relays each to
+// the backends round-robin.
+func (Balancer) Runner(params StartParams) run.Runner {
+	ln := params.Reservation.Take("default")
+	return run.Func(func(ctx context.Context) error {
+		if ln != nil {
+			defer ln.Close()
+		}
+
+		var cfg BalancerConfig
+		if err := json.Unmarshal(params.Spec.Config, &cfg); err != nil {
+			return fmt.Errorf("balancer: unmarshal config: %w", err)
+		}
+		backends := BalancerBackends(params.Egresses)
+		if len(backends) == 0 {
+			return fmt.Errorf("balancer %s→%s: no backends", cfg.Source, cfg.Target)
+		}
+
+		if ln == nil {
+			ingress, ok := params.Ingresses["default"]
+			if !ok {
+				return fmt.Errorf("balancer: no resolved ingress \"default\"")
+			}
+			var err error
+			if ln, err = net.Listen("tcp", ingress.HostPort); err != nil {
+				return fmt.Errorf("balancer %s→%s: listen: %w", cfg.Source, cfg.Target, err)
+			}
+			defer ln.Close()
+		}
+		go func() {
+			<-ctx.Done()
+			ln.Close()
+		}()
+
+		var next atomic.Uint64
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("balancer %s→%s: accept: %w", cfg.Source, cfg.Target, err)
+			}
+			backend := backends[(next.Add(1)-1)%uint64(len(backends))]
+			go relayConn(ctx, conn, backend.HostPort)
+		}
+	})
+}
+
+// BalancerBackends returns a balancer's backend endpoints in instance
+// order.
+func BalancerBackends(egresses map[string]spec.Endpoint) []spec.Endpoint {
+	type backend struct {
+		index int
+		ep    spec.Endpoint
+	}
+	var list []backend
+	for name, ep := range egresses {
+		i, err := strconv.Atoi(strings.TrimPrefix(name, BalancerBackendPrefix))
+		if !strings.HasPrefix(name, BalancerBackendPrefix) || err != nil {
+			continue
+		}
+		list = append(list, backend{i, ep})
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].index < list[b].index })
+	out := make([]spec.Endpoint, len(list))
+	for i, b := range list {
+		out[i] = b.ep
+	}
+	return out
+}
+
+// relayConn copies bytes both ways between client and addr until either
+// side closes or ctx is cancelled.
+func relayConn(ctx context.Context, client net.Conn, addr string) {
+	target, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		client.Close()
+		return
+	}
+	stop := context.AfterFunc(ctx, func() {
+		client.Close()
+		target.Close()
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	pipe := func(dst, src net.Conn) {
+		defer wg.Done()
+		io.Copy(dst, src)
+		if tc, ok := dst.(*net.TCPConn); ok {
+			tc.CloseWrite()
+		}
+	}
+	go pipe(target, client)
+	go pipe(client, target)
+	wg.Wait()
+	client.Close()
+	target.Close()
+}
@@ -1,22 +1,27 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/matgreaves/rig/internal/server/artifact"
 	"github.com/matgreaves/rig/internal/server/dockerutil"
+	"github.com/matgreaves/rig/internal/server/ready"
 	"github.com/matgreaves/rig/internal/spec"
 	"github.com/matgreaves/run"
 	"github.com/matgreaves/run/onexit"
@@ -32,14 +37,40 @@ const (
 // ContainerConfig is the type-specific config for "container" services.
 type ContainerConfig struct {
 	// Image is the Docker image reference (e.g. "postgres:16").
+	// May be empty when ImageTarball is set, in which case the image
+	// tagged in the tarball is used.
 	Image string `json:"image"`
 
+	// ImageTarball is the path to a `docker save` tarball to load the
+	// image from instead of pulling it. Relative paths are resolved
+	// against the environment dir.
+	ImageTarball string `json:"image_tarball,omitempty"`
+
 	// Cmd overrides the container's default command.
 	Cmd []string `json:"cmd,omitempty"`
 
 	// Env sets additional environment variables on the container.
 	// These are merged with the standard RIG_* wiring env vars.
 	Env map[string]string `json:"env,omitempty"`
+
+	// DockerHealthcheck gates readiness on the image's own HEALTHCHECK
+	// reporting "healthy" instead of probing the ingress ports.
+	DockerHealthcheck bool `json:"docker_healthcheck,omitempty"`
+
+	// ReadyExec gates readiness on a command run inside the container
+	// (e.g. ["pg_isready", "-U", "postgres"]) exiting 0, instead of
+	// probing the ingress ports. It is retried until it succeeds or the
+	// ready timeout expires.
+	ReadyExec []string `json:"ready_exec,omitempty"`
+
+	// Privileged runs the container in privileged mode, with every
+	// capability and access to the host's devices. CapAdd grants
+	// individual Linux capabilities (e.g. "NET_ADMIN") instead, and
+	// Sysctls sets namespaced kernel parameters. All three weaken the
+	// isolation between the container and the host.
+	Privileged bool              `json:"privileged,omitempty"`
+	CapAdd     []string          `json:"cap_add,omitempty"`
+	Sysctls    map[string]string `json:"sysctls,omitempty"`
 }
 
 // ContainerName returns the Docker container name for a service instance.
@@ -51,6 +82,93 @@ func ContainerName(instanceID, serviceName string) string {
 // It runs a Docker container with host-mapped ports.
 type Container struct{}
 
+// ReadyCheck returns the default protocol-based checker unless the service
+// opted in to ReadyExec, in which case readiness is the command succeeding,
+// or to DockerHealthcheck, in which case readiness follows the container's
+// health status as reported by Docker.
+func (Container) ReadyCheck(params ReadyCheckParams) ready.Checker {
+	var cfg ContainerConfig
+	if params.Spec.Config != nil {
+		json.Unmarshal(params.Spec.Config, &cfg)
+	}
+	if len(cfg.ReadyExec) > 0 {
+		return &execReadyCheck{
+			containerName: ContainerName(params.InstanceID, params.ServiceName),
+			command:       cfg.ReadyExec,
+		}
+	}
+	if !cfg.DockerHealthcheck {
+		var readySpec *spec.ReadySpec
+		if ing, ok := params.Spec.Ingresses[params.IngressName]; ok {
+			readySpec = ing.Ready
+		}
+		return ready.ForEndpoint(params.Endpoint, readySpec)
+	}
+	return &dockerHealthCheck{
+		containerName: ContainerName(params.InstanceID, params.ServiceName),
+	}
+}
+
+// dockerHealthCheck polls the container's Docker health status.
+type dockerHealthCheck struct {
+	containerName string
+}
+
+func (c *dockerHealthCheck) Check(ctx context.Context, addr string) error {
+	cli, err := dockerutil.Client()
+	if err != nil {
+		return fmt.Errorf("docker health: docker client: %w", err)
+	}
+	inspect, err := cli.ContainerInspect(ctx, c.containerName)
+	if err != nil {
+		return fmt.Errorf("docker health: %w (not ready)", err)
+	}
+	if inspect.State == nil || !inspect.State.Running {
+		return fmt.Errorf("docker health: container not running (not ready)")
+	}
+	return dockerHealthStatus(inspect.State.Health)
+}
+
+// dockerHealthStatus maps a container's health state to a readiness error.
+// A container without a HEALTHCHECK never reports health, so that case is
+// permanent rather than waiting out the timeout.
+func dockerHealthStatus(h *types.Health) error {
+	if h == nil || h.Status == types.NoHealthcheck {
+		return ready.Permanent(fmt.Errorf("docker health: image declares no HEALTHCHECK; remove UseDockerHealthcheck() to use the default port check"))
+	}
+	if h.Status == types.Healthy {
+		return nil
+	}
+	if n := len(h.Log); n > 0 && h.Log[n-1].ExitCode != 0 {
+		out := strings.TrimSpace(h.Log[n-1].Output)
+		return fmt.Errorf("docker health: %s (exit %d: %s)", h.Status, h.Log[n-1].ExitCode, out)
+	}
+	return fmt.Errorf("docker health: %s", h.Status)
+}
+
+// execReadyCheck runs a command inside the container; exit 0 is ready.
+type execReadyCheck struct {
+	containerName string
+	command       []string
+}
+
+func (c *execReadyCheck) Check(ctx context.Context, addr string) error {
+	var out bytes.Buffer
+	if err := ExecInContainer(ctx, c.containerName, c.command, &out, &out); err != nil {
+		return execReadyError(err, out.String())
+	}
+	return nil
+}
+
+// execReadyError adds the command's combined output, if any, to a failed
+// ready exec so health.check_failed events say why it isn't ready.
+func execReadyError(err error, output string) error {
+	if output = strings.TrimSpace(output); output != "" {
+		return fmt.Errorf("ready exec: %w: %s", err, output)
+	}
+	return fmt.Errorf("ready exec: %w", err)
+}
+
 // ExecHookConfig is the Config payload for "exec" hooks.
 type ExecHookConfig struct {
 	Command []string `json:"command"`
@@ -60,6 +178,12 @@ type ExecHookConfig struct {
 // Output is written to stdout/stderr. Returns an error if the command exits
 // with a non-zero status.
 func ExecInContainer(ctx context.Context, containerName string, cmd []string, stdout, stderr io.Writer) error {
+	return execInContainerEnv(ctx, containerName, cmd, nil, stdout, stderr)
+}
+
+// execInContainerEnv is ExecInContainer with extra environment variables
+// ("KEY=value") set for the command only.
+func execInContainerEnv(ctx context.Context, containerName string, cmd, env []string, stdout, stderr io.Writer) error {
 	cli, err := dockerutil.Client()
 	if err != nil {
 		return fmt.Errorf("exec: docker client: %w", err)
@@ -67,6 +191,7 @@ func ExecInContainer(ctx context.Context, containerName string, cmd []string, st
 
 	exec, err := cli.ContainerExecCreate(ctx, containerName, container.ExecOptions{
 		Cmd:          cmd,
+		Env:          env,
 		AttachStdout: true,
 		AttachStderr: true,
 	})
@@ -139,7 +264,8 @@ func (Container) Init(ctx context.Context, params InitParams) error {
 	return ExecInContainer(ctx, containerName, cfg.Command, params.Stdout, params.Stderr)
 }
 
-// Artifacts returns a DockerPull artifact for the configured image.
+// Artifacts returns a DockerPull artifact for the configured image, or a
+// DockerLoad artifact when the image comes from a local tarball.
 func (Container) Artifacts(params ArtifactParams) ([]artifact.Artifact, error) {
 	var cfg ContainerConfig
 	if params.Spec.Config == nil {
@@ -148,6 +274,9 @@ func (Container) Artifacts(params ArtifactParams) ([]artifact.Artifact, error) {
 	if err := json.Unmarshal(params.Spec.Config, &cfg); err != nil {
 		return nil, fmt.Errorf("service %q: invalid container config: %w", params.ServiceName, err)
 	}
+	if cfg.ImageTarball != "" {
+		return tarballArtifacts(params.ServiceName, cfg, params.Dir)
+	}
 	if cfg.Image == "" {
 		return nil, fmt.Errorf("service %q: container config missing required \"image\" field", params.ServiceName)
 	}
@@ -157,6 +286,43 @@ func (Container) Artifacts(params ArtifactParams) ([]artifact.Artifact, error) {
 	}}, nil
 }
 
+// tarballArtifacts validates the tarball and returns the DockerLoad artifact
+// for it. An empty image defaults to the single image tagged in the tarball.
+func tarballArtifacts(serviceName string, cfg ContainerConfig, dir string) ([]artifact.Artifact, error) {
+	path := resolvePath(cfg.ImageTarball, dir)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("service %q: image tarball: %w", serviceName, err)
+	}
+	image := cfg.Image
+	if image == "" {
+		tags, err := artifact.TarballImages(path)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", serviceName, err)
+		}
+		if len(tags) > 1 {
+			return nil, fmt.Errorf("service %q: image tarball %s contains %d images (%s); set the image to choose one",
+				serviceName, path, len(tags), strings.Join(tags, ", "))
+		}
+		image = tags[0]
+	}
+	return []artifact.Artifact{{
+		Key:      tarballArtifactKey(path),
+		Resolver: artifact.DockerLoad{Tarball: path, Image: image},
+	}}, nil
+}
+
+func tarballArtifactKey(path string) string {
+	return "docker-load:" + path
+}
+
+// resolvePath resolves a relative path against the environment dir.
+func resolvePath(path, dir string) string {
+	if dir != "" && !filepath.IsAbs(path) {
+		return filepath.Clean(filepath.Join(dir, path))
+	}
+	return path
+}
+
 // Publish resolves ingress endpoints using host-allocated ports.
 func (Container) Publish(_ context.Context, params PublishParams) (map[string]spec.Endpoint, error) {
 	return PublishLocalEndpoints(params)
@@ -174,6 +340,13 @@ func (Container) Runner(params StartParams) run.Runner {
 		}
 	}
 
+	// A tarball image may be untagged in the config; the artifact phase
+	// recorded which image it loaded.
+	if cfg.ImageTarball != "" && cfg.Image == "" {
+		key := tarballArtifactKey(resolvePath(cfg.ImageTarball, params.Dir))
+		cfg.Image = params.Artifacts[key].Meta["image"]
+	}
+
 	return run.Func(func(ctx context.Context) error {
 		cli, err := dockerutil.Client()
 		if err != nil {
@@ -191,6 +364,9 @@ func (Container) Runner(params StartParams) run.Runner {
 		hostIP := dockerHostIP()
 		adjustedIngresses := adjustIngressEndpoints(params.Ingresses, params.Spec.Ingresses)
 		adjustedEgresses := adjustEgressEndpoints(params.Egresses, hostIP)
+		for name, ep := range params.NetworkEgresses {
+			adjustedEgresses[name] = ep
+		}
 		adjustedEnv, err := params.BuildEnv(adjustedIngresses, adjustedEgresses)
 		if err != nil {
 			return fmt.Errorf("build container env: %w", err)
@@ -201,6 +377,7 @@ func (Container) Runner(params StartParams) run.Runner {
 		adjustedEnv["RIG_TEMP_DIR"] = containerTempPath
 		adjustedEnv["RIG_ENV_DIR"] = containerEnvPath
 		adjustTempDirsInWiring(adjustedEnv)
+		adjustEndpointsEnv(adjustedEnv, hostIP)
 
 		// Merge user-specified env vars (from container config) on top.
 		for k, v := range cfg.Env {
@@ -220,7 +397,8 @@ func (Container) Runner(params StartParams) run.Runner {
 		}
 
 		// Expand command and arg templates against the container-adjusted env
-		// so that ${RIG_TEMP_DIR}, host addresses, etc. resolve correctly.
+		// so that ${RIG_TEMP_DIR}, egress addresses (${RIG_EGRESS_DB_HOST}),
+		// etc. resolve correctly.
 		cmd := expandAll(cfg.Cmd, adjustedEnv)
 		args := expandAll(params.Args, adjustedEnv)
 		switch {
@@ -247,12 +425,27 @@ func (Container) Runner(params StartParams) run.Runner {
 				},
 			},
 		}
+		hostConfig.Privileged = cfg.Privileged
+		hostConfig.CapAdd = cfg.CapAdd
+		hostConfig.Sysctls = cfg.Sysctls
+
 		// On Linux, ensure host.docker.internal resolves to the host.
 		if runtime.GOOS == "linux" {
 			hostConfig.ExtraHosts = []string{"host.docker.internal:host-gateway"}
 		}
 
-		resp, err := cli.ContainerCreate(ctx, config, hostConfig, nil, nil, containerName)
+		// With a container network, attach to the shared network with
+		// the service name as alias so peers can address it directly.
+		var networkConfig *network.NetworkingConfig
+		if params.Network != "" {
+			networkConfig = &network.NetworkingConfig{
+				EndpointsConfig: map[string]*network.EndpointSettings{
+					params.Network: {Aliases: []string{params.ServiceName}},
+				},
+			}
+		}
+
+		resp, err := cli.ContainerCreate(ctx, config, hostConfig, networkConfig, nil, containerName)
 		if err != nil {
 			return fmt.Errorf("service %q: create container: %w", params.ServiceName, err)
 		}
@@ -267,6 +460,11 @@ func (Container) Runner(params StartParams) run.Runner {
 			// Use a background context for cleanup — the original ctx may already be cancelled.
 			cleanCtx := context.Background()
 			timeout := 10 // seconds
+			if st := params.Spec.StopTimeout; st != nil {
+				// Docker takes whole seconds; round up so a sub-second
+				// timeout still allows a graceful stop.
+				timeout = int((st.Duration + time.Second - 1) / time.Second)
+			}
 			cli.ContainerStop(cleanCtx, containerID, container.StopOptions{Timeout: &timeout})
 			cli.ContainerRemove(cleanCtx, containerID, container.RemoveOptions{Force: true})
 			// Graceful cleanup succeeded — cancel the onexit backup.
@@ -304,7 +502,7 @@ func (Container) Runner(params StartParams) run.Runner {
 		case result := <-waitCh:
 			<-logDone // drain remaining logs
 			if result.StatusCode != 0 {
-				return fmt.Errorf("service %q: container exited with code %d", params.ServiceName, result.StatusCode)
+				return &ExitError{Service: params.ServiceName, Code: int(result.StatusCode)}
 			}
 			return nil
 		case err := <-errCh:
@@ -369,6 +567,14 @@ func adjustEgressEndpoints(egresses map[string]spec.Endpoint, hostIP string) map
 	return adjusted
 }
 
+// adjustEndpointsEnv points the host addresses in RIG_ENDPOINTS at the
+// Docker host, as adjustEgressEndpoints does for egresses.
+func adjustEndpointsEnv(env map[string]string, hostIP string) {
+	if raw, ok := env["RIG_ENDPOINTS"]; ok {
+		env["RIG_ENDPOINTS"] = strings.ReplaceAll(raw, "127.0.0.1", hostIP)
+	}
+}
+
 // envMapToSlice converts a map of env vars to a slice of "KEY=VALUE" strings.
 func envMapToSlice(env map[string]string) []string {
 	out := make([]string, 0, len(env))
@@ -1,10 +1,17 @@
 package service
 
 import (
+	"archive/tar"
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/docker/docker/api/types"
+	"github.com/matgreaves/rig/internal/server/artifact"
+	"github.com/matgreaves/rig/internal/server/ready"
 	"github.com/matgreaves/rig/internal/spec"
 )
 
@@ -176,6 +183,21 @@ func TestAdjustEgressEndpoints_DoesNotMutateOriginal(t *testing.T) {
 	}
 }
 
+func TestAdjustEndpointsEnv(t *testing.T) {
+	env := map[string]string{"RIG_ENDPOINTS": `{"db":{"default":"127.0.0.1:5432"}}`}
+	adjustEndpointsEnv(env, "host.docker.internal")
+	if want := `{"db":{"default":"host.docker.internal:5432"}}`; env["RIG_ENDPOINTS"] != want {
+		t.Errorf("RIG_ENDPOINTS = %s, want %s", env["RIG_ENDPOINTS"], want)
+	}
+
+	// No RIG_ENDPOINTS leaves the env alone.
+	empty := map[string]string{}
+	adjustEndpointsEnv(empty, "host.docker.internal")
+	if _, ok := empty["RIG_ENDPOINTS"]; ok {
+		t.Error("adjustEndpointsEnv added RIG_ENDPOINTS")
+	}
+}
+
 func TestExpandAll(t *testing.T) {
 	env := map[string]string{
 		"HOST": "0.0.0.0",
@@ -320,3 +342,92 @@ func TestAdjustTempDirsInWiring_PreservesOtherFields(t *testing.T) {
 		t.Error("ingresses field was lost")
 	}
 }
+
+func TestContainerArtifacts_ImageTarball(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "api.tar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := `[{"RepoTags":["myteam/api:ci"]}]`
+	tw := tar.NewWriter(f)
+	tw.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0o644, Size: int64(len(manifest))})
+	tw.Write([]byte(manifest))
+	tw.Close()
+	f.Close()
+
+	artifactsFor := func(cfg string) ([]artifact.Artifact, error) {
+		return Container{}.Artifacts(ArtifactParams{
+			ServiceName: "api",
+			Spec:        spec.Service{Type: "container", Config: json.RawMessage(cfg)},
+			Dir:         dir,
+		})
+	}
+
+	// An empty image defaults to the one tagged in the tarball.
+	arts, err := artifactsFor(`{"image":"","image_tarball":"api.tar"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	load, ok := arts[0].Resolver.(artifact.DockerLoad)
+	if !ok {
+		t.Fatalf("resolver = %T, want artifact.DockerLoad", arts[0].Resolver)
+	}
+	if load.Image != "myteam/api:ci" || load.Tarball != filepath.Join(dir, "api.tar") {
+		t.Errorf("resolver = %+v", load)
+	}
+
+	_, err = artifactsFor(`{"image":"","image_tarball":"missing.tar"}`)
+	if err == nil || !strings.Contains(err.Error(), "image tarball") {
+		t.Errorf("missing tarball: err = %v", err)
+	}
+}
+
+func TestDockerHealthStatus(t *testing.T) {
+	if err := dockerHealthStatus(&types.Health{Status: types.Healthy}); err != nil {
+		t.Errorf("healthy: %v", err)
+	}
+
+	err := dockerHealthStatus(&types.Health{Status: types.Starting})
+	if err == nil || ready.IsPermanent(err) {
+		t.Errorf("starting: err = %v, want retryable error", err)
+	}
+
+	err = dockerHealthStatus(&types.Health{
+		Status: types.Unhealthy,
+		Log:    []*types.HealthcheckResult{{ExitCode: 1, Output: "connection refused\n"}},
+	})
+	if err == nil || ready.IsPermanent(err) || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("unhealthy: err = %v, want retryable error with probe output", err)
+	}
+
+	for _, h := range []*types.Health{nil, {Status: types.NoHealthcheck}} {
+		err := dockerHealthStatus(h)
+		if !ready.IsPermanent(err) || !strings.Contains(err.Error(), "UseDockerHealthcheck") {
+			t.Errorf("no healthcheck: err = %v, want permanent error naming the option", err)
+		}
+	}
+}
+
+func TestContainerReadyCheck_ReadyExec(t *testing.T) {
+	params := ReadyCheckParams{
+		ServiceName: "queue",
+		InstanceID:  "abc",
+		IngressName: "default",
+		Spec: spec.Service{
+			Config: json.RawMessage(`{"image":"rabbitmq:3","ready_exec":["rabbitmqctl","status"]}`),
+		},
+	}
+	rc, ok := Container{}.ReadyCheck(params).(*execReadyCheck)
+	if !ok {
+		t.Fatalf("checker = %T, want *execReadyCheck", Container{}.ReadyCheck(params))
+	}
+	if rc.containerName != "rig-abc-queue" || strings.Join(rc.command, " ") != "rabbitmqctl status" {
+		t.Errorf("checker = %+v", rc)
+	}
+
+	err := execReadyError(errors.New(`exec [rabbitmqctl status]: exit code 69`), "Error: unable to perform an operation on node\n")
+	if err == nil || ready.IsPermanent(err) || !strings.Contains(err.Error(), "exit code 69: Error: unable to perform") {
+		t.Errorf("err = %v, want retryable error with command output", err)
+	}
+}
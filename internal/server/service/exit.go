@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// ExitError is returned by runners whose process or container exited with a
+// non-zero status.
+type ExitError struct {
+	Service string
+	Code    int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("service %q: container exited with code %d", e.Service, e.Code)
+}
+
+// ExitCode extracts the exit code from a runner error. It recognises
+// *ExitError (containers) and *exec.ExitError (processes). A process killed
+// by a signal reports 128+signal, matching shell and Docker conventions —
+// e.g. 137 for SIGKILL. Returns false if err carries no exit status.
+func ExitCode(err error) (int, bool) {
+	var ee *ExitError
+	if errors.As(err, &ee) {
+		return ee.Code, true
+	}
+	var pe *exec.ExitError
+	if errors.As(err, &pe) {
+		if ws, ok := pe.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return 128 + int(ws.Signal()), true
+		}
+		if code := pe.ExitCode(); code >= 0 {
+			return code, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,33 @@
+package service_test
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/matgreaves/rig/internal/server/service"
+)
+
+func TestExitCode(t *testing.T) {
+	if _, ok := service.ExitCode(fmt.Errorf("boom")); ok {
+		t.Error("plain error should have no exit code")
+	}
+
+	err := fmt.Errorf("wrapped: %w", &service.ExitError{Service: "api", Code: 137})
+	if code, ok := service.ExitCode(err); !ok || code != 137 {
+		t.Errorf("container exit = %d, %v; want 137, true", code, ok)
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	err = exec.Command("sh", "-c", "exit 3").Run()
+	if code, ok := service.ExitCode(err); !ok || code != 3 {
+		t.Errorf("process exit = %d, %v; want 3, true", code, ok)
+	}
+	err = exec.Command("sh", "-c", "kill -9 $$").Run()
+	if code, ok := service.ExitCode(err); !ok || code != 137 {
+		t.Errorf("signalled process = %d, %v; want 137, true", code, ok)
+	}
+}
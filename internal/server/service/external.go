@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/matgreaves/rig/internal/server/ready"
+	"github.com/matgreaves/rig/internal/spec"
+	"github.com/matgreaves/run"
+)
+
+// ExternalConfig is the type-specific config for an external node.
+type ExternalConfig struct {
+	URL string `json:"url"` // e.g. "https://sandbox.example.com"
+}
+
+// External implements service.Type for nodes standing in for an address
+// outside the environment. They are injected for egresses declared with
+// spec.EgressSpec.External, so the observe proxy on the edge has a target
+// to forward to. Nothing is started: the node publishes the URL's host
+// and port and counts as ready straight away.
+type External struct{}
+
+// Publish returns the external address as the "default" ingress.
+func (External) Publish(_ context.Context, params PublishParams) (map[string]spec.Endpoint, error) {
+	var cfg ExternalConfig
+	if err := json.Unmarshal(params.Spec.Config, &cfg); err != nil {
+		return nil, fmt.Errorf("external: unmarshal config: %w", err)
+	}
+	hostPort, err := ExternalHostPort(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("external: %w", err)
+	}
+	return map[string]spec.Endpoint{
+		"default": {HostPort: hostPort, Protocol: spec.HTTP},
+	}, nil
+}
+
+// ReadyCheck returns a checker that always passes. The external service's
+// availability is outside the test's control, and probing it would make
+// every environment depend on the network being up.
+func (External) ReadyCheck(ReadyCheckParams) ready.Checker {
+	return externalReady{}
+}
+
+// Runner returns run.Idle — there is nothing to start.
+func (External) Runner(StartParams) run.Runner {
+	return run.Idle
+}
+
+type externalReady struct{}
+
+func (externalReady) Check(context.Context, string) error { return nil }
+
+// ExternalHostPort returns the host:port an external http or https URL
+// addresses, filling in the scheme's default port.
+func ExternalHostPort(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("url %q has no host", rawURL)
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		default:
+			return "", fmt.Errorf("url %q: scheme must be http or https", rawURL)
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/matgreaves/rig/internal/server/artifact"
@@ -18,8 +19,23 @@ type GoServiceConfig struct {
 	// path ("./cmd/server") resolved against the environment's Dir, or a
 	// remote module reference ("github.com/myorg/tool@v1.2.3").
 	Module string `json:"module"`
+
+	// BuildTags are passed to go build as -tags.
+	BuildTags []string `json:"build_tags,omitempty"`
+
+	// BuildFlags are extra go build arguments, e.g.
+	// ["-ldflags", "-X main.version=1.2.3"].
+	BuildFlags []string `json:"build_flags,omitempty"`
+
+	// GoVersion builds with a specific Go release ("1.22.5") through its
+	// golang.org/dl wrapper instead of the go command on PATH.
+	GoVersion string `json:"go_version,omitempty"`
 }
 
+// goVersionPattern matches Go release versions: "1.22", "1.22.5",
+// "1.23rc1".
+var goVersionPattern = regexp.MustCompile(`^[0-9]+\.[0-9]+(\.[0-9]+)?((rc|beta)[0-9]+)?$`)
+
 // Go implements Type for the "go" service type. It compiles a Go module during
 // the artifact phase and runs the resulting binary during the service phase.
 type Go struct{}
@@ -39,11 +55,20 @@ func (Go) Artifacts(params ArtifactParams) ([]artifact.Artifact, error) {
 	if !filepath.IsAbs(cfg.Module) && !strings.Contains(cfg.Module, "@") && params.Dir == "" {
 		return nil, fmt.Errorf("service %q: relative module path %q requires environment dir (SDK must send \"dir\" field)", params.ServiceName, cfg.Module)
 	}
+	if cfg.GoVersion != "" && !goVersionPattern.MatchString(cfg.GoVersion) {
+		return nil, fmt.Errorf("service %q: invalid go_version %q: want a Go release such as \"1.22.5\"", params.ServiceName, cfg.GoVersion)
+	}
 	module := resolveModule(cfg.Module, params.Dir)
-	key := artifactKey(module)
+	key := artifactKey(module, cfg)
 	return []artifact.Artifact{{
-		Key:      key,
-		Resolver: artifact.GoBuild{Module: module, HostEnv: params.HostEnv},
+		Key: key,
+		Resolver: artifact.GoBuild{
+			Module:    module,
+			HostEnv:   params.HostEnv,
+			Tags:      cfg.BuildTags,
+			Flags:     cfg.BuildFlags,
+			GoVersion: cfg.GoVersion,
+		},
 	}}, nil
 }
 
@@ -53,7 +78,7 @@ func (Go) Publish(_ context.Context, params PublishParams) (map[string]spec.Endp
 }
 
 // Runner looks up the compiled binary from the artifact results and returns a
-// run.Process that executes it with the resolved wiring.
+// runner that executes it with the resolved wiring.
 func (Go) Runner(params StartParams) run.Runner {
 	var cfg GoServiceConfig
 	if params.Spec.Config != nil {
@@ -65,7 +90,7 @@ func (Go) Runner(params StartParams) run.Runner {
 	}
 
 	module := resolveModule(cfg.Module, params.Dir)
-	key := artifactKey(module)
+	key := artifactKey(module, cfg)
 	out, ok := params.Artifacts[key]
 	if !ok {
 		return run.Func(func(context.Context) error {
@@ -73,7 +98,7 @@ func (Go) Runner(params StartParams) run.Runner {
 		})
 	}
 
-	return run.Process{
+	return processRunner(run.Process{
 		Name:   params.ServiceName,
 		Path:   out.Path,
 		Dir:    params.Dir,
@@ -81,7 +106,7 @@ func (Go) Runner(params StartParams) run.Runner {
 		Env:    params.Env,
 		Stdout: params.Stdout,
 		Stderr: params.Stderr,
-	}
+	}, params.Spec.StopTimeout)
 }
 
 // resolveModule resolves a relative module path against the environment dir.
@@ -92,7 +117,16 @@ func resolveModule(module, dir string) string {
 	return module
 }
 
-// artifactKey returns the dedup key for a GoBuild artifact.
-func artifactKey(module string) string {
-	return "gobuild:" + module
+// artifactKey returns the dedup key for a GoBuild artifact. Services
+// building the same module with different tags, flags or Go versions get
+// separate artifacts.
+func artifactKey(module string, cfg GoServiceConfig) string {
+	key := "gobuild:" + module
+	if len(cfg.BuildTags) > 0 || len(cfg.BuildFlags) > 0 {
+		key += fmt.Sprintf(" tags=%q flags=%q", cfg.BuildTags, cfg.BuildFlags)
+	}
+	if cfg.GoVersion != "" {
+		key += " go=" + cfg.GoVersion
+	}
+	return key
 }
@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
+	"github.com/matgreaves/rig/connect"
 	"github.com/matgreaves/rig/internal/server/artifact"
+	"github.com/matgreaves/rig/internal/server/ready"
 	"github.com/matgreaves/rig/internal/spec"
 	"github.com/matgreaves/run"
 )
@@ -33,9 +36,67 @@ func (Kafka) Artifacts(params ArtifactParams) ([]artifact.Artifact, error) {
 	}}, nil
 }
 
-// Publish resolves ingress endpoints using host-allocated ports.
+// Publish resolves ingress endpoints using host-allocated ports. The
+// Kafka-protocol ingresses carry KAFKA_BROKERS, so an observe proxy in front
+// of one advertises its own address as the bootstrap server.
 func (Kafka) Publish(ctx context.Context, params PublishParams) (map[string]spec.Endpoint, error) {
-	return PublishLocalEndpoints(params)
+	endpoints, err := PublishLocalEndpoints(params)
+	if err != nil {
+		return nil, err
+	}
+	for name, ep := range endpoints {
+		if ep.Protocol != spec.Kafka {
+			continue
+		}
+		if ep.Attributes == nil {
+			ep.Attributes = map[string]any{}
+		}
+		connect.KafkaBrokers.Set(ep.Attributes, "${HOSTPORT}")
+		endpoints[name] = ep
+	}
+	return endpoints, nil
+}
+
+// ReadyCheck returns a checker that asks the broker for its cluster health
+// over the admin API. A bare TCP check isn't enough: Redpanda accepts
+// connections before it has elected a controller and can serve requests.
+// The schema registry is checked over HTTP.
+func (Kafka) ReadyCheck(params ReadyCheckParams) ready.Checker {
+	if params.Endpoint.Protocol != spec.Kafka {
+		return ready.ForEndpoint(params.Endpoint, nil)
+	}
+	return &kafkaReadyCheck{
+		containerName: ContainerName(params.InstanceID, params.ServiceName),
+	}
+}
+
+// kafkaReadyCheck runs `rpk cluster health` inside the Redpanda container.
+type kafkaReadyCheck struct {
+	containerName string
+}
+
+func (c *kafkaReadyCheck) Check(ctx context.Context, addr string) error {
+	var stdout strings.Builder
+	cmd := []string{"rpk", "cluster", "health"}
+	if err := ExecInContainer(ctx, c.containerName, cmd, &stdout, io.Discard); err != nil {
+		return fmt.Errorf("kafka: %w (not ready)", err)
+	}
+	if !kafkaHealthy(stdout.String()) {
+		return fmt.Errorf("kafka: cluster not healthy (not ready)")
+	}
+	return nil
+}
+
+// kafkaHealthy reports whether `rpk cluster health` output reports the
+// cluster as healthy ("Healthy:  true").
+func kafkaHealthy(out string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(key) == "Healthy" {
+			return strings.TrimSpace(value) == "true"
+		}
+	}
+	return false
 }
 
 // Runner builds a ContainerConfig and delegates to Container{}.Runner.
@@ -63,11 +124,22 @@ func (Kafka) Runner(params StartParams) run.Runner {
 }
 
 // Init handles server-side init hooks for the Kafka service type.
-// Supports the "schema" hook type — registers a schema with the schema registry.
+// Supports "schema" (registers a schema with the schema registry) and
+// "exec" (runs a command inside the Redpanda container, e.g. `rpk topic
+// create`).
 func (Kafka) Init(ctx context.Context, params InitParams) error {
-	if params.Hook.Type != "schema" {
+	switch params.Hook.Type {
+	case "schema":
+		return kafkaInitSchema(ctx, params)
+	case "exec":
+		return Container{}.Init(ctx, params)
+	default:
 		return fmt.Errorf("kafka: unsupported hook type %q", params.Hook.Type)
 	}
+}
+
+// kafkaInitSchema posts a schema to the schema-registry ingress.
+func kafkaInitSchema(ctx context.Context, params InitParams) error {
 
 	var cfg struct {
 		Subject    string `json:"subject"`
@@ -0,0 +1,19 @@
+package service
+
+import "testing"
+
+func TestKafkaHealthy(t *testing.T) {
+	tests := []struct {
+		out  string
+		want bool
+	}{
+		{"CLUSTER HEALTH OVERVIEW\n=======================\nHealthy:                          true\nUnhealthy reasons:                []\n", true},
+		{"CLUSTER HEALTH OVERVIEW\nHealthy:                          false\nUnhealthy reasons:                [no_elected_controller]\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := kafkaHealthy(tt.out); got != tt.want {
+			t.Errorf("kafkaHealthy(%q) = %v, want %v", tt.out, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/matgreaves/rig/connect"
+	"github.com/matgreaves/rig/internal/server/artifact"
+	"github.com/matgreaves/rig/internal/server/ready"
+	"github.com/matgreaves/rig/internal/spec"
+	"github.com/matgreaves/run"
+)
+
+const (
+	mongoDefaultImage    = "mongo:7"
+	mongoDefaultDatabase = "test"
+)
+
+// MongoConfig is the type-specific config for "mongo" services.
+type MongoConfig struct {
+	Image string `json:"image,omitempty"`
+}
+
+// Mongo implements Type and ArtifactProvider for the "mongo" builtin
+// service type. Each test gets a fresh mongod container (no pool), so
+// collections never collide between tests.
+type Mongo struct{}
+
+// Artifacts returns a DockerPull artifact for the MongoDB image.
+func (Mongo) Artifacts(params ArtifactParams) ([]artifact.Artifact, error) {
+	image := mongoImage(params.Spec.Config)
+	return []artifact.Artifact{{
+		Key:      "docker:" + image,
+		Resolver: artifact.DockerPull{Image: image},
+	}}, nil
+}
+
+// Publish resolves ingress endpoints using host-allocated ports and sets
+// MONGO_URI on each, so an observe proxy in front of the server hands out
+// its own address.
+func (Mongo) Publish(ctx context.Context, params PublishParams) (map[string]spec.Endpoint, error) {
+	endpoints, err := PublishLocalEndpoints(params)
+	if err != nil {
+		return nil, err
+	}
+	for name, ep := range endpoints {
+		if ep.Attributes == nil {
+			ep.Attributes = map[string]any{}
+		}
+		connect.MongoURI.Set(ep.Attributes, "mongodb://${HOSTPORT}/"+mongoDefaultDatabase)
+		connect.MongoDatabase.Set(ep.Attributes, mongoDefaultDatabase)
+		endpoints[name] = ep
+	}
+	return endpoints, nil
+}
+
+// ReadyCheck returns a checker that runs a ping command through mongosh
+// inside the container. mongod accepts connections before it can serve
+// commands, so a bare TCP dial isn't enough.
+func (Mongo) ReadyCheck(params ReadyCheckParams) ready.Checker {
+	return &execReadyCheck{
+		containerName: ContainerName(params.InstanceID, params.ServiceName),
+		command:       []string{"mongosh", "--quiet", "--eval", "db.runCommand({ping: 1}).ok"},
+	}
+}
+
+// Runner builds a ContainerConfig and delegates to Container{}.Runner.
+func (Mongo) Runner(params StartParams) run.Runner {
+	cfgJSON, _ := json.Marshal(ContainerConfig{Image: mongoImage(params.Spec.Config)})
+
+	modified := params
+	modified.Spec.Config = cfgJSON
+
+	return Container{}.Runner(modified)
+}
+
+// mongoHookConfig is the Config payload for "mongo" hooks.
+type mongoHookConfig struct {
+	Scripts []string `json:"scripts"`
+}
+
+// Init handles server-side hooks for the Mongo service type. Supports
+// "mongo", which evaluates each script with mongosh against the test
+// database, and "exec".
+func (Mongo) Init(ctx context.Context, params InitParams) error {
+	switch params.Hook.Type {
+	case "mongo":
+	case "exec":
+		return Container{}.Init(ctx, params)
+	default:
+		return fmt.Errorf("mongo: unsupported hook type %q", params.Hook.Type)
+	}
+
+	var cfg mongoHookConfig
+	if err := json.Unmarshal(params.Hook.Config, &cfg); err != nil {
+		return fmt.Errorf("mongo: invalid mongo hook config: %w", err)
+	}
+
+	containerName := ContainerName(params.InstanceID, params.ServiceName)
+	for i, script := range cfg.Scripts {
+		// mongosh exits non-zero when a script throws, including on a
+		// failed command.
+		cmd := []string{"mongosh", "--quiet", mongoDefaultDatabase, "--eval", script}
+		if err := ExecInContainer(ctx, containerName, cmd, params.Stdout, params.Stderr); err != nil {
+			return fmt.Errorf("mongo init: script %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// mongoImage returns the configured image or the default.
+func mongoImage(raw json.RawMessage) string {
+	if raw != nil {
+		var cfg MongoConfig
+		if err := json.Unmarshal(raw, &cfg); err == nil && cfg.Image != "" {
+			return cfg.Image
+		}
+	}
+	return mongoDefaultImage
+}
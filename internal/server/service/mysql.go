@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/matgreaves/rig/connect"
+	"github.com/matgreaves/rig/internal/server/artifact"
+	"github.com/matgreaves/rig/internal/server/ready"
+	"github.com/matgreaves/rig/internal/spec"
+	"github.com/matgreaves/run"
+)
+
+const (
+	mysqlDefaultImage    = "mysql:8"
+	mysqlDefaultUser     = "root"
+	mysqlDefaultPassword = "mysql"
+)
+
+// MySQLConfig is the type-specific config for "mysql" services.
+type MySQLConfig struct {
+	// Image overrides the default MySQL Docker image.
+	Image string `json:"image,omitempty"`
+}
+
+// MySQL implements Type and ArtifactProvider for the "mysql" builtin
+// service type. Like Postgres, it uses a Pool to share containers across
+// environments, providing per-test database isolation.
+type MySQL struct {
+	pool   *Pool
+	leases sync.Map // "instanceID:serviceName" → *Lease
+}
+
+// NewMySQL creates a MySQL service type backed by the given pool.
+func NewMySQL(pool *Pool) *MySQL {
+	return &MySQL{pool: pool}
+}
+
+// Artifacts returns a DockerPull artifact for the MySQL image.
+func (m *MySQL) Artifacts(params ArtifactParams) ([]artifact.Artifact, error) {
+	image := mysqlImage(params.Spec.Config)
+	return []artifact.Artifact{{
+		Key:      "docker:" + image,
+		Resolver: artifact.DockerPull{Image: image},
+	}}, nil
+}
+
+// Publish acquires a lease from the pool (which creates the per-test database)
+// and returns an endpoint using the shared container's port and unique DB name.
+func (m *MySQL) Publish(ctx context.Context, params PublishParams) (map[string]spec.Endpoint, error) {
+	image := mysqlImage(params.Spec.Config)
+
+	lease, err := m.pool.Acquire(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("mysql publish: %w", err)
+	}
+
+	m.leases.Store(leaseKey(params.InstanceID, params.ServiceName), lease)
+
+	endpoints := make(map[string]spec.Endpoint, len(params.Ingresses))
+	for name, ingSpec := range params.Ingresses {
+		ep := spec.Endpoint{
+			HostPort:   fmt.Sprintf("%s:%d", lease.Host, lease.Port),
+			Protocol:   ingSpec.Protocol,
+			Attributes: map[string]any{},
+		}
+		connect.MySQLHost.Set(ep.Attributes, "${HOST}")
+		connect.MySQLPort.Set(ep.Attributes, "${PORT}")
+		connect.MySQLDatabase.Set(ep.Attributes, lease.ID)
+		connect.MySQLUser.Set(ep.Attributes, mysqlDefaultUser)
+		connect.MySQLPassword.Set(ep.Attributes, mysqlDefaultPassword)
+		endpoints[name] = ep
+	}
+
+	return endpoints, nil
+}
+
+// ReadyCheck returns a checker that logs in to the per-test database. A bare
+// TCP check isn't enough for MySQL: the port can accept connections before
+// the server is ready to authenticate them.
+func (m *MySQL) ReadyCheck(params ReadyCheckParams) ready.Checker {
+	key := leaseKey(params.InstanceID, params.ServiceName)
+	v, ok := m.leases.Load(key)
+	if !ok {
+		// Fallback — shouldn't happen in normal flow.
+		return &mysqlReadyCheck{
+			containerName: ContainerName(params.InstanceID, params.ServiceName),
+			dbName:        params.ServiceName,
+		}
+	}
+	lease := v.(*Lease)
+	return &mysqlReadyCheck{
+		containerName: lease.Data.(string),
+		dbName:        lease.ID,
+	}
+}
+
+// mysqlReadyCheck runs an authenticated query inside the mysql container.
+type mysqlReadyCheck struct {
+	containerName string
+	dbName        string
+}
+
+func (c *mysqlReadyCheck) Check(ctx context.Context, addr string) error {
+	args := []string{"-D", c.dbName, "-e", "SELECT 1"}
+	if err := mysqlExec(ctx, c.containerName, args, io.Discard, io.Discard); err != nil {
+		return fmt.Errorf("mysql: %w (not ready)", err)
+	}
+	return nil
+}
+
+// Runner returns a runner that blocks on ctx and releases the lease on exit.
+// The shared container is managed by the pool — no per-test container start.
+func (m *MySQL) Runner(params StartParams) run.Runner {
+	return run.Func(func(ctx context.Context) error {
+		key := leaseKey(params.InstanceID, params.ServiceName)
+		v, ok := m.leases.Load(key)
+		if !ok {
+			return fmt.Errorf("mysql runner: no lease for %s", key)
+		}
+		lease := v.(*Lease)
+
+		// Block until teardown.
+		<-ctx.Done()
+
+		// Release the lease (drops the per-test database).
+		m.leases.Delete(key)
+		m.pool.Release(lease)
+
+		return ctx.Err()
+	})
+}
+
+// Init handles server-side hooks for the MySQL service type.
+// Supports "sql" (runs each statement via the mysql client against the
+// per-test DB) and "exec" (runs an arbitrary command inside the shared
+// container).
+func (m *MySQL) Init(ctx context.Context, params InitParams) error {
+	switch params.Hook.Type {
+	case "sql":
+		return m.initSQL(ctx, params)
+	case "exec":
+		return m.initExec(ctx, params)
+	default:
+		return fmt.Errorf("mysql: unsupported hook type %q", params.Hook.Type)
+	}
+}
+
+func (m *MySQL) initSQL(ctx context.Context, params InitParams) error {
+	var cfg sqlHookConfig
+	if err := json.Unmarshal(params.Hook.Config, &cfg); err != nil {
+		return fmt.Errorf("mysql: invalid sql hook config: %w", err)
+	}
+	if cfg.Database != "" {
+		return fmt.Errorf("mysql init: sql hook database %q: logical databases are postgres only", cfg.Database)
+	}
+	if len(cfg.Statements) == 0 {
+		return nil
+	}
+
+	key := leaseKey(params.InstanceID, params.ServiceName)
+	v, ok := m.leases.Load(key)
+	if !ok {
+		return fmt.Errorf("mysql init: no lease for %s", key)
+	}
+	lease := v.(*Lease)
+
+	for _, stmt := range cfg.Statements {
+		args := []string{"-D", lease.ID, "-e", stmt}
+		if err := mysqlExec(ctx, lease.Data.(string), args, params.Stdout, params.Stderr); err != nil {
+			return fmt.Errorf("mysql init: statement %q: %w", stmt, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *MySQL) initExec(ctx context.Context, params InitParams) error {
+	var cfg ExecHookConfig
+	if err := json.Unmarshal(params.Hook.Config, &cfg); err != nil {
+		return fmt.Errorf("mysql init: invalid exec hook config: %w", err)
+	}
+	if len(cfg.Command) == 0 {
+		return fmt.Errorf("mysql init: exec hook command is empty")
+	}
+
+	key := leaseKey(params.InstanceID, params.ServiceName)
+	v, ok := m.leases.Load(key)
+	if !ok {
+		return fmt.Errorf("mysql init exec: no lease for %s", key)
+	}
+	lease := v.(*Lease)
+
+	return ExecInContainer(ctx, lease.Data.(string), cfg.Command, params.Stdout, params.Stderr)
+}
+
+// mysqlImage returns the configured image or the default.
+func mysqlImage(raw json.RawMessage) string {
+	if raw != nil {
+		var cfg MySQLConfig
+		if err := json.Unmarshal(raw, &cfg); err == nil && cfg.Image != "" {
+			return cfg.Image
+		}
+	}
+	return mysqlDefaultImage
+}
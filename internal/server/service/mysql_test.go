@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+func TestMySQLArtifacts(t *testing.T) {
+	tests := []struct {
+		config  any
+		wantKey string
+	}{
+		{nil, "docker:mysql:8"},
+		{MySQLConfig{Image: "mysql:8.4"}, "docker:mysql:8.4"},
+	}
+	for _, tt := range tests {
+		var raw json.RawMessage
+		if tt.config != nil {
+			raw, _ = json.Marshal(tt.config)
+		}
+		m := NewMySQL(NewMySQLPool(99999))
+		arts, err := m.Artifacts(ArtifactParams{
+			ServiceName: "db",
+			Spec:        spec.Service{Type: "mysql", Config: raw},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(arts) != 1 {
+			t.Fatalf("got %d artifacts, want 1", len(arts))
+		}
+		if arts[0].Key != tt.wantKey {
+			t.Errorf("key = %q, want %s", arts[0].Key, tt.wantKey)
+		}
+	}
+}
+
+func TestMySQLInit_UnsupportedHookType(t *testing.T) {
+	m := NewMySQL(NewMySQLPool(99999))
+	err := m.Init(context.Background(), InitParams{
+		ServiceName: "db",
+		Hook: &spec.HookSpec{
+			Type:   "unknown",
+			Config: json.RawMessage(`{}`),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported hook type") {
+		t.Errorf("err = %v, want unsupported hook type", err)
+	}
+}
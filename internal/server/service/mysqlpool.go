@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/matgreaves/rig/internal/server/dockerutil"
+	"github.com/matgreaves/run/onexit"
+)
+
+// NewMySQLPool creates a Pool backed by MySQL containers. Each unique image
+// key gets one shared container; individual test environments get isolated
+// databases within it. The pid is embedded in container names so multiple
+// rigd processes never collide.
+func NewMySQLPool(pid int) *Pool {
+	return NewPool(func(key string) Backend {
+		return &mysqlBackend{
+			image:         key,
+			containerName: mysqlContainerName(pid, key),
+		}
+	}, 2*time.Minute)
+}
+
+// mysqlContainerName builds a deterministic container name from the image.
+func mysqlContainerName(pid int, image string) string {
+	safe := strings.NewReplacer(":", "-", "/", "-", ".", "-").Replace(image)
+	return fmt.Sprintf("rig-mysqlpool-%d-%s", pid, safe)
+}
+
+// mysqlExec runs the mysql client inside the container as root over TCP.
+// The password is passed via MYSQL_PWD so the client doesn't warn about a
+// password on the command line. TCP (rather than the socket) matters during
+// startup: the image's temporary init server runs with networking disabled,
+// so a TCP connection only succeeds once the real server is up.
+func mysqlExec(ctx context.Context, containerName string, args []string, stdout, stderr io.Writer) error {
+	cmd := append([]string{
+		"mysql", "-h", "127.0.0.1", "-u", mysqlDefaultUser,
+	}, args...)
+	env := []string{"MYSQL_PWD=" + mysqlDefaultPassword}
+	return execInContainerEnv(ctx, containerName, cmd, env, stdout, stderr)
+}
+
+// mysqlBackend implements Backend for MySQL Docker containers.
+type mysqlBackend struct {
+	image         string
+	containerName string
+	containerID   string
+	dbCounter     atomic.Int64
+	cancelOnexit  func() error
+}
+
+// Start creates and starts a shared MySQL container.
+func (b *mysqlBackend) Start(ctx context.Context) (string, int, error) {
+	cli, err := dockerutil.Client()
+	if err != nil {
+		return "", 0, fmt.Errorf("docker client: %w", err)
+	}
+
+	// If a same-name container exists (from a previous crash), remove it.
+	cli.ContainerRemove(ctx, b.containerName, container.RemoveOptions{Force: true})
+
+	containerPort := nat.Port("3306/tcp")
+
+	config := &container.Config{
+		Image: b.image,
+		Env: []string{
+			"MYSQL_ROOT_PASSWORD=" + mysqlDefaultPassword,
+			"MYSQL_ROOT_HOST=%",
+		},
+		Cmd:          []string{"--max-connections=500"},
+		ExposedPorts: nat.PortSet{containerPort: {}},
+	}
+
+	hostConfig := &container.HostConfig{
+		PortBindings: nat.PortMap{
+			containerPort: []nat.PortBinding{{
+				HostIP:   "127.0.0.1",
+				HostPort: "", // Docker auto-assigns
+			}},
+		},
+	}
+
+	resp, err := cli.ContainerCreate(ctx, config, hostConfig, nil, nil, b.containerName)
+	if err != nil {
+		return "", 0, fmt.Errorf("create container: %w", err)
+	}
+	b.containerID = resp.ID
+
+	// Register onexit cleanup.
+	cancelOnexit, _ := onexit.OnExitF("docker rm -f %s", b.containerID)
+	b.cancelOnexit = cancelOnexit
+
+	if err := cli.ContainerStart(ctx, b.containerID, container.StartOptions{}); err != nil {
+		return "", 0, fmt.Errorf("start container: %w", err)
+	}
+
+	// Read back the mapped host port.
+	inspect, err := cli.ContainerInspect(ctx, b.containerID)
+	if err != nil {
+		return "", 0, fmt.Errorf("inspect container: %w", err)
+	}
+
+	bindings, ok := inspect.NetworkSettings.Ports[containerPort]
+	if !ok || len(bindings) == 0 {
+		return "", 0, fmt.Errorf("no port binding for 3306")
+	}
+	port, err := strconv.Atoi(bindings[0].HostPort)
+	if err != nil {
+		return "", 0, fmt.Errorf("parse host port: %w", err)
+	}
+
+	if err := b.waitReady(ctx); err != nil {
+		return "", 0, fmt.Errorf("wait for ready: %w", err)
+	}
+
+	// Orphan cleanup: drop any rig_* databases from a previous crash.
+	b.cleanOrphanDatabases(ctx)
+
+	return "127.0.0.1", port, nil
+}
+
+// Stop stops and removes the Docker container.
+func (b *mysqlBackend) Stop() {
+	if b.containerID == "" {
+		return
+	}
+
+	cli, err := dockerutil.Client()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	timeout := 10
+	cli.ContainerStop(ctx, b.containerID, container.StopOptions{Timeout: &timeout})
+	cli.ContainerRemove(ctx, b.containerID, container.RemoveOptions{Force: true})
+
+	if b.cancelOnexit != nil {
+		b.cancelOnexit()
+	}
+}
+
+// NewLease allocates a new per-test database in the shared container.
+// Returns the database name as ID and the container name as Data.
+func (b *mysqlBackend) NewLease(ctx context.Context) (string, any, error) {
+	dbNum := b.dbCounter.Add(1)
+	dbName := fmt.Sprintf("rig_%d", dbNum)
+
+	args := []string{"-e", fmt.Sprintf("CREATE DATABASE `%s`", dbName)}
+	if err := mysqlExec(ctx, b.containerName, args, io.Discard, io.Discard); err != nil {
+		return "", nil, fmt.Errorf("create database %s: %w", dbName, err)
+	}
+
+	return dbName, b.containerName, nil
+}
+
+// DropLease drops the per-test database. Best-effort — errors are ignored.
+// Unlike Postgres, MySQL doesn't refuse to drop a database with open
+// connections, so there is nothing to terminate first.
+func (b *mysqlBackend) DropLease(ctx context.Context, id string) {
+	args := []string{"-e", fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", id)}
+	mysqlExec(ctx, b.containerName, args, io.Discard, io.Discard)
+}
+
+// waitReady polls an authenticated query inside the container until it
+// succeeds or ctx is cancelled.
+func (b *mysqlBackend) waitReady(ctx context.Context) error {
+	deadline := time.After(120 * time.Second)
+	for {
+		err := mysqlExec(ctx, b.containerName, []string{"-e", "SELECT 1"}, io.Discard, io.Discard)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("mysql not ready after 120s: %w", err)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// cleanOrphanDatabases drops any rig_* databases left over from previous crashes.
+func (b *mysqlBackend) cleanOrphanDatabases(ctx context.Context) {
+	var stdout strings.Builder
+	args := []string{"-N", "-B", "-e", `SHOW DATABASES LIKE 'rig\_%'`}
+	if err := mysqlExec(ctx, b.containerName, args, &stdout, io.Discard); err != nil {
+		return
+	}
+
+	for _, db := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		db = strings.TrimSpace(db)
+		if db == "" || !strings.HasPrefix(db, "rig_") {
+			continue
+		}
+		b.DropLease(ctx, db)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/matgreaves/rig/connect"
+	"github.com/matgreaves/rig/internal/server/artifact"
+	"github.com/matgreaves/rig/internal/server/ready"
+	"github.com/matgreaves/rig/internal/spec"
+	"github.com/matgreaves/run"
+)
+
+const natsDefaultImage = "nats:latest"
+
+// NATSConfig is the type-specific config for "nats" services.
+type NATSConfig struct {
+	Image string `json:"image,omitempty"`
+}
+
+// NATS implements Type and ArtifactProvider for the "nats" builtin service
+// type. Each test gets a fresh nats-server container (no pool), so subjects
+// never collide between tests.
+type NATS struct{}
+
+// Artifacts returns a DockerPull artifact for the NATS image.
+func (NATS) Artifacts(params ArtifactParams) ([]artifact.Artifact, error) {
+	image := natsImage(params.Spec.Config)
+	return []artifact.Artifact{{
+		Key:      "docker:" + image,
+		Resolver: artifact.DockerPull{Image: image},
+	}}, nil
+}
+
+// Publish resolves ingress endpoints using host-allocated ports and sets
+// NATS_URL on each, so an observe proxy in front of the server hands out
+// its own address.
+func (NATS) Publish(ctx context.Context, params PublishParams) (map[string]spec.Endpoint, error) {
+	endpoints, err := PublishLocalEndpoints(params)
+	if err != nil {
+		return nil, err
+	}
+	for name, ep := range endpoints {
+		if ep.Attributes == nil {
+			ep.Attributes = map[string]any{}
+		}
+		connect.NATSURL.Set(ep.Attributes, "nats://${HOSTPORT}")
+		endpoints[name] = ep
+	}
+	return endpoints, nil
+}
+
+// ReadyCheck returns a checker that waits for the server's INFO greeting.
+// Docker's port forwarder accepts connections before nats-server is
+// listening, so a bare TCP dial isn't enough.
+func (NATS) ReadyCheck(ReadyCheckParams) ready.Checker {
+	return ready.NATS{}
+}
+
+// Runner builds a ContainerConfig and delegates to Container{}.Runner.
+func (NATS) Runner(params StartParams) run.Runner {
+	cfgJSON, _ := json.Marshal(ContainerConfig{Image: natsImage(params.Spec.Config)})
+
+	modified := params
+	modified.Spec.Config = cfgJSON
+
+	return Container{}.Runner(modified)
+}
+
+// natsImage returns the configured image or the default.
+func natsImage(raw json.RawMessage) string {
+	if raw != nil {
+		var cfg NATSConfig
+		if err := json.Unmarshal(raw, &cfg); err == nil && cfg.Image != "" {
+			return cfg.Image
+		}
+	}
+	return natsDefaultImage
+}
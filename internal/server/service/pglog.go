@@ -0,0 +1,86 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/matgreaves/rig/internal/server/dockerutil"
+)
+
+// pgLogLinePrefix is the log_line_prefix of pooled Postgres containers:
+// timestamp, backend pid, and database ("db=" is empty for background
+// processes).
+const pgLogLinePrefix = "%m [%p] db=%d "
+
+// pgFollowStatementLog streams the log lines of the given databases from
+// the shared container to w until ctx is done. Lines are written without
+// the prefix (e.g. "LOG:  statement: SELECT 1"). Best-effort: if the log
+// can't be followed, nothing is written.
+func pgFollowStatementLog(ctx context.Context, containerName string, databases []string, w io.Writer) {
+	cli, err := dockerutil.Client()
+	if err != nil {
+		return
+	}
+	since := time.Now()
+	logs, err := cli.ContainerLogs(ctx, containerName, container.LogsOptions{
+		ShowStderr: true,
+		Follow:     true,
+		Since:      fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()),
+	})
+	if err != nil {
+		return
+	}
+	defer logs.Close()
+	// Postgres logs to stderr.
+	stdcopy.StdCopy(io.Discard, &pgLogFilter{w: w, databases: databases}, logs)
+}
+
+// pgLogFilter passes through the log lines of a set of databases from a
+// container shared with other tests, stripping the pgLogLinePrefix.
+// Continuation lines of a multi-line statement carry no prefix and follow
+// the line they continue.
+type pgLogFilter struct {
+	w         io.Writer
+	databases []string
+	buf       []byte
+	ours      bool // whether the last prefixed line was for one of databases
+}
+
+func (f *pgLogFilter) Write(p []byte) (int, error) {
+	f.buf = append(f.buf, p...)
+	for {
+		i := bytes.IndexByte(f.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(f.buf[:i+1])
+		f.buf = f.buf[i+1:]
+		if out, ok := f.filter(line); ok {
+			if _, err := io.WriteString(f.w, out); err != nil {
+				return len(p), err
+			}
+		}
+	}
+	return len(p), nil
+}
+
+func (f *pgLogFilter) filter(line string) (string, bool) {
+	if line[0] == '\t' || line[0] == ' ' {
+		return line, f.ours
+	}
+	_, rest, ok := strings.Cut(line, "] db=")
+	if !ok {
+		f.ours = false
+		return "", false
+	}
+	db, msg, _ := strings.Cut(rest, " ")
+	f.ours = slices.Contains(f.databases, db)
+	return msg, f.ours
+}
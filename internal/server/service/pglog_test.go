@@ -0,0 +1,55 @@
+package service
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+func TestPGLogFilter(t *testing.T) {
+	var out strings.Builder
+	f := &pgLogFilter{w: &out, databases: []string{"rig_3", "rig_3_orders"}}
+
+	log := "2026-03-01 10:00:00.001 UTC [41] db=rig_3 LOG:  statement: SELECT 1\n" +
+		"2026-03-01 10:00:00.002 UTC [42] db=rig_4 LOG:  statement: SELECT 2\n" +
+		"\tFROM other\n" +
+		"2026-03-01 10:00:00.003 UTC [43] db=rig_3_orders ERROR:  relation \"orders\" does not exist at character 15\n" +
+		"2026-03-01 10:00:00.003 UTC [43] db=rig_3_orders STATEMENT:  SELECT * FROM orders\n" +
+		"\tWHERE id = 1\n" +
+		"2026-03-01 10:00:00.004 UTC [1] db= LOG:  checkpoint starting: time\n"
+	// Split mid-line to exercise buffering.
+	f.Write([]byte(log[:30]))
+	f.Write([]byte(log[30:]))
+
+	want := "LOG:  statement: SELECT 1\n" +
+		"ERROR:  relation \"orders\" does not exist at character 15\n" +
+		"STATEMENT:  SELECT * FROM orders\n" +
+		"\tWHERE id = 1\n"
+	if out.String() != want {
+		t.Errorf("filtered log:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPGLogSettings(t *testing.T) {
+	if got := pgLogSettings(nil); got != nil {
+		t.Errorf("no config: settings = %v, want none", got)
+	}
+	cfg, _ := json.Marshal(PostgresConfig{
+		LogStatements:  true,
+		LogMinDuration: &spec.Duration{Duration: 250 * time.Millisecond},
+	})
+	want := []string{"log_statement = 'all'", "log_min_duration_statement = 250"}
+	if got := pgLogSettings(cfg); !slices.Equal(got, want) {
+		t.Errorf("settings = %v, want %v", got, want)
+	}
+
+	// A zero threshold is sent as "" and still logs every duration.
+	cfg = json.RawMessage(`{"log_min_duration":""}`)
+	if got := pgLogSettings(cfg); !slices.Equal(got, []string{"log_min_duration_statement = 0"}) {
+		t.Errorf("zero threshold: settings = %v", got)
+	}
+}
@@ -63,7 +63,9 @@ func (b *pgBackend) Start(ctx context.Context) (string, int, error) {
 			"POSTGRES_PASSWORD=" + postgresDefaultPassword,
 			"POSTGRES_DB=postgres",
 		},
-		Cmd:          []string{"-c", "max_connections=500"},
+		// The database name in every log line lets each test's
+		// statement log be picked out of the shared container's output.
+		Cmd:          []string{"-c", "max_connections=500", "-c", "log_line_prefix=" + pgLogLinePrefix},
 		ExposedPorts: nat.PortSet{containerPort: {}},
 	}
 
@@ -145,20 +147,50 @@ func (b *pgBackend) NewLease(ctx context.Context) (string, any, error) {
 	dbNum := b.dbCounter.Add(1)
 	dbName := fmt.Sprintf("rig_%d", dbNum)
 
+	if err := pgCreateDatabase(ctx, b.containerName, dbName); err != nil {
+		return "", nil, err
+	}
+
+	return dbName, b.containerName, nil
+}
+
+// DropLease drops the per-test database. Best-effort — errors are ignored.
+func (b *pgBackend) DropLease(ctx context.Context, id string) {
+	pgDropDatabase(ctx, b.containerName, id)
+}
+
+// pgCreateDatabase creates a database in the container via psql.
+func pgCreateDatabase(ctx context.Context, containerName, dbName string) error {
 	createCmd := []string{
 		"psql", "-h", "localhost", "-U", postgresDefaultUser,
 		"-v", "ON_ERROR_STOP=1",
 		"-c", fmt.Sprintf("CREATE DATABASE %s", dbName),
 	}
-	if err := ExecInContainer(ctx, b.containerName, createCmd, io.Discard, io.Discard); err != nil {
-		return "", nil, fmt.Errorf("create database %s: %w", dbName, err)
+	if err := ExecInContainer(ctx, containerName, createCmd, io.Discard, io.Discard); err != nil {
+		return fmt.Errorf("create database %s: %w", dbName, err)
 	}
+	return nil
+}
 
-	return dbName, b.containerName, nil
+// pgAlterDatabase sets configuration parameters ("name = value") on a
+// database. They apply to sessions opened afterwards.
+func pgAlterDatabase(ctx context.Context, containerName, dbName string, settings []string) error {
+	for _, setting := range settings {
+		cmd := []string{
+			"psql", "-h", "localhost", "-U", postgresDefaultUser,
+			"-v", "ON_ERROR_STOP=1",
+			"-c", fmt.Sprintf("ALTER DATABASE %s SET %s", dbName, setting),
+		}
+		if err := ExecInContainer(ctx, containerName, cmd, io.Discard, io.Discard); err != nil {
+			return fmt.Errorf("alter database %s: %w", dbName, err)
+		}
+	}
+	return nil
 }
 
-// DropLease drops the per-test database. Best-effort — errors are ignored.
-func (b *pgBackend) DropLease(ctx context.Context, id string) {
+// pgDropDatabase terminates any remaining connections to a database and
+// drops it. Best-effort — errors are ignored.
+func pgDropDatabase(ctx context.Context, containerName, id string) {
 	cli, err := dockerutil.Client()
 	if err != nil {
 		return
@@ -169,7 +201,7 @@ func (b *pgBackend) DropLease(ctx context.Context, id string) {
 		"psql", "-h", "localhost", "-U", postgresDefaultUser,
 		"-c", fmt.Sprintf("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = '%s' AND pid <> pg_backend_pid()", id),
 	}
-	exec, err := cli.ContainerExecCreate(ctx, b.containerName, container.ExecOptions{
+	exec, err := cli.ContainerExecCreate(ctx, containerName, container.ExecOptions{
 		Cmd:          terminateCmd,
 		AttachStdout: true,
 		AttachStderr: true,
@@ -186,7 +218,7 @@ func (b *pgBackend) DropLease(ctx context.Context, id string) {
 		"psql", "-h", "localhost", "-U", postgresDefaultUser,
 		"-c", fmt.Sprintf("DROP DATABASE IF EXISTS %s", id),
 	}
-	exec, err = cli.ContainerExecCreate(ctx, b.containerName, container.ExecOptions{
+	exec, err = cli.ContainerExecCreate(ctx, containerName, container.ExecOptions{
 		Cmd:          dropCmd,
 		AttachStdout: true,
 		AttachStderr: true,
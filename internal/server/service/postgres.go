@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/matgreaves/rig/connect"
@@ -26,6 +28,20 @@ const (
 type PostgresConfig struct {
 	// Image overrides the default Postgres Docker image.
 	Image string `json:"image,omitempty"`
+
+	// Databases are additional logical databases created next to the
+	// per-test database. Each is published in a PGDATABASE_{NAME}
+	// attribute and can be selected by an egress or targeted by a sql hook.
+	Databases []string `json:"databases,omitempty"`
+
+	// LogStatements sets log_statement=all on the test's databases and
+	// streams their statement log into the service's output.
+	LogStatements bool `json:"log_statements,omitempty"`
+
+	// LogMinDuration sets log_min_duration_statement on the test's
+	// databases: statements taking at least this long are logged with
+	// their duration. Zero logs every statement's duration.
+	LogMinDuration *spec.Duration `json:"log_min_duration,omitempty"`
 }
 
 // Postgres implements Type and ArtifactProvider for the "postgres" builtin
@@ -67,6 +83,29 @@ func (p *Postgres) Publish(ctx context.Context, params PublishParams) (map[strin
 		return nil, fmt.Errorf("postgres publish: %w", err)
 	}
 
+	// Create the additional logical databases, named after the lease so
+	// they are isolated per test and dropped as orphans like it.
+	databases := postgresDatabases(params.Spec.Config)
+	for _, db := range databases {
+		if err := pgCreateDatabase(ctx, lease.Data.(string), pgDatabaseName(lease.ID, db)); err != nil {
+			p.dropDatabases(lease, databases)
+			p.pool.Release(lease)
+			return nil, fmt.Errorf("postgres publish: %w", err)
+		}
+	}
+
+	// Statement logging is set per database so other tests sharing the
+	// container are unaffected. It applies to sessions opened from now on.
+	if settings := pgLogSettings(params.Spec.Config); len(settings) > 0 {
+		for _, db := range pgLeaseDatabases(lease, databases) {
+			if err := pgAlterDatabase(ctx, lease.Data.(string), db, settings); err != nil {
+				p.dropDatabases(lease, databases)
+				p.pool.Release(lease)
+				return nil, fmt.Errorf("postgres publish: %w", err)
+			}
+		}
+	}
+
 	// Store the lease for later phases.
 	p.leases.Store(leaseKey(params.InstanceID, params.ServiceName), lease)
 
@@ -87,6 +126,9 @@ func (p *Postgres) Publish(ctx context.Context, params PublishParams) (map[strin
 		connect.PGDatabase.Set(ep.Attributes, lease.ID)
 		connect.PGUser.Set(ep.Attributes, postgresDefaultUser)
 		connect.PGPassword.Set(ep.Attributes, postgresDefaultPassword)
+		for _, db := range databases {
+			connect.PGDatabaseNamed(db).Set(ep.Attributes, pgDatabaseName(lease.ID, db))
+		}
 		endpoints[name] = ep
 	}
 
@@ -162,11 +204,18 @@ func (p *Postgres) Runner(params StartParams) run.Runner {
 		}
 		lease := v.(*Lease)
 
+		if len(pgLogSettings(params.Spec.Config)) > 0 {
+			databases := pgLeaseDatabases(lease, postgresDatabases(params.Spec.Config))
+			go pgFollowStatementLog(ctx, lease.Data.(string), databases, params.Stderr)
+		}
+
 		// Block until teardown.
 		<-ctx.Done()
 
-		// Release the lease (drops the per-test database).
+		// Release the lease (drops the per-test database) after dropping
+		// any additional logical databases.
 		p.leases.Delete(key)
+		p.dropDatabases(lease, postgresDatabases(params.Spec.Config))
 		p.pool.Release(lease)
 
 		return ctx.Err()
@@ -176,6 +225,7 @@ func (p *Postgres) Runner(params StartParams) run.Runner {
 // sqlHookConfig is the Config payload for "sql" hooks.
 type sqlHookConfig struct {
 	Statements []string `json:"statements"`
+	Database   string   `json:"database,omitempty"` // logical database (postgres only); empty means the per-test database
 }
 
 // Init handles server-side hooks for the Postgres service type.
@@ -208,12 +258,20 @@ func (p *Postgres) initSQL(ctx context.Context, params InitParams) error {
 	}
 	lease := v.(*Lease)
 
-	// The per-test database was already created by the pool's NewLease.
-	// Run each statement against it.
+	// The per-test database was already created by the pool's NewLease,
+	// and any logical databases by Publish. Run each statement against
+	// the one the hook targets.
+	dbName := lease.ID
+	if cfg.Database != "" {
+		if !slices.Contains(postgresDatabases(params.Spec.Config), cfg.Database) {
+			return fmt.Errorf("postgres init: sql hook targets undeclared database %q", cfg.Database)
+		}
+		dbName = pgDatabaseName(lease.ID, cfg.Database)
+	}
 	for _, stmt := range cfg.Statements {
 		cmd := []string{
 			"psql", "-h", "localhost", "-U", postgresDefaultUser,
-			"-d", lease.ID,
+			"-d", dbName,
 			"-v", "ON_ERROR_STOP=1",
 			"-c", stmt,
 		}
@@ -244,6 +302,67 @@ func (p *Postgres) initExec(ctx context.Context, params InitParams) error {
 	return ExecInContainer(ctx, lease.Data.(string), cfg.Command, params.Stdout, params.Stderr)
 }
 
+// dropDatabases drops the lease's additional logical databases.
+// Best-effort, like the pool's own DropLease.
+func (p *Postgres) dropDatabases(lease *Lease, databases []string) {
+	if len(databases) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	for _, db := range databases {
+		pgDropDatabase(ctx, lease.Data.(string), pgDatabaseName(lease.ID, db))
+	}
+}
+
+// pgDatabaseName returns the actual name of logical database db within the
+// per-test database leaseID (e.g. "rig_3_orders").
+func pgDatabaseName(leaseID, db string) string {
+	return leaseID + "_" + db
+}
+
+// postgresDatabases returns the configured logical databases.
+func postgresDatabases(raw json.RawMessage) []string {
+	if raw == nil {
+		return nil
+	}
+	var cfg PostgresConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil
+	}
+	return cfg.Databases
+}
+
+// pgLogSettings returns the statement-logging parameters to set on the
+// test's databases, as "name = value" pairs.
+func pgLogSettings(raw json.RawMessage) []string {
+	if raw == nil {
+		return nil
+	}
+	var cfg PostgresConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil
+	}
+	var settings []string
+	if cfg.LogStatements {
+		settings = append(settings, "log_statement = 'all'")
+	}
+	if cfg.LogMinDuration != nil {
+		settings = append(settings, fmt.Sprintf("log_min_duration_statement = %d", cfg.LogMinDuration.Milliseconds()))
+	}
+	return settings
+}
+
+// pgLeaseDatabases returns the actual names of every database the lease
+// owns: the per-test database and its logical databases.
+func pgLeaseDatabases(lease *Lease, databases []string) []string {
+	names := []string{lease.ID}
+	for _, db := range databases {
+		names = append(names, pgDatabaseName(lease.ID, db))
+	}
+	return names
+}
+
 // postgresImage returns the configured image or the default.
 func postgresImage(raw json.RawMessage) string {
 	if raw != nil {
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os/exec"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/matgreaves/rig/internal/spec"
 	"github.com/matgreaves/run"
+	"github.com/matgreaves/run/onexit"
 )
 
 // ProcessConfig is the type-specific config for "process" services.
@@ -28,7 +32,7 @@ func (Process) Publish(_ context.Context, params PublishParams) (map[string]spec
 	return PublishLocalEndpoints(params)
 }
 
-// Runner returns a run.Process that executes the configured binary.
+// Runner returns a runner that executes the configured binary.
 func (Process) Runner(params StartParams) run.Runner {
 	var cfg ProcessConfig
 	if params.Spec.Config != nil {
@@ -46,7 +50,7 @@ func (Process) Runner(params StartParams) run.Runner {
 		dir = filepath.Clean(filepath.Join(params.Dir, dir))
 	}
 
-	return run.Process{
+	return processRunner(run.Process{
 		Name:   params.ServiceName,
 		Path:   cfg.Command,
 		Dir:    dir,
@@ -54,5 +58,72 @@ func (Process) Runner(params StartParams) run.Runner {
 		Env:    params.Env,
 		Stdout: params.Stdout,
 		Stderr: params.Stderr,
+	}, params.Spec.StopTimeout)
+}
+
+// processRunner returns p as is when the service has no stop timeout.
+// Otherwise it wraps p so the process group is killed if it hasn't exited
+// within the timeout of being interrupted.
+func processRunner(p run.Process, stopTimeout *spec.Duration) run.Runner {
+	if stopTimeout == nil {
+		return p
+	}
+	return stopTimeoutProcess{Process: p, StopTimeout: stopTimeout.Duration}
+}
+
+// stopTimeoutProcess runs p like run.Process, but on cancellation sends
+// SIGKILL to the process group once StopTimeout has passed since the
+// SIGINT. A zero StopTimeout skips the SIGINT and kills immediately.
+type stopTimeoutProcess struct {
+	run.Process
+	StopTimeout time.Duration
+}
+
+func (p stopTimeoutProcess) Run(ctx context.Context) error {
+	path, err := exec.LookPath(p.Path)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, path, p.Args...)
+	cmd.Dir = p.Dir
+	cmd.Stdin = p.Stdin
+	cmd.Stdout = p.Stdout
+	cmd.Stderr = p.Stderr
+	for k, v := range p.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	// Own process group so the process and its children are signalled together.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	var kill *time.Timer
+	cmd.Cancel = func() error {
+		pgid := -cmd.Process.Pid
+		if p.StopTimeout <= 0 {
+			_ = syscall.Kill(pgid, syscall.SIGKILL)
+			return nil
+		}
+		_ = syscall.Kill(pgid, syscall.SIGINT)
+		kill = time.AfterFunc(p.StopTimeout, func() {
+			_ = syscall.Kill(pgid, syscall.SIGKILL)
+		})
+		return nil
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	cancel, err := onexit.Kill(p.Name, -cmd.Process.Pid, syscall.SIGKILL)
+	if err != nil {
+		cmd.Cancel()
+		return fmt.Errorf("run: failed to register killer: %w", err)
+	}
+	defer cancel()
+
+	err = cmd.Wait()
+	if kill != nil {
+		kill.Stop()
 	}
+	return err
 }
@@ -2,7 +2,12 @@ package service_test
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/matgreaves/rig/internal/server/service"
 	"github.com/matgreaves/rig/internal/spec"
@@ -114,3 +119,66 @@ func TestRegistry_RegisterAndGet(t *testing.T) {
 		t.Error("expected non-nil service type")
 	}
 }
+
+func TestProcessRunner_StopTimeout(t *testing.T) {
+	// The script ignores SIGINT, so only the stop timeout's SIGKILL ends it.
+	script := filepath.Join(t.TempDir(), "stubborn.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ntrap '' INT\necho started\nwhile true; do sleep 0.05; done\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		timeout time.Duration
+	}{
+		{"graceful", 200 * time.Millisecond},
+		{"immediate", 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, _ := json.Marshal(service.ProcessConfig{Command: script})
+			started := make(chan struct{})
+			runner := service.Process{}.Runner(service.StartParams{
+				ServiceName: "stubborn",
+				Spec: spec.Service{
+					Type:        "process",
+					Config:      cfg,
+					StopTimeout: &spec.Duration{Duration: tc.timeout},
+				},
+				Stdout: writerFunc(func(p []byte) (int, error) {
+					select {
+					case <-started:
+					default:
+						close(started)
+					}
+					return len(p), nil
+				}),
+				Stderr: io.Discard,
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- runner.Run(ctx) }()
+
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("process did not start")
+			}
+			stopAt := time.Now()
+			cancel()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("process was not killed after the stop timeout")
+			}
+			if elapsed := time.Since(stopAt); elapsed < tc.timeout {
+				t.Errorf("process killed after %s, before the %s stop timeout", elapsed, tc.timeout)
+			}
+		})
+	}
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
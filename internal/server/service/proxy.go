@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"sync"
+	"time"
 
+	"github.com/matgreaves/rig/connect"
 	"github.com/matgreaves/rig/internal/server/proxy"
 	"github.com/matgreaves/rig/internal/spec"
 	"github.com/matgreaves/run"
@@ -14,10 +18,26 @@ import (
 // ProxyConfig is the type-specific config for a proxy service node.
 // Stored in spec.Service.Config as JSON.
 type ProxyConfig struct {
-	Source        string `json:"source"`                    // consuming service name or "~test"
-	TargetSvc     string `json:"target_svc"`                // real target service name
-	Ingress       string `json:"ingress"`                   // real target ingress name
-	ReflectionKey string `json:"reflection_key,omitempty"`  // cache key for gRPC reflection descriptors
+	Source           string   `json:"source"`                      // consuming service name or "~test"
+	TargetSvc        string   `json:"target_svc"`                  // real target service name
+	Ingress          string   `json:"ingress"`                     // real target ingress name
+	ReflectionKey    string   `json:"reflection_key,omitempty"`    // cache key for gRPC reflection descriptors
+	MaxBodySize      int64    `json:"max_body_size,omitempty"`     // reject larger HTTP request bodies with 413
+	AllowMethods     []string `json:"allow_methods,omitempty"`     // gRPC methods permitted on this edge; empty allows all
+	IdleTimeout      string   `json:"idle_timeout,omitempty"`      // close TCP relay connections idle this long (Go duration)
+	BodyLimit        int      `json:"body_limit,omitempty"`        // body bytes captured per message; see proxy.Forwarder.BodyLimit
+	CapturePreview   int      `json:"capture_preview,omitempty"`   // TCP bytes previewed per direction; see proxy.Forwarder.CapturePreview
+	ConnPool         int      `json:"conn_pool,omitempty"`         // idle HTTP target connections kept for reuse; see proxy.Forwarder.ConnPool
+	TLSCertFile      string   `json:"tls_cert_file,omitempty"`     // certificate for terminating TLS on SECURE http targets
+	TLSKeyFile       string   `json:"tls_key_file,omitempty"`      // key for TLSCertFile
+	ProtoDescriptors string   `json:"proto_descriptors,omitempty"` // FileDescriptorSet used when reflection is unavailable
+	AutoDetect       bool     `json:"auto_detect,omitempty"`       // sniff TCP connections for HTTP, gRPC and Kafka
+	External         string   `json:"external,omitempty"`          // base URL of a target outside the environment
+	RedactHeaders    []string `json:"redact_headers,omitempty"`    // headers masked in events; see proxy.Redaction
+	RedactJSON       []string `json:"redact_json,omitempty"`       // JSON body paths masked in events
+
+	Mocks []spec.MockSpec `json:"mocks,omitempty"` // canned HTTP responses served instead of forwarding
+	Fault *spec.FaultSpec `json:"fault,omitempty"` // latency, drops and errors injected on this edge
 }
 
 // Proxy implements service.Type for transparent traffic proxy nodes.
@@ -79,6 +99,18 @@ func (p *Proxy) Publish(_ context.Context, params PublishParams) (map[string]spe
 		}
 	}
 
+	// A proxy terminating TLS presents its own certificate; advertise it
+	// so clients can trust the connection.
+	var cfg ProxyConfig
+	if params.Spec.Config != nil {
+		if err := json.Unmarshal(params.Spec.Config, &cfg); err != nil {
+			return nil, fmt.Errorf("proxy: unmarshal config: %w", err)
+		}
+	}
+	if terminatesTLS(cfg, target) {
+		connect.TLSCertFile.Set(attrs, cfg.TLSCertFile)
+	}
+
 	return map[string]spec.Endpoint{
 		"default": {
 			HostPort:   fmt.Sprintf("127.0.0.1:%d", port),
@@ -89,9 +121,17 @@ func (p *Proxy) Publish(_ context.Context, params PublishParams) (map[string]spe
 }
 
 // Runner starts the proxy forwarder, relaying traffic from the allocated
-// listen port to the real target endpoint.
+// listen port to the real target endpoint. The forwarder serves on the
+// reserved listener, so the port is never unbound between allocation and
+// serving.
 func (p *Proxy) Runner(params StartParams) run.Runner {
+	ln := params.Reservation.Take("default")
 	return run.Func(func(ctx context.Context) error {
+		if ln != nil {
+			// Serve closes the listener; this covers early returns.
+			defer ln.Close()
+		}
+
 		var cfg ProxyConfig
 		if err := json.Unmarshal(params.Spec.Config, &cfg); err != nil {
 			return fmt.Errorf("proxy: unmarshal config: %w", err)
@@ -115,13 +155,70 @@ func (p *Proxy) Runner(params StartParams) run.Runner {
 			Ingress:    cfg.Ingress,
 			Protocol:   string(target.Protocol),
 			Emit:       params.ProxyEmit,
+			Listener:   ln,
+
+			MaxBodySize:  cfg.MaxBodySize,
+			AllowMethods: cfg.AllowMethods,
+			BodyLimit:    cfg.BodyLimit,
+
+			CapturePreview: cfg.CapturePreview,
+			AutoDetect:     cfg.AutoDetect,
+			ConnPool:       cfg.ConnPool,
+		}
+		if len(cfg.RedactHeaders) > 0 || len(cfg.RedactJSON) > 0 {
+			fwd.Redact = &proxy.Redaction{Headers: cfg.RedactHeaders, JSONPaths: cfg.RedactJSON}
+		}
+		if cfg.IdleTimeout != "" {
+			d, err := time.ParseDuration(cfg.IdleTimeout)
+			if err != nil {
+				return fmt.Errorf("proxy: invalid idle_timeout %q: %w", cfg.IdleTimeout, err)
+			}
+			fwd.IdleTimeout = d
+		}
+		// TLS targets are decoded only when the proxy can terminate TLS
+		// itself; otherwise the encrypted bytes are relayed opaquely.
+		if terminatesTLS(cfg, target) {
+			cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+			if err != nil {
+				return fmt.Errorf("proxy: load tls certificate: %w", err)
+			}
+			fwd.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+		} else if secureEndpoint(target) {
+			fwd.Protocol = string(spec.TCP)
+		}
+
+		if cfg.External != "" {
+			u, err := url.Parse(cfg.External)
+			if err != nil {
+				return fmt.Errorf("proxy: invalid external url %q: %w", cfg.External, err)
+			}
+			fwd.External = u
+		}
+
+		if fl := cfg.Fault; fl != nil {
+			fwd.Fault = &proxy.Fault{
+				Latency:   fl.Latency.Duration,
+				Jitter:    fl.Jitter.Duration,
+				DropRate:  fl.DropRate,
+				ErrorRate: fl.ErrorRate,
+			}
+		}
+
+		for _, m := range cfg.Mocks {
+			fwd.Mocks = append(fwd.Mocks, proxy.Mock{
+				Method:  m.Method,
+				Path:    m.Path,
+				Status:  m.Status,
+				Headers: m.Headers,
+				Body:    m.Body,
+			})
 		}
 
 		// For gRPC targets, check the reflection cache first, then
 		// fall back to a live probe. Results are cached by ReflectionKey
 		// (target service name + ingress) so identical targets across
 		// test runs share descriptors.
-		if target.Protocol == spec.GRPC {
+		if fwd.Protocol == string(spec.GRPC) {
 			if dec := p.cachedReflection(cfg.ReflectionKey); dec != nil {
 				fwd.Decoder = dec
 			} else {
@@ -129,8 +226,42 @@ func (p *Proxy) Runner(params StartParams) run.Runner {
 				fwd.Decoder = dec
 				p.cacheReflection(cfg.ReflectionKey, dec)
 			}
+			// Without reflection, decode from the configured descriptor
+			// set. Methods it doesn't declare keep their raw frames.
+			if fwd.Decoder == nil && cfg.ProtoDescriptors != "" {
+				key := "descriptors:" + cfg.ProtoDescriptors
+				if dec := p.cachedReflection(key); dec != nil {
+					fwd.Decoder = dec
+				} else if dec, err := proxy.LoadDescriptorSet(cfg.ProtoDescriptors); err == nil {
+					fwd.Decoder = dec
+					p.cacheReflection(key, dec)
+				}
+			}
+		}
+		// gRPC-Web reaches HTTP targets, which don't serve reflection, so
+		// only a configured descriptor set decodes it.
+		if fwd.Protocol == string(spec.HTTP) && cfg.ProtoDescriptors != "" {
+			key := "descriptors:" + cfg.ProtoDescriptors
+			if dec := p.cachedReflection(key); dec != nil {
+				fwd.Decoder = dec
+			} else if dec, err := proxy.LoadDescriptorSet(cfg.ProtoDescriptors); err == nil {
+				fwd.Decoder = dec
+				p.cacheReflection(key, dec)
+			}
 		}
 
 		return fwd.Runner().Run(ctx)
 	})
 }
+
+// secureEndpoint reports whether ep is marked SECURE, i.e. speaks TLS.
+func secureEndpoint(ep spec.Endpoint) bool {
+	secure, _ := ep.Attributes[string(connect.Secure)].(bool)
+	return secure
+}
+
+// terminatesTLS reports whether the proxy terminates TLS for target: it
+// must be a SECURE http endpoint and a certificate must be configured.
+func terminatesTLS(cfg ProxyConfig, target spec.Endpoint) bool {
+	return cfg.TLSCertFile != "" && target.Protocol == spec.HTTP && secureEndpoint(target)
+}
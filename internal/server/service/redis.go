@@ -1,13 +1,18 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
 
 	"github.com/matgreaves/rig/connect"
 	"github.com/matgreaves/rig/internal/server/artifact"
+	"github.com/matgreaves/rig/internal/server/ready"
 	"github.com/matgreaves/rig/internal/spec"
 	"github.com/matgreaves/run"
 )
@@ -64,15 +69,23 @@ func (r *Redis) Publish(ctx context.Context, params PublishParams) (map[string]s
 		}
 	}
 
-	// Inject REDIS_URL on all ingresses.
+	// Inject standard Redis attributes on all ingresses.
 	for name, ep := range endpoints {
 		connect.RedisURL.Set(ep.Attributes, fmt.Sprintf("redis://${HOST}:${PORT}/%s", lease.ID))
+		connect.RedisHost.Set(ep.Attributes, "${HOST}")
+		connect.RedisPort.Set(ep.Attributes, "${PORT}")
 		endpoints[name] = ep
 	}
 
 	return endpoints, nil
 }
 
+// ReadyCheck returns a checker that sends PING and waits for PONG, so the
+// service isn't marked ready while Redis is still starting up.
+func (r *Redis) ReadyCheck(ReadyCheckParams) ready.Checker {
+	return ready.Redis{}
+}
+
 // Runner returns a runner that blocks on ctx and releases the lease on exit.
 // The shared container is managed by the pool — no per-test container start.
 func (r *Redis) Runner(params StartParams) run.Runner {
@@ -95,6 +108,94 @@ func (r *Redis) Runner(params StartParams) run.Runner {
 	})
 }
 
+// redisHookConfig is the Config payload for "redis" hooks.
+type redisHookConfig struct {
+	Commands []string `json:"commands"`
+}
+
+// Init handles server-side hooks for the Redis service type. Supports
+// "redis", which runs each command via redis-cli against the per-test
+// database.
+func (r *Redis) Init(ctx context.Context, params InitParams) error {
+	if params.Hook.Type != "redis" {
+		return fmt.Errorf("redis: unsupported hook type %q", params.Hook.Type)
+	}
+	var cfg redisHookConfig
+	if err := json.Unmarshal(params.Hook.Config, &cfg); err != nil {
+		return fmt.Errorf("redis: invalid redis hook config: %w", err)
+	}
+
+	key := leaseKey(params.InstanceID, params.ServiceName)
+	v, ok := r.leases.Load(key)
+	if !ok {
+		return fmt.Errorf("redis init: no lease for %s", key)
+	}
+	lease := v.(*Lease)
+
+	for _, command := range cfg.Commands {
+		args, err := splitRedisCommand(command)
+		if err != nil {
+			return fmt.Errorf("redis init: command %q: %w", command, err)
+		}
+		if len(args) == 0 {
+			continue
+		}
+		// --no-raw prints error replies as "(error) ..." even without a
+		// TTY. redis-cli exits 0 on error replies in older versions, so
+		// the output is checked as well as the exit code.
+		var out bytes.Buffer
+		cmd := append([]string{"redis-cli", "--no-raw", "-n", lease.ID}, args...)
+		err = ExecInContainer(ctx, lease.Data.(string), cmd, io.MultiWriter(&out, params.Stdout), params.Stderr)
+		if err == nil && strings.HasPrefix(out.String(), "(error)") {
+			err = errors.New(strings.TrimSpace(strings.TrimPrefix(out.String(), "(error)")))
+		}
+		if err != nil {
+			return fmt.Errorf("redis init: command %q: %w", command, err)
+		}
+	}
+	return nil
+}
+
+// splitRedisCommand splits a command line into arguments the way redis-cli
+// does: on whitespace, with single or double quotes grouping an argument.
+func splitRedisCommand(s string) ([]string, error) {
+	var (
+		args  []string
+		cur   strings.Builder
+		quote rune
+		inArg bool
+	)
+	for _, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
 // redisImage returns the configured image or the default.
 func redisImage(raw json.RawMessage) string {
 	if raw != nil {
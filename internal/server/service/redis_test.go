@@ -0,0 +1,33 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitRedisCommand(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"SET foo bar", []string{"SET", "foo", "bar"}},
+		{"  HSET  user:1 name  'Ada Lovelace' ", []string{"HSET", "user:1", "name", "Ada Lovelace"}},
+		{`SET greeting "hello world"`, []string{"SET", "greeting", "hello world"}},
+		{`SET empty ""`, []string{"SET", "empty", ""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := splitRedisCommand(tt.in)
+		if err != nil {
+			t.Errorf("splitRedisCommand(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitRedisCommand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := splitRedisCommand(`SET k "open`); err == nil {
+		t.Error("expected error for unterminated quote")
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"net"
+	"sync"
+)
+
+// Reservation holds the listeners allocated for a service's ingresses open
+// from publish until the service starts, so no other process can bind the
+// ports in between. Types that serve in-process (proxies) Take their
+// listener and serve on it directly, so there is no gap at all. The
+// lifecycle Releases whatever is left immediately before the service's
+// runner starts, leaving only that short window for external processes
+// and containers that must bind the port themselves.
+//
+// A nil *Reservation is valid and holds nothing.
+type Reservation struct {
+	mu        sync.Mutex
+	listeners map[string]net.Listener // ingress name → open listener
+}
+
+// NewReservation returns a Reservation holding listeners, keyed by ingress
+// name.
+func NewReservation(listeners map[string]net.Listener) *Reservation {
+	return &Reservation{listeners: listeners}
+}
+
+// Take transfers ownership of the listener reserved for ingress to the
+// caller, who becomes responsible for closing it. Returns nil if there is
+// none or it has already been taken or released.
+func (r *Reservation) Take(ingress string) net.Listener {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ln := r.listeners[ingress]
+	delete(r.listeners, ingress)
+	return ln
+}
+
+// Release closes every listener that has not been taken. Safe to call more
+// than once.
+func (r *Reservation) Release() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, ln := range r.listeners {
+		ln.Close()
+		delete(r.listeners, name)
+	}
+}
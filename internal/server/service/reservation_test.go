@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/matgreaves/rig/internal/server/service"
+)
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ln
+}
+
+func TestReservation_HoldsPortUntilRelease(t *testing.T) {
+	ln := listen(t)
+	addr := ln.Addr().String()
+	r := service.NewReservation(map[string]net.Listener{"default": ln})
+
+	if _, err := net.Listen("tcp", addr); err == nil {
+		t.Fatal("reserved port was bindable before release")
+	}
+
+	r.Release()
+	r.Release() // idempotent
+
+	rebound, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("port not free after release: %v", err)
+	}
+	rebound.Close()
+}
+
+func TestReservation_TakeTransfersOwnership(t *testing.T) {
+	taken := listen(t)
+	other := listen(t)
+	r := service.NewReservation(map[string]net.Listener{"default": taken, "admin": other})
+
+	ln := r.Take("default")
+	if ln != taken {
+		t.Fatalf("Take returned %v, want reserved listener", ln)
+	}
+	if again := r.Take("default"); again != nil {
+		t.Errorf("second Take returned %v, want nil", again)
+	}
+
+	r.Release()
+
+	// The taken listener survives Release; the other is closed.
+	if _, err := net.Listen("tcp", taken.Addr().String()); err == nil {
+		t.Error("taken listener was closed by Release")
+	}
+	rebound, err := net.Listen("tcp", other.Addr().String())
+	if err != nil {
+		t.Errorf("untaken listener still open after Release: %v", err)
+	} else {
+		rebound.Close()
+	}
+	taken.Close()
+}
+
+func TestReservation_Nil(t *testing.T) {
+	var r *service.Reservation
+	if ln := r.Take("default"); ln != nil {
+		t.Errorf("nil Take = %v, want nil", ln)
+	}
+	r.Release()
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os/exec"
 	"runtime"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/matgreaves/rig/connect"
@@ -20,8 +24,24 @@ const (
 // TemporalConfig is the type-specific config for "temporal" services.
 type TemporalConfig struct {
 	Version string `json:"version,omitempty"`
+
+	// Namespace, when set, replaces the per-test namespace in the
+	// TEMPORAL_NAMESPACE attribute. It is created by the "temporal" init
+	// hook and kept on the shared dev server, so tests that name the same
+	// namespace share it.
+	Namespace string `json:"namespace,omitempty"`
+
+	// SearchAttributes maps custom search attribute names to their types
+	// ("Keyword", "Text", "Int", "Double", "Bool", "Datetime",
+	// "KeywordList"). They are registered on the namespace by the
+	// "temporal" init hook.
+	SearchAttributes map[string]string `json:"search_attributes,omitempty"`
 }
 
+// temporalSearchAttributeTypes are the types accepted by
+// `temporal operator search-attribute create`.
+var temporalSearchAttributeTypes = []string{"Keyword", "Text", "Int", "Double", "Bool", "Datetime", "KeywordList"}
+
 // Temporal implements Type and ArtifactProvider for the "temporal" builtin
 // service type. It uses a Pool to share dev server processes across
 // environments, providing per-test namespace isolation.
@@ -40,6 +60,12 @@ func NewTemporal(pool *Pool) *Temporal {
 // binary is downloaded before any Acquire call.
 func (t *Temporal) Artifacts(params ArtifactParams) ([]artifact.Artifact, error) {
 	cfg := temporalConfig(params.Spec.Config)
+	for name, typ := range cfg.SearchAttributes {
+		if !slices.Contains(temporalSearchAttributeTypes, typ) {
+			return nil, fmt.Errorf("service %q: search attribute %q has invalid type %q: want one of %s",
+				params.ServiceName, name, typ, strings.Join(temporalSearchAttributeTypes, ", "))
+		}
+	}
 	url := temporalDownloadURL(cfg.Version)
 	key := temporalArtifactKey(cfg.Version)
 	return []artifact.Artifact{{
@@ -80,7 +106,11 @@ func (t *Temporal) Publish(ctx context.Context, params PublishParams) (map[strin
 	// Inject Temporal connection attributes on the default ingress.
 	if ep, ok := endpoints["default"]; ok {
 		connect.TemporalAddress.Set(ep.Attributes, "${HOSTPORT}")
-		connect.TemporalNamespace.Set(ep.Attributes, lease.ID)
+		ns := lease.ID
+		if cfg.Namespace != "" {
+			ns = cfg.Namespace
+		}
+		connect.TemporalNamespace.Set(ep.Attributes, ns)
 		endpoints["default"] = ep
 	}
 
@@ -109,6 +139,68 @@ func (t *Temporal) Runner(params StartParams) run.Runner {
 	})
 }
 
+// Init handles server-side hooks for the Temporal service type. Supports
+// "temporal", which creates the configured namespace and registers the
+// configured search attributes on the shared dev server. Both steps are
+// idempotent: a namespace or search attribute that already exists is left
+// as it is.
+func (t *Temporal) Init(ctx context.Context, params InitParams) error {
+	if params.Hook.Type != "temporal" {
+		return fmt.Errorf("temporal: unsupported hook type %q", params.Hook.Type)
+	}
+	key := leaseKey(params.InstanceID, params.ServiceName)
+	v, ok := t.leases.Load(key)
+	if !ok {
+		return fmt.Errorf("temporal init: no lease for %s", key)
+	}
+	lease := v.(*Lease)
+	data := lease.Data.(temporalLeaseData)
+	addr := fmt.Sprintf("%s:%d", lease.Host, data.GRPCPort)
+
+	cfg := temporalConfig(params.Spec.Config)
+	ns := lease.ID
+	if cfg.Namespace != "" {
+		ns = cfg.Namespace
+		err := temporalOperator(ctx, data.Binary, params,
+			"namespace", "create", ns, "--address", addr)
+		if err != nil {
+			return fmt.Errorf("temporal init: create namespace %s: %w", ns, err)
+		}
+	}
+
+	names := make([]string, 0, len(cfg.SearchAttributes))
+	for name := range cfg.SearchAttributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err := temporalOperator(ctx, data.Binary, params,
+			"search-attribute", "create",
+			"--name", name, "--type", cfg.SearchAttributes[name],
+			"--namespace", ns, "--address", addr)
+		if err != nil {
+			return fmt.Errorf("temporal init: create search attribute %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// temporalOperator runs `temporal operator args...`, writing its output to
+// the hook's log. A failure reporting that the object already exists is
+// treated as success.
+func temporalOperator(ctx context.Context, binary string, params InitParams, args ...string) error {
+	cmd := exec.CommandContext(ctx, binary, append([]string{"operator"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(strings.ToLower(string(out)), "already exists") {
+			return nil
+		}
+		return fmt.Errorf("%w\n%s", err, out)
+	}
+	params.Stdout.Write(out)
+	return nil
+}
+
 func temporalConfig(raw json.RawMessage) TemporalConfig {
 	cfg := TemporalConfig{
 		Version: temporalDefaultVersion,
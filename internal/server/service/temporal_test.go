@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matgreaves/rig/internal/spec"
+)
+
+func TestTemporalInit_NamespaceAndSearchAttributes(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	// A fake Temporal CLI that records its arguments and reports the
+	// namespace as already existing, as a shared dev server would on the
+	// second test to use it.
+	script := fmt.Sprintf(`#!/bin/sh
+echo "$@" >> %s
+case "$*" in
+*"namespace create"*) echo "Error: unable to create namespace: Namespace already exists." >&2; exit 1 ;;
+esac
+`, calls)
+	binary := filepath.Join(dir, "temporal")
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tp := NewTemporal(nil)
+	tp.leases.Store(leaseKey("inst-1", "temporal"), &Lease{
+		ID:   "rig_ns_1",
+		Host: "127.0.0.1",
+		Data: temporalLeaseData{GRPCPort: 7233, Binary: binary},
+	})
+	init := func(cfg string) error {
+		return tp.Init(context.Background(), InitParams{
+			ServiceName: "temporal",
+			InstanceID:  "inst-1",
+			Spec:        spec.Service{Config: json.RawMessage(cfg)},
+			Hook:        &spec.HookSpec{Type: "temporal"},
+			Stdout:      io.Discard,
+			Stderr:      io.Discard,
+		})
+	}
+
+	err := init(`{"namespace":"orders","search_attributes":{"Priority":"Int","CustomerId":"Keyword"}}`)
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	got, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"operator namespace create orders --address 127.0.0.1:7233",
+		"operator search-attribute create --name CustomerId --type Keyword --namespace orders --address 127.0.0.1:7233",
+		"operator search-attribute create --name Priority --type Int --namespace orders --address 127.0.0.1:7233",
+	}, "\n") + "\n"
+	if string(got) != want {
+		t.Errorf("temporal calls:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Without a namespace, search attributes go on the per-test namespace.
+	os.Remove(calls)
+	if err := init(`{"search_attributes":{"CustomerId":"Keyword"}}`); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	got, _ = os.ReadFile(calls)
+	if !strings.Contains(string(got), "--namespace rig_ns_1") || strings.Contains(string(got), "namespace create") {
+		t.Errorf("temporal calls = %q, want only a search attribute on rig_ns_1", got)
+	}
+
+	err = tp.Init(context.Background(), InitParams{ServiceName: "temporal", Hook: &spec.HookSpec{Type: "exec"}})
+	if err == nil || !strings.Contains(err.Error(), "unsupported hook type") {
+		t.Errorf("err = %v, want unsupported hook type", err)
+	}
+}
+
+func TestTemporalArtifacts_InvalidSearchAttributeType(t *testing.T) {
+	_, err := NewTemporal(nil).Artifacts(ArtifactParams{
+		ServiceName: "temporal",
+		Spec:        spec.Service{Config: json.RawMessage(`{"search_attributes":{"CustomerId":"String"}}`)},
+	})
+	if err == nil || !strings.Contains(err.Error(), `search attribute "CustomerId" has invalid type "String"`) {
+		t.Errorf("err = %v, want invalid search attribute type", err)
+	}
+}
@@ -18,6 +18,7 @@ import (
 type temporalLeaseData struct {
 	GRPCPort int
 	UIPort   int
+	Binary   string // the Temporal CLI, used by init hooks
 }
 
 // NewTemporalPool creates a Pool backed by Temporal dev server processes.
@@ -125,6 +126,7 @@ func (b *temporalBackend) NewLease(ctx context.Context) (string, any, error) {
 	return ns, temporalLeaseData{
 		GRPCPort: b.grpcPort,
 		UIPort:   b.uiPort,
+		Binary:   b.binaryPath,
 	}, nil
 }
 
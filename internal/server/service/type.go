@@ -51,6 +51,21 @@ type StartParams struct {
 	// ProxyEmit publishes a proxy event to the event log. Set for proxy
 	// service types; nil for all others.
 	ProxyEmit func(proxy.Event)
+
+	// Network is the environment's Docker network when the environment has
+	// a container network, or "" otherwise.
+	Network string
+
+	// NetworkEgresses holds egresses whose targets are attached to Network,
+	// addressed by service name and container port. Container types use
+	// these in place of the host-routed Egresses entries.
+	NetworkEgresses map[string]spec.Endpoint
+
+	// Reservation holds the allocated ingress ports open until the runner
+	// starts. Types that listen in-process may Take a listener during
+	// Runner instead of binding the port themselves; anything not taken is
+	// closed just before the runner runs.
+	Reservation *Reservation
 }
 
 // ArtifactParams is passed to ArtifactProvider.Artifacts.
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	riglog "github.com/matgreaves/rig/log"
+)
+
+// defaultTrafficLimit caps a GET /environments/{id}/traffic page when the
+// client doesn't pass ?limit=.
+const defaultTrafficLimit = 100
+
+// trafficPage is the response body of GET /environments/{id}/traffic.
+type trafficPage struct {
+	Events []riglog.Event `json:"events"`
+
+	// More is true when events past the last one returned also matched;
+	// fetch them with ?after_seq= set to its seq.
+	More bool `json:"more"`
+}
+
+// handleGetTraffic handles GET /environments/{id}/traffic.
+//
+// Returns the environment's traffic events that match the query, in
+// sequence order, using the same filters as `rig traffic`: ?edge=,
+// ?status=, ?protocol=, ?slow= (milliseconds), ?label= and ?trace=.
+// ?after_seq= and ?limit= page through the results.
+func (s *Server) handleGetTraffic(w http.ResponseWriter, r *http.Request) {
+	inst, ok := s.getInstance(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := riglog.TrafficFilter{
+		Edge:     q.Get("edge"),
+		Status:   q.Get("status"),
+		Protocol: q.Get("protocol"),
+		Label:    q.Get("label"),
+		Trace:    q.Get("trace"),
+	}
+	switch filter.Protocol {
+	case "", "http", "grpc", "tcp", "kafka", "redis", "nats", "mongo", "ws":
+	default:
+		writeError(w, http.StatusBadRequest, "protocol must be one of http, grpc, tcp, kafka, redis, nats, mongo, ws")
+		return
+	}
+	if v := q.Get("slow"); v != "" {
+		ms, err := strconv.ParseFloat(v, 64)
+		if err != nil || ms < 0 {
+			writeError(w, http.StatusBadRequest, "slow must be a non-negative number of milliseconds")
+			return
+		}
+		filter.SlowMs = ms
+	}
+	var afterSeq uint64
+	if v := q.Get("after_seq"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "after_seq must be a sequence number")
+			return
+		}
+		afterSeq = n
+	}
+	limit := defaultTrafficLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	page := trafficPage{Events: []riglog.Event{}}
+	// Traffic events are never service.log, so the lifecycle slice holds
+	// all of them.
+	for _, e := range inst.log.LifecycleSince(afterSeq) {
+		ev, ok := trafficEvent(e)
+		if !ok || !filter.Match(ev) {
+			continue
+		}
+		if len(page.Events) == limit {
+			page.More = true
+			break
+		}
+		page.Events = append(page.Events, ev)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// trafficEvent converts e to the log package's event type, which has the
+// same JSON shape, so the filters shared with `rig traffic` can run on it.
+// Non-traffic events are skipped without being converted.
+func trafficEvent(e Event) (riglog.Event, bool) {
+	switch e.Type {
+	case EventRequestCompleted, EventRequestMocked, EventGRPCCallCompleted, EventGRPCStreamClosed,
+		EventConnectionClosed, EventKafkaRequestCompleted, EventRedisCommandCompleted,
+		EventNATSMessage, EventMongoCommand, EventWebSocketClosed:
+	default:
+		return riglog.Event{}, false
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return riglog.Event{}, false
+	}
+	var ev riglog.Event
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return riglog.Event{}, false
+	}
+	return ev, true
+}
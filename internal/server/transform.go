@@ -2,7 +2,14 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/matgreaves/rig/internal/server/proxy"
 	"github.com/matgreaves/rig/internal/server/service"
 	"github.com/matgreaves/rig/internal/spec"
 )
@@ -51,6 +58,165 @@ func InsertTestNode(env *spec.Environment) {
 	}
 }
 
+// InsertExternalNodes gives every egress declared with an external URL a
+// target to point at: an injected "external" node named
+// "{egress}~external~{source}" publishing the URL's host and port. The
+// egress is retargeted to the node, keeping its mocks, so TransformObserve
+// then puts a proxy on the edge like any other.
+func InsertExternalNodes(env *spec.Environment) {
+	for svcName, svc := range env.Services {
+		for egressName, egress := range svc.Egresses {
+			if egress.External == "" {
+				continue
+			}
+			nodeName := egressName + "~external~" + svcName
+			cfgJSON, _ := json.Marshal(service.ExternalConfig{URL: egress.External})
+			env.Services[nodeName] = spec.Service{
+				Type:   "external",
+				Config: cfgJSON,
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.HTTP},
+				},
+				Injected: true,
+			}
+			svc.Egresses[egressName] = spec.EgressSpec{
+				Service: nodeName,
+				Ingress: "default",
+				Mocks:   egress.Mocks,
+			}
+		}
+	}
+}
+
+// ApplyStartupOrder turns each startup_order chain into DependsOn entries,
+// so every service in a chain waits for the one before it to be ready. Run
+// it before ExpandScale so an entry naming a scaled service waits for all
+// of its instances.
+func ApplyStartupOrder(env *spec.Environment) {
+	applyStartupOrder(env.Services, env.StartupOrder)
+}
+
+func applyStartupOrder(services map[string]spec.Service, order [][]string) {
+	for _, chain := range order {
+		for i := 1; i < len(chain); i++ {
+			prev, name := chain[i-1], chain[i]
+			svc, ok := services[name]
+			if !ok || slices.Contains(svc.DependsOn, prev) {
+				continue
+			}
+			// Clip so the append never writes into a slice shared with a
+			// copy of the map.
+			svc.DependsOn = append(slices.Clip(svc.DependsOn), prev)
+			services[name] = svc
+		}
+	}
+}
+
+// ExpandScale replaces every service with Scale above 1 by that many
+// copies named "{name}-0", "{name}-1", ... and routes egresses to it
+// through injected balancer nodes. Run it after InsertTestNode so the
+// ~test node's egresses are balanced too.
+//
+// For each egress edge (source → name.ingress):
+//  1. A balancer node named "{name}~lb~{source}" (or
+//     "{name}~{ingress}~lb~{source}" for non-default ingresses) gets an
+//     egress "backend-{i}" to the same ingress of every instance. The
+//     edge's method allowlist and mocks move onto these egresses, so
+//     TransformObserve proxies each balancer → instance hop and records
+//     traffic against the instance that served it.
+//  2. The source's egress is retargeted to the balancer's "default"
+//     ingress, keeping its name.
+//
+// The ~test node also gets an egress to each instance, so a test can
+// address one directly, and depends_on entries naming a scaled service
+// wait for all of its instances.
+func ExpandScale(env *spec.Environment) {
+	scaled := map[string][]string{} // service name → instance names
+	for _, name := range sortedKeys(env.Services) {
+		svc := env.Services[name]
+		if svc.Injected || svc.Scale <= 1 {
+			continue
+		}
+		delete(env.Services, name)
+		for i := range svc.Scale {
+			inst := svc
+			inst.Scale = 0
+			inst.Ingresses = maps.Clone(svc.Ingresses)
+			inst.Egresses = maps.Clone(svc.Egresses)
+			instName := name + "-" + strconv.Itoa(i)
+			env.Services[instName] = inst
+			scaled[name] = append(scaled[name], instName)
+		}
+	}
+	if len(scaled) == 0 {
+		return
+	}
+
+	for _, svcName := range sortedKeys(env.Services) {
+		svc := env.Services[svcName]
+		for egressName, egress := range svc.Egresses {
+			instances, ok := scaled[egress.Service]
+			if !ok {
+				continue
+			}
+			lbName := egress.Service + "~lb~" + svcName
+			if egress.Ingress != "default" {
+				lbName = egress.Service + "~" + egress.Ingress + "~lb~" + svcName
+			}
+			backends := make(map[string]spec.EgressSpec, len(instances))
+			for i, inst := range instances {
+				backends[fmt.Sprintf("%s%d", service.BalancerBackendPrefix, i)] = spec.EgressSpec{
+					Service:      inst,
+					Ingress:      egress.Ingress,
+					AllowMethods: egress.AllowMethods,
+					Mocks:        egress.Mocks,
+				}
+			}
+			protocol := env.Services[instances[0]].Ingresses[egress.Ingress].Protocol
+			cfgJSON, _ := json.Marshal(service.BalancerConfig{Source: svcName, Target: egress.Service})
+			env.Services[lbName] = spec.Service{
+				Type:   "balancer",
+				Config: cfgJSON,
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: protocol},
+				},
+				Egresses: backends,
+				Injected: true,
+			}
+			svc.Egresses[egressName] = spec.EgressSpec{
+				Service:  lbName,
+				Ingress:  "default",
+				Database: egress.Database,
+			}
+		}
+
+		var deps []string
+		for _, dep := range svc.DependsOn {
+			if instances, ok := scaled[dep]; ok {
+				deps = append(deps, instances...)
+			} else {
+				deps = append(deps, dep)
+			}
+		}
+		svc.DependsOn = deps
+		env.Services[svcName] = svc
+	}
+
+	if test, ok := env.Services["~test"]; ok {
+		for _, name := range slices.Sorted(maps.Keys(scaled)) {
+			for _, inst := range scaled[name] {
+				for ingressName := range env.Services[inst].Ingresses {
+					egressName := inst
+					if ingressName != "default" {
+						egressName = inst + "~" + ingressName
+					}
+					test.Egresses[egressName] = spec.EgressSpec{Service: inst, Ingress: ingressName}
+				}
+			}
+		}
+	}
+}
+
 // TransformObserve inserts proxy service nodes on every egress edge in the
 // graph when observe mode is enabled. Each proxy node sits between a source
 // service and its target, transparently forwarding traffic while capturing
@@ -63,6 +229,17 @@ func InsertTestNode(env *spec.Environment) {
 //     egress "target" pointing at the real target, and a ProxyConfig
 //  3. The source's egress is retargeted to the proxy node's "default" ingress
 //     — the egress name (map key) is unchanged, making the proxy transparent
+//
+// When the environment has a container network, edges between two
+// services that share the environment network are left untouched unless
+// they carry a gRPC method allowlist.
+//
+// Edges to external nodes (see InsertExternalNodes) are recorded with the
+// egress name as their target, and their proxy forwards to the external
+// URL itself.
+//
+// Edges into balancer nodes (see ExpandScale) are left alone; the proxies
+// on the balancer → instance edges record the balancer's own source.
 func TransformObserve(env *spec.Environment) {
 	if !env.Observe {
 		return
@@ -89,9 +266,17 @@ func TransformObserve(env *spec.Environment) {
 
 	for _, e := range edges {
 		targetSvc, ok := env.Services[e.egress.Service]
-		if !ok {
+		if !ok || targetSvc.Type == "balancer" {
 			continue
 		}
+		source := e.sourceSvc
+		if src := env.Services[e.sourceSvc]; src.Type == "balancer" {
+			var lb service.BalancerConfig
+			json.Unmarshal(src.Config, &lb)
+			source = lb.Source
+		}
+
+		fault := edgeFault(env, source, e.egress.Service)
 
 		targetIngress := e.egress.Ingress
 		targetIngressSpec, ok := targetSvc.Ingresses[targetIngress]
@@ -114,10 +299,43 @@ func TransformObserve(env *spec.Environment) {
 		}
 
 		cfg := service.ProxyConfig{
-			Source:        e.sourceSvc,
+			Source:        source,
 			TargetSvc:     e.egress.Service,
 			Ingress:       targetIngress,
 			ReflectionKey: reflectionKey,
+			AllowMethods:  e.egress.AllowMethods,
+			Mocks:         e.egress.Mocks,
+			Fault:         fault,
+		}
+		// Body size limits simulate an upstream gateway, so they only
+		// apply to traffic entering the environment from the test.
+		if source == "~test" {
+			cfg.MaxBodySize = targetIngressSpec.MaxBodySize
+		}
+		if targetSvc.Type == "external" {
+			var ext service.ExternalConfig
+			json.Unmarshal(targetSvc.Config, &ext)
+			cfg.TargetSvc = e.egressName
+			cfg.External = ext.URL
+		}
+		if targetIngressSpec.Protocol == spec.TCP {
+			cfg.IdleTimeout = env.TCPIdleTimeout
+			cfg.AutoDetect = env.ObserveAutoDetect
+		}
+		if targetIngressSpec.Protocol == spec.HTTP {
+			cfg.ConnPool = env.ObserveConnPool
+		}
+		cfg.BodyLimit = proxyBodyLimit(env.ObserveBodyLimit)
+		cfg.CapturePreview = proxyCapturePreview(env.ObserveBodyLimit)
+		cfg.ProtoDescriptors = env.ProtoDescriptors
+		if env.ObserveTLS != nil {
+			cfg.TLSCertFile = env.ObserveTLS.CertFile
+			cfg.TLSKeyFile = env.ObserveTLS.KeyFile
+		}
+		cfg.RedactHeaders = proxy.DefaultRedactHeaders
+		if r := env.ObserveRedact; r != nil {
+			cfg.RedactHeaders = r.Headers
+			cfg.RedactJSON = r.JSONPaths
 		}
 		cfgJSON, _ := json.Marshal(cfg)
 
@@ -129,10 +347,13 @@ func TransformObserve(env *spec.Environment) {
 					Protocol: targetIngressSpec.Protocol,
 				},
 			},
+			// The proxy waits on the target's gRPC services itself, so
+			// the probes bypass it and never show up as traffic.
 			Egresses: map[string]spec.EgressSpec{
 				"target": {
-					Service: e.egress.Service,
-					Ingress: targetIngress,
+					Service:             e.egress.Service,
+					Ingress:             targetIngress,
+					WaitForGRPCServices: e.egress.WaitForGRPCServices,
 				},
 			},
 			Injected: true,
@@ -141,9 +362,74 @@ func TransformObserve(env *spec.Environment) {
 		// Retarget the source's egress to the proxy node.
 		sourceSvc := env.Services[e.sourceSvc]
 		sourceSvc.Egresses[e.egressName] = spec.EgressSpec{
-			Service: proxyName,
-			Ingress: "default",
+			Service:  proxyName,
+			Ingress:  "default",
+			Database: e.egress.Database,
 		}
 		env.Services[e.sourceSvc] = sourceSvc
 	}
 }
+
+// edgeFault returns the environment's fault for traffic from source to
+// target, or nil. A fault naming a scaled service applies to each of its
+// instances.
+func edgeFault(env *spec.Environment, source, target string) *spec.FaultSpec {
+	for i, f := range env.Faults {
+		if instanceOf(env, source, f.Source) && instanceOf(env, target, f.Target) {
+			return &env.Faults[i]
+		}
+	}
+	return nil
+}
+
+// instanceOf reports whether name is the service want or, once ExpandScale
+// has replaced want with its instances, one of them.
+func instanceOf(env *spec.Environment, name, want string) bool {
+	if name == want {
+		return true
+	}
+	if _, ok := env.Services[want]; ok {
+		return false
+	}
+	n, ok := strings.CutPrefix(name, want+"-")
+	_, err := strconv.Atoi(n)
+	return ok && err == nil
+}
+
+// joinsNetwork reports whether a service is attached to the environment's
+// Docker network when the environment has a container network. Only plain
+// container services join — pooled backends and host processes keep
+// reaching their peers through host ports.
+func joinsNetwork(svc spec.Service) bool {
+	return svc.Type == "container" && !svc.Injected
+}
+
+// proxyCapturePreview maps the spec's observe_body_limit onto
+// proxy.Forwarder.CapturePreview. TCP previews are opt-in: only an explicit
+// positive limit (or -1, unlimited) enables them.
+func proxyCapturePreview(limit *int) int {
+	switch {
+	case limit == nil || *limit == 0:
+		return 0
+	case *limit < 0:
+		return math.MaxInt
+	default:
+		return *limit
+	}
+}
+
+// proxyBodyLimit maps the spec's observe_body_limit (nil = default,
+// 0 = disabled, -1 = unlimited) onto proxy.Forwarder.BodyLimit
+// (0 = default, negative = disabled).
+func proxyBodyLimit(limit *int) int {
+	switch {
+	case limit == nil:
+		return 0
+	case *limit == 0:
+		return -1
+	case *limit < 0:
+		return math.MaxInt
+	default:
+		return *limit
+	}
+}
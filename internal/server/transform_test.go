@@ -1,9 +1,11 @@
 package server
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/matryer/is"
+	"github.com/matgreaves/rig/internal/server/service"
 	"github.com/matgreaves/rig/internal/spec"
 )
 
@@ -202,7 +204,7 @@ func TestTransformObserve_CustomEgressName(t *testing.T) {
 					"default": {Protocol: spec.HTTP},
 				},
 				Egresses: map[string]spec.EgressSpec{
-					"database": {Service: "db", Ingress: "default"},
+					"database": {Service: "db", Ingress: "default", Database: "orders"},
 				},
 			},
 			"db": {
@@ -221,6 +223,90 @@ func TestTransformObserve_CustomEgressName(t *testing.T) {
 	apiSvc := env.Services["api"]
 	is.Equal(apiSvc.Egresses["database"].Service, "db~proxy~api")
 	is.Equal(apiSvc.Egresses["database"].Ingress, "default")
+	is.Equal(apiSvc.Egresses["database"].Database, "orders") // database selection survives retargeting
+}
+
+func TestTransformObserve_WaitForGRPCServicesMovesToProxy(t *testing.T) {
+	is := is.New(t)
+
+	env := &spec.Environment{
+		Name:    "test",
+		Observe: true,
+		Services: map[string]spec.Service{
+			"api": {
+				Type: "go",
+				Egresses: map[string]spec.EgressSpec{
+					"orders": {Service: "orders", Ingress: "default", WaitForGRPCServices: []string{"orders.v1.OrderService"}},
+				},
+			},
+			"orders": {
+				Type: "go",
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.GRPC},
+				},
+			},
+		},
+	}
+
+	TransformObserve(env)
+
+	// The proxy polls the target directly, so the reflection probes are
+	// never recorded as traffic; the source just waits for the proxy.
+	is.Equal(env.Services["api"].Egresses["orders"].WaitForGRPCServices, nil)
+	is.Equal(env.Services["orders~proxy~api"].Egresses["target"].WaitForGRPCServices, []string{"orders.v1.OrderService"})
+}
+
+func TestTransformObserve_Faults(t *testing.T) {
+	is := is.New(t)
+
+	fault := spec.FaultSpec{Source: "api", Target: "orders", ErrorRate: 0.5}
+	env := &spec.Environment{
+		Name:    "test",
+		Observe: true,
+		Faults:  []spec.FaultSpec{fault},
+		Services: map[string]spec.Service{
+			"api": {
+				Type: "container",
+				Egresses: map[string]spec.EgressSpec{
+					"orders": {Service: "orders", Ingress: "default"},
+					"users":  {Service: "users", Ingress: "default"},
+				},
+			},
+			"orders": {Type: "container", Ingresses: map[string]spec.IngressSpec{"default": {Protocol: spec.HTTP}}},
+			"users":  {Type: "container", Ingresses: map[string]spec.IngressSpec{"default": {Protocol: spec.HTTP}}},
+		},
+	}
+
+	TransformObserve(env)
+
+	// Only the faulted edge's proxy carries the fault.
+	var cfg service.ProxyConfig
+	is.NoErr(json.Unmarshal(env.Services["orders~proxy~api"].Config, &cfg))
+	is.Equal(cfg.Fault, &fault)
+	cfg = service.ProxyConfig{}
+	is.NoErr(json.Unmarshal(env.Services["users~proxy~api"].Config, &cfg))
+	is.Equal(cfg.Fault, nil)
+}
+
+func TestSelectDatabase(t *testing.T) {
+	is := is.New(t)
+
+	ep := spec.Endpoint{
+		HostPort: "127.0.0.1:5432",
+		Protocol: spec.TCP,
+		Attributes: map[string]any{
+			"PGDATABASE":        "rig_3",
+			"PGDATABASE_ORDERS": "rig_3_orders",
+		},
+	}
+
+	got, err := selectDatabase(ep, "orders")
+	is.NoErr(err)
+	is.Equal(got.Attributes["PGDATABASE"], "rig_3_orders")
+	is.Equal(ep.Attributes["PGDATABASE"], "rig_3") // source endpoint untouched
+
+	_, err = selectDatabase(ep, "billing")
+	is.True(err != nil)
 }
 
 func TestTransformObserve_NonDefaultIngress(t *testing.T) {
@@ -251,3 +337,311 @@ func TestTransformObserve_NonDefaultIngress(t *testing.T) {
 	_, ok = env.Services["temporal~ui~proxy~~test"]
 	is.True(ok) // ui ingress proxy
 }
+
+func TestTransformObserve_ContainerNetworkProxiesContainerEdges(t *testing.T) {
+	is := is.New(t)
+
+	env := &spec.Environment{
+		Name:             "test",
+		Observe:          true,
+		ContainerNetwork: true,
+		Services: map[string]spec.Service{
+			"api": {
+				Type: "container",
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.HTTP},
+				},
+				Egresses: map[string]spec.EgressSpec{
+					"backend": {Service: "backend", Ingress: "default"},
+					"db":      {Service: "db", Ingress: "default"},
+				},
+			},
+			"backend": {
+				Type: "container",
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.HTTP},
+				},
+			},
+			"db": {
+				Type: "postgres",
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.TCP},
+				},
+			},
+		},
+	}
+
+	InsertTestNode(env)
+	TransformObserve(env)
+
+	// container → container is observed like any other edge.
+	is.Equal(env.Services["api"].Egresses["backend"].Service, "backend~proxy~api")
+	is.Equal(env.Services["api"].Egresses["db"].Service, "db~proxy~api")
+
+	_, ok := env.Services["backend~proxy~~test"]
+	is.True(ok)
+}
+
+func TestTransformObserve_MaxBodySizeOnlyExternal(t *testing.T) {
+	is := is.New(t)
+
+	env := &spec.Environment{
+		Name:    "test",
+		Observe: true,
+		Services: map[string]spec.Service{
+			"api": {
+				Type: "process",
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.HTTP, MaxBodySize: 1 << 20},
+				},
+			},
+			"worker": {
+				Type: "process",
+				Egresses: map[string]spec.EgressSpec{
+					"api": {Service: "api", Ingress: "default"},
+				},
+			},
+		},
+	}
+
+	InsertTestNode(env)
+	TransformObserve(env)
+
+	proxyConfig := func(name string) service.ProxyConfig {
+		var cfg service.ProxyConfig
+		is.NoErr(json.Unmarshal(env.Services[name].Config, &cfg))
+		return cfg
+	}
+	is.Equal(proxyConfig("api~proxy~~test").MaxBodySize, int64(1<<20))
+	is.Equal(proxyConfig("api~proxy~worker").MaxBodySize, int64(0))
+}
+
+func TestInsertExternalNodes(t *testing.T) {
+	is := is.New(t)
+
+	env := &spec.Environment{
+		Name:    "test",
+		Observe: true,
+		Services: map[string]spec.Service{
+			"api": {
+				Type: "process",
+				Egresses: map[string]spec.EgressSpec{
+					"payments": {
+						External: "https://sandbox.example.com",
+						Mocks:    []spec.MockSpec{{Path: "/refunds"}},
+					},
+				},
+			},
+		},
+	}
+
+	InsertExternalNodes(env)
+
+	node, ok := env.Services["payments~external~api"]
+	is.True(ok)
+	is.Equal(node.Type, "external")
+	is.True(node.Injected)
+	is.Equal(env.Services["api"].Egresses["payments"].Service, "payments~external~api")
+
+	TransformObserve(env)
+
+	proxy := env.Services["payments~external~api~proxy~api"]
+	is.Equal(proxy.Egresses["target"].Service, "payments~external~api")
+	var cfg service.ProxyConfig
+	is.NoErr(json.Unmarshal(proxy.Config, &cfg))
+	is.Equal(cfg.TargetSvc, "payments") // traffic is recorded against the egress name
+	is.Equal(cfg.External, "https://sandbox.example.com")
+	is.Equal(len(cfg.Mocks), 1)
+	is.Equal(env.Services["api"].Egresses["payments"].Service, "payments~external~api~proxy~api")
+}
+
+func TestExpandScale(t *testing.T) {
+	is := is.New(t)
+
+	env := &spec.Environment{
+		Name:    "test",
+		Observe: true,
+		Services: map[string]spec.Service{
+			"api": {
+				Type:  "process",
+				Scale: 2,
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.HTTP},
+				},
+			},
+			"web": {
+				Type:      "process",
+				Egresses:  map[string]spec.EgressSpec{"api": {Service: "api", Ingress: "default"}},
+				DependsOn: []string{"api"},
+			},
+		},
+	}
+
+	InsertTestNode(env)
+	ExpandScale(env)
+
+	_, ok := env.Services["api"]
+	is.True(!ok) // replaced by its instances
+	is.Equal(env.Services["api-0"].Scale, 0)
+	is.Equal(env.Services["api-1"].Ingresses["default"].Protocol, spec.HTTP)
+	is.Equal(env.Services["web"].DependsOn, []string{"api-0", "api-1"})
+
+	lb := env.Services["api~lb~web"]
+	is.Equal(lb.Type, "balancer")
+	is.True(lb.Injected)
+	is.Equal(lb.Ingresses["default"].Protocol, spec.HTTP)
+	is.Equal(lb.Egresses["backend-0"].Service, "api-0")
+	is.Equal(lb.Egresses["backend-1"].Service, "api-1")
+	is.Equal(env.Services["web"].Egresses["api"].Service, "api~lb~web")
+
+	// The test reaches the service through its own balancer, and each
+	// instance directly.
+	test := env.Services["~test"]
+	is.Equal(test.Egresses["api"].Service, "api~lb~~test")
+	is.Equal(test.Egresses["api-1"].Service, "api-1")
+
+	TransformObserve(env)
+
+	// No proxy in front of the balancer; the proxies behind it record the
+	// original source against each instance.
+	is.Equal(env.Services["web"].Egresses["api"].Service, "api~lb~web")
+	proxy := env.Services["api-1~proxy~api~lb~web"]
+	is.Equal(env.Services["api~lb~web"].Egresses["backend-1"].Service, "api-1~proxy~api~lb~web")
+	var cfg service.ProxyConfig
+	is.NoErr(json.Unmarshal(proxy.Config, &cfg))
+	is.Equal(cfg.Source, "web")
+	is.Equal(cfg.TargetSvc, "api-1")
+}
+
+func TestApplyStartupOrder(t *testing.T) {
+	is := is.New(t)
+
+	env := &spec.Environment{
+		Name: "test",
+		Services: map[string]spec.Service{
+			"a": {Type: "process"},
+			"b": {Type: "process", Scale: 2},
+			"c": {Type: "process", DependsOn: []string{"b"}},
+		},
+		StartupOrder: [][]string{{"a", "b", "c"}},
+	}
+
+	ApplyStartupOrder(env)
+	is.Equal(env.Services["a"].DependsOn, []string(nil))
+	is.Equal(env.Services["b"].DependsOn, []string{"a"})
+	is.Equal(env.Services["c"].DependsOn, []string{"b"}) // already there
+
+	ExpandScale(env)
+	is.Equal(env.Services["b-0"].DependsOn, []string{"a"})
+	is.Equal(env.Services["c"].DependsOn, []string{"b-0", "b-1"})
+}
+
+func TestTransformObserve_AutoDetectOnlyTCP(t *testing.T) {
+	is := is.New(t)
+
+	env := &spec.Environment{
+		Name:              "test",
+		Observe:           true,
+		ObserveAutoDetect: true,
+		Services: map[string]spec.Service{
+			"api": {
+				Type: "process",
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.HTTP},
+					"raw":     {Protocol: spec.TCP},
+				},
+			},
+			"worker": {
+				Type: "process",
+				Egresses: map[string]spec.EgressSpec{
+					"api": {Service: "api", Ingress: "default"},
+					"raw": {Service: "api", Ingress: "raw"},
+				},
+			},
+		},
+	}
+
+	TransformObserve(env)
+
+	proxyConfig := func(name string) service.ProxyConfig {
+		var cfg service.ProxyConfig
+		is.NoErr(json.Unmarshal(env.Services[name].Config, &cfg))
+		return cfg
+	}
+	is.True(proxyConfig("api~raw~proxy~worker").AutoDetect)
+	is.True(!proxyConfig("api~proxy~worker").AutoDetect)
+}
+
+func TestTransformObserve_ConnPoolOnlyHTTP(t *testing.T) {
+	is := is.New(t)
+
+	env := &spec.Environment{
+		Name:            "test",
+		Observe:         true,
+		ObserveConnPool: 16,
+		Services: map[string]spec.Service{
+			"api": {
+				Type: "process",
+				Ingresses: map[string]spec.IngressSpec{
+					"default": {Protocol: spec.HTTP},
+					"raw":     {Protocol: spec.TCP},
+				},
+			},
+			"worker": {
+				Type: "process",
+				Egresses: map[string]spec.EgressSpec{
+					"api": {Service: "api", Ingress: "default"},
+					"raw": {Service: "api", Ingress: "raw"},
+				},
+			},
+		},
+	}
+
+	TransformObserve(env)
+
+	proxyConfig := func(name string) service.ProxyConfig {
+		var cfg service.ProxyConfig
+		is.NoErr(json.Unmarshal(env.Services[name].Config, &cfg))
+		return cfg
+	}
+	is.Equal(proxyConfig("api~proxy~worker").ConnPool, 16)
+	is.Equal(proxyConfig("api~raw~proxy~worker").ConnPool, 0)
+}
+
+func TestTransformObserve_Redact(t *testing.T) {
+	is := is.New(t)
+
+	newEnv := func(redact *spec.RedactSpec) *spec.Environment {
+		return &spec.Environment{
+			Name:          "test",
+			Observe:       true,
+			ObserveRedact: redact,
+			Services: map[string]spec.Service{
+				"api": {
+					Type:      "process",
+					Ingresses: map[string]spec.IngressSpec{"default": {Protocol: spec.HTTP}},
+				},
+				"worker": {
+					Type:     "process",
+					Egresses: map[string]spec.EgressSpec{"api": {Service: "api", Ingress: "default"}},
+				},
+			},
+		}
+	}
+	proxyConfig := func(env *spec.Environment) service.ProxyConfig {
+		TransformObserve(env)
+		var cfg service.ProxyConfig
+		is.NoErr(json.Unmarshal(env.Services["api~proxy~worker"].Config, &cfg))
+		return cfg
+	}
+
+	// Unset: the default headers.
+	cfg := proxyConfig(newEnv(nil))
+	is.Equal(cfg.RedactHeaders, []string{"Authorization", "Cookie", "Set-Cookie"})
+	is.Equal(len(cfg.RedactJSON), 0)
+
+	// Set: the spec's lists replace the default, even when empty.
+	cfg = proxyConfig(newEnv(&spec.RedactSpec{JSONPaths: []string{"user.email"}}))
+	is.Equal(len(cfg.RedactHeaders), 0)
+	is.Equal(cfg.RedactJSON, []string{"user.email"})
+}
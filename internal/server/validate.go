@@ -1,11 +1,20 @@
 package server
 
 import (
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"path"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/matgreaves/rig/internal/server/proxy"
+	"github.com/matgreaves/rig/internal/server/service"
 	"github.com/matgreaves/rig/internal/spec"
 )
 
@@ -18,16 +27,41 @@ var KnownServiceTypes = map[string]bool{
 	"go":        true,
 	"client":    true,
 	"postgres":  true,
+	"mysql":     true,
 	"temporal":  true,
 	"redis":     true,
 	"s3":        true,
 	"sqs":       true,
 	"kafka":     true,
+	"nats":      true,
+	"mongo":     true,
 	"custom":    true,
 	"proxy":     true,
+	"external":  true,
+	"balancer":  true,
 	"test":      true,
 }
 
+// scalableTypes are the service types that can run more than one instance.
+// Backing services (databases, brokers) keep state a copy wouldn't share.
+var scalableTypes = map[string]bool{
+	"container": true,
+	"process":   true,
+	"script":    true,
+	"go":        true,
+	"client":    true,
+	"custom":    true,
+}
+
+// restartableTypes are the service types whose process or container can be
+// rerun by restart_on_exit.
+var restartableTypes = map[string]bool{
+	"container": true,
+	"process":   true,
+	"script":    true,
+	"go":        true,
+}
+
 // ValidateEnvironment checks an environment spec for structural errors.
 // It calls ResolveDefaults first to fill in default values, then validates.
 // Returns all errors found (not just the first) so the user can fix them
@@ -54,21 +88,339 @@ func ValidateEnvironment(env *spec.Environment) []string {
 		}
 	}
 
+	if env.FakeNow != "" {
+		if _, err := time.Parse(time.RFC3339Nano, env.FakeNow); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid fake_now %q: must be an RFC 3339 time", env.FakeNow))
+		}
+	}
+
+	if env.TCPIdleTimeout != "" {
+		d, err := time.ParseDuration(env.TCPIdleTimeout)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Sprintf("invalid tcp_idle_timeout %q: %v", env.TCPIdleTimeout, err))
+		case d <= 0:
+			errs = append(errs, fmt.Sprintf("tcp_idle_timeout must be positive, got %q", env.TCPIdleTimeout))
+		case !env.Observe:
+			errs = append(errs, "tcp_idle_timeout requires observe")
+		}
+	}
+
+	if n := env.ObserveBodyLimit; n != nil {
+		switch {
+		case *n < -1:
+			errs = append(errs, fmt.Sprintf("observe_body_limit must be -1 (unlimited), 0 (disabled) or positive, got %d", *n))
+		case !env.Observe:
+			errs = append(errs, "observe_body_limit requires observe")
+		}
+	}
+
+	if env.ObserveAutoDetect && !env.Observe {
+		errs = append(errs, "observe_auto_detect requires observe")
+	}
+
+	switch {
+	case env.ObserveConnPool < 0:
+		errs = append(errs, fmt.Sprintf("observe_conn_pool must not be negative, got %d", env.ObserveConnPool))
+	case env.ObserveConnPool > 0 && !env.Observe:
+		errs = append(errs, "observe_conn_pool requires observe")
+	}
+
+	for k := range env.Metadata {
+		if k == "" || strings.ContainsAny(k, "=,") {
+			errs = append(errs, fmt.Sprintf("invalid metadata key %q: must be non-empty and not contain '=' or ','", k))
+		}
+	}
+
+	if env.ProtoDescriptors != "" {
+		if !env.Observe {
+			errs = append(errs, "proto_descriptors requires observe")
+		} else if _, err := proxy.LoadDescriptorSet(env.ProtoDescriptors); err != nil {
+			errs = append(errs, fmt.Sprintf("proto_descriptors: %v", err))
+		}
+	}
+
+	if t := env.ObserveTLS; t != nil {
+		switch {
+		case t.CertFile == "" || t.KeyFile == "":
+			errs = append(errs, "observe_tls requires cert_file and key_file")
+		case !env.Observe:
+			errs = append(errs, "observe_tls requires observe")
+		default:
+			if _, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile); err != nil {
+				errs = append(errs, fmt.Sprintf("observe_tls: %v", err))
+			}
+		}
+	}
+
+	if r := env.ObserveRedact; r != nil {
+		if !env.Observe {
+			errs = append(errs, "observe_redact requires observe")
+		}
+		for _, h := range r.Headers {
+			if h == "" {
+				errs = append(errs, "observe_redact: header names must not be empty")
+				break
+			}
+		}
+		for _, p := range r.JSONPaths {
+			if slices.Contains(strings.Split(p, "."), "") {
+				errs = append(errs, fmt.Sprintf("observe_redact: invalid JSON path %q: must be dot-separated field names", p))
+			}
+		}
+	}
+
 	// Sort service names for deterministic error ordering.
 	names := sortedKeys(env.Services)
 
 	for _, name := range names {
 		svc := env.Services[name]
 		errs = append(errs, validateService(name, svc, env.Services)...)
+		if !env.Observe {
+			for _, ingressName := range ingressNames(svc.Ingresses) {
+				if svc.Ingresses[ingressName].MaxBodySize != 0 {
+					errs = append(errs, fmt.Sprintf(
+						"service %q, ingress %q: max_body_size requires observe",
+						name, ingressName,
+					))
+				}
+			}
+			for _, egressName := range sortedEgressNames(svc.Egresses) {
+				if len(svc.Egresses[egressName].AllowMethods) > 0 {
+					errs = append(errs, fmt.Sprintf(
+						"service %q, egress %q: allow_methods requires observe",
+						name, egressName,
+					))
+				}
+				if len(svc.Egresses[egressName].Mocks) > 0 {
+					errs = append(errs, fmt.Sprintf(
+						"service %q, egress %q: mocks require observe",
+						name, egressName,
+					))
+				}
+				if svc.Egresses[egressName].External != "" {
+					errs = append(errs, fmt.Sprintf(
+						"service %q, egress %q: external requires observe",
+						name, egressName,
+					))
+				}
+			}
+		}
 	}
 
 	if cycle := detectCycle(env.Services); cycle != "" {
 		errs = append(errs, cycle)
 	}
 
+	errs = append(errs, validateStartupOrder(env)...)
+	errs = append(errs, validateFaults(env)...)
+
 	return errs
 }
 
+// validateFaults checks that every fault names an existing egress edge,
+// injects something, and has rates that are fractions.
+func validateFaults(env *spec.Environment) []string {
+	if len(env.Faults) == 0 {
+		return nil
+	}
+	var errs []string
+	if !env.Observe {
+		errs = append(errs, "faults require observe")
+	}
+	seen := make(map[[2]string]bool, len(env.Faults))
+	for _, f := range env.Faults {
+		prefix := fmt.Sprintf("fault %s→%s", f.Source, f.Target)
+		if seen[[2]string{f.Source, f.Target}] {
+			errs = append(errs, prefix+" is listed more than once")
+			continue
+		}
+		seen[[2]string{f.Source, f.Target}] = true
+
+		target, ok := env.Services[f.Target]
+		if !ok {
+			msg := fmt.Sprintf("%s: unknown target service %q", prefix, f.Target)
+			if suggestion := closestMatch(f.Target, env.Services); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			errs = append(errs, msg)
+		}
+		if f.Source != "~test" {
+			source, ok := env.Services[f.Source]
+			if !ok {
+				msg := fmt.Sprintf("%s: unknown source service %q", prefix, f.Source)
+				if suggestion := closestMatch(f.Source, env.Services); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+				errs = append(errs, msg)
+			} else if !hasEgressTo(source, f.Target) {
+				errs = append(errs, fmt.Sprintf("%s: %s has no egress to %s", prefix, f.Source, f.Target))
+			}
+		}
+
+		if f.Latency.Duration < 0 || f.Jitter.Duration < 0 {
+			errs = append(errs, prefix+": latency and jitter must not be negative")
+		}
+		if f.DropRate < 0 || f.DropRate > 1 {
+			errs = append(errs, fmt.Sprintf("%s: drop_rate must be between 0 and 1, got %g", prefix, f.DropRate))
+		}
+		if f.ErrorRate < 0 || f.ErrorRate > 1 {
+			errs = append(errs, fmt.Sprintf("%s: error_rate must be between 0 and 1, got %g", prefix, f.ErrorRate))
+		}
+		if f.Latency.Duration == 0 && f.Jitter.Duration == 0 && f.DropRate == 0 && f.ErrorRate == 0 {
+			errs = append(errs, prefix+": injects nothing; set latency, jitter, drop_rate or error_rate")
+		}
+		if f.ErrorRate > 0 && ok && !hasHTTPIngress(target) {
+			errs = append(errs, fmt.Sprintf("%s: error_rate requires an http ingress, %s has none", prefix, f.Target))
+		}
+	}
+	return errs
+}
+
+func hasEgressTo(svc spec.Service, target string) bool {
+	for _, e := range svc.Egresses {
+		if e.Service == target {
+			return true
+		}
+	}
+	return false
+}
+
+func hasHTTPIngress(svc spec.Service) bool {
+	for _, ing := range svc.Ingresses {
+		if ing.Protocol == spec.HTTP {
+			return true
+		}
+	}
+	return false
+}
+
+// validateStartupOrder checks that every startup_order chain names known
+// services at most once each, and that no chain, alone or combined with the
+// others, asks a service to start before something it depends on.
+func validateStartupOrder(env *spec.Environment) []string {
+	var errs []string
+	for _, chain := range env.StartupOrder {
+		seen := make(map[string]bool, len(chain))
+		for _, name := range chain {
+			if _, ok := env.Services[name]; !ok {
+				msg := fmt.Sprintf("startup_order references unknown service %q", name)
+				if suggestion := closestMatch(name, env.Services); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+				errs = append(errs, msg)
+				continue
+			}
+			if seen[name] {
+				errs = append(errs, fmt.Sprintf("startup_order lists service %q more than once", name))
+			}
+			seen[name] = true
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	for _, chain := range env.StartupOrder {
+		for i, before := range chain {
+			for _, after := range chain[i+1:] {
+				if dependsOn(env.Services, before, after) {
+					errs = append(errs, fmt.Sprintf(
+						"startup_order puts %q before %q, but %q depends on %q",
+						before, after, before, after,
+					))
+				}
+			}
+		}
+	}
+	if len(errs) > 0 || len(env.StartupOrder) < 2 {
+		return errs
+	}
+
+	// Each chain agrees with the graph, but two chains may still disagree
+	// with each other.
+	ordered := maps.Clone(env.Services)
+	applyStartupOrder(ordered, env.StartupOrder)
+	if cycle := detectCycle(ordered); cycle != "" {
+		errs = append(errs, "startup_order: "+cycle)
+	}
+	return errs
+}
+
+// dependsOn reports whether from reaches to by following egresses and
+// DependsOn, i.e. whether to must be ready before from starts.
+func dependsOn(services map[string]spec.Service, from, to string) bool {
+	seen := map[string]bool{from: true}
+	stack := []string{from}
+	for len(stack) > 0 {
+		svc := services[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		targets := slices.Clone(svc.DependsOn)
+		for _, e := range svc.Egresses {
+			targets = append(targets, e.Service)
+		}
+		for _, t := range targets {
+			if t == to {
+				return true
+			}
+			if !seen[t] {
+				seen[t] = true
+				stack = append(stack, t)
+			}
+		}
+	}
+	return false
+}
+
+// ValidationWarnings returns advisories about a valid environment spec:
+// settings that are allowed but worth a second look. They never stop the
+// environment from starting.
+func ValidationWarnings(env *spec.Environment) []string {
+	var warnings []string
+	if env.ContainerNetwork && env.Observe {
+		warnings = append(warnings,
+			"container_network with observe: every edge routes through the host proxy, so containers reach each other through host ports, not by service name; disable observe for name addressing",
+		)
+	}
+	for _, name := range sortedKeys(env.Services) {
+		svc := env.Services[name]
+		if svc.Type != "container" || svc.Config == nil {
+			continue
+		}
+		var cfg service.ContainerConfig
+		json.Unmarshal(svc.Config, &cfg)
+		if cfg.Privileged && hasExecHook(svc.Hooks) {
+			warnings = append(warnings, fmt.Sprintf(
+				"service %q: exec hooks run inside a privileged container, with full access to the host",
+				name,
+			))
+		}
+	}
+	return warnings
+}
+
+// hasExecHook reports whether hooks include an "exec" hook.
+func hasExecHook(hooks *spec.Hooks) bool {
+	if hooks == nil {
+		return false
+	}
+	for _, h := range slices.Concat(hooks.Prestart, hooks.Init) {
+		if h != nil && h.Type == "exec" {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedEgressNames(egresses map[string]spec.EgressSpec) []string {
+	names := make([]string, 0, len(egresses))
+	for name := range egresses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func sortedKeys(services map[string]spec.Service) []string {
 	names := make([]string, 0, len(services))
 	for name := range services {
@@ -88,32 +440,108 @@ func validateService(name string, svc spec.Service, allServices map[string]spec.
 		errs = append(errs, fmt.Sprintf("service %q: unknown type %q", name, svc.Type))
 	}
 
+	if svc.StopTimeout != nil && svc.StopTimeout.Duration < 0 {
+		errs = append(errs, fmt.Sprintf("service %q: stop_timeout must not be negative, got %s", name, svc.StopTimeout.Duration))
+	}
+
+	if svc.RestartOnExit < 0 {
+		errs = append(errs, fmt.Sprintf("service %q: restart_on_exit must not be negative, got %d", name, svc.RestartOnExit))
+	} else if svc.RestartOnExit > 0 && svc.Type != "" && !restartableTypes[svc.Type] {
+		errs = append(errs, fmt.Sprintf("service %q: restart_on_exit is not supported for %s services", name, svc.Type))
+	}
+
+	if svc.Type == "container" && svc.Config != nil {
+		var cfg service.ContainerConfig
+		json.Unmarshal(svc.Config, &cfg)
+		if len(cfg.ReadyExec) > 0 && cfg.DockerHealthcheck {
+			errs = append(errs, fmt.Sprintf("service %q: ready_exec and docker_healthcheck are mutually exclusive", name))
+		}
+		for _, c := range cfg.CapAdd {
+			if c == "" {
+				errs = append(errs, fmt.Sprintf("service %q: cap_add contains an empty capability", name))
+			}
+		}
+		for k := range cfg.Sysctls {
+			if k == "" {
+				errs = append(errs, fmt.Sprintf("service %q: sysctls contains an empty key", name))
+			}
+		}
+	}
+
 	// Validate ingresses (sorted for deterministic output).
 	for _, ingressName := range ingressNames(svc.Ingresses) {
 		ingress := svc.Ingresses[ingressName]
 
 		if !ingress.Protocol.Valid() {
 			errs = append(errs, fmt.Sprintf(
-				"service %q, ingress %q: invalid protocol %q (must be one of: tcp, http, grpc, kafka)",
+				"service %q, ingress %q: invalid protocol %q (must be one of: tcp, http, grpc, kafka, redis, nats, mongo)",
 				name, ingressName, ingress.Protocol,
 			))
 		}
 
+		if r := ingress.Ready; r != nil && r.Status != 0 && (r.Status < 100 || r.Status > 599) {
+			errs = append(errs, fmt.Sprintf(
+				"service %q, ingress %q: ready status %d is not an HTTP status",
+				name, ingressName, r.Status,
+			))
+		}
+		if r := ingress.Ready; r != nil && r.Successes < 0 {
+			errs = append(errs, fmt.Sprintf(
+				"service %q, ingress %q: ready successes must not be negative, got %d",
+				name, ingressName, r.Successes,
+			))
+		}
+		switch {
+		case ingress.MaxBodySize < 0:
+			errs = append(errs, fmt.Sprintf(
+				"service %q, ingress %q: max_body_size must be positive, got %d",
+				name, ingressName, ingress.MaxBodySize,
+			))
+		case ingress.MaxBodySize > 0 && ingress.Protocol != spec.HTTP:
+			errs = append(errs, fmt.Sprintf(
+				"service %q, ingress %q: max_body_size requires an http ingress",
+				name, ingressName,
+			))
+		}
+
 		// ContainerPort is optional for container types: if omitted, the
 		// host-allocated port is used as the container port (rig-native
 		// apps that read RIG_DEFAULT_PORT).
 	}
 
 	// Validate egresses (sorted for deterministic output).
-	egressNames := make([]string, 0, len(svc.Egresses))
-	for n := range svc.Egresses {
-		egressNames = append(egressNames, n)
-	}
-	sort.Strings(egressNames)
+	egressNames := sortedEgressNames(svc.Egresses)
 
 	for _, egressName := range egressNames {
 		egress := svc.Egresses[egressName]
 
+		for i, m := range egress.Mocks {
+			if m.Status != 0 && (m.Status < 100 || m.Status > 599) {
+				errs = append(errs, fmt.Sprintf(
+					"service %q, egress %q: mock %d: invalid status %d",
+					name, egressName, i, m.Status,
+				))
+			}
+			if _, err := path.Match(m.Path, ""); err != nil {
+				errs = append(errs, fmt.Sprintf(
+					"service %q, egress %q: mock %d: invalid path pattern %q",
+					name, egressName, i, m.Path,
+				))
+			}
+		}
+
+		if slices.Contains(egress.WaitForGRPCServices, "") {
+			errs = append(errs, fmt.Sprintf(
+				"service %q, egress %q: wait_for_grpc_services contains an empty service name",
+				name, egressName,
+			))
+		}
+
+		if egress.External != "" {
+			errs = append(errs, validateExternalEgress(name, egressName, egress)...)
+			continue
+		}
+
 		// Self-reference.
 		if egress.Service == name {
 			errs = append(errs, fmt.Sprintf(
@@ -146,14 +574,45 @@ func validateService(name string, svc spec.Service, allServices map[string]spec.
 			continue
 		}
 
+		// A logical database must be declared on a postgres target.
+		if egress.Database != "" {
+			if target.Type != "postgres" {
+				errs = append(errs, fmt.Sprintf(
+					"service %q, egress %q: database requires a postgres target, %q is %s",
+					name, egressName, egress.Service, target.Type,
+				))
+			} else if dbs := postgresDatabases(target); !slices.Contains(dbs, egress.Database) {
+				errs = append(errs, fmt.Sprintf(
+					"service %q, egress %q: target service %q declares no database %q (declared: %s)",
+					name, egressName, egress.Service, egress.Database, strings.Join(dbs, ", "),
+				))
+			}
+		}
+
 		if egress.Ingress != "" {
 			// Explicit ingress name — must exist on target.
-			if _, ok := target.Ingresses[egress.Ingress]; !ok {
+			ing, ok := target.Ingresses[egress.Ingress]
+			if !ok {
 				available := ingressNames(target.Ingresses)
 				errs = append(errs, fmt.Sprintf(
 					"service %q, egress %q: target service %q has no ingress %q (available: %s)",
 					name, egressName, egress.Service, egress.Ingress, strings.Join(available, ", "),
 				))
+			} else if len(egress.AllowMethods) > 0 && ing.Protocol != spec.GRPC {
+				errs = append(errs, fmt.Sprintf(
+					"service %q, egress %q: allow_methods requires a grpc ingress, %s/%s is %s",
+					name, egressName, egress.Service, egress.Ingress, ing.Protocol,
+				))
+			} else if len(egress.WaitForGRPCServices) > 0 && ing.Protocol != spec.GRPC {
+				errs = append(errs, fmt.Sprintf(
+					"service %q, egress %q: wait_for_grpc_services requires a grpc ingress, %s/%s is %s",
+					name, egressName, egress.Service, egress.Ingress, ing.Protocol,
+				))
+			} else if len(egress.Mocks) > 0 && ing.Protocol != spec.HTTP {
+				errs = append(errs, fmt.Sprintf(
+					"service %q, egress %q: mocks require an http ingress, %s/%s is %s",
+					name, egressName, egress.Service, egress.Ingress, ing.Protocol,
+				))
 			}
 		} else {
 			// ResolveDefaults would have resolved this if the target had
@@ -169,9 +628,170 @@ func validateService(name string, svc spec.Service, allServices map[string]spec.
 		}
 	}
 
+	for _, tq := range svc.TaskQueues {
+		if tq.Name == "" {
+			errs = append(errs, fmt.Sprintf("service %q: task queue name is required", name))
+			continue
+		}
+		if tq.Egress == "" {
+			if egresses := temporalEgresses(allServices, svc); len(egresses) == 0 {
+				errs = append(errs, fmt.Sprintf(
+					"service %q, task queue %q: service has no egress to a temporal service",
+					name, tq.Name,
+				))
+			} else {
+				errs = append(errs, fmt.Sprintf(
+					"service %q, task queue %q: service has %d egresses to temporal services — specify which one (%s)",
+					name, tq.Name, len(egresses), strings.Join(egresses, ", "),
+				))
+			}
+			continue
+		}
+		egress, ok := svc.Egresses[tq.Egress]
+		if !ok {
+			errs = append(errs, fmt.Sprintf(
+				"service %q, task queue %q: unknown egress %q",
+				name, tq.Name, tq.Egress,
+			))
+		} else if egress.External != "" {
+			errs = append(errs, fmt.Sprintf(
+				"service %q, task queue %q: egress %q is external, not temporal",
+				name, tq.Name, tq.Egress,
+			))
+		} else if target, ok := allServices[egress.Service]; ok && target.Type != "temporal" {
+			errs = append(errs, fmt.Sprintf(
+				"service %q, task queue %q: egress %q targets %q, which is %s, not temporal",
+				name, tq.Name, tq.Egress, egress.Service, target.Type,
+			))
+		}
+	}
+
+	// Logical database names are used unquoted in SQL and upper-cased into
+	// attribute names, so keep them to lowercase identifiers.
+	if svc.Type == "postgres" {
+		seen := make(map[string]bool)
+		for _, db := range postgresDatabases(svc) {
+			switch {
+			case !validDatabaseName(db):
+				errs = append(errs, fmt.Sprintf("service %q: invalid database name %q (use lowercase letters, digits and underscores)", name, db))
+			case seen[db]:
+				errs = append(errs, fmt.Sprintf("service %q: duplicate database %q", name, db))
+			}
+			seen[db] = true
+		}
+	}
+
+	// Test-set env vars may not replace the wiring itself.
+	for key := range svc.Env {
+		switch {
+		case key == "" || strings.ContainsAny(key, "=\x00"):
+			errs = append(errs, fmt.Sprintf("service %q: invalid env var name %q", name, key))
+		case key == "RIG_WIRING":
+			errs = append(errs, fmt.Sprintf("service %q: env cannot override RIG_WIRING", name))
+		}
+	}
+
+	// Instances of a scaled service take suffixed names, which must be free.
+	if svc.Scale < 0 {
+		errs = append(errs, fmt.Sprintf("service %q: scale must not be negative, got %d", name, svc.Scale))
+	} else if svc.Scale > 1 {
+		if !scalableTypes[svc.Type] {
+			errs = append(errs, fmt.Sprintf("service %q: scale is not supported for %s services", name, svc.Type))
+		}
+		for i := range svc.Scale {
+			inst := fmt.Sprintf("%s-%d", name, i)
+			if _, ok := allServices[inst]; ok {
+				errs = append(errs, fmt.Sprintf("service %q: instance name %q is already a service", name, inst))
+			}
+		}
+	}
+
+	// Ordering-only dependencies must name other, existing services.
+	for _, dep := range svc.DependsOn {
+		if dep == name {
+			errs = append(errs, fmt.Sprintf("service %q: depends_on cannot reference itself", name))
+			continue
+		}
+		if _, ok := allServices[dep]; !ok {
+			msg := fmt.Sprintf("service %q: depends_on references unknown service %q", name, dep)
+			if suggestion := closestMatch(dep, allServices); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			errs = append(errs, msg)
+		}
+	}
+
 	return errs
 }
 
+// postgresDatabases returns the logical databases declared in a postgres
+// service's config.
+func postgresDatabases(svc spec.Service) []string {
+	var cfg service.PostgresConfig
+	if svc.Config != nil {
+		json.Unmarshal(svc.Config, &cfg)
+	}
+	return cfg.Databases
+}
+
+// validDatabaseName reports whether db is a lowercase SQL identifier.
+func validDatabaseName(db string) bool {
+	if db == "" || db[0] < 'a' || db[0] > 'z' {
+		return false
+	}
+	for _, c := range db {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// validateExternalEgress checks an egress to an address outside the
+// environment. The URL must be http or https and name a host the service
+// couldn't reach as a sibling: loopback addresses are refused, since a
+// local dependency belongs in the environment as a service.
+func validateExternalEgress(name, egressName string, egress spec.EgressSpec) []string {
+	prefix := fmt.Sprintf("service %q, egress %q", name, egressName)
+	var errs []string
+	if egress.Service != "" || egress.Ingress != "" {
+		errs = append(errs, fmt.Sprintf("%s: external cannot be combined with service or ingress", prefix))
+	}
+	if len(egress.AllowMethods) > 0 {
+		errs = append(errs, fmt.Sprintf("%s: allow_methods requires a grpc ingress, external targets are http", prefix))
+	}
+	if len(egress.WaitForGRPCServices) > 0 {
+		errs = append(errs, fmt.Sprintf("%s: wait_for_grpc_services requires a grpc ingress, external targets are http", prefix))
+	}
+	if egress.Database != "" {
+		errs = append(errs, fmt.Sprintf("%s: database requires a postgres target", prefix))
+	}
+
+	u, err := url.Parse(egress.External)
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Sprintf("%s: invalid external url: %v", prefix, err))
+	case u.Scheme != "http" && u.Scheme != "https":
+		errs = append(errs, fmt.Sprintf("%s: external url %q must be http or https", prefix, egress.External))
+	case u.Hostname() == "":
+		errs = append(errs, fmt.Sprintf("%s: external url %q has no host", prefix, egress.External))
+	case u.RawQuery != "" || u.Fragment != "" || u.User != nil:
+		errs = append(errs, fmt.Sprintf("%s: external url %q must be a base url without credentials, query or fragment", prefix, egress.External))
+	case isLoopbackHost(u.Hostname()):
+		errs = append(errs, fmt.Sprintf("%s: external url %q is a loopback address; declare local dependencies as services", prefix, egress.External))
+	}
+	return errs
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback IP.
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // ResolveDefaults fills in default values on the environment spec.
 // Called automatically by ValidateEnvironment.
 func ResolveDefaults(env *spec.Environment) {
@@ -194,12 +814,36 @@ func ResolveDefaults(env *spec.Environment) {
 				}
 			}
 		}
+
+		// Point task queues without an egress at the sole egress to a
+		// temporal service.
+		for i, tq := range svc.TaskQueues {
+			if tq.Egress != "" {
+				continue
+			}
+			if egresses := temporalEgresses(env.Services, svc); len(egresses) == 1 {
+				svc.TaskQueues[i].Egress = egresses[0]
+			}
+		}
 		env.Services[name] = svc
 	}
 }
 
-// detectCycle walks the egress dependency graph using DFS and returns a
-// descriptive error if a cycle is found. Returns "" if the graph is acyclic.
+// temporalEgresses returns the sorted names of svc's egresses that target
+// a temporal service.
+func temporalEgresses(services map[string]spec.Service, svc spec.Service) []string {
+	var names []string
+	for _, egressName := range sortedEgressNames(svc.Egresses) {
+		if services[svc.Egresses[egressName].Service].Type == "temporal" {
+			names = append(names, egressName)
+		}
+	}
+	return names
+}
+
+// detectCycle walks the dependency graph (egresses and DependsOn) using DFS
+// and returns a descriptive error if a cycle is found. Returns "" if the
+// graph is acyclic.
 func detectCycle(services map[string]spec.Service) string {
 	const (
 		unvisited = 0
@@ -223,15 +867,20 @@ func detectCycle(services map[string]spec.Service) string {
 
 		svc := services[name]
 
-		// Sort egress names for deterministic cycle path output.
+		// Sort egress names for deterministic cycle path output, then
+		// follow ordering-only dependencies.
 		egressOrder := make([]string, 0, len(svc.Egresses))
 		for n := range svc.Egresses {
 			egressOrder = append(egressOrder, n)
 		}
 		sort.Strings(egressOrder)
-
+		targets := make([]string, 0, len(egressOrder)+len(svc.DependsOn))
 		for _, eName := range egressOrder {
-			target := svc.Egresses[eName].Service
+			targets = append(targets, svc.Egresses[eName].Service)
+		}
+		targets = append(targets, svc.DependsOn...)
+
+		for _, target := range targets {
 			if _, ok := services[target]; !ok {
 				continue // broken ref — caught by validateService
 			}
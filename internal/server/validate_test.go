@@ -1,8 +1,19 @@
 package server_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/matgreaves/rig/internal/server"
 	"github.com/matgreaves/rig/internal/spec"
@@ -305,6 +316,167 @@ func TestValidateEnvironment_TwoNodeCycle(t *testing.T) {
 	assertContainsError(t, errs, "cycle detected")
 }
 
+func TestValidateEnvironment_DependsOn(t *testing.T) {
+	env := spec.Environment{
+		Name: "depends-on",
+		Services: map[string]spec.Service{
+			"migrate": {Type: "process", DependsOn: []string{"worker"}},
+			"worker": {
+				Type:      "process",
+				DependsOn: []string{"migrate", "migrat", "worker"},
+			},
+		},
+	}
+
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `depends_on references unknown service "migrat" (did you mean "migrate"?)`)
+	assertContainsError(t, errs, `service "worker": depends_on cannot reference itself`)
+	assertContainsError(t, errs, "cycle detected")
+}
+
+func TestValidateEnvironment_StartupOrder(t *testing.T) {
+	env := spec.Environment{
+		Name: "startup-order",
+		Services: map[string]spec.Service{
+			"db": {Type: "process", Ingresses: map[string]spec.IngressSpec{"default": {Protocol: spec.TCP}}},
+			"api": {
+				Type:     "process",
+				Egresses: map[string]spec.EgressSpec{"db": {Service: "db", Ingress: "default"}},
+			},
+			"worker": {Type: "process"},
+		},
+		StartupOrder: [][]string{{"db", "worker", "api"}},
+	}
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Fatalf("expected no errors, got: %v", errs)
+	}
+
+	env.StartupOrder = [][]string{{"worker", "api", "db"}, {"workr"}, {"db", "db"}}
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `startup_order references unknown service "workr" (did you mean "worker"?)`)
+	assertContainsError(t, errs, `startup_order lists service "db" more than once`)
+
+	env.StartupOrder = [][]string{{"worker", "api", "db"}}
+	errs = server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `startup_order puts "api" before "db", but "api" depends on "db"`)
+
+	// Each chain agrees with the graph, but not with the other.
+	env.StartupOrder = [][]string{{"worker", "db"}, {"api", "worker"}}
+	errs = server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, "startup_order: cycle detected")
+}
+
+func TestValidateEnvironment_FakeNow(t *testing.T) {
+	env := validEnv()
+	env.FakeNow = "2030-01-02T03:04:05Z"
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Fatalf("expected no errors, got: %v", errs)
+	}
+	env.FakeNow = "tomorrow"
+	assertContainsError(t, server.ValidateEnvironment(&env), `invalid fake_now "tomorrow"`)
+}
+
+func TestValidateEnvironment_Env(t *testing.T) {
+	env := spec.Environment{
+		Name: "env",
+		Services: map[string]spec.Service{
+			"api": {
+				Type: "process",
+				Env:  map[string]string{"LOG_LEVEL": "debug", "RIG_WIRING": "{}", "A=B": "c"},
+			},
+		},
+	}
+
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `service "api": env cannot override RIG_WIRING`)
+	assertContainsError(t, errs, `service "api": invalid env var name "A=B"`)
+	if len(errs) != 2 {
+		t.Errorf("got %d errors, want 2: %v", len(errs), errs)
+	}
+}
+
+func TestValidateEnvironment_StopTimeout(t *testing.T) {
+	env := validEnv()
+	api := env.Services["api"]
+	api.StopTimeout = &spec.Duration{}
+	env.Services["api"] = api
+	if errs := server.ValidateEnvironment(&env); len(errs) != 0 {
+		t.Errorf("zero stop timeout: unexpected errors: %v", errs)
+	}
+
+	api.StopTimeout = &spec.Duration{Duration: -time.Second}
+	env.Services["api"] = api
+	assertContainsError(t, server.ValidateEnvironment(&env), `service "api": stop_timeout must not be negative, got -1s`)
+}
+
+func TestValidateEnvironment_RestartOnExit(t *testing.T) {
+	env := validEnv()
+	api := env.Services["api"]
+	api.RestartOnExit = 3
+	env.Services["api"] = api
+	if errs := server.ValidateEnvironment(&env); len(errs) != 0 {
+		t.Errorf("restart_on_exit on a process: unexpected errors: %v", errs)
+	}
+
+	api.RestartOnExit = -1
+	env.Services["api"] = api
+	env.Services["db"] = spec.Service{Type: "postgres", RestartOnExit: 1}
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `service "api": restart_on_exit must not be negative, got -1`)
+	assertContainsError(t, errs, `service "db": restart_on_exit is not supported for postgres services`)
+}
+
+func TestValidateEnvironment_ContainerSecurity(t *testing.T) {
+	env := validEnv()
+	env.Services["vpn"] = spec.Service{
+		Type:   "container",
+		Config: json.RawMessage(`{"image":"vpn","cap_add":["NET_ADMIN",""],"sysctls":{"":"1"}}`),
+	}
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `service "vpn": cap_add contains an empty capability`)
+	assertContainsError(t, errs, `service "vpn": sysctls contains an empty key`)
+}
+
+func TestValidationWarnings_PrivilegedExec(t *testing.T) {
+	env := validEnv()
+	env.Services["vpn"] = spec.Service{
+		Type:   "container",
+		Config: json.RawMessage(`{"image":"vpn","privileged":true}`),
+	}
+	if warnings := server.ValidationWarnings(&env); len(warnings) != 0 {
+		t.Errorf("privileged without hooks: got warnings %v", warnings)
+	}
+
+	vpn := env.Services["vpn"]
+	vpn.Hooks = &spec.Hooks{Init: []*spec.HookSpec{{Type: "exec"}}}
+	env.Services["vpn"] = vpn
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Fatalf("expected no errors, got: %v", errs)
+	}
+	warnings := server.ValidationWarnings(&env)
+	assertContainsError(t, warnings, `service "vpn": exec hooks run inside a privileged container`)
+}
+
+func TestValidationWarnings_ContainerNetworkObserve(t *testing.T) {
+	env := validEnv()
+	env.ContainerNetwork = true
+	if warnings := server.ValidationWarnings(&env); len(warnings) != 0 {
+		t.Errorf("container_network without observe: got warnings %v", warnings)
+	}
+
+	env.Observe = true
+	assertContainsError(t, server.ValidationWarnings(&env), "container_network with observe")
+}
+
+func TestValidateEnvironment_ReadyExecWithDockerHealthcheck(t *testing.T) {
+	env := validEnv()
+	env.Services["search"] = spec.Service{
+		Type:   "container",
+		Config: json.RawMessage(`{"image":"search","ready_exec":["true"],"docker_healthcheck":true}`),
+	}
+	assertContainsError(t, server.ValidateEnvironment(&env), `service "search": ready_exec and docker_healthcheck are mutually exclusive`)
+}
+
 func TestValidateEnvironment_NoCycleFalsePositive(t *testing.T) {
 	// Diamond dependency: api → db, api → cache, worker → db
 	// No cycle — just shared dependencies.
@@ -590,3 +762,436 @@ func assertContainsError(t *testing.T, errs []string, substr string) {
 	}
 	t.Errorf("expected an error containing %q, got: %v", substr, errs)
 }
+
+func TestValidateEnvironment_AllowMethods(t *testing.T) {
+	env := validEnv()
+	env.Services["temporal"] = spec.Service{
+		Type: "process",
+		Ingresses: map[string]spec.IngressSpec{
+			"default": {Protocol: spec.GRPC},
+		},
+	}
+	env.Services["worker"] = spec.Service{
+		Type: "process",
+		Egresses: map[string]spec.EgressSpec{
+			"temporal": {Service: "temporal", AllowMethods: []string{"StartWorkflowExecution"}},
+			"api":      {Service: "api", AllowMethods: []string{"Get"}},
+		},
+	}
+
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `egress "api": allow_methods requires a grpc ingress`)
+	assertContainsError(t, errs, `egress "temporal": allow_methods requires observe`)
+
+	env.Observe = true
+	delete(env.Services["worker"].Egresses, "api")
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+}
+
+func TestValidateEnvironment_WaitForGRPCServices(t *testing.T) {
+	env := validEnv()
+	env.Services["orders"] = spec.Service{
+		Type: "process",
+		Ingresses: map[string]spec.IngressSpec{
+			"default": {Protocol: spec.GRPC},
+		},
+	}
+	env.Services["worker"] = spec.Service{
+		Type: "process",
+		Egresses: map[string]spec.EgressSpec{
+			"orders": {Service: "orders", WaitForGRPCServices: []string{"orders.v1.OrderService"}},
+		},
+	}
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+
+	env.Services["worker"].Egresses["api"] = spec.EgressSpec{Service: "api", WaitForGRPCServices: []string{"api.v1.API"}}
+	env.Services["worker"].Egresses["orders"] = spec.EgressSpec{Service: "orders", WaitForGRPCServices: []string{""}}
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `egress "api": wait_for_grpc_services requires a grpc ingress`)
+	assertContainsError(t, errs, `egress "orders": wait_for_grpc_services contains an empty service name`)
+}
+
+func TestValidateEnvironment_Mocks(t *testing.T) {
+	env := validEnv()
+	env.Services["db"] = spec.Service{
+		Type: "process",
+		Ingresses: map[string]spec.IngressSpec{
+			"default": {Protocol: spec.TCP},
+		},
+	}
+	env.Services["worker"] = spec.Service{
+		Type: "process",
+		Egresses: map[string]spec.EgressSpec{
+			"api": {Service: "api", Mocks: []spec.MockSpec{{Path: "/charges/*", Status: 503}}},
+			"db":  {Service: "db", Mocks: []spec.MockSpec{{Status: 200}}},
+		},
+	}
+
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `egress "db": mocks require an http ingress`)
+	assertContainsError(t, errs, `egress "api": mocks require observe`)
+
+	env.Observe = true
+	delete(env.Services["worker"].Egresses, "db")
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+
+	env.Services["worker"].Egresses["api"] = spec.EgressSpec{Service: "api", Mocks: []spec.MockSpec{{Path: "[", Status: 42}}}
+	errs = server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `mock 0: invalid status 42`)
+	assertContainsError(t, errs, `mock 0: invalid path pattern "["`)
+}
+
+func TestValidateEnvironment_MaxBodySize(t *testing.T) {
+	env := validEnv()
+	env.Services["api"].Ingresses["default"] = spec.IngressSpec{Protocol: spec.HTTP, MaxBodySize: 1 << 20}
+	assertContainsError(t, server.ValidateEnvironment(&env), `ingress "default": max_body_size requires observe`)
+
+	env.Observe = true
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+
+	env.Services["api"].Ingresses["default"] = spec.IngressSpec{Protocol: spec.HTTP, MaxBodySize: -1}
+	assertContainsError(t, server.ValidateEnvironment(&env), `ingress "default": max_body_size must be positive, got -1`)
+
+	env.Services["api"].Ingresses["default"] = spec.IngressSpec{Protocol: spec.TCP, MaxBodySize: 1 << 20}
+	assertContainsError(t, server.ValidateEnvironment(&env), `ingress "default": max_body_size requires an http ingress`)
+}
+
+func TestValidateEnvironment_TCPIdleTimeout(t *testing.T) {
+	env := validEnv()
+	env.TCPIdleTimeout = "5m"
+	assertContainsError(t, server.ValidateEnvironment(&env), "tcp_idle_timeout requires observe")
+
+	env.Observe = true
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+
+	env.TCPIdleTimeout = "soon"
+	assertContainsError(t, server.ValidateEnvironment(&env), `invalid tcp_idle_timeout "soon"`)
+	env.TCPIdleTimeout = "-1s"
+	assertContainsError(t, server.ValidateEnvironment(&env), "tcp_idle_timeout must be positive")
+}
+
+func TestValidateEnvironment_ObserveBodyLimit(t *testing.T) {
+	env := validEnv()
+	limit := 0
+	env.ObserveBodyLimit = &limit
+	assertContainsError(t, server.ValidateEnvironment(&env), "observe_body_limit requires observe")
+
+	env.Observe = true
+	for _, limit = range []int{-1, 0, 1 << 20} {
+		if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+			t.Errorf("limit %d: expected no errors, got: %v", limit, errs)
+		}
+	}
+
+	limit = -2
+	assertContainsError(t, server.ValidateEnvironment(&env), "observe_body_limit must be -1")
+}
+
+func TestValidateEnvironment_ObserveTLS(t *testing.T) {
+	env := validEnv()
+	env.ObserveTLS = &spec.TLSSpec{CertFile: "cert.pem"}
+	assertContainsError(t, server.ValidateEnvironment(&env), "observe_tls requires cert_file and key_file")
+
+	certFile, keyFile := writeTestKeyPair(t)
+	env.ObserveTLS = &spec.TLSSpec{CertFile: certFile, KeyFile: keyFile}
+	assertContainsError(t, server.ValidateEnvironment(&env), "observe_tls requires observe")
+
+	env.Observe = true
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+
+	env.ObserveTLS.KeyFile = filepath.Join(t.TempDir(), "missing.pem")
+	assertContainsError(t, server.ValidateEnvironment(&env), "observe_tls: open")
+}
+
+func TestValidateEnvironment_ObserveAutoDetect(t *testing.T) {
+	env := validEnv()
+	env.ObserveAutoDetect = true
+	assertContainsError(t, server.ValidateEnvironment(&env), "observe_auto_detect requires observe")
+
+	env.Observe = true
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+}
+
+func TestValidateEnvironment_ObserveConnPool(t *testing.T) {
+	env := validEnv()
+	env.ObserveConnPool = 8
+	assertContainsError(t, server.ValidateEnvironment(&env), "observe_conn_pool requires observe")
+
+	env.Observe = true
+	env.ObserveConnPool = -1
+	assertContainsError(t, server.ValidateEnvironment(&env), "observe_conn_pool must not be negative")
+
+	env.ObserveConnPool = 8
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+}
+
+func TestValidateEnvironment_ObserveRedact(t *testing.T) {
+	env := validEnv()
+	env.ObserveRedact = &spec.RedactSpec{JSONPaths: []string{"user.email"}}
+	assertContainsError(t, server.ValidateEnvironment(&env), "observe_redact requires observe")
+
+	env.Observe = true
+	env.ObserveRedact = &spec.RedactSpec{Headers: []string{""}, JSONPaths: []string{"user..email"}}
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, "header names must not be empty")
+	assertContainsError(t, errs, `invalid JSON path "user..email"`)
+
+	env.ObserveRedact = &spec.RedactSpec{Headers: []string{"X-Api-Key"}, JSONPaths: []string{"user.email"}}
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+}
+
+func TestValidateEnvironment_Faults(t *testing.T) {
+	env := validEnv()
+	env.Services["db"] = spec.Service{
+		Type:      "process",
+		Ingresses: map[string]spec.IngressSpec{"default": {Protocol: spec.TCP}},
+	}
+	api := env.Services["api"]
+	api.Egresses = map[string]spec.EgressSpec{"db": {Service: "db"}}
+	env.Services["api"] = api
+	env.Faults = []spec.FaultSpec{
+		{Source: "api", Target: "db", Latency: spec.Duration{Duration: time.Second}, DropRate: 0.1},
+		{Source: "~test", Target: "api", ErrorRate: 0.5},
+	}
+	assertContainsError(t, server.ValidateEnvironment(&env), "faults require observe")
+
+	env.Observe = true
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+
+	env.Faults = []spec.FaultSpec{
+		{Source: "api", Target: "db", DropRate: 1.5, ErrorRate: 0.5},
+		{Source: "api", Target: "db", DropRate: 0.5},
+		{Source: "db", Target: "api", Jitter: spec.Duration{Duration: -time.Second}},
+		{Source: "~test", Target: "apii"},
+	}
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, "fault api→db: drop_rate must be between 0 and 1, got 1.5")
+	assertContainsError(t, errs, "fault api→db: error_rate requires an http ingress, db has none")
+	assertContainsError(t, errs, "fault api→db is listed more than once")
+	assertContainsError(t, errs, "fault db→api: db has no egress to api")
+	assertContainsError(t, errs, "fault db→api: latency and jitter must not be negative")
+	assertContainsError(t, errs, `fault ~test→apii: unknown target service "apii" (did you mean "api"?)`)
+	assertContainsError(t, errs, "fault ~test→apii: injects nothing")
+}
+
+func TestValidateEnvironment_ExternalEgress(t *testing.T) {
+	withExternal := func(egress spec.EgressSpec) spec.Environment {
+		env := validEnv()
+		env.Observe = true
+		api := env.Services["api"]
+		api.Egresses = map[string]spec.EgressSpec{"payments": egress}
+		env.Services["api"] = api
+		return env
+	}
+
+	env := withExternal(spec.EgressSpec{External: "https://sandbox.example.com/v1"})
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+
+	env.Observe = false
+	assertContainsError(t, server.ValidateEnvironment(&env), `egress "payments": external requires observe`)
+
+	tests := []struct {
+		egress spec.EgressSpec
+		want   string
+	}{
+		{spec.EgressSpec{External: "ftp://example.com"}, "must be http or https"},
+		{spec.EgressSpec{External: "sandbox.example.com"}, "must be http or https"},
+		{spec.EgressSpec{External: "https://"}, "has no host"},
+		{spec.EgressSpec{External: "http://localhost:8080"}, "loopback"},
+		{spec.EgressSpec{External: "http://127.0.0.1"}, "loopback"},
+		{spec.EgressSpec{External: "http://[::1]:80"}, "loopback"},
+		{spec.EgressSpec{External: "https://user:pw@example.com"}, "without credentials"},
+		{spec.EgressSpec{External: "https://example.com", Service: "api"}, "cannot be combined with service"},
+		{spec.EgressSpec{External: "https://example.com", AllowMethods: []string{"Get"}}, "allow_methods requires a grpc ingress"},
+	}
+	for _, tt := range tests {
+		env := withExternal(tt.egress)
+		assertContainsError(t, server.ValidateEnvironment(&env), tt.want)
+	}
+}
+
+func TestValidateEnvironment_Scale(t *testing.T) {
+	env := validEnv()
+	api := env.Services["api"]
+	api.Scale = 3
+	env.Services["api"] = api
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Fatalf("expected no errors, got: %v", errs)
+	}
+
+	api.Scale = -1
+	env.Services["api"] = api
+	assertContainsError(t, server.ValidateEnvironment(&env), `service "api": scale must not be negative, got -1`)
+
+	api.Scale = 2
+	env.Services["api"] = api
+	env.Services["api-1"] = spec.Service{Type: "process"}
+	assertContainsError(t, server.ValidateEnvironment(&env), `service "api": instance name "api-1" is already a service`)
+
+	env = validEnv()
+	env.Services["db"] = spec.Service{Type: "postgres", Scale: 2}
+	assertContainsError(t, server.ValidateEnvironment(&env), `service "db": scale is not supported for postgres services`)
+}
+
+func TestValidateEnvironment_ReadySuccesses(t *testing.T) {
+	env := validEnv()
+	env.Services["api"].Ingresses["default"] = spec.IngressSpec{
+		Protocol: spec.HTTP,
+		Ready:    &spec.ReadySpec{Successes: 3},
+	}
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Fatalf("expected no errors, got: %v", errs)
+	}
+
+	env.Services["api"].Ingresses["default"].Ready.Successes = -1
+	assertContainsError(t, server.ValidateEnvironment(&env), `service "api", ingress "default": ready successes must not be negative, got -1`)
+}
+
+func TestValidateEnvironment_TaskQueues(t *testing.T) {
+	withWorker := func(egresses map[string]spec.EgressSpec, queues ...spec.TaskQueueSpec) spec.Environment {
+		env := validEnv()
+		env.Services["temporal"] = spec.Service{
+			Type:      "temporal",
+			Ingresses: map[string]spec.IngressSpec{"default": {Protocol: spec.GRPC}},
+		}
+		env.Services["worker"] = spec.Service{Type: "process", Egresses: egresses, TaskQueues: queues}
+		return env
+	}
+
+	// The sole temporal egress is filled in.
+	env := withWorker(map[string]spec.EgressSpec{
+		"temporal": {Service: "temporal"},
+		"api":      {Service: "api"},
+	}, spec.TaskQueueSpec{Name: "orders"})
+	if errs := server.ValidateEnvironment(&env); len(errs) > 0 {
+		t.Fatalf("expected no errors, got: %v", errs)
+	}
+	if got := env.Services["worker"].TaskQueues[0].Egress; got != "temporal" {
+		t.Errorf("egress = %q, want temporal", got)
+	}
+
+	env = withWorker(map[string]spec.EgressSpec{"api": {Service: "api"}}, spec.TaskQueueSpec{Name: "orders"})
+	assertContainsError(t, server.ValidateEnvironment(&env), `task queue "orders": service has no egress to a temporal service`)
+
+	env = withWorker(map[string]spec.EgressSpec{
+		"a": {Service: "temporal"},
+		"b": {Service: "temporal"},
+	}, spec.TaskQueueSpec{Name: "orders"})
+	assertContainsError(t, server.ValidateEnvironment(&env), "specify which one (a, b)")
+
+	env = withWorker(map[string]spec.EgressSpec{"api": {Service: "api"}}, spec.TaskQueueSpec{Name: "orders", Egress: "api"})
+	assertContainsError(t, server.ValidateEnvironment(&env), `egress "api" targets "api", which is process, not temporal`)
+
+	env = withWorker(map[string]spec.EgressSpec{"temporal": {Service: "temporal"}}, spec.TaskQueueSpec{Name: "orders", Egress: "tmprl"})
+	assertContainsError(t, server.ValidateEnvironment(&env), `unknown egress "tmprl"`)
+
+	env = withWorker(map[string]spec.EgressSpec{"temporal": {Service: "temporal"}}, spec.TaskQueueSpec{})
+	assertContainsError(t, server.ValidateEnvironment(&env), "task queue name is required")
+}
+
+func TestValidateEnvironment_ProtoDescriptors(t *testing.T) {
+	env := validEnv()
+	env.ProtoDescriptors = filepath.Join(t.TempDir(), "missing.pb")
+	assertContainsError(t, server.ValidateEnvironment(&env), "proto_descriptors requires observe")
+
+	env.Observe = true
+	assertContainsError(t, server.ValidateEnvironment(&env), "proto_descriptors: open")
+}
+
+func TestValidateEnvironment_Metadata(t *testing.T) {
+	env := validEnv()
+	env.Metadata = map[string]string{"pr": "1234", "shard": ""}
+	if errs := server.ValidateEnvironment(&env); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	env.Metadata = map[string]string{"a=b": "x", "": "y"}
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `invalid metadata key "a=b"`)
+	assertContainsError(t, errs, `invalid metadata key ""`)
+}
+
+// writeTestKeyPair writes a self-signed certificate for 127.0.0.1 and its
+// key to a temp dir, returning their paths.
+func writeTestKeyPair(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestValidateEnvironment_PostgresDatabases(t *testing.T) {
+	env := validEnv()
+	env.Services["db"] = spec.Service{
+		Type:      "postgres",
+		Config:    json.RawMessage(`{"databases":["orders","Billing","orders"]}`),
+		Ingresses: map[string]spec.IngressSpec{"default": {Protocol: spec.TCP}},
+	}
+	api := env.Services["api"]
+	api.Egresses = map[string]spec.EgressSpec{
+		"orders":  {Service: "db", Database: "orders"},
+		"billing": {Service: "db", Database: "billing"},
+	}
+	env.Services["api"] = api
+
+	errs := server.ValidateEnvironment(&env)
+	assertContainsError(t, errs, `service "db": invalid database name "Billing"`)
+	assertContainsError(t, errs, `service "db": duplicate database "orders"`)
+	assertContainsError(t, errs, `egress "billing": target service "db" declares no database "billing"`)
+	for _, e := range errs {
+		if strings.Contains(e, `egress "orders"`) {
+			t.Errorf("unexpected error for declared database: %s", e)
+		}
+	}
+
+	api.Egresses = map[string]spec.EgressSpec{"self": {Service: "other", Database: "orders"}}
+	env.Services["api"] = api
+	env.Services["other"] = spec.Service{
+		Type:      "process",
+		Ingresses: map[string]spec.IngressSpec{"default": {Protocol: spec.HTTP}},
+	}
+	assertContainsError(t, server.ValidateEnvironment(&env), `database requires a postgres target, "other" is process`)
+}
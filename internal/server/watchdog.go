@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -127,6 +128,11 @@ func buildDiagnosticSnapshot(events []Event, services map[string]spec.Service, s
 					ss.WaitingOn = append(ss.WaitingOn, egress.Service)
 				}
 			}
+			for _, dep := range svc.DependsOn {
+				if phases[dep] != "ready" && !slices.Contains(ss.WaitingOn, dep) {
+					ss.WaitingOn = append(ss.WaitingOn, dep)
+				}
+			}
 			sort.Strings(ss.WaitingOn)
 		}
 
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/matgreaves/rig/connect"
 	"github.com/matgreaves/rig/internal/spec"
 )
 
@@ -15,7 +17,11 @@ import (
 //   - RIG_WIRING: full wiring as JSON for rig-aware services
 //   - Service-level attributes (RIG_TEMP_DIR, RIG_ENV_DIR, etc.)
 //   - Own ingress attributes (HOST/PORT for default, prefixed for named)
-//   - Egress attributes (always prefixed by egress name)
+//   - Egress attributes (always prefixed by egress name, and again under
+//     RIG_EGRESS_<NAME>_)
+//
+// RIG_ENDPOINTS, the addresses of every service in the environment, is
+// added separately by addEndpointsEnv.
 //
 // Rig-aware services should read RIG_WIRING. The flat env vars are a
 // convenience fallback for services that don't know about rig.
@@ -118,6 +124,104 @@ func BuildPrestartHookEnv(
 	return BuildServiceEnv(serviceName, ingresses, egresses, tempDir, envDir, hostEnv)
 }
 
+// publishedEndpoints returns the address of every ingress published so far
+// in the environment, keyed by service then ingress name. Injected nodes
+// (proxies, the ~test node) are left out; their names contain "~".
+func publishedEndpoints(events []Event, envName string) map[string]map[string]string {
+	out := map[string]map[string]string{}
+	for _, e := range events {
+		if e.Type != EventIngressPublished || e.Environment != envName || e.Endpoint == nil {
+			continue
+		}
+		if strings.Contains(e.Service, "~") {
+			continue
+		}
+		if out[e.Service] == nil {
+			out[e.Service] = map[string]string{}
+		}
+		out[e.Service][e.Ingress] = e.Endpoint.HostPort
+	}
+	return out
+}
+
+// addEndpointsEnv sets RIG_ENDPOINTS to endpoints as JSON, giving shell
+// hooks and scripts a view of the whole environment rather than just the
+// service's own egresses:
+//
+//	{"api": {"default": "127.0.0.1:8193"}, "db": {"default": "127.0.0.1:8194"}}
+func addEndpointsEnv(env map[string]string, endpoints map[string]map[string]string) {
+	if b, err := json.Marshal(endpoints); err == nil {
+		env["RIG_ENDPOINTS"] = string(b)
+	}
+}
+
+// layerEnv returns a copy of base with each layer applied over it in turn,
+// later layers winning. A service's env is built in this order, lowest
+// first:
+//
+//	host env < fake clock and seed < dotenv < wiring vars < RIG_ENDPOINTS < Env
+//
+// RIG_WIRING is never taken from a layer, so a service can always recover
+// its full wiring. base is not modified.
+func layerEnv(base map[string]string, layers ...map[string]string) map[string]string {
+	n := len(base)
+	for _, l := range layers {
+		n += len(l)
+	}
+	env := make(map[string]string, n)
+	for k, v := range base {
+		env[k] = v
+	}
+	for _, l := range layers {
+		for k, v := range l {
+			if k == "RIG_WIRING" {
+				continue
+			}
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// withClockAndSeed returns env's host env with the environment-wide fake
+// clock and seed added as RIG_FAKE_NOW and RIG_SEED, or the host env
+// itself when neither is set. They form part of the base layer, so a
+// service's dotenv file or Env can still override them. The host env is
+// not modified.
+func withClockAndSeed(env *spec.Environment) map[string]string {
+	if env.FakeNow == "" && env.Seed == nil {
+		return env.HostEnv
+	}
+	out := make(map[string]string, len(env.HostEnv)+2)
+	for k, v := range env.HostEnv {
+		out[k] = v
+	}
+	if env.FakeNow != "" {
+		out[connect.FakeNowEnv] = env.FakeNow
+	}
+	if env.Seed != nil {
+		out[connect.SeedEnv] = strconv.FormatInt(*env.Seed, 10)
+	}
+	return out
+}
+
+// selectDatabase returns a copy of a postgres endpoint whose PGDATABASE
+// names the logical database db, as published by the target in
+// PGDATABASE_{DB}.
+func selectDatabase(ep spec.Endpoint, db string) (spec.Endpoint, error) {
+	name, ok := ep.Attributes[string(connect.PGDatabaseNamed(db))].(string)
+	if !ok {
+		return spec.Endpoint{}, fmt.Errorf("target has no database %q", db)
+	}
+	attrs := make(map[string]any, len(ep.Attributes))
+	for k, v := range ep.Attributes {
+		attrs[k] = v
+	}
+	connect.PGDatabase.Set(attrs, name)
+	ep.Attributes = attrs
+	return ep, nil
+}
+
 // addIngressAttrs adds ingress attributes to the env map.
 // If a "default" ingress exists, its attributes are unprefixed.
 // All other ingresses have their attributes prefixed by the ingress name.
@@ -132,11 +236,15 @@ func addIngressAttrs(env map[string]string, ingresses map[string]spec.ResolvedEn
 }
 
 // addEgressAttrs adds egress attributes to the env map.
-// Egresses are always prefixed by the egress name.
+// Egresses are always prefixed by the egress name. Each is also added under
+// the RIG_EGRESS_ namespace (e.g. "db" → RIG_EGRESS_DB_PGHOST), which can't
+// be shadowed by a named ingress or a host var, so command and arg
+// templates can reference it reliably.
 func addEgressAttrs(env map[string]string, egresses map[string]spec.ResolvedEndpoint) {
 	for name, ep := range egresses {
 		prefix := toEnvPrefix(name)
 		addEndpointAttrs(env, prefix, ep)
+		addEndpointAttrs(env, "RIG_EGRESS_"+prefix, ep)
 	}
 }
 
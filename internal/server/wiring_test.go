@@ -110,6 +110,34 @@ func TestBuildServiceEnv_HyphenatedEgressName(t *testing.T) {
 	assertEnvVar(t, env, "ORDER_DB_PORT", "5432")
 }
 
+func TestBuildServiceEnv_EgressNamespaced(t *testing.T) {
+	// A named ingress shares the DB_ prefix with the egress; the
+	// RIG_EGRESS_ namespace keeps the egress reachable.
+	ingresses := map[string]spec.Endpoint{
+		"db": {HostPort: "127.0.0.1:9000", Protocol: spec.TCP},
+	}
+	egresses := map[string]spec.Endpoint{
+		"db": {
+			HostPort:   "127.0.0.1:54321",
+			Protocol:   spec.TCP,
+			Attributes: map[string]any{"PGHOST": "${HOST}", "PGPORT": "${PORT}"},
+		},
+		"order-cache": {HostPort: "127.0.0.1:6379", Protocol: spec.TCP},
+	}
+
+	env, _ := server.BuildServiceEnv("api", ingresses, egresses, "/tmp", "/tmp", nil)
+
+	assertEnvVar(t, env, "RIG_EGRESS_DB_HOST", "127.0.0.1")
+	assertEnvVar(t, env, "RIG_EGRESS_DB_PORT", "54321")
+	assertEnvVar(t, env, "RIG_EGRESS_DB_PGHOST", "127.0.0.1")
+	assertEnvVar(t, env, "RIG_EGRESS_ORDER_CACHE_PORT", "6379")
+
+	got := server.ExpandTemplate("postgres://u@${RIG_EGRESS_DB_PGHOST}:${RIG_EGRESS_DB_PGPORT}/db", env)
+	if want := "postgres://u@127.0.0.1:54321/db"; got != want {
+		t.Errorf("ExpandTemplate = %q, want %q", got, want)
+	}
+}
+
 func TestBuildServiceEnv_NoDefaultIngress(t *testing.T) {
 	// A service with only named ingresses (no "default") — all should be prefixed.
 	ingresses := map[string]spec.Endpoint{
@@ -11,12 +11,26 @@ import (
 func DecodeEnvironment(data []byte) (Environment, error) {
 	// First, check for duplicate service names.
 	var raw struct {
-		Name     string                     `json:"name"`
-		Services map[string]json.RawMessage `json:"services"`
-		Observe  bool                       `json:"observe"`
-		HostEnv  map[string]string          `json:"host_env"`
-		Dir      string                     `json:"dir"`
-		TTL      string                     `json:"ttl"`
+		Name              string                     `json:"name"`
+		Services          map[string]json.RawMessage `json:"services"`
+		Observe           bool                       `json:"observe"`
+		HostEnv           map[string]string          `json:"host_env"`
+		Dir               string                     `json:"dir"`
+		TTL               string                     `json:"ttl"`
+		ContainerNetwork  bool                       `json:"container_network"`
+		TCPIdleTimeout    string                     `json:"tcp_idle_timeout"`
+		ObserveBodyLimit  *int                       `json:"observe_body_limit"`
+		ObserveAutoDetect bool                       `json:"observe_auto_detect"`
+		ObserveConnPool   int                        `json:"observe_conn_pool"`
+		ObserveTLS        *TLSSpec                   `json:"observe_tls"`
+		ObserveRedact     *RedactSpec                `json:"observe_redact"`
+		ProtoDescriptors  string                     `json:"proto_descriptors"`
+		Metadata          map[string]string          `json:"metadata"`
+		Reuse             bool                       `json:"reuse"`
+		StartupOrder      [][]string                 `json:"startup_order"`
+		FakeNow           string                     `json:"fake_now"`
+		Seed              *int64                     `json:"seed"`
+		Faults            []FaultSpec                `json:"faults"`
 	}
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return Environment{}, err
@@ -28,12 +42,26 @@ func DecodeEnvironment(data []byte) (Environment, error) {
 
 	// Now unmarshal each service and check for duplicate ingress/egress keys.
 	env := Environment{
-		Name:     raw.Name,
-		Services: make(map[string]Service, len(raw.Services)),
-		Observe:  raw.Observe,
-		HostEnv:  raw.HostEnv,
-		Dir:      raw.Dir,
-		TTL:      raw.TTL,
+		Name:              raw.Name,
+		Services:          make(map[string]Service, len(raw.Services)),
+		Observe:           raw.Observe,
+		HostEnv:           raw.HostEnv,
+		Dir:               raw.Dir,
+		TTL:               raw.TTL,
+		ContainerNetwork:  raw.ContainerNetwork,
+		TCPIdleTimeout:    raw.TCPIdleTimeout,
+		ObserveBodyLimit:  raw.ObserveBodyLimit,
+		ObserveAutoDetect: raw.ObserveAutoDetect,
+		ObserveConnPool:   raw.ObserveConnPool,
+		ObserveTLS:        raw.ObserveTLS,
+		ObserveRedact:     raw.ObserveRedact,
+		ProtoDescriptors:  raw.ProtoDescriptors,
+		Metadata:          raw.Metadata,
+		Reuse:             raw.Reuse,
+		StartupOrder:      raw.StartupOrder,
+		FakeNow:           raw.FakeNow,
+		Seed:              raw.Seed,
+		Faults:            raw.Faults,
 	}
 
 	for svcName, svcData := range raw.Services {
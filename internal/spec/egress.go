@@ -1,12 +1,60 @@
 package spec
 
-// EgressSpec declares a dependency from one service to another service's ingress.
+// EgressSpec declares a dependency from one service to another service's
+// ingress, or to an external address.
 type EgressSpec struct {
-	// Service is the name of the target service.
+	// Service is the name of the target service. Empty when External is set.
 	Service string `json:"service"`
 
 	// Ingress is the name of the target ingress on the target service.
 	// If omitted, defaults to the sole ingress on the target service.
 	// Validation fails if the target has multiple ingresses and this is empty.
 	Ingress string `json:"ingress,omitempty"`
+
+	// AllowMethods restricts a gRPC egress to the listed methods, given as
+	// "Method" or "pkg.Service/Method". The proxy on the edge answers any
+	// other call with PERMISSION_DENIED without forwarding it. Requires
+	// observe mode.
+	AllowMethods []string `json:"allow_methods,omitempty"`
+
+	// Mocks are canned responses the proxy on an HTTP edge serves instead
+	// of forwarding matching requests. The first matching mock wins;
+	// unmatched requests are forwarded as usual. Requires observe mode.
+	Mocks []MockSpec `json:"mocks,omitempty"`
+
+	// Database selects one of the logical databases declared on a postgres
+	// target. The egress's PGDATABASE attribute is rewritten to name it.
+	Database string `json:"database,omitempty"`
+
+	// External is an http or https base URL outside the environment (e.g.
+	// "https://sandbox.example.com") to use as the target instead of a
+	// service. The egress is routed through an observe proxy, so its
+	// traffic is recorded like any other edge's; the service is wired to
+	// the proxy and speaks plain HTTP to it. Requires observe mode.
+	External string `json:"external,omitempty"`
+
+	// WaitForGRPCServices are fully qualified gRPC services (e.g.
+	// "orders.v1.OrderService") the target must list through server
+	// reflection before the source service starts. A target that doesn't
+	// serve reflection only needs to pass the gRPC health check.
+	WaitForGRPCServices []string `json:"wait_for_grpc_services,omitempty"`
+}
+
+// MockSpec is a canned HTTP response served by an egress proxy.
+type MockSpec struct {
+	// Method restricts the mock to one request method. Empty matches any.
+	Method string `json:"method,omitempty"`
+
+	// Path is a path.Match pattern for the request path (e.g.
+	// "/charges/*"). Empty matches any path.
+	Path string `json:"path,omitempty"`
+
+	// Status is the response status code. Defaults to 200.
+	Status int `json:"status,omitempty"`
+
+	// Headers are set on the response.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is the response body.
+	Body string `json:"body,omitempty"`
 }
@@ -16,17 +16,20 @@ const (
 	HTTP  Protocol = "http"
 	GRPC  Protocol = "grpc"
 	Kafka Protocol = "kafka"
+	Redis Protocol = "redis"
+	NATS  Protocol = "nats"
+	Mongo Protocol = "mongo"
 )
 
 // ValidProtocols returns the set of recognised protocol values.
 func ValidProtocols() []Protocol {
-	return []Protocol{TCP, HTTP, GRPC, Kafka}
+	return []Protocol{TCP, HTTP, GRPC, Kafka, Redis, NATS, Mongo}
 }
 
 // Valid reports whether p is a recognised protocol.
 func (p Protocol) Valid() bool {
 	switch p {
-	case TCP, HTTP, GRPC, Kafka:
+	case TCP, HTTP, GRPC, Kafka, Redis, NATS, Mongo:
 		return true
 	}
 	return false
@@ -114,7 +117,7 @@ func (ep Endpoint) Resolve() (ResolvedEndpoint, error) {
 // ep.Attributes is never mutated.
 //
 // Returns an error if a template references an unknown variable
-// (e.g. ${TYPO} or ${HOOST}).
+// (e.g. ${TYPO} or ${HOOST}). A literal $ is written as $$.
 func ResolveAttributes(ep Endpoint) (map[string]any, error) {
 	if ep.Attributes == nil {
 		return nil, nil
@@ -128,6 +131,7 @@ func ResolveAttributes(ep Endpoint) (map[string]any, error) {
 		"HOST":     host,
 		"PORT":     portStr,
 		"HOSTPORT": ep.HostPort,
+		"$":        "$", // $$ escapes a literal $
 	}
 
 	resolved := make(map[string]any, len(ep.Attributes))
@@ -31,6 +31,109 @@ type Environment struct {
 	// sending DELETE on cleanup, allowing the environment to outlive the test
 	// process for manual inspection.
 	TTL string `json:"ttl,omitempty"`
+
+	// ContainerNetwork places every container-backed service on a dedicated
+	// Docker network for the environment. Without Observe, containers reach
+	// each other by service name over that network instead of through host
+	// ports. With Observe, edges still route through the host proxies, so
+	// name addressing doesn't apply. Ingress ports are still published on
+	// 127.0.0.1 so the test process can connect.
+	ContainerNetwork bool `json:"container_network,omitempty"`
+
+	// TCPIdleTimeout, as a Go duration string, makes observe proxies close
+	// TCP connections that carry no data for this long. Requires Observe.
+	TCPIdleTimeout string `json:"tcp_idle_timeout,omitempty"`
+
+	// ObserveBodyLimit caps the HTTP and gRPC body bytes observe proxies
+	// capture per request or response: 0 disables body capture and -1
+	// removes the cap. Nil keeps the 64KB default. Requires Observe.
+	ObserveBodyLimit *int `json:"observe_body_limit,omitempty"`
+
+	// ObserveAutoDetect makes observe proxies on TCP ingresses sniff each
+	// connection's first bytes and decode HTTP, gRPC and Kafka traffic
+	// they recognise. Requires Observe.
+	ObserveAutoDetect bool `json:"observe_auto_detect,omitempty"`
+
+	// ObserveConnPool makes observe proxies on HTTP ingresses keep up to
+	// this many idle connections to their target open for reuse, rather
+	// than the Go default of two. Requires Observe.
+	ObserveConnPool int `json:"observe_conn_pool,omitempty"`
+
+	// ObserveTLS supplies the certificate observe proxies present when
+	// terminating TLS on HTTP ingresses marked SECURE, so HTTPS traffic can
+	// be decoded. Without it such edges are relayed as opaque TCP.
+	// Requires Observe.
+	ObserveTLS *TLSSpec `json:"observe_tls,omitempty"`
+
+	// ObserveRedact lists the headers and JSON body fields observe proxies
+	// replace with "***" in captured traffic, so secrets never reach the
+	// event log. Nil redacts the Authorization, Cookie and Set-Cookie
+	// headers; when set, its Headers replace that list. Requires Observe.
+	ObserveRedact *RedactSpec `json:"observe_redact,omitempty"`
+
+	// ProtoDescriptors is the path of a binary FileDescriptorSet observe
+	// proxies use to decode gRPC bodies when the target doesn't serve
+	// reflection, and gRPC-Web bodies on HTTP edges. Requires Observe.
+	ProtoDescriptors string `json:"proto_descriptors,omitempty"`
+
+	// Metadata tags the environment with free-form key/value labels, e.g.
+	// the CI shard or pull request it ran for. It is recorded in the event
+	// log header so `rig ls` can show and filter by it.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Reuse attaches to a running environment created from an identical
+	// spec instead of creating a new one. Name and Metadata are not part
+	// of the comparison. The shared environment is torn down when the last
+	// client using it sends DELETE.
+	Reuse bool `json:"reuse,omitempty"`
+
+	// StartupOrder lists chains of service names that start one after
+	// another: each service waits for the one before it in its chain to be
+	// ready, as if it named it in DependsOn. Separate chains give a partial
+	// order. A chain must not contradict an egress or DependsOn edge.
+	StartupOrder [][]string `json:"startup_order,omitempty"`
+
+	// FakeNow, in RFC 3339 format, starts every service with a fixed clock
+	// at that time, passed as RIG_FAKE_NOW for connect.Now to read. A
+	// service's own RIG_FAKE_NOW takes priority.
+	FakeNow string `json:"fake_now,omitempty"`
+
+	// Seed is passed to every service as RIG_SEED, for connect.Rand to
+	// seed its generator with, so randomness repeats between runs.
+	Seed *int64 `json:"seed,omitempty"`
+
+	// Faults make observe proxies misbehave on purpose on the named edges,
+	// adding latency, dropping connections or answering HTTP requests with
+	// 503. Requires Observe.
+	Faults []FaultSpec `json:"faults,omitempty"`
+}
+
+// TLSSpec names a PEM certificate and key pair on the server's filesystem.
+type TLSSpec struct {
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+// RedactSpec names what observe proxies mask in captured traffic. Header
+// names match case-insensitively; JSON paths are dot-separated field names
+// ("user.email"), applied to each element of any array along the way.
+type RedactSpec struct {
+	Headers   []string `json:"headers,omitempty"`
+	JSONPaths []string `json:"json_paths,omitempty"`
+}
+
+// FaultSpec injects faults into the traffic from Source to Target. Source
+// may be "~test" for the test's own requests. Latency applies per HTTP or
+// gRPC request, or per connection for other protocols, plus a random
+// extra of up to Jitter. DropRate and ErrorRate are fractions from 0 to 1
+// of connections closed on accept and of HTTP requests answered with 503.
+type FaultSpec struct {
+	Source    string   `json:"source"`
+	Target    string   `json:"target"`
+	Latency   Duration `json:"latency,omitempty"`
+	Jitter    Duration `json:"jitter,omitempty"`
+	DropRate  float64  `json:"drop_rate,omitempty"`
+	ErrorRate float64  `json:"error_rate,omitempty"`
 }
 
 // ResolvedEnvironment is the runtime view of an environment after all
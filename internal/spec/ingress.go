@@ -17,4 +17,9 @@ type IngressSpec struct {
 	// Attributes are static attributes published with this ingress.
 	// Service types may add dynamic attributes at publish time.
 	Attributes map[string]any `json:"attributes,omitempty"`
+
+	// MaxBodySize, when positive, makes the external proxy reject HTTP
+	// requests from the test whose body exceeds this many bytes with 413,
+	// without forwarding them. Requires observe mode.
+	MaxBodySize int64 `json:"max_body_size,omitempty"`
 }
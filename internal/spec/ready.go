@@ -8,19 +8,28 @@ import (
 // ReadySpec configures the health check for an ingress.
 // If omitted, the check type is inferred from the ingress protocol.
 type ReadySpec struct {
-	// Type overrides the health check type ("tcp", "http", "grpc").
+	// Type overrides the health check type ("tcp", "http", "grpc", "redis", "nats").
 	// Defaults to the ingress protocol.
 	Type string `json:"type,omitempty"`
 
 	// Path is the HTTP GET path for HTTP checks. Default "/".
 	Path string `json:"path,omitempty"`
 
+	// Status is the HTTP status an HTTP check requires. Default: any
+	// status below 500.
+	Status int `json:"status,omitempty"`
+
 	// Interval is the poll interval. Default 10ms with exponential backoff.
 	Interval Duration `json:"interval,omitempty"`
 
 	// Timeout is the maximum wait for the service to become ready.
 	// Default from global timeout config.
 	Timeout Duration `json:"timeout,omitempty"`
+
+	// Successes is how many probes in a row must pass before the ingress
+	// is ready, for services that open their port and then drop it while
+	// warming up. A failure restarts the count. Default 1.
+	Successes int `json:"successes,omitempty"`
 }
 
 // Duration wraps time.Duration with JSON marshalling as a string
@@ -5,7 +5,7 @@ import "encoding/json"
 // Service defines a single service within an environment.
 type Service struct {
 	// Type identifies how to start the service (e.g. "container", "process",
-	// "go", "postgres", "temporal", "redis", "s3").
+	// "go", "postgres", "mysql", "temporal", "redis", "nats", "mongo", "s3").
 	Type string `json:"type"`
 
 	// Config holds type-specific configuration as raw JSON.
@@ -22,15 +22,62 @@ type Service struct {
 	// Egresses declares dependencies on other services' ingresses.
 	Egresses map[string]EgressSpec `json:"egresses,omitempty"`
 
+	// DependsOn names services that must be ready before this one starts.
+	// Unlike an egress it is ordering only: no endpoint is wired and no
+	// proxy is inserted.
+	DependsOn []string `json:"depends_on,omitempty"`
+
 	// Hooks defines lifecycle hooks for this service.
 	Hooks *Hooks `json:"hooks,omitempty"`
 
+	// DotEnv holds variables loaded from a dotenv file by the SDK. They
+	// are layered over the host environment and under the RIG_* wiring
+	// vars and any type-specific env config.
+	DotEnv map[string]string `json:"dotenv,omitempty"`
+
+	// Env holds extra environment variables set by the test. They are
+	// layered over the RIG_* wiring vars, except RIG_WIRING itself, which
+	// cannot be overridden.
+	Env map[string]string `json:"env,omitempty"`
+
+	// StopTimeout bounds how long a container or process gets to exit
+	// after being asked to stop before it is killed. Zero kills it
+	// immediately; nil keeps the type's default (10s for containers, no
+	// limit for processes).
+	StopTimeout *Duration `json:"stop_timeout,omitempty"`
+
+	// RestartOnExit restarts a process or container that exits on its own,
+	// up to this many times, emitting service.restarted for each. The
+	// environment fails only once the restarts are used up. Zero (the
+	// default) fails the environment on the first exit.
+	RestartOnExit int `json:"restart_on_exit,omitempty"`
+
+	// TaskQueues are Temporal task queues the service runs workers for.
+	// The service is not marked ready until each has a poller registered,
+	// so workflows started once the environment is up find a worker.
+	TaskQueues []TaskQueueSpec `json:"task_queues,omitempty"`
+
+	// Scale, when above 1, runs this many identical instances of the
+	// service, named "{name}-0", "{name}-1", and so on. Egresses to the
+	// service are spread across the instances, one connection at a time.
+	Scale int `json:"scale,omitempty"`
+
 	// Injected is true for virtual service nodes inserted by spec
 	// transformation (proxy nodes, ~test node). These are filtered from
 	// user-facing output, temp dirs, and artifact collection.
 	Injected bool `json:"injected,omitempty"`
 }
 
+// TaskQueueSpec names a Temporal task queue a service polls.
+type TaskQueueSpec struct {
+	// Name is the task queue name.
+	Name string `json:"name"`
+
+	// Egress is the service's egress to the Temporal server. If omitted,
+	// defaults to the sole egress targeting a temporal service.
+	Egress string `json:"egress,omitempty"`
+}
+
 // Hooks holds the optional prestart and init hooks for a service.
 type Hooks struct {
 	Prestart []*HookSpec `json:"prestart,omitempty"`
@@ -389,6 +389,28 @@ func TestResolveAttributes_Basic(t *testing.T) {
 	}
 }
 
+func TestResolveAttributes_EscapedDollar(t *testing.T) {
+	ep := spec.Endpoint{
+		HostPort: "10.0.0.1:5432",
+		Protocol: spec.TCP,
+		Attributes: map[string]any{
+			"SECRET": "pa$$word",
+			"MIXED":  "$${HOST}=${HOST}",
+		},
+	}
+
+	got, err := spec.ResolveAttributes(ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["SECRET"] != "pa$word" {
+		t.Errorf("SECRET = %v, want pa$word", got["SECRET"])
+	}
+	if got["MIXED"] != "${HOST}=10.0.0.1" {
+		t.Errorf("MIXED = %v, want ${HOST}=10.0.0.1", got["MIXED"])
+	}
+}
+
 func TestResolveAttributes_CompoundBuiltins(t *testing.T) {
 	ep := spec.Endpoint{
 		HostPort: "10.0.0.1:5432",
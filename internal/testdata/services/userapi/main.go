@@ -32,9 +32,10 @@ func run(ctx context.Context) error {
 		return err
 	}
 
-	pg := w.Egress("db")
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		pg.Host(), pg.Port(), pg.Attr("PGUSER"), pg.Attr("PGPASSWORD"), pg.Attr("PGDATABASE"))
+	dsn, err := connect.LookupPostgresDSN(w.Egress("db"))
+	if err != nil {
+		return err
+	}
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
@@ -0,0 +1,334 @@
+package log
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matgreaves/rig/connect"
+)
+
+// Event types written to a log. The list matches the server's; a log
+// from a newer server may contain types not listed here.
+const (
+	TypeArtifactStarted   = "artifact.started"
+	TypeArtifactCompleted = "artifact.completed"
+	TypeArtifactFailed    = "artifact.failed"
+	TypeArtifactCached    = "artifact.cached"
+	TypeArtifactRetry     = "artifact.retry"
+
+	TypeIngressPublished = "ingress.published"
+	TypeWiringResolved   = "wiring.resolved"
+	TypeServicePrestart  = "service.prestart"
+	TypeServiceStarting  = "service.starting"
+	TypeServiceHealthy   = "service.healthy"
+	TypeServiceInit      = "service.init"
+	TypeServiceReady     = "service.ready"
+	TypeServiceFailed    = "service.failed"
+	TypeServiceRestarted = "service.restarted"
+	TypeServiceStopping  = "service.stopping"
+	TypeServiceStopped   = "service.stopped"
+	TypeServiceLog       = "service.log"
+
+	TypeCallbackRequest  = "callback.request"
+	TypeCallbackResponse = "callback.response"
+
+	TypeEnvironmentFailing    = "environment.failing"
+	TypeEnvironmentDestroying = "environment.destroying"
+	TypeEnvironmentUp         = "environment.up"
+	TypeEnvironmentDown       = "environment.down"
+
+	TypeTestNote          = "test.note"
+	TypeHealthCheckFailed = "health.check_failed"
+	TypeProgressStall     = "progress.stall"
+
+	TypeRequestCompleted      = "request.completed"
+	TypeRequestMocked         = "request.mocked"
+	TypeConnectionOpened      = "connection.opened"
+	TypeConnectionClosed      = "connection.closed"
+	TypeHTTPConnectionOpened  = "http.connection.opened"
+	TypeHTTPConnectionClosed  = "http.connection.closed"
+	TypeGRPCCallCompleted     = "grpc.call.completed"
+	TypeGRPCStreamOpened      = "grpc.stream.opened"
+	TypeGRPCStreamMessage     = "grpc.stream.message"
+	TypeGRPCStreamClosed      = "grpc.stream.closed"
+	TypeKafkaRequestCompleted = "kafka.request.completed"
+	TypeRedisCommandCompleted = "redis.command.completed"
+	TypeNATSMessage           = "nats.message"
+	TypeMongoCommand          = "mongo.command"
+	TypeWebSocketOpened       = "websocket.opened"
+	TypeWebSocketClosed       = "websocket.closed"
+	TypeFaultInjected         = "fault.injected"
+)
+
+// Header is the synthetic first line of a log, summarising the run.
+type Header struct {
+	Environment     string             `json:"environment"`
+	Outcome         string             `json:"outcome,omitempty"` // "passed", "failed", or "crashed"
+	Services        []string           `json:"services,omitempty"`
+	DurationMs      float64            `json:"duration_ms"`
+	ArtifactRetries int                `json:"artifact_retries,omitempty"`
+	DroppedEvents   int                `json:"dropped_events,omitempty"`     // events missing from the log, dropped to stay under rigd's event cap
+	ExitCodes       map[string]int     `json:"exit_codes,omitempty"`         // first exit code per crashed service
+	ReadyDurations  map[string]float64 `json:"ready_durations_ms,omitempty"` // service.starting → service.ready per service, in ms
+	BodyLimit       *int               `json:"observe_body_limit,omitempty"` // observe body capture limit, if not the default
+	Metadata        map[string]string  `json:"metadata,omitempty"`           // labels from rig.WithMetadata
+	Timestamp       time.Time          `json:"timestamp"`
+}
+
+// Event is a single entry in a log. It has the same JSON shape as the
+// server's event; fields that only matter to the server's own protocol
+// (callbacks, the resolved environment) are kept raw.
+type Event struct {
+	Seq          uint64              `json:"seq"`
+	Type         string              `json:"type"`
+	Environment  string              `json:"environment,omitempty"`
+	Service      string              `json:"service,omitempty"`
+	Ingress      string              `json:"ingress,omitempty"`
+	Endpoint     *connect.Endpoint   `json:"endpoint,omitempty"`
+	Artifact     string              `json:"artifact,omitempty"`
+	Log          *LogEntry           `json:"log,omitempty"`
+	Callback     json.RawMessage     `json:"callback,omitempty"`
+	Result       json.RawMessage     `json:"result,omitempty"`
+	Error        string              `json:"error,omitempty"`
+	ExitCode     *int                `json:"exit_code,omitempty"`    // service.failed, service.restarted: exit status of a crashed process or container
+	StopTimeout  string              `json:"stop_timeout,omitempty"` // service.stopping: the service's configured stop timeout
+	Outputs      map[string]string   `json:"outputs,omitempty"`      // service.ready: values set by the service's init hooks
+	Request      *RequestInfo        `json:"request,omitempty"`
+	Connection   *ConnectionInfo     `json:"connection,omitempty"`
+	GRPCCall     *GRPCCallInfo       `json:"grpc_call,omitempty"`
+	GRPCStream   *GRPCStreamInfo     `json:"grpc_stream,omitempty"`
+	KafkaRequest *KafkaRequestInfo   `json:"kafka_request,omitempty"`
+	RedisCommand *RedisCommandInfo   `json:"redis_command,omitempty"`
+	NATSMessage  *NATSMessageInfo    `json:"nats_message,omitempty"`
+	MongoCommand *MongoCommandInfo   `json:"mongo_command,omitempty"`
+	WebSocket    *WebSocketInfo      `json:"websocket,omitempty"`
+	Fault        *FaultInfo          `json:"fault,omitempty"`
+	Diagnostic   *DiagnosticSnapshot `json:"diagnostic,omitempty"`
+	EnvDir       string              `json:"env_dir,omitempty"`
+	Message      string              `json:"message,omitempty"`
+	Outcome      string              `json:"outcome,omitempty"` // environment.down: "passed", "failed", or "crashed"
+	Assertion    *AssertionInfo      `json:"assertion,omitempty"`
+
+	// Ingresses is populated on environment.up: service name to ingress
+	// name to resolved endpoint.
+	Ingresses map[string]map[string]connect.Endpoint `json:"ingresses,omitempty"`
+	Resolved  json.RawMessage                        `json:"resolved,omitempty"`
+	Timestamp time.Time                              `json:"timestamp"`
+}
+
+// LogEntry is a chunk of service output.
+type LogEntry struct {
+	Stream string `json:"stream"` // "stdout" or "stderr"
+	Data   string `json:"data"`
+}
+
+// RequestInfo is an observed HTTP request/response pair.
+type RequestInfo struct {
+	Source       string  `json:"source"`
+	Target       string  `json:"target"`
+	Ingress      string  `json:"ingress"`
+	Method       string  `json:"method"`
+	Path         string  `json:"path"`
+	StatusCode   int     `json:"status_code"`
+	LatencyMs    float64 `json:"latency_ms"`
+	RequestSize  int64   `json:"request_size"`
+	ResponseSize int64   `json:"response_size"`
+
+	RequestHeaders        map[string][]string `json:"request_headers,omitempty"`
+	RequestBody           []byte              `json:"request_body,omitempty"`
+	RequestBodyTruncated  bool                `json:"request_body_truncated,omitempty"`
+	ResponseHeaders       map[string][]string `json:"response_headers,omitempty"`
+	ResponseBody          []byte              `json:"response_body,omitempty"`
+	ResponseBodyTruncated bool                `json:"response_body_truncated,omitempty"`
+
+	ProxyInjected   bool    `json:"proxy_injected,omitempty"`    // answered by the proxy, not the target
+	ProxyOverheadMs float64 `json:"proxy_overhead_ms,omitempty"` // time in the proxy before reaching the target; not in LatencyMs
+	Label           string  `json:"label,omitempty"`             // X-Rig-Label header value
+
+	TraceID      string `json:"trace_id,omitempty"`
+	SpanID       string `json:"span_id,omitempty"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
+}
+
+// ConnectionInfo is an observed TCP connection.
+type ConnectionInfo struct {
+	Source      string  `json:"source"`
+	Target      string  `json:"target"`
+	Ingress     string  `json:"ingress"`
+	BytesIn     int64   `json:"bytes_in"`
+	BytesOut    int64   `json:"bytes_out"`
+	DurationMs  float64 `json:"duration_ms"`
+	CloseReason string  `json:"close_reason,omitempty"` // set when the proxy closed the connection
+
+	// Set on http.connection.closed.
+	Requests     int64  `json:"requests,omitempty"`
+	HTTPProtocol string `json:"http_protocol,omitempty"` // "HTTP/1.1", "h2c", ...
+
+	PreviewIn           []byte `json:"preview_in,omitempty"`
+	PreviewInTruncated  bool   `json:"preview_in_truncated,omitempty"`
+	PreviewOut          []byte `json:"preview_out,omitempty"`
+	PreviewOutTruncated bool   `json:"preview_out_truncated,omitempty"`
+}
+
+// WebSocketInfo is an observed websocket connection.
+type WebSocketInfo struct {
+	Source     string  `json:"source"`
+	Target     string  `json:"target"`
+	Ingress    string  `json:"ingress"`
+	Path       string  `json:"path"`
+	FramesIn   int64   `json:"frames_in"`
+	FramesOut  int64   `json:"frames_out"`
+	BytesIn    int64   `json:"bytes_in"`
+	BytesOut   int64   `json:"bytes_out"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// GRPCCallInfo is an observed gRPC call.
+type GRPCCallInfo struct {
+	Source           string              `json:"source"`
+	Target           string              `json:"target"`
+	Ingress          string              `json:"ingress"`
+	Service          string              `json:"service"`      // "pkg.ServiceName"
+	Method           string              `json:"method"`       // "MethodName"
+	GRPCStatus       string              `json:"grpc_status"`  // "OK", or a numeric code
+	GRPCMessage      string              `json:"grpc_message"` // status message
+	LatencyMs        float64             `json:"latency_ms"`
+	RequestSize      int64               `json:"request_size"`
+	ResponseSize     int64               `json:"response_size"`
+	RequestMetadata  map[string][]string `json:"request_metadata,omitempty"`
+	ResponseMetadata map[string][]string `json:"response_metadata,omitempty"`
+
+	RequestBody           []byte          `json:"request_body,omitempty"`
+	RequestBodyTruncated  bool            `json:"request_body_truncated,omitempty"`
+	ResponseBody          []byte          `json:"response_body,omitempty"`
+	ResponseBodyTruncated bool            `json:"response_body_truncated,omitempty"`
+	RequestBodyDecoded    json.RawMessage `json:"request_body_decoded,omitempty"`
+	ResponseBodyDecoded   json.RawMessage `json:"response_body_decoded,omitempty"`
+
+	ProxyInjected bool `json:"proxy_injected,omitempty"`
+
+	TraceID      string `json:"trace_id,omitempty"`
+	SpanID       string `json:"span_id,omitempty"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
+}
+
+// GRPCStreamInfo is an observed streaming gRPC call: its identity on
+// grpc.stream.opened, one message on grpc.stream.message, and the final
+// status and counts on grpc.stream.closed.
+type GRPCStreamInfo struct {
+	Source          string              `json:"source"`
+	Target          string              `json:"target"`
+	Ingress         string              `json:"ingress"`
+	Service         string              `json:"service"` // "pkg.ServiceName"
+	Method          string              `json:"method"`  // "MethodName"
+	RequestMetadata map[string][]string `json:"request_metadata,omitempty"`
+
+	Direction     string          `json:"direction,omitempty"` // "in" or "out"
+	Body          []byte          `json:"body,omitempty"`
+	BodyTruncated bool            `json:"body_truncated,omitempty"`
+	BodyDecoded   json.RawMessage `json:"body_decoded,omitempty"`
+	Size          int64           `json:"size,omitempty"`
+
+	GRPCStatus       string              `json:"grpc_status,omitempty"`
+	GRPCMessage      string              `json:"grpc_message,omitempty"`
+	MessagesIn       int64               `json:"messages_in,omitempty"`
+	MessagesOut      int64               `json:"messages_out,omitempty"`
+	RequestSize      int64               `json:"request_size,omitempty"`
+	ResponseSize     int64               `json:"response_size,omitempty"`
+	DurationMs       float64             `json:"duration_ms,omitempty"`
+	ResponseMetadata map[string][]string `json:"response_metadata,omitempty"`
+
+	TraceID      string `json:"trace_id,omitempty"`
+	SpanID       string `json:"span_id,omitempty"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
+}
+
+// KafkaRequestInfo is an observed Kafka request/response pair.
+type KafkaRequestInfo struct {
+	Source        string  `json:"source"`
+	Target        string  `json:"target"`
+	Ingress       string  `json:"ingress"`
+	APIKey        int16   `json:"api_key"`
+	APIName       string  `json:"api_name"`
+	APIVersion    int16   `json:"api_version"`
+	CorrelationID int32   `json:"correlation_id"`
+	LatencyMs     float64 `json:"latency_ms"`
+	RequestSize   int64   `json:"request_size"`
+	ResponseSize  int64   `json:"response_size"`
+}
+
+// RedisCommandInfo is an observed Redis command and its reply.
+type RedisCommandInfo struct {
+	Source       string  `json:"source"`
+	Target       string  `json:"target"`
+	Ingress      string  `json:"ingress"`
+	Command      string  `json:"command"`
+	Key          string  `json:"key,omitempty"`
+	ReplyType    string  `json:"reply_type"`
+	RedisError   string  `json:"redis_error,omitempty"`
+	LatencyMs    float64 `json:"latency_ms"`
+	RequestSize  int64   `json:"request_size"`
+	ResponseSize int64   `json:"response_size"`
+}
+
+// MongoCommandInfo is an observed MongoDB command and its reply.
+type MongoCommandInfo struct {
+	Source       string  `json:"source"`
+	Target       string  `json:"target"`
+	Ingress      string  `json:"ingress"`
+	Command      string  `json:"command"`
+	Collection   string  `json:"collection,omitempty"`
+	Database     string  `json:"database,omitempty"`
+	MongoError   string  `json:"mongo_error,omitempty"`
+	LatencyMs    float64 `json:"latency_ms"`
+	RequestSize  int64   `json:"request_size"`
+	ResponseSize int64   `json:"response_size"`
+}
+
+// FaultInfo is a fault an observe proxy injected on an edge.
+type FaultInfo struct {
+	Source     string  `json:"source"`
+	Target     string  `json:"target"`
+	Ingress    string  `json:"ingress"`
+	Kind       string  `json:"kind"` // "latency", "drop" or "error"
+	DelayMs    float64 `json:"delay_ms,omitempty"`
+	StatusCode int     `json:"status_code,omitempty"`
+	Method     string  `json:"method,omitempty"`
+	Path       string  `json:"path,omitempty"`
+}
+
+// NATSMessageInfo is an observed NATS protocol message.
+type NATSMessageInfo struct {
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	Ingress     string `json:"ingress"`
+	Op          string `json:"op"` // "PUB", "SUB", or "MSG"
+	Subject     string `json:"subject"`
+	ReplyTo     string `json:"reply_to,omitempty"`
+	Queue       string `json:"queue,omitempty"`
+	PayloadSize int64  `json:"payload_size"`
+	HeaderSize  int64  `json:"header_size,omitempty"`
+}
+
+// AssertionInfo is the structured form of a failed test assertion.
+type AssertionInfo struct {
+	File  string `json:"file,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Field string `json:"field,omitempty"`
+	Want  string `json:"want,omitempty"`
+	Got   string `json:"got,omitempty"`
+}
+
+// DiagnosticSnapshot is the state of every service when a progress stall
+// was detected.
+type DiagnosticSnapshot struct {
+	StalledFor string            `json:"stalled_for"`
+	Services   []ServiceSnapshot `json:"services"`
+}
+
+// ServiceSnapshot is one service's state in a DiagnosticSnapshot.
+type ServiceSnapshot struct {
+	Name      string   `json:"name"`
+	Phase     string   `json:"phase"`
+	WaitingOn []string `json:"waiting_on,omitempty"`
+}
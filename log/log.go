@@ -0,0 +1,186 @@
+// Package log reads the JSONL event logs rigd writes for each test, so
+// tools can query a capture without redefining the event types:
+//
+//	l, err := log.Open(".rig/logs/TestOrderFlow-1a2b3c.jsonl")
+//	if err != nil {
+//		return err
+//	}
+//	for _, ev := range l.Failures() {
+//		fmt.Println(ev.Service, ev.Error)
+//	}
+//
+// The Event type has the same JSON shape as the events the server
+// publishes and streams, so it also decodes the SSE event stream.
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Log is a parsed event log.
+type Log struct {
+	header *Header
+	events []Event
+}
+
+// Open reads and parses the log at path.
+func Open(path string) (*Log, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	l, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Read parses a log from r. The log.header line is optional, so Read also
+// accepts a bare sequence of events.
+func Read(r io.Reader) (*Log, error) {
+	l := &Log{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 256*1024), 16<<20)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var probe struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(line, &probe); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if probe.Type == "log.header" {
+			var h Header
+			if err := json.Unmarshal(line, &h); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			l.header = &h
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		l.events = append(l.events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Header returns the log's summary header, or nil if it has none.
+func (l *Log) Header() *Header {
+	return l.header
+}
+
+// Events returns every event in the log, in sequence order.
+func (l *Log) Events() []Event {
+	return l.events
+}
+
+// Failures returns the events that explain why a run failed: crashed
+// services, failed builds, the environment.failing that tears a crashed
+// environment down, and failed test assertions.
+func (l *Log) Failures() []Event {
+	var out []Event
+	for _, ev := range l.events {
+		switch ev.Type {
+		case TypeServiceFailed, TypeArtifactFailed, TypeEnvironmentFailing:
+			out = append(out, ev)
+		case TypeTestNote:
+			if ev.Error != "" {
+				out = append(out, ev)
+			}
+		}
+	}
+	return out
+}
+
+// Edge summarises the traffic observed from one service to another.
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Calls  int    `json:"calls"`
+
+	// Errors counts HTTP 4xx/5xx responses, gRPC calls and streams with a non-OK
+	// status and Redis error replies.
+	Errors int `json:"errors"`
+}
+
+// Edges groups the log's traffic by source → target, sorted by source,
+// then target. Every protocol counts a completed exchange as one call:
+// an HTTP request, gRPC call or stream, Kafka request, Redis command, NATS message,
+// MongoDB command, or a closed TCP or websocket connection.
+func (l *Log) Edges() []Edge {
+	type key struct{ source, target string }
+	edges := map[key]*Edge{}
+	add := func(source, target string, failed bool) {
+		k := key{source, target}
+		e := edges[k]
+		if e == nil {
+			e = &Edge{Source: source, Target: target}
+			edges[k] = e
+		}
+		e.Calls++
+		if failed {
+			e.Errors++
+		}
+	}
+	for _, ev := range l.events {
+		switch {
+		case ev.Request != nil && (ev.Type == TypeRequestCompleted || ev.Type == TypeRequestMocked):
+			add(ev.Request.Source, ev.Request.Target, ev.Request.StatusCode >= 400)
+		case ev.GRPCCall != nil && ev.Type == TypeGRPCCallCompleted:
+			add(ev.GRPCCall.Source, ev.GRPCCall.Target, !grpcOK(ev.GRPCCall.GRPCStatus))
+		case ev.GRPCStream != nil && ev.Type == TypeGRPCStreamClosed:
+			add(ev.GRPCStream.Source, ev.GRPCStream.Target, !grpcOK(ev.GRPCStream.GRPCStatus))
+		case ev.Connection != nil && ev.Type == TypeConnectionClosed:
+			add(ev.Connection.Source, ev.Connection.Target, false)
+		case ev.KafkaRequest != nil:
+			add(ev.KafkaRequest.Source, ev.KafkaRequest.Target, false)
+		case ev.RedisCommand != nil:
+			add(ev.RedisCommand.Source, ev.RedisCommand.Target, ev.RedisCommand.RedisError != "")
+		case ev.NATSMessage != nil:
+			add(ev.NATSMessage.Source, ev.NATSMessage.Target, false)
+		case ev.MongoCommand != nil:
+			add(ev.MongoCommand.Source, ev.MongoCommand.Target, ev.MongoCommand.MongoError != "")
+		case ev.WebSocket != nil && ev.Type == TypeWebSocketClosed:
+			add(ev.WebSocket.Source, ev.WebSocket.Target, false)
+		}
+	}
+
+	out := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Source != out[j].Source {
+			return out[i].Source < out[j].Source
+		}
+		return out[i].Target < out[j].Target
+	})
+	return out
+}
+
+// grpcOK reports whether a gRPC status, by name or numeric code, is OK.
+func grpcOK(status string) bool {
+	if status == "OK" {
+		return true
+	}
+	code, err := strconv.Atoi(status)
+	return err == nil && code == 0
+}
@@ -0,0 +1,138 @@
+package log_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/matgreaves/rig/log"
+)
+
+func TestOpen_Header(t *testing.T) {
+	l, err := log.Open("testdata/failed.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := l.Header()
+	if h == nil {
+		t.Fatal("Header() = nil, want the log.header line")
+	}
+	if h.Environment != "TestOrderFlow" || h.Outcome != "failed" || h.DurationMs != 3312 {
+		t.Errorf("header = %+v", *h)
+	}
+	if want := []string{"db", "temporal", "api"}; !reflect.DeepEqual(h.Services, want) {
+		t.Errorf("services = %v, want %v", h.Services, want)
+	}
+	if n := len(l.Events()); n != 2 {
+		t.Errorf("got %d events, want 2 (header excluded)", n)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	tests := []struct {
+		path string
+		typ  string
+		err  string
+	}{
+		{"testdata/failed.jsonl", log.TypeTestNote, "expected 200, got 500"},
+		{"testdata/crashed.jsonl", log.TypeEnvironmentFailing, `service "worker": exit 1`},
+	}
+	for _, tt := range tests {
+		l, err := log.Open(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := l.Failures()
+		if len(got) != 1 || got[0].Type != tt.typ || got[0].Error != tt.err {
+			t.Errorf("%s: failures = %+v, want one %s %q", tt.path, got, tt.typ, tt.err)
+		}
+	}
+}
+
+func TestEdges(t *testing.T) {
+	l, err := log.Open("testdata/mixed_traffic.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Header() != nil {
+		t.Errorf("Header() = %+v, want nil for a log without one", l.Header())
+	}
+	want := []log.Edge{
+		{Source: "order", Target: "postgres", Calls: 4, Errors: 1},
+		{Source: "order", Target: "temporal", Calls: 1},
+		{Source: "temporal", Target: "order", Calls: 1},
+	}
+	if got := l.Edges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("edges = %+v, want %+v", got, want)
+	}
+	if len(l.Failures()) != 0 {
+		t.Errorf("failures = %+v, want none", l.Failures())
+	}
+}
+
+func TestEvents_Traffic(t *testing.T) {
+	l, err := log.Open("testdata/mixed_traffic.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := l.Events()[1]
+	if ev.Type != log.TypeRequestCompleted || ev.Request == nil {
+		t.Fatalf("event 1 = %+v, want request.completed", ev)
+	}
+	if got := string(ev.Request.RequestBody); got != `{"name":"foo"}` {
+		t.Errorf("request body = %q", got)
+	}
+	g := l.Events()[2].GRPCCall
+	if g == nil || g.Service != "WorkflowService" || string(g.ResponseBodyDecoded) != `{"run_id":"run-xyz789"}` {
+		t.Errorf("grpc call = %+v", g)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	_, err := log.Read(strings.NewReader("{\"type\":\"service.ready\"}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("err = %v, want a line 2 parse error", err)
+	}
+}
+
+func TestOpen_Missing(t *testing.T) {
+	if _, err := log.Open("testdata/missing.jsonl"); err == nil {
+		t.Error("Open of a missing file succeeded")
+	}
+}
+
+func TestTraffic(t *testing.T) {
+	l, err := log.Open("testdata/mixed_traffic.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seqs := func(events []log.Event) []uint64 {
+		var out []uint64
+		for _, ev := range events {
+			out = append(out, ev.Seq)
+		}
+		return out
+	}
+	tests := []struct {
+		name   string
+		filter log.TrafficFilter
+		want   []uint64
+	}{
+		{"all", log.TrafficFilter{}, []uint64{2, 3, 4, 5, 6, 7}},
+		{"edge", log.TrafficFilter{Edge: "order→postgres"}, []uint64{2, 5, 6, 7}},
+		{"edge ascii arrow", log.TrafficFilter{Edge: "->order"}, []uint64{4}},
+		{"edge either end", log.TrafficFilter{Edge: "temporal"}, []uint64{3, 4}},
+		{"status class", log.TrafficFilter{Status: "5xx"}, []uint64{7}},
+		{"grpc status", log.TrafficFilter{Status: "ok"}, []uint64{3}},
+		{"protocol", log.TrafficFilter{Protocol: "tcp"}, []uint64{6}},
+		{"slow", log.TrafficFilter{SlowMs: 10}, []uint64{6, 7}},
+		{"combined", log.TrafficFilter{Edge: "order→postgres", Protocol: "http", SlowMs: 1}, []uint64{2, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seqs(l.Traffic(tt.filter)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("seqs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package log
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TrafficFilter selects traffic events. Empty fields match everything; an
+// event must match every set field.
+type TrafficFilter struct {
+	// Edge is "source→target" ("->" also works) with either side optional,
+	// or a bare service name matching either end. Names are case-insensitive.
+	Edge string
+
+	// Status is an exact status ("404", "OK", "UNAVAILABLE", a Redis error
+	// code such as "WRONGTYPE", a MongoDB error code name such as
+	// "DuplicateKey") or an HTTP class such as "5xx".
+	Status string
+
+	// Protocol is "http", "grpc", "tcp", "kafka", "redis", "nats", "mongo"
+	// or "ws".
+	Protocol string
+
+	// SlowMs keeps events whose latency (duration, for connections) is at
+	// least this many milliseconds.
+	SlowMs float64
+
+	Label string // X-Rig-Label value of HTTP requests
+	Trace string // trace ID, or a prefix of one, of HTTP and gRPC calls
+}
+
+// IsTraffic reports whether ev is a completed exchange shown as traffic:
+// an HTTP request, gRPC call or stream, Kafka request, Redis command, NATS
+// message, MongoDB command, or a closed TCP or websocket connection.
+func IsTraffic(ev Event) bool {
+	return trafficProtocol(ev) != ""
+}
+
+// Match reports whether ev is a traffic event selected by f.
+func (f TrafficFilter) Match(ev Event) bool {
+	protocol := trafficProtocol(ev)
+	if protocol == "" {
+		return false
+	}
+	if f.Protocol != "" && !strings.EqualFold(f.Protocol, protocol) {
+		return false
+	}
+	source, target := trafficEdge(ev)
+	if !matchEdge(source, target, f.Edge) {
+		return false
+	}
+	if f.Status != "" && !matchStatus(ev, f.Status) {
+		return false
+	}
+	if f.SlowMs != 0 && trafficLatency(ev) < f.SlowMs {
+		return false
+	}
+	if f.Label != "" && (ev.Request == nil || ev.Request.Label != f.Label) {
+		return false
+	}
+	if f.Trace != "" {
+		id := trafficTraceID(ev)
+		if id == "" || !strings.HasPrefix(id, strings.ToLower(f.Trace)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Traffic returns the log's traffic events selected by f, in sequence order.
+func (l *Log) Traffic(f TrafficFilter) []Event {
+	var out []Event
+	for _, ev := range l.events {
+		if f.Match(ev) {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// trafficProtocol returns the protocol of a traffic event, or "" if ev
+// isn't one.
+func trafficProtocol(ev Event) string {
+	switch {
+	case ev.Request != nil && (ev.Type == TypeRequestCompleted || ev.Type == TypeRequestMocked):
+		return "http"
+	case ev.GRPCCall != nil && ev.Type == TypeGRPCCallCompleted,
+		ev.GRPCStream != nil && ev.Type == TypeGRPCStreamClosed:
+		return "grpc"
+	case ev.Connection != nil && ev.Type == TypeConnectionClosed:
+		return "tcp"
+	case ev.KafkaRequest != nil && ev.Type == TypeKafkaRequestCompleted:
+		return "kafka"
+	case ev.RedisCommand != nil && ev.Type == TypeRedisCommandCompleted:
+		return "redis"
+	case ev.NATSMessage != nil && ev.Type == TypeNATSMessage:
+		return "nats"
+	case ev.MongoCommand != nil && ev.Type == TypeMongoCommand:
+		return "mongo"
+	case ev.WebSocket != nil && ev.Type == TypeWebSocketClosed:
+		return "ws"
+	}
+	return ""
+}
+
+func trafficEdge(ev Event) (source, target string) {
+	switch {
+	case ev.Request != nil:
+		return ev.Request.Source, ev.Request.Target
+	case ev.GRPCCall != nil:
+		return ev.GRPCCall.Source, ev.GRPCCall.Target
+	case ev.GRPCStream != nil:
+		return ev.GRPCStream.Source, ev.GRPCStream.Target
+	case ev.Connection != nil:
+		return ev.Connection.Source, ev.Connection.Target
+	case ev.KafkaRequest != nil:
+		return ev.KafkaRequest.Source, ev.KafkaRequest.Target
+	case ev.RedisCommand != nil:
+		return ev.RedisCommand.Source, ev.RedisCommand.Target
+	case ev.NATSMessage != nil:
+		return ev.NATSMessage.Source, ev.NATSMessage.Target
+	case ev.MongoCommand != nil:
+		return ev.MongoCommand.Source, ev.MongoCommand.Target
+	case ev.WebSocket != nil:
+		return ev.WebSocket.Source, ev.WebSocket.Target
+	}
+	return "", ""
+}
+
+func matchEdge(source, target, edge string) bool {
+	if edge == "" {
+		return true
+	}
+	edge = strings.ReplaceAll(edge, "->", "→")
+	if src, tgt, ok := strings.Cut(edge, "→"); ok {
+		src, tgt = strings.TrimSpace(src), strings.TrimSpace(tgt)
+		if src != "" && !strings.EqualFold(source, src) {
+			return false
+		}
+		return tgt == "" || strings.EqualFold(target, tgt)
+	}
+	return strings.EqualFold(source, edge) || strings.EqualFold(target, edge)
+}
+
+// matchStatus compares status against the event's status: the HTTP status
+// code, the gRPC status, or, for Redis and MongoDB, "OK" or the error's code.
+// Other protocols have no status and never match.
+func matchStatus(ev Event, status string) bool {
+	if len(status) == 3 && status[1] == 'x' && status[2] == 'x' {
+		if ev.Request == nil {
+			return false
+		}
+		actual := strconv.Itoa(ev.Request.StatusCode)
+		return len(actual) == 3 && actual[0] == status[0]
+	}
+	var actual string
+	switch {
+	case ev.Request != nil:
+		actual = strconv.Itoa(ev.Request.StatusCode)
+	case ev.GRPCCall != nil:
+		actual = ev.GRPCCall.GRPCStatus
+	case ev.GRPCStream != nil:
+		actual = ev.GRPCStream.GRPCStatus
+	case ev.RedisCommand != nil:
+		actual = "OK"
+		if ev.RedisCommand.RedisError != "" {
+			// Error replies start with a code such as ERR or WRONGTYPE.
+			actual, _, _ = strings.Cut(ev.RedisCommand.RedisError, " ")
+		}
+	case ev.MongoCommand != nil:
+		actual = "OK"
+		if ev.MongoCommand.MongoError != "" {
+			actual, _, _ = strings.Cut(ev.MongoCommand.MongoError, ":")
+		}
+	default:
+		return false
+	}
+	return strings.EqualFold(actual, status)
+}
+
+func trafficLatency(ev Event) float64 {
+	switch {
+	case ev.Request != nil:
+		return ev.Request.LatencyMs
+	case ev.GRPCCall != nil:
+		return ev.GRPCCall.LatencyMs
+	case ev.GRPCStream != nil:
+		return ev.GRPCStream.DurationMs
+	case ev.Connection != nil:
+		return ev.Connection.DurationMs
+	case ev.KafkaRequest != nil:
+		return ev.KafkaRequest.LatencyMs
+	case ev.RedisCommand != nil:
+		return ev.RedisCommand.LatencyMs
+	case ev.MongoCommand != nil:
+		return ev.MongoCommand.LatencyMs
+	case ev.WebSocket != nil:
+		return ev.WebSocket.DurationMs
+	}
+	return 0
+}
+
+func trafficTraceID(ev Event) string {
+	switch {
+	case ev.Request != nil:
+		return ev.Request.TraceID
+	case ev.GRPCCall != nil:
+		return ev.GRPCCall.TraceID
+	}
+	return ""
+}